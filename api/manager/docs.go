@@ -242,6 +242,153 @@ const docTemplate = `{
                 }
             }
         },
+        "/audits": {
+            "get": {
+                "description": "Get Audits",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get Audits",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "user",
+                            "scheduler",
+                            "seed_peer"
+                        ],
+                        "type": "string",
+                        "description": "actor type",
+                        "name": "actor_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "user name or hostname of actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "http method or grpc method",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "resource type",
+                        "name": "resource_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "resource id",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "SUCCESS",
+                            "FAILURE"
+                        ],
+                        "type": "string",
+                        "description": "mutation state",
+                        "name": "state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.Audit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/audits/{id}": {
+            "get": {
+                "description": "Get Audit by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get Audit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.Audit"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
         "/buckets": {
             "get": {
                 "description": "Get Buckets",
@@ -863,6 +1010,47 @@ const docTemplate = `{
                 }
             }
         },
+        "/jobs/{id}/cancel": {
+            "post": {
+                "description": "Cancel by id, the queued tasks are revoked from the queues of schedulers and the unfinished runs of recurring job are canceled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Cancel Job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
         "/oauth": {
             "get": {
                 "description": "Get Oauths",
@@ -1232,6 +1420,227 @@ const docTemplate = `{
                 }
             }
         },
+        "/registry-credentials": {
+            "get": {
+                "description": "Get RegistryCredentials",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegistryCredential"
+                ],
+                "summary": "Get RegistryCredentials",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.RegistryCredential"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegistryCredential"
+                ],
+                "summary": "Create RegistryCredential",
+                "parameters": [
+                    {
+                        "description": "RegistryCredential",
+                        "name": "RegistryCredential",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateRegistryCredentialRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.RegistryCredential"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/registry-credentials/{id}": {
+            "get": {
+                "description": "Get RegistryCredential by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegistryCredential"
+                ],
+                "summary": "Get RegistryCredential",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.RegistryCredential"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegistryCredential"
+                ],
+                "summary": "Destroy RegistryCredential",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RegistryCredential"
+                ],
+                "summary": "Update RegistryCredential",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "RegistryCredential",
+                        "name": "RegistryCredential",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateRegistryCredentialRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.RegistryCredential"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
         "/roles": {
             "get": {
                 "description": "Get roles",
@@ -4057,6 +4466,62 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_model.Audit": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "actor_type": {
+                    "type": "string"
+                },
+                "after": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.JSONMap"
+                },
+                "before": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.JSONMap"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "request": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.JSONMap"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_model.Config": {
             "type": "object",
             "properties": {
@@ -4102,9 +4567,18 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
                 "result": {
                     "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.JSONMap"
                 },
+                "schedule": {
+                    "type": "string"
+                },
                 "scheduler_clusters": {
                     "type": "array",
                     "items": {
@@ -4163,6 +4637,41 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_model.RegistryCredential": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_model.User"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_model.Scheduler": {
             "type": "object",
             "properties": {
@@ -4546,6 +5055,9 @@ const docTemplate = `{
                     "type": "object",
                     "additionalProperties": {}
                 },
+                "schedule": {
+                    "type": "string"
+                },
                 "scheduler_cluster_ids": {
                     "type": "array",
                     "items": {
@@ -4655,6 +5167,47 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.CreateRegistryCredentialRequest": {
+            "type": "object",
+            "required": [
+                "host",
+                "secret",
+                "type",
+                "user_id"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "ecr",
+                        "gcr"
+                    ]
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "basic",
+                        "bearer",
+                        "token-exchange"
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.CreateRoleRequest": {
             "type": "object",
             "required": [
@@ -5055,6 +5608,9 @@ const docTemplate = `{
         "d7y_io_dragonfly_v2_manager_types.SchedulerClusterConfig": {
             "type": "object",
             "properties": {
+                "evaluator": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SchedulerClusterEvaluatorConfig"
+                },
                 "filter_parent_limit": {
                     "type": "integer",
                     "maximum": 20,
@@ -5067,6 +5623,61 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.SchedulerClusterEvaluatorConfig": {
+            "type": "object",
+            "properties": {
+                "cpu_load_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "disk_free_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "finished_piece_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "free_upload_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "host_type_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "idc_affinity_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "location_affinity_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "memory_pressure_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "net_topology_affinity_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "parent_host_upload_success_weight": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.SchedulerClusterScopes": {
             "type": "object",
             "properties": {
@@ -5203,6 +5814,9 @@ const docTemplate = `{
                 "bio": {
                     "type": "string"
                 },
+                "schedule": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
                 }
@@ -5272,6 +5886,41 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.UpdateRegistryCredentialRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string",
+                    "enum": [
+                        "ecr",
+                        "gcr"
+                    ]
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "basic",
+                        "bearer",
+                        "token-exchange"
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.UpdateSchedulerClusterRequest": {
             "type": "object",
             "properties": {
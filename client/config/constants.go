@@ -45,6 +45,21 @@ const (
 	PatternSource   = "source"
 )
 
+// Piece selection strategy.
+const (
+	// PieceSelectionStrategySequential downloads pieces in order, it is suitable for streaming.
+	PieceSelectionStrategySequential = "sequential"
+	// PieceSelectionStrategyRarestFirst downloads the pieces owned by the fewest parents first.
+	PieceSelectionStrategyRarestFirst = "rarest-first"
+	// PieceSelectionStrategyRandom downloads pieces in random order for swarm diversity.
+	PieceSelectionStrategyRandom = "random"
+)
+
+const (
+	// DefaultEndgamePieces is the default count of remaining pieces to enter endgame mode.
+	DefaultEndgamePieces = 4
+)
+
 // Download limit.
 const (
 	DefaultPerPeerDownloadLimit = 512 * unit.MB
@@ -88,11 +103,12 @@ const (
 
 // Service defalut port of listening.
 const (
-	DefaultEndPort                = 65535
-	DefaultPeerStartPort          = 65000
-	DefaultUploadStartPort        = 65002
-	DefaultObjectStorageStartPort = 65004
-	DefaultHealthyStartPort       = 40901
+	DefaultEndPort                  = 65535
+	DefaultPeerStartPort            = 65000
+	DefaultUploadStartPort          = 65002
+	DefaultObjectStorageStartPort   = 65004
+	DefaultObjectStorageS3StartPort = 65006
+	DefaultHealthyStartPort         = 40901
 )
 
 var (
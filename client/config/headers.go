@@ -29,4 +29,29 @@ const (
 	HeaderDragonflyRegistry = "X-Dragonfly-Registry"
 	// HeaderDragonflyObjectMetaDigest is used for digest of object storage.
 	HeaderDragonflyObjectMetaDigest = "X-Dragonfly-Object-Meta-Digest"
+	// HeaderDragonflyWeight is used for the weight of task in priority traffic shaper.
+	HeaderDragonflyWeight = "X-Dragonfly-Weight"
+	// HeaderDragonflyPieceSelectionStrategy is used for the piece selection strategy of task.
+	HeaderDragonflyPieceSelectionStrategy = "X-Dragonfly-Piece-Selection-Strategy"
 )
+
+// daemonHeaders is the headers of url meta used by dfdaemon only, they are not sent to the source.
+var daemonHeaders = []string{
+	HeaderDragonflyWeight,
+	HeaderDragonflyPieceSelectionStrategy,
+}
+
+// SourceHeader returns a copy of url meta header without the headers used by dfdaemon only,
+// it is the header of back-to-source request.
+func SourceHeader(header map[string]string) map[string]string {
+	sourceHeader := make(map[string]string, len(header))
+	for key, value := range header {
+		sourceHeader[key] = value
+	}
+
+	for _, key := range daemonHeaders {
+		delete(sourceHeader, key)
+	}
+
+	return sourceHeader
+}
@@ -0,0 +1,60 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		expect func(t *testing.T, header, sourceHeader map[string]string)
+	}{
+		{
+			name: "headers used by dfdaemon are removed",
+			header: map[string]string{
+				"Authorization":                       "foo",
+				HeaderDragonflyWeight:                 "2",
+				HeaderDragonflyPieceSelectionStrategy: PieceSelectionStrategyRarestFirst,
+			},
+			expect: func(t *testing.T, header, sourceHeader map[string]string) {
+				assert := assert.New(t)
+				assert.Equal(map[string]string{"Authorization": "foo"}, sourceHeader)
+				assert.Equal("2", header[HeaderDragonflyWeight])
+				assert.Equal(PieceSelectionStrategyRarestFirst, header[HeaderDragonflyPieceSelectionStrategy])
+			},
+		},
+		{
+			name:   "header is nil",
+			header: nil,
+			expect: func(t *testing.T, header, sourceHeader map[string]string) {
+				assert := assert.New(t)
+				assert.Empty(sourceHeader)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, tc.header, SourceHeader(tc.header))
+		})
+	}
+}
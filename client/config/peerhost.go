@@ -135,6 +135,14 @@ func (p *DaemonOption) Convert() error {
 		}
 	}
 
+	if p.ObjectStorage.S3.ListenOption.TCPListen != nil && p.ObjectStorage.S3.ListenOption.TCPListen.Listen == "" {
+		if p.Network.EnableIPv6 {
+			p.ObjectStorage.S3.ListenOption.TCPListen.Listen = net.IPv6zero.String()
+		} else {
+			p.ObjectStorage.S3.ListenOption.TCPListen.Listen = net.IPv4zero.String()
+		}
+	}
+
 	if p.Proxy.ListenOption.TCPListen != nil && p.Proxy.ListenOption.TCPListen.Listen == "" {
 		if p.Network.EnableIPv6 {
 			p.Proxy.ListenOption.TCPListen.Listen = net.IPv6zero.String()
@@ -187,6 +195,18 @@ func (p *DaemonOption) Validate() error {
 		if p.ObjectStorage.MaxReplicas <= 0 {
 			return errors.New("max replicas must be greater than 0")
 		}
+
+		if p.ObjectStorage.S3.Enable {
+			if len(p.ObjectStorage.S3.Credentials) == 0 {
+				return errors.New("s3 credentials are required")
+			}
+
+			for _, credential := range p.ObjectStorage.S3.Credentials {
+				if credential.AccessKey == "" || credential.SecretKey == "" {
+					return errors.New("s3 credential requires parameter accessKey and secretKey")
+				}
+			}
+		}
 	}
 
 	if p.Reload.Interval.Duration > 0 && p.Reload.Interval.Duration < time.Second {
@@ -198,6 +218,16 @@ func (p *DaemonOption) Validate() error {
 	default:
 		return errors.New("available pattern: p2p, seed-peer, source")
 	}
+
+	switch p.Download.PieceSelectionStrategy {
+	case "", PieceSelectionStrategySequential, PieceSelectionStrategyRarestFirst, PieceSelectionStrategyRandom:
+	default:
+		return errors.New("available piece selection strategy: sequential, rarest-first, random")
+	}
+
+	if p.Download.EndgamePieces < 0 {
+		return errors.New("endgame pieces must be greater than or equal to 0")
+	}
 	return nil
 }
 
@@ -311,6 +341,30 @@ type DownloadOption struct {
 
 	RecursiveConcurrent    RecursiveConcurrent `mapstructure:"recursiveConcurrent" yaml:"recursiveConcurrent"`
 	CacheRecursiveMetadata time.Duration       `mapstructure:"cacheRecursiveMetadata" yaml:"cacheRecursiveMetadata"`
+	// PriorityTrafficShaper is used when TrafficShaperType is priority
+	PriorityTrafficShaper PriorityTrafficShaperOption `mapstructure:"priorityTrafficShaper" yaml:"priorityTrafficShaper"`
+	// PieceSelectionStrategy is the default order of downloading pieces from parents,
+	// available strategies: sequential, rarest-first, random, default: sequential
+	PieceSelectionStrategy string `mapstructure:"pieceSelectionStrategy" yaml:"pieceSelectionStrategy"`
+	// EndgamePieces is the count of remaining pieces to enter endgame mode, the remaining pieces are
+	// requested from multiple parents in parallel and the duplicates are cancelled when the first copy arrives,
+	// set 0 to disable endgame mode
+	EndgamePieces int32 `mapstructure:"endgamePieces" yaml:"endgamePieces"`
+	// FileSourceDirs is the directories which file source is allowed to read, like the mounted NFS directories,
+	// file source is disabled when it is empty
+	FileSourceDirs []string `mapstructure:"fileSourceDirs" yaml:"fileSourceDirs"`
+}
+
+type PriorityTrafficShaperOption struct {
+	// MinRateLimit is the guaranteed minimum download rate of every task,
+	// when it is zero, the piece size of task is guaranteed
+	MinRateLimit util.RateLimit `mapstructure:"minRateLimit" yaml:"minRateLimit"`
+	// DefaultWeight is the weight of task which has no weight in header, tag or application, default: 1
+	DefaultWeight int `mapstructure:"defaultWeight" yaml:"defaultWeight"`
+	// TagWeights is the weight of task by tag
+	TagWeights map[string]int `mapstructure:"tagWeights" yaml:"tagWeights"`
+	// ApplicationWeights is the weight of task by application
+	ApplicationWeights map[string]int `mapstructure:"applicationWeights" yaml:"applicationWeights"`
 }
 
 type TransportOption struct {
@@ -483,6 +537,24 @@ type ObjectStorageOption struct {
 	MaxReplicas int `mapstructure:"maxReplicas" yaml:"maxReplicas"`
 	// ListenOption is object storage service listener.
 	ListenOption `yaml:",inline" mapstructure:",squash"`
+	// S3 is the S3-compatible service of object storage.
+	S3 ObjectStorageS3Option `mapstructure:"s3" yaml:"s3"`
+}
+
+type ObjectStorageS3Option struct {
+	// Enable S3-compatible service, it serves the path-style api of objects.
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// Credentials are used to verify the AWS signature version 4 of requests.
+	Credentials []S3CredentialOption `mapstructure:"credentials" yaml:"credentials"`
+	// ListenOption is S3-compatible service listener.
+	ListenOption `yaml:",inline" mapstructure:",squash"`
+}
+
+type S3CredentialOption struct {
+	// AccessKey is access key id of credential.
+	AccessKey string `mapstructure:"accessKey" yaml:"accessKey"`
+	// SecretKey is secret access key of credential.
+	SecretKey string `mapstructure:"secretKey" yaml:"secretKey"`
 }
 
 type ListenOption struct {
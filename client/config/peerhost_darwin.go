@@ -69,6 +69,7 @@ var peerHostConfig = func() *DaemonOption {
 			PieceDownloadTimeout: 30 * time.Second,
 			GRPCDialTimeout:      10 * time.Second,
 			GetPiecesMaxRetry:    100,
+			EndgamePieces:        DefaultEndgamePieces,
 			RecursiveConcurrent: RecursiveConcurrent{
 				GoroutineCount: 32,
 			},
@@ -132,6 +133,21 @@ var peerHostConfig = func() *DaemonOption {
 					},
 				},
 			},
+			S3: ObjectStorageS3Option{
+				Enable: false,
+				ListenOption: ListenOption{
+					Security: SecurityOption{
+						Insecure:  true,
+						TLSVerify: true,
+					},
+					TCPListen: &TCPListenOption{
+						PortRange: TCPListenPortRange{
+							Start: DefaultObjectStorageS3StartPort,
+							End:   DefaultEndPort,
+						},
+					},
+				},
+			},
 		},
 		Proxy: &ProxyOption{
 			ListenOption: ListenOption{
@@ -299,6 +299,8 @@ func TestPeerHostOption_Load(t *testing.T) {
 				Limit: 512 * 1024 * 1024,
 			},
 			PieceDownloadTimeout: 30 * time.Second,
+			EndgamePieces:        8,
+			FileSourceDirs:       []string{"/mnt/nfs"},
 			DownloadGRPC: ListenOption{
 				Security: SecurityOption{
 					Insecure:  true,
@@ -395,6 +397,24 @@ func TestPeerHostOption_Load(t *testing.T) {
 					},
 				},
 			},
+			S3: ObjectStorageS3Option{
+				Enable: true,
+				Credentials: []S3CredentialOption{
+					{
+						AccessKey: "foo",
+						SecretKey: "bar",
+					},
+				},
+				ListenOption: ListenOption{
+					TCPListen: &TCPListenOption{
+						Listen: "0.0.0.0",
+						PortRange: TCPListenPortRange{
+							Start: 65006,
+							End:   0,
+						},
+					},
+				},
+			},
 		},
 		Storage: StorageOption{
 			DataPath: "/tmp/storage/data",
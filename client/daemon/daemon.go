@@ -67,6 +67,7 @@ import (
 	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	schedulerclient "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
 	"d7y.io/dragonfly/v2/pkg/source"
+	"d7y.io/dragonfly/v2/pkg/source/clients/fileprotocol"
 	"d7y.io/dragonfly/v2/pkg/types"
 )
 
@@ -110,6 +111,13 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 	// update plugin directory
 	source.UpdatePluginDir(d.PluginDir())
 
+	// file source client of daemon is restricted in the allowed directories
+	source.UnRegister(fileprotocol.FileScheme)
+	if err := source.Register(fileprotocol.FileScheme,
+		fileprotocol.NewFileSourceClient(fileprotocol.WithAllowedDirs(opt.Download.FileSourceDirs...)), fileprotocol.Adapter); err != nil {
+		return nil, err
+	}
+
 	host := &schedulerv1.PeerHost{
 		Id:             idgen.HostID(opt.Host.Hostname, int32(opt.Download.PeerGRPC.TCPListen.PortRange.Start)),
 		Ip:             opt.Host.AdvertiseIP,
@@ -256,14 +264,17 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 			GRPCCredentials: grpcCredentials,
 			GRPCDialTimeout: opt.Download.GRPCDialTimeout,
 		},
-		SchedulerClient:   schedulerClient,
-		PerPeerRateLimit:  opt.Download.PerPeerRateLimit.Limit,
-		TotalRateLimit:    opt.Download.TotalRateLimit.Limit,
-		TrafficShaperType: opt.Download.TrafficShaperType,
-		Multiplex:         opt.Storage.Multiplex,
-		Prefetch:          opt.Download.Prefetch,
-		GetPiecesMaxRetry: opt.Download.GetPiecesMaxRetry,
-		SplitRunningTasks: opt.Download.SplitRunningTasks,
+		SchedulerClient:        schedulerClient,
+		PerPeerRateLimit:       opt.Download.PerPeerRateLimit.Limit,
+		TotalRateLimit:         opt.Download.TotalRateLimit.Limit,
+		TrafficShaperType:      opt.Download.TrafficShaperType,
+		PriorityTrafficShaper:  opt.Download.PriorityTrafficShaper,
+		PieceSelectionStrategy: opt.Download.PieceSelectionStrategy,
+		EndgamePieces:          opt.Download.EndgamePieces,
+		Multiplex:              opt.Storage.Multiplex,
+		Prefetch:               opt.Download.Prefetch,
+		GetPiecesMaxRetry:      opt.Download.GetPiecesMaxRetry,
+		SplitRunningTasks:      opt.Download.SplitRunningTasks,
 	}
 	peerTaskManager, err := peer.NewPeerTaskManager(peerTaskManagerOption)
 	if err != nil {
@@ -551,6 +562,19 @@ func (cd *clientDaemon) Serve() error {
 		}
 	}
 
+	// prepare s3-compatible object storage service listen
+	var objectStorageS3Listener net.Listener
+	if cd.Option.ObjectStorage.Enable && cd.Option.ObjectStorage.S3.Enable {
+		if cd.Option.ObjectStorage.S3.TCPListen == nil {
+			return errors.New("s3-compatible object storage tcp listen option is empty")
+		}
+		objectStorageS3Listener, _, err = cd.prepareTCPListener(cd.Option.ObjectStorage.S3.ListenOption, true)
+		if err != nil {
+			logger.Errorf("failed to listen for s3-compatible object storage service: %v", err)
+			return err
+		}
+	}
+
 	g := errgroup.Group{}
 	// serve download grpc service
 	g.Go(func() error {
@@ -650,6 +674,21 @@ func (cd *clientDaemon) Serve() error {
 		})
 	}
 
+	// serve s3-compatible object storage service
+	if cd.Option.ObjectStorage.Enable && cd.Option.ObjectStorage.S3.Enable {
+		g.Go(func() error {
+			defer objectStorageS3Listener.Close()
+			logger.Infof("serve s3-compatible object storage service at %s://%s", objectStorageS3Listener.Addr().Network(), objectStorageS3Listener.Addr().String())
+			if err := cd.ObjectStorage.ServeS3(objectStorageS3Listener); err != nil && err != http.ErrServerClosed {
+				logger.Errorf("failed to serve for s3-compatible object storage service: %v", err)
+				return err
+			} else if err == http.ErrServerClosed {
+				logger.Infof("s3-compatible object storage service closed")
+			}
+			return nil
+		})
+	}
+
 	// serve announcer
 	var announcerOptions []announcer.Option
 	if cd.managerClient != nil {
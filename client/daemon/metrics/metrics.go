@@ -43,6 +43,9 @@ const (
 	SeedPeerDownloadTypeBackToSource = "back_to_source"
 )
 
+// TrafficShaperWeightBuckets is the upper bounds of the weight buckets of priority traffic shaper.
+var TrafficShaperWeightBuckets = []float64{1, 2, 4, 8, 16, 32, 64}
+
 var (
 	ProxyRequestCount = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: types.MetricsNamespace,
@@ -162,6 +165,21 @@ var (
 		Name:      "prefetch_task_total",
 		Help:      "Counter of the total prefetched tasks.",
 	})
+
+	TrafficShaperTaskWeight = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.DfdaemonMetricsName,
+		Name:      "traffic_shaper_task_weight",
+		Help:      "Histogram of the weight of the tasks added to priority traffic shaper.",
+		Buckets:   TrafficShaperWeightBuckets,
+	})
+
+	TrafficShaperAllocatedBandwidth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.DfdaemonMetricsName,
+		Name:      "traffic_shaper_allocated_bandwidth",
+		Help:      "Gauge of the total bandwidth allocated to the running tasks in priority traffic shaper, grouped by the weight bucket of tasks.",
+	}, []string{"le"})
 )
 
 func New(addr string) *http.Server {
@@ -33,6 +33,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -82,11 +83,22 @@ const (
 	defaultSignExpireTime = 5 * time.Minute
 )
 
+const (
+	// queryUploads is the query of initiating multipart upload.
+	queryUploads = "uploads"
+
+	// queryUploadID is the query of multipart upload id.
+	queryUploadID = "uploadId"
+)
+
 // ObjectStorage is the interface used for object storage server.
 type ObjectStorage interface {
 	// Started object storage server.
 	Serve(lis net.Listener) error
 
+	// Started S3-compatible server of object storage.
+	ServeS3(lis net.Listener) error
+
 	// Stop object storage server.
 	Stop() error
 }
@@ -94,11 +106,15 @@ type ObjectStorage interface {
 // objectStorage provides object storage function.
 type objectStorage struct {
 	*http.Server
+	s3Server        *http.Server
 	config          *config.DaemonOption
 	dynconfig       config.Dynconfig
 	peerTaskManager peer.TaskManager
 	storageManager  storage.Manager
 	peerIDGenerator peer.IDGenerator
+
+	// newClient returns the client of backend object storage.
+	newClient func(name, region, endpoint, accessKey, secretKey string) (objectstorage.ObjectStorage, error)
 }
 
 // New returns a new ObjectStorage instence.
@@ -109,6 +125,7 @@ func New(cfg *config.DaemonOption, dynconfig config.Dynconfig, peerTaskManager p
 		peerTaskManager: peerTaskManager,
 		storageManager:  storageManager,
 		peerIDGenerator: peer.NewPeerIDGenerator(cfg.Host.AdvertiseIP),
+		newClient:       objectstorage.New,
 	}
 
 	router := o.initRouter(cfg, logDir)
@@ -116,6 +133,12 @@ func New(cfg *config.DaemonOption, dynconfig config.Dynconfig, peerTaskManager p
 		Handler: router,
 	}
 
+	if cfg.ObjectStorage.S3.Enable {
+		o.s3Server = &http.Server{
+			Handler: o.initS3Router(cfg),
+		}
+	}
+
 	return o, nil
 }
 
@@ -124,8 +147,23 @@ func (o *objectStorage) Serve(lis net.Listener) error {
 	return o.Server.Serve(lis)
 }
 
+// Started S3-compatible server of object storage.
+func (o *objectStorage) ServeS3(lis net.Listener) error {
+	if o.s3Server == nil {
+		return errors.New("s3-compatible server is disabled")
+	}
+
+	return o.s3Server.Serve(lis)
+}
+
 // Stop object storage server.
 func (o *objectStorage) Stop() error {
+	if o.s3Server != nil {
+		if err := o.s3Server.Shutdown(context.Background()); err != nil {
+			return err
+		}
+	}
+
 	return o.Server.Shutdown(context.Background())
 }
 
@@ -177,6 +215,7 @@ func (o *objectStorage) initRouter(cfg *config.DaemonOption, logDir string) *gin
 	b.GET(":id/objects/*object_key", o.getObject)
 	b.DELETE(":id/objects/*object_key", o.destroyObject)
 	b.PUT(":id/objects/*object_key", o.putObject)
+	b.POST(":id/objects/*object_key", o.postObject)
 
 	return r
 }
@@ -230,6 +269,11 @@ func (o *objectStorage) headObject(ctx *gin.Context) {
 
 // getObject uses to download object data.
 func (o *objectStorage) getObject(ctx *gin.Context) {
+	if _, ok := ctx.GetQuery(queryUploadID); ok {
+		o.listParts(ctx)
+		return
+	}
+
 	var params ObjectParams
 	if err := ctx.ShouldBindUri(&params); err != nil {
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
@@ -243,9 +287,32 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 	}
 
 	var (
-		bucketName    = params.ID
-		objectKey     = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
-		filter        = query.Filter
+		bucketName = params.ID
+		objectKey  = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
+	)
+
+	reader, attr, _, err := o.getObjectReader(ctx, bucketName, objectKey, query.Filter, ctx.GetHeader(headers.Range))
+	if err != nil {
+		ctx.JSON(statusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	var contentLength int64 = -1
+	if l, ok := attr[headers.ContentLength]; ok {
+		if i, err := strconv.ParseInt(l, 10, 64); err == nil {
+			contentLength = i
+		}
+	}
+
+	logger.Infof("object %s content length is %d and content type is %s", objectKey, contentLength, attr[headers.ContentType])
+	ctx.DataFromReader(http.StatusOK, contentLength, attr[headers.ContentType], reader, nil)
+}
+
+// getObjectReader returns the reader of object downloaded by P2P with the attributes of task
+// and the metadata of object, rangeHeader is the http range header of object if it is not empty.
+func (o *objectStorage) getObjectReader(ctx context.Context, bucketName, objectKey, filter, rangeHeader string) (io.ReadCloser, map[string]string, *objectstorage.ObjectMetadata, error) {
+	var (
 		artifactRange *util.Range
 		ranges        []util.Range
 		err           error
@@ -259,30 +326,25 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 
 	client, err := o.client()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
-		return
+		return nil, nil, nil, err
 	}
 
 	meta, isExist, err := client.GetObjectMetadata(ctx, bucketName, objectKey)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
-		return
+		return nil, nil, nil, err
 	}
 
 	if !isExist {
-		ctx.JSON(http.StatusNotFound, gin.H{"errors": http.StatusText(http.StatusNotFound)})
-		return
+		return nil, nil, nil, newStatusError(http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 	}
 
 	urlMeta.Digest = meta.Digest
 
 	// Parse http range header.
-	rangeHeader := ctx.GetHeader(headers.Range)
 	if len(rangeHeader) > 0 {
-		ranges, err = o.parseRangeHeader(rangeHeader)
+		ranges, err = o.parseRangeHeader(rangeHeader, meta.ContentLength)
 		if err != nil {
-			ctx.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"errors": err.Error()})
-			return
+			return nil, nil, nil, newStatusError(http.StatusRequestedRangeNotSatisfiable, err)
 		}
 		artifactRange = &ranges[0]
 
@@ -296,8 +358,7 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 
 	signURL, err := client.GetSignURL(ctx, bucketName, objectKey, objectstorage.MethodGet, defaultSignExpireTime)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
-		return
+		return nil, nil, nil, err
 	}
 
 	taskID := idgen.TaskID(signURL, urlMeta)
@@ -311,24 +372,19 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 		PeerID:  o.peerIDGenerator.PeerID(),
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
-		return
+		return nil, nil, nil, err
 	}
-	defer reader.Close()
 
-	var contentLength int64 = -1
-	if l, ok := attr[headers.ContentLength]; ok {
-		if i, err := strconv.ParseInt(l, 10, 64); err == nil {
-			contentLength = i
-		}
-	}
-
-	log.Infof("object content length is %d and content type is %s", contentLength, attr[headers.ContentType])
-	ctx.DataFromReader(http.StatusOK, contentLength, attr[headers.ContentType], reader, nil)
+	return reader, attr, meta, nil
 }
 
 // destroyObject uses to delete object data.
 func (o *objectStorage) destroyObject(ctx *gin.Context) {
+	if _, ok := ctx.GetQuery(queryUploadID); ok {
+		o.abortMultipartUpload(ctx)
+		return
+	}
+
 	var params ObjectParams
 	if err := ctx.ShouldBindUri(&params); err != nil {
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
@@ -358,6 +414,11 @@ func (o *objectStorage) destroyObject(ctx *gin.Context) {
 
 // putObject uses to upload object data.
 func (o *objectStorage) putObject(ctx *gin.Context) {
+	if _, ok := ctx.GetQuery(queryUploadID); ok {
+		o.uploadPart(ctx)
+		return
+	}
+
 	var params ObjectParams
 	if err := ctx.ShouldBindUri(&params); err != nil {
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
@@ -376,24 +437,49 @@ func (o *objectStorage) putObject(ctx *gin.Context) {
 		mode        = form.Mode
 		filter      = form.Filter
 		maxReplicas = form.MaxReplicas
-		fileHeader  = form.File
+		file        = newObjectFileFromFileHeader(form.File)
 	)
 
-	client, err := o.client()
-	if err != nil {
+	switch mode {
+	case Ephemeral, WriteBack, AsyncWriteBack:
+	default:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": fmt.Sprintf("unknow mode %d", mode)})
+		return
+	}
+
+	if err := o.importObject(ctx, bucketName, objectKey, filter, mode, maxReplicas, o.md5FromFile(file), file); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
 		return
 	}
 
+	ctx.Status(http.StatusOK)
+	return
+}
+
+// importObject imports object to local storage and announces it to scheduler,
+// then imports object to seed peers and backend by mode. The file is closed when
+// all imports are finished, including the imports running in background.
+func (o *objectStorage) importObject(ctx context.Context, bucketName, objectKey, filter string, mode uint, maxReplicas int, dgst *digest.Digest, file *objectFile) error {
+	var wg sync.WaitGroup
+	defer func() {
+		go func() {
+			wg.Wait()
+			file.Close()
+		}()
+	}()
+
+	client, err := o.client()
+	if err != nil {
+		return err
+	}
+
 	signURL, err := client.GetSignURL(ctx, bucketName, objectKey, objectstorage.MethodGet, defaultSignExpireTime)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
-		return
+		return err
 	}
 
 	// Initialize url meta.
 	urlMeta := &commonv1.UrlMeta{Filter: o.config.ObjectStorage.Filter}
-	dgst := o.md5FromFileHeader(fileHeader)
 	urlMeta.Digest = dgst.String()
 	if filter != "" {
 		urlMeta.Filter = filter
@@ -413,10 +499,9 @@ func (o *objectStorage) putObject(ctx *gin.Context) {
 
 	// Import object to local storage.
 	log.Infof("import object %s to local storage", objectKey)
-	if err := o.importObjectToLocalStorage(ctx, taskID, peerID, fileHeader); err != nil {
+	if err := o.importObjectToLocalStorage(ctx, taskID, peerID, file); err != nil {
 		log.Error(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
-		return
+		return err
 	}
 
 	// Announce peer information to scheduler.
@@ -426,61 +511,291 @@ func (o *objectStorage) putObject(ctx *gin.Context) {
 		PeerID: peerID,
 	}, signURL, commonv1.TaskType_DfStore, urlMeta); err != nil {
 		log.Error(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
-		return
+		return err
 	}
 
 	// Handle task for backend.
 	switch mode {
 	case Ephemeral:
-		ctx.Status(http.StatusOK)
-		return
+		return nil
 	case WriteBack:
 		// Import object to seed peer.
+		wg.Add(1)
 		go func() {
-			if err := o.importObjectToSeedPeers(context.Background(), bucketName, objectKey, urlMeta.Filter, Ephemeral, fileHeader, maxReplicas, log); err != nil {
+			defer wg.Done()
+			if err := o.importObjectToSeedPeers(context.Background(), bucketName, objectKey, urlMeta.Filter, Ephemeral, file, maxReplicas, log); err != nil {
 				log.Errorf("import object %s to seed peers failed: %s", objectKey, err)
 			}
 		}()
 
 		// Import object to object storage.
 		log.Infof("import object %s to bucket %s", objectKey, bucketName)
-		if err := o.importObjectToBackend(ctx, bucketName, objectKey, dgst, fileHeader, client); err != nil {
+		if err := o.importObjectToBackend(ctx, bucketName, objectKey, dgst, file, client); err != nil {
 			log.Error(err)
-			ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
-			return
+			return err
 		}
 
-		ctx.Status(http.StatusOK)
-		return
+		return nil
 	case AsyncWriteBack:
 		// Import object to seed peer.
+		wg.Add(1)
 		go func() {
-			if err := o.importObjectToSeedPeers(context.Background(), bucketName, objectKey, urlMeta.Filter, Ephemeral, fileHeader, maxReplicas, log); err != nil {
+			defer wg.Done()
+			if err := o.importObjectToSeedPeers(context.Background(), bucketName, objectKey, urlMeta.Filter, Ephemeral, file, maxReplicas, log); err != nil {
 				log.Errorf("import object %s to seed peers failed: %s", objectKey, err)
 			}
 		}()
 
 		// Import object to object storage.
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			log.Infof("import object %s to bucket %s", objectKey, bucketName)
-			if err := o.importObjectToBackend(context.Background(), bucketName, objectKey, dgst, fileHeader, client); err != nil {
+			if err := o.importObjectToBackend(context.Background(), bucketName, objectKey, dgst, file, client); err != nil {
 				log.Errorf("import object %s to bucket %s failed: %s", objectKey, bucketName, err.Error())
 				return
 			}
 		}()
 
-		ctx.Status(http.StatusOK)
+		return nil
+	}
+
+	return fmt.Errorf("unknow mode %d", mode)
+}
+
+// postObject uses to initiate or complete multipart upload of object.
+func (o *objectStorage) postObject(ctx *gin.Context) {
+	if _, ok := ctx.GetQuery(queryUploads); ok {
+		o.createMultipartUpload(ctx)
 		return
 	}
 
-	ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": fmt.Sprintf("unknow mode %d", mode)})
-	return
+	if _, ok := ctx.GetQuery(queryUploadID); ok {
+		o.completeMultipartUpload(ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": fmt.Sprintf("query %s or %s is required", queryUploads, queryUploadID)})
+}
+
+// createMultipartUpload uses to initiate multipart upload, the parts are written
+// to the backend directly and the object is distributed by P2P when it is downloaded.
+func (o *objectStorage) createMultipartUpload(ctx *gin.Context) {
+	var params ObjectParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query CreateMultipartUploadQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var (
+		bucketName = params.ID
+		objectKey  = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
+	)
+
+	// Digest is used to verify the object downloaded by P2P.
+	if query.Digest != "" {
+		if _, err := digest.Parse(query.Digest); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+	}
+
+	client, err := o.client()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	uploadID, err := client.CreateMultipartUpload(ctx, bucketName, objectKey, query.Digest)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	logger.Infof("create multipart upload %s of object %s in bucket %s", uploadID, objectKey, bucketName)
+	ctx.JSON(http.StatusOK, CreateMultipartUploadResponse{UploadID: uploadID})
+}
+
+// uploadPart uses to upload part of multipart upload, the body of request is data of part.
+func (o *objectStorage) uploadPart(ctx *gin.Context) {
+	var params ObjectParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query UploadPartQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	// Object storage requires the size of part.
+	if ctx.Request.ContentLength <= 0 {
+		ctx.JSON(http.StatusLengthRequired, gin.H{"errors": http.StatusText(http.StatusLengthRequired)})
+		return
+	}
+
+	var (
+		bucketName = params.ID
+		objectKey  = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
+	)
+
+	client, err := o.client()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	part, err := client.UploadPart(ctx, bucketName, objectKey, query.UploadID, query.PartNumber, ctx.Request.ContentLength, ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	ctx.Header(headers.ETag, part.ETag)
+	ctx.JSON(http.StatusOK, Part{
+		PartNumber: part.PartNumber,
+		ETag:       part.ETag,
+		Size:       part.Size,
+	})
 }
 
-// getAvailableSeedPeer uses to calculate md5 with file header.
-func (o *objectStorage) md5FromFileHeader(fileHeader *multipart.FileHeader) *digest.Digest {
-	f, err := fileHeader.Open()
+// completeMultipartUpload uses to complete multipart upload with the uploaded parts.
+func (o *objectStorage) completeMultipartUpload(ctx *gin.Context) {
+	var params ObjectParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query MultipartUploadQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var json CompleteMultipartUploadRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var (
+		bucketName = params.ID
+		objectKey  = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
+		parts      []*objectstorage.PartMetadata
+	)
+
+	for _, part := range json.Parts {
+		parts = append(parts, &objectstorage.PartMetadata{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+			Size:       part.Size,
+		})
+	}
+
+	client, err := o.client()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	logger.Infof("complete multipart upload %s of object %s in bucket %s with %d parts", query.UploadID, objectKey, bucketName, len(parts))
+	if err := client.CompleteMultipartUpload(ctx, bucketName, objectKey, query.UploadID, parts); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// abortMultipartUpload uses to abort multipart upload and delete the uploaded parts.
+func (o *objectStorage) abortMultipartUpload(ctx *gin.Context) {
+	var params ObjectParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query MultipartUploadQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var (
+		bucketName = params.ID
+		objectKey  = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
+	)
+
+	client, err := o.client()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	logger.Infof("abort multipart upload %s of object %s in bucket %s", query.UploadID, objectKey, bucketName)
+	if err := client.AbortMultipartUpload(ctx, bucketName, objectKey, query.UploadID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// listParts uses to list the uploaded parts of multipart upload.
+func (o *objectStorage) listParts(ctx *gin.Context) {
+	var params ObjectParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query MultipartUploadQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var (
+		bucketName = params.ID
+		objectKey  = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
+	)
+
+	client, err := o.client()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	metadatas, err := client.ListParts(ctx, bucketName, objectKey, query.UploadID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	parts := []Part{}
+	for _, metadata := range metadatas {
+		parts = append(parts, Part{
+			PartNumber: metadata.PartNumber,
+			ETag:       metadata.ETag,
+			Size:       metadata.Size,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, parts)
+}
+
+// md5FromFile uses to calculate md5 with object file.
+func (o *objectStorage) md5FromFile(file *objectFile) *digest.Digest {
+	f, err := file.Open()
 	if err != nil {
 		return nil
 	}
@@ -490,8 +805,8 @@ func (o *objectStorage) md5FromFileHeader(fileHeader *multipart.FileHeader) *dig
 }
 
 // importObjectToBackend uses to import object to backend.
-func (o *objectStorage) importObjectToBackend(ctx context.Context, bucketName, objectKey string, dgst *digest.Digest, fileHeader *multipart.FileHeader, client objectstorage.ObjectStorage) error {
-	f, err := fileHeader.Open()
+func (o *objectStorage) importObjectToBackend(ctx context.Context, bucketName, objectKey string, dgst *digest.Digest, file *objectFile, client objectstorage.ObjectStorage) error {
+	f, err := file.Open()
 	if err != nil {
 		return err
 	}
@@ -504,8 +819,8 @@ func (o *objectStorage) importObjectToBackend(ctx context.Context, bucketName, o
 }
 
 // importObjectToSeedPeers uses to import object to local storage.
-func (o *objectStorage) importObjectToLocalStorage(ctx context.Context, taskID, peerID string, fileHeader *multipart.FileHeader) error {
-	f, err := fileHeader.Open()
+func (o *objectStorage) importObjectToLocalStorage(ctx context.Context, taskID, peerID string, file *objectFile) error {
+	f, err := file.Open()
 	if err != nil {
 		return nil
 	}
@@ -525,7 +840,7 @@ func (o *objectStorage) importObjectToLocalStorage(ctx context.Context, taskID,
 	}
 
 	// Import task data to dfdaemon.
-	if err := o.peerTaskManager.GetPieceManager().Import(ctx, meta, tsd, fileHeader.Size, f); err != nil {
+	if err := o.peerTaskManager.GetPieceManager().Import(ctx, meta, tsd, file.Size, f); err != nil {
 		return err
 	}
 
@@ -533,7 +848,7 @@ func (o *objectStorage) importObjectToLocalStorage(ctx context.Context, taskID,
 }
 
 // importObjectToSeedPeers uses to import object to available seed peers.
-func (o *objectStorage) importObjectToSeedPeers(ctx context.Context, bucketName, objectKey, filter string, mode int, fileHeader *multipart.FileHeader, maxReplicas int, log *logger.SugaredLoggerOnWith) error {
+func (o *objectStorage) importObjectToSeedPeers(ctx context.Context, bucketName, objectKey, filter string, mode int, file *objectFile, maxReplicas int, log *logger.SugaredLoggerOnWith) error {
 	schedulers, err := o.dynconfig.GetSchedulers()
 	if err != nil {
 		return err
@@ -552,7 +867,7 @@ func (o *objectStorage) importObjectToSeedPeers(ctx context.Context, bucketName,
 	var replicas int
 	for _, seedPeerHost := range seedPeerHosts {
 		log.Infof("import object %s to seed peer %s", objectKey, seedPeerHost)
-		if err := o.importObjectToSeedPeer(ctx, seedPeerHost, bucketName, objectKey, filter, mode, fileHeader); err != nil {
+		if err := o.importObjectToSeedPeer(ctx, seedPeerHost, bucketName, objectKey, filter, mode, file); err != nil {
 			log.Errorf("import object %s to seed peer %s failed: %s", objectKey, seedPeerHost, err)
 			continue
 		}
@@ -568,8 +883,8 @@ func (o *objectStorage) importObjectToSeedPeers(ctx context.Context, bucketName,
 }
 
 // importObjectToSeedPeer uses to import object to seed peer.
-func (o *objectStorage) importObjectToSeedPeer(ctx context.Context, seedPeerHost, bucketName, objectKey, filter string, mode int, fileHeader *multipart.FileHeader) error {
-	f, err := fileHeader.Open()
+func (o *objectStorage) importObjectToSeedPeer(ctx context.Context, seedPeerHost, bucketName, objectKey, filter string, mode int, file *objectFile) error {
+	f, err := file.Open()
 	if err != nil {
 		return err
 	}
@@ -588,7 +903,7 @@ func (o *objectStorage) importObjectToSeedPeer(ctx context.Context, seedPeerHost
 		}
 	}
 
-	part, err := writer.CreateFormFile("file", fileHeader.Filename)
+	part, err := writer.CreateFormFile("file", file.Filename)
 	if err != nil {
 		return err
 	}
@@ -626,6 +941,75 @@ func (o *objectStorage) importObjectToSeedPeer(ctx context.Context, seedPeerHost
 	return nil
 }
 
+// objectFile is the uploaded data of object, it is opened by each import of object.
+type objectFile struct {
+	// Filename is the file name of object.
+	Filename string
+
+	// Size is the size of object.
+	Size int64
+
+	// open opens the data of object.
+	open func() (multipart.File, error)
+
+	// close releases the data of object, it is nil if nothing need to be released.
+	close func() error
+}
+
+// newObjectFileFromFileHeader returns the object file of multipart form file.
+func newObjectFileFromFileHeader(fileHeader *multipart.FileHeader) *objectFile {
+	return &objectFile{
+		Filename: fileHeader.Filename,
+		Size:     fileHeader.Size,
+		open:     fileHeader.Open,
+	}
+}
+
+// Open opens the data of object.
+func (f *objectFile) Open() (multipart.File, error) {
+	return f.open()
+}
+
+// Close releases the data of object.
+func (f *objectFile) Close() error {
+	if f.close == nil {
+		return nil
+	}
+
+	return f.close()
+}
+
+// statusError is the error with http status code.
+type statusError struct {
+	code int
+	err  error
+}
+
+// newStatusError returns a new error with http status code.
+func newStatusError(code int, err error) error {
+	return &statusError{code: code, err: err}
+}
+
+// Error returns the message of error.
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// statusCode returns http status code of error, the default code is http.StatusInternalServerError.
+func statusCode(err error) int {
+	var serr *statusError
+	if errors.As(err, &serr) {
+		return serr.code
+	}
+
+	return http.StatusInternalServerError
+}
+
 // client uses to generate client of object storage.
 func (o *objectStorage) client() (objectstorage.ObjectStorage, error) {
 	config, err := o.dynconfig.GetObjectStorage()
@@ -633,7 +1017,7 @@ func (o *objectStorage) client() (objectstorage.ObjectStorage, error) {
 		return nil, err
 	}
 
-	client, err := objectstorage.New(config.Name, config.Region, config.Endpoint, config.AccessKey, config.SecretKey)
+	client, err := o.newClient(config.Name, config.Region, config.Endpoint, config.AccessKey, config.SecretKey)
 	if err != nil {
 		return nil, err
 	}
@@ -641,9 +1025,14 @@ func (o *objectStorage) client() (objectstorage.ObjectStorage, error) {
 	return client, nil
 }
 
-// parseRangeHeader uses to parse range http header for dragonfly.
-func (o *objectStorage) parseRangeHeader(rangeHeader string) ([]util.Range, error) {
-	ranges, err := util.ParseRange(rangeHeader, math.MaxInt64)
+// parseRangeHeader uses to parse range http header for dragonfly,
+// size is the size of object and it is unlimited if size is not positive.
+func (o *objectStorage) parseRangeHeader(rangeHeader string, size int64) ([]util.Range, error) {
+	if size <= 0 {
+		size = math.MaxInt64
+	}
+
+	ranges, err := util.ParseRange(rangeHeader, size)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,386 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-http-utils/headers"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/peer"
+	storagemocks "d7y.io/dragonfly/v2/client/daemon/storage/mocks"
+	"d7y.io/dragonfly/v2/pkg/objectstorage"
+	objectstoragemocks "d7y.io/dragonfly/v2/pkg/objectstorage/mocks"
+)
+
+// runGatewayTests serves the requests of tests by object storage gateway router.
+func runGatewayTests(t *testing.T, tests []objectStorageTest) {
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			o, client, peerTaskManager, pieceManager, storageManager := newTestObjectStorage(t, ctl)
+			tc.mock(client.EXPECT(), peerTaskManager.EXPECT(), pieceManager.EXPECT(), storageManager.EXPECT())
+
+			req := httptest.NewRequest(tc.method, "http://127.0.0.1:65004"+tc.target, bytes.NewReader(tc.body))
+			for key, values := range tc.header {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			o.initRouter(o.config, t.TempDir()).ServeHTTP(w, req)
+			tc.expect(t, w, o)
+		})
+	}
+}
+
+// newMultipartForm returns the multipart form of putting object and its content type.
+func newMultipartForm(t *testing.T, fields map[string]string, data []byte) ([]byte, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		if err := writer.WriteField(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	part, err := writer.CreateFormFile("file", "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := part.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return body.Bytes(), writer.FormDataContentType()
+}
+
+// decodeGatewayResponse decodes the json response of object storage gateway.
+func decodeGatewayResponse(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObjectStorage_Object(t *testing.T) {
+	form, contentType := newMultipartForm(t, map[string]string{"mode": fmt.Sprint(Ephemeral)}, mockContent)
+	invalidForm, invalidContentType := newMultipartForm(t, map[string]string{"mode": "3"}, mockContent)
+	runGatewayTests(t, []objectStorageTest{
+		{
+			name:   "head object",
+			method: http.MethodHead,
+			target: "/buckets/bucket/objects/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(&objectstorage.ObjectMetadata{
+					ContentLength: 10,
+					ETag:          `"etag"`,
+					Digest:        "md5:foo",
+				}, true, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal("10", w.Header().Get(headers.ContentLength))
+				assert.Equal(`"etag"`, w.Header().Get(headers.ETag))
+				assert.Equal("md5:foo", w.Header().Get(config.HeaderDragonflyObjectMetaDigest))
+			},
+		},
+		{
+			name:   "head object which does not exist",
+			method: http.MethodHead,
+			target: "/buckets/bucket/objects/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(nil, false, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, w.Code)
+			},
+		},
+		{
+			name:   "get object",
+			method: http.MethodGet,
+			target: "/buckets/bucket/objects/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				gomock.InOrder(
+					mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(&objectstorage.ObjectMetadata{ContentLength: 5}, true, nil).Times(1),
+					mc.GetSignURL(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(objectstorage.MethodGet), gomock.Eq(defaultSignExpireTime)).Return(mockSignURL, nil).Times(1),
+					mp.StartStreamTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *peer.StreamTaskRequest) (io.ReadCloser, map[string]string, error) {
+						assert.Equal(t, mockSignURL, req.URL)
+						return io.NopCloser(bytes.NewReader(mockContent)), map[string]string{headers.ContentLength: "5"}, nil
+					}).Times(1),
+				)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal(mockContent, w.Body.Bytes())
+			},
+		},
+		{
+			name:   "get object which does not exist",
+			method: http.MethodGet,
+			target: "/buckets/bucket/objects/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(nil, false, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, w.Code)
+			},
+		},
+		{
+			name:   "put object",
+			method: http.MethodPut,
+			target: "/buckets/bucket/objects/dir/a.txt",
+			header: http.Header{headers.ContentType: []string{contentType}},
+			body:   form,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				gomock.InOrder(
+					mc.GetSignURL(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(objectstorage.MethodGet), gomock.Eq(defaultSignExpireTime)).Return(mockSignURL, nil).Times(1),
+					ms.RegisterTask(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1),
+					mpm.Import(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq(int64(len(mockContent))), gomock.Any()).Return(nil).Times(1),
+					mp.AnnouncePeerTask(gomock.Any(), gomock.Any(), gomock.Eq(mockSignURL), gomock.Any(), gomock.Any()).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+			},
+		},
+		{
+			name:   "put object with invalid mode",
+			method: http.MethodPut,
+			target: "/buckets/bucket/objects/dir/a.txt",
+			header: http.Header{headers.ContentType: []string{invalidContentType}},
+			body:   invalidForm,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name:   "destroy object",
+			method: http.MethodDelete,
+			target: "/buckets/bucket/objects/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.DeleteObject(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+			},
+		},
+		{
+			name:   "post object without multipart upload query",
+			method: http.MethodPost,
+			target: "/buckets/bucket/objects/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+	})
+}
+
+func TestObjectStorage_MultipartUpload(t *testing.T) {
+	runGatewayTests(t, []objectStorageTest{
+		{
+			name:   "create multipart upload",
+			method: http.MethodPost,
+			target: "/buckets/bucket/objects/dir/a.txt?uploads&digest=md5:5d41402abc4b2a76b9719d911017c592",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.CreateMultipartUpload(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq("md5:5d41402abc4b2a76b9719d911017c592")).Return(mockUploadID, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var resp CreateMultipartUploadResponse
+				decodeGatewayResponse(t, w, &resp)
+				assert.Equal(mockUploadID, resp.UploadID)
+			},
+		},
+		{
+			name:   "create multipart upload with invalid digest",
+			method: http.MethodPost,
+			target: "/buckets/bucket/objects/dir/a.txt?uploads&digest=md5:foo:bar",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name:   "upload part",
+			method: http.MethodPut,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=foo&partNumber=1",
+			body:   mockContent,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.UploadPart(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID), gomock.Eq(int64(1)), gomock.Eq(int64(len(mockContent))), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, bucketName, objectKey, uploadID string, partNumber, size int64, reader io.Reader) (*objectstorage.PartMetadata, error) {
+						data, err := io.ReadAll(reader)
+						assert.NoError(t, err)
+						assert.Equal(t, mockContent, data)
+						return &objectstorage.PartMetadata{PartNumber: partNumber, ETag: `"etag1"`, Size: size}, nil
+					}).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal(`"etag1"`, w.Header().Get(headers.ETag))
+
+				var part Part
+				decodeGatewayResponse(t, w, &part)
+				assert.Equal(Part{PartNumber: 1, ETag: `"etag1"`, Size: int64(len(mockContent))}, part)
+			},
+		},
+		{
+			name:   "upload part without part number",
+			method: http.MethodPut,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=foo",
+			body:   mockContent,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name:   "upload part without content",
+			method: http.MethodPut,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=foo&partNumber=1",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusLengthRequired, w.Code)
+			},
+		},
+		{
+			name:   "complete multipart upload",
+			method: http.MethodPost,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=foo",
+			header: http.Header{headers.ContentType: []string{"application/json"}},
+			body:   []byte(`{"parts":[{"partNumber":2,"etag":"\"etag2\"","size":5},{"partNumber":1,"etag":"\"etag1\"","size":5}]}`),
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.CompleteMultipartUpload(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID), gomock.Eq([]*objectstorage.PartMetadata{
+					{PartNumber: 2, ETag: `"etag2"`, Size: 5},
+					{PartNumber: 1, ETag: `"etag1"`, Size: 5},
+				})).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+			},
+		},
+		{
+			name:   "complete multipart upload without parts",
+			method: http.MethodPost,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=foo",
+			header: http.Header{headers.ContentType: []string{"application/json"}},
+			body:   []byte(`{"parts":[]}`),
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name:   "complete multipart upload failed",
+			method: http.MethodPost,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=foo",
+			header: http.Header{headers.ContentType: []string{"application/json"}},
+			body:   []byte(`{"parts":[{"partNumber":1,"etag":"\"etag1\""}]}`),
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.CompleteMultipartUpload(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID), gomock.Any()).Return(errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, w.Code)
+				assert.Contains(w.Body.String(), "foo")
+			},
+		},
+		{
+			name:   "abort multipart upload",
+			method: http.MethodDelete,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=foo",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.AbortMultipartUpload(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID)).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+			},
+		},
+		{
+			name:   "list parts",
+			method: http.MethodGet,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=foo",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.ListParts(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID)).Return([]*objectstorage.PartMetadata{
+					{PartNumber: 1, ETag: `"etag1"`, Size: 5},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var parts []Part
+				decodeGatewayResponse(t, w, &parts)
+				assert.Equal([]Part{{PartNumber: 1, ETag: `"etag1"`, Size: 5}}, parts)
+			},
+		},
+		{
+			name:   "list parts without upload id",
+			method: http.MethodGet,
+			target: "/buckets/bucket/objects/dir/a.txt?uploadId=",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+	})
+}
@@ -0,0 +1,815 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-http-utils/headers"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/objectstorage"
+)
+
+const (
+	// s3XMLNamespace is the xml namespace of S3 responses.
+	s3XMLNamespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+	// s3TimeFormat is the time format in S3 xml responses.
+	s3TimeFormat = "2006-01-02T15:04:05.000Z"
+
+	// s3MaxKeys is the max keys of listing objects.
+	s3MaxKeys = 1000
+
+	// s3StorageClass is the storage class of objects.
+	s3StorageClass = "STANDARD"
+)
+
+const (
+	// headerAmzCopySource is the header of copying object, copy is not supported.
+	headerAmzCopySource = "X-Amz-Copy-Source"
+
+	// headerContentMD5 is the base64 encoded md5 of request body.
+	headerContentMD5 = "Content-MD5"
+)
+
+// s3Error is the error of S3-compatible API.
+type s3Error struct {
+	// Code is the error code of S3.
+	Code string
+
+	// Message is the description of error.
+	Message string
+
+	// StatusCode is the http status code.
+	StatusCode int
+}
+
+// Error returns the message of error.
+func (e *s3Error) Error() string {
+	return e.Message
+}
+
+var (
+	errS3AccessDenied                      = &s3Error{"AccessDenied", "Access Denied.", http.StatusForbidden}
+	errS3AuthorizationHeaderMalformed      = &s3Error{"AuthorizationHeaderMalformed", "The authorization header is malformed.", http.StatusBadRequest}
+	errS3AuthorizationQueryParametersError = &s3Error{"AuthorizationQueryParametersError", "The authorization query parameters are malformed.", http.StatusBadRequest}
+	errS3BadDigest                         = &s3Error{"BadDigest", "The Content-MD5 you specified did not match what we received.", http.StatusBadRequest}
+	errS3ContentSHA256Mismatch             = &s3Error{"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", http.StatusBadRequest}
+	errS3ExpiredToken                      = &s3Error{"AccessDenied", "Request has expired.", http.StatusForbidden}
+	errS3IncompleteBody                    = &s3Error{"IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.", http.StatusBadRequest}
+	errS3InternalError                     = &s3Error{"InternalError", "We encountered an internal error, please try again.", http.StatusInternalServerError}
+	errS3InvalidAccessKeyID                = &s3Error{"InvalidAccessKeyId", "The access key Id you provided does not exist in our records.", http.StatusForbidden}
+	errS3InvalidArgument                   = &s3Error{"InvalidArgument", "Invalid argument.", http.StatusBadRequest}
+	errS3InvalidPart                       = &s3Error{"InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest}
+	errS3InvalidRange                      = &s3Error{"InvalidRange", "The requested range is not satisfiable.", http.StatusRequestedRangeNotSatisfiable}
+	errS3InvalidRequest                    = &s3Error{"InvalidRequest", "Invalid request.", http.StatusBadRequest}
+	errS3MalformedXML                      = &s3Error{"MalformedXML", "The XML you provided was not well-formed.", http.StatusBadRequest}
+	errS3MissingContentLength              = &s3Error{"MissingContentLength", "You must provide the Content-Length HTTP header.", http.StatusLengthRequired}
+	errS3NoSuchBucket                      = &s3Error{"NoSuchBucket", "The specified bucket does not exist.", http.StatusNotFound}
+	errS3NoSuchKey                         = &s3Error{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	errS3NotImplemented                    = &s3Error{"NotImplemented", "A header or query you provided implies functionality that is not implemented.", http.StatusNotImplemented}
+	errS3RequestTimeTooSkewed              = &s3Error{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.", http.StatusForbidden}
+	errS3SignatureDoesNotMatch             = &s3Error{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+	errS3SignatureVersionNotSupported      = &s3Error{"InvalidRequest", "The authorization mechanism you have provided is not supported, please use AWS4-HMAC-SHA256.", http.StatusBadRequest}
+)
+
+// S3ErrorResponse is the error response of S3-compatible API.
+type S3ErrorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+// S3Owner is the owner of buckets.
+type S3Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+// S3Bucket is the bucket in ListBuckets response.
+type S3Bucket struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+// S3ListAllMyBucketsResult is the response of ListBuckets.
+type S3ListAllMyBucketsResult struct {
+	XMLName xml.Name   `xml:"ListAllMyBucketsResult"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Owner   S3Owner    `xml:"Owner"`
+	Buckets []S3Bucket `xml:"Buckets>Bucket"`
+}
+
+// S3Object is the object in ListObjectsV2 response.
+type S3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// S3CommonPrefix is the common prefix in ListObjectsV2 response.
+type S3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// S3ListBucketResult is the response of ListObjectsV2.
+type S3ListBucketResult struct {
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	Xmlns                 string           `xml:"xmlns,attr"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	EncodingType          string           `xml:"EncodingType,omitempty"`
+	MaxKeys               int64            `xml:"MaxKeys"`
+	KeyCount              int              `xml:"KeyCount"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	Contents              []S3Object       `xml:"Contents"`
+	CommonPrefixes        []S3CommonPrefix `xml:"CommonPrefixes"`
+}
+
+// S3InitiateMultipartUploadResult is the response of CreateMultipartUpload.
+type S3InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+// S3Part is the part of multipart upload.
+type S3Part struct {
+	PartNumber int64  `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+	Size       int64  `xml:"Size,omitempty"`
+}
+
+// S3CompleteMultipartUpload is the request of CompleteMultipartUpload.
+type S3CompleteMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []S3Part `xml:"Part"`
+}
+
+// S3CompleteMultipartUploadResult is the response of CompleteMultipartUpload.
+type S3CompleteMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// S3ListPartsResult is the response of ListParts.
+type S3ListPartsResult struct {
+	XMLName     xml.Name `xml:"ListPartsResult"`
+	Xmlns       string   `xml:"xmlns,attr"`
+	Bucket      string   `xml:"Bucket"`
+	Key         string   `xml:"Key"`
+	UploadID    string   `xml:"UploadId"`
+	IsTruncated bool     `xml:"IsTruncated"`
+	Parts       []S3Part `xml:"Part"`
+}
+
+// initS3Router initializes router of S3-compatible API, the requests are path-style
+// and signed by AWS signature version 4 with the configured credentials.
+func (o *objectStorage) initS3Router(cfg *config.DaemonOption) *gin.Engine {
+	r := gin.New()
+
+	// Middleware
+	r.Use(gin.Logger())
+	r.Use(gin.Recovery())
+
+	// Opentelemetry
+	if cfg.Options.Telemetry.Jaeger != "" {
+		r.Use(otelgin.Middleware(OtelServiceName))
+	}
+
+	// Authentication
+	r.Use(s3Authenticate(newSignV4Verifier(cfg.ObjectStorage.S3.Credentials)))
+
+	// Buckets
+	r.GET("/", o.s3ListBuckets)
+	r.HEAD("/:bucket", o.s3HeadBucket)
+	r.GET("/:bucket", o.s3GetBucket)
+
+	// Objects
+	r.HEAD("/:bucket/*key", o.s3HeadObject)
+	r.GET("/:bucket/*key", o.s3GetObject)
+	r.PUT("/:bucket/*key", o.s3PutObject)
+	r.DELETE("/:bucket/*key", o.s3DeleteObject)
+	r.POST("/:bucket/*key", o.s3PostObject)
+
+	r.NoRoute(func(ctx *gin.Context) {
+		s3ErrorResponse(ctx, errS3NotImplemented)
+	})
+
+	return r
+}
+
+// s3Authenticate verifies the signature of requests.
+func s3Authenticate(verifier *signV4Verifier) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := verifier.Verify(ctx.Request); err != nil {
+			s3ErrorResponse(ctx, err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// s3ErrorResponse writes the error response of S3-compatible API, the errors of
+// object storage gateway are converted to S3 errors by http status code.
+func s3ErrorResponse(ctx *gin.Context, err error) {
+	var serr *s3Error
+	if !errors.As(err, &serr) {
+		switch statusCode(err) {
+		case http.StatusNotFound:
+			serr = errS3NoSuchKey
+		case http.StatusRequestedRangeNotSatisfiable:
+			serr = errS3InvalidRange
+		default:
+			logger.Errorf("s3 request %s %s failed: %s", ctx.Request.Method, ctx.Request.URL.Path, err)
+			serr = errS3InternalError
+		}
+	}
+
+	if ctx.Request.Method == http.MethodHead {
+		ctx.Status(serr.StatusCode)
+		return
+	}
+
+	ctx.XML(serr.StatusCode, S3ErrorResponse{
+		Code:     serr.Code,
+		Message:  serr.Message,
+		Resource: ctx.Request.URL.Path,
+	})
+}
+
+// s3ObjectKey returns the object key of request, it is empty if the request is for bucket.
+func s3ObjectKey(ctx *gin.Context) string {
+	return strings.TrimPrefix(ctx.Param("key"), "/")
+}
+
+// s3ListBuckets lists buckets.
+func (o *objectStorage) s3ListBuckets(ctx *gin.Context) {
+	client, err := o.client()
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	bucketMetadatas, err := client.ListBucketMetadatas(ctx)
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	result := S3ListAllMyBucketsResult{Xmlns: s3XMLNamespace}
+	for _, bucketMetadata := range bucketMetadatas {
+		result.Buckets = append(result.Buckets, S3Bucket{
+			Name:         bucketMetadata.Name,
+			CreationDate: bucketMetadata.CreateAt.UTC().Format(s3TimeFormat),
+		})
+	}
+
+	ctx.XML(http.StatusOK, result)
+}
+
+// s3HeadBucket checks whether the bucket exists.
+func (o *objectStorage) s3HeadBucket(ctx *gin.Context) {
+	client, err := o.client()
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	isExist, err := client.IsBucketExist(ctx, ctx.Param("bucket"))
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	if !isExist {
+		s3ErrorResponse(ctx, errS3NoSuchBucket)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// s3GetBucket lists objects of bucket by ListObjectsV2.
+func (o *objectStorage) s3GetBucket(ctx *gin.Context) {
+	if ctx.Query("list-type") != "2" {
+		s3ErrorResponse(ctx, errS3NotImplemented)
+		return
+	}
+
+	var (
+		bucketName   = ctx.Param("bucket")
+		prefix       = ctx.Query("prefix")
+		delimiter    = ctx.Query("delimiter")
+		startAfter   = ctx.Query("start-after")
+		encodingType = ctx.Query("encoding-type")
+		token        = ctx.Query("continuation-token")
+		maxKeys      = int64(s3MaxKeys)
+		marker       = startAfter
+	)
+
+	if encodingType != "" && encodingType != "url" {
+		s3ErrorResponse(ctx, errS3InvalidArgument)
+		return
+	}
+
+	if value, ok := ctx.GetQuery("max-keys"); ok {
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || i < 0 {
+			s3ErrorResponse(ctx, errS3InvalidArgument)
+			return
+		}
+
+		if i < maxKeys {
+			maxKeys = i
+		}
+	}
+
+	// The continuation token is the opaque marker of next listing.
+	if token != "" {
+		b, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			s3ErrorResponse(ctx, errS3InvalidArgument)
+			return
+		}
+		marker = string(b)
+	}
+
+	result := S3ListBucketResult{
+		Xmlns:             s3XMLNamespace,
+		Name:              bucketName,
+		Prefix:            prefix,
+		Delimiter:         delimiter,
+		StartAfter:        startAfter,
+		ContinuationToken: token,
+		EncodingType:      encodingType,
+		MaxKeys:           maxKeys,
+	}
+
+	if maxKeys > 0 {
+		client, err := o.client()
+		if err != nil {
+			s3ErrorResponse(ctx, err)
+			return
+		}
+
+		objectMetadatas, err := client.ListObjectMetadatas(ctx, bucketName, prefix, marker, delimiter, maxKeys)
+		if err != nil {
+			s3ErrorResponse(ctx, err)
+			return
+		}
+
+		for _, objectMetadata := range objectMetadatas.Metadatas {
+			result.Contents = append(result.Contents, S3Object{
+				Key:          objectMetadata.Key,
+				LastModified: objectMetadata.LastModified.UTC().Format(s3TimeFormat),
+				ETag:         objectMetadata.ETag,
+				Size:         objectMetadata.ContentLength,
+				StorageClass: s3StorageClass,
+			})
+		}
+
+		for _, commonPrefix := range objectMetadatas.CommonPrefixes {
+			result.CommonPrefixes = append(result.CommonPrefixes, S3CommonPrefix{Prefix: commonPrefix})
+		}
+
+		result.IsTruncated = objectMetadatas.IsTruncated
+		if objectMetadatas.IsTruncated && objectMetadatas.NextMarker != "" {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(objectMetadatas.NextMarker))
+		}
+	}
+	result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+
+	// Keys in response are url encoded when encoding type is url.
+	if encodingType == "url" {
+		result.Prefix = s3EncodeKey(result.Prefix)
+		result.Delimiter = s3EncodeKey(result.Delimiter)
+		result.StartAfter = s3EncodeKey(result.StartAfter)
+		for i := range result.Contents {
+			result.Contents[i].Key = s3EncodeKey(result.Contents[i].Key)
+		}
+
+		for i := range result.CommonPrefixes {
+			result.CommonPrefixes[i].Prefix = s3EncodeKey(result.CommonPrefixes[i].Prefix)
+		}
+	}
+
+	ctx.XML(http.StatusOK, result)
+}
+
+// s3EncodeKey encodes the key of listing when encoding type is url.
+func s3EncodeKey(key string) string {
+	return signV4Escape(key, false)
+}
+
+// s3HeadObject returns metadata of object.
+func (o *objectStorage) s3HeadObject(ctx *gin.Context) {
+	bucketName, objectKey := ctx.Param("bucket"), s3ObjectKey(ctx)
+	if objectKey == "" {
+		o.s3HeadBucket(ctx)
+		return
+	}
+
+	client, err := o.client()
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	meta, isExist, err := client.GetObjectMetadata(ctx, bucketName, objectKey)
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	if !isExist {
+		s3ErrorResponse(ctx, errS3NoSuchKey)
+		return
+	}
+
+	s3SetObjectHeaders(ctx, meta)
+	ctx.Header(headers.ContentLength, strconv.FormatInt(meta.ContentLength, 10))
+	ctx.Status(http.StatusOK)
+}
+
+// s3GetObject downloads object by P2P.
+func (o *objectStorage) s3GetObject(ctx *gin.Context) {
+	bucketName, objectKey := ctx.Param("bucket"), s3ObjectKey(ctx)
+	if objectKey == "" {
+		o.s3GetBucket(ctx)
+		return
+	}
+
+	if _, ok := ctx.GetQuery(queryUploadID); ok {
+		o.s3ListParts(ctx)
+		return
+	}
+
+	rangeHeader := ctx.GetHeader(headers.Range)
+	reader, attr, meta, err := o.getObjectReader(ctx, bucketName, objectKey, "", rangeHeader)
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+	defer reader.Close()
+
+	contentType := meta.ContentType
+	if contentType == "" {
+		contentType = attr[headers.ContentType]
+	}
+
+	s3SetObjectHeaders(ctx, meta)
+	if rangeHeader == "" {
+		ctx.DataFromReader(http.StatusOK, meta.ContentLength, contentType, reader, nil)
+		return
+	}
+
+	ranges, err := o.parseRangeHeader(rangeHeader, meta.ContentLength)
+	if err != nil {
+		s3ErrorResponse(ctx, errS3InvalidRange)
+		return
+	}
+
+	r := ranges[0]
+	ctx.DataFromReader(http.StatusPartialContent, r.Length, contentType, reader, map[string]string{
+		headers.ContentRange: fmt.Sprintf("bytes %d-%d/%d", r.Start, r.Start+r.Length-1, meta.ContentLength),
+	})
+}
+
+// s3SetObjectHeaders sets the headers of object metadata.
+func s3SetObjectHeaders(ctx *gin.Context, meta *objectstorage.ObjectMetadata) {
+	ctx.Header(headers.AcceptRanges, "bytes")
+	if meta.ETag != "" {
+		ctx.Header(headers.ETag, meta.ETag)
+	}
+
+	if !meta.LastModified.IsZero() {
+		ctx.Header(headers.LastModified, meta.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if meta.ContentType != "" {
+		ctx.Header(headers.ContentType, meta.ContentType)
+	}
+
+	if meta.ContentEncoding != "" {
+		ctx.Header(headers.ContentEncoding, meta.ContentEncoding)
+	}
+
+	if meta.ContentDisposition != "" {
+		ctx.Header(headers.ContentDisposition, meta.ContentDisposition)
+	}
+
+	if meta.ContentLanguage != "" {
+		ctx.Header(headers.ContentLanguage, meta.ContentLanguage)
+	}
+}
+
+// s3PutObject uploads object, the object is imported to P2P network and written back to backend.
+func (o *objectStorage) s3PutObject(ctx *gin.Context) {
+	bucketName, objectKey := ctx.Param("bucket"), s3ObjectKey(ctx)
+	if objectKey == "" || ctx.GetHeader(headerAmzCopySource) != "" {
+		s3ErrorResponse(ctx, errS3NotImplemented)
+		return
+	}
+
+	if _, ok := ctx.GetQuery(queryUploadID); ok {
+		o.s3UploadPart(ctx)
+		return
+	}
+
+	if ctx.Request.ContentLength < 0 {
+		s3ErrorResponse(ctx, errS3MissingContentLength)
+		return
+	}
+
+	// Spool the body to a temporary file, the file is opened by each import of object.
+	f, err := os.CreateTemp(o.config.DataDir, "s3-object-*")
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+	name := f.Name()
+
+	h := md5.New()
+	n, err := io.Copy(io.MultiWriter(f, h), ctx.Request.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n != ctx.Request.ContentLength {
+		err = errS3IncompleteBody
+	}
+
+	sum := h.Sum(nil)
+	if contentMD5 := ctx.GetHeader(headerContentMD5); err == nil && contentMD5 != "" && contentMD5 != base64.StdEncoding.EncodeToString(sum) {
+		err = errS3BadDigest
+	}
+
+	if err != nil {
+		os.Remove(name)
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	file := &objectFile{
+		Filename: path.Base(objectKey),
+		Size:     n,
+		open: func() (multipart.File, error) {
+			return os.Open(name)
+		},
+		close: func() error {
+			return os.Remove(name)
+		},
+	}
+
+	etag := hex.EncodeToString(sum)
+	if err := o.importObject(ctx, bucketName, objectKey, "", WriteBack, 0, digest.New(digest.AlgorithmMD5, etag), file); err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header(headers.ETag, strconv.Quote(etag))
+	ctx.Status(http.StatusOK)
+}
+
+// s3DeleteObject deletes object or aborts multipart upload.
+func (o *objectStorage) s3DeleteObject(ctx *gin.Context) {
+	bucketName, objectKey := ctx.Param("bucket"), s3ObjectKey(ctx)
+	if objectKey == "" {
+		s3ErrorResponse(ctx, errS3NotImplemented)
+		return
+	}
+
+	client, err := o.client()
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	if uploadID, ok := ctx.GetQuery(queryUploadID); ok {
+		if err := client.AbortMultipartUpload(ctx, bucketName, objectKey, uploadID); err != nil {
+			s3ErrorResponse(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+
+	if err := client.DeleteObject(ctx, bucketName, objectKey); err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// s3PostObject creates or completes multipart upload.
+func (o *objectStorage) s3PostObject(ctx *gin.Context) {
+	if s3ObjectKey(ctx) == "" {
+		s3ErrorResponse(ctx, errS3NotImplemented)
+		return
+	}
+
+	if _, ok := ctx.GetQuery(queryUploads); ok {
+		o.s3CreateMultipartUpload(ctx)
+		return
+	}
+
+	if _, ok := ctx.GetQuery(queryUploadID); ok {
+		o.s3CompleteMultipartUpload(ctx)
+		return
+	}
+
+	s3ErrorResponse(ctx, errS3NotImplemented)
+}
+
+// s3CreateMultipartUpload initiates multipart upload of object.
+func (o *objectStorage) s3CreateMultipartUpload(ctx *gin.Context) {
+	bucketName, objectKey := ctx.Param("bucket"), s3ObjectKey(ctx)
+	client, err := o.client()
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	uploadID, err := client.CreateMultipartUpload(ctx, bucketName, objectKey, "")
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.XML(http.StatusOK, S3InitiateMultipartUploadResult{
+		Xmlns:    s3XMLNamespace,
+		Bucket:   bucketName,
+		Key:      objectKey,
+		UploadID: uploadID,
+	})
+}
+
+// s3UploadPart uploads part of multipart upload to backend.
+func (o *objectStorage) s3UploadPart(ctx *gin.Context) {
+	bucketName, objectKey := ctx.Param("bucket"), s3ObjectKey(ctx)
+	partNumber, err := strconv.ParseInt(ctx.Query("partNumber"), 10, 64)
+	if err != nil || partNumber < 1 || partNumber > objectstorage.MaxPartNumber {
+		s3ErrorResponse(ctx, errS3InvalidArgument)
+		return
+	}
+
+	if ctx.Request.ContentLength < 0 {
+		s3ErrorResponse(ctx, errS3MissingContentLength)
+		return
+	}
+
+	client, err := o.client()
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	part, err := client.UploadPart(ctx, bucketName, objectKey, ctx.Query(queryUploadID), partNumber, ctx.Request.ContentLength, ctx.Request.Body)
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header(headers.ETag, part.ETag)
+	ctx.Status(http.StatusOK)
+}
+
+// s3CompleteMultipartUpload completes multipart upload by the parts in request body.
+func (o *objectStorage) s3CompleteMultipartUpload(ctx *gin.Context) {
+	bucketName, objectKey := ctx.Param("bucket"), s3ObjectKey(ctx)
+
+	var req S3CompleteMultipartUpload
+	if err := xml.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		var serr *s3Error
+		if errors.As(err, &serr) {
+			s3ErrorResponse(ctx, err)
+			return
+		}
+
+		s3ErrorResponse(ctx, errS3MalformedXML)
+		return
+	}
+
+	if len(req.Parts) == 0 {
+		s3ErrorResponse(ctx, errS3MalformedXML)
+		return
+	}
+
+	var parts []*objectstorage.PartMetadata
+	for _, part := range req.Parts {
+		if part.PartNumber < 1 || part.PartNumber > objectstorage.MaxPartNumber || part.ETag == "" {
+			s3ErrorResponse(ctx, errS3InvalidPart)
+			return
+		}
+
+		parts = append(parts, &objectstorage.PartMetadata{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
+
+	client, err := o.client()
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	if err := client.CompleteMultipartUpload(ctx, bucketName, objectKey, ctx.Query(queryUploadID), parts); err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	result := S3CompleteMultipartUploadResult{
+		Xmlns:    s3XMLNamespace,
+		Location: path.Join("/", bucketName, objectKey),
+		Bucket:   bucketName,
+		Key:      objectKey,
+	}
+
+	// ETag of the completed object is generated by backend.
+	if meta, isExist, err := client.GetObjectMetadata(ctx, bucketName, objectKey); err == nil && isExist {
+		result.ETag = meta.ETag
+	}
+
+	ctx.XML(http.StatusOK, result)
+}
+
+// s3ListParts lists the uploaded parts of multipart upload.
+func (o *objectStorage) s3ListParts(ctx *gin.Context) {
+	bucketName, objectKey, uploadID := ctx.Param("bucket"), s3ObjectKey(ctx), ctx.Query(queryUploadID)
+	client, err := o.client()
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	partMetadatas, err := client.ListParts(ctx, bucketName, objectKey, uploadID)
+	if err != nil {
+		s3ErrorResponse(ctx, err)
+		return
+	}
+
+	result := S3ListPartsResult{
+		Xmlns:    s3XMLNamespace,
+		Bucket:   bucketName,
+		Key:      objectKey,
+		UploadID: uploadID,
+	}
+	for _, partMetadata := range partMetadatas {
+		result.Parts = append(result.Parts, S3Part{
+			PartNumber: partMetadata.PartNumber,
+			ETag:       partMetadata.ETag,
+			Size:       partMetadata.Size,
+		})
+	}
+
+	ctx.XML(http.StatusOK, result)
+}
@@ -0,0 +1,790 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/headers"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	configmocks "d7y.io/dragonfly/v2/client/config/mocks"
+	"d7y.io/dragonfly/v2/client/daemon/peer"
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	storagemocks "d7y.io/dragonfly/v2/client/daemon/storage/mocks"
+	"d7y.io/dragonfly/v2/client/util"
+	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/objectstorage"
+	objectstoragemocks "d7y.io/dragonfly/v2/pkg/objectstorage/mocks"
+)
+
+var (
+	mockBucketName  = "bucket"
+	mockObjectKey   = "dir/a.txt"
+	mockSignURL     = "http://127.0.0.1:9000/bucket/dir/a.txt"
+	mockUploadID    = "foo"
+	mockContent     = []byte("hello")
+	mockContentMD5  = md5.Sum(mockContent)
+	mockLastModifed = time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC)
+)
+
+// objectStorageTest is the test case of object storage handlers.
+type objectStorageTest struct {
+	name   string
+	method string
+	target string
+	header http.Header
+	body   []byte
+	mock   func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder)
+	expect func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage)
+}
+
+// newTestObjectStorage returns the object storage with mocked dependencies.
+func newTestObjectStorage(t *testing.T, ctl *gomock.Controller) (*objectStorage, *objectstoragemocks.MockObjectStorage, *peer.MockTaskManager, *peer.MockPieceManager, *storagemocks.MockManager) {
+	client := objectstoragemocks.NewMockObjectStorage(ctl)
+	dynconfig := configmocks.NewMockDynconfig(ctl)
+	peerTaskManager := peer.NewMockTaskManager(ctl)
+	pieceManager := peer.NewMockPieceManager(ctl)
+	storageManager := storagemocks.NewMockManager(ctl)
+
+	dynconfig.EXPECT().GetObjectStorage().Return(&managerv1.ObjectStorage{Name: objectstorage.ServiceNameS3}, nil).AnyTimes()
+	dynconfig.EXPECT().GetSchedulers().Return(nil, nil).AnyTimes()
+	peerTaskManager.EXPECT().GetPieceManager().Return(pieceManager).AnyTimes()
+
+	cfg := &config.DaemonOption{
+		DataDir: t.TempDir(),
+		ObjectStorage: config.ObjectStorageOption{
+			Enable: true,
+			S3: config.ObjectStorageS3Option{
+				Enable:      true,
+				Credentials: []config.S3CredentialOption{{AccessKey: "foo", SecretKey: "bar"}},
+			},
+		},
+	}
+	cfg.Console = true
+
+	return &objectStorage{
+		config:          cfg,
+		dynconfig:       dynconfig,
+		peerTaskManager: peerTaskManager,
+		storageManager:  storageManager,
+		peerIDGenerator: peer.NewPeerIDGenerator("127.0.0.1"),
+		newClient: func(name, region, endpoint, accessKey, secretKey string) (objectstorage.ObjectStorage, error) {
+			return client, nil
+		},
+	}, client, peerTaskManager, pieceManager, storageManager
+}
+
+// runS3Tests serves the signed requests of tests by S3-compatible router.
+func runS3Tests(t *testing.T, tests []objectStorageTest) {
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			o, client, peerTaskManager, pieceManager, storageManager := newTestObjectStorage(t, ctl)
+			tc.mock(client.EXPECT(), peerTaskManager.EXPECT(), pieceManager.EXPECT(), storageManager.EXPECT())
+
+			body := bytes.NewReader(tc.body)
+			req := httptest.NewRequest(tc.method, "http://127.0.0.1:65006"+tc.target, body)
+			for key, values := range tc.header {
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+
+			if _, err := newTestSigner("bar").Sign(req, body, signV4Service, "us-east-1", time.Now()); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			o.initS3Router(o.config).ServeHTTP(w, req)
+			tc.expect(t, w, o)
+		})
+	}
+}
+
+// decodeS3Response decodes the xml response of S3-compatible API.
+func decodeS3Response(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	if err := xml.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestObjectStorage_S3Router(t *testing.T) {
+	runS3Tests(t, []objectStorageTest{
+		{
+			name:   "list buckets",
+			method: http.MethodGet,
+			target: "/",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.ListBucketMetadatas(gomock.Any()).Return([]*objectstorage.BucketMetadata{{Name: mockBucketName, CreateAt: mockLastModifed}}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var result S3ListAllMyBucketsResult
+				decodeS3Response(t, w, &result)
+				assert.Equal(s3XMLNamespace, result.Xmlns)
+				assert.Equal([]S3Bucket{{Name: mockBucketName, CreationDate: "2022-10-17T00:00:00.000Z"}}, result.Buckets)
+			},
+		},
+		{
+			name:   "head bucket",
+			method: http.MethodHead,
+			target: "/bucket",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.IsBucketExist(gomock.Any(), gomock.Eq(mockBucketName)).Return(true, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+			},
+		},
+		{
+			name:   "head bucket which does not exist",
+			method: http.MethodHead,
+			target: "/bucket",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.IsBucketExist(gomock.Any(), gomock.Eq(mockBucketName)).Return(false, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, w.Code)
+				assert.Empty(w.Body.Bytes())
+			},
+		},
+		{
+			name:   "list objects v1 is not implemented",
+			method: http.MethodGet,
+			target: "/bucket",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotImplemented, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("NotImplemented", result.Code)
+				assert.Equal("/bucket", result.Resource)
+			},
+		},
+		{
+			name:   "unknown route is not implemented",
+			method: http.MethodPatch,
+			target: "/bucket/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotImplemented, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("NotImplemented", result.Code)
+			},
+		},
+		{
+			name:   "delete object",
+			method: http.MethodDelete,
+			target: "/bucket/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.DeleteObject(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNoContent, w.Code)
+			},
+		},
+		{
+			name:   "copy object is not implemented",
+			method: http.MethodPut,
+			target: "/bucket/dir/a.txt",
+			header: http.Header{headerAmzCopySource: []string{"/bucket/dir/b.txt"}},
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotImplemented, w.Code)
+			},
+		},
+	})
+}
+
+func TestObjectStorage_S3Authenticate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	o, _, _, _, _ := newTestObjectStorage(t, ctl)
+
+	w := httptest.NewRecorder()
+	o.initS3Router(o.config).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://127.0.0.1:65006/", nil))
+
+	assert := assert.New(t)
+	assert.Equal(http.StatusForbidden, w.Code)
+
+	var result S3ErrorResponse
+	decodeS3Response(t, w, &result)
+	assert.Equal("AccessDenied", result.Code)
+}
+
+func TestObjectStorage_S3ListObjectsV2(t *testing.T) {
+	token := base64.RawURLEncoding.EncodeToString([]byte("dir/a"))
+	runS3Tests(t, []objectStorageTest{
+		{
+			name:   "list objects with continuation token",
+			method: http.MethodGet,
+			target: "/bucket?" + url.Values{
+				"list-type":          []string{"2"},
+				"prefix":             []string{"dir/"},
+				"delimiter":          []string{"/"},
+				"max-keys":           []string{"2"},
+				"continuation-token": []string{token},
+			}.Encode(),
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.ListObjectMetadatas(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq("dir/"), gomock.Eq("dir/a"), gomock.Eq("/"), gomock.Eq(int64(2))).Return(&objectstorage.ObjectMetadatas{
+					Metadatas: []*objectstorage.ObjectMetadata{
+						{Key: "dir/b.txt", ContentLength: 5, ETag: `"etag"`, LastModified: mockLastModifed},
+					},
+					CommonPrefixes: []string{"dir/c/"},
+					IsTruncated:    true,
+					NextMarker:     "dir/c/",
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var result S3ListBucketResult
+				decodeS3Response(t, w, &result)
+				assert.Equal(mockBucketName, result.Name)
+				assert.Equal(token, result.ContinuationToken)
+				assert.Equal(base64.RawURLEncoding.EncodeToString([]byte("dir/c/")), result.NextContinuationToken)
+				assert.Equal(int64(2), result.MaxKeys)
+				assert.Equal(2, result.KeyCount)
+				assert.True(result.IsTruncated)
+				assert.Equal([]S3Object{{
+					Key:          "dir/b.txt",
+					LastModified: "2022-10-17T00:00:00.000Z",
+					ETag:         `"etag"`,
+					Size:         5,
+					StorageClass: s3StorageClass,
+				}}, result.Contents)
+				assert.Equal([]S3CommonPrefix{{Prefix: "dir/c/"}}, result.CommonPrefixes)
+			},
+		},
+		{
+			name:   "list objects with url encoding type",
+			method: http.MethodGet,
+			target: "/bucket?" + url.Values{
+				"list-type":     []string{"2"},
+				"prefix":        []string{"a b/"},
+				"delimiter":     []string{"/"},
+				"encoding-type": []string{"url"},
+			}.Encode(),
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.ListObjectMetadatas(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq("a b/"), gomock.Eq(""), gomock.Eq("/"), gomock.Eq(int64(s3MaxKeys))).Return(&objectstorage.ObjectMetadatas{
+					Metadatas:      []*objectstorage.ObjectMetadata{{Key: "a b/c+d.txt"}},
+					CommonPrefixes: []string{"a b/e f/"},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var result S3ListBucketResult
+				decodeS3Response(t, w, &result)
+				assert.Equal("url", result.EncodingType)
+				assert.Equal("a%20b/", result.Prefix)
+				assert.Equal("/", result.Delimiter)
+				assert.Equal("a%20b/c%2Bd.txt", result.Contents[0].Key)
+				assert.Equal("a%20b/e%20f/", result.CommonPrefixes[0].Prefix)
+				assert.False(result.IsTruncated)
+				assert.Empty(result.NextContinuationToken)
+			},
+		},
+		{
+			name:   "list objects with zero max keys",
+			method: http.MethodGet,
+			target: "/bucket?list-type=2&max-keys=0",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var result S3ListBucketResult
+				decodeS3Response(t, w, &result)
+				assert.Equal(0, result.KeyCount)
+				assert.Empty(result.Contents)
+			},
+		},
+		{
+			name:   "list objects with invalid encoding type",
+			method: http.MethodGet,
+			target: "/bucket?list-type=2&encoding-type=base64",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("InvalidArgument", result.Code)
+			},
+		},
+		{
+			name:   "list objects with invalid continuation token",
+			method: http.MethodGet,
+			target: "/bucket?list-type=2&continuation-token=%21%21",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("InvalidArgument", result.Code)
+			},
+		},
+		{
+			name:   "list objects failed",
+			method: http.MethodGet,
+			target: "/bucket?list-type=2",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.ListObjectMetadatas(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(""), gomock.Eq(""), gomock.Eq(""), gomock.Eq(int64(s3MaxKeys))).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("InternalError", result.Code)
+			},
+		},
+	})
+}
+
+func TestObjectStorage_S3PutObject(t *testing.T) {
+	etag := hex.EncodeToString(mockContentMD5[:])
+	runS3Tests(t, []objectStorageTest{
+		{
+			name:   "put object with content md5",
+			method: http.MethodPut,
+			target: "/bucket/dir/a.txt",
+			header: http.Header{headerContentMD5: []string{base64.StdEncoding.EncodeToString(mockContentMD5[:])}},
+			body:   mockContent,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				gomock.InOrder(
+					mc.GetSignURL(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(objectstorage.MethodGet), gomock.Eq(defaultSignExpireTime)).Return(mockSignURL, nil).Times(1),
+					ms.RegisterTask(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1),
+					mpm.Import(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq(int64(len(mockContent))), gomock.Any()).DoAndReturn(
+						func(ctx context.Context, ptm storage.PeerTaskMetadata, tsd storage.TaskStorageDriver, contentLength int64, reader io.Reader) error {
+							data, err := io.ReadAll(reader)
+							assert.NoError(t, err)
+							assert.Equal(t, mockContent, data)
+							return nil
+						}).Times(1),
+					mp.AnnouncePeerTask(gomock.Any(), gomock.Any(), gomock.Eq(mockSignURL), gomock.Any(), gomock.Any()).Return(nil).Times(1),
+					mc.PutObject(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(digest.New(digest.AlgorithmMD5, etag).String()), gomock.Any()).DoAndReturn(
+						func(ctx context.Context, bucketName, objectKey, digest string, reader io.Reader) error {
+							data, err := io.ReadAll(reader)
+							assert.NoError(t, err)
+							assert.Equal(t, mockContent, data)
+							return nil
+						}).Times(1),
+				)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal(`"`+etag+`"`, w.Header().Get(headers.ETag))
+
+				// The spooled file is removed after all imports are finished.
+				assert.Eventually(func() bool {
+					entries, err := os.ReadDir(o.config.DataDir)
+					return err == nil && len(entries) == 0
+				}, 5*time.Second, 10*time.Millisecond)
+			},
+		},
+		{
+			name:   "put object with mismatched content md5",
+			method: http.MethodPut,
+			target: "/bucket/dir/a.txt",
+			header: http.Header{headerContentMD5: []string{base64.StdEncoding.EncodeToString(make([]byte, md5.Size))}},
+			body:   mockContent,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("BadDigest", result.Code)
+
+				entries, err := os.ReadDir(o.config.DataDir)
+				assert.NoError(err)
+				assert.Empty(entries)
+			},
+		},
+		{
+			name:   "put object to backend failed",
+			method: http.MethodPut,
+			target: "/bucket/dir/a.txt",
+			body:   mockContent,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				gomock.InOrder(
+					mc.GetSignURL(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(objectstorage.MethodGet), gomock.Eq(defaultSignExpireTime)).Return(mockSignURL, nil).Times(1),
+					ms.RegisterTask(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1),
+					mpm.Import(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq(int64(len(mockContent))), gomock.Any()).Return(nil).Times(1),
+					mp.AnnouncePeerTask(gomock.Any(), gomock.Any(), gomock.Eq(mockSignURL), gomock.Any(), gomock.Any()).Return(nil).Times(1),
+					mc.PutObject(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Any(), gomock.Any()).Return(errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("InternalError", result.Code)
+			},
+		},
+	})
+}
+
+func TestObjectStorage_S3GetObject(t *testing.T) {
+	runS3Tests(t, []objectStorageTest{
+		{
+			name:   "get object",
+			method: http.MethodGet,
+			target: "/bucket/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				gomock.InOrder(
+					mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(&objectstorage.ObjectMetadata{
+						ContentLength: 10,
+						ContentType:   "text/plain",
+						ETag:          `"etag"`,
+						Digest:        "md5:foo",
+						LastModified:  mockLastModifed,
+					}, true, nil).Times(1),
+					mc.GetSignURL(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(objectstorage.MethodGet), gomock.Eq(defaultSignExpireTime)).Return(mockSignURL, nil).Times(1),
+					mp.StartStreamTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *peer.StreamTaskRequest) (io.ReadCloser, map[string]string, error) {
+						assert := assert.New(t)
+						assert.Nil(req.Range)
+						assert.Equal("md5:foo", req.URLMeta.Digest)
+						return io.NopCloser(strings.NewReader("0123456789")), map[string]string{}, nil
+					}).Times(1),
+				)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal("0123456789", w.Body.String())
+				assert.Equal("text/plain", w.Header().Get(headers.ContentType))
+				assert.Equal(`"etag"`, w.Header().Get(headers.ETag))
+				assert.Equal(mockLastModifed.Format(http.TimeFormat), w.Header().Get(headers.LastModified))
+			},
+		},
+		{
+			name:   "get object with range",
+			method: http.MethodGet,
+			target: "/bucket/dir/a.txt",
+			header: http.Header{headers.Range: []string{"bytes=2-6"}},
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				gomock.InOrder(
+					mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(&objectstorage.ObjectMetadata{
+						ContentLength: 10,
+						Digest:        "md5:foo",
+					}, true, nil).Times(1),
+					mc.GetSignURL(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(objectstorage.MethodGet), gomock.Eq(defaultSignExpireTime)).Return(mockSignURL, nil).Times(1),
+					mp.StartStreamTask(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, req *peer.StreamTaskRequest) (io.ReadCloser, map[string]string, error) {
+						assert := assert.New(t)
+						assert.Equal(&util.Range{Start: 2, Length: 5}, req.Range)
+						assert.Equal("2-6", req.URLMeta.Range)
+						assert.Empty(req.URLMeta.Digest)
+						return io.NopCloser(strings.NewReader("23456")), map[string]string{}, nil
+					}).Times(1),
+				)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusPartialContent, w.Code)
+				assert.Equal("23456", w.Body.String())
+				assert.Equal("bytes 2-6/10", w.Header().Get(headers.ContentRange))
+				assert.Equal("5", w.Header().Get(headers.ContentLength))
+			},
+		},
+		{
+			name:   "get object with unsatisfiable range",
+			method: http.MethodGet,
+			target: "/bucket/dir/a.txt",
+			header: http.Header{headers.Range: []string{"bytes=20-30"}},
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(&objectstorage.ObjectMetadata{ContentLength: 10}, true, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusRequestedRangeNotSatisfiable, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("InvalidRange", result.Code)
+			},
+		},
+		{
+			name:   "get object which does not exist",
+			method: http.MethodGet,
+			target: "/bucket/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(nil, false, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("NoSuchKey", result.Code)
+				assert.Equal("/bucket/dir/a.txt", result.Resource)
+			},
+		},
+	})
+}
+
+func TestObjectStorage_S3HeadObject(t *testing.T) {
+	runS3Tests(t, []objectStorageTest{
+		{
+			name:   "head object",
+			method: http.MethodHead,
+			target: "/bucket/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(&objectstorage.ObjectMetadata{
+					ContentLength: 10,
+					ETag:          `"etag"`,
+				}, true, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal("10", w.Header().Get(headers.ContentLength))
+				assert.Equal(`"etag"`, w.Header().Get(headers.ETag))
+				assert.Equal("bytes", w.Header().Get(headers.AcceptRanges))
+			},
+		},
+		{
+			name:   "head object which does not exist",
+			method: http.MethodHead,
+			target: "/bucket/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(nil, false, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNotFound, w.Code)
+				assert.Empty(w.Body.Bytes())
+			},
+		},
+		{
+			name:   "head object failed",
+			method: http.MethodHead,
+			target: "/bucket/dir/a.txt",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(nil, false, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, w.Code)
+				assert.Empty(w.Body.Bytes())
+			},
+		},
+	})
+}
+
+func TestObjectStorage_S3MultipartUpload(t *testing.T) {
+	runS3Tests(t, []objectStorageTest{
+		{
+			name:   "create multipart upload",
+			method: http.MethodPost,
+			target: "/bucket/dir/a.txt?uploads",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.CreateMultipartUpload(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq("")).Return(mockUploadID, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var result S3InitiateMultipartUploadResult
+				decodeS3Response(t, w, &result)
+				assert.Equal(s3XMLNamespace, result.Xmlns)
+				assert.Equal(mockBucketName, result.Bucket)
+				assert.Equal(mockObjectKey, result.Key)
+				assert.Equal(mockUploadID, result.UploadID)
+			},
+		},
+		{
+			name:   "upload part",
+			method: http.MethodPut,
+			target: "/bucket/dir/a.txt?partNumber=1&uploadId=foo",
+			body:   mockContent,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.UploadPart(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID), gomock.Eq(int64(1)), gomock.Eq(int64(len(mockContent))), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, bucketName, objectKey, uploadID string, partNumber, size int64, reader io.Reader) (*objectstorage.PartMetadata, error) {
+						data, err := io.ReadAll(reader)
+						assert.NoError(t, err)
+						assert.Equal(t, mockContent, data)
+						return &objectstorage.PartMetadata{PartNumber: partNumber, ETag: `"etag1"`, Size: size}, nil
+					}).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal(`"etag1"`, w.Header().Get(headers.ETag))
+			},
+		},
+		{
+			name:   "upload part with invalid part number",
+			method: http.MethodPut,
+			target: "/bucket/dir/a.txt?partNumber=0&uploadId=foo",
+			body:   mockContent,
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("InvalidArgument", result.Code)
+			},
+		},
+		{
+			name:   "complete multipart upload",
+			method: http.MethodPost,
+			target: "/bucket/dir/a.txt?uploadId=foo",
+			body: []byte(`<CompleteMultipartUpload>
+  <Part><PartNumber>1</PartNumber><ETag>"etag1"</ETag></Part>
+  <Part><PartNumber>2</PartNumber><ETag>"etag2"</ETag></Part>
+</CompleteMultipartUpload>`),
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				gomock.InOrder(
+					mc.CompleteMultipartUpload(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID), gomock.Eq([]*objectstorage.PartMetadata{
+						{PartNumber: 1, ETag: `"etag1"`},
+						{PartNumber: 2, ETag: `"etag2"`},
+					})).Return(nil).Times(1),
+					mc.GetObjectMetadata(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey)).Return(&objectstorage.ObjectMetadata{ETag: `"etag-2"`}, true, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var result S3CompleteMultipartUploadResult
+				decodeS3Response(t, w, &result)
+				assert.Equal("/bucket/dir/a.txt", result.Location)
+				assert.Equal(mockBucketName, result.Bucket)
+				assert.Equal(mockObjectKey, result.Key)
+				assert.Equal(`"etag-2"`, result.ETag)
+			},
+		},
+		{
+			name:   "complete multipart upload with malformed xml",
+			method: http.MethodPost,
+			target: "/bucket/dir/a.txt?uploadId=foo",
+			body:   []byte("<CompleteMultipartUpload><Part>"),
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("MalformedXML", result.Code)
+			},
+		},
+		{
+			name:   "complete multipart upload with invalid part",
+			method: http.MethodPost,
+			target: "/bucket/dir/a.txt?uploadId=foo",
+			body:   []byte("<CompleteMultipartUpload><Part><PartNumber>1</PartNumber></Part></CompleteMultipartUpload>"),
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, w.Code)
+
+				var result S3ErrorResponse
+				decodeS3Response(t, w, &result)
+				assert.Equal("InvalidPart", result.Code)
+			},
+		},
+		{
+			name:   "list parts",
+			method: http.MethodGet,
+			target: "/bucket/dir/a.txt?uploadId=foo",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.ListParts(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID)).Return([]*objectstorage.PartMetadata{
+					{PartNumber: 1, ETag: `"etag1"`, Size: 5},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var result S3ListPartsResult
+				decodeS3Response(t, w, &result)
+				assert.Equal(mockUploadID, result.UploadID)
+				assert.Equal([]S3Part{{PartNumber: 1, ETag: `"etag1"`, Size: 5}}, result.Parts)
+			},
+		},
+		{
+			name:   "abort multipart upload",
+			method: http.MethodDelete,
+			target: "/bucket/dir/a.txt?uploadId=foo",
+			mock: func(mc *objectstoragemocks.MockObjectStorageMockRecorder, mp *peer.MockTaskManagerMockRecorder, mpm *peer.MockPieceManagerMockRecorder, ms *storagemocks.MockManagerMockRecorder) {
+				mc.AbortMultipartUpload(gomock.Any(), gomock.Eq(mockBucketName), gomock.Eq(mockObjectKey), gomock.Eq(mockUploadID)).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, o *objectStorage) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusNoContent, w.Code)
+			},
+		},
+	})
+}
@@ -0,0 +1,546 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-http-utils/headers"
+
+	"d7y.io/dragonfly/v2/client/config"
+)
+
+const (
+	// signV4Algorithm is the algorithm of AWS signature version 4.
+	signV4Algorithm = "AWS4-HMAC-SHA256"
+
+	// signV4ChunkAlgorithm is the algorithm of chunk signature in streaming upload.
+	signV4ChunkAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+
+	// signV4Service is the service in credential scope of S3.
+	signV4Service = "s3"
+
+	// signV4Terminator is the terminator of credential scope.
+	signV4Terminator = "aws4_request"
+
+	// amzDateFormat is the format of X-Amz-Date.
+	amzDateFormat = "20060102T150405Z"
+
+	// amzShortDateFormat is the format of date in credential scope.
+	amzShortDateFormat = "20060102"
+
+	// maxRequestTimeSkew is the max difference between the time of request and server.
+	maxRequestTimeSkew = 15 * time.Minute
+
+	// maxPresignExpires is the max expires of presigned url.
+	maxPresignExpires = 7 * 24 * time.Hour
+
+	// maxChunkSize is the max size of chunk in streaming upload.
+	maxChunkSize = 16 * 1024 * 1024
+
+	// emptySHA256 is the sha256 of empty payload.
+	emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+const (
+	// unsignedPayload is the payload hash of request which payload is not signed.
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// streamingSignedPayload is the payload hash of streaming upload which chunks are signed.
+	streamingSignedPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+	// streamingUnsignedPayloadTrailer is the payload hash of streaming upload which chunks are
+	// not signed and the checksum is sent in trailer.
+	streamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+)
+
+const (
+	headerAmzDate                 = "X-Amz-Date"
+	headerAmzContentSHA256        = "X-Amz-Content-Sha256"
+	headerAmzDecodedContentLength = "X-Amz-Decoded-Content-Length"
+
+	queryAmzAlgorithm     = "X-Amz-Algorithm"
+	queryAmzCredential    = "X-Amz-Credential"
+	queryAmzDate          = "X-Amz-Date"
+	queryAmzExpires       = "X-Amz-Expires"
+	queryAmzSignedHeaders = "X-Amz-SignedHeaders"
+	queryAmzSignature     = "X-Amz-Signature"
+)
+
+// sha256Regexp matches the hex sha256 of payload.
+var sha256Regexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// signV4Verifier verifies AWS signature version 4 of requests with the configured credentials.
+type signV4Verifier struct {
+	// credentials is the secret keys by access keys.
+	credentials map[string]string
+
+	// now returns the current time.
+	now func() time.Time
+}
+
+// newSignV4Verifier returns a new signV4Verifier.
+func newSignV4Verifier(credentials []config.S3CredentialOption) *signV4Verifier {
+	v := &signV4Verifier{
+		credentials: make(map[string]string, len(credentials)),
+		now:         time.Now,
+	}
+
+	for _, credential := range credentials {
+		v.credentials[credential.AccessKey] = credential.SecretKey
+	}
+
+	return v
+}
+
+// signV4Request is the signature fields of request.
+type signV4Request struct {
+	accessKey     string
+	date          time.Time
+	amzDate       string
+	scope         string
+	region        string
+	signedHeaders []string
+	signature     string
+	payloadHash   string
+}
+
+// Verify verifies the signature in authorization header or query of presigned url. If the payload is
+// signed, the body of request is replaced with the reader which verifies the payload when it is read.
+func (v *signV4Verifier) Verify(r *http.Request) error {
+	var (
+		req *signV4Request
+		err error
+	)
+
+	query := r.URL.Query()
+	if query.Has(queryAmzAlgorithm) {
+		req, err = v.parsePresignedQuery(query)
+	} else {
+		req, err = v.parseAuthorizationHeader(r)
+	}
+	if err != nil {
+		return err
+	}
+
+	secretKey, ok := v.credentials[req.accessKey]
+	if !ok {
+		return errS3InvalidAccessKeyID
+	}
+
+	signingKey := signV4SigningKey(secretKey, req.date.Format(amzShortDateFormat), req.region)
+	stringToSign := strings.Join([]string{
+		signV4Algorithm,
+		req.amzDate,
+		req.scope,
+		sha256Hex([]byte(canonicalRequest(r, req.signedHeaders, req.payloadHash))),
+	}, "\n")
+
+	if !hmac.Equal([]byte(hex.EncodeToString(hmacSHA256(signingKey, []byte(stringToSign)))), []byte(req.signature)) {
+		return errS3SignatureDoesNotMatch
+	}
+
+	switch {
+	case req.payloadHash == unsignedPayload:
+	case sha256Regexp.MatchString(req.payloadHash):
+		r.Body = &readCloser{
+			Reader: &sha256Reader{reader: r.Body, hash: sha256.New(), expected: req.payloadHash},
+			Closer: r.Body,
+		}
+	case req.payloadHash == streamingSignedPayload || req.payloadHash == streamingUnsignedPayloadTrailer:
+		decodedContentLength, err := strconv.ParseInt(r.Header.Get(headerAmzDecodedContentLength), 10, 64)
+		if err != nil || decodedContentLength < 0 {
+			return errS3MissingContentLength
+		}
+
+		reader := &chunkedReader{
+			reader:    bufio.NewReader(r.Body),
+			remaining: decodedContentLength,
+		}
+		if req.payloadHash == streamingSignedPayload {
+			reader.signingKey = signingKey
+			reader.amzDate = req.amzDate
+			reader.scope = req.scope
+			reader.signature = req.signature
+		}
+
+		r.Body = &readCloser{Reader: reader, Closer: r.Body}
+		r.ContentLength = decodedContentLength
+	default:
+		return errS3NotImplemented
+	}
+
+	return nil
+}
+
+// parseAuthorizationHeader parses the signature fields of authorization header, like:
+// AWS4-HMAC-SHA256 Credential=<access key>/<date>/<region>/s3/aws4_request, SignedHeaders=<headers>, Signature=<signature>.
+func (v *signV4Verifier) parseAuthorizationHeader(r *http.Request) (*signV4Request, error) {
+	authorization := r.Header.Get(headers.Authorization)
+	if authorization == "" {
+		return nil, errS3AccessDenied
+	}
+
+	if !strings.HasPrefix(authorization, signV4Algorithm+" ") {
+		return nil, errS3SignatureVersionNotSupported
+	}
+	fields := strings.TrimPrefix(authorization, signV4Algorithm+" ")
+
+	values := map[string]string{}
+	for _, field := range strings.Split(fields, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, errS3AuthorizationHeaderMalformed
+		}
+		values[key] = value
+	}
+
+	req := &signV4Request{
+		amzDate:     r.Header.Get(headerAmzDate),
+		signature:   values["Signature"],
+		payloadHash: r.Header.Get(headerAmzContentSHA256),
+	}
+	if req.signature == "" || values["SignedHeaders"] == "" {
+		return nil, errS3AuthorizationHeaderMalformed
+	}
+	req.signedHeaders = strings.Split(values["SignedHeaders"], ";")
+
+	if req.payloadHash == "" {
+		return nil, errS3InvalidRequest
+	}
+
+	if err := req.parseCredential(values["Credential"]); err != nil {
+		return nil, err
+	}
+
+	now := v.now()
+	if req.date.Before(now.Add(-maxRequestTimeSkew)) || req.date.After(now.Add(maxRequestTimeSkew)) {
+		return nil, errS3RequestTimeTooSkewed
+	}
+
+	return req, nil
+}
+
+// parsePresignedQuery parses the signature fields of presigned url.
+func (v *signV4Verifier) parsePresignedQuery(query url.Values) (*signV4Request, error) {
+	if query.Get(queryAmzAlgorithm) != signV4Algorithm {
+		return nil, errS3SignatureVersionNotSupported
+	}
+
+	req := &signV4Request{
+		amzDate:     query.Get(queryAmzDate),
+		signature:   query.Get(queryAmzSignature),
+		payloadHash: unsignedPayload,
+	}
+	if req.signature == "" || query.Get(queryAmzSignedHeaders) == "" {
+		return nil, errS3AuthorizationQueryParametersError
+	}
+	req.signedHeaders = strings.Split(query.Get(queryAmzSignedHeaders), ";")
+
+	if err := req.parseCredential(query.Get(queryAmzCredential)); err != nil {
+		return nil, err
+	}
+
+	expires, err := strconv.ParseInt(query.Get(queryAmzExpires), 10, 64)
+	if err != nil || expires <= 0 || time.Duration(expires)*time.Second > maxPresignExpires {
+		return nil, errS3AuthorizationQueryParametersError
+	}
+
+	now := v.now()
+	if req.date.After(now.Add(maxRequestTimeSkew)) {
+		return nil, errS3AccessDenied
+	}
+
+	if now.After(req.date.Add(time.Duration(expires) * time.Second)) {
+		return nil, errS3ExpiredToken
+	}
+
+	return req, nil
+}
+
+// parseCredential parses the credential of request, like: <access key>/<date>/<region>/s3/aws4_request.
+func (req *signV4Request) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[3] != signV4Service || parts[4] != signV4Terminator {
+		return errS3AuthorizationHeaderMalformed
+	}
+
+	date, err := time.Parse(amzDateFormat, req.amzDate)
+	if err != nil || date.Format(amzShortDateFormat) != parts[1] {
+		return errS3AuthorizationHeaderMalformed
+	}
+
+	if !containsString(req.signedHeaders, "host") {
+		return errS3AuthorizationHeaderMalformed
+	}
+
+	req.accessKey = parts[0]
+	req.date = date
+	req.region = parts[2]
+	req.scope = strings.Join(parts[1:], "/")
+	return nil
+}
+
+// canonicalRequest returns the canonical request of signature version 4.
+func canonicalRequest(r *http.Request, signedHeaders []string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		var values []string
+		switch name {
+		case "host":
+			values = []string{r.Host}
+		case "content-length":
+			values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		default:
+			values = r.Header.Values(name)
+		}
+
+		for i, value := range values {
+			values[i] = strings.Join(strings.Fields(value), " ")
+		}
+
+		canonicalHeaders.WriteString(name)
+		canonicalHeaders.WriteString(":")
+		canonicalHeaders.WriteString(strings.Join(values, ","))
+		canonicalHeaders.WriteString("\n")
+	}
+
+	return strings.Join([]string{
+		r.Method,
+		signV4Escape(r.URL.Path, false),
+		canonicalQuery(r.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// canonicalQuery returns the canonical query string without the signature of presigned url.
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for key, values := range query {
+		if key == queryAmzSignature {
+			continue
+		}
+
+		for _, value := range values {
+			params = append(params, signV4Escape(key, true)+"="+signV4Escape(value, true))
+		}
+	}
+
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// signV4Escape escapes the string by the URI encoding of signature version 4,
+// all characters except the unreserved characters are escaped, and the slash
+// is not escaped in path.
+func signV4Escape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !escapeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+
+		b.WriteString("%")
+		b.WriteString(strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+
+	return b.String()
+}
+
+// signV4SigningKey returns the signing key derived from the secret key.
+func signV4SigningKey(secretKey, date, region string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretKey), []byte(date))
+	key = hmacSHA256(key, []byte(region))
+	key = hmacSHA256(key, []byte(signV4Service))
+	return hmacSHA256(key, []byte(signV4Terminator))
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readCloser replaces the reader of request body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// sha256Reader verifies the sha256 of payload when the payload is read to the end.
+type sha256Reader struct {
+	reader   io.Reader
+	hash     hash.Hash
+	expected string
+}
+
+// Read reads the payload, it returns errS3ContentSHA256Mismatch at the end of payload if sha256 is mismatched.
+func (r *sha256Reader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(r.hash.Sum(nil)) != r.expected {
+		return n, errS3ContentSHA256Mismatch
+	}
+
+	return n, err
+}
+
+// chunkedReader decodes the aws-chunked payload of streaming upload, like:
+// <hex size>;chunk-signature=<signature>\r\n<data>\r\n...0;chunk-signature=<signature>\r\n\r\n,
+// the chunk signatures are verified if signing key is not nil and the trailers are ignored.
+type chunkedReader struct {
+	reader    *bufio.Reader
+	remaining int64
+	chunk     []byte
+	err       error
+
+	signingKey []byte
+	amzDate    string
+	scope      string
+	signature  string
+}
+
+// Read reads the decoded payload, the data of chunk is returned after the chunk is verified.
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.chunk, r.err = r.readChunk()
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// readChunk reads and verifies the next chunk, it returns io.EOF after the last chunk.
+func (r *chunkedReader) readChunk() ([]byte, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	sizeField, extension, _ := strings.Cut(line, ";")
+	size, err := strconv.ParseInt(sizeField, 16, 64)
+	if err != nil || size < 0 || size > maxChunkSize || size > r.remaining {
+		return nil, errS3IncompleteBody
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, data); err != nil {
+		return nil, errS3IncompleteBody
+	}
+
+	if r.signingKey != nil {
+		name, signature, _ := strings.Cut(extension, "=")
+		if name != "chunk-signature" {
+			return nil, errS3SignatureDoesNotMatch
+		}
+
+		stringToSign := strings.Join([]string{
+			signV4ChunkAlgorithm,
+			r.amzDate,
+			r.scope,
+			r.signature,
+			emptySHA256,
+			sha256Hex(data),
+		}, "\n")
+
+		expected := hex.EncodeToString(hmacSHA256(r.signingKey, []byte(stringToSign)))
+		if !hmac.Equal([]byte(expected), []byte(signature)) {
+			return nil, errS3SignatureDoesNotMatch
+		}
+		r.signature = expected
+	}
+
+	// The last chunk is followed by trailers and an empty line.
+	if size == 0 {
+		for {
+			line, err := r.readLine()
+			if err != nil {
+				return nil, err
+			}
+
+			if line == "" {
+				break
+			}
+		}
+
+		if r.remaining != 0 {
+			return nil, errS3IncompleteBody
+		}
+
+		return nil, io.EOF
+	}
+
+	line, err = r.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if line != "" {
+		return nil, errS3IncompleteBody
+	}
+
+	r.remaining -= size
+	return data, nil
+}
+
+// readLine reads a line ended with \r\n.
+func (r *chunkedReader) readLine() (string, error) {
+	line, err := r.reader.ReadSlice('\n')
+	if err != nil {
+		return "", errS3IncompleteBody
+	}
+
+	return string(bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))), nil
+}
@@ -0,0 +1,274 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/config"
+)
+
+func newTestSigner(secretKey string) *v4.Signer {
+	return v4.NewSigner(credentials.NewStaticCredentials("foo", secretKey, ""), func(s *v4.Signer) {
+		s.DisableURIPathEscaping = true
+	})
+}
+
+func TestSignV4Verifier_Verify(t *testing.T) {
+	now := time.Now()
+	verifier := newSignV4Verifier([]config.S3CredentialOption{{AccessKey: "foo", SecretKey: "bar"}})
+	verifier.now = func() time.Time { return now }
+
+	tests := []struct {
+		name   string
+		sign   func(req *http.Request, body io.ReadSeeker) error
+		body   string
+		expect func(t *testing.T, req *http.Request, err error)
+	}{
+		{
+			name: "sign header",
+			sign: func(req *http.Request, body io.ReadSeeker) error {
+				_, err := newTestSigner("bar").Sign(req, body, signV4Service, "us-east-1", now)
+				return err
+			},
+			body: "hello",
+			expect: func(t *testing.T, req *http.Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				b, err := io.ReadAll(req.Body)
+				assert.NoError(err)
+				assert.Equal("hello", string(b))
+			},
+		},
+		{
+			name: "sign header with mismatched payload",
+			sign: func(req *http.Request, body io.ReadSeeker) error {
+				if _, err := newTestSigner("bar").Sign(req, body, signV4Service, "us-east-1", now); err != nil {
+					return err
+				}
+
+				req.Body = io.NopCloser(strings.NewReader("world"))
+				return nil
+			},
+			body: "hello",
+			expect: func(t *testing.T, req *http.Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				_, err = io.ReadAll(req.Body)
+				assert.ErrorIs(err, errS3ContentSHA256Mismatch)
+			},
+		},
+		{
+			name: "sign header with wrong secret key",
+			sign: func(req *http.Request, body io.ReadSeeker) error {
+				_, err := newTestSigner("baz").Sign(req, body, signV4Service, "us-east-1", now)
+				return err
+			},
+			expect: func(t *testing.T, req *http.Request, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, errS3SignatureDoesNotMatch)
+			},
+		},
+		{
+			name: "sign header with unknown access key",
+			sign: func(req *http.Request, body io.ReadSeeker) error {
+				signer := v4.NewSigner(credentials.NewStaticCredentials("unknown", "bar", ""))
+				_, err := signer.Sign(req, body, signV4Service, "us-east-1", now)
+				return err
+			},
+			expect: func(t *testing.T, req *http.Request, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, errS3InvalidAccessKeyID)
+			},
+		},
+		{
+			name: "sign header with skewed time",
+			sign: func(req *http.Request, body io.ReadSeeker) error {
+				_, err := newTestSigner("bar").Sign(req, body, signV4Service, "us-east-1", now.Add(-time.Hour))
+				return err
+			},
+			expect: func(t *testing.T, req *http.Request, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, errS3RequestTimeTooSkewed)
+			},
+		},
+		{
+			name: "presign url",
+			sign: func(req *http.Request, body io.ReadSeeker) error {
+				_, err := newTestSigner("bar").Presign(req, nil, signV4Service, "us-east-1", time.Hour, now)
+				return err
+			},
+			expect: func(t *testing.T, req *http.Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "presign url expired",
+			sign: func(req *http.Request, body io.ReadSeeker) error {
+				_, err := newTestSigner("bar").Presign(req, nil, signV4Service, "us-east-1", time.Minute, now.Add(-time.Hour))
+				return err
+			},
+			expect: func(t *testing.T, req *http.Request, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, errS3ExpiredToken)
+			},
+		},
+		{
+			name: "anonymous request",
+			sign: func(req *http.Request, body io.ReadSeeker) error {
+				return nil
+			},
+			expect: func(t *testing.T, req *http.Request, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, errS3AccessDenied)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body := strings.NewReader(tc.body)
+			req, err := http.NewRequest(http.MethodPut, "http://127.0.0.1:65006/bucket/dir/a%20b.txt?partNumber=1&uploadId=x%2By", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tc.sign(req, body); err != nil {
+				t.Fatal(err)
+			}
+
+			tc.expect(t, req, verifier.Verify(req))
+		})
+	}
+}
+
+func TestChunkedReader(t *testing.T) {
+	var (
+		amzDate    = "20221017T000000Z"
+		scope      = "20221017/us-east-1/s3/aws4_request"
+		signingKey = signV4SigningKey("bar", "20221017", "us-east-1")
+		seed       = "seed"
+	)
+
+	// encode encodes chunks to aws-chunked payload and signs chunks if signed is true.
+	encode := func(signed bool, chunks ...string) string {
+		var (
+			buf       bytes.Buffer
+			signature = seed
+		)
+		for _, chunk := range append(chunks, "") {
+			fmt.Fprintf(&buf, "%x", len(chunk))
+			if signed {
+				signature = fmt.Sprintf("%x", hmacSHA256(signingKey, []byte(strings.Join([]string{
+					signV4ChunkAlgorithm, amzDate, scope, signature, emptySHA256, sha256Hex([]byte(chunk)),
+				}, "\n"))))
+				fmt.Fprintf(&buf, ";chunk-signature=%s", signature)
+			}
+			fmt.Fprintf(&buf, "\r\n%s\r\n", chunk)
+		}
+
+		return buf.String()
+	}
+
+	tests := []struct {
+		name      string
+		payload   string
+		signed    bool
+		remaining int64
+		expect    func(t *testing.T, data []byte, err error)
+	}{
+		{
+			name:      "signed chunks",
+			payload:   encode(true, "hello ", "world"),
+			signed:    true,
+			remaining: 11,
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("hello world", string(data))
+			},
+		},
+		{
+			name:      "tampered chunk",
+			payload:   strings.Replace(encode(true, "hello ", "world"), "world", "WORLD", 1),
+			signed:    true,
+			remaining: 11,
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, errS3SignatureDoesNotMatch)
+				assert.Equal("hello ", string(data))
+			},
+		},
+		{
+			name:      "unsigned chunks with trailer",
+			payload:   strings.TrimSuffix(encode(false, "hello"), "\r\n") + "x-amz-checksum-crc32:NhCmhg==\r\n\r\n",
+			remaining: 5,
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("hello", string(data))
+			},
+		},
+		{
+			name:      "mismatched decoded content length",
+			payload:   encode(false, "hello"),
+			remaining: 6,
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, errS3IncompleteBody)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reader := &chunkedReader{
+				reader:    bufio.NewReader(strings.NewReader(tc.payload)),
+				remaining: tc.remaining,
+			}
+			if tc.signed {
+				reader.signingKey = signingKey
+				reader.amzDate = amzDate
+				reader.scope = scope
+				reader.signature = seed
+			}
+
+			data, err := io.ReadAll(reader)
+			tc.expect(t, data, err)
+		})
+	}
+}
+
+func TestObjectStorage_InitS3Router(t *testing.T) {
+	assert := assert.New(t)
+	o := &objectStorage{}
+	assert.NotPanics(func() {
+		o.initS3Router(&config.DaemonOption{})
+	})
+}
@@ -33,3 +33,30 @@ type PutObjectRequset struct {
 type GetObjectQuery struct {
 	Filter string `form:"filter" binding:"omitempty"`
 }
+
+type CreateMultipartUploadQuery struct {
+	Digest string `form:"digest" binding:"omitempty"`
+}
+
+type CreateMultipartUploadResponse struct {
+	UploadID string `json:"uploadId"`
+}
+
+type UploadPartQuery struct {
+	UploadID   string `form:"uploadId" binding:"required"`
+	PartNumber int64  `form:"partNumber" binding:"required,gte=1,lte=10000"`
+}
+
+type MultipartUploadQuery struct {
+	UploadID string `form:"uploadId" binding:"required"`
+}
+
+type Part struct {
+	PartNumber int64  `json:"partNumber" binding:"required,gte=1,lte=10000"`
+	ETag       string `json:"etag" binding:"required"`
+	Size       int64  `json:"size" binding:"omitempty"`
+}
+
+type CompleteMultipartUploadRequest struct {
+	Parts []Part `json:"parts" binding:"required,gt=0,lte=10000,dive"`
+}
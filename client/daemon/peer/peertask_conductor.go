@@ -145,10 +145,8 @@ type peerTaskConductor struct {
 	readyPieces *Bitmap
 	// lock used by piece result manage, when update readyPieces, lock first
 	readyPiecesLock sync.RWMutex
-	// runningPieces stands all downloading pieces
-	runningPieces *Bitmap
-	// lock used by piece download worker
-	runningPiecesLock sync.Mutex
+	// pieceDispatcher decides the order of downloading pieces
+	pieceDispatcher PieceDispatcher
 	// requestedPieces stands all pieces requested from peers
 	requestedPieces *Bitmap
 	// lock used by piece download worker
@@ -237,7 +235,6 @@ func (ptm *peerTaskManager) newPeerTaskConductor(
 		legacyPeerCount:     atomic.NewInt64(0),
 		span:                span,
 		readyPieces:         NewBitmap(),
-		runningPieces:       NewBitmap(),
 		requestedPieces:     NewBitmap(),
 		failedPieceCh:       make(chan int32, config.DefaultPieceChanSize),
 		failedReason:        failedReasonNotSet,
@@ -260,6 +257,9 @@ func (ptm *peerTaskManager) newPeerTaskConductor(
 		peerTaskConductor: ptc,
 	}
 
+	ptc.pieceDispatcher = NewPieceDispatcher(ptm.pieceSelectionStrategy(request),
+		WithEndgame(ptm.EndgamePieces, ptc.GetTotalPieces))
+
 	ptc.pieceDownloadCtx, ptc.pieceDownloadCancel = context.WithCancel(ptc.ctx)
 
 	return ptc
@@ -293,6 +293,14 @@ func (pt *peerTaskConductor) register() error {
 			pt.Errorf("scheduler did not response in %s", pt.SchedulerOption.ScheduleTimeout.Duration)
 		}
 		pt.Errorf("step 1: peer %s register failed: %s", pt.request.PeerId, err)
+		// download is forbidden by the application priority, do not back source
+		if dferrors.CheckError(err, commonv1.Code_SchedForbidden) {
+			pt.peerPacketStream = &dummyPeerPacketStream{}
+			pt.Errorf("register peer task forbidden: %s, peer id: %s", err, pt.request.PeerId)
+			pt.span.RecordError(err)
+			pt.cancel(commonv1.Code_SchedForbidden, err.Error())
+			return err
+		}
 		if pt.SchedulerOption.DisableAutoBackSource {
 			// when peer register failed, some actions need to do with peerPacketStream
 			pt.peerPacketStream = &dummyPeerPacketStream{}
@@ -495,6 +503,7 @@ func (pt *peerTaskConductor) cancelNotRegisterred(code commonv1.Code, reason str
 		pt.broker.Stop()
 		pt.span.End()
 		pt.pieceDownloadCancel()
+		pt.pieceDispatcher.Close()
 		if pt.pieceTaskSyncManager != nil {
 			pt.pieceTaskSyncManager.cancel()
 		}
@@ -532,6 +541,7 @@ func (pt *peerTaskConductor) forceBackSource() {
 func (pt *peerTaskConductor) backSource() {
 	// cancel all piece download
 	pt.pieceDownloadCancel()
+	pt.pieceDispatcher.Close()
 	// cancel all sync pieces
 	if pt.pieceTaskSyncManager != nil {
 		pt.pieceTaskSyncManager.cancel()
@@ -579,22 +589,16 @@ func (pt *peerTaskConductor) pullPieces() {
 }
 
 func (pt *peerTaskConductor) pullPiecesWithP2P() {
-	var (
-		// keep same size with pt.failedPieceCh for avoiding deadlock
-		pieceBufferSize = uint32(config.DefaultPieceChanSize)
-		pieceRequestCh  = make(chan *DownloadPieceRequest, pieceBufferSize)
-	)
 	ctx, cancel := context.WithCancel(pt.ctx)
 
 	pt.pieceTaskSyncManager = &pieceTaskSyncManager{
 		ctx:               ctx,
 		ctxCancel:         cancel,
 		peerTaskConductor: pt,
-		pieceRequestCh:    pieceRequestCh,
 		workers:           map[string]*pieceTaskSynchronizer{},
 	}
-	go pt.pullPiecesFromPeers(pieceRequestCh)
-	pt.receivePeerPacket(pieceRequestCh)
+	go pt.pullPiecesFromPeers()
+	pt.receivePeerPacket()
 }
 
 func (pt *peerTaskConductor) storeEmptyPeerTask() {
@@ -695,7 +699,7 @@ func (pt *peerTaskConductor) storeTinyPeerTask() {
 	pt.PublishPieceInfo(0, uint32(contentLength))
 }
 
-func (pt *peerTaskConductor) receivePeerPacket(pieceRequestCh chan *DownloadPieceRequest) {
+func (pt *peerTaskConductor) receivePeerPacket() {
 	var (
 		lastNotReadyPiece   int32 = 0
 		peerPacket          *schedulerv1.PeerPacket
@@ -785,7 +789,7 @@ loop:
 			trace.WithAttributes(config.AttributeMainPeer.String(peerPacket.MainPeer.PeerId)))
 
 		if !firstPacketReceived {
-			pt.initDownloadPieceWorkers(peerPacket.ParallelCount, pieceRequestCh)
+			pt.initDownloadPieceWorkers(peerPacket.ParallelCount)
 			firstPeerSpan.SetAttributes(config.AttributeMainPeer.String(peerPacket.MainPeer.PeerId))
 			firstPeerSpan.End()
 		}
@@ -956,7 +960,7 @@ func (pt *peerTaskConductor) pullSinglePiece() {
 }
 
 // Deprecated
-func (pt *peerTaskConductor) pullPiecesFromPeers(pieceRequestCh chan *DownloadPieceRequest) {
+func (pt *peerTaskConductor) pullPiecesFromPeers() {
 	if ok, backSource := pt.waitFirstPeerPacket(); !ok {
 		if backSource {
 			return
@@ -1025,7 +1029,7 @@ loop:
 		pt.updateMetadata(piecePacket)
 
 		// 3. dispatch piece request to all workers
-		pt.dispatchPieceRequest(pieceRequestCh, piecePacket)
+		pt.dispatchPieceRequest(piecePacket)
 
 		// 4. get next not request piece
 		if num, ok = pt.getNextPieceNum(num); ok {
@@ -1085,12 +1089,12 @@ func (pt *peerTaskConductor) updateMetadata(piecePacket *commonv1.PiecePacket) {
 	}
 }
 
-func (pt *peerTaskConductor) initDownloadPieceWorkers(count int32, pieceRequestCh chan *DownloadPieceRequest) {
+func (pt *peerTaskConductor) initDownloadPieceWorkers(count int32) {
 	if count < 1 {
 		count = 4
 	}
 	for i := int32(0); i < count; i++ {
-		go pt.downloadPieceWorker(i, pieceRequestCh)
+		go pt.downloadPieceWorker(i)
 	}
 }
 
@@ -1156,7 +1160,7 @@ func (pt *peerTaskConductor) waitAvailablePeerPacket() (int32, bool) {
 }
 
 // Deprecated
-func (pt *peerTaskConductor) dispatchPieceRequest(pieceRequestCh chan *DownloadPieceRequest, piecePacket *commonv1.PiecePacket) {
+func (pt *peerTaskConductor) dispatchPieceRequest(piecePacket *commonv1.PiecePacket) {
 	pieceCount := len(piecePacket.PieceInfos)
 	pt.Debugf("dispatch piece request, piece count: %d", pieceCount)
 	// fix cdn return zero piece info, but with total piece count and content length
@@ -1175,7 +1179,7 @@ func (pt *peerTaskConductor) dispatchPieceRequest(pieceRequestCh chan *DownloadP
 			pt.requestedPieces.Set(piece.PieceNum)
 		}
 		pt.requestedPiecesLock.Unlock()
-		req := &DownloadPieceRequest{
+		pt.pieceDispatcher.Put(&DownloadPieceRequest{
 			storage: pt.GetStorage(),
 			piece:   piece,
 			log:     pt.Log(),
@@ -1183,14 +1187,7 @@ func (pt *peerTaskConductor) dispatchPieceRequest(pieceRequestCh chan *DownloadP
 			PeerID:  pt.GetPeerID(),
 			DstPid:  piecePacket.DstPid,
 			DstAddr: piecePacket.DstAddr,
-		}
-		select {
-		case pieceRequestCh <- req:
-		case <-pt.successCh:
-			pt.Infof("peer task success, stop dispatch piece request")
-		case <-pt.failCh:
-			pt.Warnf("peer task fail, stop dispatch piece request")
-		}
+		})
 	}
 }
 
@@ -1224,55 +1221,42 @@ wait:
 	}
 }
 
-func (pt *peerTaskConductor) downloadPieceWorker(id int32, requests chan *DownloadPieceRequest) {
+func (pt *peerTaskConductor) downloadPieceWorker(id int32) {
 	for {
-		select {
-		case request := <-requests:
-			pt.readyPiecesLock.RLock()
-			if pt.readyPieces.IsSet(request.piece.PieceNum) {
-				pt.readyPiecesLock.RUnlock()
-				pt.Log().Debugf("piece %d is already downloaded, skip", request.piece.PieceNum)
-				continue
+		ctx, request, err := pt.pieceDispatcher.Get(pt.pieceDownloadCtx)
+		if err != nil {
+			select {
+			case <-pt.successCh:
+				pt.Infof("peer task success, peer download worker #%d exit", id)
+			case <-pt.failCh:
+				pt.Errorf("peer task fail, peer download worker #%d exit", id)
+			default:
+				pt.Infof("piece download cancelled, peer download worker #%d exit", id)
 			}
-			pt.readyPiecesLock.RUnlock()
-			pt.downloadPiece(id, request)
-		case <-pt.pieceDownloadCtx.Done():
-			pt.Infof("piece download cancelled, peer download worker #%d exit", id)
-			return
-		case <-pt.successCh:
-			pt.Infof("peer task success, peer download worker #%d exit", id)
-			return
-		case <-pt.failCh:
-			pt.Errorf("peer task fail, peer download worker #%d exit", id)
 			return
 		}
-	}
-}
 
-func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPieceRequest) {
-	// only downloading piece in one worker at same time
-	pt.runningPiecesLock.Lock()
-	if pt.runningPieces.IsSet(request.piece.PieceNum) {
-		pt.runningPiecesLock.Unlock()
-		pt.Log().Debugf("piece %d is downloading, skip", request.piece.PieceNum)
-		// TODO save to queue for failed pieces
-		return
+		pt.readyPiecesLock.RLock()
+		if pt.readyPieces.IsSet(request.piece.PieceNum) {
+			pt.readyPiecesLock.RUnlock()
+			pt.Log().Debugf("piece %d is already downloaded, skip", request.piece.PieceNum)
+			pt.pieceDispatcher.Report(request, nil)
+			continue
+		}
+		pt.readyPiecesLock.RUnlock()
+		pt.downloadPiece(ctx, id, request)
 	}
-	pt.runningPieces.Set(request.piece.PieceNum)
-	pt.runningPiecesLock.Unlock()
-
-	defer func() {
-		pt.runningPiecesLock.Lock()
-		pt.runningPieces.Clean(request.piece.PieceNum)
-		pt.runningPiecesLock.Unlock()
-	}()
+}
 
-	ctx, span := tracer.Start(pt.pieceDownloadCtx, fmt.Sprintf(config.SpanDownloadPiece, request.piece.PieceNum))
+func (pt *peerTaskConductor) downloadPiece(ctx context.Context, workerID int32, request *DownloadPieceRequest) {
+	// ctx is cancelled when the piece is downloaded by other worker in endgame mode
+	ctx, span := tracer.Start(ctx, fmt.Sprintf(config.SpanDownloadPiece, request.piece.PieceNum))
 	span.SetAttributes(config.AttributePiece.Int(int(request.piece.PieceNum)))
 	span.SetAttributes(config.AttributePieceWorker.Int(int(workerID)))
 
 	// wait limit
 	if pt.limiter != nil && !pt.waitLimit(ctx, request) {
+		pt.pieceDispatcher.Report(request, ctx.Err())
 		span.SetAttributes(config.AttributePieceSuccess.Bool(false))
 		span.End()
 		return
@@ -1284,6 +1268,25 @@ func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPiec
 	// download piece
 	// result is always not nil, PieceManager will report begin and end time
 	result, err := pt.PieceManager.DownloadPiece(ctx, request)
+	// the piece is committed by other parent in endgame mode, only the committed request reports success
+	if errors.Is(err, storage.ErrPieceExists) {
+		pt.pieceDispatcher.Report(request, nil)
+		pt.Debugf("piece %d is written by other parent, ignore the request to %s", request.piece.PieceNum, request.DstPid)
+		span.SetAttributes(config.AttributePieceSuccess.Bool(false))
+		span.End()
+		return
+	}
+
+	// check before reporting, the ctx is cancelled after the request is reported
+	duplicated := err != nil && ctx.Err() != nil && pt.pieceDownloadCtx.Err() == nil
+	pt.pieceDispatcher.Report(request, err)
+	if duplicated {
+		pt.Debugf("piece %d is downloaded by other parent, cancel the request to %s", request.piece.PieceNum, request.DstPid)
+		span.SetAttributes(config.AttributePieceSuccess.Bool(false))
+		span.End()
+		return
+	}
+
 	if err != nil {
 		pt.ReportPieceResult(request, result, err)
 		span.SetAttributes(config.AttributePieceSuccess.Bool(false))
@@ -1554,6 +1557,7 @@ func (pt *peerTaskConductor) done() {
 		pt.broker.Stop()
 		pt.span.End()
 		pt.pieceDownloadCancel()
+		pt.pieceDispatcher.Close()
 		if pt.pieceTaskSyncManager != nil {
 			pt.pieceTaskSyncManager.cancel()
 		}
@@ -1657,6 +1661,7 @@ func (pt *peerTaskConductor) fail() {
 		pt.broker.Stop()
 		pt.span.End()
 		pt.pieceDownloadCancel()
+		pt.pieceDispatcher.Close()
 		if pt.pieceTaskSyncManager != nil {
 			pt.pieceTaskSyncManager.cancel()
 		}
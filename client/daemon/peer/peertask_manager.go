@@ -34,6 +34,7 @@ import (
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
+	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	clientutil "d7y.io/dragonfly/v2/client/util"
@@ -131,6 +132,12 @@ type TaskManagerOption struct {
 	PerPeerRateLimit  rate.Limit
 	TotalRateLimit    rate.Limit
 	TrafficShaperType string
+	// PriorityTrafficShaper is used when TrafficShaperType is priority
+	PriorityTrafficShaper config.PriorityTrafficShaperOption
+	// PieceSelectionStrategy is the default piece selection strategy of peer tasks
+	PieceSelectionStrategy string
+	// EndgamePieces is the count of remaining pieces to enter endgame mode, 0 disables endgame mode
+	EndgamePieces int32
 	// Multiplex indicates to reuse the data of completed peer tasks
 	Multiplex bool
 	// Prefetch indicates to prefetch the whole files of ranged requests
@@ -144,7 +151,7 @@ func NewPeerTaskManager(opt *TaskManagerOption) (TaskManager, error) {
 		TaskManagerOption: *opt,
 		runningPeerTasks:  sync.Map{},
 		conductorLock:     &sync.Mutex{},
-		trafficShaper:     NewTrafficShaper(opt.TrafficShaperType, opt.TotalRateLimit, util.ComputePieceSize),
+		trafficShaper:     NewTrafficShaper(opt.TrafficShaperType, opt.TotalRateLimit, util.ComputePieceSize, opt.PriorityTrafficShaper),
 	}
 	ptm.trafficShaper.Start()
 	return ptm, nil
@@ -158,6 +165,8 @@ func (ptm *peerTaskManager) findPeerTaskConductor(key string) (*peerTaskConducto
 	return pt.(*peerTaskConductor), true
 }
 
+// getPeerTaskConductor returns the running peerTaskConductor of task or starts a new one,
+// created is true if the peerTaskConductor is started by this call.
 func (ptm *peerTaskManager) getPeerTaskConductor(ctx context.Context,
 	taskID string,
 	request *schedulerv1.PeerTaskRequest,
@@ -165,12 +174,7 @@ func (ptm *peerTaskManager) getPeerTaskConductor(ctx context.Context,
 	parent *peerTaskConductor,
 	rg *clientutil.Range,
 	desiredLocation string,
-	seed bool) (*peerTaskConductor, error) {
-	var (
-		ptc     *peerTaskConductor
-		created bool
-		err     error
-	)
+	seed bool) (ptc *peerTaskConductor, created bool, err error) {
 
 	if ptm.SplitRunningTasks {
 		ptc, created, err = ptm.createSplitedPeerTaskConductor(
@@ -181,15 +185,15 @@ func (ptm *peerTaskManager) getPeerTaskConductor(ctx context.Context,
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if created {
 		if err = ptc.start(); err != nil {
-			return nil, err
+			return nil, false, err
 		}
 	}
-	return ptc, err
+	return ptc, created, err
 }
 
 // getOrCreatePeerTaskConductor will get or create a peerTaskConductor,
@@ -293,7 +297,7 @@ func (ptm *peerTaskManager) prefetchParentTask(request *schedulerv1.PeerTaskRequ
 	}
 
 	logger.Infof("prefetch peer task %s/%s", taskID, req.PeerId)
-	prefetch, err := ptm.getPeerTaskConductor(context.Background(), taskID, req, limit, nil, nil, desiredLocation, false)
+	prefetch, _, err := ptm.getPeerTaskConductor(context.Background(), taskID, req, limit, nil, nil, desiredLocation, false)
 	if err != nil {
 		logger.Errorf("prefetch peer task %s/%s error: %s", prefetch.taskID, prefetch.peerID, err)
 		return nil
@@ -401,6 +405,17 @@ func (ptm *peerTaskManager) getRunningTaskKey(taskID, peerID string) string {
 	return taskID
 }
 
+// pieceSelectionStrategy returns the piece selection strategy of the request,
+// the strategy in the url meta header overrides the default strategy.
+func (ptm *peerTaskManager) pieceSelectionStrategy(request *schedulerv1.PeerTaskRequest) string {
+	if request.UrlMeta != nil {
+		if strategy, ok := request.UrlMeta.Header[config.HeaderDragonflyPieceSelectionStrategy]; ok {
+			return strategy
+		}
+	}
+	return ptm.PieceSelectionStrategy
+}
+
 func (ptm *peerTaskManager) Subscribe(request *commonv1.PieceTaskRequest) (*SubscribeResponse, bool) {
 	ptc, ok := ptm.findPeerTaskConductor(ptm.getRunningTaskKey(request.TaskId, request.DstPid))
 	if !ok {
@@ -304,7 +304,7 @@ func setupMockManager(ctrl *gomock.Controller, ts *testSpec, opt componentsOptio
 	ptm := &peerTaskManager{
 		conductorLock:    &sync.Mutex{},
 		runningPeerTasks: sync.Map{},
-		trafficShaper:    NewTrafficShaper("plain", 0, nil),
+		trafficShaper:    NewTrafficShaper("plain", 0, nil, config.PriorityTrafficShaperOption{}),
 		TaskManagerOption: TaskManagerOption{
 			SchedulerClient: schedulerClient,
 			TaskOption: TaskOption{
@@ -46,7 +46,6 @@ type pieceTaskSyncManager struct {
 	ctx               context.Context
 	ctxCancel         context.CancelFunc
 	peerTaskConductor *peerTaskConductor
-	pieceRequestCh    chan *DownloadPieceRequest
 	workers           map[string]*pieceTaskSynchronizer
 	watchdog          *synchronizerWatchdog
 }
@@ -59,7 +58,6 @@ type pieceTaskSynchronizer struct {
 	dstPeer           *schedulerv1.PeerPacket_DestPeer
 	error             atomic.Value
 	peerTaskConductor *peerTaskConductor
-	pieceRequestCh    chan *DownloadPieceRequest
 }
 
 type synchronizerWatchdog struct {
@@ -211,7 +209,6 @@ func (s *pieceTaskSyncManager) newPieceTaskSynchronizer(
 	synchronizer := &pieceTaskSynchronizer{
 		span:                span,
 		peerTaskConductor:   s.peerTaskConductor,
-		pieceRequestCh:      s.pieceRequestCh,
 		syncPiecesStream:    stream,
 		grpcClient:          grpcClient,
 		dstPeer:             dstPeer,
@@ -362,7 +359,7 @@ func (s *pieceTaskSynchronizer) dispatchPieceRequest(piecePacket *commonv1.Piece
 			s.peerTaskConductor.requestedPieces.Set(piece.PieceNum)
 		}
 		s.peerTaskConductor.requestedPiecesLock.Unlock()
-		req := &DownloadPieceRequest{
+		s.peerTaskConductor.pieceDispatcher.Put(&DownloadPieceRequest{
 			storage: s.peerTaskConductor.GetStorage(),
 			piece:   piece,
 			log:     s.peerTaskConductor.Log(),
@@ -370,15 +367,8 @@ func (s *pieceTaskSynchronizer) dispatchPieceRequest(piecePacket *commonv1.Piece
 			PeerID:  s.peerTaskConductor.GetPeerID(),
 			DstPid:  piecePacket.DstPid,
 			DstAddr: piecePacket.DstAddr,
-		}
-		select {
-		case s.pieceRequestCh <- req:
-			s.span.AddEvent(fmt.Sprintf("send piece #%d request to piece download queue", piece.PieceNum))
-		case <-s.peerTaskConductor.successCh:
-			s.Infof("peer task success, stop dispatch piece request, dest peer: %s", s.dstPeer.PeerId)
-		case <-s.peerTaskConductor.failCh:
-			s.Warnf("peer task fail, stop dispatch piece request, dest peer: %s", s.dstPeer.PeerId)
-		}
+		})
+		s.span.AddEvent(fmt.Sprintf("put piece #%d request to piece dispatcher", piece.PieceNum))
 	}
 }
 
@@ -22,6 +22,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/client/config"
@@ -42,6 +43,8 @@ type SeedTaskResponse struct {
 	Span    trace.Span
 	TaskID  string
 	PeerID  string
+	// Cancel cancels the seed peer task with the reason, it is nil when the running task is reused.
+	Cancel func(reason string)
 }
 
 // SeedTask represents a seed peer task
@@ -64,7 +67,7 @@ func (ptm *peerTaskManager) newSeedTask(
 	limit rate.Limit) (*SeedTaskResponse, error) {
 
 	taskID := idgen.TaskID(request.Url, request.UrlMeta)
-	ptc, err := ptm.getPeerTaskConductor(ctx, taskID, &request.PeerTaskRequest, limit, nil, request.Range, "", true)
+	ptc, created, err := ptm.getPeerTaskConductor(ctx, taskID, &request.PeerTaskRequest, limit, nil, request.Range, "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -83,5 +86,13 @@ func (ptm *peerTaskManager) newSeedTask(
 			FailReason:       ptc.getFailedError,
 		},
 	}
+
+	// The running conductor is shared by other downloads of the task, only the conductor
+	// started by this request can be canceled.
+	if created {
+		resp.Cancel = func(reason string) {
+			ptc.cancel(commonv1.Code_ClientContextCanceled, reason)
+		}
+	}
 	return resp, nil
 }
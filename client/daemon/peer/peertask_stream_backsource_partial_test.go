@@ -253,7 +253,7 @@ func TestStreamPeerTask_BackSource_Partial_WithContentLength(t *testing.T) {
 	ptm := &peerTaskManager{
 		conductorLock:    &sync.Mutex{},
 		runningPeerTasks: sync.Map{},
-		trafficShaper:    NewTrafficShaper("plain", 0, nil),
+		trafficShaper:    NewTrafficShaper("plain", 0, nil, config.PriorityTrafficShaperOption{}),
 		TaskManagerOption: TaskManagerOption{
 			SchedulerClient: schedulerClient,
 			TaskOption: TaskOption{
@@ -0,0 +1,384 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"d7y.io/dragonfly/v2/client/config"
+)
+
+var errPieceDispatcherClosed = errors.New("piece dispatcher closed")
+
+// PieceDispatcher collects the pieces offered by parents and decides
+// which piece to download next by the piece selection strategy.
+type PieceDispatcher interface {
+	// Put adds a piece request offered by the parent, the latest request of the same parent is kept.
+	Put(req *DownloadPieceRequest)
+	// Get blocks until a piece request is available, it returns error when ctx is done or dispatcher is closed.
+	// The returned context is derived from ctx and is used to download the piece, it is cancelled
+	// when the piece is downloaded by the duplicated request in endgame mode.
+	Get(ctx context.Context) (context.Context, *DownloadPieceRequest, error)
+	// Report reports the result of piece request returned by Get,
+	// the failed parent of the piece is dropped and the piece is dispatched again with other parents.
+	Report(req *DownloadPieceRequest, err error)
+	// Close closes the dispatcher and wakes up all blocked Get.
+	Close()
+}
+
+// PieceDispatcherOption is a functional option for configuring the piece dispatcher.
+type PieceDispatcherOption func(d *pieceDispatcher)
+
+// WithEndgame enables endgame mode when the count of remaining pieces is not greater than pieces,
+// totalPieces returns the total piece count of task, it is negative when the total piece count is unknown.
+// In endgame mode, the running pieces are requested from other parents in parallel.
+func WithEndgame(pieces int32, totalPieces func() int32) PieceDispatcherOption {
+	return func(d *pieceDispatcher) {
+		d.endgamePieces = pieces
+		d.totalPieces = totalPieces
+	}
+}
+
+type pieceDispatcher struct {
+	strategy      string
+	rand          *rand.Rand
+	endgamePieces int32
+	totalPieces   func() int32
+
+	mu sync.Mutex
+	// offers stands the requests of pending pieces from all parents
+	offers map[int32][]*DownloadPieceRequest
+	// pending stands the offered pieces which are not running,
+	// it is ordered by the strategy so that next does not scan all offers
+	pending *pendingPieces
+	// running stands the cancel functions of requests returned by Get and not reported yet,
+	// it is keyed by piece number and the parent of request
+	running map[int32]map[string]context.CancelFunc
+	// done stands the pieces reported success
+	done map[int32]struct{}
+
+	notify    chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewPieceDispatcher returns a new PieceDispatcher, unknown strategy falls back to sequential.
+func NewPieceDispatcher(strategy string, options ...PieceDispatcherOption) PieceDispatcher {
+	switch strategy {
+	case config.PieceSelectionStrategySequential, config.PieceSelectionStrategyRarestFirst, config.PieceSelectionStrategyRandom:
+	default:
+		strategy = config.PieceSelectionStrategySequential
+	}
+
+	d := &pieceDispatcher{
+		strategy: strategy,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		offers:   map[int32][]*DownloadPieceRequest{},
+		running:  map[int32]map[string]context.CancelFunc{},
+		done:     map[int32]struct{}{},
+		notify:   make(chan struct{}, 1),
+		closed:   make(chan struct{}),
+	}
+
+	d.pending = newPendingPieces(d.less)
+
+	for _, opt := range options {
+		opt(d)
+	}
+
+	return d
+}
+
+func (d *pieceDispatcher) Put(req *DownloadPieceRequest) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	num := req.piece.PieceNum
+	if _, ok := d.done[num]; ok {
+		return
+	}
+
+	offers := d.offers[num]
+	for i, offer := range offers {
+		if offer.DstPid == req.DstPid {
+			offers[i] = req
+			return
+		}
+	}
+	d.offers[num] = append(offers, req)
+
+	// the count of offers is changed, the order of pending piece is fixed
+	if _, ok := d.running[num]; !ok {
+		d.pending.upsert(num)
+	}
+	d.wakeup()
+}
+
+func (d *pieceDispatcher) Get(ctx context.Context) (context.Context, *DownloadPieceRequest, error) {
+	for {
+		d.mu.Lock()
+		req := d.next()
+		if req != nil {
+			pieceCtx, cancel := context.WithCancel(ctx)
+			attempts, ok := d.running[req.piece.PieceNum]
+			if !ok {
+				attempts = map[string]context.CancelFunc{}
+				d.running[req.piece.PieceNum] = attempts
+			}
+			attempts[req.DstPid] = cancel
+			d.mu.Unlock()
+			return pieceCtx, req, nil
+		}
+		d.mu.Unlock()
+
+		select {
+		case <-d.notify:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-d.closed:
+			return nil, nil, errPieceDispatcherClosed
+		}
+	}
+}
+
+func (d *pieceDispatcher) Report(req *DownloadPieceRequest, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	num := req.piece.PieceNum
+	attempts := d.running[num]
+	if cancel, ok := attempts[req.DstPid]; ok {
+		cancel()
+		delete(attempts, req.DstPid)
+	}
+
+	if err == nil {
+		// cancel the duplicated requests of endgame mode
+		for _, cancel := range attempts {
+			cancel()
+		}
+		delete(d.running, num)
+		delete(d.offers, num)
+		d.pending.remove(num)
+		d.done[num] = struct{}{}
+
+		// the remaining pieces may enter endgame mode
+		d.wakeup()
+		return
+	}
+
+	if len(attempts) == 0 {
+		delete(d.running, num)
+	}
+
+	// the piece is downloaded by other parent, the request is cancelled
+	if _, ok := d.done[num]; ok {
+		return
+	}
+
+	offers := d.offers[num]
+	for i, offer := range offers {
+		if offer.DstPid == req.DstPid {
+			offers = append(offers[:i], offers[i+1:]...)
+			break
+		}
+	}
+
+	if len(offers) == 0 {
+		delete(d.offers, num)
+		return
+	}
+
+	d.offers[num] = offers
+	if _, ok := d.running[num]; !ok {
+		d.pending.upsert(num)
+	}
+	d.wakeup()
+}
+
+func (d *pieceDispatcher) Close() {
+	d.closeOnce.Do(func() {
+		close(d.closed)
+	})
+}
+
+// next selects a pending piece by the strategy, it returns nil when there is no pending piece.
+// In endgame mode, a running piece is selected with the parent which is not requested yet
+// when all pending pieces are running. Caller must hold the lock.
+func (d *pieceDispatcher) next() *DownloadPieceRequest {
+	if d.pending.Len() == 0 {
+		return d.nextEndgame()
+	}
+
+	// the random strategy selects any pending piece with the same probability,
+	// the other strategies select the top of pending pieces
+	var i int
+	if d.strategy == config.PieceSelectionStrategyRandom {
+		i = d.rand.Intn(d.pending.Len())
+	}
+	selected := heap.Remove(d.pending, i).(int32)
+
+	offers := d.offers[selected]
+	req := offers[0]
+	if d.strategy == config.PieceSelectionStrategyRandom {
+		req = offers[d.rand.Intn(len(offers))]
+	}
+
+	// wake up another worker for the rest pending pieces
+	if d.pending.Len() > 0 || d.isEndgame() {
+		d.wakeup()
+	}
+
+	return req
+}
+
+// nextEndgame selects the running piece with the fewest requests and returns
+// the request of the parent which is not requested yet, the offers are scanned
+// because there are few remaining pieces in endgame mode. Caller must hold the lock.
+func (d *pieceDispatcher) nextEndgame() *DownloadPieceRequest {
+	if !d.isEndgame() {
+		return nil
+	}
+
+	var selected *DownloadPieceRequest
+	for num, offers := range d.offers {
+		attempts := d.running[num]
+		for _, offer := range offers {
+			if _, ok := attempts[offer.DstPid]; ok {
+				continue
+			}
+
+			if selected == nil {
+				selected = offer
+				break
+			}
+
+			// prefer the piece with fewer requests, then the smaller piece number
+			if la, lb := len(attempts), len(d.running[selected.piece.PieceNum]); la < lb || (la == lb && num < selected.piece.PieceNum) {
+				selected = offer
+			}
+			break
+		}
+	}
+
+	if selected != nil {
+		d.wakeup()
+	}
+
+	return selected
+}
+
+// isEndgame reports whether the count of remaining pieces is in the range of endgame mode.
+// Caller must hold the lock.
+func (d *pieceDispatcher) isEndgame() bool {
+	if d.endgamePieces <= 0 || d.totalPieces == nil {
+		return false
+	}
+
+	total := d.totalPieces()
+	if total <= 0 {
+		return false
+	}
+
+	return total-int32(len(d.done)) <= d.endgamePieces
+}
+
+// less reports whether the piece a is preferred to the piece b.
+func (d *pieceDispatcher) less(a, b int32) bool {
+	if d.strategy == config.PieceSelectionStrategyRarestFirst {
+		// prefer the piece owned by the fewest parents, then the smaller piece number
+		if la, lb := len(d.offers[a]), len(d.offers[b]); la != lb {
+			return la < lb
+		}
+	}
+
+	return a < b
+}
+
+// wakeup notifies a blocked Get without blocking. Caller must hold the lock.
+func (d *pieceDispatcher) wakeup() {
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pendingPieces is the heap of piece numbers ordered by less,
+// it implements heap.Interface and tracks the index of every piece.
+type pendingPieces struct {
+	nums  []int32
+	index map[int32]int
+	less  func(a, b int32) bool
+}
+
+// newPendingPieces returns an empty heap of pending pieces.
+func newPendingPieces(less func(a, b int32) bool) *pendingPieces {
+	return &pendingPieces{
+		index: map[int32]int{},
+		less:  less,
+	}
+}
+
+func (p *pendingPieces) Len() int {
+	return len(p.nums)
+}
+
+func (p *pendingPieces) Less(i, j int) bool {
+	return p.less(p.nums[i], p.nums[j])
+}
+
+func (p *pendingPieces) Swap(i, j int) {
+	p.nums[i], p.nums[j] = p.nums[j], p.nums[i]
+	p.index[p.nums[i]] = i
+	p.index[p.nums[j]] = j
+}
+
+func (p *pendingPieces) Push(x any) {
+	num := x.(int32)
+	p.index[num] = len(p.nums)
+	p.nums = append(p.nums, num)
+}
+
+func (p *pendingPieces) Pop() any {
+	n := len(p.nums) - 1
+	num := p.nums[n]
+	p.nums = p.nums[:n]
+	delete(p.index, num)
+	return num
+}
+
+// upsert adds the piece, or fixes the order of the piece if it exists.
+func (p *pendingPieces) upsert(num int32) {
+	if i, ok := p.index[num]; ok {
+		heap.Fix(p, i)
+		return
+	}
+
+	heap.Push(p, num)
+}
+
+// remove removes the piece if it exists.
+func (p *pendingPieces) remove(num int32) {
+	if i, ok := p.index[num]; ok {
+		heap.Remove(p, i)
+	}
+}
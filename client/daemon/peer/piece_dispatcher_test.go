@@ -0,0 +1,325 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+)
+
+type pieceOffer struct {
+	num    int32
+	dstPid string
+}
+
+func newPieceOffer(offer pieceOffer) *DownloadPieceRequest {
+	return &DownloadPieceRequest{
+		piece:  &commonv1.PieceInfo{PieceNum: offer.num},
+		DstPid: offer.dstPid,
+	}
+}
+
+func TestPieceDispatcher_Get(t *testing.T) {
+	testCases := []struct {
+		name     string
+		strategy string
+		offers   []pieceOffer
+		expect   func(assert *testifyassert.Assertions, nums []int32)
+	}{
+		{
+			name:     "sequential",
+			strategy: config.PieceSelectionStrategySequential,
+			offers:   []pieceOffer{{2, "a"}, {0, "a"}, {1, "a"}, {1, "b"}},
+			expect: func(assert *testifyassert.Assertions, nums []int32) {
+				assert.Equal([]int32{0, 1, 2}, nums)
+			},
+		},
+		{
+			name:     "unknown strategy falls back to sequential",
+			strategy: "foo",
+			offers:   []pieceOffer{{1, "a"}, {0, "a"}},
+			expect: func(assert *testifyassert.Assertions, nums []int32) {
+				assert.Equal([]int32{0, 1}, nums)
+			},
+		},
+		{
+			name:     "rarest first",
+			strategy: config.PieceSelectionStrategyRarestFirst,
+			offers:   []pieceOffer{{0, "a"}, {0, "b"}, {0, "c"}, {1, "a"}, {1, "b"}, {2, "c"}, {3, "a"}},
+			expect: func(assert *testifyassert.Assertions, nums []int32) {
+				assert.Equal([]int32{2, 3, 1, 0}, nums)
+			},
+		},
+		{
+			name:     "rarest first with duplicated offers of the same parent",
+			strategy: config.PieceSelectionStrategyRarestFirst,
+			offers:   []pieceOffer{{0, "a"}, {0, "a"}, {0, "a"}, {1, "a"}, {1, "b"}},
+			expect: func(assert *testifyassert.Assertions, nums []int32) {
+				assert.Equal([]int32{0, 1}, nums)
+			},
+		},
+		{
+			name:     "rarest first with offers put after the piece is pending",
+			strategy: config.PieceSelectionStrategyRarestFirst,
+			offers:   []pieceOffer{{0, "a"}, {1, "a"}, {1, "b"}, {2, "a"}, {2, "b"}, {2, "c"}, {0, "b"}, {0, "c"}, {0, "d"}},
+			expect: func(assert *testifyassert.Assertions, nums []int32) {
+				assert.Equal([]int32{1, 2, 0}, nums)
+			},
+		},
+		{
+			name:     "random",
+			strategy: config.PieceSelectionStrategyRandom,
+			offers:   []pieceOffer{{0, "a"}, {1, "a"}, {2, "b"}, {3, "c"}},
+			expect: func(assert *testifyassert.Assertions, nums []int32) {
+				sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+				assert.Equal([]int32{0, 1, 2, 3}, nums)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			d := NewPieceDispatcher(tc.strategy)
+			for _, offer := range tc.offers {
+				d.Put(newPieceOffer(offer))
+			}
+
+			var nums []int32
+			for {
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				_, req, err := d.Get(ctx)
+				cancel()
+				if err != nil {
+					assert.ErrorIs(err, context.DeadlineExceeded)
+					break
+				}
+
+				nums = append(nums, req.piece.PieceNum)
+			}
+
+			tc.expect(assert, nums)
+		})
+	}
+}
+
+func TestPieceDispatcher_Report(t *testing.T) {
+	assert := testifyassert.New(t)
+	d := NewPieceDispatcher(config.PieceSelectionStrategySequential)
+	d.Put(newPieceOffer(pieceOffer{0, "a"}))
+	d.Put(newPieceOffer(pieceOffer{0, "b"}))
+
+	_, req, err := d.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal("a", req.DstPid)
+
+	// running piece is not dispatched again
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	_, _, err = d.Get(ctx)
+	cancel()
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	// failed parent is dropped, the piece is dispatched with the other parent
+	d.Report(req, errors.New("foo"))
+	_, req, err = d.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal("b", req.DstPid)
+
+	// done piece is not dispatched again
+	d.Report(req, nil)
+	d.Put(newPieceOffer(pieceOffer{0, "c"}))
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	_, _, err = d.Get(ctx)
+	cancel()
+	assert.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestPieceDispatcher_Close(t *testing.T) {
+	assert := testifyassert.New(t)
+	d := NewPieceDispatcher(config.PieceSelectionStrategySequential)
+
+	errCh := make(chan error)
+	go func() {
+		_, _, err := d.Get(context.Background())
+		errCh <- err
+	}()
+
+	d.Close()
+	d.Close()
+	assert.ErrorIs(<-errCh, errPieceDispatcherClosed)
+}
+
+func TestPieceDispatcher_Wakeup(t *testing.T) {
+	assert := testifyassert.New(t)
+	d := NewPieceDispatcher(config.PieceSelectionStrategySequential)
+
+	const workers = 4
+	numCh := make(chan int32, workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			_, req, err := d.Get(context.Background())
+			if err == nil {
+				numCh <- req.piece.PieceNum
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	for i := int32(0); i < workers; i++ {
+		d.Put(newPieceOffer(pieceOffer{i, "a"}))
+	}
+
+	var nums []int32
+	for i := 0; i < workers; i++ {
+		select {
+		case num := <-numCh:
+			nums = append(nums, num)
+		case <-time.After(time.Second):
+			assert.FailNow("blocked workers are not woken up")
+		}
+	}
+
+	sort.Slice(nums, func(i, j int) bool { return nums[i] < nums[j] })
+	assert.Equal([]int32{0, 1, 2, 3}, nums)
+	d.Close()
+}
+
+func TestPieceDispatcher_Endgame(t *testing.T) {
+	testCases := []struct {
+		name          string
+		endgamePieces int32
+		totalPieces   int32
+		expect        func(assert *testifyassert.Assertions, d PieceDispatcher)
+	}{
+		{
+			name:          "duplicated requests are cancelled when the first copy arrives",
+			endgamePieces: 2,
+			totalPieces:   2,
+			expect: func(assert *testifyassert.Assertions, d PieceDispatcher) {
+				ctx1, req1, err := d.Get(context.Background())
+				assert.Nil(err)
+				assert.Equal(int32(0), req1.piece.PieceNum)
+				assert.Equal("a", req1.DstPid)
+
+				_, req2, err := d.Get(context.Background())
+				assert.Nil(err)
+				assert.Equal(int32(1), req2.piece.PieceNum)
+
+				// all pieces are running, request the piece with the other parent
+				ctx3, req3, err := d.Get(context.Background())
+				assert.Nil(err)
+				assert.Equal(int32(0), req3.piece.PieceNum)
+				assert.Equal("b", req3.DstPid)
+
+				// the first copy arrives, the duplicated request is cancelled
+				d.Report(req3, nil)
+				assert.ErrorIs(ctx3.Err(), context.Canceled)
+				assert.ErrorIs(ctx1.Err(), context.Canceled)
+
+				// the cancelled request does not dispatch the piece again
+				d.Report(req1, context.Canceled)
+				_, req4, err := d.Get(context.Background())
+				assert.Nil(err)
+				assert.Equal(int32(1), req4.piece.PieceNum)
+				assert.Equal("b", req4.DstPid)
+
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+				_, _, err = d.Get(ctx)
+				assert.ErrorIs(err, context.DeadlineExceeded)
+			},
+		},
+		{
+			name:          "remaining pieces are more than endgame pieces",
+			endgamePieces: 1,
+			totalPieces:   2,
+			expect: func(assert *testifyassert.Assertions, d PieceDispatcher) {
+				_, req1, err := d.Get(context.Background())
+				assert.Nil(err)
+				_, req2, err := d.Get(context.Background())
+				assert.Nil(err)
+
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				_, _, err = d.Get(ctx)
+				cancel()
+				assert.ErrorIs(err, context.DeadlineExceeded)
+
+				// enter endgame mode after the first piece is done
+				d.Report(req1, nil)
+				_, req3, err := d.Get(context.Background())
+				assert.Nil(err)
+				assert.Equal(req2.piece.PieceNum, req3.piece.PieceNum)
+				assert.NotEqual(req2.DstPid, req3.DstPid)
+			},
+		},
+		{
+			name:          "endgame mode is disabled",
+			endgamePieces: 0,
+			totalPieces:   2,
+			expect: func(assert *testifyassert.Assertions, d PieceDispatcher) {
+				for i := 0; i < 2; i++ {
+					_, _, err := d.Get(context.Background())
+					assert.Nil(err)
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+				_, _, err := d.Get(ctx)
+				assert.ErrorIs(err, context.DeadlineExceeded)
+			},
+		},
+		{
+			name:          "total pieces is unknown",
+			endgamePieces: 2,
+			totalPieces:   -1,
+			expect: func(assert *testifyassert.Assertions, d PieceDispatcher) {
+				for i := 0; i < 2; i++ {
+					_, _, err := d.Get(context.Background())
+					assert.Nil(err)
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+				_, _, err := d.Get(ctx)
+				assert.ErrorIs(err, context.DeadlineExceeded)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			totalPieces := tc.totalPieces
+			d := NewPieceDispatcher(config.PieceSelectionStrategySequential,
+				WithEndgame(tc.endgamePieces, func() int32 { return totalPieces }))
+			for _, offer := range []pieceOffer{{0, "a"}, {0, "b"}, {1, "a"}, {1, "b"}} {
+				d.Put(newPieceOffer(offer))
+			}
+
+			tc.expect(testifyassert.New(t), d)
+			d.Close()
+		})
+	}
+}
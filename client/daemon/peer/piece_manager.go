@@ -215,12 +215,20 @@ func (pm *pieceManager) DownloadPiece(ctx context.Context, request *DownloadPiec
 				Length: int64(request.piece.RangeSize),
 			},
 		},
+		// the piece may be requested from multiple parents in endgame mode
+		Exclusive: true,
 	}
 
 	result.Size, err = request.storage.WritePiece(ctx, writePieceRequest)
 	result.FinishTime = time.Now().UnixNano()
 
 	span.RecordError(err)
+	if errors.Is(err, storage.ErrPieceExists) {
+		request.log.Debugf("piece %d is written by other parent, ignore the data from peer: %s",
+			request.piece.PieceNum, request.DstPid)
+		return result, err
+	}
+
 	if err != nil {
 		request.log.Errorf("put piece to storage failed, piece num: %d, wrote: %d, error: %s",
 			request.piece.PieceNum, result.Size, err)
@@ -308,7 +316,7 @@ func (pm *pieceManager) DownloadSource(ctx context.Context, pt Task, peerTaskReq
 	log := pt.Log()
 	log.Infof("start to download from source")
 
-	backSourceRequest, err := source.NewRequestWithContext(ctx, peerTaskRequest.Url, peerTaskRequest.UrlMeta.Header)
+	backSourceRequest, err := source.NewRequestWithContext(ctx, peerTaskRequest.Url, config.SourceHeader(peerTaskRequest.UrlMeta.Header))
 	if err != nil {
 		return err
 	}
@@ -859,7 +867,7 @@ func (pm *pieceManager) downloadPieceFromSource(ctx context.Context,
 	parsedRange *clientutil.Range,
 	pieceCount int32,
 	downloadedPieceCount *atomic.Int32) error {
-	backSourceRequest, err := source.NewRequestWithContext(ctx, peerTaskRequest.Url, peerTaskRequest.UrlMeta.Header)
+	backSourceRequest, err := source.NewRequestWithContext(ctx, peerTaskRequest.Url, config.SourceHeader(peerTaskRequest.UrlMeta.Header))
 	if err != nil {
 		log.Errorf("build piece %d back source request error: %s", num, err)
 		return err
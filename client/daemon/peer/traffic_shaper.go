@@ -23,6 +23,7 @@ import (
 	"go.uber.org/atomic"
 	"golang.org/x/time/rate"
 
+	"d7y.io/dragonfly/v2/client/config"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/math"
 )
@@ -30,6 +31,7 @@ import (
 const (
 	TypePlainTrafficShaper    = "plain"
 	TypeSamplingTrafficShaper = "sampling"
+	TypePriorityTrafficShaper = "priority"
 )
 
 // TrafficShaper allocates bandwidth for running tasks dynamically
@@ -48,11 +50,14 @@ type TrafficShaper interface {
 	GetBandwidth() int64
 }
 
-func NewTrafficShaper(trafficShaperType string, totalRateLimit rate.Limit, computePieceSize func(int64) uint32) TrafficShaper {
+func NewTrafficShaper(trafficShaperType string, totalRateLimit rate.Limit, computePieceSize func(int64) uint32,
+	priorityOption config.PriorityTrafficShaperOption) TrafficShaper {
 	var ts TrafficShaper
 	switch trafficShaperType {
 	case TypeSamplingTrafficShaper:
 		ts = NewSamplingTrafficShaper(totalRateLimit, computePieceSize)
+	case TypePriorityTrafficShaper:
+		ts = NewPriorityTrafficShaper(totalRateLimit, computePieceSize, priorityOption)
 	case TypePlainTrafficShaper:
 		ts = NewPlainTrafficShaper()
 	default:
@@ -0,0 +1,291 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/math"
+)
+
+const (
+	// defaultTrafficShaperWeight is the weight of task when no weight is configured
+	defaultTrafficShaperWeight = 1
+
+	// infWeightBucket is the weight bucket of the weights greater than all bounds.
+	infWeightBucket = "+Inf"
+)
+
+type priorityTaskEntry struct {
+	ptc    *peerTaskConductor
+	weight int
+	// guaranteed minimum bandwidth
+	minBandwidth float64
+	// used bandwidth in the past second
+	lastSecondBandwidth *atomic.Int64
+	// need bandwidth in the next second
+	needBandwidth float64
+	// allocated bandwidth in the next second
+	allocatedBandwidth float64
+	// indicates if the bandwidth need to be updated, tasks added within one second don't need to be updated
+	needUpdate bool
+}
+
+// priorityTrafficShaper allocates bandwidth by weighted fair queueing across tasks,
+// every task is guaranteed a minimum bandwidth, the remaining bandwidth is shared by
+// the weight of tasks, and the bandwidth not used by a task is shared by the others.
+type priorityTrafficShaper struct {
+	*logger.SugaredLoggerOnWith
+	sync.RWMutex
+	computePieceSize func(int64) uint32
+	totalRateLimit   rate.Limit
+	option           config.PriorityTrafficShaperOption
+	// total used bandwidth in the past second
+	lastSecondBandwidth *atomic.Int64
+	// total used bandwidth in the current second
+	usingBandWidth *atomic.Int64
+	tasks          map[string]*priorityTaskEntry
+	stopCh         chan struct{}
+}
+
+func NewPriorityTrafficShaper(totalRateLimit rate.Limit, computePieceSize func(int64) uint32, option config.PriorityTrafficShaperOption) TrafficShaper {
+	log := logger.With("component", "TrafficShaper")
+	return &priorityTrafficShaper{
+		SugaredLoggerOnWith: log,
+		computePieceSize:    computePieceSize,
+		totalRateLimit:      totalRateLimit,
+		option:              option,
+		lastSecondBandwidth: atomic.NewInt64(0),
+		usingBandWidth:      atomic.NewInt64(0),
+		tasks:               make(map[string]*priorityTaskEntry),
+		stopCh:              make(chan struct{}),
+	}
+}
+
+func (ts *priorityTrafficShaper) Start() {
+	go func() {
+		// update bandwidth of all running tasks every second
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ts.lastSecondBandwidth.Store(ts.usingBandWidth.Load())
+				ts.usingBandWidth.Store(0)
+				ts.updateLimit()
+			case <-ts.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (ts *priorityTrafficShaper) Stop() {
+	close(ts.stopCh)
+}
+
+// updateLimit updates every task's need bandwidth by the used bandwidth in the past second, then reallocates
+func (ts *priorityTrafficShaper) updateLimit() {
+	ts.Lock()
+	defer ts.Unlock()
+	for _, te := range ts.tasks {
+		needBandwidth := float64(te.lastSecondBandwidth.Swap(0))
+		if !te.needUpdate {
+			// if this task is added within 1 second, don't reduce its need bandwidth this time
+			te.needUpdate = true
+			needBandwidth = math.Max(needBandwidth, te.needBandwidth)
+		}
+		if contentLength := te.ptc.contentLength.Load(); contentLength > 0 {
+			remainingLength := contentLength - te.ptc.completedLength.Load()
+			needBandwidth = math.Min(float64(remainingLength), needBandwidth)
+		}
+		te.needBandwidth = needBandwidth
+	}
+	ts.allocate()
+}
+
+// allocate allocates bandwidth for tasks, the caller must hold the lock
+func (ts *priorityTrafficShaper) allocate() {
+	allocateWeightedFairBandwidth(float64(ts.totalRateLimit), ts.tasks)
+	bandwidths := make(map[string]float64, len(metrics.TrafficShaperWeightBuckets)+1)
+	for _, te := range ts.tasks {
+		te.ptc.limiter.SetLimit(rate.Limit(te.allocatedBandwidth))
+		bandwidths[weightBucket(te.weight)] += te.allocatedBandwidth
+		ts.Debugf("period update limit, task %s, weight %d, need bandwidth %f, rate limit %f",
+			te.ptc.taskID, te.weight, te.needBandwidth, te.allocatedBandwidth)
+	}
+
+	// The allocated bandwidth is exported by weight bucket instead of task,
+	// the buckets without tasks are reset to zero.
+	for _, bound := range metrics.TrafficShaperWeightBuckets {
+		le := strconv.FormatFloat(bound, 'f', -1, 64)
+		metrics.TrafficShaperAllocatedBandwidth.WithLabelValues(le).Set(bandwidths[le])
+	}
+	metrics.TrafficShaperAllocatedBandwidth.WithLabelValues(infWeightBucket).Set(bandwidths[infWeightBucket])
+}
+
+// weightBucket returns the upper bound of the weight bucket which the weight belongs to.
+func weightBucket(weight int) string {
+	for _, bound := range metrics.TrafficShaperWeightBuckets {
+		if float64(weight) <= bound {
+			return strconv.FormatFloat(bound, 'f', -1, 64)
+		}
+	}
+
+	return infWeightBucket
+}
+
+// allocateWeightedFairBandwidth allocates total bandwidth to tasks by weighted max-min fairness:
+//  1. every task gets its guaranteed minimum bandwidth.
+//  2. the remaining bandwidth is shared by weight among the tasks whose need is not satisfied,
+//     the part beyond a task's need is shared by the others again.
+//  3. when all needs are satisfied, the left bandwidth is shared by weight among all tasks,
+//     so that tasks can grow in the next period.
+func allocateWeightedFairBandwidth(total float64, tasks map[string]*priorityTaskEntry) {
+	remaining := total
+	unsatisfied := make(map[string]*priorityTaskEntry, len(tasks))
+	for key, te := range tasks {
+		te.allocatedBandwidth = te.minBandwidth
+		remaining -= te.minBandwidth
+		if te.needBandwidth > te.allocatedBandwidth {
+			unsatisfied[key] = te
+		}
+	}
+
+	for remaining > 0 && len(unsatisfied) > 0 {
+		var totalWeight int
+		for _, te := range unsatisfied {
+			totalWeight += te.weight
+		}
+
+		var (
+			used      float64
+			satisfied []string
+		)
+		for key, te := range unsatisfied {
+			share := remaining * float64(te.weight) / float64(totalWeight)
+			if lack := te.needBandwidth - te.allocatedBandwidth; share >= lack {
+				share = lack
+				satisfied = append(satisfied, key)
+			}
+			te.allocatedBandwidth += share
+			used += share
+		}
+		remaining -= used
+
+		// all unsatisfied tasks got their weighted share
+		if len(satisfied) == 0 {
+			break
+		}
+		for _, key := range satisfied {
+			delete(unsatisfied, key)
+		}
+	}
+
+	if remaining <= 0 || len(tasks) == 0 {
+		return
+	}
+
+	var totalWeight int
+	for _, te := range tasks {
+		totalWeight += te.weight
+	}
+	for _, te := range tasks {
+		te.allocatedBandwidth += remaining * float64(te.weight) / float64(totalWeight)
+	}
+}
+
+// weight returns the weight of task, the weight in request header is used first,
+// then the weight of tag and the weight of application.
+func (ts *priorityTrafficShaper) weight(ptc *peerTaskConductor) int {
+	if urlMeta := ptc.request.UrlMeta; urlMeta != nil {
+		if value, ok := urlMeta.Header[config.HeaderDragonflyWeight]; ok {
+			if weight, err := strconv.Atoi(value); err == nil && weight > 0 {
+				return weight
+			}
+			ts.Warnf("invalid weight %q of task %s", value, ptc.taskID)
+		}
+
+		if weight, ok := ts.option.TagWeights[urlMeta.Tag]; ok && weight > 0 {
+			return weight
+		}
+
+		if weight, ok := ts.option.ApplicationWeights[urlMeta.Application]; ok && weight > 0 {
+			return weight
+		}
+	}
+
+	if ts.option.DefaultWeight > 0 {
+		return ts.option.DefaultWeight
+	}
+
+	return defaultTrafficShaperWeight
+}
+
+func (ts *priorityTrafficShaper) AddTask(taskID string, ptc *peerTaskConductor) {
+	ts.Lock()
+	defer ts.Unlock()
+	pieceSize := ts.computePieceSize(ptc.contentLength.Load())
+	minBandwidth := float64(ts.option.MinRateLimit.Limit)
+	if minBandwidth <= 0 {
+		minBandwidth = float64(pieceSize)
+	}
+
+	te := &priorityTaskEntry{
+		ptc:                 ptc,
+		weight:              ts.weight(ptc),
+		minBandwidth:        minBandwidth,
+		lastSecondBandwidth: atomic.NewInt64(0),
+		// a new task needs as much bandwidth as possible
+		needBandwidth: float64(ts.totalRateLimit),
+	}
+	ts.tasks[taskID] = te
+	metrics.TrafficShaperTaskWeight.Observe(float64(te.weight))
+	ts.allocate()
+}
+
+func (ts *priorityTrafficShaper) RemoveTask(taskID string) {
+	ts.Lock()
+	defer ts.Unlock()
+	if _, ok := ts.tasks[taskID]; !ok {
+		return
+	}
+
+	delete(ts.tasks, taskID)
+	ts.allocate()
+}
+
+func (ts *priorityTrafficShaper) Record(taskID string, n int) {
+	ts.usingBandWidth.Add(int64(n))
+	ts.RLock()
+	if te, ok := ts.tasks[taskID]; ok {
+		te.lastSecondBandwidth.Add(int64(n))
+	}
+	ts.RUnlock()
+}
+
+func (ts *priorityTrafficShaper) GetBandwidth() int64 {
+	return ts.lastSecondBandwidth.Load()
+}
@@ -235,7 +235,7 @@ func trafficShaperSetupMockManager(ctrl *gomock.Controller, ts *trafficShaperTes
 		runningPeerTasks: sync.Map{},
 		trafficShaper: NewTrafficShaper(opt.trafficShaperType, opt.totalRateLimit, func(contentLength int64) uint32 {
 			return opt.pieceSize
-		}),
+		}, config.PriorityTrafficShaperOption{}),
 		TaskManagerOption: TaskManagerOption{
 			SchedulerClient:  schedulerClient,
 			PerPeerRateLimit: opt.perPeerRateLimit,
@@ -469,7 +469,7 @@ func TestTrafficShaper_TaskSuite(t *testing.T) {
 		t.Run(_tc.name, func(t *testing.T) {
 			assert := testifyassert.New(t)
 			require := testifyrequire.New(t)
-			for _, trafficShaperType := range []string{"plain", "sampling"} {
+			for _, trafficShaperType := range []string{"plain", "sampling", "priority"} {
 				// dup a new test case with the task type
 				logger.Infof("-------------------- test %s, %s traffic shaper started --------------------",
 					_tc.name, trafficShaperType)
@@ -609,3 +609,158 @@ func (ts *trafficShaperTestSpec) run(assert *testifyassert.Assertions, require *
 		assert.True(success, "task should success")
 	}
 }
+
+func TestAllocateWeightedFairBandwidth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		total    float64
+		tasks    map[string]*priorityTaskEntry
+		expected map[string]float64
+	}{
+		{
+			name:     "no task",
+			total:    1000,
+			tasks:    map[string]*priorityTaskEntry{},
+			expected: map[string]float64{},
+		},
+		{
+			name:  "share by weight when all tasks are not satisfied",
+			total: 1000,
+			tasks: map[string]*priorityTaskEntry{
+				"foo": {weight: 3, minBandwidth: 100, needBandwidth: 1000},
+				"bar": {weight: 1, minBandwidth: 100, needBandwidth: 1000},
+			},
+			expected: map[string]float64{
+				"foo": 700,
+				"bar": 300,
+			},
+		},
+		{
+			name:  "bandwidth not used by satisfied task is shared by others",
+			total: 1000,
+			tasks: map[string]*priorityTaskEntry{
+				"foo": {weight: 3, minBandwidth: 100, needBandwidth: 200},
+				"bar": {weight: 1, minBandwidth: 100, needBandwidth: 1000},
+			},
+			expected: map[string]float64{
+				"foo": 200,
+				"bar": 800,
+			},
+		},
+		{
+			name:  "left bandwidth is shared by weight when all tasks are satisfied",
+			total: 1000,
+			tasks: map[string]*priorityTaskEntry{
+				"foo": {weight: 1, minBandwidth: 100, needBandwidth: 200},
+				"bar": {weight: 1, minBandwidth: 100, needBandwidth: 400},
+			},
+			expected: map[string]float64{
+				"foo": 400,
+				"bar": 600,
+			},
+		},
+		{
+			name:  "minimum bandwidth is guaranteed",
+			total: 1000,
+			tasks: map[string]*priorityTaskEntry{
+				"foo": {weight: 3, minBandwidth: 100, needBandwidth: 1000},
+				"bar": {weight: 1, minBandwidth: 500, needBandwidth: 1000},
+			},
+			expected: map[string]float64{
+				"foo": 400,
+				"bar": 600,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			allocateWeightedFairBandwidth(tc.total, tc.tasks)
+			for key, te := range tc.tasks {
+				assert.InDelta(tc.expected[key], te.allocatedBandwidth, 0.001, key)
+			}
+		})
+	}
+}
+
+func TestWeightBucket(t *testing.T) {
+	testCases := []struct {
+		weight   int
+		expected string
+	}{
+		{weight: 1, expected: "1"},
+		{weight: 3, expected: "4"},
+		{weight: 64, expected: "64"},
+		{weight: 1000, expected: "+Inf"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			assert.Equal(tc.expected, weightBucket(tc.weight))
+		})
+	}
+}
+
+func TestPriorityTrafficShaper_Weight(t *testing.T) {
+	option := config.PriorityTrafficShaperOption{
+		DefaultWeight:      2,
+		TagWeights:         map[string]int{"foo": 4},
+		ApplicationWeights: map[string]int{"bar": 8},
+	}
+
+	testCases := []struct {
+		name     string
+		option   config.PriorityTrafficShaperOption
+		urlMeta  *commonv1.UrlMeta
+		expected int
+	}{
+		{
+			name:     "weight in header",
+			option:   option,
+			urlMeta:  &commonv1.UrlMeta{Tag: "foo", Application: "bar", Header: map[string]string{config.HeaderDragonflyWeight: "16"}},
+			expected: 16,
+		},
+		{
+			name:     "invalid weight in header",
+			option:   option,
+			urlMeta:  &commonv1.UrlMeta{Tag: "foo", Header: map[string]string{config.HeaderDragonflyWeight: "-1"}},
+			expected: 4,
+		},
+		{
+			name:     "weight of tag",
+			option:   option,
+			urlMeta:  &commonv1.UrlMeta{Tag: "foo", Application: "bar"},
+			expected: 4,
+		},
+		{
+			name:     "weight of application",
+			option:   option,
+			urlMeta:  &commonv1.UrlMeta{Application: "bar"},
+			expected: 8,
+		},
+		{
+			name:     "default weight",
+			option:   option,
+			urlMeta:  &commonv1.UrlMeta{},
+			expected: 2,
+		},
+		{
+			name:     "without default weight",
+			option:   config.PriorityTrafficShaperOption{},
+			urlMeta:  nil,
+			expected: defaultTrafficShaperWeight,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := NewPriorityTrafficShaper(0, nil, tc.option).(*priorityTrafficShaper)
+			ptc := &peerTaskConductor{
+				request: &schedulerv1.PeerTaskRequest{UrlMeta: tc.urlMeta},
+			}
+			testifyassert.Equal(t, tc.expected, ts.weight(ptc))
+		})
+	}
+}
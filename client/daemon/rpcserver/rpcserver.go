@@ -580,7 +580,7 @@ func (s *server) recursiveDownloadWithDirectMetadata(
 		}
 
 		parentReq := queue.PopFront()
-		request, err := source.NewRequestWithContext(ctx, parentReq.Url, parentReq.UrlMeta.Header)
+		request, err := source.NewRequestWithContext(ctx, parentReq.Url, config.SourceHeader(parentReq.UrlMeta.Header))
 		if err != nil {
 			log.Errorf("generate url [%v] request error: %v", request.URL, err)
 			span.RecordError(err)
@@ -18,11 +18,13 @@ package rpcserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"time"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
@@ -140,6 +142,7 @@ func (s *seeder) ObtainSeeds(seedRequest *cdnsystemv1.SeedRequest, seedsServer c
 		seedsServer:         seedsServer,
 		seedTaskRequest:     &req,
 		startNanoSecond:     time.Now().UnixNano(),
+		cancelOnDisconnect:  isCancelSeedOnDisconnect(seedsServer.Context()),
 	}
 	defer resp.Span.End()
 
@@ -158,6 +161,19 @@ type seedSynchronizer struct {
 	seedTaskRequest *peer.SeedTaskRequest
 	startNanoSecond int64
 	attributeSent   bool
+	// cancelOnDisconnect stands the seed task is canceled when the client disconnects.
+	cancelOnDisconnect bool
+}
+
+// isCancelSeedOnDisconnect returns whether the client requests canceling the seed task when it disconnects.
+func isCancelSeedOnDisconnect(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	values := md.Get(common.CancelSeedOnDisconnectKey)
+	return len(values) > 0 && values[0] == "true"
 }
 
 func (s *seedSynchronizer) sendPieceSeeds(reuse bool) (err error) {
@@ -173,6 +189,10 @@ func (s *seedSynchronizer) sendPieceSeeds(reuse bool) (err error) {
 			s.Errorf("context done due to %s", err.Error())
 			s.Span.RecordError(err)
 			s.Span.SetAttributes(config.AttributeSeedTaskSuccess.Bool(false))
+			if errors.Is(err, context.Canceled) && s.cancelOnDisconnect && s.Cancel != nil {
+				s.Infof("cancel seed task due to client disconnected")
+				s.Cancel("seed task is canceled by client")
+			}
 			return err
 		case <-s.Success:
 			s.Infof("seed task success, send reminding piece seeds")
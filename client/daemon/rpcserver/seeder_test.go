@@ -31,6 +31,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
@@ -391,3 +392,39 @@ func setupSeederServerAndClient(t *testing.T, srv *server, sd *seeder, assert *t
 
 	return port, client
 }
+
+func Test_isCancelSeedOnDisconnect(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		expect bool
+	}{
+		{
+			name:   "context without metadata",
+			ctx:    context.Background(),
+			expect: false,
+		},
+		{
+			name:   "metadata without cancel key",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs("foo", "bar")),
+			expect: false,
+		},
+		{
+			name:   "metadata with cancel key",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CancelSeedOnDisconnectKey, "true")),
+			expect: true,
+		},
+		{
+			name:   "metadata with invalid cancel value",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs(common.CancelSeedOnDisconnectKey, "foo")),
+			expect: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			assert.Equal(tc.expect, isCancelSeedOnDisconnect(tc.ctx))
+		})
+	}
+}
@@ -61,10 +61,14 @@ type localTaskStore struct {
 	content []byte
 
 	subtasks map[PeerTaskMetadata]*localSubTaskStore
+
+	// writingPieces stands the exclusive pieces which are being written to the data file directly
+	writingPieces map[int32]struct{}
 }
 
 var _ TaskStorageDriver = (*localTaskStore)(nil)
 var _ Reclaimer = (*localTaskStore)(nil)
+var _ pieceCommitter = (*localTaskStore)(nil)
 
 func (t *localTaskStore) touch() {
 	access := time.Now().UnixNano()
@@ -98,6 +102,10 @@ func (t *localTaskStore) SubTask(req *RegisterSubTaskRequest) *localSubTaskStore
 func (t *localTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest) (int64, error) {
 	t.touch()
 
+	if req.Exclusive {
+		return writeExclusivePiece(t, t.DataFilePath, req.Range.Start, req)
+	}
+
 	// piece already exists
 	t.RLock()
 	if piece, ok := t.Pieces[req.Num]; ok {
@@ -184,6 +192,33 @@ func (t *localTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest)
 	return n, nil
 }
 
+func (t *localTaskStore) pieceExists(num int32) bool {
+	_, ok := t.Pieces[num]
+	return ok
+}
+
+func (t *localTaskStore) markWriting(num int32) bool {
+	if t.writingPieces == nil {
+		t.writingPieces = map[int32]struct{}{}
+	}
+
+	if _, ok := t.writingPieces[num]; ok {
+		return false
+	}
+
+	t.writingPieces[num] = struct{}{}
+	return true
+}
+
+func (t *localTaskStore) unmarkWriting(num int32) {
+	delete(t.writingPieces, num)
+}
+
+func (t *localTaskStore) commitPiece(n int64, req *WritePieceRequest) {
+	t.Pieces[req.Num] = req.PieceMetadata
+	t.genMetadata(n, req)
+}
+
 func (t *localTaskStore) genMetadata(n int64, req *WritePieceRequest) {
 	if req.GenMetadata == nil {
 		return
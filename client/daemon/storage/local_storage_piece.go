@@ -0,0 +1,147 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"time"
+)
+
+// pieceCommitter is implemented by the task stores which write the exclusive pieces,
+// the methods except the lock methods must be called with the lock held.
+type pieceCommitter interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+
+	// pieceExists reports whether the piece is committed.
+	pieceExists(num int32) bool
+	// markWriting marks the piece is being written to the data file directly,
+	// it returns false when the piece is already marked by other request.
+	markWriting(num int32) bool
+	// unmarkWriting clears the mark of markWriting.
+	unmarkWriting(num int32)
+	// commitPiece records the written piece.
+	commitPiece(n int64, req *WritePieceRequest)
+}
+
+// writeExclusivePiece writes the piece which may be requested from multiple parents at the same time.
+// Only one request of the piece writes to the data file directly, the other requests read the piece
+// into buffer and write it under the lock. The piece is committed only when it does not exist,
+// so the verified piece is never overwritten, and ErrPieceExists is returned to the other requests.
+func writeExclusivePiece(pc pieceCommitter, dataFilePath string, offset int64, req *WritePieceRequest) (int64, error) {
+	pc.Lock()
+	if pc.pieceExists(req.Num) {
+		pc.Unlock()
+		return 0, ErrPieceExists
+	}
+	direct := pc.markWriting(req.Num)
+	pc.Unlock()
+
+	if direct {
+		defer func() {
+			pc.Lock()
+			pc.unmarkWriting(req.Num)
+			pc.Unlock()
+		}()
+	}
+
+	start := time.Now().UnixNano()
+	file, err := os.OpenFile(dataFilePath, os.O_RDWR, defaultFileMode)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var (
+		buf *bytes.Buffer
+		w   io.Writer
+	)
+	if direct {
+		w = &pieceFileWriter{pc: pc, num: req.Num, file: file, offset: offset}
+	} else {
+		buf = bytes.NewBuffer(make([]byte, 0, req.Range.Length))
+		w = buf
+	}
+
+	n, err := io.Copy(w, io.LimitReader(req.Reader, req.Range.Length))
+	if err != nil {
+		return n, err
+	}
+
+	if n != req.Range.Length {
+		return n, ErrShortRead
+	}
+
+	if err := verifyPieceDigest(req); err != nil {
+		return n, err
+	}
+
+	pc.Lock()
+	defer pc.Unlock()
+	if pc.pieceExists(req.Num) {
+		return n, ErrPieceExists
+	}
+
+	if !direct {
+		if _, err := file.WriteAt(buf.Bytes(), offset); err != nil {
+			return n, err
+		}
+	}
+
+	req.PieceMetadata.Cost = uint64(time.Now().UnixNano() - start)
+	pc.commitPiece(n, req)
+	return n, nil
+}
+
+// verifyPieceDigest reads the end of the piece reader, the digest reader verifies
+// the digest only when it reaches EOF, which is not read by io.LimitReader.
+func verifyPieceDigest(req *WritePieceRequest) error {
+	if req.PieceMetadata.Md5 == "" {
+		return nil
+	}
+
+	if _, err := req.Reader.Read(nil); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// pieceFileWriter writes the piece to the data file under the read lock,
+// it stops with ErrPieceExists once the piece is committed by other request.
+type pieceFileWriter struct {
+	pc     pieceCommitter
+	num    int32
+	file   *os.File
+	offset int64
+}
+
+func (w *pieceFileWriter) Write(p []byte) (int, error) {
+	w.pc.RLock()
+	defer w.pc.RUnlock()
+	if w.pc.pieceExists(w.num) {
+		return 0, ErrPieceExists
+	}
+
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
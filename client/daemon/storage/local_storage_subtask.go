@@ -42,9 +42,18 @@ type localSubTaskStore struct {
 	invalid atomic.Bool
 
 	Range *util.Range
+
+	// writingPieces stands the exclusive pieces which are being written to the data file directly
+	writingPieces map[int32]struct{}
 }
 
+var _ pieceCommitter = (*localSubTaskStore)(nil)
+
 func (t *localSubTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest) (int64, error) {
+	if req.Exclusive {
+		return writeExclusivePiece(t, t.parent.DataFilePath, t.Range.Start+req.Range.Start, req)
+	}
+
 	// piece already exists
 	t.RLock()
 	if piece, ok := t.Pieces[req.Num]; ok {
@@ -360,6 +369,33 @@ func (t *localSubTaskStore) IsInvalid(req *PeerTaskMetadata) (bool, error) {
 	return t.invalid.Load(), nil
 }
 
+func (t *localSubTaskStore) pieceExists(num int32) bool {
+	_, ok := t.Pieces[num]
+	return ok
+}
+
+func (t *localSubTaskStore) markWriting(num int32) bool {
+	if t.writingPieces == nil {
+		t.writingPieces = map[int32]struct{}{}
+	}
+
+	if _, ok := t.writingPieces[num]; ok {
+		return false
+	}
+
+	t.writingPieces[num] = struct{}{}
+	return true
+}
+
+func (t *localSubTaskStore) unmarkWriting(num int32) {
+	delete(t.writingPieces, num)
+}
+
+func (t *localSubTaskStore) commitPiece(n int64, req *WritePieceRequest) {
+	t.Pieces[req.Num] = req.PieceMetadata
+	t.genMetadata(n, req)
+}
+
 func (t *localSubTaskStore) genMetadata(n int64, req *WritePieceRequest) {
 	if req.GenMetadata == nil {
 		return
@@ -317,6 +317,174 @@ func TestLocalTaskStore_PutAndGetPiece(t *testing.T) {
 	}
 }
 
+// gateReader blocks the reads after the first limit bytes until gate is closed,
+// reached is closed when the first limit bytes are read.
+type gateReader struct {
+	r       io.Reader
+	limit   int
+	read    int
+	reached chan struct{}
+	gate    chan struct{}
+}
+
+func (g *gateReader) Read(p []byte) (int, error) {
+	if g.read >= g.limit && g.gate != nil {
+		close(g.reached)
+		<-g.gate
+		g.gate = nil
+	}
+
+	if g.gate != nil && len(p) > g.limit-g.read {
+		p = p[:g.limit-g.read]
+	}
+
+	n, err := g.r.Read(p)
+	g.read += n
+	return n, err
+}
+
+func TestLocalTaskStore_WriteExclusivePiece(t *testing.T) {
+	pieceSize := 4096
+	validData := make([]byte, pieceSize)
+	rand.Read(validData)
+	corruptData := make([]byte, pieceSize)
+	rand.Read(corruptData)
+	pieceMd5 := calcPieceMd5(validData)
+
+	// newParentReader returns the piece reader with digest like the piece downloader,
+	// the reader blocks after a half of piece is read when gate is not nil
+	newParentReader := func(data []byte, reached, gate chan struct{}) io.Reader {
+		var r io.Reader = bytes.NewReader(data)
+		if gate != nil {
+			r = &gateReader{r: r, limit: pieceSize / 2, reached: reached, gate: gate}
+		}
+
+		r, _ = digest.NewReader(io.LimitReader(r, int64(pieceSize)), digest.WithDigest(pieceMd5))
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		direct []byte
+		other  []byte
+		expect func(t *testing.T, directErr, otherErr error)
+	}{
+		{
+			name:   "corrupt parent writes directly and valid parent commits the piece",
+			direct: corruptData,
+			other:  validData,
+			expect: func(t *testing.T, directErr, otherErr error) {
+				assert := testifyassert.New(t)
+				assert.ErrorIs(directErr, ErrPieceExists)
+				assert.Nil(otherErr)
+			},
+		},
+		{
+			name:   "valid parent writes directly and corrupt parent is not committed",
+			direct: validData,
+			other:  corruptData,
+			expect: func(t *testing.T, directErr, otherErr error) {
+				assert := testifyassert.New(t)
+				assert.Nil(directErr)
+				assert.EqualError(otherErr, "digest encoded not match")
+			},
+		},
+		{
+			name:   "valid parents race and only one commits the piece",
+			direct: validData,
+			other:  validData,
+			expect: func(t *testing.T, directErr, otherErr error) {
+				assert := testifyassert.New(t)
+				assert.ErrorIs(directErr, ErrPieceExists)
+				assert.Nil(otherErr)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			var (
+				taskID = "task-exclusive-d4bb1c273a9889fea14abd4651994fe8"
+				peerID = "peer-exclusive-d4bb1c273a9889fea14abd4651994fe8"
+			)
+			sm, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy,
+				&config.StorageOption{
+					DataPath: test.DataDir,
+					TaskExpireTime: clientutil.Duration{
+						Duration: time.Minute,
+					},
+				}, func(request CommonTaskRequest) {
+				})
+			assert.Nil(err)
+
+			ts, err := sm.(*storageManager).CreateTask(
+				&RegisterTaskRequest{
+					PeerTaskMetadata: PeerTaskMetadata{
+						PeerID: peerID,
+						TaskID: taskID,
+					},
+					ContentLength: int64(pieceSize),
+				})
+			assert.Nil(err)
+			defer ts.(*localTaskStore).Reclaim()
+
+			newRequest := func(r io.Reader) *WritePieceRequest {
+				return &WritePieceRequest{
+					PeerTaskMetadata: PeerTaskMetadata{
+						PeerID: peerID,
+						TaskID: taskID,
+					},
+					PieceMetadata: PieceMetadata{
+						Num: 0,
+						Md5: pieceMd5,
+						Range: clientutil.Range{
+							Start:  0,
+							Length: int64(pieceSize),
+						},
+						Style: commonv1.PieceStyle_PLAIN,
+					},
+					Reader:    r,
+					Exclusive: true,
+				}
+			}
+
+			// the direct parent writes a half of piece to the data file and blocks
+			reached, gate := make(chan struct{}), make(chan struct{})
+			directErrCh := make(chan error, 1)
+			go func() {
+				_, err := ts.WritePiece(context.Background(), newRequest(newParentReader(tc.direct, reached, gate)))
+				directErrCh <- err
+			}()
+			<-reached
+
+			// the other parent is buffered because the piece is being written by the direct parent
+			_, otherErr := ts.WritePiece(context.Background(), newRequest(newParentReader(tc.other, nil, nil)))
+			close(gate)
+			directErr := <-directErrCh
+			tc.expect(t, directErr, otherErr)
+
+			// the late request does not write the committed piece
+			_, err = ts.WritePiece(context.Background(), newRequest(newParentReader(corruptData, nil, nil)))
+			assert.ErrorIs(err, ErrPieceExists)
+
+			rd, cl, err := ts.ReadPiece(context.Background(), &ReadPieceRequest{
+				PeerTaskMetadata: PeerTaskMetadata{
+					TaskID: taskID,
+				},
+				PieceMetadata: PieceMetadata{
+					Num: 0,
+				},
+			})
+			assert.Nil(err)
+			defer cl.Close()
+			data, err := io.ReadAll(rd)
+			assert.Nil(err)
+			assert.Equal(validData, data)
+		})
+	}
+}
+
 func TestLocalTaskStore_StoreTaskData_Simple(t *testing.T) {
 	assert := testifyassert.New(t)
 	src := path.Join(test.DataDir, taskData)
@@ -75,6 +75,9 @@ type WritePieceRequest struct {
 	Reader        io.Reader
 	// GenMetadata is used after the last piece in back source case
 	GenMetadata func(n int64) (total int32, contentLength int64, gen bool)
+	// Exclusive stands the piece may be requested from multiple parents at the same time,
+	// only one request commits the piece and the others get ErrPieceExists
+	Exclusive bool
 }
 
 type StoreRequest struct {
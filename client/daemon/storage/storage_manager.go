@@ -112,6 +112,7 @@ type Manager interface {
 var (
 	ErrTaskNotFound     = errors.New("task not found")
 	ErrPieceNotFound    = errors.New("piece not found")
+	ErrPieceExists      = errors.New("piece already exists")
 	ErrPieceCountNotSet = errors.New("total piece count not set")
 	ErrDigestNotSet     = errors.New("digest not set")
 	ErrInvalidDigest    = errors.New("invalid digest")
@@ -68,6 +68,40 @@ type Dfstore interface {
 
 	// IsObjectExistWithContext returns whether the object exists.
 	IsObjectExistWithContext(ctx context.Context, input *IsObjectExistInput) (bool, error)
+
+	// CreateMultipartUploadRequestWithContext returns *http.Request of initiating multipart upload.
+	CreateMultipartUploadRequestWithContext(ctx context.Context, input *CreateMultipartUploadInput) (*http.Request, error)
+
+	// CreateMultipartUploadWithContext initiates multipart upload and returns the upload id.
+	CreateMultipartUploadWithContext(ctx context.Context, input *CreateMultipartUploadInput) (string, error)
+
+	// UploadPartRequestWithContext returns *http.Request of uploading part.
+	UploadPartRequestWithContext(ctx context.Context, input *UploadPartInput) (*http.Request, error)
+
+	// UploadPartWithContext uploads part of multipart upload.
+	UploadPartWithContext(ctx context.Context, input *UploadPartInput) (*pkgobjectstorage.PartMetadata, error)
+
+	// CompleteMultipartUploadRequestWithContext returns *http.Request of completing multipart upload.
+	CompleteMultipartUploadRequestWithContext(ctx context.Context, input *CompleteMultipartUploadInput) (*http.Request, error)
+
+	// CompleteMultipartUploadWithContext completes multipart upload with the uploaded parts.
+	CompleteMultipartUploadWithContext(ctx context.Context, input *CompleteMultipartUploadInput) error
+
+	// AbortMultipartUploadRequestWithContext returns *http.Request of aborting multipart upload.
+	AbortMultipartUploadRequestWithContext(ctx context.Context, input *AbortMultipartUploadInput) (*http.Request, error)
+
+	// AbortMultipartUploadWithContext aborts multipart upload and deletes the uploaded parts.
+	AbortMultipartUploadWithContext(ctx context.Context, input *AbortMultipartUploadInput) error
+
+	// ListPartsRequestWithContext returns *http.Request of listing the uploaded parts.
+	ListPartsRequestWithContext(ctx context.Context, input *ListPartsInput) (*http.Request, error)
+
+	// ListPartsWithContext returns metadata of the uploaded parts of multipart upload.
+	ListPartsWithContext(ctx context.Context, input *ListPartsInput) ([]*pkgobjectstorage.PartMetadata, error)
+
+	// PutMultipartObjectWithContext puts data of object by multipart upload and returns the upload id,
+	// the upload is resumed by the upload id of input when the previous upload is interrupted.
+	PutMultipartObjectWithContext(ctx context.Context, input *PutMultipartObjectInput) (string, error)
 }
 
 // dfstore provides object storage function.
@@ -0,0 +1,602 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dfstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-http-utils/headers"
+
+	"d7y.io/dragonfly/v2/client/daemon/objectstorage"
+	pkgobjectstorage "d7y.io/dragonfly/v2/pkg/objectstorage"
+)
+
+const (
+	// DefaultPartSize is the default size of parts in multipart upload.
+	DefaultPartSize = 64 * 1024 * 1024
+)
+
+// md5ETagRegexp matches the ETag of part which is the md5 of part data.
+var md5ETagRegexp = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// CreateMultipartUploadInput is used to construct request of initiating multipart upload.
+type CreateMultipartUploadInput struct {
+	// BucketName is bucket name.
+	BucketName string
+
+	// ObjectKey is object key.
+	ObjectKey string
+
+	// Digest is the digest of object, it is used to verify
+	// the object downloaded by P2P.
+	Digest string
+}
+
+// Validate validates CreateMultipartUploadInput fields.
+func (i *CreateMultipartUploadInput) Validate() error {
+	if i.BucketName == "" {
+		return errors.New("invalid BucketName")
+	}
+
+	if i.ObjectKey == "" {
+		return errors.New("invalid ObjectKey")
+	}
+
+	return nil
+}
+
+// CreateMultipartUploadRequestWithContext returns *http.Request of initiating multipart upload.
+func (dfs *dfstore) CreateMultipartUploadRequestWithContext(ctx context.Context, input *CreateMultipartUploadInput) (*http.Request, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(dfs.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = filepath.Join("buckets", input.BucketName, "objects", input.ObjectKey)
+
+	query := u.Query()
+	if input.Digest != "" {
+		query.Set("digest", input.Digest)
+	}
+	u.RawQuery = "uploads"
+	if encoded := query.Encode(); encoded != "" {
+		u.RawQuery += "&" + encoded
+	}
+
+	return http.NewRequestWithContext(ctx, http.MethodPost, u.String(), nil)
+}
+
+// CreateMultipartUploadWithContext initiates multipart upload and returns the upload id.
+func (dfs *dfstore) CreateMultipartUploadWithContext(ctx context.Context, input *CreateMultipartUploadInput) (string, error) {
+	req, err := dfs.CreateMultipartUploadRequestWithContext(ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	var resp objectstorage.CreateMultipartUploadResponse
+	if err := dfs.doJSON(req, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.UploadID, nil
+}
+
+// UploadPartInput is used to construct request of uploading part.
+type UploadPartInput struct {
+	// BucketName is bucket name.
+	BucketName string
+
+	// ObjectKey is object key.
+	ObjectKey string
+
+	// UploadID is the id of multipart upload.
+	UploadID string
+
+	// PartNumber is number of part, it starts from 1.
+	PartNumber int64
+
+	// Size is the length of data in reader.
+	Size int64
+
+	// Reader is reader of part.
+	Reader io.Reader
+}
+
+// Validate validates UploadPartInput fields.
+func (i *UploadPartInput) Validate() error {
+	if i.BucketName == "" {
+		return errors.New("invalid BucketName")
+	}
+
+	if i.ObjectKey == "" {
+		return errors.New("invalid ObjectKey")
+	}
+
+	if i.UploadID == "" {
+		return errors.New("invalid UploadID")
+	}
+
+	if i.PartNumber < 1 || i.PartNumber > pkgobjectstorage.MaxPartNumber {
+		return errors.New("invalid PartNumber")
+	}
+
+	if i.Size <= 0 {
+		return errors.New("invalid Size")
+	}
+
+	return nil
+}
+
+// UploadPartRequestWithContext returns *http.Request of uploading part.
+func (dfs *dfstore) UploadPartRequestWithContext(ctx context.Context, input *UploadPartInput) (*http.Request, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(dfs.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = filepath.Join("buckets", input.BucketName, "objects", input.ObjectKey)
+
+	query := u.Query()
+	query.Set("uploadId", input.UploadID)
+	query.Set("partNumber", fmt.Sprint(input.PartNumber))
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), io.LimitReader(input.Reader, input.Size))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = input.Size
+
+	return req, nil
+}
+
+// UploadPartWithContext uploads part of multipart upload.
+func (dfs *dfstore) UploadPartWithContext(ctx context.Context, input *UploadPartInput) (*pkgobjectstorage.PartMetadata, error) {
+	req, err := dfs.UploadPartRequestWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var part objectstorage.Part
+	if err := dfs.doJSON(req, &part); err != nil {
+		return nil, err
+	}
+
+	return &pkgobjectstorage.PartMetadata{
+		PartNumber: part.PartNumber,
+		ETag:       part.ETag,
+		Size:       part.Size,
+	}, nil
+}
+
+// CompleteMultipartUploadInput is used to construct request of completing multipart upload.
+type CompleteMultipartUploadInput struct {
+	// BucketName is bucket name.
+	BucketName string
+
+	// ObjectKey is object key.
+	ObjectKey string
+
+	// UploadID is the id of multipart upload.
+	UploadID string
+
+	// Parts is the uploaded parts.
+	Parts []*pkgobjectstorage.PartMetadata
+}
+
+// Validate validates CompleteMultipartUploadInput fields.
+func (i *CompleteMultipartUploadInput) Validate() error {
+	if i.BucketName == "" {
+		return errors.New("invalid BucketName")
+	}
+
+	if i.ObjectKey == "" {
+		return errors.New("invalid ObjectKey")
+	}
+
+	if i.UploadID == "" {
+		return errors.New("invalid UploadID")
+	}
+
+	if len(i.Parts) == 0 || len(i.Parts) > pkgobjectstorage.MaxPartNumber {
+		return errors.New("invalid Parts")
+	}
+
+	return nil
+}
+
+// CompleteMultipartUploadRequestWithContext returns *http.Request of completing multipart upload.
+func (dfs *dfstore) CompleteMultipartUploadRequestWithContext(ctx context.Context, input *CompleteMultipartUploadInput) (*http.Request, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	var body objectstorage.CompleteMultipartUploadRequest
+	for _, part := range input.Parts {
+		body.Parts = append(body.Parts, objectstorage.Part{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+			Size:       part.Size,
+		})
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(dfs.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = filepath.Join("buckets", input.BucketName, "objects", input.ObjectKey)
+
+	query := u.Query()
+	query.Set("uploadId", input.UploadID)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(headers.ContentType, "application/json")
+
+	return req, nil
+}
+
+// CompleteMultipartUploadWithContext completes multipart upload with the uploaded parts.
+func (dfs *dfstore) CompleteMultipartUploadWithContext(ctx context.Context, input *CompleteMultipartUploadInput) error {
+	req, err := dfs.CompleteMultipartUploadRequestWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	return dfs.doJSON(req, nil)
+}
+
+// AbortMultipartUploadInput is used to construct request of aborting multipart upload.
+type AbortMultipartUploadInput struct {
+	// BucketName is bucket name.
+	BucketName string
+
+	// ObjectKey is object key.
+	ObjectKey string
+
+	// UploadID is the id of multipart upload.
+	UploadID string
+}
+
+// Validate validates AbortMultipartUploadInput fields.
+func (i *AbortMultipartUploadInput) Validate() error {
+	if i.BucketName == "" {
+		return errors.New("invalid BucketName")
+	}
+
+	if i.ObjectKey == "" {
+		return errors.New("invalid ObjectKey")
+	}
+
+	if i.UploadID == "" {
+		return errors.New("invalid UploadID")
+	}
+
+	return nil
+}
+
+// AbortMultipartUploadRequestWithContext returns *http.Request of aborting multipart upload.
+func (dfs *dfstore) AbortMultipartUploadRequestWithContext(ctx context.Context, input *AbortMultipartUploadInput) (*http.Request, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(dfs.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = filepath.Join("buckets", input.BucketName, "objects", input.ObjectKey)
+
+	query := u.Query()
+	query.Set("uploadId", input.UploadID)
+	u.RawQuery = query.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodDelete, u.String(), nil)
+}
+
+// AbortMultipartUploadWithContext aborts multipart upload and deletes the uploaded parts.
+func (dfs *dfstore) AbortMultipartUploadWithContext(ctx context.Context, input *AbortMultipartUploadInput) error {
+	req, err := dfs.AbortMultipartUploadRequestWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	return dfs.doJSON(req, nil)
+}
+
+// ListPartsInput is used to construct request of listing the uploaded parts.
+type ListPartsInput struct {
+	// BucketName is bucket name.
+	BucketName string
+
+	// ObjectKey is object key.
+	ObjectKey string
+
+	// UploadID is the id of multipart upload.
+	UploadID string
+}
+
+// Validate validates ListPartsInput fields.
+func (i *ListPartsInput) Validate() error {
+	if i.BucketName == "" {
+		return errors.New("invalid BucketName")
+	}
+
+	if i.ObjectKey == "" {
+		return errors.New("invalid ObjectKey")
+	}
+
+	if i.UploadID == "" {
+		return errors.New("invalid UploadID")
+	}
+
+	return nil
+}
+
+// ListPartsRequestWithContext returns *http.Request of listing the uploaded parts.
+func (dfs *dfstore) ListPartsRequestWithContext(ctx context.Context, input *ListPartsInput) (*http.Request, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(dfs.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = filepath.Join("buckets", input.BucketName, "objects", input.ObjectKey)
+
+	query := u.Query()
+	query.Set("uploadId", input.UploadID)
+	u.RawQuery = query.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+}
+
+// ListPartsWithContext returns metadata of the uploaded parts of multipart upload.
+func (dfs *dfstore) ListPartsWithContext(ctx context.Context, input *ListPartsInput) ([]*pkgobjectstorage.PartMetadata, error) {
+	req, err := dfs.ListPartsRequestWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []objectstorage.Part
+	if err := dfs.doJSON(req, &parts); err != nil {
+		return nil, err
+	}
+
+	var metadatas []*pkgobjectstorage.PartMetadata
+	for _, part := range parts {
+		metadatas = append(metadatas, &pkgobjectstorage.PartMetadata{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+			Size:       part.Size,
+		})
+	}
+
+	return metadatas, nil
+}
+
+// PutMultipartObjectInput is used to put object by multipart upload.
+type PutMultipartObjectInput struct {
+	// BucketName is bucket name.
+	BucketName string
+
+	// ObjectKey is object key.
+	ObjectKey string
+
+	// Digest is the digest of object, it is used to verify
+	// the object downloaded by P2P.
+	Digest string
+
+	// UploadID is the id of the interrupted multipart upload,
+	// the parts uploaded are skipped when it is resumed.
+	UploadID string
+
+	// PartSize is the size of parts, DefaultPartSize is used by default.
+	PartSize int64
+
+	// Size is the size of object.
+	Size int64
+
+	// Reader is reader of object.
+	Reader io.ReaderAt
+}
+
+// Validate validates PutMultipartObjectInput fields.
+func (i *PutMultipartObjectInput) Validate() error {
+	if i.BucketName == "" {
+		return errors.New("invalid BucketName")
+	}
+
+	if i.ObjectKey == "" {
+		return errors.New("invalid ObjectKey")
+	}
+
+	if i.PartSize != 0 && i.PartSize < pkgobjectstorage.MinPartSize {
+		return errors.New("invalid PartSize")
+	}
+
+	if i.Size <= 0 {
+		return errors.New("invalid Size")
+	}
+
+	if i.Reader == nil {
+		return errors.New("invalid Reader")
+	}
+
+	return nil
+}
+
+// PutMultipartObjectWithContext puts data of object by multipart upload and returns the upload id.
+// If the upload is interrupted, the upload id is returned with the error, and the upload is resumed
+// by the upload id of input, the uploaded parts with the same size and data are skipped.
+func (dfs *dfstore) PutMultipartObjectWithContext(ctx context.Context, input *PutMultipartObjectInput) (string, error) {
+	if err := input.Validate(); err != nil {
+		return "", err
+	}
+
+	partSize := input.PartSize
+	if partSize == 0 {
+		partSize = DefaultPartSize
+	}
+
+	if (input.Size+partSize-1)/partSize > pkgobjectstorage.MaxPartNumber {
+		return "", fmt.Errorf("parts of object exceed %d, increase PartSize", pkgobjectstorage.MaxPartNumber)
+	}
+
+	uploadID := input.UploadID
+	uploadedParts := map[int64]*pkgobjectstorage.PartMetadata{}
+	if uploadID == "" {
+		var err error
+		if uploadID, err = dfs.CreateMultipartUploadWithContext(ctx, &CreateMultipartUploadInput{
+			BucketName: input.BucketName,
+			ObjectKey:  input.ObjectKey,
+			Digest:     input.Digest,
+		}); err != nil {
+			return "", err
+		}
+	} else {
+		metadatas, err := dfs.ListPartsWithContext(ctx, &ListPartsInput{
+			BucketName: input.BucketName,
+			ObjectKey:  input.ObjectKey,
+			UploadID:   uploadID,
+		})
+		if err != nil {
+			return uploadID, err
+		}
+
+		for _, metadata := range metadatas {
+			uploadedParts[metadata.PartNumber] = metadata
+		}
+	}
+
+	var parts []*pkgobjectstorage.PartMetadata
+	for partNumber, offset := int64(1), int64(0); offset < input.Size; partNumber, offset = partNumber+1, offset+partSize {
+		size := partSize
+		if input.Size-offset < size {
+			size = input.Size - offset
+		}
+
+		reader := io.NewSectionReader(input.Reader, offset, size)
+		if part, ok := uploadedParts[partNumber]; ok && part.Size == size {
+			matched, err := isPartMatched(part, reader)
+			if err != nil {
+				return uploadID, err
+			}
+
+			if matched {
+				parts = append(parts, part)
+				continue
+			}
+
+			if _, err := reader.Seek(0, io.SeekStart); err != nil {
+				return uploadID, err
+			}
+		}
+
+		part, err := dfs.UploadPartWithContext(ctx, &UploadPartInput{
+			BucketName: input.BucketName,
+			ObjectKey:  input.ObjectKey,
+			UploadID:   uploadID,
+			PartNumber: partNumber,
+			Size:       size,
+			Reader:     reader,
+		})
+		if err != nil {
+			return uploadID, err
+		}
+
+		parts = append(parts, part)
+	}
+
+	if err := dfs.CompleteMultipartUploadWithContext(ctx, &CompleteMultipartUploadInput{
+		BucketName: input.BucketName,
+		ObjectKey:  input.ObjectKey,
+		UploadID:   uploadID,
+		Parts:      parts,
+	}); err != nil {
+		return uploadID, err
+	}
+
+	return uploadID, nil
+}
+
+// doJSON sends request and decodes the json of response to v if v is not nil.
+func (dfs *dfstore) doJSON(req *http.Request, v any) error {
+	resp, err := dfs.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("bad response status %s", resp.Status)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// isPartMatched returns whether the uploaded part is the same as data of reader,
+// the part is matched by size if its ETag is not md5 of data.
+func isPartMatched(part *pkgobjectstorage.PartMetadata, reader io.Reader) (bool, error) {
+	etag := strings.Trim(part.ETag, `"`)
+	if !md5ETagRegexp.MatchString(etag) {
+		return true, nil
+	}
+
+	h := md5.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(etag, hex.EncodeToString(h.Sum(nil))), nil
+}
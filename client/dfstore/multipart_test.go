@@ -0,0 +1,186 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dfstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/daemon/objectstorage"
+)
+
+// fakeMultipartServer serves the multipart upload apis of object storage gateway in memory.
+type fakeMultipartServer struct {
+	mu        sync.Mutex
+	parts     map[int64][]byte
+	uploads   int
+	uploaded  []int64
+	failPart  int64
+	completed []byte
+}
+
+func (s *fakeMultipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.uploads++
+		s.parts = map[int64][]byte{}
+		json.NewEncoder(w).Encode(objectstorage.CreateMultipartUploadResponse{UploadID: "foo"})
+	case r.Method == http.MethodPut:
+		partNumber, _ := strconv.ParseInt(query.Get("partNumber"), 10, 64)
+		if partNumber == s.failPart {
+			s.failPart = 0
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		data, _ := io.ReadAll(r.Body)
+		if int64(len(data)) != r.ContentLength {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.parts[partNumber] = data
+		s.uploaded = append(s.uploaded, partNumber)
+		json.NewEncoder(w).Encode(objectstorage.Part{PartNumber: partNumber, ETag: etag(data), Size: int64(len(data))})
+	case r.Method == http.MethodGet:
+		parts := []objectstorage.Part{}
+		for partNumber, data := range s.parts {
+			parts = append(parts, objectstorage.Part{PartNumber: partNumber, ETag: etag(data), Size: int64(len(data))})
+		}
+		json.NewEncoder(w).Encode(parts)
+	case r.Method == http.MethodPost:
+		var req objectstorage.CompleteMultipartUploadRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		sort.Slice(req.Parts, func(i, j int) bool { return req.Parts[i].PartNumber < req.Parts[j].PartNumber })
+
+		var completed []byte
+		for _, part := range req.Parts {
+			if etag(s.parts[part.PartNumber]) != part.ETag {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			completed = append(completed, s.parts[part.PartNumber]...)
+		}
+		s.completed = completed
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func etag(data []byte) string {
+	h := md5.Sum(data)
+	return fmt.Sprintf("%q", hex.EncodeToString(h[:]))
+}
+
+func TestDfstore_PutMultipartObjectWithContext(t *testing.T) {
+	assert := assert.New(t)
+	server := &fakeMultipartServer{failPart: 3}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	const partSize = 5 * 1024 * 1024
+	data := bytes.Repeat([]byte("0123456789"), partSize*3/10+1)
+	dfs := New(ts.URL)
+	input := &PutMultipartObjectInput{
+		BucketName: "bucket",
+		ObjectKey:  "foo/bar",
+		PartSize:   partSize,
+		Size:       int64(len(data)),
+		Reader:     bytes.NewReader(data),
+	}
+
+	// The upload is interrupted by the third part.
+	uploadID, err := dfs.PutMultipartObjectWithContext(context.Background(), input)
+	assert.Error(err)
+	assert.Equal("foo", uploadID)
+	assert.Equal([]int64{1, 2}, server.uploaded)
+
+	// The second part is changed and uploaded again with the rest parts.
+	server.parts[2] = []byte("changed")
+	server.uploaded = nil
+	input.UploadID = uploadID
+	uploadID, err = dfs.PutMultipartObjectWithContext(context.Background(), input)
+	assert.NoError(err)
+	assert.Equal("foo", uploadID)
+	assert.Equal([]int64{2, 3, 4}, server.uploaded)
+	assert.Equal(1, server.uploads)
+	assert.Equal(data, server.completed)
+}
+
+func TestPutMultipartObjectInput_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  *PutMultipartObjectInput
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name:  "valid input",
+			input: &PutMultipartObjectInput{BucketName: "bucket", ObjectKey: "foo", Size: 1, Reader: bytes.NewReader([]byte("a"))},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:  "part size is too small",
+			input: &PutMultipartObjectInput{BucketName: "bucket", ObjectKey: "foo", PartSize: 1, Size: 1, Reader: bytes.NewReader([]byte("a"))},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid PartSize")
+			},
+		},
+		{
+			name:  "empty object",
+			input: &PutMultipartObjectInput{BucketName: "bucket", ObjectKey: "foo", Reader: bytes.NewReader(nil)},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid Size")
+			},
+		},
+		{
+			name:  "reader is nil",
+			input: &PutMultipartObjectInput{BucketName: "bucket", ObjectKey: "foo", Size: 1},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid Reader")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, tc.input.Validate())
+		})
+	}
+}
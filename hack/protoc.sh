@@ -0,0 +1,18 @@
+#!/bin/bash
+
+PROTO_FILES="pkg/rpc/scheduler/cluster/cluster.proto"
+API_PATH=$(go list -m -f '{{.Dir}}' d7y.io/api)
+VALIDATE_PATH=$(go list -m -f '{{.Dir}}' github.com/envoyproxy/protoc-gen-validate)
+
+echo "generate protos..."
+
+for file in ${PROTO_FILES}; do
+  if protoc -I . -I "${API_PATH}" -I "${VALIDATE_PATH}" \
+    --go_out=. --go_opt=paths=source_relative \
+    --go-grpc_out=. --go-grpc_opt=paths=source_relative,require_unimplemented_servers=false \
+    "${file}"; then
+    echo "generate protos ${file} successfully"
+  else
+    echo "generate protos ${file} failed"
+  fi
+done
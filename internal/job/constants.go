@@ -24,7 +24,18 @@ const (
 
 // Job Name.
 const (
-	PreheatJob = "preheat"
+	PreheatJob    = "preheat"
+	DeleteTaskJob = "delete_task"
+	GetTaskJob    = "get_task"
+)
+
+// Redis key prefix of job.
+const (
+	// ProgressKeyPrefix is the prefix of redis key which stores the progresses of jobs in group.
+	ProgressKeyPrefix = "job_progress"
+
+	// CanceledKeyPrefix is the prefix of redis key which marks the jobs in group canceled.
+	CanceledKeyPrefix = "job_canceled"
 )
 
 // Machinery server configuration.
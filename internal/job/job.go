@@ -46,27 +46,35 @@ type Job struct {
 	Server *machinery.Server
 	Worker *machinery.Worker
 	Queue  Queue
+
+	// rdb is the redis client of result backend, it stores the progresses of jobs.
+	rdb redis.UniversalClient
+
+	// brokerRDB is the redis client of broker, it stores the queues of jobs.
+	brokerRDB redis.UniversalClient
 }
 
 func New(cfg *Config, queue Queue) (*Job, error) {
 	// Set logger
 	machineryv1log.Set(&MachineryLogger{})
 
-	if err := ping(&redis.UniversalOptions{
+	brokerRDB := redis.NewUniversalClient(&redis.UniversalOptions{
 		Addrs:    cfg.Addrs,
 		Username: cfg.Username,
 		Password: cfg.Password,
 		DB:       cfg.BrokerDB,
-	}); err != nil {
+	})
+	if err := brokerRDB.Ping(context.Background()).Err(); err != nil {
 		return nil, err
 	}
 
-	if err := ping(&redis.UniversalOptions{
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{
 		Addrs:    cfg.Addrs,
 		Username: cfg.Username,
 		Password: cfg.Password,
 		DB:       cfg.BackendDB,
-	}); err != nil {
+	})
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
 		return nil, err
 	}
 
@@ -88,16 +96,13 @@ func New(cfg *Config, queue Queue) (*Job, error) {
 	}
 
 	return &Job{
-		Server: server,
-		Queue:  queue,
+		Server:    server,
+		Queue:     queue,
+		rdb:       rdb,
+		brokerRDB: brokerRDB,
 	}, nil
 }
 
-func ping(options *redis.UniversalOptions) error {
-	client := redis.NewUniversalClient(options)
-	return client.Ping(context.Background()).Err()
-}
-
 func (t *Job) RegisterJob(namedJobFuncs map[string]any) error {
 	return t.Server.RegisterTasks(namedJobFuncs)
 }
@@ -156,6 +161,98 @@ func (t *Job) GetGroupJobState(groupID string) (*GroupJobState, error) {
 	}, nil
 }
 
+// SetPreheatProgress stores the progress of preheat job in the group,
+// the progresses expire with the results of group.
+func (t *Job) SetPreheatProgress(ctx context.Context, groupID, jobID string, progress *PreheatProgress) error {
+	b, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	key := progressKey(groupID)
+	pipe := t.rdb.TxPipeline()
+	pipe.HSet(ctx, key, jobID, b)
+	pipe.Expire(ctx, key, DefaultResultsExpireIn*time.Second)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetPreheatProgresses returns the progresses of preheat jobs in the group, keyed by job id.
+func (t *Job) GetPreheatProgresses(ctx context.Context, groupID string) (map[string]*PreheatProgress, error) {
+	values, err := t.rdb.HGetAll(ctx, progressKey(groupID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	progresses := make(map[string]*PreheatProgress, len(values))
+	for jobID, value := range values {
+		progress := &PreheatProgress{}
+		if err := json.Unmarshal([]byte(value), progress); err != nil {
+			return nil, err
+		}
+
+		progresses[jobID] = progress
+	}
+
+	return progresses, nil
+}
+
+// CancelGroupJob marks the jobs in the group canceled, the pending jobs are skipped
+// and the running jobs are stopped by the workers.
+func (t *Job) CancelGroupJob(ctx context.Context, groupID string) error {
+	return t.rdb.Set(ctx, canceledKey(groupID), 1, DefaultResultsExpireIn*time.Second).Err()
+}
+
+// RevokeGroupJob removes the pending jobs of the group from the queues,
+// the jobs consumed by workers already are stopped by CancelGroupJob.
+func (t *Job) RevokeGroupJob(ctx context.Context, groupID string, queues []Queue) error {
+	for _, queue := range queues {
+		messages, err := t.brokerRDB.LRange(ctx, queue.String(), 0, -1).Result()
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			signature := machineryv1tasks.Signature{}
+			if err := json.Unmarshal([]byte(message), &signature); err != nil {
+				continue
+			}
+
+			if signature.GroupUUID != groupID {
+				continue
+			}
+
+			if err := t.brokerRDB.LRem(ctx, queue.String(), 1, message).Err(); err != nil {
+				return err
+			}
+
+			logger.WithGroupAndTaskID(groupID, signature.UUID).Infof("revoke job from queue %s", queue)
+		}
+	}
+
+	return nil
+}
+
+// IsGroupJobCanceled returns whether the jobs in the group are canceled.
+func (t *Job) IsGroupJobCanceled(ctx context.Context, groupID string) (bool, error) {
+	n, err := t.rdb.Exists(ctx, canceledKey(groupID)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// canceledKey returns the redis key of canceled mark of the group.
+func canceledKey(groupID string) string {
+	return fmt.Sprintf("%s:%s", CanceledKeyPrefix, groupID)
+}
+
+// progressKey returns the redis key of progresses in the group.
+func progressKey(groupID string) string {
+	return fmt.Sprintf("%s:%s", ProgressKeyPrefix, groupID)
+}
+
 func MarshalRequest(v any) ([]machineryv1tasks.Arg, error) {
 	b, err := json.Marshal(v)
 	if err != nil {
@@ -168,6 +265,16 @@ func MarshalRequest(v any) ([]machineryv1tasks.Arg, error) {
 	}}, nil
 }
 
+// MarshalResponse marshals the response of job as the result stored in backend.
+func MarshalResponse(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
 func UnmarshalResponse(data []reflect.Value, v any) error {
 	if len(data) == 0 {
 		return errors.New("empty data is not specified")
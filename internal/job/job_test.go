@@ -17,10 +17,14 @@
 package job
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 
 	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/go-redis/redismock/v8"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -293,3 +297,106 @@ func TestUnmarshalRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		expect func(t *testing.T, result string, err error)
+	}{
+		{
+			name: "marshal common struct",
+			value: struct {
+				I int64  `json:"i"`
+				S string `json:"s"`
+			}{
+				I: 1,
+				S: "foo",
+			},
+			expect: func(t *testing.T, result string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("{\"i\":1,\"s\":\"foo\"}", result)
+			},
+		},
+		{
+			name:  "marshal unsupported value",
+			value: make(chan int),
+			expect: func(t *testing.T, result string, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := MarshalResponse(tc.value)
+			tc.expect(t, result, err)
+		})
+	}
+}
+
+func TestJob_RevokeGroupJob(t *testing.T) {
+	marshal := func(signature *machineryv1tasks.Signature) string {
+		b, _ := json.Marshal(signature)
+		return string(b)
+	}
+
+	canceled := marshal(&machineryv1tasks.Signature{UUID: "task_foo", GroupUUID: "group_foo"})
+	other := marshal(&machineryv1tasks.Signature{UUID: "task_bar", GroupUUID: "group_bar"})
+	tests := []struct {
+		name   string
+		queues []Queue
+		mock   func(mock redismock.ClientMock)
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name:   "revoke pending jobs of group",
+			queues: []Queue{"scheduler_1_foo", "scheduler_1_bar"},
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectLRange("scheduler_1_foo", 0, -1).SetVal([]string{other, canceled, "invalid"})
+				mock.ExpectLRem("scheduler_1_foo", 1, canceled).SetVal(1)
+				mock.ExpectLRange("scheduler_1_bar", 0, -1).SetVal([]string{other})
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:   "queue is empty",
+			queues: []Queue{"scheduler_1_foo"},
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectLRange("scheduler_1_foo", 0, -1).SetVal([]string{})
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:   "remove job failed",
+			queues: []Queue{"scheduler_1_foo"},
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectLRange("scheduler_1_foo", 0, -1).SetVal([]string{canceled})
+				mock.ExpectLRem("scheduler_1_foo", 1, canceled).SetErr(errors.New("foo"))
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rdb, mock := redismock.NewClientMock()
+			tc.mock(mock)
+
+			j := &Job{brokerRDB: rdb}
+			tc.expect(t, j.RevokeGroupJob(context.Background(), "group_foo", tc.queues))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
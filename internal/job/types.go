@@ -16,6 +16,8 @@
 
 package job
 
+import "time"
+
 type PreheatRequest struct {
 	URL     string            `json:"url" validate:"required,url"`
 	Tag     string            `json:"tag" validate:"omitempty"`
@@ -26,3 +28,94 @@ type PreheatRequest struct {
 
 type PreheatResponse struct {
 }
+
+// DeleteTaskRequest is the request of deleting the task in the hosts of P2P cluster,
+// the task is identified by the url and url meta.
+type DeleteTaskRequest struct {
+	URL     string            `json:"url" validate:"required,url"`
+	Tag     string            `json:"tag" validate:"omitempty"`
+	Digest  string            `json:"digest" validate:"omitempty"`
+	Filter  string            `json:"filter" validate:"omitempty"`
+	Headers map[string]string `json:"headers" validate:"omitempty"`
+}
+
+// PreheatProgress is the progress of preheat job reported by the scheduler worker.
+type PreheatProgress struct {
+	// URL is the url of preheat request.
+	URL string `json:"url"`
+
+	// State is the state of preheat, like STARTED, SUCCESS and FAILURE.
+	State string `json:"state"`
+
+	// ContentLength is the content length of task, it is -1 before the length is known.
+	ContentLength int64 `json:"content_length"`
+
+	// BytesDone is the bytes of pieces downloaded by seed peer.
+	BytesDone int64 `json:"bytes_done"`
+
+	// TotalPieces is the total piece count of task, it is -1 before the count is known.
+	TotalPieces int32 `json:"total_pieces"`
+
+	// PiecesDone is the count of pieces downloaded by seed peer.
+	PiecesDone int32 `json:"pieces_done"`
+
+	// SeedPeerHost is the host of seed peer which downloads the task.
+	SeedPeerHost string `json:"seed_peer_host"`
+
+	// Error is the error message of failed preheat.
+	Error string `json:"error"`
+
+	// UpdatedAt is the time of progress reported.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetTaskRequest is the request of getting the peers of task in scheduler,
+// the task is identified by the task id, or the url and url meta.
+type GetTaskRequest struct {
+	TaskID  string            `json:"task_id" validate:"required_without=URL"`
+	URL     string            `json:"url" validate:"required_without=TaskID,omitempty,url"`
+	Tag     string            `json:"tag" validate:"omitempty"`
+	Digest  string            `json:"digest" validate:"omitempty"`
+	Filter  string            `json:"filter" validate:"omitempty"`
+	Headers map[string]string `json:"headers" validate:"omitempty"`
+}
+
+// GetTaskResponse is the task and its peers in the scheduler, the responses of
+// schedulers are merged by scheduler cluster in the result of job.
+type GetTaskResponse struct {
+	// SchedulerClusterID is the cluster id of scheduler.
+	SchedulerClusterID uint `json:"scheduler_cluster_id"`
+	// SchedulerHostname is the hostname of scheduler, the hostnames of the schedulers
+	// which have the task are joined by comma after merged.
+	SchedulerHostname string `json:"scheduler_hostname"`
+	// TaskID is the id of task.
+	TaskID string `json:"task_id"`
+	// State is the state of task, it is empty if the task is not found.
+	State string `json:"state"`
+	// ContentLength is the content length of task, it is -1 before the length is known.
+	ContentLength int64 `json:"content_length"`
+	// TotalPieces is the total piece count of task, it is -1 before the count is known.
+	TotalPieces int32 `json:"total_pieces"`
+	// Peers is the peers of task.
+	Peers []*GetTaskPeer `json:"peers"`
+}
+
+// GetTaskPeer is the peer of task in the scheduler.
+type GetTaskPeer struct {
+	// ID is the id of peer.
+	ID string `json:"id"`
+	// State is the state of peer.
+	State string `json:"state"`
+	// FinishedPieces is the count of pieces downloaded by peer.
+	FinishedPieces int32 `json:"finished_pieces"`
+	// HostID is the id of host.
+	HostID string `json:"host_id"`
+	// Hostname is the hostname of host.
+	Hostname string `json:"hostname"`
+	// IP is the ip of host.
+	IP string `json:"ip"`
+	// HostType is the type of host, like normal, super, strong and weak.
+	HostType string `json:"host_type"`
+	// UpdatedAt is the time of peer updated.
+	UpdatedAt time.Time `json:"updated_at"`
+}
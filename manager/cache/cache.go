@@ -41,6 +41,9 @@ const (
 
 	// Buckets prefix of cache key.
 	BucketsNamespace = "buckets"
+
+	// Leader of job schedules prefix of cache key.
+	JobSchedulesLeaderNamespace = "job-schedules-leader"
 )
 
 // Cache is cache client.
@@ -17,12 +17,15 @@
 package config
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"time"
 
 	"d7y.io/dragonfly/v2/cmd/dependency/base"
+	"d7y.io/dragonfly/v2/manager/event"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
 	"d7y.io/dragonfly/v2/pkg/objectstorage"
 	"d7y.io/dragonfly/v2/pkg/rpc"
@@ -54,6 +57,9 @@ type Config struct {
 
 	// Network configuration.
 	Network NetworkConfig `yaml:"network" mapstructure:"network"`
+
+	// Event configuration.
+	Event EventConfig `yaml:"event" mapstructure:"event"`
 }
 
 type ServerConfig struct {
@@ -280,6 +286,10 @@ type SecurityConfig struct {
 
 	// CertSpec is the desired state of certificate.
 	CertSpec CertSpec `mapstructure:"certSpec" yaml:"certSpec"`
+
+	// EncryptionKey is the base64 encoded AES key to encrypt the secrets in database,
+	// like registry credentials, the length of decoded key must be 16, 24 or 32 bytes.
+	EncryptionKey string `mapstructure:"encryptionKey" yaml:"encryptionKey"`
 }
 
 type CertSpec struct {
@@ -298,6 +308,41 @@ type NetworkConfig struct {
 	EnableIPv6 bool `mapstructure:"enableIPv6" yaml:"enableIPv6"`
 }
 
+type EventConfig struct {
+	// Webhooks is the webhooks receiving the events of manager.
+	Webhooks []WebhookConfig `mapstructure:"webhooks" yaml:"webhooks"`
+
+	// Redis is the configuration of publishing events by redis pub/sub.
+	Redis EventRedisConfig `mapstructure:"redis" yaml:"redis"`
+}
+
+type EventRedisConfig struct {
+	// Enable publishing events to the redis of database.
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+
+	// Prefix is the prefix of channels, the channel of event
+	// is the prefix and the type of event joined by dot.
+	Prefix string `mapstructure:"prefix" yaml:"prefix"`
+}
+
+type WebhookConfig struct {
+	// URL is the url of webhook.
+	URL string `mapstructure:"url" yaml:"url"`
+
+	// Secret is the secret of signing the body of webhook request by HMAC-SHA256,
+	// the signature is in the X-Dragonfly-Signature header.
+	Secret string `mapstructure:"secret" yaml:"secret"`
+
+	// Events is the event types sent to webhook, all events are sent if it is empty.
+	Events []string `mapstructure:"events" yaml:"events"`
+
+	// Timeout is the timeout of webhook request.
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout"`
+
+	// MaxAttempts is the max attempts of webhook request.
+	MaxAttempts int `mapstructure:"maxAttempts" yaml:"maxAttempts"`
+}
+
 // New config instance.
 func New() *Config {
 	return &Config{
@@ -362,6 +407,11 @@ func New() *Config {
 		Network: NetworkConfig{
 			EnableIPv6: DefaultNetworkEnableIPv6,
 		},
+		Event: EventConfig{
+			Redis: EventRedisConfig{
+				Prefix: DefaultEventRedisPrefix,
+			},
+		},
 	}
 }
 
@@ -527,12 +577,50 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Security.EncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(cfg.Security.EncryptionKey)
+		if err != nil {
+			return errors.New("security requires parameter encryptionKey to be base64 encoded")
+		}
+
+		if !slices.Contains([]int{16, 24, 32}, len(key)) {
+			return errors.New("security requires parameter encryptionKey to be 16, 24 or 32 bytes")
+		}
+	}
+
 	if cfg.Metrics.Enable {
 		if cfg.Metrics.Addr == "" {
 			return errors.New("metrics requires parameter addr")
 		}
 	}
 
+	for _, webhook := range cfg.Event.Webhooks {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("webhook requires parameter url to be http or https url")
+		}
+
+		for _, e := range webhook.Events {
+			if !slices.Contains(event.Types, e) {
+				return fmt.Errorf("webhook has unknown event %s", e)
+			}
+		}
+
+		if webhook.Timeout < 0 {
+			return errors.New("webhook requires parameter timeout to be positive")
+		}
+
+		if webhook.MaxAttempts < 0 {
+			return errors.New("webhook requires parameter maxAttempts to be positive")
+		}
+	}
+
+	if cfg.Event.Redis.Enable {
+		if cfg.Event.Redis.Prefix == "" {
+			return errors.New("event requires parameter redis prefix")
+		}
+	}
+
 	return nil
 }
 
@@ -113,6 +113,7 @@ func TestManagerConfig_Load(t *testing.T) {
 				DNSNames:       []string{"foo"},
 				ValidityPeriod: 1000,
 			},
+			EncryptionKey: "AAAAAAAAAAAAAAAAAAAAAA==",
 		},
 		Metrics: MetricsConfig{
 			Enable:          true,
@@ -122,6 +123,21 @@ func TestManagerConfig_Load(t *testing.T) {
 		Network: NetworkConfig{
 			EnableIPv6: true,
 		},
+		Event: EventConfig{
+			Webhooks: []WebhookConfig{
+				{
+					URL:         "https://example.com/webhook",
+					Secret:      "foo",
+					Events:      []string{"job.state_changed"},
+					Timeout:     1000,
+					MaxAttempts: 3,
+				},
+			},
+			Redis: EventRedisConfig{
+				Enable: true,
+				Prefix: "foo",
+			},
+		},
 	}
 
 	managerConfigYAML := &Config{}
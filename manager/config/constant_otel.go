@@ -19,13 +19,18 @@ package config
 import "go.opentelemetry.io/otel/attribute"
 
 const (
-	AttributeID          = attribute.Key("d7y.manager.id")
-	AttributePreheatType = attribute.Key("d7y.manager.preheat.type")
-	AttributePreheatURL  = attribute.Key("d7y.manager.preheat.url")
+	AttributeID            = attribute.Key("d7y.manager.id")
+	AttributePreheatType   = attribute.Key("d7y.manager.preheat.type")
+	AttributePreheatURL    = attribute.Key("d7y.manager.preheat.url")
+	AttributeDeleteTaskURL = attribute.Key("d7y.manager.delete-task.url")
+	AttributeGetTaskID     = attribute.Key("d7y.manager.get-task.id")
+	AttributeGetTaskURL    = attribute.Key("d7y.manager.get-task.url")
 )
 
 const (
 	SpanPreheat          = "preheat"
 	SpanGetLayers        = "get-layers"
 	SpanAuthWithRegistry = "auth-with-registry"
+	SpanDeleteTask       = "delete-task"
+	SpanGetTask          = "get-task"
 )
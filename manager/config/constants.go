@@ -108,3 +108,8 @@ var (
 	// DefaultNetworkEnableIPv6 is default value of enableIPv6.
 	DefaultNetworkEnableIPv6 = false
 )
+
+const (
+	// DefaultEventRedisPrefix is default prefix of channels publishing events by redis.
+	DefaultEventRedisPrefix = "dragonfly"
+)
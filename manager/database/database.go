@@ -84,6 +84,8 @@ func migrate(db *gorm.DB) error {
 		&model.Oauth{},
 		&model.Config{},
 		&model.Application{},
+		&model.RegistryCredential{},
+		&model.Audit{},
 	)
 }
 
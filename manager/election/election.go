@@ -0,0 +1,96 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/election_mock.go -source election.go -package mocks
+
+package election
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// campaignScript sets the leader key with ttl if the key is absent,
+// or extends the ttl if the key is held by the candidate.
+var campaignScript = redis.NewScript(`
+local leader = redis.call("GET", KEYS[1])
+if leader == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+
+if not leader then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+
+return 0
+`)
+
+// resignScript deletes the leader key if the key is held by the candidate.
+var resignScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+
+return 0
+`)
+
+// Elector is the interface used for electing the leader among manager replicas.
+type Elector interface {
+	// Campaign tries to become the leader or keep the leadership,
+	// it returns whether the candidate is the leader.
+	Campaign(ctx context.Context) (bool, error)
+
+	// Resign gives up the leadership if the candidate is the leader.
+	Resign(ctx context.Context) error
+}
+
+// redisElector elects the leader by the redis key with ttl, the leadership
+// is lost if the leader does not campaign again in ttl.
+type redisElector struct {
+	rdb redis.UniversalClient
+	key string
+	id  string
+	ttl time.Duration
+}
+
+// NewRedisElector returns a new Elector of redis, id is the unique identity of candidate.
+func NewRedisElector(rdb redis.UniversalClient, key, id string, ttl time.Duration) Elector {
+	return &redisElector{
+		rdb: rdb,
+		key: key,
+		id:  id,
+		ttl: ttl,
+	}
+}
+
+// Campaign runs the campaign script atomically.
+func (e *redisElector) Campaign(ctx context.Context) (bool, error) {
+	n, err := campaignScript.Run(ctx, e.rdb, []string{e.key}, e.id, e.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// Resign runs the resign script atomically.
+func (e *redisElector) Resign(ctx context.Context) error {
+	return resignScript.Run(ctx, e.rdb, []string{e.key}, e.id).Err()
+}
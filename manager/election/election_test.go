@@ -0,0 +1,137 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockKey = "foo"
+	mockID  = "bar"
+	mockTTL = 30 * time.Second
+)
+
+func TestRedisElector_Campaign(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(mock redismock.ClientMock)
+		expect func(t *testing.T, leader bool, err error)
+	}{
+		{
+			name: "candidate becomes the leader",
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(campaignScript.Hash(), []string{mockKey}, mockID, mockTTL.Milliseconds()).SetVal(int64(1))
+			},
+			expect: func(t *testing.T, leader bool, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.True(leader)
+			},
+		},
+		{
+			name: "candidate is the follower",
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(campaignScript.Hash(), []string{mockKey}, mockID, mockTTL.Milliseconds()).SetVal(int64(0))
+			},
+			expect: func(t *testing.T, leader bool, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.False(leader)
+			},
+		},
+		{
+			name: "campaign script failed",
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(campaignScript.Hash(), []string{mockKey}, mockID, mockTTL.Milliseconds()).SetErr(errors.New("foo"))
+			},
+			expect: func(t *testing.T, leader bool, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+				assert.False(leader)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rdb, mock := redismock.NewClientMock()
+			tc.mock(mock)
+
+			e := NewRedisElector(rdb, mockKey, mockID, mockTTL)
+			leader, err := e.Campaign(context.Background())
+			tc.expect(t, leader, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRedisElector_Resign(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(mock redismock.ClientMock)
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "leader resigns",
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(resignScript.Hash(), []string{mockKey}, mockID).SetVal(int64(1))
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "follower resigns",
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(resignScript.Hash(), []string{mockKey}, mockID).SetVal(int64(0))
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "resign script failed",
+			mock: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(resignScript.Hash(), []string{mockKey}, mockID).SetErr(errors.New("foo"))
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rdb, mock := redismock.NewClientMock()
+			tc.mock(mock)
+
+			e := NewRedisElector(rdb, mockKey, mockID, mockTTL)
+			tc.expect(t, e.Resign(context.Background()))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
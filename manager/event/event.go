@@ -0,0 +1,224 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/event_mock.go -source event.go -package mocks
+
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	// TypeJobStateChanged is the event type of job state changed.
+	TypeJobStateChanged = "job.state_changed"
+
+	// TypeSchedulerInactive is the event type of scheduler inactive by keepalive timeout.
+	TypeSchedulerInactive = "scheduler.inactive"
+
+	// TypeSeedPeerInactive is the event type of seed peer inactive by keepalive timeout.
+	TypeSeedPeerInactive = "seed_peer.inactive"
+
+	// TypeModelVersionActivated is the event type of model version activated.
+	TypeModelVersionActivated = "model.version_activated"
+)
+
+// Types is the types of events emitted by manager.
+var Types = []string{
+	TypeJobStateChanged,
+	TypeSchedulerInactive,
+	TypeSeedPeerInactive,
+	TypeModelVersionActivated,
+}
+
+const (
+	// defaultQueueSize is the default size of events queue of each sink,
+	// the events are dropped when the queue is full.
+	defaultQueueSize = 1024
+
+	// defaultSendTimeout is the default timeout of sending event to a sink.
+	defaultSendTimeout = 2 * time.Minute
+)
+
+// Event is the event emitted by manager.
+type Event struct {
+	// ID is the unique id of event.
+	ID string `json:"id"`
+
+	// Type is the type of event.
+	Type string `json:"type"`
+
+	// CreatedAt is the time of event created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Data is the data of event, like JobStateChanged.
+	Data any `json:"data"`
+}
+
+// JobStateChanged is the data of job state changed event.
+type JobStateChanged struct {
+	ID            uint   `json:"id"`
+	Type          string `json:"type"`
+	TaskID        string `json:"task_id"`
+	State         string `json:"state"`
+	PreviousState string `json:"previous_state"`
+}
+
+// HostInactive is the data of scheduler and seed peer inactive events.
+type HostInactive struct {
+	ID        uint   `json:"id"`
+	HostName  string `json:"host_name"`
+	IP        string `json:"ip"`
+	ClusterID uint   `json:"cluster_id"`
+}
+
+// ModelVersionActivated is the data of model version activated event.
+type ModelVersionActivated struct {
+	SchedulerID       uint   `json:"scheduler_id"`
+	ModelID           string `json:"model_id"`
+	VersionID         string `json:"version_id"`
+	PreviousVersionID string `json:"previous_version_id"`
+}
+
+// Sink is the destination of events, like webhook and message queue.
+type Sink interface {
+	// Send sends the event, it retries by itself if needed.
+	Send(ctx context.Context, event *Event) error
+}
+
+// Notifier emits events to sinks asynchronously.
+type Notifier interface {
+	// Notify emits the event with the type and data without blocking.
+	Notify(eventType string, data any)
+
+	// Serve starts sending the events to sinks.
+	Serve()
+
+	// Stop stops sending events, the events in queue are sent before stopped.
+	Stop()
+}
+
+type notifier struct {
+	workers []*worker
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+}
+
+// worker sends the events in its own queue to the sink, so a slow sink
+// does not block the events of other sinks.
+type worker struct {
+	sink   Sink
+	events chan *Event
+}
+
+// New returns a new Notifier, events are discarded if there is no sink.
+func New(sinks ...Sink) Notifier {
+	n := &notifier{
+		done: make(chan struct{}),
+	}
+
+	for _, sink := range sinks {
+		n.workers = append(n.workers, &worker{
+			sink:   sink,
+			events: make(chan *Event, defaultQueueSize),
+		})
+	}
+
+	return n
+}
+
+// Notify emits the event with the type and data without blocking.
+func (n *notifier) Notify(eventType string, data any) {
+	if len(n.workers) == 0 {
+		return
+	}
+
+	event := &Event{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		Data:      data,
+	}
+
+	for _, w := range n.workers {
+		select {
+		case w.events <- event:
+		case <-n.done:
+			return
+		default:
+			logger.Warnf("events queue of sink is full, drop event %s %s", event.Type, event.ID)
+		}
+	}
+}
+
+// Serve starts sending the events to sinks.
+func (n *notifier) Serve() {
+	for _, w := range n.workers {
+		n.wg.Add(1)
+		go func(w *worker) {
+			defer n.wg.Done()
+			w.serve(n.done)
+		}(w)
+	}
+
+	<-n.done
+	n.wg.Wait()
+}
+
+// Stop stops sending events, the events in queue are sent before stopped.
+func (n *notifier) Stop() {
+	n.once.Do(func() {
+		close(n.done)
+	})
+	n.wg.Wait()
+}
+
+// serve sends the events in queue to the sink until done,
+// the remaining events in queue are sent before returned.
+func (w *worker) serve(done <-chan struct{}) {
+	for {
+		select {
+		case event := <-w.events:
+			w.send(event)
+		case <-done:
+			for {
+				select {
+				case event := <-w.events:
+					w.send(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send sends the event to the sink.
+func (w *worker) send(event *Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSendTimeout)
+	defer cancel()
+
+	if err := w.sink.Send(ctx, event); err != nil {
+		logger.Errorf("send event %s %s failed: %s", event.Type, event.ID, err.Error())
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSink struct {
+	mu     sync.Mutex
+	events []*Event
+	err    error
+}
+
+func (s *fakeSink) Send(ctx context.Context, event *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return s.err
+}
+
+type blockingSink struct {
+	release chan struct{}
+}
+
+func (s *blockingSink) Send(ctx context.Context, event *Event) error {
+	<-s.release
+	return nil
+}
+
+type fakePublisher struct {
+	subject string
+	data    []byte
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, subject string, data []byte) error {
+	p.subject = subject
+	p.data = data
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		sinks  []*fakeSink
+		events []string
+		expect func(t *testing.T, sinks []*fakeSink)
+	}{
+		{
+			name:   "notify events to sinks",
+			sinks:  []*fakeSink{{}, {err: errors.New("foo")}},
+			events: []string{TypeJobStateChanged, TypeSeedPeerInactive},
+			expect: func(t *testing.T, sinks []*fakeSink) {
+				assert := assert.New(t)
+				for _, sink := range sinks {
+					assert.Len(sink.events, 2)
+					assert.Equal(TypeJobStateChanged, sink.events[0].Type)
+					assert.Equal(TypeSeedPeerInactive, sink.events[1].Type)
+					assert.NotEmpty(sink.events[0].ID)
+					assert.False(sink.events[0].CreatedAt.IsZero())
+				}
+			},
+		},
+		{
+			name:   "notify events without sinks",
+			events: []string{TypeJobStateChanged},
+			expect: func(t *testing.T, sinks []*fakeSink) {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var sinks []Sink
+			for _, sink := range tc.sinks {
+				sinks = append(sinks, sink)
+			}
+
+			n := New(sinks...)
+			for _, eventType := range tc.events {
+				n.Notify(eventType, nil)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				n.Serve()
+				close(done)
+			}()
+
+			n.Stop()
+			<-done
+			tc.expect(t, tc.sinks)
+		})
+	}
+}
+
+func TestNotifier_SlowSink(t *testing.T) {
+	assert := assert.New(t)
+	slow := &blockingSink{release: make(chan struct{})}
+	fast := &fakeSink{}
+
+	n := New(slow, fast)
+	n.Notify(TypeJobStateChanged, nil)
+	n.Notify(TypeSeedPeerInactive, nil)
+
+	done := make(chan struct{})
+	go func() {
+		n.Serve()
+		close(done)
+	}()
+
+	assert.Eventually(func() bool {
+		fast.mu.Lock()
+		defer fast.mu.Unlock()
+		return len(fast.events) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	close(slow.release)
+	n.Stop()
+	<-done
+}
+
+func TestPublisherSink_Send(t *testing.T) {
+	assert := assert.New(t)
+	publisher := &fakePublisher{}
+	assert.NoError(NewPublisherSink(publisher, "dragonfly").Send(context.Background(), &Event{ID: "foo", Type: TypeModelVersionActivated}))
+	assert.Equal("dragonfly.model.version_activated", publisher.subject)
+
+	var event Event
+	assert.NoError(json.Unmarshal(publisher.data, &event))
+	assert.Equal("foo", event.ID)
+}
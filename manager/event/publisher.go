@@ -0,0 +1,54 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Publisher publishes messages to the subject of message queue, it is implemented
+// by the clients of message queue, like the subject of NATS and the topic of Kafka.
+type Publisher interface {
+	// Publish publishes the message to the subject.
+	Publish(ctx context.Context, subject string, data []byte) error
+}
+
+type publisherSink struct {
+	publisher Publisher
+	prefix    string
+}
+
+// NewPublisherSink returns a new Sink which publishes events in json by the publisher,
+// the subject of event is the prefix and the type of event joined by dot.
+func NewPublisherSink(publisher Publisher, prefix string) Sink {
+	return &publisherSink{
+		publisher: publisher,
+		prefix:    prefix,
+	}
+}
+
+// Send publishes the event to the subject of event type.
+func (p *publisherSink) Send(ctx context.Context, event *Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return p.publisher.Publish(ctx, fmt.Sprintf("%s.%s", p.prefix, event.Type), data)
+}
@@ -0,0 +1,37 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type redisPublisher struct {
+	rdb redis.UniversalClient
+}
+
+// NewRedisPublisher returns a new Publisher which publishes messages to the channel of redis.
+func NewRedisPublisher(rdb redis.UniversalClient) Publisher {
+	return &redisPublisher{rdb: rdb}
+}
+
+// Publish publishes the message to the channel of subject.
+func (r *redisPublisher) Publish(ctx context.Context, subject string, data []byte) error {
+	return r.rdb.Publish(ctx, subject, data).Err()
+}
@@ -0,0 +1,180 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"d7y.io/dragonfly/v2/pkg/retry"
+	"d7y.io/dragonfly/v2/pkg/slices"
+)
+
+const (
+	// SignatureHeader is the header of webhook request which carries the HMAC-SHA256
+	// signature of body signed by the secret, it is in sha256=<hex> format.
+	SignatureHeader = "X-Dragonfly-Signature"
+
+	// EventHeader is the header of webhook request which carries the type of event.
+	EventHeader = "X-Dragonfly-Event"
+
+	// DeliveryHeader is the header of webhook request which carries the id of event,
+	// the receiver can deduplicate the retried requests by it.
+	DeliveryHeader = "X-Dragonfly-Delivery"
+)
+
+const (
+	// DefaultWebhookTimeout is the default timeout of webhook request.
+	DefaultWebhookTimeout = 10 * time.Second
+
+	// DefaultWebhookMaxAttempts is the default max attempts of webhook request.
+	DefaultWebhookMaxAttempts = 3
+
+	// defaultWebhookInitBackoff is the default initial backoff seconds of retrying webhook request.
+	defaultWebhookInitBackoff = 1
+
+	// defaultWebhookMaxBackoff is the default max backoff seconds of retrying webhook request.
+	defaultWebhookMaxBackoff = 30
+)
+
+// WebhookOption is a functional option for configuring the webhook.
+type WebhookOption func(w *webhook)
+
+// WithWebhookSecret sets the secret of signing the body of webhook request.
+func WithWebhookSecret(secret string) WebhookOption {
+	return func(w *webhook) {
+		w.secret = secret
+	}
+}
+
+// WithWebhookEvents sets the event types sent to webhook, all events are sent if it is empty.
+func WithWebhookEvents(events []string) WebhookOption {
+	return func(w *webhook) {
+		w.events = events
+	}
+}
+
+// WithWebhookTimeout sets the timeout of webhook request.
+func WithWebhookTimeout(timeout time.Duration) WebhookOption {
+	return func(w *webhook) {
+		w.client.Timeout = timeout
+	}
+}
+
+// WithWebhookMaxAttempts sets the max attempts of webhook request.
+func WithWebhookMaxAttempts(maxAttempts int) WebhookOption {
+	return func(w *webhook) {
+		w.maxAttempts = maxAttempts
+	}
+}
+
+// WithWebhookBackoff sets the initial and max backoff seconds of retrying webhook request.
+func WithWebhookBackoff(initBackoff, maxBackoff float64) WebhookOption {
+	return func(w *webhook) {
+		w.initBackoff = initBackoff
+		w.maxBackoff = maxBackoff
+	}
+}
+
+type webhook struct {
+	url         string
+	secret      string
+	events      []string
+	client      *http.Client
+	maxAttempts int
+	initBackoff float64
+	maxBackoff  float64
+}
+
+// NewWebhook returns a new Sink which posts events in json to the url.
+func NewWebhook(url string, options ...WebhookOption) Sink {
+	w := &webhook{
+		url:         url,
+		client:      &http.Client{Timeout: DefaultWebhookTimeout},
+		maxAttempts: DefaultWebhookMaxAttempts,
+		initBackoff: defaultWebhookInitBackoff,
+		maxBackoff:  defaultWebhookMaxBackoff,
+	}
+
+	for _, opt := range options {
+		opt(w)
+	}
+
+	return w
+}
+
+// Send posts the event to the url, the request is retried when it fails
+// by network error, server error or rate limit.
+func (w *webhook) Send(ctx context.Context, event *Event) error {
+	if len(w.events) > 0 && !slices.Contains(w.events, event.Type) {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = retry.Run(ctx, w.initBackoff, w.maxBackoff, w.maxAttempts, func() (any, bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+		if err != nil {
+			return nil, true, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(EventHeader, event.Type)
+		req.Header.Set(DeliveryHeader, event.ID)
+		if w.secret != "" {
+			req.Header.Set(SignatureHeader, Sign(w.secret, body))
+		}
+
+		resp, err := w.client.Do(req)
+		if err != nil {
+			return nil, false, err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body) // nolint: errcheck
+
+		if resp.StatusCode/100 == 2 {
+			return nil, false, nil
+		}
+
+		err = fmt.Errorf("webhook %s response status %d", w.url, resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, false, err
+		}
+
+		return nil, true, err
+	})
+
+	return err
+}
+
+// Sign returns the HMAC-SHA256 signature of body signed by the secret in sha256=<hex> format.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,142 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []WebhookOption
+		statuses []int
+		event    *Event
+		expect   func(t *testing.T, reqs []*http.Request, bodies [][]byte, err error)
+	}{
+		{
+			name:     "send event with signature",
+			options:  []WebhookOption{WithWebhookSecret("foo")},
+			statuses: []int{http.StatusOK},
+			event:    &Event{ID: "bar", Type: TypeJobStateChanged, Data: &JobStateChanged{ID: 1, State: "SUCCESS"}},
+			expect: func(t *testing.T, reqs []*http.Request, bodies [][]byte, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(reqs, 1)
+				assert.Equal(TypeJobStateChanged, reqs[0].Header.Get(EventHeader))
+				assert.Equal("bar", reqs[0].Header.Get(DeliveryHeader))
+				assert.Equal(Sign("foo", bodies[0]), reqs[0].Header.Get(SignatureHeader))
+
+				var event Event
+				assert.NoError(json.Unmarshal(bodies[0], &event))
+				assert.Equal("bar", event.ID)
+				assert.Equal(TypeJobStateChanged, event.Type)
+			},
+		},
+		{
+			name:     "send event without secret",
+			statuses: []int{http.StatusNoContent},
+			event:    &Event{ID: "bar", Type: TypeSchedulerInactive},
+			expect: func(t *testing.T, reqs []*http.Request, bodies [][]byte, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(reqs, 1)
+				assert.Empty(reqs[0].Header.Get(SignatureHeader))
+			},
+		},
+		{
+			name:     "retry when server error",
+			options:  []WebhookOption{WithWebhookMaxAttempts(3)},
+			statuses: []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK},
+			event:    &Event{ID: "bar", Type: TypeSchedulerInactive},
+			expect: func(t *testing.T, reqs []*http.Request, bodies [][]byte, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(reqs, 3)
+				assert.Equal(bodies[0], bodies[2])
+			},
+		},
+		{
+			name:     "retry exceeds max attempts",
+			options:  []WebhookOption{WithWebhookMaxAttempts(2)},
+			statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			event:    &Event{ID: "bar", Type: TypeSchedulerInactive},
+			expect: func(t *testing.T, reqs []*http.Request, bodies [][]byte, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "502")
+				assert.Len(reqs, 2)
+			},
+		},
+		{
+			name:     "not retry when client error",
+			statuses: []int{http.StatusBadRequest, http.StatusOK},
+			event:    &Event{ID: "bar", Type: TypeSchedulerInactive},
+			expect: func(t *testing.T, reqs []*http.Request, bodies [][]byte, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "400")
+				assert.Len(reqs, 1)
+			},
+		},
+		{
+			name:     "skip event not subscribed",
+			options:  []WebhookOption{WithWebhookEvents([]string{TypeJobStateChanged})},
+			statuses: []int{http.StatusOK},
+			event:    &Event{ID: "bar", Type: TypeSchedulerInactive},
+			expect: func(t *testing.T, reqs []*http.Request, bodies [][]byte, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(reqs, 0)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				count  int32
+				reqs   []*http.Request
+				bodies [][]byte
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				reqs = append(reqs, r)
+				bodies = append(bodies, body)
+				w.WriteHeader(tc.statuses[atomic.AddInt32(&count, 1)-1])
+			}))
+			defer server.Close()
+
+			options := append([]WebhookOption{WithWebhookBackoff(0.001, 0.001), WithWebhookTimeout(time.Second)}, tc.options...)
+			err := NewWebhook(server.URL, options...).Send(context.Background(), tc.event)
+			tc.expect(t, reqs, bodies, err)
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("sha256=f9320baf0249169e73850cd6156ded0106e2bb6ad8cab01b7bbbebe6d1065317", Sign("foo", []byte("bar")))
+	assert.NotEqual(Sign("foo", []byte("bar")), Sign("baz", []byte("bar")))
+}
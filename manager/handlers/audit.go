@@ -0,0 +1,91 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// nolint
+	_ "d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Get Audit
+// @Description Get Audit by id
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.Audit
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /audits/{id} [get]
+func (h *Handlers) GetAudit(ctx *gin.Context) {
+	var params types.AuditParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	audit, err := h.service.GetAudit(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, audit)
+}
+
+// @Summary Get Audits
+// @Description Get Audits
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Param actor_type query string false "actor type" Enums(user, scheduler, seed_peer)
+// @Param actor query string false "user name or hostname of actor"
+// @Param user_id query int false "user id"
+// @Param method query string false "http method or grpc method"
+// @Param resource_type query string false "resource type"
+// @Param resource_id query string false "resource id"
+// @Param state query string false "mutation state" Enums(SUCCESS, FAILURE)
+// @Success 200 {object} []model.Audit
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /audits [get]
+func (h *Handlers) GetAudits(ctx *gin.Context) {
+	var query types.GetAuditsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	audits, count, err := h.service.GetAudits(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, audits)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -8,6 +9,7 @@ import (
 
 	"d7y.io/dragonfly/v2/internal/job"
 	_ "d7y.io/dragonfly/v2/manager/model" // nolint
+	"d7y.io/dragonfly/v2/manager/service"
 	"d7y.io/dragonfly/v2/manager/types"
 )
 
@@ -38,6 +40,39 @@ func (h *Handlers) CreateJob(ctx *gin.Context) {
 		}
 
 		job, err := h.service.CreatePreheatJob(ctx.Request.Context(), json)
+		if errors.Is(err, service.ErrInvalidJobArgs) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
+		ctx.JSON(http.StatusOK, job)
+	case job.DeleteTaskJob:
+		var json types.CreateDeleteTaskJobRequest
+		if err := ctx.ShouldBindBodyWith(&json, binding.JSON); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		job, err := h.service.CreateDeleteTaskJob(ctx.Request.Context(), json)
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
+		ctx.JSON(http.StatusOK, job)
+	case job.GetTaskJob:
+		var json types.CreateGetTaskJobRequest
+		if err := ctx.ShouldBindBodyWith(&json, binding.JSON); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		job, err := h.service.CreateGetTaskJob(ctx.Request.Context(), json)
 		if err != nil {
 			ctx.Error(err) // nolint: errcheck
 			return
@@ -109,6 +144,33 @@ func (h *Handlers) UpdateJob(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, job)
 }
 
+// @Summary Cancel Job
+// @Description Cancel by id, the queued tasks are revoked from the queues of schedulers and the unfinished runs of recurring job are canceled
+// @Tags Job
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.Job
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /jobs/{id}/cancel [post]
+func (h *Handlers) CancelJob(ctx *gin.Context) {
+	var params types.JobParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	job, err := h.service.CancelJob(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
 // @Summary Get Job
 // @Description Get Job by id
 // @Tags Job
@@ -0,0 +1,171 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// nolint
+	_ "d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Create RegistryCredential
+// @Description Create by json config
+// @Tags RegistryCredential
+// @Accept json
+// @Produce json
+// @Param RegistryCredential body types.CreateRegistryCredentialRequest true "RegistryCredential"
+// @Success 200 {object} model.RegistryCredential
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /registry-credentials [post]
+func (h *Handlers) CreateRegistryCredential(ctx *gin.Context) {
+	var json types.CreateRegistryCredentialRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	registryCredential, err := h.service.CreateRegistryCredential(ctx.Request.Context(), json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, registryCredential)
+}
+
+// @Summary Destroy RegistryCredential
+// @Description Destroy by id
+// @Tags RegistryCredential
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /registry-credentials/{id} [delete]
+func (h *Handlers) DestroyRegistryCredential(ctx *gin.Context) {
+	var params types.RegistryCredentialParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.DestroyRegistryCredential(ctx.Request.Context(), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Update RegistryCredential
+// @Description Update by json config
+// @Tags RegistryCredential
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param RegistryCredential body types.UpdateRegistryCredentialRequest true "RegistryCredential"
+// @Success 200 {object} model.RegistryCredential
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /registry-credentials/{id} [patch]
+func (h *Handlers) UpdateRegistryCredential(ctx *gin.Context) {
+	var params types.RegistryCredentialParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var json types.UpdateRegistryCredentialRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	registryCredential, err := h.service.UpdateRegistryCredential(ctx.Request.Context(), params.ID, json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, registryCredential)
+}
+
+// @Summary Get RegistryCredential
+// @Description Get RegistryCredential by id
+// @Tags RegistryCredential
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.RegistryCredential
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /registry-credentials/{id} [get]
+func (h *Handlers) GetRegistryCredential(ctx *gin.Context) {
+	var params types.RegistryCredentialParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	registryCredential, err := h.service.GetRegistryCredential(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, registryCredential)
+}
+
+// @Summary Get RegistryCredentials
+// @Description Get RegistryCredentials
+// @Tags RegistryCredential
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Success 200 {object} []model.RegistryCredential
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /registry-credentials [get]
+func (h *Handlers) GetRegistryCredentials(ctx *gin.Context) {
+	var query types.GetRegistryCredentialsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	registryCredentials, count, err := h.service.GetRegistryCredentials(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, registryCredentials)
+}
@@ -0,0 +1,66 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/delete_task_mock.go -source delete_task.go -package mocks
+
+package job
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/config"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+type DeleteTask interface {
+	CreateDeleteTask(context.Context, []model.Scheduler, types.DeleteTaskArgs) (*internaljob.GroupJobState, error)
+}
+
+type deleteTask struct {
+	job *internaljob.Job
+}
+
+func newDeleteTask(job *internaljob.Job) DeleteTask {
+	return &deleteTask{
+		job: job,
+	}
+}
+
+// CreateDeleteTask sends the delete task job to the queues of schedulers, every scheduler
+// deletes the task in the hosts of its peers and evicts the task from its resource.
+func (d *deleteTask) CreateDeleteTask(ctx context.Context, schedulers []model.Scheduler, json types.DeleteTaskArgs) (*internaljob.GroupJobState, error) {
+	var span trace.Span
+	ctx, span = tracer.Start(ctx, config.SpanDeleteTask, trace.WithSpanKind(trace.SpanKindProducer))
+	span.SetAttributes(config.AttributeDeleteTaskURL.String(json.URL))
+	defer span.End()
+
+	args, err := internaljob.MarshalRequest(internaljob.DeleteTaskRequest{
+		URL:     json.URL,
+		Tag:     json.Tag,
+		Digest:  json.Digest,
+		Filter:  json.Filter,
+		Headers: json.Headers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sendGroupJob(ctx, d.job, internaljob.DeleteTaskJob, args, getSchedulerQueues(schedulers))
+}
@@ -0,0 +1,68 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/get_task_mock.go -source get_task.go -package mocks
+
+package job
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/config"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+type GetTask interface {
+	CreateGetTask(context.Context, []model.Scheduler, types.GetTaskArgs) (*internaljob.GroupJobState, error)
+}
+
+type getTask struct {
+	job *internaljob.Job
+}
+
+func newGetTask(job *internaljob.Job) GetTask {
+	return &getTask{
+		job: job,
+	}
+}
+
+// CreateGetTask sends the get task job to the queues of schedulers, every scheduler
+// returns the task and the peers holding the task in its resource.
+func (g *getTask) CreateGetTask(ctx context.Context, schedulers []model.Scheduler, json types.GetTaskArgs) (*internaljob.GroupJobState, error) {
+	var span trace.Span
+	ctx, span = tracer.Start(ctx, config.SpanGetTask, trace.WithSpanKind(trace.SpanKindProducer))
+	span.SetAttributes(config.AttributeGetTaskID.String(json.TaskID))
+	span.SetAttributes(config.AttributeGetTaskURL.String(json.URL))
+	defer span.End()
+
+	args, err := internaljob.MarshalRequest(internaljob.GetTaskRequest{
+		TaskID:  json.TaskID,
+		URL:     json.URL,
+		Tag:     json.Tag,
+		Digest:  json.Digest,
+		Filter:  json.Filter,
+		Headers: json.Headers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sendGroupJob(ctx, g.job, internaljob.GetTaskJob, args, getSchedulerQueues(schedulers))
+}
@@ -17,16 +17,29 @@
 package job
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
 	internaljob "d7y.io/dragonfly/v2/internal/job"
 	"d7y.io/dragonfly/v2/manager/config"
+	"d7y.io/dragonfly/v2/pkg/encryption"
 )
 
 type Job struct {
 	*internaljob.Job
 	Preheat
+	DeleteTask
+	GetTask
 }
 
-func New(cfg *config.Config) (*Job, error) {
+func New(cfg *config.Config, db *gorm.DB, cipher encryption.Cipher) (*Job, error) {
 	j, err := internaljob.New(&internaljob.Config{
 		Addrs:     cfg.Database.Redis.Addrs,
 		Username:  cfg.Database.Redis.Username,
@@ -38,13 +51,49 @@ func New(cfg *config.Config) (*Job, error) {
 		return nil, err
 	}
 
-	p, err := newPreheat(j)
+	p, err := newPreheat(j, newRegistryCredentials(db, cipher))
 	if err != nil {
 		return nil, err
 	}
 
 	return &Job{
-		Job:     j,
-		Preheat: p,
+		Job:        j,
+		Preheat:    p,
+		DeleteTask: newDeleteTask(j),
+		GetTask:    newGetTask(j),
+	}, nil
+}
+
+// sendGroupJob sends the job with the same args to every queue as a group.
+func sendGroupJob(ctx context.Context, job *internaljob.Job, name string, args []machineryv1tasks.Arg, queues []internaljob.Queue) (*internaljob.GroupJobState, error) {
+	if len(queues) == 0 {
+		return nil, errors.New("no active scheduler in scheduler clusters")
+	}
+
+	var signatures []*machineryv1tasks.Signature
+	for _, queue := range queues {
+		signatures = append(signatures, &machineryv1tasks.Signature{
+			UUID:       fmt.Sprintf("task_%s", uuid.New().String()),
+			Name:       name,
+			RoutingKey: queue.String(),
+			Args:       args,
+		})
+	}
+
+	group, err := machineryv1tasks.NewGroup(signatures...)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("create %s group %s in queues %v", name, group.GroupUUID, queues)
+	if _, err := job.Server.SendGroupWithContext(ctx, group, 0); err != nil {
+		logger.Errorf("create %s group %s failed: %s", name, group.GroupUUID, err.Error())
+		return nil, err
+	}
+
+	return &internaljob.GroupJobState{
+		GroupUUID: group.GroupUUID,
+		State:     machineryv1tasks.StatePending,
+		CreatedAt: time.Now(),
 	}, nil
 }
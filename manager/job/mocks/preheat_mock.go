@@ -8,7 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
-	job "d7y.io/dragonfly/v2/internal/job"
+	job "d7y.io/dragonfly/v2/manager/job"
 	model "d7y.io/dragonfly/v2/manager/model"
 	types "d7y.io/dragonfly/v2/manager/types"
 	gomock "github.com/golang/mock/gomock"
@@ -38,10 +38,10 @@ func (m *MockPreheat) EXPECT() *MockPreheatMockRecorder {
 }
 
 // CreatePreheat mocks base method.
-func (m *MockPreheat) CreatePreheat(arg0 context.Context, arg1 []model.Scheduler, arg2 types.PreheatArgs) (*job.GroupJobState, error) {
+func (m *MockPreheat) CreatePreheat(arg0 context.Context, arg1 []model.Scheduler, arg2 types.PreheatArgs) (*job.PreheatJobState, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreatePreheat", arg0, arg1, arg2)
-	ret0, _ := ret[0].(*job.GroupJobState)
+	ret0, _ := ret[0].(*job.PreheatJobState)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
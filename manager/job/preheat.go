@@ -26,14 +26,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
 	"github.com/distribution/distribution/v3"
-	"github.com/distribution/distribution/v3/manifest/schema2"
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
 	"github.com/google/uuid"
+	"github.com/opencontainers/go-digest"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
@@ -43,6 +46,7 @@ import (
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/types"
 	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
+	"d7y.io/dragonfly/v2/pkg/registry"
 )
 
 var tracer = otel.Tracer("manager")
@@ -57,6 +61,15 @@ const (
 	PreheatFileType PreheatType = "file"
 )
 
+const (
+	// PlatformAll is the platform to preheat the images of all platforms
+	// in manifest list or image index.
+	PlatformAll = "all"
+
+	// DefaultPlatform is the default platform of preheat job.
+	DefaultPlatform = "linux/amd64"
+)
+
 const (
 	// defaultHTTPRequesttimeout is the default timeout of http client.
 	defaultHTTPRequesttimeout = 1 * time.Minute
@@ -64,12 +77,35 @@ const (
 
 var accessURLPattern, _ = regexp.Compile("^(.*)://(.*)/v2/(.*)/manifests/(.*)")
 
+var repositoryURLPattern, _ = regexp.Compile("^(.*)://(.*)/v2/(.*?)(/manifests/.*)?/?$")
+
 type Preheat interface {
-	CreatePreheat(context.Context, []model.Scheduler, types.PreheatArgs) (*internaljob.GroupJobState, error)
+	CreatePreheat(context.Context, []model.Scheduler, types.PreheatArgs) (*PreheatJobState, error)
+}
+
+// PreheatJobState is the state of created preheat group job.
+type PreheatJobState struct {
+	*internaljob.GroupJobState
+
+	// Result is the initial result of job, like the images resolved by tag pattern.
+	Result map[string]any
+}
+
+// PreheatImage is the image of repository resolved by tag pattern.
+type PreheatImage struct {
+	// Tag is the tag of image.
+	Tag string `json:"tag"`
+
+	// Digest is the manifest digest of tag when the job is created.
+	Digest string `json:"digest"`
+
+	// URL is the manifest url pinned by digest.
+	URL string `json:"url"`
 }
 
 type preheat struct {
-	job *internaljob.Job
+	job         *internaljob.Job
+	credentials RegistryCredentials
 }
 
 type preheatImage struct {
@@ -79,13 +115,21 @@ type preheatImage struct {
 	tag      string
 }
 
-func newPreheat(job *internaljob.Job) (Preheat, error) {
+// preheatPlatform is the platform of image, nil stands for all platforms.
+type preheatPlatform struct {
+	os           string
+	architecture string
+	variant      string
+}
+
+func newPreheat(job *internaljob.Job, credentials RegistryCredentials) (Preheat, error) {
 	return &preheat{
-		job: job,
+		job:         job,
+		credentials: credentials,
 	}, nil
 }
 
-func (p *preheat) CreatePreheat(ctx context.Context, schedulers []model.Scheduler, json types.PreheatArgs) (*internaljob.GroupJobState, error) {
+func (p *preheat) CreatePreheat(ctx context.Context, schedulers []model.Scheduler, json types.PreheatArgs) (*PreheatJobState, error) {
 	var span trace.Span
 	ctx, span = tracer.Start(ctx, config.SpanPreheat, trace.WithSpanKind(trace.SpanKindProducer))
 	span.SetAttributes(config.AttributePreheatType.String(json.Type))
@@ -101,20 +145,59 @@ func (p *preheat) CreatePreheat(ctx context.Context, schedulers []model.Schedule
 	queues := getSchedulerQueues(schedulers)
 
 	// Generate download files
-	var files []internaljob.PreheatRequest
+	var (
+		files  []internaljob.PreheatRequest
+		result map[string]any
+	)
 	switch PreheatType(json.Type) {
 	case PreheatImageType:
+		platform, err := parsePlatform(json.Platform)
+		if err != nil {
+			return nil, err
+		}
+
+		header := nethttp.MapToHeader(rawheader)
+		if json.TagRegex != "" || json.TagSemver != "" {
+			// Parse image repository url and resolve tags to digests
+			image, err := parseRepositoryURL(url)
+			if err != nil {
+				return nil, err
+			}
+
+			images, err := p.resolveImages(ctx, json.TagRegex, json.TagSemver, header, image)
+			if err != nil {
+				return nil, err
+			}
+
+			var urls []string
+			for _, image := range images {
+				urls = append(urls, image.URL)
+			}
+
+			files, err = p.getLayers(ctx, urls, tag, filter, header, image, platform)
+			if err != nil {
+				return nil, err
+			}
+
+			result = map[string]any{"Images": images}
+			break
+		}
+
 		// Parse image manifest url
 		image, err := parseAccessURL(url)
 		if err != nil {
 			return nil, err
 		}
 
-		files, err = p.getLayers(ctx, url, tag, filter, nethttp.MapToHeader(rawheader), image)
+		files, err = p.getLayers(ctx, []string{url}, tag, filter, header, image, platform)
 		if err != nil {
 			return nil, err
 		}
 	case PreheatFileType:
+		if json.TagRegex != "" || json.TagSemver != "" {
+			return nil, errors.New("tag pattern is only supported by image preheat")
+		}
+
 		files = []internaljob.PreheatRequest{
 			{
 				URL:     url,
@@ -127,7 +210,64 @@ func (p *preheat) CreatePreheat(ctx context.Context, schedulers []model.Schedule
 		return nil, errors.New("unknow preheat type")
 	}
 
-	return p.createGroupJob(ctx, files, queues)
+	groupJobState, err := p.createGroupJob(ctx, files, queues)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreheatJobState{
+		GroupJobState: groupJobState,
+		Result:        result,
+	}, nil
+}
+
+// ValidatePreheatArgs validates the args of preheat job without requesting the registry,
+// it is used to reject the invalid recurring job before the first run.
+func ValidatePreheatArgs(args types.PreheatArgs) error {
+	switch PreheatType(args.Type) {
+	case PreheatImageType:
+		if _, err := parsePlatform(args.Platform); err != nil {
+			return err
+		}
+
+		if args.TagRegex == "" && args.TagSemver == "" {
+			_, err := parseAccessURL(args.URL)
+			return err
+		}
+
+		if _, err := parseRepositoryURL(args.URL); err != nil {
+			return err
+		}
+
+		if args.TagRegex != "" {
+			if _, err := regexp.Compile(args.TagRegex); err != nil {
+				return fmt.Errorf("invalid tag regex %s: %w", args.TagRegex, err)
+			}
+		}
+
+		if args.TagSemver != "" {
+			if _, err := semver.NewConstraint(args.TagSemver); err != nil {
+				return fmt.Errorf("invalid tag semver %s: %w", args.TagSemver, err)
+			}
+		}
+	case PreheatFileType:
+		if args.TagRegex != "" || args.TagSemver != "" {
+			return errors.New("tag pattern is only supported by image preheat")
+		}
+
+		u, err := neturl.Parse(args.URL)
+		if err != nil {
+			return err
+		}
+
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid url %s", args.URL)
+		}
+	default:
+		return errors.New("unknow preheat type")
+	}
+
+	return nil
 }
 
 func (p *preheat) createGroupJob(ctx context.Context, files []internaljob.PreheatRequest, queues []internaljob.Queue) (*internaljob.GroupJobState, error) {
@@ -172,51 +312,257 @@ func (p *preheat) createGroupJob(ctx context.Context, files []internaljob.Prehea
 	}, nil
 }
 
-func (p *preheat) getLayers(ctx context.Context, url, tag, filter string, header http.Header, image *preheatImage) ([]internaljob.PreheatRequest, error) {
+// getLayers returns the preheat requests of the layers in the manifests of urls.
+func (p *preheat) getLayers(ctx context.Context, urls []string, tag, filter string, header http.Header, image *preheatImage, platform *preheatPlatform) ([]internaljob.PreheatRequest, error) {
 	ctx, span := tracer.Start(ctx, config.SpanGetLayers, trace.WithSpanKind(trace.SpanKindProducer))
 	defer span.End()
 
-	resp, err := p.getManifests(ctx, url, header)
+	var manifests []distribution.Manifest
+	for _, url := range urls {
+		m, err := p.getManifests(ctx, url, header, image, platform)
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, m...)
+	}
+
+	return p.parseLayers(manifests, tag, filter, header, image), nil
+}
+
+// resolveImages lists the tags of repository and resolves the tags matched
+// by the regex and the semver constraint to manifest digests.
+func (p *preheat) resolveImages(ctx context.Context, tagRegex, tagSemver string, header http.Header, image *preheatImage) ([]PreheatImage, error) {
+	var (
+		regex      *regexp.Regexp
+		constraint *semver.Constraints
+		err        error
+	)
+	if tagRegex != "" {
+		if regex, err = regexp.Compile(tagRegex); err != nil {
+			return nil, fmt.Errorf("invalid tag regex %s: %w", tagRegex, err)
+		}
+	}
+
+	if tagSemver != "" {
+		if constraint, err = semver.NewConstraint(tagSemver); err != nil {
+			return nil, fmt.Errorf("invalid tag semver %s: %w", tagSemver, err)
+		}
+	}
+
+	tags, err := p.getTags(ctx, header, image)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode/100 != 2 {
-		if resp.StatusCode == http.StatusUnauthorized {
-			token, err := getAuthToken(ctx, resp.Header)
-			if err != nil {
-				return nil, err
+	var images []PreheatImage
+	for _, tag := range tags {
+		if regex != nil && !regex.MatchString(tag) {
+			continue
+		}
+
+		if constraint != nil {
+			version, err := semver.NewVersion(tag)
+			if err != nil || !constraint.Check(version) {
+				continue
 			}
+		}
+
+		dgst, err := p.resolveDigest(ctx, manifestURL(image.protocol, image.domain, image.name, tag), header)
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, PreheatImage{
+			Tag:    tag,
+			Digest: dgst.String(),
+			URL:    manifestURL(image.protocol, image.domain, image.name, dgst.String()),
+		})
+	}
+
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no tag of %s matches", image.name)
+	}
+
+	return images, nil
+}
+
+// getTags lists all tags of repository, the paginated tags are followed by the next link.
+func (p *preheat) getTags(ctx context.Context, header http.Header, image *preheatImage) ([]string, error) {
+	var (
+		tags []string
+		url  = tagsURL(image.protocol, image.domain, image.name)
+	)
+	for url != "" {
+		resp, err := p.requestRegistry(ctx, http.MethodGet, url, header, "application/json")
+		if err != nil {
+			return nil, err
+		}
+
+		var tagList struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&tagList)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
 
-			bearer := "Bearer " + token
-			header.Add("Authorization", bearer)
+		tags = append(tags, tagList.Tags...)
+		url = nextURL(resp.Request.URL, resp.Header.Get("Link"))
+	}
 
-			resp, err = p.getManifests(ctx, url, header)
+	return tags, nil
+}
+
+// resolveDigest returns the digest of manifest by the Docker-Content-Digest header of HEAD request,
+// the digest is computed by the manifest content if the header is not found.
+func (p *preheat) resolveDigest(ctx context.Context, url string, header http.Header) (digest.Digest, error) {
+	resp, err := p.requestRegistry(ctx, http.MethodHead, url, header, strings.Join(registry.ManifestMediaTypes, ", "))
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if dgst, err := digest.Parse(resp.Header.Get("Docker-Content-Digest")); err == nil {
+		return dgst, nil
+	}
+
+	resp, err = p.requestRegistry(ctx, http.MethodGet, url, header, strings.Join(registry.ManifestMediaTypes, ", "))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	return digest.FromReader(resp.Body)
+}
+
+// getManifests returns the image manifests of url, the manifest list and image index
+// are resolved to the image manifests of the matched platforms.
+func (p *preheat) getManifests(ctx context.Context, url string, header http.Header, image *preheatImage, platform *preheatPlatform) ([]distribution.Manifest, error) {
+	manifest, err := p.getManifest(ctx, url, header)
+	if err != nil {
+		return nil, err
+	}
+
+	// OCI image index is unmarshaled as manifest list.
+	manifestList, ok := manifest.(*manifestlist.DeserializedManifestList)
+	if !ok {
+		return []distribution.Manifest{manifest}, nil
+	}
+
+	var manifests []distribution.Manifest
+	for _, desc := range manifestList.Manifests {
+		if !platform.match(desc.Platform) {
+			continue
+		}
+
+		manifest, err := p.getManifest(ctx, manifestURL(image.protocol, image.domain, image.name, desc.Digest.String()), header)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := manifest.(*manifestlist.DeserializedManifestList); ok {
+			return nil, fmt.Errorf("nested manifest list %s is not supported", desc.Digest)
+		}
+
+		manifests = append(manifests, manifest)
+	}
+
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no manifest matches platform %s", platform)
+	}
+
+	return manifests, nil
+}
+
+// getManifest requests the manifest from registry.
+func (p *preheat) getManifest(ctx context.Context, url string, header http.Header) (distribution.Manifest, error) {
+	resp, err := p.requestRegistry(ctx, http.MethodGet, url, header, strings.Join(registry.ManifestMediaTypes, ", "))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.UnmarshalManifest(resp.Header.Get("Content-Type"), body)
+}
+
+// requestRegistry requests the registry, it requests the auth token with the stored credential of registry
+// host and retries when registry responses unauthorized. The stored basic credential is only sent to the token
+// service, and the header is updated with the short-lived token scoped by the challenge of registry, which
+// is passed to seed peers. The stored static bearer token is set to the header directly. The registry of
+// basic auth challenge is rejected, because seed peers can not download the layers without the stored
+// credential. The response body must be closed by caller.
+func (p *preheat) requestRegistry(ctx context.Context, method, url string, header http.Header, accept string) (*http.Response, error) {
+	resp, err := p.request(ctx, method, url, header, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		if strings.HasPrefix(strings.ToLower(resp.Header.Get("WWW-Authenticate")), "basic") {
+			return nil, errors.New("registry of basic auth challenge is not supported by preheat")
+		}
+
+		auth, err := p.registryAuth(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+
+		token := ""
+		if auth != nil && auth.Token != "" {
+			token = auth.Token
+		} else {
+			token, err = getAuthToken(ctx, resp.Header, auth)
 			if err != nil {
 				return nil, err
 			}
-		} else {
-			return nil, fmt.Errorf("request registry %d", resp.StatusCode)
 		}
+
+		header.Set("Authorization", "Bearer "+token)
+		resp, err = p.request(ctx, method, url, header, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("request registry %d", resp.StatusCode)
 	}
 
-	layers, err := p.parseLayers(resp, url, tag, filter, header, image)
+	return resp, nil
+}
+
+// registryAuth returns the auth of stored credential by the host of url,
+// nil is returned if the credential of host is not found.
+func (p *preheat) registryAuth(ctx context.Context, url string) (*RegistryAuth, error) {
+	if p.credentials == nil {
+		return nil, nil
+	}
+
+	u, err := neturl.Parse(url)
 	if err != nil {
 		return nil, err
 	}
 
-	return layers, nil
+	return p.credentials.Get(ctx, u.Host)
 }
 
-func (p *preheat) getManifests(ctx context.Context, url string, header http.Header) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+func (p *preheat) request(ctx context.Context, method, url string, header http.Header, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header = header
-	req.Header.Add("Accept", schema2.MediaTypeManifest)
+	req.Header = header.Clone()
+	req.Header.Set("Accept", accept)
 
 	client := &http.Client{
 		Timeout: defaultHTTPRequesttimeout,
@@ -233,38 +579,42 @@ func (p *preheat) getManifests(ctx context.Context, url string, header http.Head
 	return resp, nil
 }
 
-func (p *preheat) parseLayers(resp *http.Response, url, tag, filter string, header http.Header, image *preheatImage) ([]internaljob.PreheatRequest, error) {
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+// parseLayers returns the preheat requests of the layers and config blobs of manifests,
+// the blobs shared by manifests are preheated once.
+func (p *preheat) parseLayers(manifests []distribution.Manifest, tag, filter string, header http.Header, image *preheatImage) []internaljob.PreheatRequest {
+	var (
+		layers  []internaljob.PreheatRequest
+		digests = map[string]struct{}{}
+	)
+	for _, manifest := range manifests {
+		for _, v := range manifest.References() {
+			digest := v.Digest.String()
+			if _, ok := digests[digest]; ok {
+				continue
+			}
+			digests[digest] = struct{}{}
 
-	manifest, _, err := distribution.UnmarshalManifest(schema2.MediaTypeManifest, body)
-	if err != nil {
-		return nil, err
-	}
+			layer := internaljob.PreheatRequest{
+				URL:     layerURL(image.protocol, image.domain, image.name, digest),
+				Tag:     tag,
+				Filter:  filter,
+				Headers: nethttp.HeaderToMap(header),
+			}
 
-	var layers []internaljob.PreheatRequest
-	for _, v := range manifest.References() {
-		digest := v.Digest.String()
-		layer := internaljob.PreheatRequest{
-			URL:     layerURL(image.protocol, image.domain, image.name, digest),
-			Tag:     tag,
-			Filter:  filter,
-			Headers: nethttp.HeaderToMap(header),
+			layers = append(layers, layer)
 		}
-
-		layers = append(layers, layer)
 	}
 
-	return layers, nil
+	return layers
 }
 
-func getAuthToken(ctx context.Context, header http.Header) (string, error) {
+// getAuthToken requests the bearer token by the challenge of registry,
+// the token is requested with the basic auth if the auth is not nil.
+func getAuthToken(ctx context.Context, header http.Header, auth *RegistryAuth) (string, error) {
 	ctx, span := tracer.Start(ctx, config.SpanAuthWithRegistry, trace.WithSpanKind(trace.SpanKindProducer))
 	defer span.End()
 
-	authURL := authURL(header.Values("WWW-Authenticate"))
+	authURL := registry.AuthURL(header.Values("WWW-Authenticate"))
 	if len(authURL) == 0 {
 		return "", errors.New("authURL is empty")
 	}
@@ -274,6 +624,10 @@ func getAuthToken(ctx context.Context, header http.Header) (string, error) {
 		return "", err
 	}
 
+	if auth != nil {
+		req.Header.Set("Authorization", auth.BasicAuth())
+	}
+
 	client := &http.Client{
 		Timeout: defaultHTTPRequesttimeout,
 		Transport: &http.Transport{
@@ -288,37 +642,43 @@ func getAuthToken(ctx context.Context, header http.Header) (string, error) {
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
-	var result map[string]any
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", err
-	}
+	return registry.ParseToken(body)
+}
 
-	if result["token"] == nil {
-		return "", errors.New("token is empty")
-	}
+func layerURL(protocol string, domain string, name string, digest string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/blobs/%s", protocol, domain, name, digest)
+}
 
-	token := fmt.Sprintf("%v", result["token"])
-	return token, nil
+func manifestURL(protocol string, domain string, name string, reference string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/manifests/%s", protocol, domain, name, reference)
+}
 
+func tagsURL(protocol string, domain string, name string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/tags/list", protocol, domain, name)
 }
 
-func authURL(wwwAuth []string) string {
-	// Bearer realm="<auth-service-url>",service="<service>",scope="repository:<name>:pull"
-	if len(wwwAuth) == 0 {
+// nextURL returns the url of next page by the link header, like
+// </v2/<name>/tags/list?n=<n>&last=<last>>; rel="next".
+func nextURL(base *neturl.URL, link string) string {
+	if link == "" {
 		return ""
 	}
-	polished := make([]string, 0)
-	for _, it := range wwwAuth {
-		polished = append(polished, strings.ReplaceAll(it, "\"", ""))
+
+	for _, v := range strings.Split(link, ",") {
+		fields := strings.Split(v, ";")
+		if len(fields) < 2 || !strings.Contains(fields[1], `rel="next"`) {
+			continue
+		}
+
+		ref, err := neturl.Parse(strings.Trim(strings.TrimSpace(fields[0]), "<>"))
+		if err != nil {
+			return ""
+		}
+
+		return base.ResolveReference(ref).String()
 	}
-	fileds := strings.Split(polished[0], ",")
-	host := strings.Split(fileds[0], "=")[1]
-	query := strings.Join(fileds[1:], "&")
-	return fmt.Sprintf("%s?%s", host, query)
-}
 
-func layerURL(protocol string, domain string, name string, digest string) string {
-	return fmt.Sprintf("%s://%s/v2/%s/blobs/%s", protocol, domain, name, digest)
+	return ""
 }
 
 func parseAccessURL(url string) (*preheatImage, error) {
@@ -335,6 +695,82 @@ func parseAccessURL(url string) (*preheatImage, error) {
 	}, nil
 }
 
+// parsePlatform parses the platform in os/arch[/variant] format, the empty platform is
+// the default platform and nil is returned for all platforms.
+func parsePlatform(platform string) (*preheatPlatform, error) {
+	switch platform {
+	case "":
+		platform = DefaultPlatform
+	case PlatformAll:
+		return nil, nil
+	}
+
+	fields := strings.Split(platform, "/")
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, fmt.Errorf("invalid platform %s", platform)
+	}
+
+	for _, field := range fields {
+		if field == "" {
+			return nil, fmt.Errorf("invalid platform %s", platform)
+		}
+	}
+
+	p := &preheatPlatform{
+		os:           fields[0],
+		architecture: fields[1],
+	}
+
+	if len(fields) == 3 {
+		p.variant = fields[2]
+	}
+
+	return p, nil
+}
+
+// match reports whether the platform of manifest matches, the variant is
+// matched only if it is specified. All platforms match every manifest
+// except the manifests of unknown platform, like attestation manifests.
+func (p *preheatPlatform) match(spec manifestlist.PlatformSpec) bool {
+	if p == nil {
+		return spec.OS != "unknown"
+	}
+
+	if p.os != spec.OS || p.architecture != spec.Architecture {
+		return false
+	}
+
+	return p.variant == "" || p.variant == spec.Variant
+}
+
+// String returns the platform in os/arch[/variant] format.
+func (p *preheatPlatform) String() string {
+	if p == nil {
+		return PlatformAll
+	}
+
+	if p.variant == "" {
+		return fmt.Sprintf("%s/%s", p.os, p.architecture)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", p.os, p.architecture, p.variant)
+}
+
+// parseRepositoryURL parses the repository url like <protocol>://<domain>/v2/<name>,
+// the reference of manifest url is ignored.
+func parseRepositoryURL(url string) (*preheatImage, error) {
+	r := repositoryURLPattern.FindStringSubmatch(url)
+	if len(r) != 5 || r[3] == "" {
+		return nil, errors.New("parse repository url failed")
+	}
+
+	return &preheatImage{
+		protocol: r[1],
+		domain:   r[2],
+		name:     r[3],
+	}, nil
+}
+
 func getSchedulerQueues(schedulers []model.Scheduler) []internaljob.Queue {
 	var queues []internaljob.Queue
 	for _, scheduler := range schedulers {
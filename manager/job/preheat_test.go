@@ -0,0 +1,734 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+	"github.com/distribution/distribution/v3/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+type mockManifest struct {
+	mediaType string
+	body      []byte
+}
+
+func newMockImageManifest(t *testing.T, mediaType string, config string, layers ...string) mockManifest {
+	descriptor := func(mediaType, content string) map[string]any {
+		return map[string]any{
+			"mediaType": mediaType,
+			"size":      len(content),
+			"digest":    digest.FromString(content).String(),
+		}
+	}
+
+	var layerDescriptors []map[string]any
+	for _, layer := range layers {
+		layerDescriptors = append(layerDescriptors, descriptor(specs.MediaTypeImageLayerGzip, layer))
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     mediaType,
+		"config":        descriptor(specs.MediaTypeImageConfig, config),
+		"layers":        layerDescriptors,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mockManifest{mediaType: mediaType, body: body}
+}
+
+func newMockImageIndex(t *testing.T, mediaType string, manifests map[string]mockManifest) mockManifest {
+	var descriptors []map[string]any
+	for platform, manifest := range manifests {
+		p, err := parsePlatform(platform)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		descriptors = append(descriptors, map[string]any{
+			"mediaType": manifest.mediaType,
+			"size":      len(manifest.body),
+			"digest":    digest.FromBytes(manifest.body).String(),
+			"platform": map[string]any{
+				"os":           p.os,
+				"architecture": p.architecture,
+				"variant":      p.variant,
+			},
+		})
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     mediaType,
+		"manifests":     descriptors,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mockManifest{mediaType: mediaType, body: body}
+}
+
+func TestPreheat_getLayers(t *testing.T) {
+	amd64 := newMockImageManifest(t, specs.MediaTypeImageManifest, "amd64-config", "amd64-layer", "shared-layer")
+	arm64 := newMockImageManifest(t, specs.MediaTypeImageManifest, "arm64-config", "arm64-layer", "shared-layer")
+	unknown := newMockImageManifest(t, specs.MediaTypeImageManifest, "attestation-config", "attestation-layer")
+	schema2Manifest := newMockImageManifest(t, schema2.MediaTypeManifest, "schema2-config", "schema2-layer")
+
+	blob := func(content string) string {
+		return fmt.Sprintf("/v2/foo/blobs/%s", digest.FromString(content))
+	}
+
+	tests := []struct {
+		name     string
+		manifest mockManifest
+		platform string
+		expect   func(t *testing.T, urls []string, err error)
+	}{
+		{
+			name:     "docker schema2 manifest",
+			manifest: schema2Manifest,
+			expect: func(t *testing.T, urls []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{blob("schema2-config"), blob("schema2-layer")}, urls)
+			},
+		},
+		{
+			name:     "oci manifest without content type",
+			manifest: mockManifest{body: amd64.body},
+			expect: func(t *testing.T, urls []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{blob("amd64-config"), blob("amd64-layer"), blob("shared-layer")}, urls)
+			},
+		},
+		{
+			name: "oci image index with default platform",
+			manifest: newMockImageIndex(t, specs.MediaTypeImageIndex, map[string]mockManifest{
+				"linux/amd64":     amd64,
+				"linux/arm64/v8":  arm64,
+				"unknown/unknown": unknown,
+			}),
+			expect: func(t *testing.T, urls []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{blob("amd64-config"), blob("amd64-layer"), blob("shared-layer")}, urls)
+			},
+		},
+		{
+			name: "docker manifest list with platform without variant",
+			manifest: newMockImageIndex(t, manifestlist.MediaTypeManifestList, map[string]mockManifest{
+				"linux/amd64":    amd64,
+				"linux/arm64/v8": arm64,
+			}),
+			platform: "linux/arm64",
+			expect: func(t *testing.T, urls []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{blob("arm64-config"), blob("arm64-layer"), blob("shared-layer")}, urls)
+			},
+		},
+		{
+			name: "oci image index with all platforms",
+			manifest: newMockImageIndex(t, specs.MediaTypeImageIndex, map[string]mockManifest{
+				"linux/amd64":     amd64,
+				"linux/arm64/v8":  arm64,
+				"unknown/unknown": unknown,
+			}),
+			platform: PlatformAll,
+			expect: func(t *testing.T, urls []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.ElementsMatch([]string{
+					blob("amd64-config"), blob("amd64-layer"), blob("shared-layer"),
+					blob("arm64-config"), blob("arm64-layer"),
+				}, urls)
+			},
+		},
+		{
+			name: "oci image index without matched platform",
+			manifest: newMockImageIndex(t, specs.MediaTypeImageIndex, map[string]mockManifest{
+				"linux/amd64": amd64,
+			}),
+			platform: "linux/s390x",
+			expect: func(t *testing.T, urls []string, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "no manifest matches platform linux/s390x")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			manifests := map[string]mockManifest{
+				"/v2/foo/manifests/latest": tc.manifest,
+			}
+			for _, manifest := range []mockManifest{amd64, arm64, unknown} {
+				manifests[fmt.Sprintf("/v2/foo/manifests/%s", digest.FromBytes(manifest.body))] = manifest
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				manifest, ok := manifests[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				if manifest.mediaType != "" {
+					w.Header().Set("Content-Type", manifest.mediaType)
+				}
+				w.Write(manifest.body)
+			}))
+			defer server.Close()
+
+			url := server.URL + "/v2/foo/manifests/latest"
+			image, err := parseAccessURL(url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			platform, err := parsePlatform(tc.platform)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p := &preheat{}
+			layers, err := p.getLayers(context.Background(), []string{url}, "", "", http.Header{}, image, platform)
+			var urls []string
+			for _, layer := range layers {
+				urls = append(urls, layer.URL[len(server.URL):])
+			}
+
+			tc.expect(t, urls, err)
+		})
+	}
+}
+
+func TestPreheat_resolveImages(t *testing.T) {
+	manifests := map[string]mockManifest{}
+	for _, tag := range []string{"v1.0.0", "v1.1.0", "v2.0.0", "latest"} {
+		manifests[tag] = newMockImageManifest(t, specs.MediaTypeImageManifest, tag+"-config", tag+"-layer")
+	}
+
+	tests := []struct {
+		name      string
+		tagRegex  string
+		tagSemver string
+		expect    func(t *testing.T, images []PreheatImage, err error)
+	}{
+		{
+			name:     "tag regex",
+			tagRegex: "^v1\\.",
+			expect: func(t *testing.T, images []PreheatImage, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(images, 2)
+				assert.Equal("v1.0.0", images[0].Tag)
+				assert.Equal(digest.FromBytes(manifests["v1.0.0"].body).String(), images[0].Digest)
+				assert.Equal("v1.1.0", images[1].Tag)
+				assert.Equal(digest.FromBytes(manifests["v1.1.0"].body).String(), images[1].Digest)
+				assert.Contains(images[1].URL, "/v2/foo/manifests/"+images[1].Digest)
+			},
+		},
+		{
+			name:      "tag semver",
+			tagSemver: ">= 1.1, < 3",
+			expect: func(t *testing.T, images []PreheatImage, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(images, 2)
+				assert.Equal("v1.1.0", images[0].Tag)
+				assert.Equal("v2.0.0", images[1].Tag)
+			},
+		},
+		{
+			name:      "tag regex and semver",
+			tagRegex:  "^v2",
+			tagSemver: ">= 1.1",
+			expect: func(t *testing.T, images []PreheatImage, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(images, 1)
+				assert.Equal("v2.0.0", images[0].Tag)
+			},
+		},
+		{
+			name:     "no tag matches",
+			tagRegex: "^v3",
+			expect: func(t *testing.T, images []PreheatImage, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "no tag of foo matches")
+			},
+		},
+		{
+			name:      "invalid tag semver",
+			tagSemver: "foo",
+			expect: func(t *testing.T, images []PreheatImage, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "invalid tag semver foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v2/foo/tags/list":
+					// Paginate tags by two.
+					if r.URL.Query().Get("last") == "" {
+						w.Header().Set("Link", `</v2/foo/tags/list?n=2&last=v1.1.0>; rel="next"`)
+						json.NewEncoder(w).Encode(map[string]any{"name": "foo", "tags": []string{"v1.0.0", "v1.1.0"}})
+						return
+					}
+
+					json.NewEncoder(w).Encode(map[string]any{"name": "foo", "tags": []string{"v2.0.0", "latest"}})
+					return
+				}
+
+				tag := r.URL.Path[len("/v2/foo/manifests/"):]
+				manifest, ok := manifests[tag]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				// Docker-Content-Digest is only responded for v1 tags, the others are computed by content.
+				w.Header().Set("Content-Type", manifest.mediaType)
+				if tag[:2] == "v1" {
+					w.Header().Set("Docker-Content-Digest", digest.FromBytes(manifest.body).String())
+				}
+
+				if r.Method == http.MethodGet {
+					w.Write(manifest.body)
+				}
+			}))
+			defer server.Close()
+
+			image, err := parseRepositoryURL(server.URL + "/v2/foo")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p := &preheat{}
+			images, err := p.resolveImages(context.Background(), tc.tagRegex, tc.tagSemver, http.Header{}, image)
+			tc.expect(t, images, err)
+		})
+	}
+}
+
+type fakeRegistryCredentials struct {
+	auths map[string]*RegistryAuth
+	err   error
+}
+
+func (f *fakeRegistryCredentials) Get(ctx context.Context, host string) (*RegistryAuth, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return f.auths[host], nil
+}
+
+func TestPreheat_requestRegistry(t *testing.T) {
+	basic := &RegistryAuth{Username: "foo", Password: "bar"}
+	tests := []struct {
+		name      string
+		challenge string
+		auth      func(host string) *fakeRegistryCredentials
+		header    http.Header
+		expect    func(t *testing.T, authorization string, header http.Header, err error)
+	}{
+		{
+			name:      "bearer challenge with basic credential",
+			challenge: "bearer",
+			auth: func(host string) *fakeRegistryCredentials {
+				return &fakeRegistryCredentials{auths: map[string]*RegistryAuth{host: basic}}
+			},
+			expect: func(t *testing.T, authorization string, header http.Header, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("Bearer private", authorization)
+				assert.Equal("Bearer private", header.Get("Authorization"))
+			},
+		},
+		{
+			name:      "bearer challenge with static bearer token credential",
+			challenge: "bearer",
+			auth: func(host string) *fakeRegistryCredentials {
+				return &fakeRegistryCredentials{auths: map[string]*RegistryAuth{host: {Token: "static"}}}
+			},
+			expect: func(t *testing.T, authorization string, header http.Header, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("Bearer static", authorization)
+				assert.Equal("Bearer static", header.Get("Authorization"))
+			},
+		},
+		{
+			name:      "bearer challenge without credential",
+			challenge: "bearer",
+			auth: func(host string) *fakeRegistryCredentials {
+				return &fakeRegistryCredentials{}
+			},
+			expect: func(t *testing.T, authorization string, header http.Header, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("Bearer anonymous", authorization)
+				assert.Equal("Bearer anonymous", header.Get("Authorization"))
+			},
+		},
+		{
+			name:      "basic challenge with basic credential",
+			challenge: "basic",
+			auth: func(host string) *fakeRegistryCredentials {
+				return &fakeRegistryCredentials{auths: map[string]*RegistryAuth{host: basic}}
+			},
+			expect: func(t *testing.T, authorization string, header http.Header, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "registry of basic auth challenge is not supported by preheat")
+				assert.Empty(header.Get("Authorization"))
+			},
+		},
+		{
+			name:      "authorization of headers is used without credential",
+			challenge: "bearer",
+			auth: func(host string) *fakeRegistryCredentials {
+				return &fakeRegistryCredentials{err: errors.New("foo")}
+			},
+			header: http.Header{"Authorization": []string{"Bearer private"}},
+			expect: func(t *testing.T, authorization string, header http.Header, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("Bearer private", authorization)
+			},
+		},
+		{
+			name:      "get credential failed",
+			challenge: "bearer",
+			auth: func(host string) *fakeRegistryCredentials {
+				return &fakeRegistryCredentials{err: errors.New("foo")}
+			},
+			expect: func(t *testing.T, authorization string, header http.Header, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization := r.Header.Get("Authorization")
+				if r.URL.Path == "/token" {
+					token := "anonymous"
+					if authorization == basic.BasicAuth() {
+						token = "private"
+					}
+
+					json.NewEncoder(w).Encode(map[string]string{"token": token})
+					return
+				}
+
+				if authorization == "" {
+					if tc.challenge == "basic" {
+						w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+					} else {
+						w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+					}
+
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				w.Write([]byte(authorization))
+			}))
+			defer server.Close()
+
+			header := tc.header
+			if header == nil {
+				header = http.Header{}
+			}
+
+			p := &preheat{credentials: tc.auth(server.Listener.Addr().String())}
+			resp, err := p.requestRegistry(context.Background(), http.MethodGet, server.URL+"/v2/foo/manifests/latest", header, "application/json")
+			if err != nil {
+				tc.expect(t, "", header, err)
+				return
+			}
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			tc.expect(t, string(body), header, err)
+		})
+	}
+}
+
+func TestPreheat_parseRepositoryURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		expect func(t *testing.T, image *preheatImage, err error)
+	}{
+		{
+			name: "repository url",
+			url:  "https://index.docker.io/v2/library/alpine",
+			expect: func(t *testing.T, image *preheatImage, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&preheatImage{protocol: "https", domain: "index.docker.io", name: "library/alpine"}, image)
+			},
+		},
+		{
+			name: "manifest url",
+			url:  "https://index.docker.io/v2/library/alpine/manifests/3.16",
+			expect: func(t *testing.T, image *preheatImage, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&preheatImage{protocol: "https", domain: "index.docker.io", name: "library/alpine"}, image)
+			},
+		},
+		{
+			name: "invalid url",
+			url:  "https://index.docker.io/library/alpine",
+			expect: func(t *testing.T, image *preheatImage, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "parse repository url failed")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			image, err := parseRepositoryURL(tc.url)
+			tc.expect(t, image, err)
+		})
+	}
+}
+
+func TestPreheat_parsePlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		expect   func(t *testing.T, platform *preheatPlatform, err error)
+	}{
+		{
+			name:     "default platform",
+			platform: "",
+			expect: func(t *testing.T, platform *preheatPlatform, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&preheatPlatform{os: "linux", architecture: "amd64"}, platform)
+				assert.Equal(DefaultPlatform, platform.String())
+			},
+		},
+		{
+			name:     "platform with variant",
+			platform: "linux/arm64/v8",
+			expect: func(t *testing.T, platform *preheatPlatform, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&preheatPlatform{os: "linux", architecture: "arm64", variant: "v8"}, platform)
+				assert.Equal("linux/arm64/v8", platform.String())
+			},
+		},
+		{
+			name:     "all platforms",
+			platform: PlatformAll,
+			expect: func(t *testing.T, platform *preheatPlatform, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Nil(platform)
+				assert.Equal(PlatformAll, platform.String())
+			},
+		},
+		{
+			name:     "invalid platform",
+			platform: "linux",
+			expect: func(t *testing.T, platform *preheatPlatform, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid platform linux")
+			},
+		},
+		{
+			name:     "platform with empty field",
+			platform: "linux//v8",
+			expect: func(t *testing.T, platform *preheatPlatform, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid platform linux//v8")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			platform, err := parsePlatform(tc.platform)
+			tc.expect(t, platform, err)
+		})
+	}
+}
+
+func TestPreheat_ValidatePreheatArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   types.PreheatArgs
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "image args",
+			args: types.PreheatArgs{
+				Type:     "image",
+				URL:      "https://index.docker.io/v2/library/alpine/manifests/3.16",
+				Platform: "linux/arm64",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "image args with tag pattern",
+			args: types.PreheatArgs{
+				Type:      "image",
+				URL:       "https://index.docker.io/v2/library/alpine",
+				TagRegex:  "^3\\.",
+				TagSemver: ">= 3.16",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "file args",
+			args: types.PreheatArgs{
+				Type: "file",
+				URL:  "https://example.com/foo",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "invalid manifest url",
+			args: types.PreheatArgs{
+				Type: "image",
+				URL:  "https://index.docker.io/v2/library/alpine",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "parse access url failed")
+			},
+		},
+		{
+			name: "invalid repository url",
+			args: types.PreheatArgs{
+				Type:     "image",
+				URL:      "https://index.docker.io/library/alpine",
+				TagRegex: ".*",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "parse repository url failed")
+			},
+		},
+		{
+			name: "invalid file url",
+			args: types.PreheatArgs{
+				Type: "file",
+				URL:  "foo",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid url foo")
+			},
+		},
+		{
+			name: "invalid tag regex",
+			args: types.PreheatArgs{
+				Type:     "image",
+				URL:      "https://index.docker.io/v2/library/alpine",
+				TagRegex: "[",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "invalid tag regex [")
+			},
+		},
+		{
+			name: "invalid tag semver",
+			args: types.PreheatArgs{
+				Type:      "image",
+				URL:       "https://index.docker.io/v2/library/alpine",
+				TagSemver: "foo",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "invalid tag semver foo")
+			},
+		},
+		{
+			name: "invalid platform",
+			args: types.PreheatArgs{
+				Type:     "image",
+				URL:      "https://index.docker.io/v2/library/alpine/manifests/3.16",
+				Platform: "linux",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid platform linux")
+			},
+		},
+		{
+			name: "tag pattern of file args",
+			args: types.PreheatArgs{
+				Type:     "file",
+				URL:      "https://example.com/foo",
+				TagRegex: ".*",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "tag pattern is only supported by image preheat")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, ValidatePreheatArgs(tc.args))
+		})
+	}
+}
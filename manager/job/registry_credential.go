@@ -0,0 +1,148 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/registry_credential_mock.go -source registry_credential.go -package mocks
+
+package job
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/pkg/encryption"
+)
+
+const (
+	// TokenExchangeProviderECR is the token exchange provider of AWS elastic container registry.
+	TokenExchangeProviderECR = "ecr"
+
+	// TokenExchangeProviderGCR is the token exchange provider of google container registry.
+	TokenExchangeProviderGCR = "gcr"
+)
+
+// RegistryAuth is the auth of registry resolved by the registry credential, the basic auth
+// is exchanged for the short-lived token of registry token service, and the static bearer
+// token is used as the token directly.
+type RegistryAuth struct {
+	// Username is the username of basic auth.
+	Username string
+
+	// Password is the password of basic auth.
+	Password string
+
+	// Token is the static bearer token.
+	Token string
+}
+
+// BasicAuth returns the value of authorization header of basic auth.
+func (a *RegistryAuth) BasicAuth() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(a.Username+":"+a.Password))
+}
+
+// RegistryCredentials is the interface used for resolving the auth of registry.
+type RegistryCredentials interface {
+	// Get returns the auth of registry host, nil is returned if the credential of host is not found.
+	Get(ctx context.Context, host string) (*RegistryAuth, error)
+}
+
+// TokenExchanger is the interface used for exchanging the credential of cloud provider for registry auth.
+type TokenExchanger interface {
+	// Exchange exchanges the username and secret of credential for the auth of registry host.
+	Exchange(ctx context.Context, host, username, secret string) (*RegistryAuth, error)
+}
+
+// tokenExchangers is the token exchangers keyed by provider.
+var tokenExchangers = map[string]TokenExchanger{
+	TokenExchangeProviderECR: &ecrTokenExchanger{},
+	TokenExchangeProviderGCR: &gcrTokenExchanger{},
+}
+
+// RegisterTokenExchanger registers the token exchanger of provider, it overrides the registered one.
+func RegisterTokenExchanger(provider string, exchanger TokenExchanger) {
+	tokenExchangers[provider] = exchanger
+}
+
+// registryCredentials resolves the auth of registry by the credentials stored in database.
+type registryCredentials struct {
+	db     *gorm.DB
+	cipher encryption.Cipher
+}
+
+// newRegistryCredentials returns a new RegistryCredentials, the secrets of credentials are decrypted by cipher.
+func newRegistryCredentials(db *gorm.DB, cipher encryption.Cipher) RegistryCredentials {
+	return &registryCredentials{
+		db:     db,
+		cipher: cipher,
+	}
+}
+
+// Get decrypts the credential of host and resolves the auth by credential type.
+func (r *registryCredentials) Get(ctx context.Context, host string) (*RegistryAuth, error) {
+	registryCredential := model.RegistryCredential{}
+	if err := r.db.WithContext(ctx).First(&registryCredential, &model.RegistryCredential{Host: host}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if r.cipher == nil {
+		return nil, errors.New("registry credential requires security encryptionKey")
+	}
+
+	secret, err := r.cipher.Decrypt(registryCredential.EncryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt registry credential of %s: %w", host, err)
+	}
+
+	switch registryCredential.Type {
+	case model.RegistryCredentialTypeBasic:
+		return &RegistryAuth{Username: registryCredential.Username, Password: string(secret)}, nil
+	case model.RegistryCredentialTypeBearer:
+		return &RegistryAuth{Token: string(secret)}, nil
+	case model.RegistryCredentialTypeTokenExchange:
+		exchanger, ok := tokenExchangers[registryCredential.Provider]
+		if !ok {
+			return nil, fmt.Errorf("unknown token exchange provider %s", registryCredential.Provider)
+		}
+
+		return exchanger.Exchange(ctx, host, registryCredential.Username, string(secret))
+	default:
+		return nil, fmt.Errorf("unknown registry credential type %s", registryCredential.Type)
+	}
+}
+
+// ecrTokenExchanger exchanges the access key of AWS for the authorization token of ECR.
+type ecrTokenExchanger struct{}
+
+// Exchange is not implemented yet, register the implementation by RegisterTokenExchanger.
+func (e *ecrTokenExchanger) Exchange(ctx context.Context, host, username, secret string) (*RegistryAuth, error) {
+	return nil, errors.New("ecr token exchange is not implemented")
+}
+
+// gcrTokenExchanger uses the json key of google service account as the password of GCR.
+type gcrTokenExchanger struct{}
+
+// Exchange returns the basic auth of json key, GCR exchanges it for access token by the token challenge.
+func (e *gcrTokenExchanger) Exchange(ctx context.Context, host, username, secret string) (*RegistryAuth, error) {
+	return &RegistryAuth{Username: "_json_key", Password: secret}, nil
+}
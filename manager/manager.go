@@ -20,12 +20,14 @@ import (
 	"context"
 	"crypto/tls"
 	"embed"
+	"encoding/base64"
 	"io/fs"
 	"net/http"
 	"path"
 	"time"
 
 	"github.com/gin-contrib/static"
+	"github.com/google/uuid"
 	"github.com/johanbrandhorst/certify"
 	"google.golang.org/grpc"
 	zapadapter "logur.dev/adapter/zap"
@@ -34,15 +36,19 @@ import (
 	"d7y.io/dragonfly/v2/manager/cache"
 	"d7y.io/dragonfly/v2/manager/config"
 	"d7y.io/dragonfly/v2/manager/database"
+	"d7y.io/dragonfly/v2/manager/election"
+	"d7y.io/dragonfly/v2/manager/event"
 	"d7y.io/dragonfly/v2/manager/job"
 	"d7y.io/dragonfly/v2/manager/metrics"
 	"d7y.io/dragonfly/v2/manager/permission/rbac"
 	"d7y.io/dragonfly/v2/manager/router"
 	"d7y.io/dragonfly/v2/manager/rpcserver"
+	"d7y.io/dragonfly/v2/manager/schedule"
 	"d7y.io/dragonfly/v2/manager/searcher"
 	"d7y.io/dragonfly/v2/manager/service"
 	pkgcache "d7y.io/dragonfly/v2/pkg/cache"
 	"d7y.io/dragonfly/v2/pkg/dfpath"
+	"d7y.io/dragonfly/v2/pkg/encryption"
 	"d7y.io/dragonfly/v2/pkg/issuer"
 	"d7y.io/dragonfly/v2/pkg/objectstorage"
 	"d7y.io/dragonfly/v2/pkg/rpc"
@@ -96,6 +102,12 @@ type Server struct {
 
 	// Metrics server
 	metricsServer *http.Server
+
+	// Schedule of recurring jobs
+	schedule schedule.Schedule
+
+	// Notifier of events
+	notifier event.Notifier
 }
 
 func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
@@ -122,8 +134,22 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 	// Initialize searcher
 	searcher := searcher.New(d.PluginDir())
 
+	// Initialize cipher of secrets in database
+	var cipher encryption.Cipher
+	if cfg.Security.EncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(cfg.Security.EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+
+		cipher, err = encryption.NewAESGCM(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize job
-	job, err := job.New(cfg)
+	job, err := job.New(cfg, db.DB, cipher)
 	if err != nil {
 		return nil, err
 	}
@@ -143,8 +169,32 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 		}
 	}
 
+	// Initialize notifier of events
+	var sinks []event.Sink
+	for _, webhook := range cfg.Event.Webhooks {
+		webhookOptions := []event.WebhookOption{
+			event.WithWebhookSecret(webhook.Secret),
+			event.WithWebhookEvents(webhook.Events),
+		}
+
+		if webhook.Timeout > 0 {
+			webhookOptions = append(webhookOptions, event.WithWebhookTimeout(webhook.Timeout))
+		}
+
+		if webhook.MaxAttempts > 0 {
+			webhookOptions = append(webhookOptions, event.WithWebhookMaxAttempts(webhook.MaxAttempts))
+		}
+
+		sinks = append(sinks, event.NewWebhook(webhook.URL, webhookOptions...))
+	}
+
+	if cfg.Event.Redis.Enable {
+		sinks = append(sinks, event.NewPublisherSink(event.NewRedisPublisher(db.RDB), cfg.Event.Redis.Prefix))
+	}
+	s.notifier = event.New(sinks...)
+
 	// Initialize REST server
-	restService := service.New(db, cache, job, enforcer, objectStorage)
+	restService := service.New(db, cache, job, enforcer, objectStorage, cipher, s.notifier)
 	router, err := router.Init(cfg, d.LogDir(), restService, enforcer, EmbedFolder(assets, assetsTargetPath))
 	if err != nil {
 		return nil, err
@@ -154,6 +204,9 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 		Handler: router,
 	}
 
+	// Initialize schedule of recurring jobs, it runs on the leader of manager replicas
+	s.schedule = schedule.New(db.DB, restService, election.NewRedisElector(db.RDB, schedule.LeaderKey, uuid.NewString(), schedule.LeaderTTL))
+
 	// Initialize roles and check roles
 	err = rbac.InitRBAC(enforcer, router, db.DB)
 	if err != nil {
@@ -161,7 +214,7 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 	}
 
 	// Initialize signing certificate and tls credentials of grpc server.
-	var options []rpcserver.Option
+	options := []rpcserver.Option{rpcserver.WithNotifier(s.notifier)}
 	if cfg.Security.AutoIssueCert {
 		cert, err := tls.X509KeyPair([]byte(cfg.Security.CACert), []byte(cfg.Security.CAKey))
 		if err != nil {
@@ -250,6 +303,18 @@ func (s *Server) Serve() error {
 		}()
 	}
 
+	// Started notifier of events
+	go func() {
+		logger.Info("started notifier")
+		s.notifier.Serve()
+	}()
+
+	// Started schedule of recurring jobs
+	go func() {
+		logger.Info("started schedule")
+		s.schedule.Serve()
+	}()
+
 	// Generate GRPC listener
 	lis, _, err := rpc.ListenWithPortRange(s.config.Server.GRPC.ListenIP, s.config.Server.GRPC.PortRange.Start, s.config.Server.GRPC.PortRange.End)
 	if err != nil {
@@ -284,6 +349,14 @@ func (s *Server) Stop() {
 		}
 	}
 
+	// Stop schedule of recurring jobs
+	s.schedule.Stop()
+	logger.Info("schedule closed under request")
+
+	// Stop notifier of events
+	s.notifier.Stop()
+	logger.Info("notifier closed under request")
+
 	// Stop GRPC server
 	stopped := make(chan struct{})
 	go func() {
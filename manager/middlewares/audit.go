@@ -0,0 +1,279 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/service"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+const (
+	// redactedValue is the value of sensitive field in audit request.
+	redactedValue = "******"
+
+	// maxAuditBodySize is the max size of request body recorded in audit.
+	maxAuditBodySize = 1 << 20
+)
+
+var (
+	// sensitiveFields is the keywords of sensitive fields redacted in audit request.
+	sensitiveFields = []string{"password", "secret", "token", "private_key", "access_key", "authorization", "cookie", "header"}
+
+	// unauditedPaths is the mutating routes of authentication, which are not audited.
+	unauditedPaths = map[string]struct{}{
+		"/api/v1/users/signin":        {},
+		"/api/v1/users/signout":       {},
+		"/api/v1/users/refresh_token": {},
+	}
+)
+
+// Audit records the mutating REST requests of api, it must be used before the Error
+// middleware to record the final status code.
+func Audit(service service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutation(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		resourceType, err := rbac.GetAPIGroupName(c.Request.URL.Path)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBodySize))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
+					Message: http.StatusText(http.StatusBadRequest),
+				})
+				return
+			}
+
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		}
+
+		// The fields of existing resource are loaded before the mutation, they are compared
+		// with the fields after the mutation to record the changes.
+		var before map[string]any
+		if id := c.Param("id"); id != "" && c.Request.Method != http.MethodPost {
+			if before, err = service.GetAuditResource(c.Request.Context(), resourceType, id); err != nil {
+				logger.Warnf("get audit resource %s %s failed: %s", resourceType, id, err)
+			}
+		}
+
+		// The id of created resource is only in the response body.
+		var writer *auditResponseWriter
+		if c.Request.Method == http.MethodPost {
+			writer = &auditResponseWriter{ResponseWriter: c.Writer}
+			c.Writer = writer
+		}
+
+		c.Next()
+
+		// Routes are matched after the request is handled.
+		if _, ok := unauditedPaths[c.FullPath()]; ok || c.FullPath() == "" {
+			return
+		}
+
+		audit := types.CreateAuditRequest{
+			ActorType:    model.AuditActorTypeUser,
+			Method:       c.Request.Method,
+			Path:         c.Request.URL.Path,
+			ResourceType: resourceType,
+			ResourceID:   c.Param("id"),
+			Request:      redactRequest(body),
+			StatusCode:   c.Writer.Status(),
+			State:        model.AuditStateSuccess,
+			IP:           c.ClientIP(),
+		}
+
+		if audit.StatusCode >= http.StatusBadRequest {
+			audit.State = model.AuditStateFailure
+		}
+
+		if audit.ResourceID == "" && audit.State == model.AuditStateSuccess && writer != nil {
+			audit.ResourceID = responseResourceID(writer.body.Bytes())
+		}
+
+		if err := c.Errors.Last(); err != nil {
+			audit.Error = err.Error()
+		}
+
+		// The request context is done when the response is written,
+		// so audit is created with the background context.
+		ctx := context.Background()
+		if before != nil && audit.State == model.AuditStateSuccess {
+			var (
+				after map[string]any
+				err   error
+			)
+			if audit.Method != http.MethodDelete {
+				after, err = service.GetAuditResource(ctx, resourceType, audit.ResourceID)
+			}
+
+			if err != nil {
+				logger.Warnf("get audit resource %s %s failed: %s", resourceType, audit.ResourceID, err)
+			} else {
+				audit.Before, audit.After = diffResource(before, after)
+			}
+		}
+
+		if id, ok := c.Get("id"); ok {
+			if id, ok := id.(float64); ok {
+				audit.UserID = uint(id)
+				if user, err := service.GetUser(ctx, audit.UserID); err == nil {
+					audit.Actor = user.Name
+				}
+			}
+		}
+
+		if _, err := service.CreateAudit(ctx, audit); err != nil {
+			logger.Errorf("create audit of %s %s failed: %s", audit.Method, audit.Path, err)
+		}
+	}
+}
+
+// auditResponseWriter records the response body up to maxAuditBodySize.
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes the data to the response and records it.
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	if n := maxAuditBodySize - w.body.Len(); n > 0 {
+		if len(data) < n {
+			n = len(data)
+		}
+		w.body.Write(data[:n])
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes the string to the response and records it.
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// responseResourceID returns the id field of the json response body,
+// it returns empty string when the id is not found.
+func responseResourceID(body []byte) string {
+	var response struct {
+		ID json.Number `json:"id"`
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		return ""
+	}
+
+	return response.ID.String()
+}
+
+// diffResource returns the redacted fields of resource changed by the mutation,
+// all fields of before are returned if the resource is deleted.
+func diffResource(before, after map[string]any) (map[string]any, map[string]any) {
+	if after == nil {
+		return redact(before), nil
+	}
+
+	changedBefore, changedAfter := map[string]any{}, map[string]any{}
+	for key, value := range after {
+		if reflect.DeepEqual(before[key], value) {
+			continue
+		}
+
+		changedBefore[key] = before[key]
+		changedAfter[key] = value
+	}
+
+	return redact(changedBefore), redact(changedAfter)
+}
+
+// isMutation reports whether the http method mutates resources.
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// redactRequest decodes the json request body and redacts the sensitive fields,
+// it returns nil when the body is not a json object.
+func redactRequest(body []byte) map[string]any {
+	request := map[string]any{}
+	if err := json.Unmarshal(body, &request); err != nil || len(request) == 0 {
+		return nil
+	}
+
+	return redact(request)
+}
+
+// redact replaces the values of sensitive fields in v recursively.
+func redact(v map[string]any) map[string]any {
+	for key, value := range v {
+		if isSensitiveField(key) {
+			v[key] = redactedValue
+			continue
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			v[key] = redact(value)
+		case []any:
+			for i, item := range value {
+				if item, ok := item.(map[string]any); ok {
+					value[i] = redact(item)
+				}
+			}
+		}
+	}
+
+	return v
+}
+
+// isSensitiveField reports whether the field of key is sensitive.
+func isSensitiveField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range sensitiveFields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,257 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middlewares
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/service/mocks"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		response string
+		mock     func(m *mocks.MockServiceMockRecorder)
+	}{
+		{
+			name:   "mutation of user",
+			method: http.MethodPatch,
+			path:   "/api/v1/oauth/1",
+			body:   `{"name":"foo","client_secret":"bar"}`,
+			status: http.StatusOK,
+			mock: func(m *mocks.MockServiceMockRecorder) {
+				gomock.InOrder(
+					m.GetAuditResource(gomock.Any(), "oauth", "1").Return(map[string]any{"id": 1, "name": "bar", "client_secret": "baz", "bio": "foo"}, nil).Times(1),
+					m.GetAuditResource(gomock.Any(), "oauth", "1").Return(map[string]any{"id": 1, "name": "foo", "client_secret": "bar", "bio": "foo"}, nil).Times(1),
+					m.GetUser(gomock.Any(), uint(2)).Return(&model.User{Name: "baz"}, nil).Times(1),
+					m.CreateAudit(gomock.Any(), types.CreateAuditRequest{
+						ActorType:    model.AuditActorTypeUser,
+						Actor:        "baz",
+						UserID:       2,
+						Method:       http.MethodPatch,
+						Path:         "/api/v1/oauth/1",
+						ResourceType: "oauth",
+						ResourceID:   "1",
+						Request:      map[string]any{"name": "foo", "client_secret": redactedValue},
+						Before:       map[string]any{"name": "bar", "client_secret": redactedValue},
+						After:        map[string]any{"name": "foo", "client_secret": redactedValue},
+						StatusCode:   http.StatusOK,
+						State:        model.AuditStateSuccess,
+						IP:           "192.0.2.1",
+					}).Return(&model.Audit{}, nil).Times(1),
+				)
+			},
+		},
+		{
+			name:     "creation records id of created resource",
+			method:   http.MethodPost,
+			path:     "/api/v1/oauth",
+			body:     `{"name":"foo","headers":{"Authorization":"bar"}}`,
+			status:   http.StatusOK,
+			response: `{"id":1000000,"name":"foo"}`,
+			mock: func(m *mocks.MockServiceMockRecorder) {
+				gomock.InOrder(
+					m.GetUser(gomock.Any(), uint(2)).Return(&model.User{Name: "baz"}, nil).Times(1),
+					m.CreateAudit(gomock.Any(), types.CreateAuditRequest{
+						ActorType:    model.AuditActorTypeUser,
+						Actor:        "baz",
+						UserID:       2,
+						Method:       http.MethodPost,
+						Path:         "/api/v1/oauth",
+						ResourceType: "oauth",
+						ResourceID:   "1000000",
+						Request:      map[string]any{"name": "foo", "headers": redactedValue},
+						StatusCode:   http.StatusOK,
+						State:        model.AuditStateSuccess,
+						IP:           "192.0.2.1",
+					}).Return(&model.Audit{}, nil).Times(1),
+				)
+			},
+		},
+		{
+			name:   "deletion records fields of deleted resource",
+			method: http.MethodDelete,
+			path:   "/api/v1/oauth/1",
+			status: http.StatusOK,
+			mock: func(m *mocks.MockServiceMockRecorder) {
+				gomock.InOrder(
+					m.GetAuditResource(gomock.Any(), "oauth", "1").Return(map[string]any{"id": 1, "name": "foo", "client_secret": "bar"}, nil).Times(1),
+					m.GetUser(gomock.Any(), uint(2)).Return(&model.User{Name: "baz"}, nil).Times(1),
+					m.CreateAudit(gomock.Any(), types.CreateAuditRequest{
+						ActorType:    model.AuditActorTypeUser,
+						Actor:        "baz",
+						UserID:       2,
+						Method:       http.MethodDelete,
+						Path:         "/api/v1/oauth/1",
+						ResourceType: "oauth",
+						ResourceID:   "1",
+						Before:       map[string]any{"id": 1, "name": "foo", "client_secret": redactedValue},
+						StatusCode:   http.StatusOK,
+						State:        model.AuditStateSuccess,
+						IP:           "192.0.2.1",
+					}).Return(&model.Audit{}, nil).Times(1),
+				)
+			},
+		},
+		{
+			name:   "get resource after mutation failed",
+			method: http.MethodPatch,
+			path:   "/api/v1/oauth/1",
+			body:   `{"name":"foo"}`,
+			status: http.StatusOK,
+			mock: func(m *mocks.MockServiceMockRecorder) {
+				gomock.InOrder(
+					m.GetAuditResource(gomock.Any(), "oauth", "1").Return(map[string]any{"id": 1, "name": "bar"}, nil).Times(1),
+					m.GetAuditResource(gomock.Any(), "oauth", "1").Return(nil, errors.New("foo")).Times(1),
+					m.GetUser(gomock.Any(), uint(2)).Return(&model.User{Name: "baz"}, nil).Times(1),
+					m.CreateAudit(gomock.Any(), types.CreateAuditRequest{
+						ActorType:    model.AuditActorTypeUser,
+						Actor:        "baz",
+						UserID:       2,
+						Method:       http.MethodPatch,
+						Path:         "/api/v1/oauth/1",
+						ResourceType: "oauth",
+						ResourceID:   "1",
+						Request:      map[string]any{"name": "foo"},
+						StatusCode:   http.StatusOK,
+						State:        model.AuditStateSuccess,
+						IP:           "192.0.2.1",
+					}).Return(&model.Audit{}, nil).Times(1),
+				)
+			},
+		},
+		{
+			name:   "failed mutation",
+			method: http.MethodPatch,
+			path:   "/api/v1/oauth/1",
+			body:   "foo",
+			status: http.StatusNotFound,
+			mock: func(m *mocks.MockServiceMockRecorder) {
+				gomock.InOrder(
+					m.GetAuditResource(gomock.Any(), "oauth", "1").Return(map[string]any{"id": 1, "name": "bar"}, nil).Times(1),
+					m.GetUser(gomock.Any(), uint(2)).Return(&model.User{Name: "baz"}, nil).Times(1),
+					m.CreateAudit(gomock.Any(), types.CreateAuditRequest{
+						ActorType:    model.AuditActorTypeUser,
+						Actor:        "baz",
+						UserID:       2,
+						Method:       http.MethodPatch,
+						Path:         "/api/v1/oauth/1",
+						ResourceType: "oauth",
+						ResourceID:   "1",
+						StatusCode:   http.StatusNotFound,
+						State:        model.AuditStateFailure,
+						IP:           "192.0.2.1",
+					}).Return(&model.Audit{}, nil).Times(1),
+				)
+			},
+		},
+		{
+			name:   "read request is not audited",
+			method: http.MethodGet,
+			path:   "/api/v1/oauth/1",
+			status: http.StatusOK,
+			mock:   func(m *mocks.MockServiceMockRecorder) {},
+		},
+		{
+			name:   "signin is not audited",
+			method: http.MethodPost,
+			path:   "/api/v1/users/signin",
+			body:   `{"name":"foo","password":"bar"}`,
+			status: http.StatusOK,
+			mock:   func(m *mocks.MockServiceMockRecorder) {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			tc.mock(svc.EXPECT())
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(Audit(svc))
+			handler := func(c *gin.Context) {
+				c.Set("id", float64(2))
+				body, err := io.ReadAll(c.Request.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.body, string(body))
+				if tc.response != "" {
+					c.Data(tc.status, "application/json", []byte(tc.response))
+					return
+				}
+				c.Status(tc.status)
+			}
+			r.Handle(tc.method, "/api/v1/oauth/:id", handler)
+			r.POST("/api/v1/oauth", handler)
+			r.POST("/api/v1/users/signin", handler)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.RemoteAddr = "192.0.2.1:8080"
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.response, w.Body.String())
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(map[string]any{
+		"name":     "foo",
+		"password": redactedValue,
+		"config": map[string]any{
+			"secret_key": redactedValue,
+			"endpoint":   "bar",
+		},
+		"rules": []any{
+			map[string]any{"Token": redactedValue},
+			"baz",
+		},
+		"Cookie": redactedValue,
+	}, redact(map[string]any{
+		"name":     "foo",
+		"password": "foo",
+		"config": map[string]any{
+			"secret_key": "foo",
+			"endpoint":   "bar",
+		},
+		"rules": []any{
+			map[string]any{"Token": "foo"},
+			"baz",
+		},
+		"Cookie": "foo",
+	}))
+}
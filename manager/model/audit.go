@@ -0,0 +1,54 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+const (
+	// AuditActorTypeUser is the mutation made by user with REST API.
+	AuditActorTypeUser = "user"
+
+	// AuditActorTypeScheduler is the mutation made by scheduler with GRPC.
+	AuditActorTypeScheduler = "scheduler"
+
+	// AuditActorTypeSeedPeer is the mutation made by seed peer with GRPC.
+	AuditActorTypeSeedPeer = "seed_peer"
+)
+
+const (
+	// AuditStateSuccess is the mutation is succeeded.
+	AuditStateSuccess = "SUCCESS"
+
+	// AuditStateFailure is the mutation is failed.
+	AuditStateFailure = "FAILURE"
+)
+
+type Audit struct {
+	Model
+	ActorType    string  `gorm:"column:actor_type;type:varchar(256);index:idx_audit_actor;not null;comment:actor type" json:"actor_type"`
+	Actor        string  `gorm:"column:actor;type:varchar(256);index:idx_audit_actor;comment:user name or hostname of actor" json:"actor"`
+	UserID       uint    `gorm:"column:user_id;index:idx_audit_user_id;comment:user id" json:"user_id"`
+	Method       string  `gorm:"column:method;type:varchar(256);not null;comment:http method or grpc method" json:"method"`
+	Path         string  `gorm:"column:path;type:varchar(1024);comment:request path" json:"path"`
+	ResourceType string  `gorm:"column:resource_type;type:varchar(256);index:idx_audit_resource;comment:resource type" json:"resource_type"`
+	ResourceID   string  `gorm:"column:resource_id;type:varchar(256);index:idx_audit_resource;comment:resource id" json:"resource_id"`
+	Request      JSONMap `gorm:"column:request;comment:redacted request fields" json:"request"`
+	Before       JSONMap `gorm:"column:before;comment:redacted resource fields before mutation" json:"before"`
+	After        JSONMap `gorm:"column:after;comment:redacted resource fields after mutation" json:"after"`
+	StatusCode   int     `gorm:"column:status_code;comment:http status code or grpc code" json:"status_code"`
+	State        string  `gorm:"column:state;type:varchar(256);index:idx_audit_state;not null;comment:mutation state" json:"state"`
+	Error        string  `gorm:"column:error;type:varchar(1024);comment:error message" json:"error"`
+	IP           string  `gorm:"column:ip;type:varchar(256);comment:client ip" json:"ip"`
+}
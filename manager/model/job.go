@@ -16,6 +16,16 @@
 
 package model
 
+import "time"
+
+const (
+	// JobStateScheduled is the state of recurring job, the runs of job are created by schedule.
+	JobStateScheduled = "SCHEDULED"
+
+	// JobStateCanceled is the state of job canceled by user.
+	JobStateCanceled = "CANCELED"
+)
+
 type Job struct {
 	Model
 	TaskID            string             `gorm:"column:task_id;type:varchar(256);not null;comment:task id" json:"task_id"`
@@ -24,6 +34,9 @@ type Job struct {
 	State             string             `gorm:"column:state;type:varchar(256);not null;default:'PENDING';comment:service state" json:"state"`
 	Args              JSONMap            `gorm:"column:args;not null;comment:task request args" json:"args"`
 	Result            JSONMap            `gorm:"column:result;comment:task result" json:"result"`
+	Schedule          string             `gorm:"column:schedule;type:varchar(256);comment:cron schedule of recurring job" json:"schedule"`
+	NextRunAt         *time.Time         `gorm:"column:next_run_at;comment:next run time of recurring job" json:"next_run_at"`
+	ParentID          uint               `gorm:"column:parent_id;index:idx_job_parent_id;comment:recurring job id of run" json:"parent_id"`
 	UserID            uint               `gorm:"column:user_id;comment:user id" json:"user_id"`
 	User              User               `json:"-"`
 	SeedPeerClusters  []SeedPeerCluster  `gorm:"many2many:job_seed_peer_cluster;" json:"seed_peer_clusters"`
@@ -0,0 +1,42 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+const (
+	// RegistryCredentialTypeBasic is the credential of username and password.
+	RegistryCredentialTypeBasic = "basic"
+
+	// RegistryCredentialTypeBearer is the credential of static bearer token, it is passed to
+	// the registry and seed peers as the authorization header directly.
+	RegistryCredentialTypeBearer = "bearer"
+
+	// RegistryCredentialTypeTokenExchange is the credential exchanged for registry auth
+	// by the cloud provider, like ECR and GCR.
+	RegistryCredentialTypeTokenExchange = "token-exchange"
+)
+
+type RegistryCredential struct {
+	Model
+	Host            string `gorm:"column:host;type:varchar(256);index:uk_registry_credential_host,unique;not null;comment:registry host" json:"host"`
+	Type            string `gorm:"column:type;type:varchar(256);not null;comment:credential type" json:"type"`
+	Provider        string `gorm:"column:provider;type:varchar(256);comment:token exchange provider" json:"provider"`
+	Username        string `gorm:"column:username;type:varchar(256);comment:username" json:"username"`
+	EncryptedSecret []byte `gorm:"column:encrypted_secret;comment:encrypted secret" json:"-"`
+	BIO             string `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	UserID          uint   `gorm:"comment:user id" json:"user_id"`
+	User            User   `json:"user"`
+}
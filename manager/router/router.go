@@ -74,6 +74,7 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	r.Use(gin.Recovery())
 	r.Use(ginzap.Ginzap(logger.GinLogger.Desugar(), time.RFC3339, true))
 	r.Use(ginzap.RecoveryWithZap(logger.GinLogger.Desugar(), true))
+	r.Use(middlewares.Audit(service))
 	r.Use(middlewares.Error())
 	r.Use(cors.New(corsConfig))
 
@@ -165,6 +166,19 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	cs.GET(":id", h.GetApplication)
 	cs.GET("", h.GetApplications)
 
+	// Registry Credential
+	rc := apiv1.Group("/registry-credentials", jwt.MiddlewareFunc(), rbac)
+	rc.POST("", h.CreateRegistryCredential)
+	rc.DELETE(":id", h.DestroyRegistryCredential)
+	rc.PATCH(":id", h.UpdateRegistryCredential)
+	rc.GET(":id", h.GetRegistryCredential)
+	rc.GET("", h.GetRegistryCredentials)
+
+	// Audit
+	au := apiv1.Group("/audits", jwt.MiddlewareFunc(), rbac)
+	au.GET(":id", h.GetAudit)
+	au.GET("", h.GetAudits)
+
 	// Seed Peer Cluster
 	spc := apiv1.Group("/seed-peer-clusters", jwt.MiddlewareFunc(), rbac)
 	spc.POST("", h.CreateSeedPeerCluster)
@@ -223,6 +237,7 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	job.POST("", h.CreateJob)
 	job.DELETE(":id", h.DestroyJob)
 	job.PATCH(":id", h.UpdateJob)
+	job.POST(":id/cancel", h.CancelJob)
 	job.GET(":id", h.GetJob)
 	job.GET("", h.GetJobs)
 
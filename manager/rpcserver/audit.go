@@ -0,0 +1,246 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/model"
+)
+
+// auditedMethod is the grpc method audited by manager.
+type auditedMethod struct {
+	actorType    string
+	resourceType string
+}
+
+// auditedMethods is the mutating grpc methods audited by manager, keyed by full method.
+var auditedMethods = map[string]auditedMethod{
+	"/manager.Manager/UpdateScheduler": {
+		actorType:    model.AuditActorTypeScheduler,
+		resourceType: "schedulers",
+	},
+	"/manager.Manager/UpdateSeedPeer": {
+		actorType:    model.AuditActorTypeSeedPeer,
+		resourceType: "seed-peers",
+	},
+	"/manager.Manager/CreateModelVersion": {
+		actorType:    model.AuditActorTypeScheduler,
+		resourceType: "schedulers",
+	},
+}
+
+// unauditedFields is the request fields which are not recorded in audit, like the binary of model.
+var unauditedFields = []string{"data"}
+
+// auditUnaryServerInterceptor records the audited grpc methods in audit log.
+func (s *Server) auditUnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	method, ok := auditedMethods[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	// The fields of existing resource are loaded before the mutation, they are compared
+	// with the fields after the mutation to record the changes.
+	before, beforeErr := s.auditResource(ctx, req, nil)
+	if beforeErr != nil {
+		logger.Warnf("get audit resource of %s failed: %s", info.FullMethod, beforeErr)
+	}
+
+	resp, err := handler(ctx, req)
+
+	// The request context may be done when the handler returns,
+	// so audit is created with the background context.
+	auditCtx := context.Background()
+	audit := model.Audit{
+		ActorType:    method.actorType,
+		Actor:        s.auditActor(auditCtx, req),
+		Method:       info.FullMethod,
+		ResourceType: method.resourceType,
+		ResourceID:   auditResourceID(req, resp),
+		Request:      auditMessage(req),
+		StatusCode:   int(status.Code(err)),
+		State:        model.AuditStateSuccess,
+	}
+
+	if err != nil {
+		audit.State = model.AuditStateFailure
+		audit.Error = err.Error()
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			audit.IP = host
+		}
+	}
+
+	if err == nil && beforeErr == nil {
+		after, err := s.auditResource(auditCtx, req, resp)
+		if err != nil {
+			logger.Warnf("get audit resource of %s failed: %s", info.FullMethod, err)
+		} else {
+			audit.Before, audit.After = diffAuditResource(before, after)
+		}
+	}
+
+	if err := s.db.WithContext(auditCtx).Create(&audit).Error; err != nil {
+		logger.Errorf("create audit of %s failed: %s", info.FullMethod, err)
+	}
+
+	return resp, err
+}
+
+// auditActor returns the hostname of the host calling the method.
+func (s *Server) auditActor(ctx context.Context, req any) string {
+	switch req := req.(type) {
+	case interface{ GetHostName() string }:
+		return req.GetHostName()
+	case interface{ GetSchedulerId() uint64 }:
+		scheduler := model.Scheduler{}
+		if err := s.db.WithContext(ctx).Select("host_name").First(&scheduler, req.GetSchedulerId()).Error; err != nil {
+			return ""
+		}
+
+		return scheduler.HostName
+	default:
+		return ""
+	}
+}
+
+// auditResourceID returns the id of resource mutated by the method, the model version
+// belongs to the scheduler resource like the REST api.
+func auditResourceID(req, resp any) string {
+	if resp, ok := resp.(interface{ GetId() uint64 }); ok && resp.GetId() > 0 {
+		return fmt.Sprint(resp.GetId())
+	}
+
+	if req, ok := req.(interface{ GetSchedulerId() uint64 }); ok {
+		return fmt.Sprint(req.GetSchedulerId())
+	}
+
+	return ""
+}
+
+// auditResource returns the fields of resource mutated by the method, nil is returned
+// if the resource does not exist. The model version is stored in redis instead of
+// database and it exists only after it is created, so its fields are from the response.
+func (s *Server) auditResource(ctx context.Context, req, resp any) (map[string]any, error) {
+	switch req := req.(type) {
+	case *managerv1.UpdateSchedulerRequest:
+		return s.getAuditResource(ctx, &model.Scheduler{}, &model.Scheduler{
+			HostName:           req.HostName,
+			SchedulerClusterID: uint(req.SchedulerClusterId),
+		})
+	case *managerv1.UpdateSeedPeerRequest:
+		return s.getAuditResource(ctx, &model.SeedPeer{}, &model.SeedPeer{
+			HostName:          req.HostName,
+			SeedPeerClusterID: uint(req.SeedPeerClusterId),
+		})
+	case *managerv1.CreateModelVersionRequest:
+		if resp == nil {
+			return nil, nil
+		}
+
+		return auditMessage(resp), nil
+	default:
+		return nil, nil
+	}
+}
+
+// getAuditResource returns the fields of resource model matched by the conditions.
+func (s *Server) getAuditResource(ctx context.Context, m any, conds any) (map[string]any, error) {
+	resource := map[string]any{}
+	if err := s.db.WithContext(ctx).Model(m).Where(conds).Take(&resource).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	// The json and text columns are scanned as bytes.
+	for key, value := range resource {
+		if value, ok := value.([]byte); ok {
+			resource[key] = string(value)
+		}
+	}
+
+	return resource, nil
+}
+
+// diffAuditResource returns the fields of resource changed by the method,
+// all fields of after are returned if the resource is created.
+func diffAuditResource(before, after map[string]any) (model.JSONMap, model.JSONMap) {
+	if after == nil {
+		return nil, nil
+	}
+
+	if before == nil {
+		return nil, after
+	}
+
+	changedBefore, changedAfter := model.JSONMap{}, model.JSONMap{}
+	for key, value := range after {
+		if reflect.DeepEqual(before[key], value) {
+			continue
+		}
+
+		changedBefore[key] = before[key]
+		changedAfter[key] = value
+	}
+
+	return changedBefore, changedAfter
+}
+
+// auditMessage converts the grpc message to the fields recorded in audit.
+func auditMessage(m any) model.JSONMap {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+
+	request := model.JSONMap{}
+	if err := json.Unmarshal(b, &request); err != nil {
+		return nil
+	}
+
+	for _, field := range unauditedFields {
+		delete(request, field)
+	}
+
+	return request
+}
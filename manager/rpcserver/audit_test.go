@@ -0,0 +1,154 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, mock
+}
+
+// expectInsertAudit expects the audit is created with the before and after fields.
+func expectInsertAudit(mdb sqlmock.Sqlmock, state string, before, after any) {
+	mdb.ExpectBegin()
+	mdb.ExpectExec(regexp.QuoteMeta("INSERT INTO `audit`")).WithArgs(
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), before,
+		after, sqlmock.AnyArg(), state, sqlmock.AnyArg(), sqlmock.AnyArg(),
+	).WillReturnResult(sqlmock.NewResult(1, 1))
+	mdb.ExpectCommit()
+}
+
+func TestServer_auditUnaryServerInterceptor(t *testing.T) {
+	schedulerColumns := []string{"id", "host_name", "idc", "scheduler_cluster_id"}
+
+	tests := []struct {
+		name    string
+		method  string
+		req     any
+		handler grpc.UnaryHandler
+		mock    func(mdb sqlmock.Sqlmock)
+	}{
+		{
+			name:   "update scheduler",
+			method: "/manager.Manager/UpdateScheduler",
+			req:    &managerv1.UpdateSchedulerRequest{HostName: "foo", SchedulerClusterId: 1, Idc: "baz"},
+			handler: func(ctx context.Context, req any) (any, error) {
+				return &managerv1.Scheduler{Id: 1, HostName: "foo", Idc: "baz"}, nil
+			},
+			mock: func(mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `scheduler`")).WillReturnRows(
+					sqlmock.NewRows(schedulerColumns).AddRow(1, "foo", "bar", 1))
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `scheduler`")).WillReturnRows(
+					sqlmock.NewRows(schedulerColumns).AddRow(1, "foo", "baz", 1))
+				expectInsertAudit(mdb, "SUCCESS", `{"idc":"bar"}`, `{"idc":"baz"}`)
+			},
+		},
+		{
+			name:   "create scheduler",
+			method: "/manager.Manager/UpdateScheduler",
+			req:    &managerv1.UpdateSchedulerRequest{HostName: "foo", SchedulerClusterId: 1, Idc: "baz"},
+			handler: func(ctx context.Context, req any) (any, error) {
+				return &managerv1.Scheduler{Id: 1, HostName: "foo", Idc: "baz"}, nil
+			},
+			mock: func(mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `scheduler`")).WillReturnRows(
+					sqlmock.NewRows(schedulerColumns))
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `scheduler`")).WillReturnRows(
+					sqlmock.NewRows(schedulerColumns).AddRow(1, "foo", "baz", 1))
+				expectInsertAudit(mdb, "SUCCESS", nil, `{"host_name":"foo","id":1,"idc":"baz","scheduler_cluster_id":1}`)
+			},
+		},
+		{
+			name:   "update seed peer failed",
+			method: "/manager.Manager/UpdateSeedPeer",
+			req:    &managerv1.UpdateSeedPeerRequest{HostName: "foo", SeedPeerClusterId: 1, Idc: "baz"},
+			handler: func(ctx context.Context, req any) (any, error) {
+				return nil, errors.New("foo")
+			},
+			mock: func(mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `seed_peer`")).WillReturnRows(
+					sqlmock.NewRows([]string{"id", "host_name", "idc", "seed_peer_cluster_id"}).AddRow(1, "foo", "bar", 1))
+				expectInsertAudit(mdb, "FAILURE", nil, nil)
+			},
+		},
+		{
+			name:   "create model version",
+			method: "/manager.Manager/CreateModelVersion",
+			req:    &managerv1.CreateModelVersionRequest{SchedulerId: 1, ModelId: "foo", Data: []byte("bar"), Mae: 1},
+			handler: func(ctx context.Context, req any) (any, error) {
+				return &managerv1.ModelVersion{VersionId: "baz", Data: []byte("bar"), Mae: 1}, nil
+			},
+			mock: func(mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT `host_name` FROM `scheduler`")).WillReturnRows(
+					sqlmock.NewRows([]string{"host_name"}).AddRow("foo"))
+				expectInsertAudit(mdb, "SUCCESS", nil, `{"mae":1,"version_id":"baz"}`)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mdb := newMockDB(t)
+			tc.mock(mdb)
+
+			s := &Server{db: db}
+			ctx, cancel := context.WithCancel(context.Background())
+			_, err := s.auditUnaryServerInterceptor(ctx, tc.req, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(ctx context.Context, req any) (any, error) {
+				// The request context is done when the handler returns.
+				defer cancel()
+				return tc.handler(ctx, req)
+			})
+
+			assert := assert.New(t)
+			if _, ok := tc.req.(*managerv1.UpdateSeedPeerRequest); ok {
+				assert.EqualError(err, "foo")
+			} else {
+				assert.NoError(err)
+			}
+			assert.NoError(mdb.ExpectationsWereMet())
+		})
+	}
+}
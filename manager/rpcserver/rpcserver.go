@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	cachev8 "github.com/go-redis/cache/v8"
@@ -42,6 +43,7 @@ import (
 	"d7y.io/dragonfly/v2/manager/cache"
 	"d7y.io/dragonfly/v2/manager/config"
 	"d7y.io/dragonfly/v2/manager/database"
+	"d7y.io/dragonfly/v2/manager/event"
 	"d7y.io/dragonfly/v2/manager/metrics"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/searcher"
@@ -102,6 +104,9 @@ type Server struct {
 
 	// selfSignedCert is self signed certificate.
 	selfSignedCert *SelfSignedCert
+
+	// notifier emits the events of manager.
+	notifier event.Notifier
 }
 
 // Option is a functional option for rpc server.
@@ -133,6 +138,14 @@ func WithGRPCServerOptions(opts []grpc.ServerOption) Option {
 	}
 }
 
+// WithNotifier set the notifier of emitting events, like scheduler inactive.
+func WithNotifier(notifier event.Notifier) Option {
+	return func(s *Server) error {
+		s.notifier = notifier
+		return nil
+	}
+}
+
 // New returns a new manager server from the given options.
 func New(
 	cfg *config.Config, database *database.Database, cache *cache.Cache, searcher searcher.Searcher,
@@ -147,6 +160,7 @@ func New(
 		searcher:            searcher,
 		objectStorage:       objectStorage,
 		objectStorageConfig: objectStorageConfig,
+		notifier:            event.New(),
 	}
 
 	// Peer cache is evicted, and the metrics of the peer should be released.
@@ -162,6 +176,8 @@ func New(
 		}
 	}
 
+	// Record the mutations of schedulers and seed peers in audit log.
+	s.serverOptions = append(s.serverOptions, grpc.ChainUnaryInterceptor(s.auditUnaryServerInterceptor))
 	return s, managerserver.New(s, s, s.serverOptions...), nil
 }
 
@@ -515,6 +531,19 @@ func (s *Server) ListSchedulers(ctx context.Context, req *managerv1.ListSchedule
 	log := logger.WithHostnameAndIP(req.HostName, req.Ip)
 	log.Debugf("list schedulers, version %s, commit %s", req.Version, req.Commit)
 
+	// Scheduler lists the schedulers of its scheduler cluster, the searcher is skipped
+	// because the searcher may match the other scheduler clusters.
+	if req.SourceType == managerv1.SourceType_SCHEDULER_SOURCE {
+		if value, ok := req.HostInfo[types.ListSchedulersSchedulerClusterIDKey]; ok {
+			schedulerClusterID, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+
+			return s.listSchedulersBySchedulerClusterID(ctx, uint(schedulerClusterID))
+		}
+	}
+
 	// Count the number of the active peer.
 	if s.config.Metrics.EnablePeerGauge && req.SourceType == managerv1.SourceType_PEER_SOURCE {
 		peerCacheKey := fmt.Sprintf("%s-%s", req.HostName, req.Ip)
@@ -610,6 +639,35 @@ func (s *Server) ListSchedulers(ctx context.Context, req *managerv1.ListSchedule
 	return &pbListSchedulersResponse, nil
 }
 
+// List active schedulers of the scheduler cluster.
+func (s *Server) listSchedulersBySchedulerClusterID(ctx context.Context, schedulerClusterID uint) (*managerv1.ListSchedulersResponse, error) {
+	schedulerCluster := model.SchedulerCluster{}
+	if err := s.db.WithContext(ctx).Preload("Schedulers", "state = ?", "active").First(&schedulerCluster, schedulerClusterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Error(codes.NotFound, "scheduler cluster not found")
+		}
+
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+
+	var pbListSchedulersResponse managerv1.ListSchedulersResponse
+	for _, scheduler := range schedulerCluster.Schedulers {
+		pbListSchedulersResponse.Schedulers = append(pbListSchedulersResponse.Schedulers, &managerv1.Scheduler{
+			Id:                 uint64(scheduler.ID),
+			HostName:           scheduler.HostName,
+			Idc:                scheduler.IDC,
+			NetTopology:        scheduler.NetTopology,
+			Location:           scheduler.Location,
+			Ip:                 scheduler.IP,
+			Port:               scheduler.Port,
+			State:              scheduler.State,
+			SchedulerClusterId: uint64(scheduler.SchedulerClusterID),
+		})
+	}
+
+	return &pbListSchedulersResponse, nil
+}
+
 // Get object storage configuration.
 func (s *Server) GetObjectStorage(ctx context.Context, req *managerv1.GetObjectStorageRequest) (*managerv1.ObjectStorage, error) {
 	if !s.objectStorageConfig.Enable {
@@ -773,6 +831,7 @@ func (s *Server) UpdateModel(ctx context.Context, req *managerv1.UpdateModelRequ
 		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
+	previousVersionID := model.VersionId
 	model.VersionId = req.VersionId
 	model.UpdatedAt = timestamppb.New(time.Now())
 
@@ -789,6 +848,15 @@ func (s *Server) UpdateModel(ctx context.Context, req *managerv1.UpdateModelRequ
 		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
+	if model.VersionId != previousVersionID {
+		s.notifier.Notify(event.TypeModelVersionActivated, &event.ModelVersionActivated{
+			SchedulerID:       scheduler.ID,
+			ModelID:           model.ModelId,
+			VersionID:         model.VersionId,
+			PreviousVersionID: previousVersionID,
+		})
+	}
+
 	return model, nil
 }
 
@@ -1114,6 +1182,13 @@ func (s *Server) KeepAlive(stream managerv1.Manager_KeepAliveServer) error {
 					return status.Error(codes.Unknown, err.Error())
 				}
 
+				s.notifier.Notify(event.TypeSchedulerInactive, &event.HostInactive{
+					ID:        scheduler.ID,
+					HostName:  hostName,
+					IP:        ip,
+					ClusterID: clusterID,
+				})
+
 				if err := s.cache.Delete(
 					context.TODO(),
 					cache.MakeSchedulerCacheKey(clusterID, hostName, ip),
@@ -1134,6 +1209,13 @@ func (s *Server) KeepAlive(stream managerv1.Manager_KeepAliveServer) error {
 					return status.Error(codes.Unknown, err.Error())
 				}
 
+				s.notifier.Notify(event.TypeSeedPeerInactive, &event.HostInactive{
+					ID:        seedPeer.ID,
+					HostName:  hostName,
+					IP:        ip,
+					ClusterID: clusterID,
+				})
+
 				if err := s.cache.Delete(
 					context.TODO(),
 					cache.MakeSeedPeerCacheKey(clusterID, hostName, ip),
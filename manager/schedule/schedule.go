@@ -0,0 +1,195 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/schedule_mock.go -source schedule.go -package mocks
+
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/cache"
+	"d7y.io/dragonfly/v2/manager/election"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/service"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/structure"
+)
+
+const (
+	// defaultInterval is the default interval of checking the recurring jobs to run.
+	defaultInterval = 10 * time.Second
+
+	// LeaderTTL is the ttl of leadership to run the recurring jobs,
+	// the leadership is taken over by other manager replica if the leader is down.
+	LeaderTTL = 3 * defaultInterval
+)
+
+// LeaderKey is the redis key of the leader to run the recurring jobs.
+var LeaderKey = cache.MakeNamespaceCacheKey(cache.JobSchedulesLeaderNamespace)
+
+// Schedule runs the recurring jobs by schedule, only the leader of manager replicas runs the jobs.
+type Schedule interface {
+	// Serve runs the recurring jobs until stopped.
+	Serve()
+
+	// Stop stops running the recurring jobs and resigns the leadership.
+	Stop()
+}
+
+type schedule struct {
+	// db finds the recurring jobs.
+	db *gorm.DB
+
+	// service creates the runs of recurring jobs.
+	service service.Service
+
+	// elector elects the leader among manager replicas.
+	elector election.Elector
+
+	// interval is the interval of checking the recurring jobs to run.
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New returns a new Schedule.
+func New(db *gorm.DB, service service.Service, elector election.Elector) Schedule {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &schedule{
+		db:       db,
+		service:  service,
+		elector:  elector,
+		interval: defaultInterval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Serve runs the recurring jobs by schedule until stopped.
+func (s *schedule) Serve() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			leader, err := s.elector.Campaign(s.ctx)
+			if err != nil {
+				logger.Errorf("campaign job schedules leader failed: %s", err.Error())
+				continue
+			}
+
+			if !leader {
+				continue
+			}
+
+			s.run(s.ctx, time.Now())
+		case <-s.ctx.Done():
+			// Resign the leadership, so the other manager replica takes over immediately.
+			if err := s.elector.Resign(context.Background()); err != nil {
+				logger.Errorf("resign job schedules leader failed: %s", err.Error())
+			}
+
+			return
+		}
+	}
+}
+
+// Stop stops running the recurring jobs and waits for resigning the leadership.
+func (s *schedule) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// run runs the recurring jobs which are due to run at now.
+func (s *schedule) run(ctx context.Context, now time.Time) {
+	var jobs []model.Job
+	if err := s.db.WithContext(ctx).Preload("SchedulerClusters").Where("schedule <> ? AND next_run_at <= ?", "", now).Find(&jobs).Error; err != nil {
+		logger.Errorf("find recurring jobs failed: %s", err.Error())
+		return
+	}
+
+	for _, job := range jobs {
+		log := logger.WithGroupAndJobID(job.TaskID, fmt.Sprint(job.ID))
+		schedule, err := cron.ParseStandard(job.Schedule)
+		if err != nil {
+			log.Errorf("parse schedule %s failed: %s", job.Schedule, err.Error())
+			continue
+		}
+
+		// Move the next run time forward before running, the job is skipped if it has been run
+		// by the previous leader, and the missed runs are not run again.
+		result := s.db.WithContext(ctx).Model(&model.Job{}).Where("id = ? AND next_run_at = ?", job.ID, job.NextRunAt).Update("next_run_at", schedule.Next(now))
+		if result.Error != nil {
+			log.Errorf("update next run time failed: %s", result.Error.Error())
+			continue
+		}
+
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		run, err := s.runJob(ctx, job)
+		if err != nil {
+			log.Errorf("run recurring job failed: %s", err.Error())
+			continue
+		}
+
+		log.Infof("run recurring job %d", run.ID)
+	}
+}
+
+// runJob creates a run of the recurring job by the job type.
+func (s *schedule) runJob(ctx context.Context, job model.Job) (*model.Job, error) {
+	switch job.Type {
+	case internaljob.PreheatJob:
+		var args types.PreheatArgs
+		if err := structure.MapToStruct(job.Args, &args); err != nil {
+			return nil, err
+		}
+
+		var schedulerClusterIDs []uint
+		for _, schedulerCluster := range job.SchedulerClusters {
+			schedulerClusterIDs = append(schedulerClusterIDs, schedulerCluster.ID)
+		}
+
+		return s.service.CreatePreheatJob(ctx, types.CreatePreheatJobRequest{
+			BIO:                 job.BIO,
+			Type:                job.Type,
+			Args:                args,
+			UserID:              job.UserID,
+			SchedulerClusterIDs: schedulerClusterIDs,
+			ParentID:            job.ID,
+		})
+	default:
+		return nil, errors.New("unknown job type")
+	}
+}
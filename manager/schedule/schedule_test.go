@@ -0,0 +1,222 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schedule
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	electionmocks "d7y.io/dragonfly/v2/manager/election/mocks"
+	"d7y.io/dragonfly/v2/manager/model"
+	servicemocks "d7y.io/dragonfly/v2/manager/service/mocks"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+var (
+	mockJobColumns = []string{"id", "type", "bio", "user_id", "args", "schedule", "next_run_at"}
+
+	mockArgs = `{"type":"image","url":"https://example.com/v2/foo/manifests/bar"}`
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, mock
+}
+
+func TestSchedule_Serve(t *testing.T) {
+	tests := []struct {
+		name string
+		mock func(t *testing.T, me *electionmocks.MockElectorMockRecorder, ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock, ran chan struct{})
+	}{
+		{
+			name: "leader runs the due recurring jobs",
+			mock: func(t *testing.T, me *electionmocks.MockElectorMockRecorder, ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock, ran chan struct{}) {
+				nextRunAt := time.Now().Add(-time.Minute)
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job`")).WillReturnRows(
+					sqlmock.NewRows(mockJobColumns).AddRow(1, internaljob.PreheatJob, "foo", 2, mockArgs, "0 2 * * *", nextRunAt))
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job_scheduler_cluster`")).WillReturnRows(
+					sqlmock.NewRows([]string{"job_id", "scheduler_cluster_id"}).AddRow(1, 3))
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `scheduler_cluster`")).WillReturnRows(
+					sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mdb.ExpectBegin()
+				mdb.ExpectExec(regexp.QuoteMeta("UPDATE `job` SET `next_run_at`")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mdb.ExpectCommit()
+
+				gomock.InOrder(
+					me.Campaign(gomock.Any()).Return(true, nil).Times(1),
+					me.Campaign(gomock.Any()).Return(false, nil).AnyTimes(),
+				)
+				me.Resign(gomock.Any()).Return(nil).Times(1)
+				ms.CreatePreheatJob(gomock.Any(), types.CreatePreheatJobRequest{
+					BIO:  "foo",
+					Type: internaljob.PreheatJob,
+					Args: types.PreheatArgs{
+						Type: "image",
+						URL:  "https://example.com/v2/foo/manifests/bar",
+					},
+					UserID:              2,
+					SchedulerClusterIDs: []uint{3},
+					ParentID:            1,
+				}).Do(func(context.Context, types.CreatePreheatJobRequest) { close(ran) }).Return(&model.Job{}, nil).Times(1)
+			},
+		},
+		{
+			name: "follower does not run the recurring jobs",
+			mock: func(t *testing.T, me *electionmocks.MockElectorMockRecorder, ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock, ran chan struct{}) {
+				gomock.InOrder(
+					me.Campaign(gomock.Any()).Return(false, nil).Times(1),
+					me.Campaign(gomock.Any()).Do(func(context.Context) { close(ran) }).Return(false, nil).Times(1),
+					me.Campaign(gomock.Any()).Return(false, nil).AnyTimes(),
+				)
+				me.Resign(gomock.Any()).Return(nil).Times(1)
+			},
+		},
+		{
+			name: "campaign failed",
+			mock: func(t *testing.T, me *electionmocks.MockElectorMockRecorder, ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock, ran chan struct{}) {
+				gomock.InOrder(
+					me.Campaign(gomock.Any()).Do(func(context.Context) { close(ran) }).Return(false, errors.New("foo")).Times(1),
+					me.Campaign(gomock.Any()).Return(false, errors.New("foo")).AnyTimes(),
+				)
+				me.Resign(gomock.Any()).Return(errors.New("bar")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			elector := electionmocks.NewMockElector(ctl)
+			svc := servicemocks.NewMockService(ctl)
+			db, mdb := newMockDB(t)
+			ran := make(chan struct{})
+			tc.mock(t, elector.EXPECT(), svc.EXPECT(), mdb, ran)
+
+			s := New(db, svc, elector)
+			s.(*schedule).interval = 10 * time.Millisecond
+			go s.Serve()
+
+			select {
+			case <-ran:
+			case <-time.After(5 * time.Second):
+				t.Fatal("schedule does not run")
+			}
+
+			s.Stop()
+			assert.NoError(t, mdb.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSchedule_run(t *testing.T) {
+	tests := []struct {
+		name string
+		mock func(ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock)
+	}{
+		{
+			name: "job has been run by the previous leader",
+			mock: func(ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job`")).WillReturnRows(
+					sqlmock.NewRows(mockJobColumns).AddRow(1, internaljob.PreheatJob, "foo", 2, mockArgs, "0 2 * * *", time.Now()))
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job_scheduler_cluster`")).WillReturnRows(
+					sqlmock.NewRows([]string{"job_id", "scheduler_cluster_id"}))
+				mdb.ExpectBegin()
+				mdb.ExpectExec(regexp.QuoteMeta("UPDATE `job` SET `next_run_at`")).WillReturnResult(sqlmock.NewResult(0, 0))
+				mdb.ExpectCommit()
+			},
+		},
+		{
+			name: "job of unknown type is not run",
+			mock: func(ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job`")).WillReturnRows(
+					sqlmock.NewRows(mockJobColumns).AddRow(1, "foo", "foo", 2, mockArgs, "0 2 * * *", time.Now()))
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job_scheduler_cluster`")).WillReturnRows(
+					sqlmock.NewRows([]string{"job_id", "scheduler_cluster_id"}))
+				mdb.ExpectBegin()
+				mdb.ExpectExec(regexp.QuoteMeta("UPDATE `job` SET `next_run_at`")).WillReturnResult(sqlmock.NewResult(0, 1))
+				mdb.ExpectCommit()
+			},
+		},
+		{
+			name: "job with invalid schedule is skipped",
+			mock: func(ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job`")).WillReturnRows(
+					sqlmock.NewRows(mockJobColumns).AddRow(1, internaljob.PreheatJob, "foo", 2, mockArgs, "foo", time.Now()))
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job_scheduler_cluster`")).WillReturnRows(
+					sqlmock.NewRows([]string{"job_id", "scheduler_cluster_id"}))
+			},
+		},
+		{
+			name: "update next run time failed",
+			mock: func(ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job`")).WillReturnRows(
+					sqlmock.NewRows(mockJobColumns).AddRow(1, internaljob.PreheatJob, "foo", 2, mockArgs, "0 2 * * *", time.Now()))
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job_scheduler_cluster`")).WillReturnRows(
+					sqlmock.NewRows([]string{"job_id", "scheduler_cluster_id"}))
+				mdb.ExpectBegin()
+				mdb.ExpectExec(regexp.QuoteMeta("UPDATE `job` SET `next_run_at`")).WillReturnError(errors.New("foo"))
+				mdb.ExpectRollback()
+			},
+		},
+		{
+			name: "find recurring jobs failed",
+			mock: func(ms *servicemocks.MockServiceMockRecorder, mdb sqlmock.Sqlmock) {
+				mdb.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `job`")).WillReturnError(errors.New("foo"))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			elector := electionmocks.NewMockElector(ctl)
+			svc := servicemocks.NewMockService(ctl)
+			db, mdb := newMockDB(t)
+			tc.mock(svc.EXPECT(), mdb)
+
+			s := New(db, svc, elector)
+			s.(*schedule).run(context.Background(), time.Now())
+			assert.NoError(t, mdb.ExpectationsWereMet())
+		})
+	}
+}
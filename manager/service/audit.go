@@ -0,0 +1,116 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func (s *service) CreateAudit(ctx context.Context, json types.CreateAuditRequest) (*model.Audit, error) {
+	audit := model.Audit{
+		ActorType:    json.ActorType,
+		Actor:        json.Actor,
+		UserID:       json.UserID,
+		Method:       json.Method,
+		Path:         json.Path,
+		ResourceType: json.ResourceType,
+		ResourceID:   json.ResourceID,
+		Request:      json.Request,
+		Before:       json.Before,
+		After:        json.After,
+		StatusCode:   json.StatusCode,
+		State:        json.State,
+		Error:        json.Error,
+		IP:           json.IP,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&audit).Error; err != nil {
+		return nil, err
+	}
+
+	return &audit, nil
+}
+
+func (s *service) GetAudit(ctx context.Context, id uint) (*model.Audit, error) {
+	audit := model.Audit{}
+	if err := s.db.WithContext(ctx).First(&audit, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &audit, nil
+}
+
+func (s *service) GetAudits(ctx context.Context, q types.GetAuditsQuery) ([]model.Audit, int64, error) {
+	var count int64
+	audits := []model.Audit{}
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.Audit{
+		ActorType:    q.ActorType,
+		Actor:        q.Actor,
+		UserID:       q.UserID,
+		Method:       q.Method,
+		ResourceType: q.ResourceType,
+		ResourceID:   q.ResourceID,
+		State:        q.State,
+	}).Order("id DESC").Find(&audits).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return audits, count, nil
+}
+
+// auditResources is the models of resource types whose fields are recorded in audit,
+// the resource type is the api group name of route.
+var auditResources = map[string]any{
+	"users":                &model.User{},
+	"oauth":                &model.Oauth{},
+	"scheduler-clusters":   &model.SchedulerCluster{},
+	"schedulers":           &model.Scheduler{},
+	"applications":         &model.Application{},
+	"registry-credentials": &model.RegistryCredential{},
+	"seed-peer-clusters":   &model.SeedPeerCluster{},
+	"seed-peers":           &model.SeedPeer{},
+	"security-rules":       &model.SecurityRule{},
+	"security-groups":      &model.SecurityGroup{},
+	"configs":              &model.Config{},
+	"jobs":                 &model.Job{},
+}
+
+// GetAuditResource returns the fields of resource by resource type and id,
+// nil is returned if the fields of resource type are not recorded in audit.
+func (s *service) GetAuditResource(ctx context.Context, resourceType, id string) (map[string]any, error) {
+	m, ok := auditResources[resourceType]
+	if !ok {
+		return nil, nil
+	}
+
+	resource := map[string]any{}
+	if err := s.db.WithContext(ctx).Model(m).Where("id = ?", id).Take(&resource).Error; err != nil {
+		return nil, err
+	}
+
+	// The json and text columns are scanned as bytes.
+	for key, value := range resource {
+		if value, ok := value.([]byte); ok {
+			resource[key] = string(value)
+		}
+	}
+
+	return resource, nil
+}
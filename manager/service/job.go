@@ -18,12 +18,18 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/event"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/retry"
@@ -31,45 +37,113 @@ import (
 )
 
 func (s *service) CreatePreheatJob(ctx context.Context, json types.CreatePreheatJobRequest) (*model.Job, error) {
-	var schedulers []model.Scheduler
-	var schedulerClusters []model.SchedulerCluster
+	if json.Schedule != "" {
+		return s.createRecurringPreheatJob(ctx, json)
+	}
+
+	return s.createPreheatJob(ctx, json)
+}
+
+// createPreheatJob creates the preheat job which runs once immediately.
+func (s *service) createPreheatJob(ctx context.Context, json types.CreatePreheatJobRequest) (*model.Job, error) {
+	schedulers, schedulerClusters, err := s.getActiveSchedulers(ctx, json.SchedulerClusterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	preheatJobState, err := s.job.CreatePreheat(ctx, schedulers, json.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := structure.StructToMap(json.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	job := model.Job{
+		TaskID:            preheatJobState.GroupUUID,
+		BIO:               json.BIO,
+		Type:              json.Type,
+		State:             preheatJobState.State,
+		Args:              args,
+		Result:            preheatJobState.Result,
+		UserID:            json.UserID,
+		ParentID:          json.ParentID,
+		SchedulerClusters: schedulerClusters,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	go s.pollingJob(context.Background(), job.ID, job.TaskID)
+
+	return &job, nil
+}
+
+// getActiveSchedulers returns an active scheduler of every scheduler cluster, the scheduler clusters
+// without active scheduler are skipped unless they are specified by schedulerClusterIDs.
+func (s *service) getActiveSchedulers(ctx context.Context, schedulerClusterIDs []uint) ([]model.Scheduler, []model.SchedulerCluster, error) {
+	return s.findActiveSchedulers(ctx, schedulerClusterIDs, 1)
+}
 
-	if len(json.SchedulerClusterIDs) != 0 {
-		for _, schedulerClusterID := range json.SchedulerClusterIDs {
+// getAllActiveSchedulers returns all active schedulers of every scheduler cluster, it is used by the jobs
+// of existing task, because the tasks are spread across the schedulers of a cluster by task id.
+func (s *service) getAllActiveSchedulers(ctx context.Context, schedulerClusterIDs []uint) ([]model.Scheduler, []model.SchedulerCluster, error) {
+	return s.findActiveSchedulers(ctx, schedulerClusterIDs, -1)
+}
+
+// findActiveSchedulers returns at most limit active schedulers of every scheduler cluster, the limit is
+// canceled if it is -1. The scheduler clusters without active scheduler are skipped unless they are
+// specified by schedulerClusterIDs.
+func (s *service) findActiveSchedulers(ctx context.Context, schedulerClusterIDs []uint, limit int) ([]model.Scheduler, []model.SchedulerCluster, error) {
+	var schedulerClusters []model.SchedulerCluster
+	if len(schedulerClusterIDs) != 0 {
+		for _, schedulerClusterID := range schedulerClusterIDs {
 			schedulerCluster := model.SchedulerCluster{}
 			if err := s.db.WithContext(ctx).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			schedulerClusters = append(schedulerClusters, schedulerCluster)
-
-			scheduler := model.Scheduler{}
-			if err := s.db.WithContext(ctx).First(&scheduler, model.Scheduler{
-				SchedulerClusterID: schedulerCluster.ID,
-				State:              model.SchedulerStateActive,
-			}).Error; err != nil {
-				return nil, err
-			}
-			schedulers = append(schedulers, scheduler)
 		}
 	} else {
 		if err := s.db.WithContext(ctx).Find(&schedulerClusters).Error; err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+	}
 
-		for _, schedulerCluster := range schedulerClusters {
-			scheduler := model.Scheduler{}
-			if err := s.db.WithContext(ctx).First(&scheduler, model.Scheduler{
-				SchedulerClusterID: schedulerCluster.ID,
-				State:              model.SchedulerStateActive,
-			}).Error; err != nil {
-				continue
+	var schedulers []model.Scheduler
+	for _, schedulerCluster := range schedulerClusters {
+		var clusterSchedulers []model.Scheduler
+		if err := s.db.WithContext(ctx).Where(&model.Scheduler{
+			SchedulerClusterID: schedulerCluster.ID,
+			State:              model.SchedulerStateActive,
+		}).Order("id").Limit(limit).Find(&clusterSchedulers).Error; err != nil {
+			return nil, nil, err
+		}
+
+		if len(clusterSchedulers) == 0 {
+			if len(schedulerClusterIDs) != 0 {
+				return nil, nil, gorm.ErrRecordNotFound
 			}
 
-			schedulers = append(schedulers, scheduler)
+			continue
 		}
+
+		schedulers = append(schedulers, clusterSchedulers...)
 	}
 
-	groupJobState, err := s.job.CreatePreheat(ctx, schedulers, json.Args)
+	return schedulers, schedulerClusters, nil
+}
+
+func (s *service) CreateDeleteTaskJob(ctx context.Context, json types.CreateDeleteTaskJobRequest) (*model.Job, error) {
+	schedulers, schedulerClusters, err := s.getAllActiveSchedulers(ctx, json.SchedulerClusterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteTaskJobState, err := s.job.CreateDeleteTask(ctx, schedulers, json.Args)
 	if err != nil {
 		return nil, err
 	}
@@ -80,10 +154,10 @@ func (s *service) CreatePreheatJob(ctx context.Context, json types.CreatePreheat
 	}
 
 	job := model.Job{
-		TaskID:            groupJobState.GroupUUID,
+		TaskID:            deleteTaskJobState.GroupUUID,
 		BIO:               json.BIO,
 		Type:              json.Type,
-		State:             groupJobState.State,
+		State:             deleteTaskJobState.State,
 		Args:              args,
 		UserID:            json.UserID,
 		SchedulerClusters: schedulerClusters,
@@ -98,6 +172,130 @@ func (s *service) CreatePreheatJob(ctx context.Context, json types.CreatePreheat
 	return &job, nil
 }
 
+func (s *service) CreateGetTaskJob(ctx context.Context, json types.CreateGetTaskJobRequest) (*model.Job, error) {
+	schedulers, schedulerClusters, err := s.getAllActiveSchedulers(ctx, json.SchedulerClusterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	getTaskJobState, err := s.job.CreateGetTask(ctx, schedulers, json.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := structure.StructToMap(json.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	job := model.Job{
+		TaskID:            getTaskJobState.GroupUUID,
+		BIO:               json.BIO,
+		Type:              json.Type,
+		State:             getTaskJobState.State,
+		Args:              args,
+		UserID:            json.UserID,
+		SchedulerClusters: schedulerClusters,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	go s.pollingJob(context.Background(), job.ID, job.TaskID)
+
+	return &job, nil
+}
+
+// getTaskResponses returns the responses of succeeded get task jobs in the group.
+func getTaskResponses(jobStates []*machineryv1tasks.TaskState, log *logger.SugaredLoggerOnWith) []*internaljob.GetTaskResponse {
+	var tasks []*internaljob.GetTaskResponse
+	for _, jobState := range jobStates {
+		if !jobState.IsSuccess() || len(jobState.Results) == 0 {
+			continue
+		}
+
+		data, ok := jobState.Results[0].Value.(string)
+		if !ok {
+			log.Warnf("invalid result of job %s", jobState.TaskUUID)
+			continue
+		}
+
+		task := &internaljob.GetTaskResponse{}
+		if err := json.Unmarshal([]byte(data), task); err != nil {
+			log.Warnf("unmarshal result of job %s failed: %s", jobState.TaskUUID, err.Error())
+			continue
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return mergeTaskResponses(tasks)
+}
+
+// taskStateSucceeded is the state of succeeded task in scheduler.
+const taskStateSucceeded = "Succeeded"
+
+// mergeTaskResponses merges the responses of the schedulers in the same scheduler cluster, because
+// the peers of task are spread across the schedulers of cluster. The hostnames of the schedulers
+// which have the task are joined by comma.
+func mergeTaskResponses(tasks []*internaljob.GetTaskResponse) []*internaljob.GetTaskResponse {
+	var (
+		merged []*internaljob.GetTaskResponse
+		byID   = map[uint]*internaljob.GetTaskResponse{}
+		peers  = map[uint]map[string]struct{}{}
+	)
+	for _, task := range tasks {
+		m, ok := byID[task.SchedulerClusterID]
+		if !ok {
+			m = &internaljob.GetTaskResponse{
+				SchedulerClusterID: task.SchedulerClusterID,
+				TaskID:             task.TaskID,
+				ContentLength:      -1,
+				TotalPieces:        -1,
+				Peers:              []*internaljob.GetTaskPeer{},
+			}
+			byID[task.SchedulerClusterID] = m
+			peers[task.SchedulerClusterID] = map[string]struct{}{}
+			merged = append(merged, m)
+		}
+
+		// The task is not found in scheduler.
+		if task.State == "" {
+			continue
+		}
+
+		if m.SchedulerHostname == "" {
+			m.SchedulerHostname = task.SchedulerHostname
+		} else {
+			m.SchedulerHostname += "," + task.SchedulerHostname
+		}
+
+		if m.State == "" || task.State == taskStateSucceeded {
+			m.State = task.State
+		}
+
+		if task.ContentLength > m.ContentLength {
+			m.ContentLength = task.ContentLength
+		}
+
+		if task.TotalPieces > m.TotalPieces {
+			m.TotalPieces = task.TotalPieces
+		}
+
+		for _, peer := range task.Peers {
+			if _, ok := peers[task.SchedulerClusterID][peer.ID]; ok {
+				continue
+			}
+
+			peers[task.SchedulerClusterID][peer.ID] = struct{}{}
+			m.Peers = append(m.Peers, peer)
+		}
+	}
+
+	return merged
+}
+
 func (s *service) pollingJob(ctx context.Context, id uint, groupID string) {
 	var (
 		job model.Job
@@ -116,14 +314,53 @@ func (s *service) pollingJob(ctx context.Context, id uint, groupID string) {
 			return nil, false, err
 		}
 
-		if err := s.db.WithContext(ctx).First(&job, id).Updates(model.Job{
+		if err := s.db.WithContext(ctx).First(&job, id).Error; err != nil {
+			log.Errorf("polling group failed: %s", err.Error())
+			return nil, true, err
+		}
+
+		// Stop polling the job canceled by user.
+		if job.State == model.JobStateCanceled {
+			log.Info("polling group canceled")
+			return nil, true, nil
+		}
+
+		// Merge the progresses of preheat urls reported by scheduler workers.
+		progresses, err := s.job.GetPreheatProgresses(ctx, groupID)
+		if err != nil {
+			log.Warnf("polling progresses failed: %s", err.Error())
+		} else if len(progresses) > 0 {
+			result["Progresses"] = progresses
+		}
+
+		// Collect the tasks returned by schedulers for get task job.
+		if job.Type == internaljob.GetTaskJob {
+			if tasks := getTaskResponses(groupJob.JobStates, log); len(tasks) > 0 {
+				result["Tasks"] = tasks
+			}
+		}
+
+		// Keep the initial result of job, like the images resolved by tag pattern.
+		for k, v := range job.Result {
+			if _, ok := result[k]; !ok {
+				result[k] = v
+			}
+		}
+
+		previousState := job.State
+		tx := s.db.WithContext(ctx).Model(&job).Where("state <> ?", model.JobStateCanceled).Updates(model.Job{
 			State:  groupJob.State,
 			Result: result,
-		}).Error; err != nil {
+		})
+		if err := tx.Error; err != nil {
 			log.Errorf("polling group failed: %s", err.Error())
 			return nil, true, err
 		}
 
+		if tx.RowsAffected > 0 {
+			s.notifyJobStateChanged(&job, previousState)
+		}
+
 		switch job.State {
 		case machineryv1tasks.StateSuccess:
 			log.Info("polling group succeeded")
@@ -141,17 +378,39 @@ func (s *service) pollingJob(ctx context.Context, id uint, groupID string) {
 	}
 
 	// Polling timeout and failed.
-	if job.State != machineryv1tasks.StateSuccess && job.State != machineryv1tasks.StateFailure {
-		job := model.Job{}
-		if err := s.db.WithContext(ctx).First(&job, id).Updates(model.Job{
+	if job.State != machineryv1tasks.StateSuccess && job.State != machineryv1tasks.StateFailure && job.State != model.JobStateCanceled {
+		previousState := job.State
+		tx := s.db.WithContext(ctx).Model(&model.Job{}).Where("id = ? AND state <> ?", id, model.JobStateCanceled).Updates(model.Job{
 			State: machineryv1tasks.StateFailure,
-		}).Error; err != nil {
+		})
+		if err := tx.Error; err != nil {
 			log.Errorf("polling group failed: %s", err.Error())
+		} else if tx.RowsAffected > 0 {
+			if err := s.db.WithContext(ctx).First(&job, id).Error; err != nil {
+				log.Errorf("polling group failed: %s", err.Error())
+			} else {
+				s.notifyJobStateChanged(&job, previousState)
+			}
 		}
 		log.Error("polling group timeout")
 	}
 }
 
+// notifyJobStateChanged emits the job state changed event if the state of job is changed.
+func (s *service) notifyJobStateChanged(job *model.Job, previousState string) {
+	if job.State == previousState {
+		return
+	}
+
+	s.notifier.Notify(event.TypeJobStateChanged, &event.JobStateChanged{
+		ID:            job.ID,
+		Type:          job.Type,
+		TaskID:        job.TaskID,
+		State:         job.State,
+		PreviousState: previousState,
+	})
+}
+
 func (s *service) DestroyJob(ctx context.Context, id uint) error {
 	job := model.Job{}
 	if err := s.db.WithContext(ctx).First(&job, id).Error; err != nil {
@@ -167,9 +426,34 @@ func (s *service) DestroyJob(ctx context.Context, id uint) error {
 
 func (s *service) UpdateJob(ctx context.Context, id uint, json types.UpdateJobRequest) (*model.Job, error) {
 	job := model.Job{}
-	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Preload("SchedulerClusters").First(&job, id).Updates(model.Job{
-		BIO:    json.BIO,
-		UserID: json.UserID,
+	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Preload("SchedulerClusters").First(&job, id).Error; err != nil {
+		return nil, err
+	}
+
+	var nextRunAt *time.Time
+	if json.Schedule != "" {
+		if job.Schedule == "" {
+			return nil, errors.New("schedule can only be updated for recurring job")
+		}
+
+		if job.State == model.JobStateCanceled {
+			return nil, errors.New("schedule can not be updated for canceled job")
+		}
+
+		schedule, err := cron.ParseStandard(json.Schedule)
+		if err != nil {
+			return nil, err
+		}
+
+		next := schedule.Next(time.Now())
+		nextRunAt = &next
+	}
+
+	if err := s.db.WithContext(ctx).Model(&job).Updates(model.Job{
+		BIO:       json.BIO,
+		UserID:    json.UserID,
+		Schedule:  json.Schedule,
+		NextRunAt: nextRunAt,
 	}).Error; err != nil {
 		return nil, err
 	}
@@ -177,12 +461,118 @@ func (s *service) UpdateJob(ctx context.Context, id uint, json types.UpdateJobRe
 	return &job, nil
 }
 
+func (s *service) CancelJob(ctx context.Context, id uint) (*model.Job, error) {
+	job := model.Job{}
+	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Preload("SchedulerClusters").First(&job, id).Error; err != nil {
+		return nil, err
+	}
+
+	switch job.State {
+	case machineryv1tasks.StateSuccess, machineryv1tasks.StateFailure, model.JobStateCanceled:
+		return nil, fmt.Errorf("job in state %s can not be canceled", job.State)
+	}
+
+	// The recurring job stops creating runs and its unfinished runs are canceled.
+	if job.Schedule != "" {
+		runs := []model.Job{}
+		if err := s.db.WithContext(ctx).Preload("SchedulerClusters").Where("parent_id = ? AND state NOT IN ?", job.ID, []string{
+			machineryv1tasks.StateSuccess,
+			machineryv1tasks.StateFailure,
+			model.JobStateCanceled,
+		}).Find(&runs).Error; err != nil {
+			return nil, err
+		}
+
+		for i := range runs {
+			if err := s.cancelJob(ctx, &runs[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.cancelJob(ctx, &job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// cancelJob cancels the group of job and updates the job state to canceled.
+func (s *service) cancelJob(ctx context.Context, job *model.Job) error {
+	if job.TaskID != "" {
+		if err := s.cancelGroupJob(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	previousState := job.State
+	if err := s.db.WithContext(ctx).Model(job).Updates(map[string]any{
+		"state":       model.JobStateCanceled,
+		"next_run_at": nil,
+	}).Error; err != nil {
+		return err
+	}
+
+	s.notifyJobStateChanged(job, previousState)
+	return nil
+}
+
+// cancelGroupJob revokes the queued jobs of the group from the queues of schedulers,
+// and the running jobs are stopped by schedulers and seed peers when they find the
+// group is canceled.
+func (s *service) cancelGroupJob(ctx context.Context, job *model.Job) error {
+	if err := s.job.CancelGroupJob(ctx, job.TaskID); err != nil {
+		return err
+	}
+
+	var schedulerClusterIDs []uint
+	for _, schedulerCluster := range job.SchedulerClusters {
+		schedulerClusterIDs = append(schedulerClusterIDs, schedulerCluster.ID)
+	}
+
+	if len(schedulerClusterIDs) == 0 {
+		return nil
+	}
+
+	schedulers := []model.Scheduler{}
+	if err := s.db.WithContext(ctx).Find(&schedulers, "scheduler_cluster_id IN ?", schedulerClusterIDs).Error; err != nil {
+		return err
+	}
+
+	var queues []internaljob.Queue
+	for _, scheduler := range schedulers {
+		queue, err := internaljob.GetSchedulerQueue(scheduler.SchedulerClusterID, scheduler.HostName)
+		if err != nil {
+			continue
+		}
+
+		queues = append(queues, queue)
+	}
+
+	return s.job.RevokeGroupJob(ctx, job.TaskID, queues)
+}
+
 func (s *service) GetJob(ctx context.Context, id uint) (*model.Job, error) {
 	job := model.Job{}
 	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Preload("SchedulerClusters").First(&job, id).Error; err != nil {
 		return nil, err
 	}
 
+	// The result of running preheat job is refreshed by polling, so the latest
+	// progresses are read from the result backend directly.
+	if job.Type == internaljob.PreheatJob && job.Schedule == "" && job.State != machineryv1tasks.StateSuccess && job.State != machineryv1tasks.StateFailure && job.State != model.JobStateCanceled {
+		progresses, err := s.job.GetPreheatProgresses(ctx, job.TaskID)
+		if err != nil {
+			logger.Warnf("get job %d progresses failed: %s", id, err.Error())
+		} else if len(progresses) > 0 {
+			if job.Result == nil {
+				job.Result = model.JSONMap{}
+			}
+
+			job.Result["Progresses"] = progresses
+		}
+	}
+
 	return &job, nil
 }
 
@@ -190,9 +580,10 @@ func (s *service) GetJobs(ctx context.Context, q types.GetJobsQuery) ([]model.Jo
 	var count int64
 	var jobs []model.Job
 	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.Job{
-		Type:   q.Type,
-		State:  q.State,
-		UserID: q.UserID,
+		Type:     q.Type,
+		State:    q.State,
+		UserID:   q.UserID,
+		ParentID: q.ParentID,
 	}).Find(&jobs).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,81 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"d7y.io/dragonfly/v2/manager/job"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/structure"
+)
+
+// ErrInvalidJobArgs is returned when the schedule or args of recurring job is invalid.
+var ErrInvalidJobArgs = errors.New("invalid job args")
+
+// createRecurringPreheatJob creates the recurring preheat job, the runs of job
+// are created by schedule on the leader of manager replicas.
+func (s *service) createRecurringPreheatJob(ctx context.Context, json types.CreatePreheatJobRequest) (*model.Job, error) {
+	// The args of recurring job are validated on creation, otherwise the invalid
+	// job is not found until its first run fails.
+	schedule, err := cron.ParseStandard(json.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid schedule %s: %v", ErrInvalidJobArgs, json.Schedule, err)
+	}
+
+	if err := job.ValidatePreheatArgs(json.Args); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJobArgs, err)
+	}
+
+	var schedulerClusters []model.SchedulerCluster
+	for _, schedulerClusterID := range json.SchedulerClusterIDs {
+		schedulerCluster := model.SchedulerCluster{}
+		if err := s.db.WithContext(ctx).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
+			return nil, err
+		}
+		schedulerClusters = append(schedulerClusters, schedulerCluster)
+	}
+
+	args, err := structure.StructToMap(json.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	nextRunAt := schedule.Next(time.Now())
+	job := model.Job{
+		BIO:               json.BIO,
+		Type:              json.Type,
+		State:             model.JobStateScheduled,
+		Args:              args,
+		Schedule:          json.Schedule,
+		NextRunAt:         &nextRunAt,
+		UserID:            json.UserID,
+		SchedulerClusters: schedulerClusters,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
@@ -153,6 +153,21 @@ func (mr *MockServiceMockRecorder) AddSeedPeerToSeedPeerCluster(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSeedPeerToSeedPeerCluster", reflect.TypeOf((*MockService)(nil).AddSeedPeerToSeedPeerCluster), arg0, arg1, arg2)
 }
 
+// CancelJob mocks base method.
+func (m *MockService) CancelJob(arg0 context.Context, arg1 uint) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelJob", arg0, arg1)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelJob indicates an expected call of CancelJob.
+func (mr *MockServiceMockRecorder) CancelJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelJob", reflect.TypeOf((*MockService)(nil).CancelJob), arg0, arg1)
+}
+
 // CreateApplication mocks base method.
 func (m *MockService) CreateApplication(arg0 context.Context, arg1 types.CreateApplicationRequest) (*model.Application, error) {
 	m.ctrl.T.Helper()
@@ -168,6 +183,21 @@ func (mr *MockServiceMockRecorder) CreateApplication(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplication", reflect.TypeOf((*MockService)(nil).CreateApplication), arg0, arg1)
 }
 
+// CreateAudit mocks base method.
+func (m *MockService) CreateAudit(arg0 context.Context, arg1 types.CreateAuditRequest) (*model.Audit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAudit", arg0, arg1)
+	ret0, _ := ret[0].(*model.Audit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAudit indicates an expected call of CreateAudit.
+func (mr *MockServiceMockRecorder) CreateAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAudit", reflect.TypeOf((*MockService)(nil).CreateAudit), arg0, arg1)
+}
+
 // CreateBucket mocks base method.
 func (m *MockService) CreateBucket(arg0 context.Context, arg1 types.CreateBucketRequest) error {
 	m.ctrl.T.Helper()
@@ -197,6 +227,36 @@ func (mr *MockServiceMockRecorder) CreateConfig(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConfig", reflect.TypeOf((*MockService)(nil).CreateConfig), arg0, arg1)
 }
 
+// CreateDeleteTaskJob mocks base method.
+func (m *MockService) CreateDeleteTaskJob(arg0 context.Context, arg1 types.CreateDeleteTaskJobRequest) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeleteTaskJob", arg0, arg1)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDeleteTaskJob indicates an expected call of CreateDeleteTaskJob.
+func (mr *MockServiceMockRecorder) CreateDeleteTaskJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeleteTaskJob", reflect.TypeOf((*MockService)(nil).CreateDeleteTaskJob), arg0, arg1)
+}
+
+// CreateGetTaskJob mocks base method.
+func (m *MockService) CreateGetTaskJob(arg0 context.Context, arg1 types.CreateGetTaskJobRequest) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGetTaskJob", arg0, arg1)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGetTaskJob indicates an expected call of CreateGetTaskJob.
+func (mr *MockServiceMockRecorder) CreateGetTaskJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGetTaskJob", reflect.TypeOf((*MockService)(nil).CreateGetTaskJob), arg0, arg1)
+}
+
 // CreateModel mocks base method.
 func (m *MockService) CreateModel(arg0 context.Context, arg1 types.CreateModelParams, arg2 types.CreateModelRequest) (*types.Model, error) {
 	m.ctrl.T.Helper()
@@ -257,6 +317,21 @@ func (mr *MockServiceMockRecorder) CreatePreheatJob(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePreheatJob", reflect.TypeOf((*MockService)(nil).CreatePreheatJob), arg0, arg1)
 }
 
+// CreateRegistryCredential mocks base method.
+func (m *MockService) CreateRegistryCredential(arg0 context.Context, arg1 types.CreateRegistryCredentialRequest) (*model.RegistryCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRegistryCredential", arg0, arg1)
+	ret0, _ := ret[0].(*model.RegistryCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRegistryCredential indicates an expected call of CreateRegistryCredential.
+func (mr *MockServiceMockRecorder) CreateRegistryCredential(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRegistryCredential", reflect.TypeOf((*MockService)(nil).CreateRegistryCredential), arg0, arg1)
+}
+
 // CreateRole mocks base method.
 func (m *MockService) CreateRole(arg0 context.Context, arg1 types.CreateRoleRequest) error {
 	m.ctrl.T.Helper()
@@ -504,6 +579,20 @@ func (mr *MockServiceMockRecorder) DestroyOauth(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyOauth", reflect.TypeOf((*MockService)(nil).DestroyOauth), arg0, arg1)
 }
 
+// DestroyRegistryCredential mocks base method.
+func (m *MockService) DestroyRegistryCredential(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyRegistryCredential", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyRegistryCredential indicates an expected call of DestroyRegistryCredential.
+func (mr *MockServiceMockRecorder) DestroyRegistryCredential(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyRegistryCredential", reflect.TypeOf((*MockService)(nil).DestroyRegistryCredential), arg0, arg1)
+}
+
 // DestroyRole mocks base method.
 func (m *MockService) DestroyRole(arg0 context.Context, arg1 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -648,6 +737,52 @@ func (mr *MockServiceMockRecorder) GetApplications(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplications", reflect.TypeOf((*MockService)(nil).GetApplications), arg0, arg1)
 }
 
+// GetAudit mocks base method.
+func (m *MockService) GetAudit(arg0 context.Context, arg1 uint) (*model.Audit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAudit", arg0, arg1)
+	ret0, _ := ret[0].(*model.Audit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAudit indicates an expected call of GetAudit.
+func (mr *MockServiceMockRecorder) GetAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAudit", reflect.TypeOf((*MockService)(nil).GetAudit), arg0, arg1)
+}
+
+// GetAuditResource mocks base method.
+func (m *MockService) GetAuditResource(arg0 context.Context, arg1, arg2 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditResource", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditResource indicates an expected call of GetAuditResource.
+func (mr *MockServiceMockRecorder) GetAuditResource(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditResource", reflect.TypeOf((*MockService)(nil).GetAuditResource), arg0, arg1, arg2)
+}
+
+// GetAudits mocks base method.
+func (m *MockService) GetAudits(arg0 context.Context, arg1 types.GetAuditsQuery) ([]model.Audit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAudits", arg0, arg1)
+	ret0, _ := ret[0].([]model.Audit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAudits indicates an expected call of GetAudits.
+func (mr *MockServiceMockRecorder) GetAudits(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAudits", reflect.TypeOf((*MockService)(nil).GetAudits), arg0, arg1)
+}
+
 // GetBucket mocks base method.
 func (m *MockService) GetBucket(arg0 context.Context, arg1 string) (*objectstorage.BucketMetadata, error) {
 	m.ctrl.T.Helper()
@@ -860,6 +995,37 @@ func (mr *MockServiceMockRecorder) GetPermissions(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissions", reflect.TypeOf((*MockService)(nil).GetPermissions), arg0, arg1)
 }
 
+// GetRegistryCredential mocks base method.
+func (m *MockService) GetRegistryCredential(arg0 context.Context, arg1 uint) (*model.RegistryCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRegistryCredential", arg0, arg1)
+	ret0, _ := ret[0].(*model.RegistryCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRegistryCredential indicates an expected call of GetRegistryCredential.
+func (mr *MockServiceMockRecorder) GetRegistryCredential(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegistryCredential", reflect.TypeOf((*MockService)(nil).GetRegistryCredential), arg0, arg1)
+}
+
+// GetRegistryCredentials mocks base method.
+func (m *MockService) GetRegistryCredentials(arg0 context.Context, arg1 types.GetRegistryCredentialsQuery) ([]model.RegistryCredential, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRegistryCredentials", arg0, arg1)
+	ret0, _ := ret[0].([]model.RegistryCredential)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRegistryCredentials indicates an expected call of GetRegistryCredentials.
+func (mr *MockServiceMockRecorder) GetRegistryCredentials(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegistryCredentials", reflect.TypeOf((*MockService)(nil).GetRegistryCredentials), arg0, arg1)
+}
+
 // GetRole mocks base method.
 func (m *MockService) GetRole(arg0 context.Context, arg1 string) [][]string {
 	m.ctrl.T.Helper()
@@ -1299,6 +1465,21 @@ func (mr *MockServiceMockRecorder) UpdateOauth(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOauth", reflect.TypeOf((*MockService)(nil).UpdateOauth), arg0, arg1, arg2)
 }
 
+// UpdateRegistryCredential mocks base method.
+func (m *MockService) UpdateRegistryCredential(arg0 context.Context, arg1 uint, arg2 types.UpdateRegistryCredentialRequest) (*model.RegistryCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRegistryCredential", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.RegistryCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRegistryCredential indicates an expected call of UpdateRegistryCredential.
+func (mr *MockServiceMockRecorder) UpdateRegistryCredential(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRegistryCredential", reflect.TypeOf((*MockService)(nil).UpdateRegistryCredential), arg0, arg1, arg2)
+}
+
 // UpdateScheduler mocks base method.
 func (m *MockService) UpdateScheduler(arg0 context.Context, arg1 uint, arg2 types.UpdateSchedulerRequest) (*model.Scheduler, error) {
 	m.ctrl.T.Helper()
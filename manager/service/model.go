@@ -23,6 +23,7 @@ import (
 	"github.com/google/uuid"
 
 	"d7y.io/dragonfly/v2/manager/cache"
+	"d7y.io/dragonfly/v2/manager/event"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/types"
 )
@@ -78,6 +79,7 @@ func (s *service) UpdateModel(ctx context.Context, params types.ModelParams, jso
 	if err != nil {
 		return nil, err
 	}
+	previousVersionID := model.VersionID
 	model.VersionID = json.VersionID
 	model.UpdatedAt = time.Now()
 
@@ -85,6 +87,15 @@ func (s *service) UpdateModel(ctx context.Context, params types.ModelParams, jso
 		return nil, err
 	}
 
+	if model.VersionID != previousVersionID {
+		s.notifier.Notify(event.TypeModelVersionActivated, &event.ModelVersionActivated{
+			SchedulerID:       scheduler.ID,
+			ModelID:           model.ID,
+			VersionID:         model.VersionID,
+			PreviousVersionID: previousVersionID,
+		})
+	}
+
 	return model, nil
 }
 
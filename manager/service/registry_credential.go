@@ -0,0 +1,119 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func (s *service) CreateRegistryCredential(ctx context.Context, json types.CreateRegistryCredentialRequest) (*model.RegistryCredential, error) {
+	encryptedSecret, err := s.encryptSecret(json.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	registryCredential := model.RegistryCredential{
+		Host:            json.Host,
+		Type:            json.Type,
+		Provider:        json.Provider,
+		Username:        json.Username,
+		EncryptedSecret: encryptedSecret,
+		BIO:             json.BIO,
+		UserID:          json.UserID,
+	}
+
+	if err := s.db.WithContext(ctx).Preload("User").Create(&registryCredential).Error; err != nil {
+		return nil, err
+	}
+
+	return &registryCredential, nil
+}
+
+func (s *service) DestroyRegistryCredential(ctx context.Context, id uint) error {
+	registryCredential := model.RegistryCredential{}
+	if err := s.db.WithContext(ctx).First(&registryCredential, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Delete(&model.RegistryCredential{}, id).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) UpdateRegistryCredential(ctx context.Context, id uint, json types.UpdateRegistryCredentialRequest) (*model.RegistryCredential, error) {
+	var (
+		encryptedSecret []byte
+		err             error
+	)
+	if json.Secret != "" {
+		encryptedSecret, err = s.encryptSecret(json.Secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	registryCredential := model.RegistryCredential{}
+	if err := s.db.WithContext(ctx).Preload("User").First(&registryCredential, id).Updates(model.RegistryCredential{
+		Type:            json.Type,
+		Provider:        json.Provider,
+		Username:        json.Username,
+		EncryptedSecret: encryptedSecret,
+		BIO:             json.BIO,
+		UserID:          json.UserID,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &registryCredential, nil
+}
+
+func (s *service) GetRegistryCredential(ctx context.Context, id uint) (*model.RegistryCredential, error) {
+	registryCredential := model.RegistryCredential{}
+	if err := s.db.WithContext(ctx).Preload("User").First(&registryCredential, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &registryCredential, nil
+}
+
+func (s *service) GetRegistryCredentials(ctx context.Context, q types.GetRegistryCredentialsQuery) ([]model.RegistryCredential, int64, error) {
+	var count int64
+	registryCredentials := []model.RegistryCredential{}
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.RegistryCredential{
+		Host: q.Host,
+		Type: q.Type,
+	}).Preload("User").Find(&registryCredentials).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return registryCredentials, count, nil
+}
+
+// encryptSecret encrypts the secret of registry credential before it is stored in database.
+func (s *service) encryptSecret(secret string) ([]byte, error) {
+	if s.cipher == nil {
+		return nil, errors.New("registry credential requires security encryptionKey")
+	}
+
+	return s.cipher.Encrypt([]byte(secret))
+}
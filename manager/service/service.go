@@ -28,10 +28,12 @@ import (
 
 	"d7y.io/dragonfly/v2/manager/cache"
 	"d7y.io/dragonfly/v2/manager/database"
+	"d7y.io/dragonfly/v2/manager/event"
 	"d7y.io/dragonfly/v2/manager/job"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/permission/rbac"
 	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/encryption"
 	"d7y.io/dragonfly/v2/pkg/objectstorage"
 )
 
@@ -120,8 +122,11 @@ type Service interface {
 	GetConfigs(context.Context, types.GetConfigsQuery) ([]model.Config, int64, error)
 
 	CreatePreheatJob(context.Context, types.CreatePreheatJobRequest) (*model.Job, error)
+	CreateDeleteTaskJob(context.Context, types.CreateDeleteTaskJobRequest) (*model.Job, error)
+	CreateGetTaskJob(context.Context, types.CreateGetTaskJobRequest) (*model.Job, error)
 	DestroyJob(context.Context, uint) error
 	UpdateJob(context.Context, uint, types.UpdateJobRequest) (*model.Job, error)
+	CancelJob(context.Context, uint) (*model.Job, error)
 	GetJob(context.Context, uint) (*model.Job, error)
 	GetJobs(context.Context, types.GetJobsQuery) ([]model.Job, int64, error)
 
@@ -134,6 +139,17 @@ type Service interface {
 	GetApplication(context.Context, uint) (*model.Application, error)
 	GetApplications(context.Context, types.GetApplicationsQuery) ([]model.Application, int64, error)
 
+	CreateRegistryCredential(context.Context, types.CreateRegistryCredentialRequest) (*model.RegistryCredential, error)
+	DestroyRegistryCredential(context.Context, uint) error
+	UpdateRegistryCredential(context.Context, uint, types.UpdateRegistryCredentialRequest) (*model.RegistryCredential, error)
+	GetRegistryCredential(context.Context, uint) (*model.RegistryCredential, error)
+	GetRegistryCredentials(context.Context, types.GetRegistryCredentialsQuery) ([]model.RegistryCredential, int64, error)
+
+	CreateAudit(context.Context, types.CreateAuditRequest) (*model.Audit, error)
+	GetAudit(context.Context, uint) (*model.Audit, error)
+	GetAudits(context.Context, types.GetAuditsQuery) ([]model.Audit, int64, error)
+	GetAuditResource(context.Context, string, string) (map[string]any, error)
+
 	CreateModel(context.Context, types.CreateModelParams, types.CreateModelRequest) (*types.Model, error)
 	DestroyModel(context.Context, types.ModelParams) error
 	UpdateModel(context.Context, types.ModelParams, types.UpdateModelRequest) (*types.Model, error)
@@ -154,10 +170,12 @@ type service struct {
 	job           *job.Job
 	enforcer      *casbin.Enforcer
 	objectStorage objectstorage.ObjectStorage
+	cipher        encryption.Cipher
+	notifier      event.Notifier
 }
 
 // NewREST returns a new REST instence
-func New(database *database.Database, cache *cache.Cache, job *job.Job, enforcer *casbin.Enforcer, objectStorage objectstorage.ObjectStorage) Service {
+func New(database *database.Database, cache *cache.Cache, job *job.Job, enforcer *casbin.Enforcer, objectStorage objectstorage.ObjectStorage, cipher encryption.Cipher, notifier event.Notifier) Service {
 	return &service{
 		db:            database.DB,
 		rdb:           database.RDB,
@@ -165,5 +183,7 @@ func New(database *database.Database, cache *cache.Cache, job *job.Job, enforcer
 		job:           job,
 		enforcer:      enforcer,
 		objectStorage: objectStorage,
+		cipher:        cipher,
+		notifier:      notifier,
 	}
 }
@@ -0,0 +1,50 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+type AuditParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type CreateAuditRequest struct {
+	ActorType    string         `json:"actor_type" binding:"required,oneof=user scheduler seed_peer"`
+	Actor        string         `json:"actor" binding:"omitempty"`
+	UserID       uint           `json:"user_id" binding:"omitempty"`
+	Method       string         `json:"method" binding:"required"`
+	Path         string         `json:"path" binding:"omitempty"`
+	ResourceType string         `json:"resource_type" binding:"omitempty"`
+	ResourceID   string         `json:"resource_id" binding:"omitempty"`
+	Request      map[string]any `json:"request" binding:"omitempty"`
+	Before       map[string]any `json:"before" binding:"omitempty"`
+	After        map[string]any `json:"after" binding:"omitempty"`
+	StatusCode   int            `json:"status_code" binding:"omitempty"`
+	State        string         `json:"state" binding:"required,oneof=SUCCESS FAILURE"`
+	Error        string         `json:"error" binding:"omitempty"`
+	IP           string         `json:"ip" binding:"omitempty"`
+}
+
+type GetAuditsQuery struct {
+	ActorType    string `form:"actor_type" binding:"omitempty,oneof=user scheduler seed_peer"`
+	Actor        string `form:"actor" binding:"omitempty"`
+	UserID       uint   `form:"user_id" binding:"omitempty"`
+	Method       string `form:"method" binding:"omitempty"`
+	ResourceType string `form:"resource_type" binding:"omitempty"`
+	ResourceID   string `form:"resource_id" binding:"omitempty"`
+	State        string `form:"state" binding:"omitempty,oneof=SUCCESS FAILURE"`
+	Page         int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage      int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
@@ -24,11 +24,14 @@ type CreateJobRequest struct {
 	UserID              uint           `json:"user_id" binding:"omitempty"`
 	SeedPeerClusterIDs  []uint         `json:"seed_peer_cluster_ids" binding:"omitempty"`
 	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids" binding:"omitempty"`
+	Schedule            string         `json:"schedule" binding:"omitempty"`
 }
 
 type UpdateJobRequest struct {
 	BIO    string `json:"bio" binding:"omitempty"`
 	UserID uint   `json:"user_id" binding:"omitempty"`
+	// Schedule is the cron schedule of recurring job, it is only updated for recurring job.
+	Schedule string `json:"schedule" binding:"omitempty"`
 }
 
 type JobParams struct {
@@ -36,11 +39,12 @@ type JobParams struct {
 }
 
 type GetJobsQuery struct {
-	Type    string `form:"type" binding:"omitempty"`
-	State   string `form:"state" binding:"omitempty,oneof=PENDING RECEIVED STARTED RETRY SUCCESS FAILURE"`
-	UserID  uint   `form:"user_id" binding:"omitempty"`
-	Page    int    `form:"page" binding:"omitempty,gte=1"`
-	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+	Type     string `form:"type" binding:"omitempty"`
+	State    string `form:"state" binding:"omitempty,oneof=PENDING RECEIVED STARTED RETRY SUCCESS FAILURE SCHEDULED CANCELED"`
+	UserID   uint   `form:"user_id" binding:"omitempty"`
+	ParentID uint   `form:"parent_id" binding:"omitempty"`
+	Page     int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
 }
 
 type CreatePreheatJobRequest struct {
@@ -50,6 +54,11 @@ type CreatePreheatJobRequest struct {
 	Result              map[string]any `json:"result" binding:"omitempty"`
 	UserID              uint           `json:"user_id" binding:"omitempty"`
 	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids" binding:"omitempty"`
+	// Schedule is the cron schedule of recurring job in standard format like 0 2 * * *,
+	// the job runs once immediately if it is empty.
+	Schedule string `json:"schedule" binding:"omitempty"`
+	// ParentID is the id of recurring job if the job is a run of it, it is not set by api.
+	ParentID uint `json:"-"`
 }
 
 type PreheatArgs struct {
@@ -58,4 +67,52 @@ type PreheatArgs struct {
 	Tag     string            `json:"tag" binding:"omitempty"`
 	Filter  string            `json:"filter" binding:"omitempty"`
 	Headers map[string]string `json:"headers" binding:"omitempty"`
+	// Platform is the platform of image manifest list or image index to preheat,
+	// it is in os/arch[/variant] format like linux/amd64, or all for every platform,
+	// default is linux/amd64.
+	Platform string `json:"platform" binding:"omitempty"`
+	// TagRegex is the regex of image tags to preheat, the url is the repository url
+	// like https://index.docker.io/v2/library/alpine when tag pattern is specified.
+	TagRegex string `json:"tag_regex" binding:"omitempty"`
+	// TagSemver is the semver constraint of image tags to preheat, like >= 1.2, < 2.0.
+	TagSemver string `json:"tag_semver" binding:"omitempty"`
+}
+
+type CreateDeleteTaskJobRequest struct {
+	BIO                 string         `json:"bio" binding:"omitempty"`
+	Type                string         `json:"type" binding:"required"`
+	Args                DeleteTaskArgs `json:"args" binding:"required"`
+	Result              map[string]any `json:"result" binding:"omitempty"`
+	UserID              uint           `json:"user_id" binding:"omitempty"`
+	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids" binding:"omitempty"`
+}
+
+// DeleteTaskArgs identifies the task to delete, the url meta must be
+// the same as the url meta of downloading.
+type DeleteTaskArgs struct {
+	URL     string            `json:"url" binding:"required"`
+	Tag     string            `json:"tag" binding:"omitempty"`
+	Digest  string            `json:"digest" binding:"omitempty"`
+	Filter  string            `json:"filter" binding:"omitempty"`
+	Headers map[string]string `json:"headers" binding:"omitempty"`
+}
+
+type CreateGetTaskJobRequest struct {
+	BIO                 string         `json:"bio" binding:"omitempty"`
+	Type                string         `json:"type" binding:"required"`
+	Args                GetTaskArgs    `json:"args" binding:"required"`
+	Result              map[string]any `json:"result" binding:"omitempty"`
+	UserID              uint           `json:"user_id" binding:"omitempty"`
+	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids" binding:"omitempty"`
+}
+
+// GetTaskArgs identifies the task to get by the task id, or the url
+// and url meta which are the same as the url meta of downloading.
+type GetTaskArgs struct {
+	TaskID  string            `json:"task_id" binding:"required_without=URL"`
+	URL     string            `json:"url" binding:"required_without=TaskID"`
+	Tag     string            `json:"tag" binding:"omitempty"`
+	Digest  string            `json:"digest" binding:"omitempty"`
+	Filter  string            `json:"filter" binding:"omitempty"`
+	Headers map[string]string `json:"headers" binding:"omitempty"`
 }
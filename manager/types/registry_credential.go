@@ -0,0 +1,47 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+type RegistryCredentialParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type CreateRegistryCredentialRequest struct {
+	Host     string `json:"host" binding:"required"`
+	Type     string `json:"type" binding:"required,oneof=basic bearer token-exchange"`
+	Provider string `json:"provider" binding:"required_if=Type token-exchange,omitempty,oneof=ecr gcr"`
+	Username string `json:"username" binding:"required_if=Type basic"`
+	Secret   string `json:"secret" binding:"required"`
+	BIO      string `json:"bio" binding:"omitempty"`
+	UserID   uint   `json:"user_id" binding:"required"`
+}
+
+type UpdateRegistryCredentialRequest struct {
+	Type     string `json:"type" binding:"omitempty,oneof=basic bearer token-exchange"`
+	Provider string `json:"provider" binding:"omitempty,oneof=ecr gcr"`
+	Username string `json:"username" binding:"omitempty"`
+	Secret   string `json:"secret" binding:"omitempty"`
+	BIO      string `json:"bio" binding:"omitempty"`
+	UserID   uint   `json:"user_id" binding:"required"`
+}
+
+type GetRegistryCredentialsQuery struct {
+	Host    string `form:"host" binding:"omitempty"`
+	Type    string `form:"type" binding:"omitempty,oneof=basic bearer token-exchange"`
+	Page    int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
@@ -16,6 +16,10 @@
 
 package types
 
+// ListSchedulersSchedulerClusterIDKey is the host info key of list schedulers request,
+// scheduler sets it to list the active schedulers of its scheduler cluster.
+const ListSchedulersSchedulerClusterIDKey = "scheduler_cluster_id"
+
 type SchedulerParams struct {
 	ID uint `uri:"id" binding:"required"`
 }
@@ -54,8 +54,22 @@ type GetSchedulerClustersQuery struct {
 }
 
 type SchedulerClusterConfig struct {
-	FilterParentLimit      uint32 `yaml:"filterParentLimit" mapstructure:"filterParentLimit" json:"filter_parent_limit" binding:"omitempty,gte=1,lte=20"`
-	FilterParentRangeLimit uint32 `yaml:"filterParentRangeLimit" mapstructure:"filterParentRangeLimit" json:"filter_parent_range_limit" binding:"omitempty,gte=10,lte=1000"`
+	FilterParentLimit      uint32                           `yaml:"filterParentLimit" mapstructure:"filterParentLimit" json:"filter_parent_limit" binding:"omitempty,gte=1,lte=20"`
+	FilterParentRangeLimit uint32                           `yaml:"filterParentRangeLimit" mapstructure:"filterParentRangeLimit" json:"filter_parent_range_limit" binding:"omitempty,gte=10,lte=1000"`
+	Evaluator              *SchedulerClusterEvaluatorConfig `yaml:"evaluator" mapstructure:"evaluator" json:"evaluator" binding:"omitempty"`
+}
+
+type SchedulerClusterEvaluatorConfig struct {
+	FinishedPieceWeight           *float64 `yaml:"finishedPieceWeight" mapstructure:"finishedPieceWeight" json:"finished_piece_weight" binding:"omitempty,gte=0,lte=1"`
+	ParentHostUploadSuccessWeight *float64 `yaml:"parentHostUploadSuccessWeight" mapstructure:"parentHostUploadSuccessWeight" json:"parent_host_upload_success_weight" binding:"omitempty,gte=0,lte=1"`
+	FreeUploadWeight              *float64 `yaml:"freeUploadWeight" mapstructure:"freeUploadWeight" json:"free_upload_weight" binding:"omitempty,gte=0,lte=1"`
+	HostTypeWeight                *float64 `yaml:"hostTypeWeight" mapstructure:"hostTypeWeight" json:"host_type_weight" binding:"omitempty,gte=0,lte=1"`
+	IDCAffinityWeight             *float64 `yaml:"idcAffinityWeight" mapstructure:"idcAffinityWeight" json:"idc_affinity_weight" binding:"omitempty,gte=0,lte=1"`
+	NetTopologyAffinityWeight     *float64 `yaml:"netTopologyAffinityWeight" mapstructure:"netTopologyAffinityWeight" json:"net_topology_affinity_weight" binding:"omitempty,gte=0,lte=1"`
+	LocationAffinityWeight        *float64 `yaml:"locationAffinityWeight" mapstructure:"locationAffinityWeight" json:"location_affinity_weight" binding:"omitempty,gte=0,lte=1"`
+	CPULoadWeight                 *float64 `yaml:"cpuLoadWeight" mapstructure:"cpuLoadWeight" json:"cpu_load_weight" binding:"omitempty,gte=0,lte=1"`
+	MemoryPressureWeight          *float64 `yaml:"memoryPressureWeight" mapstructure:"memoryPressureWeight" json:"memory_pressure_weight" binding:"omitempty,gte=0,lte=1"`
+	DiskFreeWeight                *float64 `yaml:"diskFreeWeight" mapstructure:"diskFreeWeight" json:"disk_free_weight" binding:"omitempty,gte=0,lte=1"`
 }
 
 type SchedulerClusterClientConfig struct {
@@ -0,0 +1,76 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Cipher is the interface used for encrypting the secrets at rest.
+type Cipher interface {
+	// Encrypt encrypts the plaintext, the nonce is prepended to the ciphertext.
+	Encrypt(plaintext []byte) ([]byte, error)
+
+	// Decrypt decrypts the ciphertext returned by Encrypt.
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// aesGCM is the cipher of AES in galois counter mode.
+type aesGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM returns a new AES-GCM Cipher, the length of key must be 16, 24 or 32 bytes
+// to select AES-128, AES-192 or AES-256.
+func NewAESGCM(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aesGCM{aead: aead}, nil
+}
+
+// Encrypt encrypts the plaintext with a random nonce.
+func (c *aesGCM) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt splits the nonce from the ciphertext and decrypts it.
+func (c *aesGCM) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	return c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
@@ -0,0 +1,100 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAESGCM(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    []byte
+		expect func(t *testing.T, c Cipher, err error)
+	}{
+		{
+			name: "aes-128",
+			key:  bytes.Repeat([]byte{1}, 16),
+			expect: func(t *testing.T, c Cipher, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NotNil(c)
+			},
+		},
+		{
+			name: "aes-256",
+			key:  bytes.Repeat([]byte{1}, 32),
+			expect: func(t *testing.T, c Cipher, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NotNil(c)
+			},
+		},
+		{
+			name: "invalid key size",
+			key:  []byte("foo"),
+			expect: func(t *testing.T, c Cipher, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+				assert.Nil(c)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewAESGCM(tc.key)
+			tc.expect(t, c, err)
+		})
+	}
+}
+
+func TestAESGCM_EncryptDecrypt(t *testing.T) {
+	assert := assert.New(t)
+	c, err := NewAESGCM(bytes.Repeat([]byte{1}, 32))
+	assert.NoError(err)
+
+	ciphertext, err := c.Encrypt([]byte("foo"))
+	assert.NoError(err)
+	assert.NotContains(string(ciphertext), "foo")
+
+	// nonce is random, the same plaintext is encrypted to different ciphertexts
+	other, err := c.Encrypt([]byte("foo"))
+	assert.NoError(err)
+	assert.NotEqual(ciphertext, other)
+
+	plaintext, err := c.Decrypt(ciphertext)
+	assert.NoError(err)
+	assert.Equal("foo", string(plaintext))
+
+	// tampered ciphertext can not be decrypted
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	_, err = c.Decrypt(ciphertext)
+	assert.Error(err)
+
+	_, err = c.Decrypt([]byte("bar"))
+	assert.Error(err)
+
+	// ciphertext can not be decrypted by other key
+	other2, err := NewAESGCM(bytes.Repeat([]byte{2}, 32))
+	assert.NoError(err)
+	_, err = other2.Decrypt(other)
+	assert.Error(err)
+}
@@ -32,6 +32,14 @@ const (
 	MetaDigest = "digest"
 )
 
+const (
+	// MaxPartNumber is the max number of parts in multipart upload.
+	MaxPartNumber = 10000
+
+	// MinPartSize is the min size of parts in multipart upload, except the last part.
+	MinPartSize = 5 * 1024 * 1024
+)
+
 // Method is the client operation method .
 type Method string
 
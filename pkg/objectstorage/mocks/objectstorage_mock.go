@@ -37,6 +37,34 @@ func (m *MockObjectStorage) EXPECT() *MockObjectStorageMockRecorder {
 	return m.recorder
 }
 
+// AbortMultipartUpload mocks base method.
+func (m *MockObjectStorage) AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbortMultipartUpload", ctx, bucketName, objectKey, uploadID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AbortMultipartUpload indicates an expected call of AbortMultipartUpload.
+func (mr *MockObjectStorageMockRecorder) AbortMultipartUpload(ctx, bucketName, objectKey, uploadID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortMultipartUpload", reflect.TypeOf((*MockObjectStorage)(nil).AbortMultipartUpload), ctx, bucketName, objectKey, uploadID)
+}
+
+// CompleteMultipartUpload mocks base method.
+func (m *MockObjectStorage) CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*objectstorage.PartMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteMultipartUpload", ctx, bucketName, objectKey, uploadID, parts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteMultipartUpload indicates an expected call of CompleteMultipartUpload.
+func (mr *MockObjectStorageMockRecorder) CompleteMultipartUpload(ctx, bucketName, objectKey, uploadID, parts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteMultipartUpload", reflect.TypeOf((*MockObjectStorage)(nil).CompleteMultipartUpload), ctx, bucketName, objectKey, uploadID, parts)
+}
+
 // CreateBucket mocks base method.
 func (m *MockObjectStorage) CreateBucket(ctx context.Context, bucketName string) error {
 	m.ctrl.T.Helper()
@@ -51,6 +79,21 @@ func (mr *MockObjectStorageMockRecorder) CreateBucket(ctx, bucketName interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBucket", reflect.TypeOf((*MockObjectStorage)(nil).CreateBucket), ctx, bucketName)
 }
 
+// CreateMultipartUpload mocks base method.
+func (m *MockObjectStorage) CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMultipartUpload", ctx, bucketName, objectKey, digest)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMultipartUpload indicates an expected call of CreateMultipartUpload.
+func (mr *MockObjectStorageMockRecorder) CreateMultipartUpload(ctx, bucketName, objectKey, digest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMultipartUpload", reflect.TypeOf((*MockObjectStorage)(nil).CreateMultipartUpload), ctx, bucketName, objectKey, digest)
+}
+
 // DeleteBucket mocks base method.
 func (m *MockObjectStorage) DeleteBucket(ctx context.Context, bucketName string) error {
 	m.ctrl.T.Helper()
@@ -186,18 +229,33 @@ func (mr *MockObjectStorageMockRecorder) ListBucketMetadatas(ctx interface{}) *g
 }
 
 // ListObjectMetadatas mocks base method.
-func (m *MockObjectStorage) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker string, limit int64) ([]*objectstorage.ObjectMetadata, error) {
+func (m *MockObjectStorage) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker, delimiter string, limit int64) (*objectstorage.ObjectMetadatas, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListObjectMetadatas", ctx, bucketName, prefix, marker, limit)
-	ret0, _ := ret[0].([]*objectstorage.ObjectMetadata)
+	ret := m.ctrl.Call(m, "ListObjectMetadatas", ctx, bucketName, prefix, marker, delimiter, limit)
+	ret0, _ := ret[0].(*objectstorage.ObjectMetadatas)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListObjectMetadatas indicates an expected call of ListObjectMetadatas.
-func (mr *MockObjectStorageMockRecorder) ListObjectMetadatas(ctx, bucketName, prefix, marker, limit interface{}) *gomock.Call {
+func (mr *MockObjectStorageMockRecorder) ListObjectMetadatas(ctx, bucketName, prefix, marker, delimiter, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjectMetadatas", reflect.TypeOf((*MockObjectStorage)(nil).ListObjectMetadatas), ctx, bucketName, prefix, marker, delimiter, limit)
+}
+
+// ListParts mocks base method.
+func (m *MockObjectStorage) ListParts(ctx context.Context, bucketName, objectKey, uploadID string) ([]*objectstorage.PartMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListParts", ctx, bucketName, objectKey, uploadID)
+	ret0, _ := ret[0].([]*objectstorage.PartMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListParts indicates an expected call of ListParts.
+func (mr *MockObjectStorageMockRecorder) ListParts(ctx, bucketName, objectKey, uploadID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjectMetadatas", reflect.TypeOf((*MockObjectStorage)(nil).ListObjectMetadatas), ctx, bucketName, prefix, marker, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListParts", reflect.TypeOf((*MockObjectStorage)(nil).ListParts), ctx, bucketName, objectKey, uploadID)
 }
 
 // PutObject mocks base method.
@@ -213,3 +271,18 @@ func (mr *MockObjectStorageMockRecorder) PutObject(ctx, bucketName, objectKey, d
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObject", reflect.TypeOf((*MockObjectStorage)(nil).PutObject), ctx, bucketName, objectKey, digest, reader)
 }
+
+// UploadPart mocks base method.
+func (m *MockObjectStorage) UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, partNumber, size int64, reader io.Reader) (*objectstorage.PartMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadPart", ctx, bucketName, objectKey, uploadID, partNumber, size, reader)
+	ret0, _ := ret[0].(*objectstorage.PartMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadPart indicates an expected call of UploadPart.
+func (mr *MockObjectStorageMockRecorder) UploadPart(ctx, bucketName, objectKey, uploadID, partNumber, size, reader interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadPart", reflect.TypeOf((*MockObjectStorage)(nil).UploadPart), ctx, bucketName, objectKey, uploadID, partNumber, size, reader)
+}
@@ -49,6 +49,23 @@ type ObjectMetadata struct {
 
 	// Digest is object digest.
 	Digest string
+
+	// LastModified is last modified time of object.
+	LastModified time.Time
+}
+
+type ObjectMetadatas struct {
+	// Metadatas are metadata of objects.
+	Metadatas []*ObjectMetadata
+
+	// CommonPrefixes are the prefixes of object keys grouped by delimiter.
+	CommonPrefixes []string
+
+	// IsTruncated is whether there are more objects to list.
+	IsTruncated bool
+
+	// NextMarker is the marker of next listing when the listing is truncated.
+	NextMarker string
 }
 
 type BucketMetadata struct {
@@ -59,6 +76,17 @@ type BucketMetadata struct {
 	CreateAt time.Time
 }
 
+type PartMetadata struct {
+	// PartNumber is number of part, it starts from 1.
+	PartNumber int64
+
+	// ETag is ETag of part.
+	ETag string
+
+	// Size is size of part.
+	Size int64
+}
+
 type ObjectStorage interface {
 	// GetBucketMetadata returns metadata of bucket.
 	GetBucketMetadata(ctx context.Context, bucketName string) (*BucketMetadata, error)
@@ -87,14 +115,30 @@ type ObjectStorage interface {
 	// DeleteObject deletes data of object.
 	DeleteObject(ctx context.Context, bucketName, objectKey string) error
 
-	// ListObjectMetadatas returns metadata of objects.
-	ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker string, limit int64) ([]*ObjectMetadata, error)
+	// ListObjectMetadatas returns metadata of objects after marker, the keys containing delimiter
+	// after prefix are grouped as common prefixes if delimiter is not empty.
+	ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker, delimiter string, limit int64) (*ObjectMetadatas, error)
 
 	// IsObjectExist returns whether the object exists.
 	IsObjectExist(ctx context.Context, bucketName, objectKey string) (bool, error)
 
 	// GetSignURL returns sign url of object.
 	GetSignURL(ctx context.Context, bucketName, objectKey string, method Method, expire time.Duration) (string, error)
+
+	// CreateMultipartUpload initiates multipart upload of object and returns the upload id.
+	CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error)
+
+	// UploadPart uploads part of multipart upload, size is the length of data in reader.
+	UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, partNumber, size int64, reader io.Reader) (*PartMetadata, error)
+
+	// CompleteMultipartUpload completes multipart upload by assembling the parts in order of part number.
+	CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*PartMetadata) error
+
+	// AbortMultipartUpload aborts multipart upload and deletes the uploaded parts.
+	AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error
+
+	// ListParts returns metadata of the uploaded parts of multipart upload.
+	ListParts(ctx context.Context, bucketName, objectKey, uploadID string) ([]*PartMetadata, error)
 }
 
 // New object storage interface.
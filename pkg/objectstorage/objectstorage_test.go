@@ -0,0 +1,277 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockBucketName = "bucket"
+	mockObjectKey  = "dir/a.txt"
+	mockUploadID   = "foo"
+	mockDigest     = "md5:5d41402abc4b2a76b9719d911017c592"
+)
+
+// multipartServer serves the multipart upload api of object storage, the api of
+// s3, oss and obs are compatible in multipart upload.
+type multipartServer struct {
+	t  *testing.T
+	mu sync.Mutex
+
+	// digest is the digest meta of multipart upload.
+	digest string
+
+	// parts is the uploaded parts, the key is part number.
+	parts map[int]string
+
+	// completed is the part numbers of completed object in order.
+	completed []int
+
+	// aborted reports whether the multipart upload is aborted.
+	aborted bool
+}
+
+// ServeHTTP handles the multipart upload requests of object.
+func (s *multipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path != "/"+mockBucketName+"/"+mockObjectKey {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	if _, ok := query["uploads"]; ok && r.Method == http.MethodPost {
+		for key, values := range r.Header {
+			if strings.HasSuffix(strings.ToLower(key), "-meta-"+MetaDigest) {
+				s.digest = values[0]
+			}
+		}
+
+		s.writeXML(w, fmt.Sprintf("<InitiateMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>",
+			mockBucketName, mockObjectKey, mockUploadID))
+		return
+	}
+
+	if query.Get("uploadId") != mockUploadID {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.parts[partNumber] = string(data)
+		w.Header().Set("ETag", partETag(string(data)))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPost:
+		var req struct {
+			Parts []struct {
+				PartNumber int    `xml:"PartNumber"`
+				ETag       string `xml:"ETag"`
+			} `xml:"Part"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for _, part := range req.Parts {
+			if partETag(s.parts[part.PartNumber]) != part.ETag {
+				http.Error(w, "invalid part", http.StatusBadRequest)
+				return
+			}
+
+			s.completed = append(s.completed, part.PartNumber)
+		}
+
+		s.writeXML(w, fmt.Sprintf("<CompleteMultipartUploadResult><Bucket>%s</Bucket><Key>%s</Key><ETag>%s</ETag></CompleteMultipartUploadResult>",
+			mockBucketName, mockObjectKey, `"etag-2"`))
+	case http.MethodDelete:
+		s.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		// List one part in each page to cover pagination.
+		var marker int
+		if value := query.Get("part-number-marker"); value != "" {
+			var err error
+			if marker, err = strconv.Atoi(value); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		var partNumbers []int
+		for partNumber := range s.parts {
+			if partNumber > marker {
+				partNumbers = append(partNumbers, partNumber)
+			}
+		}
+		sort.Ints(partNumbers)
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "<ListPartsResult><Bucket>%s</Bucket><Key>%s</Key><UploadId>%s</UploadId><MaxParts>1</MaxParts>", mockBucketName, mockObjectKey, mockUploadID)
+		if len(partNumbers) > 0 {
+			partNumber := partNumbers[0]
+			fmt.Fprintf(&b, "<Part><PartNumber>%d</PartNumber><LastModified>2022-10-17T00:00:00.000Z</LastModified><ETag>%s</ETag><Size>%d</Size></Part>",
+				partNumber, partETag(s.parts[partNumber]), len(s.parts[partNumber]))
+			fmt.Fprintf(&b, "<NextPartNumberMarker>%d</NextPartNumberMarker>", partNumber)
+		}
+		fmt.Fprintf(&b, "<IsTruncated>%t</IsTruncated></ListPartsResult>", len(partNumbers) > 1)
+		s.writeXML(w, b.String())
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// writeXML writes the xml response.
+func (s *multipartServer) writeXML(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.WriteString(w, xml.Header+body); err != nil {
+		s.t.Error(err)
+	}
+}
+
+// partETag returns the etag of part data.
+func partETag(data string) string {
+	sum := md5.Sum([]byte(data))
+	return strconv.Quote(hex.EncodeToString(sum[:]))
+}
+
+func TestObjectStorage_MultipartUpload(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(endpoint string) (ObjectStorage, error)
+	}{
+		{
+			name: "s3",
+			new: func(endpoint string) (ObjectStorage, error) {
+				cfg := aws.NewConfig().
+					WithCredentials(credentials.NewStaticCredentials("foo", "bar", "")).
+					WithRegion("us-east-1").
+					WithEndpoint(endpoint).
+					WithS3ForcePathStyle(true)
+				s, err := session.NewSession(cfg)
+				if err != nil {
+					return nil, err
+				}
+
+				return &s3{client: awss3.New(s)}, nil
+			},
+		},
+		{
+			name: "oss",
+			new: func(endpoint string) (ObjectStorage, error) {
+				return newOSS("", endpoint, "foo", "bar")
+			},
+		},
+		{
+			name: "obs",
+			new: func(endpoint string) (ObjectStorage, error) {
+				return newOBS("", endpoint, "foo", "bar")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := &multipartServer{t: t, parts: map[int]string{}}
+			s := httptest.NewServer(server)
+			defer s.Close()
+
+			client, err := tc.new(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert := assert.New(t)
+			ctx := context.Background()
+			uploadID, err := client.CreateMultipartUpload(ctx, mockBucketName, mockObjectKey, mockDigest)
+			assert.NoError(err)
+			assert.Equal(mockUploadID, uploadID)
+			assert.Equal(mockDigest, server.digest)
+
+			var parts []*PartMetadata
+			for _, part := range []struct {
+				partNumber int64
+				data       string
+			}{
+				{2, "world"},
+				{1, "hello "},
+			} {
+				metadata, err := client.UploadPart(ctx, mockBucketName, mockObjectKey, uploadID, part.partNumber, int64(len(part.data)), strings.NewReader(part.data))
+				assert.NoError(err)
+				assert.Equal(&PartMetadata{
+					PartNumber: part.partNumber,
+					ETag:       partETag(part.data),
+					Size:       int64(len(part.data)),
+				}, metadata)
+				parts = append(parts, metadata)
+			}
+			assert.Equal(map[int]string{1: "hello ", 2: "world"}, server.parts)
+
+			metadatas, err := client.ListParts(ctx, mockBucketName, mockObjectKey, uploadID)
+			assert.NoError(err)
+			assert.Equal([]*PartMetadata{
+				{PartNumber: 1, ETag: partETag("hello "), Size: 6},
+				{PartNumber: 2, ETag: partETag("world"), Size: 5},
+			}, metadatas)
+
+			// Parts are completed in order of part number.
+			assert.NoError(client.CompleteMultipartUpload(ctx, mockBucketName, mockObjectKey, uploadID, parts))
+			assert.Equal([]int{1, 2}, server.completed)
+
+			assert.NoError(client.AbortMultipartUpload(ctx, mockBucketName, mockObjectKey, uploadID))
+			assert.True(server.aborted)
+
+			_, err = client.ListParts(ctx, mockBucketName, mockObjectKey, "bar")
+			assert.Error(err)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"time"
 
@@ -114,6 +115,7 @@ func (o *obs) GetObjectMetadata(ctx context.Context, bucketName, objectKey strin
 		ContentType:        metadata.ContentType,
 		ETag:               metadata.ETag,
 		Digest:             metadata.Metadata[MetaDigest],
+		LastModified:       metadata.LastModified,
 	}, true, nil
 }
 
@@ -157,11 +159,12 @@ func (o *obs) DeleteObject(ctx context.Context, bucketName, objectKey string) er
 }
 
 // ListObjectMetadatas returns metadata of objects.
-func (o *obs) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker string, limit int64) ([]*ObjectMetadata, error) {
+func (o *obs) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker, delimiter string, limit int64) (*ObjectMetadatas, error) {
 	resp, err := o.client.ListObjects(&huaweiobs.ListObjectsInput{
 		ListObjsInput: huaweiobs.ListObjsInput{
-			Prefix:  prefix,
-			MaxKeys: int(limit),
+			Prefix:    prefix,
+			MaxKeys:   int(limit),
+			Delimiter: delimiter,
 		},
 		Bucket: bucketName,
 		Marker: marker,
@@ -170,11 +173,17 @@ func (o *obs) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marke
 		return nil, err
 	}
 
-	var metadatas []*ObjectMetadata
+	metadatas := &ObjectMetadatas{
+		CommonPrefixes: resp.CommonPrefixes,
+		IsTruncated:    resp.IsTruncated,
+		NextMarker:     resp.NextMarker,
+	}
 	for _, object := range resp.Contents {
-		metadatas = append(metadatas, &ObjectMetadata{
-			Key:  object.Key,
-			ETag: object.ETag,
+		metadatas.Metadatas = append(metadatas.Metadatas, &ObjectMetadata{
+			Key:           object.Key,
+			ContentLength: object.Size,
+			ETag:          object.ETag,
+			LastModified:  object.LastModified,
 		})
 	}
 
@@ -225,3 +234,110 @@ func (o *obs) GetSignURL(ctx context.Context, bucketName, objectKey string, meth
 
 	return resp.SignedUrl, nil
 }
+
+// CreateMultipartUpload initiates multipart upload of object and returns the upload id.
+func (o *obs) CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error) {
+	resp, err := o.client.InitiateMultipartUpload(&huaweiobs.InitiateMultipartUploadInput{
+		ObjectOperationInput: huaweiobs.ObjectOperationInput{
+			Bucket: bucketName,
+			Key:    objectKey,
+			Metadata: map[string]string{
+				MetaDigest: digest,
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return resp.UploadId, nil
+}
+
+// UploadPart uploads part of multipart upload, size is the length of data in reader.
+func (o *obs) UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, partNumber, size int64, reader io.Reader) (*PartMetadata, error) {
+	resp, err := o.client.UploadPart(&huaweiobs.UploadPartInput{
+		Bucket:     bucketName,
+		Key:        objectKey,
+		UploadId:   uploadID,
+		PartNumber: int(partNumber),
+		PartSize:   size,
+		Body:       reader,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PartMetadata{
+		PartNumber: partNumber,
+		ETag:       resp.ETag,
+		Size:       size,
+	}, nil
+}
+
+// CompleteMultipartUpload completes multipart upload by assembling the parts in order of part number.
+func (o *obs) CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*PartMetadata) error {
+	var obsParts []huaweiobs.Part
+	for _, part := range parts {
+		obsParts = append(obsParts, huaweiobs.Part{
+			PartNumber: int(part.PartNumber),
+			ETag:       part.ETag,
+		})
+	}
+
+	sort.Slice(obsParts, func(i, j int) bool {
+		return obsParts[i].PartNumber < obsParts[j].PartNumber
+	})
+
+	_, err := o.client.CompleteMultipartUpload(&huaweiobs.CompleteMultipartUploadInput{
+		Bucket:   bucketName,
+		Key:      objectKey,
+		UploadId: uploadID,
+		Parts:    obsParts,
+	})
+
+	return err
+}
+
+// AbortMultipartUpload aborts multipart upload and deletes the uploaded parts.
+func (o *obs) AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error {
+	_, err := o.client.AbortMultipartUpload(&huaweiobs.AbortMultipartUploadInput{
+		Bucket:   bucketName,
+		Key:      objectKey,
+		UploadId: uploadID,
+	})
+
+	return err
+}
+
+// ListParts returns metadata of the uploaded parts of multipart upload.
+func (o *obs) ListParts(ctx context.Context, bucketName, objectKey, uploadID string) ([]*PartMetadata, error) {
+	var (
+		metadatas []*PartMetadata
+		marker    int
+	)
+	for {
+		resp, err := o.client.ListParts(&huaweiobs.ListPartsInput{
+			Bucket:           bucketName,
+			Key:              objectKey,
+			UploadId:         uploadID,
+			PartNumberMarker: marker,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, part := range resp.Parts {
+			metadatas = append(metadatas, &PartMetadata{
+				PartNumber: int64(part.PartNumber),
+				ETag:       part.ETag,
+				Size:       part.Size,
+			})
+		}
+
+		if !resp.IsTruncated {
+			return metadatas, nil
+		}
+
+		marker = resp.NextPartNumberMarker
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
@@ -109,6 +110,9 @@ func (o *oss) GetObjectMetadata(ctx context.Context, bucketName, objectKey strin
 		return nil, false, err
 	}
 
+	// Last-Modified header is optional, zero time is used if it is invalid.
+	lastModified, _ := http.ParseTime(header.Get(headers.LastModified))
+
 	return &ObjectMetadata{
 		Key:                objectKey,
 		ContentDisposition: header.Get(headers.ContentDisposition),
@@ -118,6 +122,7 @@ func (o *oss) GetObjectMetadata(ctx context.Context, bucketName, objectKey strin
 		ContentType:        header.Get(headers.ContentType),
 		ETag:               header.Get(headers.ETag),
 		Digest:             header.Get(aliyunoss.HTTPHeaderOssMetaPrefix + MetaDigest),
+		LastModified:       lastModified,
 	}, true, nil
 }
 
@@ -153,22 +158,28 @@ func (o *oss) DeleteObject(ctx context.Context, bucketName, objectKey string) er
 }
 
 // ListObjectMetadatas returns metadata of objects.
-func (o *oss) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker string, limit int64) ([]*ObjectMetadata, error) {
+func (o *oss) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker, delimiter string, limit int64) (*ObjectMetadatas, error) {
 	bucket, err := o.client.Bucket(bucketName)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := bucket.ListObjects(aliyunoss.Prefix(prefix), aliyunoss.Marker(marker), aliyunoss.MaxKeys(int(limit)))
+	resp, err := bucket.ListObjects(aliyunoss.Prefix(prefix), aliyunoss.Marker(marker), aliyunoss.Delimiter(delimiter), aliyunoss.MaxKeys(int(limit)))
 	if err != nil {
 		return nil, err
 	}
 
-	var metadatas []*ObjectMetadata
+	metadatas := &ObjectMetadatas{
+		CommonPrefixes: resp.CommonPrefixes,
+		IsTruncated:    resp.IsTruncated,
+		NextMarker:     resp.NextMarker,
+	}
 	for _, object := range resp.Objects {
-		metadatas = append(metadatas, &ObjectMetadata{
-			Key:  object.Key,
-			ETag: object.ETag,
+		metadatas.Metadatas = append(metadatas.Metadatas, &ObjectMetadata{
+			Key:           object.Key,
+			ContentLength: object.Size,
+			ETag:          object.ETag,
+			LastModified:  object.LastModified,
 		})
 	}
 
@@ -217,3 +228,112 @@ func (o *oss) GetSignURL(ctx context.Context, bucketName, objectKey string, meth
 
 	return bucket.SignURL(objectKey, ossHTTPMethod, int64(expire.Seconds()))
 }
+
+// CreateMultipartUpload initiates multipart upload of object and returns the upload id.
+func (o *oss) CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error) {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return "", err
+	}
+
+	meta := aliyunoss.Meta(MetaDigest, digest)
+	resp, err := bucket.InitiateMultipartUpload(objectKey, meta)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.UploadID, nil
+}
+
+// UploadPart uploads part of multipart upload, size is the length of data in reader.
+func (o *oss) UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, partNumber, size int64, reader io.Reader) (*PartMetadata, error) {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := bucket.UploadPart(multipartUpload(bucketName, objectKey, uploadID), reader, size, int(partNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PartMetadata{
+		PartNumber: partNumber,
+		ETag:       resp.ETag,
+		Size:       size,
+	}, nil
+}
+
+// CompleteMultipartUpload completes multipart upload by assembling the parts in order of part number.
+func (o *oss) CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*PartMetadata) error {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return err
+	}
+
+	var uploadParts aliyunoss.UploadParts
+	for _, part := range parts {
+		uploadParts = append(uploadParts, aliyunoss.UploadPart{
+			PartNumber: int(part.PartNumber),
+			ETag:       part.ETag,
+		})
+	}
+	sort.Sort(uploadParts)
+
+	_, err = bucket.CompleteMultipartUpload(multipartUpload(bucketName, objectKey, uploadID), uploadParts)
+	return err
+}
+
+// AbortMultipartUpload aborts multipart upload and deletes the uploaded parts.
+func (o *oss) AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return err
+	}
+
+	return bucket.AbortMultipartUpload(multipartUpload(bucketName, objectKey, uploadID))
+}
+
+// ListParts returns metadata of the uploaded parts of multipart upload.
+func (o *oss) ListParts(ctx context.Context, bucketName, objectKey, uploadID string) ([]*PartMetadata, error) {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		metadatas []*PartMetadata
+		marker    int
+	)
+	for {
+		resp, err := bucket.ListUploadedParts(multipartUpload(bucketName, objectKey, uploadID), aliyunoss.PartNumberMarker(marker))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, part := range resp.UploadedParts {
+			metadatas = append(metadatas, &PartMetadata{
+				PartNumber: int64(part.PartNumber),
+				ETag:       part.ETag,
+				Size:       int64(part.Size),
+			})
+		}
+
+		if !resp.IsTruncated {
+			return metadatas, nil
+		}
+
+		if marker, err = strconv.Atoi(resp.NextPartNumberMarker); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// multipartUpload returns the multipart upload of oss by upload id.
+func multipartUpload(bucketName, objectKey, uploadID string) aliyunoss.InitiateMultipartUploadResult {
+	return aliyunoss.InitiateMultipartUploadResult{
+		Bucket:   bucketName,
+		Key:      objectKey,
+		UploadID: uploadID,
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -27,6 +28,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	awss3 "github.com/aws/aws-sdk-go/service/s3"
 )
 
@@ -114,6 +116,7 @@ func (s *s3) GetObjectMetadata(ctx context.Context, bucketName, objectKey string
 		ContentType:        aws.StringValue(resp.ContentType),
 		ETag:               aws.StringValue(resp.ETag),
 		Digest:             aws.StringValue(resp.Metadata[MetaDigest]),
+		LastModified:       aws.TimeValue(resp.LastModified),
 	}, true, nil
 }
 
@@ -155,26 +158,46 @@ func (s *s3) DeleteObject(ctx context.Context, bucketName, objectKey string) err
 	return err
 }
 
-// DeleteObject deletes data of object.
-func (s *s3) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker string, limit int64) ([]*ObjectMetadata, error) {
-	resp, err := s.client.ListObjectsWithContext(ctx, &awss3.ListObjectsInput{
+// ListObjectMetadatas returns metadata of objects.
+func (s *s3) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker, delimiter string, limit int64) (*ObjectMetadatas, error) {
+	input := &awss3.ListObjectsInput{
 		Bucket:  aws.String(bucketName),
 		Prefix:  aws.String(prefix),
 		Marker:  aws.String(marker),
 		MaxKeys: aws.Int64(limit),
-	})
+	}
+	if delimiter != "" {
+		input.Delimiter = aws.String(delimiter)
+	}
+
+	resp, err := s.client.ListObjectsWithContext(ctx, input)
 	if err != nil {
 		return nil, err
 	}
 
-	var metadatas []*ObjectMetadata
+	metadatas := &ObjectMetadatas{
+		IsTruncated: aws.BoolValue(resp.IsTruncated),
+		NextMarker:  aws.StringValue(resp.NextMarker),
+	}
 	for _, object := range resp.Contents {
-		metadatas = append(metadatas, &ObjectMetadata{
-			Key:  aws.StringValue(object.Key),
-			ETag: aws.StringValue(object.ETag),
+		metadatas.Metadatas = append(metadatas.Metadatas, &ObjectMetadata{
+			Key:           aws.StringValue(object.Key),
+			ContentLength: aws.Int64Value(object.Size),
+			ETag:          aws.StringValue(object.ETag),
+			LastModified:  aws.TimeValue(object.LastModified),
 		})
 	}
 
+	for _, commonPrefix := range resp.CommonPrefixes {
+		metadatas.CommonPrefixes = append(metadatas.CommonPrefixes, aws.StringValue(commonPrefix.Prefix))
+	}
+
+	// S3 returns next marker only when delimiter is specified,
+	// the last key is the marker of next listing.
+	if metadatas.IsTruncated && metadatas.NextMarker == "" && len(metadatas.Metadatas) > 0 {
+		metadatas.NextMarker = metadatas.Metadatas[len(metadatas.Metadatas)-1].Key
+	}
+
 	return metadatas, nil
 }
 
@@ -242,3 +265,106 @@ func (s *s3) GetSignURL(ctx context.Context, bucketName, objectKey string, metho
 
 	return req.Presign(expire)
 }
+
+// CreateMultipartUpload initiates multipart upload of object and returns the upload id.
+func (s *s3) CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error) {
+	meta := map[string]string{}
+	meta[MetaDigest] = digest
+
+	resp, err := s.client.CreateMultipartUploadWithContext(ctx, &awss3.CreateMultipartUploadInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectKey),
+		Metadata: aws.StringMap(meta),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(resp.UploadId), nil
+}
+
+// UploadPart uploads part of multipart upload, size is the length of data in reader.
+func (s *s3) UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, partNumber, size int64, reader io.Reader) (*PartMetadata, error) {
+	resp, err := s.client.UploadPartWithContext(ctx, &awss3.UploadPartInput{
+		Bucket:        aws.String(bucketName),
+		Key:           aws.String(objectKey),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int64(partNumber),
+		ContentLength: aws.Int64(size),
+		Body:          aws.ReadSeekCloser(reader),
+	}, withUnsignedPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PartMetadata{
+		PartNumber: partNumber,
+		ETag:       aws.StringValue(resp.ETag),
+		Size:       size,
+	}, nil
+}
+
+// CompleteMultipartUpload completes multipart upload by assembling the parts in order of part number.
+func (s *s3) CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*PartMetadata) error {
+	var completedParts []*awss3.CompletedPart
+	for _, part := range parts {
+		completedParts = append(completedParts, &awss3.CompletedPart{
+			PartNumber: aws.Int64(part.PartNumber),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	sort.Slice(completedParts, func(i, j int) bool {
+		return aws.Int64Value(completedParts[i].PartNumber) < aws.Int64Value(completedParts[j].PartNumber)
+	})
+
+	_, err := s.client.CompleteMultipartUploadWithContext(ctx, &awss3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucketName),
+		Key:             aws.String(objectKey),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &awss3.CompletedMultipartUpload{Parts: completedParts},
+	})
+
+	return err
+}
+
+// AbortMultipartUpload aborts multipart upload and deletes the uploaded parts.
+func (s *s3) AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error {
+	_, err := s.client.AbortMultipartUploadWithContext(ctx, &awss3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
+	})
+
+	return err
+}
+
+// ListParts returns metadata of the uploaded parts of multipart upload.
+func (s *s3) ListParts(ctx context.Context, bucketName, objectKey, uploadID string) ([]*PartMetadata, error) {
+	var metadatas []*PartMetadata
+	if err := s.client.ListPartsPagesWithContext(ctx, &awss3.ListPartsInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
+	}, func(page *awss3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range page.Parts {
+			metadatas = append(metadatas, &PartMetadata{
+				PartNumber: aws.Int64Value(part.PartNumber),
+				ETag:       aws.StringValue(part.ETag),
+				Size:       aws.Int64Value(part.Size),
+			})
+		}
+
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	return metadatas, nil
+}
+
+// withUnsignedPayload signs request without the hash of body,
+// because the body of part may be streamed and is not seekable.
+func withUnsignedPayload(r *request.Request) {
+	r.Handlers.Sign.Swap(v4.SignRequestHandler.Name, v4.BuildNamedHandler(v4.SignRequestHandler.Name, v4.WithUnsignedPayload))
+}
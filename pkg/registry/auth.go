@@ -0,0 +1,60 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AuthURL returns the url of token service by the bearer challenge of registry, like
+// Bearer realm="<auth-service-url>",service="<service>",scope="repository:<name>:pull".
+func AuthURL(wwwAuth []string) string {
+	if len(wwwAuth) == 0 {
+		return ""
+	}
+	polished := make([]string, 0)
+	for _, it := range wwwAuth {
+		polished = append(polished, strings.ReplaceAll(it, "\"", ""))
+	}
+	fileds := strings.Split(polished[0], ",")
+	realm := strings.SplitN(fileds[0], "=", 2)
+	if len(realm) != 2 {
+		return ""
+	}
+	query := strings.Join(fileds[1:], "&")
+	return fmt.Sprintf("%s?%s", realm[1], query)
+}
+
+// ParseToken returns the bearer token in the response of token service,
+// the access_token is compatible with OAuth2.
+func ParseToken(body []byte) (string, error) {
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+
+	for _, key := range []string{"token", "access_token"} {
+		if result[key] != nil {
+			return fmt.Sprintf("%v", result[key]), nil
+		}
+	}
+
+	return "", errors.New("token is empty")
+}
@@ -0,0 +1,81 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthURL(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("https://auth.docker.io/token?service=registry.docker.io&scope=repository:library/alpine:pull",
+		AuthURL([]string{`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/alpine:pull"`}))
+	assert.Equal("", AuthURL(nil))
+	assert.Equal("", AuthURL([]string{"Bearer"}))
+}
+
+func TestParseToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		expect func(t *testing.T, token string, err error)
+	}{
+		{
+			name: "token",
+			body: `{"token":"foo","access_token":"bar"}`,
+			expect: func(t *testing.T, token string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo", token)
+			},
+		},
+		{
+			name: "access token",
+			body: `{"access_token":"bar"}`,
+			expect: func(t *testing.T, token string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("bar", token)
+			},
+		},
+		{
+			name: "token is empty",
+			body: `{}`,
+			expect: func(t *testing.T, token string, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "token is empty")
+			},
+		},
+		{
+			name: "invalid body",
+			body: "foo",
+			expect: func(t *testing.T, token string, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := ParseToken([]byte(tc.body))
+			tc.expect(t, token, err)
+		})
+	}
+}
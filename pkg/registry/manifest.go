@@ -0,0 +1,91 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry
+
+import (
+	"encoding/json"
+	"mime"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	"github.com/distribution/distribution/v3/manifest/schema2"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ManifestMediaTypes is the media types of manifests accepted from registry.
+var ManifestMediaTypes = []string{
+	schema2.MediaTypeManifest,
+	manifestlist.MediaTypeManifestList,
+	specs.MediaTypeImageManifest,
+	specs.MediaTypeImageIndex,
+}
+
+// UnmarshalManifest unmarshals the manifest by the content type of response,
+// OCI image index is unmarshaled as manifest list.
+func UnmarshalManifest(contentType string, body []byte) (distribution.Manifest, error) {
+	manifest, _, err := distribution.UnmarshalManifest(ManifestMediaType(contentType, body), body)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// ManifestMediaType returns the media type of manifest by content type of response,
+// if the content type is not a manifest media type, the media type is detected by the manifest.
+func ManifestMediaType(contentType string, body []byte) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		for _, v := range ManifestMediaTypes {
+			if mediaType == v {
+				return mediaType
+			}
+		}
+	}
+
+	var versioned struct {
+		MediaType string          `json:"mediaType"`
+		Manifests json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(body, &versioned); err != nil {
+		return contentType
+	}
+
+	if versioned.MediaType != "" {
+		return versioned.MediaType
+	}
+
+	// The media type of OCI manifest and OCI image index is optional.
+	if versioned.Manifests != nil {
+		return specs.MediaTypeImageIndex
+	}
+
+	return specs.MediaTypeImageManifest
+}
+
+// Layers returns the layers of image manifest, it returns nil if the manifest
+// is a manifest list or image index.
+func Layers(manifest distribution.Manifest) []distribution.Descriptor {
+	switch manifest := manifest.(type) {
+	case *ocischema.DeserializedManifest:
+		return manifest.Layers
+	case *schema2.DeserializedManifest:
+		return manifest.Layers
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+	"github.com/distribution/distribution/v3/manifest/schema2"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifestMediaType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expect      string
+	}{
+		{
+			name:        "media type of content type",
+			contentType: schema2.MediaTypeManifest + "; charset=utf-8",
+			body:        "{}",
+			expect:      schema2.MediaTypeManifest,
+		},
+		{
+			name:        "media type of manifest",
+			contentType: "application/octet-stream",
+			body:        `{"mediaType":"` + manifestlist.MediaTypeManifestList + `"}`,
+			expect:      manifestlist.MediaTypeManifestList,
+		},
+		{
+			name:        "OCI image index without media type",
+			contentType: "application/octet-stream",
+			body:        `{"manifests":[]}`,
+			expect:      specs.MediaTypeImageIndex,
+		},
+		{
+			name:        "OCI image manifest without media type",
+			contentType: "application/octet-stream",
+			body:        `{"layers":[]}`,
+			expect:      specs.MediaTypeImageManifest,
+		},
+		{
+			name:        "invalid manifest",
+			contentType: "application/octet-stream",
+			body:        "foo",
+			expect:      "application/octet-stream",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tc.expect, ManifestMediaType(tc.contentType, []byte(tc.body)))
+		})
+	}
+}
+
+func TestLayers(t *testing.T) {
+	assert := assert.New(t)
+	manifest, err := UnmarshalManifest(specs.MediaTypeImageManifest, []byte(`{"schemaVersion":2,"config":{"digest":"sha256:a"},"layers":[{"digest":"sha256:b","size":1}]}`))
+	assert.NoError(err)
+	layers := Layers(manifest)
+	assert.Len(layers, 1)
+	assert.Equal("sha256:b", layers[0].Digest.String())
+
+	manifest, err = UnmarshalManifest(specs.MediaTypeImageIndex, []byte(`{"schemaVersion":2,"manifests":[{"digest":"sha256:c"}]}`))
+	assert.NoError(err)
+	assert.Nil(Layers(manifest))
+}
@@ -23,3 +23,9 @@ var (
 	// BeginOfPiece is the number of begin piece.
 	BeginOfPiece = int32(-1)
 )
+
+const (
+	// CancelSeedOnDisconnectKey is the grpc metadata key of ObtainSeeds request, the seed peer
+	// cancels the seed task if the request is canceled by client before the task is finished.
+	CancelSeedOnDisconnectKey = "dragonfly-cancel-seed-on-disconnect"
+)
@@ -0,0 +1,133 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.4
+// source: pkg/rpc/scheduler/cluster/cluster.proto
+
+package cluster
+
+import (
+	context "context"
+	v1 "d7y.io/api/pkg/apis/scheduler/v1"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ClusterClient is the client API for Cluster service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ClusterClient interface {
+	// ListTaskPeers streams the succeeded peers of the task as announce task requests.
+	ListTaskPeers(ctx context.Context, in *v1.StatTaskRequest, opts ...grpc.CallOption) (Cluster_ListTaskPeersClient, error)
+}
+
+type clusterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClusterClient(cc grpc.ClientConnInterface) ClusterClient {
+	return &clusterClient{cc}
+}
+
+func (c *clusterClient) ListTaskPeers(ctx context.Context, in *v1.StatTaskRequest, opts ...grpc.CallOption) (Cluster_ListTaskPeersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Cluster_ServiceDesc.Streams[0], "/scheduler.cluster.v1.Cluster/ListTaskPeers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &clusterListTaskPeersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cluster_ListTaskPeersClient interface {
+	Recv() (*v1.AnnounceTaskRequest, error)
+	grpc.ClientStream
+}
+
+type clusterListTaskPeersClient struct {
+	grpc.ClientStream
+}
+
+func (x *clusterListTaskPeersClient) Recv() (*v1.AnnounceTaskRequest, error) {
+	m := new(v1.AnnounceTaskRequest)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ClusterServer is the server API for Cluster service.
+// All implementations should embed UnimplementedClusterServer
+// for forward compatibility
+type ClusterServer interface {
+	// ListTaskPeers streams the succeeded peers of the task as announce task requests.
+	ListTaskPeers(*v1.StatTaskRequest, Cluster_ListTaskPeersServer) error
+}
+
+// UnimplementedClusterServer should be embedded to have forward compatible implementations.
+type UnimplementedClusterServer struct {
+}
+
+func (UnimplementedClusterServer) ListTaskPeers(*v1.StatTaskRequest, Cluster_ListTaskPeersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListTaskPeers not implemented")
+}
+
+// UnsafeClusterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClusterServer will
+// result in compilation errors.
+type UnsafeClusterServer interface {
+	mustEmbedUnimplementedClusterServer()
+}
+
+func RegisterClusterServer(s grpc.ServiceRegistrar, srv ClusterServer) {
+	s.RegisterService(&Cluster_ServiceDesc, srv)
+}
+
+func _Cluster_ListTaskPeers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.StatTaskRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ClusterServer).ListTaskPeers(m, &clusterListTaskPeersServer{stream})
+}
+
+type Cluster_ListTaskPeersServer interface {
+	Send(*v1.AnnounceTaskRequest) error
+	grpc.ServerStream
+}
+
+type clusterListTaskPeersServer struct {
+	grpc.ServerStream
+}
+
+func (x *clusterListTaskPeersServer) Send(m *v1.AnnounceTaskRequest) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Cluster_ServiceDesc is the grpc.ServiceDesc for Cluster service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cluster_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scheduler.cluster.v1.Cluster",
+	HandlerType: (*ClusterServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListTaskPeers",
+			Handler:       _Cluster_ListTaskPeers_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/rpc/scheduler/cluster/cluster.proto",
+}
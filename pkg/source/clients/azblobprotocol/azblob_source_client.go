@@ -0,0 +1,534 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azblobprotocol
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-http-utils/headers"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const (
+	AzureBlobScheme      = "azblob"
+	AzureBlobShortScheme = "az"
+)
+
+const (
+	// Azure storage account name
+	accountName = "azureAccountName"
+	// Azure storage account key, it is used to sign the request by shared key
+	accountKey = "azureAccountKey"
+	// Azure shared access signature token
+	sasToken = "azureSASToken"
+	// Azure blob service endpoint, default is https://<account>.blob.core.windows.net,
+	// it is used for the sovereign cloud and emulator.
+	endpoint = "azureEndpoint"
+)
+
+const (
+	// apiVersion is the version of Azure blob service REST API.
+	apiVersion = "2020-10-02"
+
+	// headerPrefix is the prefix of Azure storage headers.
+	headerPrefix = "x-ms-"
+
+	// listMaxResults is the max count of blobs listed at once.
+	listMaxResults = 1000
+)
+
+var _ source.ResourceClient = (*azblobSourceClient)(nil)
+var _ source.ResourceMetadataGetter = (*azblobSourceClient)(nil)
+var _ source.ResourceLister = (*azblobSourceClient)(nil)
+
+func init() {
+	sourceClient := NewAzureBlobSourceClient()
+	if err := source.Register(AzureBlobScheme, sourceClient, adaptor); err != nil {
+		panic(err)
+	}
+
+	if err := source.Register(AzureBlobShortScheme, sourceClient, adaptor); err != nil {
+		panic(err)
+	}
+}
+
+func adaptor(request *source.Request) *source.Request {
+	clonedRequest := request.Clone(request.Context())
+	if request.Header.Get(source.Range) != "" {
+		clonedRequest.Header.Set(headers.Range, fmt.Sprintf("bytes=%s", request.Header.Get(source.Range)))
+		clonedRequest.Header.Del(source.Range)
+	}
+	return clonedRequest
+}
+
+func NewAzureBlobSourceClient(opts ...AzureBlobSourceClientOption) source.ResourceClient {
+	return newAzureBlobSourceClient(opts...)
+}
+
+func newAzureBlobSourceClient(opts ...AzureBlobSourceClientOption) *azblobSourceClient {
+	sourceClient := &azblobSourceClient{
+		httpClient: http.DefaultClient,
+	}
+	for i := range opts {
+		opts[i](sourceClient)
+	}
+	return sourceClient
+}
+
+type AzureBlobSourceClientOption func(p *azblobSourceClient)
+
+// WithHTTPClient set the http client of Azure blob service.
+func WithHTTPClient(client *http.Client) AzureBlobSourceClientOption {
+	return func(sourceClient *azblobSourceClient) {
+		sourceClient.httpClient = client
+	}
+}
+
+// azblobSourceClient is an implementation of the interface of source.ResourceClient
+// with Azure blob service REST API, the host of url is the container and the path is the blob.
+type azblobSourceClient struct {
+	httpClient *http.Client
+}
+
+// enumerationResults is the response of listing blobs of Azure blob service.
+type enumerationResults struct {
+	Blobs struct {
+		Blob []struct {
+			Name string `xml:"Name"`
+		} `xml:"Blob"`
+		BlobPrefix []struct {
+			Name string `xml:"Name"`
+		} `xml:"BlobPrefix"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// GetContentLength get length of resource content
+// return source.UnknownSourceFileLen if response status is not StatusOK and StatusPartialContent
+func (a *azblobSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	header, err := a.getProperties(request)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+
+	contentLength, err := strconv.ParseInt(header.Get(headers.ContentLength), 10, 64)
+	if err != nil {
+		return source.UnknownSourceFileLen, fmt.Errorf("parse content-length str to int64: %w", err)
+	}
+	return contentLength, nil
+}
+
+// IsSupportRange checks if resource supports breakpoint continuation
+// return false if response status is not StatusPartialContent
+func (a *azblobSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	if _, err := a.getProperties(request); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetMetadata gets the metadata of the blob.
+func (a *azblobSourceClient) GetMetadata(request *source.Request) (*source.Metadata, error) {
+	header, err := a.getProperties(request)
+	if err != nil {
+		return nil, err
+	}
+
+	totalContentLength, err := strconv.ParseInt(header.Get(headers.ContentLength), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse content-length str to int64: %w", err)
+	}
+
+	hdr := source.Header{}
+	for k, v := range header {
+		if len(v) > 0 {
+			hdr.Set(k, v[0])
+		}
+	}
+	return &source.Metadata{
+		Header:             hdr,
+		Status:             http.StatusText(http.StatusOK),
+		StatusCode:         http.StatusOK,
+		SupportRange:       true,
+		TotalContentLength: totalContentLength,
+		Validate: func() error {
+			return nil
+		},
+		Temporary: true,
+	}, nil
+}
+
+// IsExpired checks if a resource received or stored is the same.
+func (a *azblobSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	header, err := a.getProperties(request)
+	if err != nil {
+		return false, err
+	}
+	return !(header.Get(headers.ETag) == info.ETag || header.Get(headers.LastModified) == info.LastModified), nil
+}
+
+// Download downloads from source
+func (a *azblobSourceClient) Download(request *source.Request) (*source.Response, error) {
+	u, err := a.blobURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.do(request, http.MethodGet, u)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK, http.StatusPartialContent}); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return source.NewResponse(
+		resp.Body,
+		source.WithStatus(resp.StatusCode, resp.Status),
+		source.WithContentLength(resp.ContentLength),
+		source.WithExpireInfo(
+			source.ExpireInfo{
+				LastModified: resp.Header.Get(headers.LastModified),
+				ETag:         resp.Header.Get(headers.ETag),
+			},
+		)), nil
+}
+
+// GetLastModified gets last modified timestamp milliseconds of resource
+func (a *azblobSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	header, err := a.getProperties(request)
+	if err != nil {
+		return -1, err
+	}
+
+	lastModified, err := time.ParseInLocation(source.TimeFormat, header.Get(headers.LastModified), time.UTC)
+	if err != nil {
+		return -1, err
+	}
+	return lastModified.UnixMilli(), nil
+}
+
+// List lists the blobs and virtual directories of the directory.
+func (a *azblobSourceClient) List(request *source.Request) (urls []source.URLEntry, err error) {
+	// if it's a blob, just return it.
+	isDir, err := a.isDirectory(request)
+	if err != nil {
+		return nil, err
+	}
+	// if request is a single file, just return
+	if !isDir {
+		return []source.URLEntry{buildURLEntry(false, request.URL)}, nil
+	}
+
+	// list all files and subdirectory
+	prefix := strings.TrimPrefix(addTrailingSlash(request.URL.Path), "/")
+	var marker string
+	for {
+		results, err := a.listBlobs(request, prefix, marker, listMaxResults)
+		if err != nil {
+			return urls, err
+		}
+
+		for _, blob := range results.Blobs.Blob {
+			if blob.Name != prefix {
+				url := *request.URL
+				url.Path = addLeadingSlash(blob.Name)
+				urls = append(urls, buildURLEntry(false, &url))
+			}
+		}
+
+		for _, prefix := range results.Blobs.BlobPrefix {
+			url := *request.URL
+			url.Path = addLeadingSlash(prefix.Name)
+			urls = append(urls, buildURLEntry(true, &url))
+		}
+
+		if results.NextMarker == "" {
+			break
+		}
+		marker = results.NextMarker
+	}
+	return urls, nil
+}
+
+func (a *azblobSourceClient) isDirectory(request *source.Request) (bool, error) {
+	prefix := strings.TrimPrefix(addTrailingSlash(request.URL.Path), "/")
+	results, err := a.listBlobs(request, prefix, "", 1)
+	if err != nil {
+		return false, err
+	}
+	if len(results.Blobs.Blob)+len(results.Blobs.BlobPrefix) > 0 {
+		return true, nil
+	}
+	return false, nil
+}
+
+// listBlobs lists the blobs and prefixes with the prefix in container.
+func (a *azblobSourceClient) listBlobs(request *source.Request, prefix, marker string, maxResults int) (*enumerationResults, error) {
+	u, err := a.containerURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Set("restype", "container")
+	query.Set("comp", "list")
+	query.Set("prefix", prefix)
+	query.Set("delimiter", "/")
+	query.Set("maxresults", strconv.Itoa(maxResults))
+	if marker != "" {
+		query.Set("marker", marker)
+	}
+	u.RawQuery = query.Encode()
+
+	resp, err := a.do(request, http.MethodGet, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK}); err != nil {
+		return nil, fmt.Errorf("list azure blob %s/%s: %w", request.URL.Host, prefix, err)
+	}
+
+	results := &enumerationResults{}
+	if err := xml.NewDecoder(resp.Body).Decode(results); err != nil {
+		return nil, fmt.Errorf("decode azure blobs: %w", err)
+	}
+	return results, nil
+}
+
+// getProperties gets the properties of the blob in response header.
+func (a *azblobSourceClient) getProperties(request *source.Request) (http.Header, error) {
+	u, err := a.blobURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.do(request, http.MethodHead, u)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK}); err != nil {
+		return nil, fmt.Errorf("get azure blob %s%s properties: %w", request.URL.Host, request.URL.Path, err)
+	}
+	return resp.Header, nil
+}
+
+// do sends the request of Azure blob service with the range and authorization.
+func (a *azblobSourceClient) do(request *source.Request, method string, u *url.URL) (*http.Response, error) {
+	if token := strings.TrimPrefix(request.Header.Get(sasToken), "?"); token != "" {
+		sas, err := url.ParseQuery(token)
+		if err != nil {
+			return nil, fmt.Errorf("parse azure sas token: %w", err)
+		}
+
+		query := u.Query()
+		for k, v := range sas {
+			query[k] = v
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(request.Context(), method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("x-ms-version", apiVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	if r := request.Header.Get(headers.Range); r != "" && method == http.MethodGet {
+		req.Header.Set(headers.Range, r)
+	}
+
+	// Shared access signature is preferred to shared key.
+	if key := request.Header.Get(accountKey); key != "" && request.Header.Get(sasToken) == "" {
+		authorization, err := sign(request.Header.Get(accountName), key, req)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(headers.Authorization, authorization)
+	}
+
+	return a.httpClient.Do(req)
+}
+
+// containerURL returns the container url of Azure blob service.
+func (a *azblobSourceClient) containerURL(request *source.Request) (*url.URL, error) {
+	if request.URL.Host == "" {
+		return nil, errors.New("container is empty")
+	}
+
+	e := request.Header.Get(endpoint)
+	if e == "" {
+		name := request.Header.Get(accountName)
+		if name == "" {
+			return nil, errors.New("azureAccountName is empty")
+		}
+		e = fmt.Sprintf("https://%s.blob.core.windows.net", name)
+	}
+
+	u, err := url.Parse(e)
+	if err != nil {
+		return nil, fmt.Errorf("parse azure endpoint %s: %w", e, err)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + request.URL.Host
+	return u, nil
+}
+
+// blobURL returns the blob url of Azure blob service.
+func (a *azblobSourceClient) blobURL(request *source.Request) (*url.URL, error) {
+	blob := strings.TrimPrefix(request.URL.Path, "/")
+	if blob == "" {
+		return nil, errors.New("blob is empty")
+	}
+
+	u, err := a.containerURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path += "/" + blob
+	return u, nil
+}
+
+// sign returns the authorization of shared key, refer to
+// https://learn.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key.
+func sign(name, key string, req *http.Request) (string, error) {
+	if name == "" {
+		return "", errors.New("azureAccountName is empty")
+	}
+
+	decodedKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", fmt.Errorf("decode azure account key: %w", err)
+	}
+
+	contentLength := req.Header.Get(headers.ContentLength)
+	if contentLength == "0" {
+		contentLength = ""
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get(headers.ContentEncoding),
+		req.Header.Get(headers.ContentLanguage),
+		contentLength,
+		req.Header.Get(headers.ContentMD5),
+		req.Header.Get(headers.ContentType),
+		// Date is empty when x-ms-date is set.
+		"",
+		req.Header.Get(headers.IfModifiedSince),
+		req.Header.Get(headers.IfMatch),
+		req.Header.Get(headers.IfNoneMatch),
+		req.Header.Get(headers.IfUnmodifiedSince),
+		req.Header.Get(headers.Range),
+		canonicalizedHeaders(req.Header) + canonicalizedResource(name, req.URL),
+	}, "\n")
+
+	h := hmac.New(sha256.New, decodedKey)
+	h.Write([]byte(stringToSign))
+	return fmt.Sprintf("SharedKey %s:%s", name, base64.StdEncoding.EncodeToString(h.Sum(nil))), nil
+}
+
+// canonicalizedHeaders returns the sorted x-ms- headers with the line break.
+func canonicalizedHeaders(header http.Header) string {
+	var keys []string
+	for k := range header {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, headerPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteString(":")
+		b.WriteString(strings.TrimSpace(header.Get(k)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// canonicalizedResource returns the account, the path and the sorted query of url.
+func canonicalizedResource(name string, u *url.URL) string {
+	var b strings.Builder
+	b.WriteString("/")
+	b.WriteString(name)
+	b.WriteString(u.EscapedPath())
+
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		b.WriteString("\n")
+		b.WriteString(strings.ToLower(k))
+		b.WriteString(":")
+		b.WriteString(strings.Join(values, ","))
+	}
+	return b.String()
+}
+
+func buildURLEntry(isDir bool, url *url.URL) source.URLEntry {
+	if isDir {
+		url.Path = addTrailingSlash(url.Path)
+		list := strings.Split(url.Path, "/")
+		return source.URLEntry{URL: url, Name: list[len(list)-2], IsDir: true}
+	}
+	_, name := filepath.Split(url.Path)
+	return source.URLEntry{URL: url, Name: name, IsDir: false}
+}
+
+func addLeadingSlash(s string) string {
+	if strings.HasPrefix(s, "/") {
+		return s
+	}
+	return "/" + s
+}
+
+func addTrailingSlash(s string) string {
+	if strings.HasSuffix(s, "/") {
+		return s
+	}
+	return s + "/"
+}
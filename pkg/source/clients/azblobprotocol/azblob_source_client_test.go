@@ -0,0 +1,266 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azblobprotocol
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+var (
+	testContent      = "l am test case"
+	testLastModified = time.Date(2021, 6, 6, 12, 52, 30, 0, time.UTC)
+	testETag         = "0x8D9A2F0E5D5C7C1"
+	testAccountName  = "devstoreaccount1"
+	testAccountKey   = base64.StdEncoding.EncodeToString([]byte("foo"))
+	testSASToken     = "sv=2020-10-02&sp=rl&sig=bar"
+)
+
+// newAzureBlobServer returns a fake server of Azure blob service with the blobs in container.
+func newAzureBlobServer(t *testing.T, blobs []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sig") != "bar" {
+			authorization, err := sign(testAccountName, testAccountKey, r)
+			assert.NoError(t, err)
+			if r.Header.Get(headers.Authorization) != authorization {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+
+		if r.URL.Path == "/container" {
+			query := r.URL.Query()
+			assert.Equal(t, "container", query.Get("restype"))
+			assert.Equal(t, "list", query.Get("comp"))
+
+			prefix := query.Get("prefix")
+			var b strings.Builder
+			b.WriteString("<EnumerationResults><Blobs>")
+			for _, name := range blobs {
+				if !strings.HasPrefix(name, prefix) {
+					continue
+				}
+
+				if i := strings.Index(name[len(prefix):], "/"); i >= 0 {
+					fmt.Fprintf(&b, "<BlobPrefix><Name>%s</Name></BlobPrefix>", name[:len(prefix)+i+1])
+					continue
+				}
+				fmt.Fprintf(&b, "<Blob><Name>%s</Name></Blob>", name)
+			}
+			b.WriteString("</Blobs><NextMarker/></EnumerationResults>")
+			fmt.Fprint(w, xml.Header+b.String())
+			return
+		}
+
+		if r.URL.Path != "/container/dir/foo bar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set(headers.ETag, testETag)
+		w.Header().Set(headers.LastModified, testLastModified.Format(source.TimeFormat))
+		if r.Header.Get(headers.Range) == "bytes=0-3" {
+			w.Header().Set(headers.ContentLength, "4")
+			w.WriteHeader(http.StatusPartialContent)
+			fmt.Fprint(w, testContent[:4])
+			return
+		}
+
+		w.Header().Set(headers.ContentLength, fmt.Sprint(len(testContent)))
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, testContent)
+		}
+	}))
+}
+
+func TestAzureBlobSourceClient(t *testing.T) {
+	server := newAzureBlobServer(t, []string{"dir/foo bar", "dir/sub/baz"})
+	defer server.Close()
+
+	client := newAzureBlobSourceClient()
+	newRequest := func(rawURL string, header map[string]string) *source.Request {
+		request, err := source.NewRequestWithContext(context.Background(), rawURL, header)
+		assert.NoError(t, err)
+		request.Header.Set(endpoint, server.URL)
+		request.Header.Set(accountName, testAccountName)
+		request.Header.Set(accountKey, testAccountKey)
+		return adaptor(request)
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "get content length",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				contentLength, err := client.GetContentLength(newRequest("azblob://container/dir/foo bar", nil))
+				assert.NoError(err)
+				assert.Equal(int64(len(testContent)), contentLength)
+			},
+		},
+		{
+			name: "get metadata",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				metadata, err := client.GetMetadata(newRequest("azblob://container/dir/foo bar", nil))
+				assert.NoError(err)
+				assert.True(metadata.SupportRange)
+				assert.Equal(int64(len(testContent)), metadata.TotalContentLength)
+				assert.Equal(testETag, metadata.Header.Get(headers.ETag))
+			},
+		},
+		{
+			name: "get last modified",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				lastModified, err := client.GetLastModified(newRequest("az://container/dir/foo bar", nil))
+				assert.NoError(err)
+				assert.Equal(testLastModified.UnixMilli(), lastModified)
+			},
+		},
+		{
+			name: "is expired",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				expired, err := client.IsExpired(newRequest("azblob://container/dir/foo bar", nil), &source.ExpireInfo{ETag: testETag})
+				assert.NoError(err)
+				assert.False(expired)
+
+				expired, err = client.IsExpired(newRequest("azblob://container/dir/foo bar", nil), &source.ExpireInfo{ETag: "foo"})
+				assert.NoError(err)
+				assert.True(expired)
+			},
+		},
+		{
+			name: "download",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				resp, err := client.Download(newRequest("azblob://container/dir/foo bar", nil))
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testContent, string(data))
+				assert.Equal(testETag, resp.ExpireInfo().ETag)
+			},
+		},
+		{
+			name: "download with range",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				resp, err := client.Download(newRequest("azblob://container/dir/foo bar", map[string]string{source.Range: "0-3"}))
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(http.StatusPartialContent, resp.StatusCode)
+				assert.Equal(testContent[:4], string(data))
+			},
+		},
+		{
+			name: "download with sas token",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				request := newRequest("azblob://container/dir/foo bar", nil)
+				request.Header.Del(accountKey)
+				request.Header.Set(sasToken, "?"+testSASToken)
+				resp, err := client.Download(request)
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testContent, string(data))
+			},
+		},
+		{
+			name: "invalid account key",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				request := newRequest("azblob://container/dir/foo bar", nil)
+				request.Header.Set(accountKey, base64.StdEncoding.EncodeToString([]byte("bar")))
+				_, err := client.GetContentLength(request)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "blob not found",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				_, err := client.GetContentLength(newRequest("azblob://container/dir/foo", nil))
+				assert.Error(err)
+
+				_, err = client.Download(newRequest("azblob://container/dir/foo", nil))
+				assert.Error(err)
+			},
+		},
+		{
+			name: "list directory",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				entries, err := client.List(newRequest("azblob://container/dir", nil))
+				assert.NoError(err)
+				assert.Len(entries, 2)
+				assert.Equal("foo bar", entries[0].Name)
+				assert.False(entries[0].IsDir)
+				assert.Equal("/dir/foo bar", entries[0].URL.Path)
+				assert.Equal("sub", entries[1].Name)
+				assert.True(entries[1].IsDir)
+				assert.Equal("/dir/sub/", entries[1].URL.Path)
+			},
+		},
+		{
+			name: "list blob",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				entries, err := client.List(newRequest("azblob://container/dir/foo bar", nil))
+				assert.NoError(err)
+				assert.Len(entries, 1)
+				assert.Equal("foo bar", entries[0].Name)
+				assert.False(entries[0].IsDir)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.run)
+	}
+}
+
+func TestCanonicalizedResource(t *testing.T) {
+	assert := assert.New(t)
+	request, err := http.NewRequest(http.MethodGet, "https://foo.blob.core.windows.net/container?restype=container&comp=list&prefix=a%2Fb&include=metadata&include=copy", nil)
+	assert.NoError(err)
+	assert.Equal("/foo/container\ncomp:list\ninclude:copy,metadata\nprefix:a/b\nrestype:container", canonicalizedResource("foo", request.URL))
+}
@@ -0,0 +1,317 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileprotocol
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/go-http-utils/headers"
+
+	"d7y.io/dragonfly/v2/pkg/net/http"
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const FileScheme = "file"
+
+var _ source.ResourceClient = (*fileSourceClient)(nil)
+var _ source.ResourceMetadataGetter = (*fileSourceClient)(nil)
+var _ source.ResourceLister = (*fileSourceClient)(nil)
+
+func init() {
+	if err := source.Register(FileScheme, NewFileSourceClient(), Adapter); err != nil {
+		panic(err)
+	}
+}
+
+// Adapter keeps the range of request, which is parsed by the file length when downloading.
+func Adapter(request *source.Request) *source.Request {
+	clonedRequest := request.Clone(request.Context())
+	return clonedRequest
+}
+
+func NewFileSourceClient(opts ...FileSourceClientOption) source.ResourceClient {
+	return newFileSourceClient(opts...)
+}
+
+func newFileSourceClient(opts ...FileSourceClientOption) *fileSourceClient {
+	sourceClient := &fileSourceClient{}
+	for i := range opts {
+		opts[i](sourceClient)
+	}
+	return sourceClient
+}
+
+type FileSourceClientOption func(p *fileSourceClient)
+
+// WithAllowedDirs restricts the files read by client in the directories, like the mounted NFS directories,
+// all files are denied if dirs is empty. It must be used when the client reads files for other peers.
+func WithAllowedDirs(dirs ...string) FileSourceClientOption {
+	return func(sourceClient *fileSourceClient) {
+		sourceClient.restricted = true
+		sourceClient.allowedDirs = dirs
+	}
+}
+
+// fileSourceClient is an implementation of the interface of source.ResourceClient
+// with local filesystem, the url is file:///<absolute path>.
+type fileSourceClient struct {
+	restricted  bool
+	allowedDirs []string
+}
+
+// fileReadCloser reads the range of file and closes the file.
+type fileReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// GetContentLength get length of resource content
+// return source.UnknownSourceFileLen if response status is not StatusOK and StatusPartialContent
+func (f *fileSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	_, info, err := f.statFile(request.URL)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+	return info.Size(), nil
+}
+
+// IsSupportRange checks if resource supports breakpoint continuation
+// return false if response status is not StatusPartialContent
+func (f *fileSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	if _, _, err := f.statFile(request.URL); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetMetadata gets the metadata of the file.
+func (f *fileSourceClient) GetMetadata(request *source.Request) (*source.Metadata, error) {
+	_, info, err := f.statFile(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := source.Header{}
+	hdr.Set(headers.ContentLength, strconv.FormatInt(info.Size(), 10))
+	hdr.Set(headers.LastModified, info.ModTime().UTC().Format(source.TimeFormat))
+	hdr.Set(headers.ETag, etag(info))
+	return &source.Metadata{
+		Header:             hdr,
+		Status:             "OK",
+		StatusCode:         200,
+		SupportRange:       true,
+		TotalContentLength: info.Size(),
+		Validate: func() error {
+			return nil
+		},
+		Temporary: true,
+	}, nil
+}
+
+// IsExpired checks if a resource received or stored is the same, the file is expired
+// when the inode, size or modification time of file is changed.
+func (f *fileSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	_, fileInfo, err := f.statFile(request.URL)
+	if err != nil {
+		return false, err
+	}
+
+	if info.ETag != "" {
+		return etag(fileInfo) != info.ETag, nil
+	}
+	return fileInfo.ModTime().UTC().Format(source.TimeFormat) != info.LastModified, nil
+}
+
+// Download downloads from source
+func (f *fileSourceClient) Download(request *source.Request) (*source.Response, error) {
+	path, info, err := f.statFile(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// default read all data when rang is nil
+	limitReadN := info.Size()
+	if request.Header.Get(source.Range) != "" {
+		requestRange, err := http.ParseRange(request.Header.Get(source.Range), uint64(limitReadN))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		if _, err := file.Seek(int64(requestRange.StartIndex), io.SeekStart); err != nil {
+			file.Close()
+			return nil, err
+		}
+		limitReadN = int64(requestRange.Length())
+	}
+
+	return source.NewResponse(
+		&fileReadCloser{Reader: io.LimitReader(file, limitReadN), Closer: file},
+		source.WithContentLength(limitReadN),
+		source.WithExpireInfo(source.ExpireInfo{
+			LastModified: info.ModTime().UTC().Format(source.TimeFormat),
+			ETag:         etag(info),
+		})), nil
+}
+
+// GetLastModified gets last modified timestamp milliseconds of resource
+func (f *fileSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	_, info, err := f.statFile(request.URL)
+	if err != nil {
+		return -1, err
+	}
+	return info.ModTime().UnixMilli(), nil
+}
+
+// List lists the regular files and sub directories of the directory,
+// the symbolic links are listed as the files or directories they point to.
+func (f *fileSourceClient) List(request *source.Request) (urls []source.URLEntry, err error) {
+	path, err := f.resolvePath(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// if request is a single file, just return
+	if !info.IsDir() {
+		return []source.URLEntry{buildURLEntry(false, request.URL)}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		url := *request.URL
+		url.Path = filepath.Join(request.URL.Path, entry.Name())
+		url.RawPath = ""
+
+		// the target of symbolic link must be in the allowed directories.
+		entryPath, err := f.resolvePath(&url)
+		if err != nil {
+			continue
+		}
+
+		entryInfo, err := os.Stat(entryPath)
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case entryInfo.IsDir():
+			urls = append(urls, buildURLEntry(true, &url))
+		case entryInfo.Mode().IsRegular():
+			urls = append(urls, buildURLEntry(false, &url))
+		}
+	}
+	return urls, nil
+}
+
+// statFile returns the resolved path and the info of regular file.
+func (f *fileSourceClient) statFile(u *url.URL) (string, os.FileInfo, error) {
+	path, err := f.resolvePath(u)
+	if err != nil {
+		return "", nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !info.Mode().IsRegular() {
+		return "", nil, fmt.Errorf("%s is not a regular file", u.Path)
+	}
+	return path, info, nil
+}
+
+// resolvePath returns the path of url with symbolic links evaluated,
+// the path must be in the allowed directories if the client is restricted.
+func (f *fileSourceClient) resolvePath(u *url.URL) (string, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("remote host %s of file url is not supported", u.Host)
+	}
+
+	if !filepath.IsAbs(u.Path) {
+		return "", fmt.Errorf("path %s is not absolute", u.Path)
+	}
+
+	path := filepath.Clean(u.Path)
+	if !f.restricted {
+		return path, nil
+	}
+
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, dir := range f.allowedDirs {
+		allowedDir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			allowedDir = filepath.Clean(dir)
+		}
+
+		if path == allowedDir || strings.HasPrefix(path, addTrailingSlash(allowedDir)) {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not in allowed directories: %w", u.Path, os.ErrPermission)
+}
+
+// etag returns the entity tag of file by the inode, size and modification time.
+func etag(info os.FileInfo) string {
+	var ino uint64
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		ino = uint64(stat.Ino)
+	}
+	return fmt.Sprintf("%x-%x-%x", ino, info.Size(), info.ModTime().UnixNano())
+}
+
+func buildURLEntry(isDir bool, url *url.URL) source.URLEntry {
+	if isDir {
+		url.Path = addTrailingSlash(url.Path)
+		list := strings.Split(url.Path, "/")
+		return source.URLEntry{URL: url, Name: list[len(list)-2], IsDir: true}
+	}
+	_, name := filepath.Split(url.Path)
+	return source.URLEntry{URL: url, Name: name, IsDir: false}
+}
+
+func addTrailingSlash(s string) string {
+	if strings.HasSuffix(s, "/") {
+		return s
+	}
+	return s + "/"
+}
@@ -0,0 +1,261 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileprotocol
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+func newRequest(t *testing.T, path string, header map[string]string) *source.Request {
+	request, err := source.NewRequestWithContext(context.Background(), (&url.URL{Scheme: FileScheme, Path: path}).String(), header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
+
+func TestFileSourceClient_Download(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo")
+	if err := os.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		expect func(t *testing.T, data []byte, err error)
+	}{
+		{
+			name: "download all",
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("0123456789", string(data))
+			},
+		},
+		{
+			name:   "download range",
+			header: map[string]string{source.Range: "2-5"},
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("2345", string(data))
+			},
+		},
+		{
+			name:   "invalid range",
+			header: map[string]string{source.Range: "20-30"},
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	client := newFileSourceClient()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := client.Download(newRequest(t, path, tc.header))
+			if err != nil {
+				tc.expect(t, nil, err)
+				return
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			tc.expect(t, data, err)
+		})
+	}
+}
+
+func TestFileSourceClient_IsExpired(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "foo")
+	if err := os.WriteFile(path, []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := newFileSourceClient()
+	request := newRequest(t, path, nil)
+	resp, err := client.Download(request)
+	assert.NoError(err)
+	resp.Body.Close()
+
+	expireInfo := resp.ExpireInfo()
+	expired, err := client.IsExpired(request, &expireInfo)
+	assert.NoError(err)
+	assert.False(expired)
+
+	// the size and modification time of file are changed
+	if err := os.WriteFile(path, []byte("foobar"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expired, err = client.IsExpired(request, &expireInfo)
+	assert.NoError(err)
+	assert.True(expired)
+
+	// the inode of file is changed with the same size and modification time
+	info, err := os.Stat(path)
+	assert.NoError(err)
+	resp, err = client.Download(request)
+	assert.NoError(err)
+	resp.Body.Close()
+	expireInfo = resp.ExpireInfo()
+
+	tmp := filepath.Join(dir, "bar")
+	assert.NoError(os.WriteFile(tmp, []byte("foobaz"), 0644))
+	assert.NoError(os.Chtimes(tmp, info.ModTime(), info.ModTime()))
+	assert.NoError(os.Rename(tmp, path))
+	expired, err = client.IsExpired(request, &expireInfo)
+	assert.NoError(err)
+	assert.True(expired)
+
+	// fall back to the modification time without etag
+	expired, err = client.IsExpired(request, &source.ExpireInfo{LastModified: info.ModTime().UTC().Format(source.TimeFormat)})
+	assert.NoError(err)
+	assert.False(expired)
+
+	lastModified, err := client.GetLastModified(request)
+	assert.NoError(err)
+	assert.Equal(info.ModTime().UnixMilli(), lastModified)
+}
+
+func TestFileSourceClient_List(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "foo"), []byte("foo"), 0644))
+	assert.NoError(os.Symlink(filepath.Join(dir, "foo"), filepath.Join(dir, "bar")))
+	assert.NoError(os.Symlink(filepath.Join(dir, "none"), filepath.Join(dir, "broken")))
+
+	client := newFileSourceClient()
+	entries, err := client.List(newRequest(t, dir, nil))
+	assert.NoError(err)
+
+	names := map[string]bool{}
+	for _, entry := range entries {
+		names[entry.Name] = entry.IsDir
+	}
+	assert.Equal(map[string]bool{"bar": false, "foo": false, "sub": true}, names)
+
+	entries, err = client.List(newRequest(t, filepath.Join(dir, "foo"), nil))
+	assert.NoError(err)
+	assert.Len(entries, 1)
+	assert.Equal("foo", entries[0].Name)
+}
+
+func TestFileSourceClient_AllowedDirs(t *testing.T) {
+	allowedDir := t.TempDir()
+	deniedDir := t.TempDir()
+	for _, dir := range []string{allowedDir, deniedDir} {
+		if err := os.WriteFile(filepath.Join(dir, "foo"), []byte("foo"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(deniedDir, "foo"), filepath.Join(allowedDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		client *fileSourceClient
+		path   string
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name:   "file in allowed directory",
+			client: newFileSourceClient(WithAllowedDirs(allowedDir)),
+			path:   filepath.Join(allowedDir, "foo"),
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:   "file not in allowed directory",
+			client: newFileSourceClient(WithAllowedDirs(allowedDir)),
+			path:   filepath.Join(deniedDir, "foo"),
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, os.ErrPermission)
+			},
+		},
+		{
+			name:   "symbolic link escapes allowed directory",
+			client: newFileSourceClient(WithAllowedDirs(allowedDir)),
+			path:   filepath.Join(allowedDir, "link"),
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, os.ErrPermission)
+			},
+		},
+		{
+			name:   "path escapes allowed directory",
+			client: newFileSourceClient(WithAllowedDirs(allowedDir)),
+			path:   allowedDir + "/../" + filepath.Base(deniedDir) + "/foo",
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, os.ErrPermission)
+			},
+		},
+		{
+			name:   "no allowed directory",
+			client: newFileSourceClient(WithAllowedDirs()),
+			path:   filepath.Join(allowedDir, "foo"),
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, os.ErrPermission)
+			},
+		},
+		{
+			name:   "unrestricted client",
+			client: newFileSourceClient(),
+			path:   filepath.Join(deniedDir, "foo"),
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.client.GetContentLength(newRequest(t, tc.path, nil))
+			tc.expect(t, err)
+		})
+	}
+}
+
+func TestFileSourceClient_RemoteHost(t *testing.T) {
+	assert := assert.New(t)
+	request, err := source.NewRequest("file://example.com/foo")
+	assert.NoError(err)
+
+	_, err = newFileSourceClient().GetContentLength(request)
+	assert.Error(err)
+}
@@ -0,0 +1,463 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcsprotocol
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-http-utils/headers"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const GCSScheme = "gs"
+
+const (
+	// GCS endpoint, default is https://storage.googleapis.com,
+	// it is used for the compatible storage and emulator.
+	endpoint = "gcsEndpoint"
+	// GCS OAuth2 access token
+	accessToken = "gcsAccessToken"
+	// GCS service account or authorized user credentials in json
+	credentials = "gcsCredentials"
+)
+
+const (
+	// defaultEndpoint is the endpoint of GCS JSON API.
+	defaultEndpoint = "https://storage.googleapis.com"
+
+	// readOnlyScope is the OAuth2 scope of reading objects.
+	readOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+
+	// listMaxResults is the max count of objects listed at once.
+	listMaxResults = 1000
+)
+
+var _ source.ResourceClient = (*gcsSourceClient)(nil)
+var _ source.ResourceMetadataGetter = (*gcsSourceClient)(nil)
+var _ source.ResourceLister = (*gcsSourceClient)(nil)
+
+func init() {
+	if err := source.Register(GCSScheme, NewGCSSourceClient(), adaptor); err != nil {
+		panic(err)
+	}
+}
+
+func adaptor(request *source.Request) *source.Request {
+	clonedRequest := request.Clone(request.Context())
+	if request.Header.Get(source.Range) != "" {
+		clonedRequest.Header.Set(headers.Range, fmt.Sprintf("bytes=%s", request.Header.Get(source.Range)))
+		clonedRequest.Header.Del(source.Range)
+	}
+	return clonedRequest
+}
+
+func NewGCSSourceClient(opts ...GCSSourceClientOption) source.ResourceClient {
+	return newGCSSourceClient(opts...)
+}
+
+func newGCSSourceClient(opts ...GCSSourceClientOption) *gcsSourceClient {
+	sourceClient := &gcsSourceClient{
+		httpClient: http.DefaultClient,
+	}
+	for i := range opts {
+		opts[i](sourceClient)
+	}
+	return sourceClient
+}
+
+type GCSSourceClientOption func(p *gcsSourceClient)
+
+// WithHTTPClient set the http client of GCS JSON API.
+func WithHTTPClient(client *http.Client) GCSSourceClientOption {
+	return func(sourceClient *gcsSourceClient) {
+		sourceClient.httpClient = client
+	}
+}
+
+// gcsSourceClient is an implementation of the interface of source.ResourceClient
+// with GCS JSON API.
+type gcsSourceClient struct {
+	httpClient *http.Client
+	// sha256 of credentials -> oauth2.TokenSource
+	tokenSources sync.Map
+}
+
+// gcsObject is the object resource of GCS JSON API.
+type gcsObject struct {
+	Name        string    `json:"name"`
+	Size        string    `json:"size"`
+	ContentType string    `json:"contentType"`
+	MD5Hash     string    `json:"md5Hash"`
+	CRC32C      string    `json:"crc32c"`
+	ETag        string    `json:"etag"`
+	Updated     time.Time `json:"updated"`
+}
+
+// gcsObjects is the response of listing objects of GCS JSON API.
+type gcsObjects struct {
+	Items         []gcsObject `json:"items"`
+	Prefixes      []string    `json:"prefixes"`
+	NextPageToken string      `json:"nextPageToken"`
+}
+
+// GetContentLength get length of resource content
+// return source.UnknownSourceFileLen if response status is not StatusOK and StatusPartialContent
+func (g *gcsSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	object, err := g.getObject(request)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+
+	contentLength, err := strconv.ParseInt(object.Size, 10, 64)
+	if err != nil {
+		return source.UnknownSourceFileLen, fmt.Errorf("parse size of gcs object %s: %w", object.Name, err)
+	}
+	return contentLength, nil
+}
+
+// IsSupportRange checks if resource supports breakpoint continuation
+// return false if response status is not StatusPartialContent
+func (g *gcsSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	if _, err := g.getObject(request); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetMetadata gets the metadata of the object.
+func (g *gcsSourceClient) GetMetadata(request *source.Request) (*source.Metadata, error) {
+	object, err := g.getObject(request)
+	if err != nil {
+		return nil, err
+	}
+
+	totalContentLength, err := strconv.ParseInt(object.Size, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse size of gcs object %s: %w", object.Name, err)
+	}
+
+	hdr := source.Header{}
+	hdr.Set(headers.ContentLength, object.Size)
+	hdr.Set(headers.LastModified, object.Updated.UTC().Format(source.TimeFormat))
+	hdr.Set(headers.ETag, object.ETag)
+	if object.ContentType != "" {
+		hdr.Set(headers.ContentType, object.ContentType)
+	}
+	return &source.Metadata{
+		Header:             hdr,
+		Status:             http.StatusText(http.StatusOK),
+		StatusCode:         http.StatusOK,
+		SupportRange:       true,
+		TotalContentLength: totalContentLength,
+		Validate: func() error {
+			return nil
+		},
+		Temporary: true,
+	}, nil
+}
+
+// IsExpired checks if a resource received or stored is the same.
+func (g *gcsSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	object, err := g.getObject(request)
+	if err != nil {
+		return false, err
+	}
+	return !(object.ETag == info.ETag || object.Updated.UTC().Format(source.TimeFormat) == info.LastModified), nil
+}
+
+// Download downloads from source
+func (g *gcsSourceClient) Download(request *source.Request) (*source.Response, error) {
+	u, err := g.objectURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Set("alt", "media")
+	u.RawQuery = query.Encode()
+
+	resp, err := g.do(request, u)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK, http.StatusPartialContent}); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return source.NewResponse(
+		resp.Body,
+		source.WithStatus(resp.StatusCode, resp.Status),
+		source.WithContentLength(resp.ContentLength),
+		source.WithExpireInfo(
+			source.ExpireInfo{
+				LastModified: resp.Header.Get(headers.LastModified),
+				ETag:         resp.Header.Get(headers.ETag),
+			},
+		)), nil
+}
+
+// GetLastModified gets last modified timestamp milliseconds of resource
+func (g *gcsSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	object, err := g.getObject(request)
+	if err != nil {
+		return -1, err
+	}
+	return object.Updated.UnixMilli(), nil
+}
+
+// List lists the objects and sub directories of the directory.
+func (g *gcsSourceClient) List(request *source.Request) (urls []source.URLEntry, err error) {
+	// if it's a object, just return it.
+	isDir, err := g.isDirectory(request)
+	if err != nil {
+		return nil, err
+	}
+	// if request is a single file, just return
+	if !isDir {
+		return []source.URLEntry{buildURLEntry(false, request.URL)}, nil
+	}
+
+	// list all files and subdirectory
+	prefix := strings.TrimPrefix(addTrailingSlash(request.URL.Path), "/")
+	var pageToken string
+	for {
+		objects, err := g.listObjects(request, prefix, pageToken, listMaxResults)
+		if err != nil {
+			return urls, err
+		}
+
+		for _, object := range objects.Items {
+			if object.Name != prefix {
+				url := *request.URL
+				url.Path = addLeadingSlash(object.Name)
+				urls = append(urls, buildURLEntry(false, &url))
+			}
+		}
+
+		for _, prefix := range objects.Prefixes {
+			url := *request.URL
+			url.Path = addLeadingSlash(prefix)
+			urls = append(urls, buildURLEntry(true, &url))
+		}
+
+		if objects.NextPageToken == "" {
+			break
+		}
+		pageToken = objects.NextPageToken
+	}
+	return urls, nil
+}
+
+func (g *gcsSourceClient) isDirectory(request *source.Request) (bool, error) {
+	prefix := strings.TrimPrefix(addTrailingSlash(request.URL.Path), "/")
+	objects, err := g.listObjects(request, prefix, "", 1)
+	if err != nil {
+		return false, err
+	}
+	if len(objects.Items)+len(objects.Prefixes) > 0 {
+		return true, nil
+	}
+	return false, nil
+}
+
+// listObjects lists the objects and prefixes with the prefix in bucket.
+func (g *gcsSourceClient) listObjects(request *source.Request, prefix, pageToken string, maxResults int) (*gcsObjects, error) {
+	u, err := g.bucketURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("prefix", prefix)
+	query.Set("delimiter", "/")
+	query.Set("maxResults", strconv.Itoa(maxResults))
+	if pageToken != "" {
+		query.Set("pageToken", pageToken)
+	}
+	u.Path += "/o"
+	u.RawQuery = query.Encode()
+
+	resp, err := g.do(request, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK}); err != nil {
+		return nil, fmt.Errorf("list gcs object %s/%s: %w", request.URL.Host, prefix, err)
+	}
+
+	objects := &gcsObjects{}
+	if err := json.NewDecoder(resp.Body).Decode(objects); err != nil {
+		return nil, fmt.Errorf("decode gcs objects: %w", err)
+	}
+	return objects, nil
+}
+
+// getObject gets the object resource of the request.
+func (g *gcsSourceClient) getObject(request *source.Request) (*gcsObject, error) {
+	u, err := g.objectURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.do(request, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK}); err != nil {
+		return nil, fmt.Errorf("get gcs object %s%s: %w", request.URL.Host, request.URL.Path, err)
+	}
+
+	object := &gcsObject{}
+	if err := json.NewDecoder(resp.Body).Decode(object); err != nil {
+		return nil, fmt.Errorf("decode gcs object: %w", err)
+	}
+	return object, nil
+}
+
+// do sends the GET request of GCS JSON API with the range and authorization.
+func (g *gcsSourceClient) do(request *source.Request, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(request.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r := request.Header.Get(headers.Range); r != "" {
+		req.Header.Set(headers.Range, r)
+	}
+
+	token, err := g.getToken(request.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != nil {
+		token.SetAuthHeader(req)
+	}
+
+	return g.httpClient.Do(req)
+}
+
+// getToken returns the OAuth2 token of the credentials in header,
+// it returns nil when the credentials is not set for the public bucket.
+func (g *gcsSourceClient) getToken(header source.Header) (*oauth2.Token, error) {
+	if token := header.Get(accessToken); token != "" {
+		return &oauth2.Token{AccessToken: token, TokenType: "Bearer"}, nil
+	}
+
+	creds := header.Get(credentials)
+	if creds == "" {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256([]byte(creds))
+	key := hex.EncodeToString(sum[:])
+	if tokenSource, ok := g.tokenSources.Load(key); ok {
+		return tokenSource.(oauth2.TokenSource).Token()
+	}
+
+	// Credentials are used to request tokens, which is not canceled with the request.
+	c, err := google.CredentialsFromJSON(context.Background(), []byte(creds), readOnlyScope)
+	if err != nil {
+		return nil, fmt.Errorf("parse gcs credentials: %w", err)
+	}
+
+	tokenSource, _ := g.tokenSources.LoadOrStore(key, oauth2.ReuseTokenSource(nil, c.TokenSource))
+	return tokenSource.(oauth2.TokenSource).Token()
+}
+
+// bucketURL returns the bucket url of GCS JSON API.
+func (g *gcsSourceClient) bucketURL(request *source.Request) (*url.URL, error) {
+	if request.URL.Host == "" {
+		return nil, errors.New("bucket is empty")
+	}
+
+	e := request.Header.Get(endpoint)
+	if e == "" {
+		e = defaultEndpoint
+	}
+
+	u, err := url.Parse(e)
+	if err != nil {
+		return nil, fmt.Errorf("parse gcs endpoint %s: %w", e, err)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/storage/v1/b/" + request.URL.Host
+	return u, nil
+}
+
+// objectURL returns the object url of GCS JSON API, the object name is escaped
+// as a single path segment.
+func (g *gcsSourceClient) objectURL(request *source.Request) (*url.URL, error) {
+	object := strings.TrimPrefix(request.URL.Path, "/")
+	if object == "" {
+		return nil, errors.New("object is empty")
+	}
+
+	u, err := g.bucketURL(request)
+	if err != nil {
+		return nil, err
+	}
+
+	u.RawPath = u.EscapedPath() + "/o/" + url.PathEscape(object)
+	u.Path += "/o/" + object
+	return u, nil
+}
+
+func buildURLEntry(isDir bool, url *url.URL) source.URLEntry {
+	if isDir {
+		url.Path = addTrailingSlash(url.Path)
+		list := strings.Split(url.Path, "/")
+		return source.URLEntry{URL: url, Name: list[len(list)-2], IsDir: true}
+	}
+	_, name := filepath.Split(url.Path)
+	return source.URLEntry{URL: url, Name: name, IsDir: false}
+}
+
+func addLeadingSlash(s string) string {
+	if strings.HasPrefix(s, "/") {
+		return s
+	}
+	return "/" + s
+}
+
+func addTrailingSlash(s string) string {
+	if strings.HasSuffix(s, "/") {
+		return s
+	}
+	return s + "/"
+}
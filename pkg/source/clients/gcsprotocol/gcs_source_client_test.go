@@ -0,0 +1,238 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcsprotocol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+var (
+	testContent = "l am test case"
+	testUpdated = time.Date(2021, 6, 6, 12, 52, 30, 0, time.UTC)
+	testETag    = "CKih16GjycICEAE="
+)
+
+// newGCSServer returns a fake server of GCS JSON API with the objects in bucket.
+func newGCSServer(t *testing.T, objects []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(headers.Authorization) != "Bearer foo" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path == "/storage/v1/b/bucket/o" {
+			prefix := r.URL.Query().Get("prefix")
+			result := gcsObjects{}
+			for _, name := range objects {
+				if !strings.HasPrefix(name, prefix) {
+					continue
+				}
+
+				if i := strings.Index(name[len(prefix):], "/"); i >= 0 {
+					result.Prefixes = append(result.Prefixes, name[:len(prefix)+i+1])
+					continue
+				}
+				result.Items = append(result.Items, gcsObject{Name: name})
+			}
+
+			assert.NoError(t, json.NewEncoder(w).Encode(result))
+			return
+		}
+
+		name := strings.TrimPrefix(r.URL.EscapedPath(), "/storage/v1/b/bucket/o/")
+		if name != "dir%2Ffoo%20bar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("alt") == "media" {
+			w.Header().Set(headers.ETag, testETag)
+			w.Header().Set(headers.LastModified, testUpdated.Format(source.TimeFormat))
+			if r.Header.Get(headers.Range) == "bytes=0-3" {
+				w.Header().Set(headers.ContentLength, "4")
+				w.WriteHeader(http.StatusPartialContent)
+				fmt.Fprint(w, testContent[:4])
+				return
+			}
+
+			w.Header().Set(headers.ContentLength, fmt.Sprint(len(testContent)))
+			fmt.Fprint(w, testContent)
+			return
+		}
+
+		assert.NoError(t, json.NewEncoder(w).Encode(gcsObject{
+			Name:    "dir/foo bar",
+			Size:    fmt.Sprint(len(testContent)),
+			ETag:    testETag,
+			Updated: testUpdated,
+		}))
+	}))
+}
+
+func TestGCSSourceClient(t *testing.T) {
+	server := newGCSServer(t, []string{"dir/foo bar", "dir/sub/baz"})
+	defer server.Close()
+
+	client := newGCSSourceClient()
+	newRequest := func(rawURL string, header map[string]string) *source.Request {
+		request, err := source.NewRequestWithContext(context.Background(), rawURL, header)
+		assert.NoError(t, err)
+		request.Header.Set(endpoint, server.URL)
+		request.Header.Set(accessToken, "foo")
+		return adaptor(request)
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "get content length",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				contentLength, err := client.GetContentLength(newRequest("gs://bucket/dir/foo bar", nil))
+				assert.NoError(err)
+				assert.Equal(int64(len(testContent)), contentLength)
+			},
+		},
+		{
+			name: "get metadata",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				metadata, err := client.GetMetadata(newRequest("gs://bucket/dir/foo bar", nil))
+				assert.NoError(err)
+				assert.True(metadata.SupportRange)
+				assert.Equal(int64(len(testContent)), metadata.TotalContentLength)
+				assert.Equal(testETag, metadata.Header.Get(headers.ETag))
+			},
+		},
+		{
+			name: "get last modified",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				lastModified, err := client.GetLastModified(newRequest("gs://bucket/dir/foo bar", nil))
+				assert.NoError(err)
+				assert.Equal(testUpdated.UnixMilli(), lastModified)
+			},
+		},
+		{
+			name: "is expired",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				expired, err := client.IsExpired(newRequest("gs://bucket/dir/foo bar", nil), &source.ExpireInfo{ETag: testETag})
+				assert.NoError(err)
+				assert.False(expired)
+
+				expired, err = client.IsExpired(newRequest("gs://bucket/dir/foo bar", nil), &source.ExpireInfo{ETag: "foo"})
+				assert.NoError(err)
+				assert.True(expired)
+			},
+		},
+		{
+			name: "download",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				resp, err := client.Download(newRequest("gs://bucket/dir/foo bar", nil))
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testContent, string(data))
+				assert.Equal(testETag, resp.ExpireInfo().ETag)
+			},
+		},
+		{
+			name: "download with range",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				resp, err := client.Download(newRequest("gs://bucket/dir/foo bar", map[string]string{source.Range: "0-3"}))
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(http.StatusPartialContent, resp.StatusCode)
+				assert.Equal(testContent[:4], string(data))
+			},
+		},
+		{
+			name: "object not found",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				_, err := client.GetContentLength(newRequest("gs://bucket/dir/foo", nil))
+				assert.Error(err)
+
+				_, err = client.Download(newRequest("gs://bucket/dir/foo", nil))
+				assert.Error(err)
+			},
+		},
+		{
+			name: "unauthorized",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				request := newRequest("gs://bucket/dir/foo bar", nil)
+				request.Header.Del(accessToken)
+				_, err := client.GetContentLength(request)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "list directory",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				entries, err := client.List(newRequest("gs://bucket/dir", nil))
+				assert.NoError(err)
+				assert.Len(entries, 2)
+				assert.Equal("foo bar", entries[0].Name)
+				assert.False(entries[0].IsDir)
+				assert.Equal("/dir/foo bar", entries[0].URL.Path)
+				assert.Equal("sub", entries[1].Name)
+				assert.True(entries[1].IsDir)
+				assert.Equal("/dir/sub/", entries[1].URL.Path)
+			},
+		},
+		{
+			name: "list object",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				entries, err := client.List(newRequest("gs://bucket/dir/foo bar", nil))
+				assert.NoError(err)
+				assert.Len(entries, 1)
+				assert.Equal("foo bar", entries[0].Name)
+				assert.False(entries[0].IsDir)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.run)
+	}
+}
@@ -0,0 +1,508 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ociprotocol
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+	"github.com/go-http-utils/headers"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"d7y.io/dragonfly/v2/pkg/registry"
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const (
+	OCIScheme  = "oci"
+	ORASScheme = "oras"
+)
+
+const (
+	// Username of registry, it is used for basic auth and requesting bearer token
+	username = "ociUsername"
+	// Password of registry
+	password = "ociPassword"
+	// Static bearer token of registry, it takes precedence over username and password
+	token = "ociToken"
+	// Request registry with plain http if it is true
+	plainHTTP = "ociPlainHTTP"
+)
+
+const (
+	// defaultTag is the tag of artifact if the reference is not set.
+	defaultTag = "latest"
+
+	// dockerHubDomain is the domain of docker hub in reference.
+	dockerHubDomain = "docker.io"
+
+	// dockerHubRegistry is the registry of docker hub.
+	dockerHubRegistry = "registry-1.docker.io"
+
+	// dockerContentDigest is the header of manifest digest in registry response.
+	dockerContentDigest = "Docker-Content-Digest"
+)
+
+// blobPathPattern matches the path of blob url, like /<repository>/blobs/<digest>.
+var blobPathPattern = regexp.MustCompile(`^/(.+)/blobs/([a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]+)$`)
+
+var _ source.ResourceClient = (*ociSourceClient)(nil)
+var _ source.ResourceMetadataGetter = (*ociSourceClient)(nil)
+var _ source.ResourceLister = (*ociSourceClient)(nil)
+
+func init() {
+	sourceClient := NewOCISourceClient()
+	if err := source.Register(OCIScheme, sourceClient, adaptor); err != nil {
+		panic(err)
+	}
+
+	if err := source.Register(ORASScheme, sourceClient, adaptor); err != nil {
+		panic(err)
+	}
+}
+
+func adaptor(request *source.Request) *source.Request {
+	clonedRequest := request.Clone(request.Context())
+	if request.Header.Get(source.Range) != "" {
+		clonedRequest.Header.Set(headers.Range, fmt.Sprintf("bytes=%s", request.Header.Get(source.Range)))
+		clonedRequest.Header.Del(source.Range)
+	}
+	return clonedRequest
+}
+
+func NewOCISourceClient(opts ...OCISourceClientOption) source.ResourceClient {
+	return newOCISourceClient(opts...)
+}
+
+func newOCISourceClient(opts ...OCISourceClientOption) *ociSourceClient {
+	sourceClient := &ociSourceClient{
+		httpClient: http.DefaultClient,
+	}
+	for i := range opts {
+		opts[i](sourceClient)
+	}
+	return sourceClient
+}
+
+type OCISourceClientOption func(p *ociSourceClient)
+
+// WithHTTPClient set the http client of registry.
+func WithHTTPClient(client *http.Client) OCISourceClientOption {
+	return func(sourceClient *ociSourceClient) {
+		sourceClient.httpClient = client
+	}
+}
+
+// ociSourceClient is an implementation of the interface of source.ResourceClient
+// with OCI distribution API. The url of artifact is oci://<registry>/<repository>[:<tag>|@<digest>],
+// which is downloaded as the only layer of artifact or listed as the layers of artifact.
+// The url of blob is oci://<registry>/<repository>/blobs/<digest>.
+type ociSourceClient struct {
+	httpClient *http.Client
+	// registry_repository_sha256 of credential -> bearer token
+	tokens sync.Map
+}
+
+// reference is the parsed url of artifact or blob.
+type reference struct {
+	scheme     string
+	registry   string
+	repository string
+	// reference is the tag or digest of manifest
+	reference string
+	// blob is the digest of blob if url is a blob url
+	blob digest.Digest
+}
+
+// parseReference parses the url of artifact or blob.
+func parseReference(request *source.Request) (*reference, error) {
+	ref := &reference{
+		scheme:   "https",
+		registry: request.URL.Host,
+	}
+
+	if strings.ToLower(request.Header.Get(plainHTTP)) == "true" {
+		ref.scheme = "http"
+	}
+
+	if ref.registry == "" {
+		return nil, errors.New("registry is empty")
+	}
+
+	path := request.URL.Path
+	if matches := blobPathPattern.FindStringSubmatch(path); matches != nil {
+		dgst, err := digest.Parse(matches[2])
+		if err != nil {
+			return nil, fmt.Errorf("parse blob digest %s: %w", matches[2], err)
+		}
+
+		ref.repository = matches[1]
+		ref.blob = dgst
+	} else {
+		path = strings.TrimPrefix(path, "/")
+		if i := strings.LastIndex(path, "@"); i >= 0 {
+			ref.repository, ref.reference = path[:i], path[i+1:]
+		} else if i := strings.LastIndex(path, ":"); i > strings.LastIndex(path, "/") {
+			ref.repository, ref.reference = path[:i], path[i+1:]
+		} else {
+			ref.repository, ref.reference = path, defaultTag
+		}
+	}
+
+	if ref.repository == "" || (ref.reference == "" && ref.blob == "") {
+		return nil, fmt.Errorf("invalid reference %s", request.URL)
+	}
+
+	if ref.registry == dockerHubDomain {
+		ref.registry = dockerHubRegistry
+		if !strings.Contains(ref.repository, "/") {
+			ref.repository = "library/" + ref.repository
+		}
+	}
+
+	return ref, nil
+}
+
+// blobURL returns the url of blob in the source url format.
+func (r *reference) blobURL(u *url.URL, dgst digest.Digest) *url.URL {
+	blobURL := *u
+	blobURL.Path = fmt.Sprintf("/%s/blobs/%s", r.repository, dgst)
+	blobURL.RawPath = ""
+	return &blobURL
+}
+
+// manifestURL returns the url of manifest in the source url format.
+func (r *reference) manifestURL(u *url.URL, dgst digest.Digest) *url.URL {
+	manifestURL := *u
+	manifestURL.Path = fmt.Sprintf("/%s@%s", r.repository, dgst)
+	manifestURL.RawPath = ""
+	return &manifestURL
+}
+
+// GetContentLength get length of resource content
+// return source.UnknownSourceFileLen if response status is not StatusOK and StatusPartialContent
+func (o *ociSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	_, desc, err := o.resolveBlob(request)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+	return desc.Size, nil
+}
+
+// IsSupportRange checks if resource supports breakpoint continuation
+// return false if response status is not StatusPartialContent
+func (o *ociSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	if _, _, err := o.resolveBlob(request); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetMetadata gets the metadata of the blob.
+func (o *ociSourceClient) GetMetadata(request *source.Request) (*source.Metadata, error) {
+	_, desc, err := o.resolveBlob(request)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := source.Header{}
+	hdr.Set(headers.ContentLength, strconv.FormatInt(desc.Size, 10))
+	hdr.Set(headers.ETag, desc.Digest.String())
+	return &source.Metadata{
+		Header:             hdr,
+		Status:             http.StatusText(http.StatusOK),
+		StatusCode:         http.StatusOK,
+		SupportRange:       true,
+		TotalContentLength: desc.Size,
+		Validate: func() error {
+			return nil
+		},
+		Temporary: true,
+	}, nil
+}
+
+// IsExpired checks if a resource received or stored is the same,
+// the blob is expired when the tag of artifact is pushed with the different content.
+func (o *ociSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	_, desc, err := o.resolveBlob(request)
+	if err != nil {
+		return false, err
+	}
+	return desc.Digest.String() != info.ETag, nil
+}
+
+// Download downloads from source
+func (o *ociSourceClient) Download(request *source.Request) (*source.Response, error) {
+	ref, desc, err := o.resolveBlob(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.requestRegistry(request, ref, http.MethodGet, "blobs/"+desc.Digest.String(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK, http.StatusPartialContent}); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return source.NewResponse(
+		resp.Body,
+		source.WithStatus(resp.StatusCode, resp.Status),
+		source.WithContentLength(resp.ContentLength),
+		source.WithExpireInfo(
+			source.ExpireInfo{
+				ETag: desc.Digest.String(),
+			},
+		)), nil
+}
+
+// GetLastModified gets last modified timestamp milliseconds of resource,
+// registry does not provide the last modified time of blob.
+func (o *ociSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	return -1, nil
+}
+
+// List lists the layers of artifact, the manifests of image index are listed as directories.
+func (o *ociSourceClient) List(request *source.Request) (urls []source.URLEntry, err error) {
+	ref, err := parseReference(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// if request is a single blob, just return
+	if ref.blob != "" {
+		return []source.URLEntry{{URL: request.URL, Name: ref.blob.Encoded()}}, nil
+	}
+
+	manifest, _, err := o.getManifest(request, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifestList, ok := manifest.(*manifestlist.DeserializedManifestList); ok {
+		for _, desc := range manifestList.Manifests {
+			name := desc.Digest.Encoded()
+			if desc.Platform.OS != "" {
+				name = strings.Join([]string{desc.Platform.OS, desc.Platform.Architecture, desc.Platform.Variant}, "_")
+				name = strings.TrimSuffix(name, "_")
+			}
+
+			urls = append(urls, source.URLEntry{URL: ref.manifestURL(request.URL, desc.Digest), Name: name, IsDir: true})
+		}
+		return urls, nil
+	}
+
+	for _, desc := range registry.Layers(manifest) {
+		name := desc.Annotations[specs.AnnotationTitle]
+		if name == "" {
+			name = desc.Digest.Encoded()
+		}
+
+		urls = append(urls, source.URLEntry{URL: ref.blobURL(request.URL, desc.Digest), Name: name})
+	}
+	return urls, nil
+}
+
+// resolveBlob resolves the blob of request, the blob of artifact is the only layer of manifest.
+func (o *ociSourceClient) resolveBlob(request *source.Request) (*reference, distribution.Descriptor, error) {
+	ref, err := parseReference(request)
+	if err != nil {
+		return nil, distribution.Descriptor{}, err
+	}
+
+	if ref.blob != "" {
+		resp, err := o.requestRegistry(request, ref, http.MethodHead, "blobs/"+ref.blob.String(), "")
+		if err != nil {
+			return nil, distribution.Descriptor{}, err
+		}
+		resp.Body.Close()
+
+		if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK}); err != nil {
+			return nil, distribution.Descriptor{}, fmt.Errorf("get blob %s: %w", ref.blob, err)
+		}
+
+		return ref, distribution.Descriptor{Digest: ref.blob, Size: resp.ContentLength}, nil
+	}
+
+	manifest, dgst, err := o.getManifest(request, ref)
+	if err != nil {
+		return nil, distribution.Descriptor{}, err
+	}
+
+	layers := registry.Layers(manifest)
+	if len(layers) != 1 {
+		return nil, distribution.Descriptor{}, fmt.Errorf("artifact %s has %d layers, download it recursively", dgst, len(layers))
+	}
+
+	return ref, layers[0], nil
+}
+
+// getManifest requests the manifest of reference and returns the manifest digest.
+func (o *ociSourceClient) getManifest(request *source.Request, ref *reference) (distribution.Manifest, digest.Digest, error) {
+	resp, err := o.requestRegistry(request, ref, http.MethodGet, "manifests/"+ref.reference, strings.Join(registry.ManifestMediaTypes, ", "))
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK}); err != nil {
+		return nil, "", fmt.Errorf("get manifest %s/%s:%s: %w", ref.registry, ref.repository, ref.reference, err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	manifest, err := registry.UnmarshalManifest(resp.Header.Get(headers.ContentType), body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dgst, err := digest.Parse(resp.Header.Get(dockerContentDigest))
+	if err != nil {
+		dgst = digest.FromBytes(body)
+	}
+	return manifest, dgst, nil
+}
+
+// requestRegistry requests the registry api of repository, it requests the bearer token
+// and retries when registry responses unauthorized. The response body must be closed by caller.
+func (o *ociSourceClient) requestRegistry(request *source.Request, ref *reference, method, path, accept string) (*http.Response, error) {
+	tokenKey := tokenKey(request, ref)
+	authorization := ""
+	if t := request.Header.Get(token); t != "" {
+		authorization = "Bearer " + t
+	} else if t, ok := o.tokens.Load(tokenKey); ok {
+		authorization = "Bearer " + t.(string)
+	}
+
+	resp, err := o.request(request, ref, method, path, accept, authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized || request.Header.Get(token) != "" {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	if strings.HasPrefix(strings.ToLower(resp.Header.Get(headers.WWWAuthenticate)), "basic") {
+		if request.Header.Get(username) == "" {
+			return nil, errors.New("registry requires basic auth credential")
+		}
+
+		authorization = basicAuth(request.Header.Get(username), request.Header.Get(password))
+	} else {
+		t, err := o.getAuthToken(request, resp.Header)
+		if err != nil {
+			return nil, err
+		}
+
+		o.tokens.Store(tokenKey, t)
+		authorization = "Bearer " + t
+	}
+
+	return o.request(request, ref, method, path, accept, authorization)
+}
+
+// tokenKey returns the cache key of bearer token, the credential is hashed into
+// the key so that the token is only reused by the same username and password.
+func tokenKey(request *source.Request, ref *reference) string {
+	u, p := request.Header.Get(username), request.Header.Get(password)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%s", len(u), u, p)))
+	return strings.Join([]string{ref.registry, ref.repository, hex.EncodeToString(sum[:])}, "_")
+}
+
+// request requests the registry api of repository with the authorization.
+func (o *ociSourceClient) request(request *source.Request, ref *reference, method, path, accept, authorization string) (*http.Response, error) {
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", ref.scheme, ref.registry, ref.repository, path)
+	req, err := http.NewRequestWithContext(request.Context(), method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if accept != "" {
+		req.Header.Set(headers.Accept, accept)
+	}
+
+	if authorization != "" {
+		req.Header.Set(headers.Authorization, authorization)
+	}
+
+	if r := request.Header.Get(headers.Range); r != "" && method == http.MethodGet && strings.HasPrefix(path, "blobs/") {
+		req.Header.Set(headers.Range, r)
+	}
+
+	return o.httpClient.Do(req)
+}
+
+// getAuthToken requests the bearer token by the challenge of registry,
+// the token is requested with the basic auth if the username is set.
+func (o *ociSourceClient) getAuthToken(request *source.Request, header http.Header) (string, error) {
+	authURL := registry.AuthURL(header.Values(headers.WWWAuthenticate))
+	if authURL == "" {
+		return "", errors.New("authURL is empty")
+	}
+
+	req, err := http.NewRequestWithContext(request.Context(), http.MethodGet, authURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if u := request.Header.Get(username); u != "" {
+		req.Header.Set(headers.Authorization, basicAuth(u, request.Header.Get(password)))
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK}); err != nil {
+		return "", fmt.Errorf("request token: %w", err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return registry.ParseToken(body)
+}
+
+// basicAuth returns the authorization of basic auth.
+func basicAuth(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
@@ -0,0 +1,417 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ociprotocol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/headers"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+var (
+	testConfig = "{}"
+	testModel  = "l am model"
+	testVocab  = "l am vocab"
+)
+
+// newRegistryServer returns a fake registry requires bearer token, the repository models/bert
+// has the artifacts of tag multi with two layers, tag single with one layer and tag index of image index.
+func newRegistryServer(t *testing.T) *httptest.Server {
+	blobs := map[digest.Digest]string{}
+	for _, content := range []string{testConfig, testModel, testVocab} {
+		blobs[digest.FromString(content)] = content
+	}
+
+	descriptor := func(mediaType, content, title string) map[string]any {
+		desc := map[string]any{
+			"mediaType": mediaType,
+			"digest":    digest.FromString(content).String(),
+			"size":      len(content),
+		}
+		if title != "" {
+			desc["annotations"] = map[string]string{specs.AnnotationTitle: title}
+		}
+		return desc
+	}
+
+	marshal := func(v any) string {
+		b, err := json.Marshal(v)
+		assert.NoError(t, err)
+		return string(b)
+	}
+
+	single := marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     specs.MediaTypeImageManifest,
+		"config":        descriptor("application/vnd.d7y.model.config.v1+json", testConfig, ""),
+		"layers":        []any{descriptor("application/octet-stream", testModel, "")},
+	})
+	manifests := map[string]string{
+		"multi": marshal(map[string]any{
+			"schemaVersion": 2,
+			"mediaType":     specs.MediaTypeImageManifest,
+			"config":        descriptor("application/vnd.d7y.model.config.v1+json", testConfig, ""),
+			"layers": []any{
+				descriptor("application/octet-stream", testModel, "model.bin"),
+				descriptor("application/octet-stream", testVocab, "vocab.txt"),
+			},
+		}),
+		"single": single,
+		"index": marshal(map[string]any{
+			"schemaVersion": 2,
+			"mediaType":     specs.MediaTypeImageIndex,
+			"manifests": []any{map[string]any{
+				"mediaType": specs.MediaTypeImageManifest,
+				"digest":    digest.FromString(single).String(),
+				"size":      len(single),
+				"platform":  map[string]string{"os": "linux", "architecture": "amd64"},
+			}},
+		}),
+		digest.FromString(single).String(): single,
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if username, password, ok := r.BasicAuth(); !ok || username != "foo" || password != "bar" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			assert.Equal(t, "repository:models/bert:pull", r.URL.Query().Get("scope"))
+			fmt.Fprint(w, `{"token":"baz"}`)
+			return
+		}
+
+		if r.Header.Get(headers.Authorization) != "Bearer baz" {
+			w.Header().Set(headers.WWWAuthenticate, fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:models/bert:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if reference := strings.TrimPrefix(r.URL.Path, "/v2/models/bert/manifests/"); reference != r.URL.Path {
+			manifest, ok := manifests[reference]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			var versioned struct {
+				MediaType string `json:"mediaType"`
+			}
+			assert.NoError(t, json.Unmarshal([]byte(manifest), &versioned))
+			assert.Contains(t, r.Header.Get(headers.Accept), versioned.MediaType)
+
+			w.Header().Set(headers.ContentType, versioned.MediaType)
+			w.Header().Set(dockerContentDigest, digest.FromString(manifest).String())
+			fmt.Fprint(w, manifest)
+			return
+		}
+
+		if dgst := strings.TrimPrefix(r.URL.Path, "/v2/models/bert/blobs/"); dgst != r.URL.Path {
+			content, ok := blobs[digest.Digest(dgst)]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	return server
+}
+
+func TestOCISourceClient(t *testing.T) {
+	server := newRegistryServer(t)
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	client := newOCISourceClient()
+	newRequest := func(path string, header map[string]string) *source.Request {
+		request, err := source.NewRequestWithContext(context.Background(), fmt.Sprintf("oci://%s/%s", u.Host, path), header)
+		assert.NoError(t, err)
+		request.Header.Set(plainHTTP, "true")
+		request.Header.Set(username, "foo")
+		request.Header.Set(password, "bar")
+		return adaptor(request)
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{
+		{
+			name: "get content length of artifact",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				contentLength, err := client.GetContentLength(newRequest("models/bert:single", nil))
+				assert.NoError(err)
+				assert.Equal(int64(len(testModel)), contentLength)
+			},
+		},
+		{
+			name: "get content length of blob",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				contentLength, err := client.GetContentLength(newRequest("models/bert/blobs/"+digest.FromString(testVocab).String(), nil))
+				assert.NoError(err)
+				assert.Equal(int64(len(testVocab)), contentLength)
+			},
+		},
+		{
+			name: "get metadata",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				metadata, err := client.GetMetadata(newRequest("models/bert:single", nil))
+				assert.NoError(err)
+				assert.True(metadata.SupportRange)
+				assert.Equal(int64(len(testModel)), metadata.TotalContentLength)
+				assert.Equal(digest.FromString(testModel).String(), metadata.Header.Get(headers.ETag))
+			},
+		},
+		{
+			name: "is expired",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				expired, err := client.IsExpired(newRequest("models/bert:single", nil), &source.ExpireInfo{ETag: digest.FromString(testModel).String()})
+				assert.NoError(err)
+				assert.False(expired)
+
+				expired, err = client.IsExpired(newRequest("models/bert:single", nil), &source.ExpireInfo{ETag: digest.FromString(testVocab).String()})
+				assert.NoError(err)
+				assert.True(expired)
+			},
+		},
+		{
+			name: "download artifact",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				resp, err := client.Download(newRequest("models/bert:single", nil))
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testModel, string(data))
+				assert.Equal(digest.FromString(testModel).String(), resp.ExpireInfo().ETag)
+			},
+		},
+		{
+			name: "download artifact pinned by digest",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				entries, err := client.List(newRequest("models/bert:index", nil))
+				assert.NoError(err)
+				assert.Len(entries, 1)
+
+				request, err := source.NewRequestWithContext(context.Background(), entries[0].URL.String(), map[string]string{plainHTTP: "true", username: "foo", password: "bar"})
+				assert.NoError(err)
+				resp, err := client.Download(request)
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testModel, string(data))
+			},
+		},
+		{
+			name: "download blob with range",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				resp, err := client.Download(newRequest("models/bert/blobs/"+digest.FromString(testVocab).String(), map[string]string{source.Range: "0-3"}))
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(http.StatusPartialContent, resp.StatusCode)
+				assert.Equal(testVocab[:4], string(data))
+			},
+		},
+		{
+			name: "download artifact with multiple layers",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				_, err := client.Download(newRequest("models/bert:multi", nil))
+				assert.Error(err)
+			},
+		},
+		{
+			name: "artifact not found",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				_, err := client.Download(newRequest("models/bert:foo", nil))
+				assert.Error(err)
+			},
+		},
+		{
+			name: "unauthorized",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				request := newRequest("models/bert:single", nil)
+				request.Header.Set(token, "foo")
+				_, err := client.Download(request)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "wrong password does not reuse cached token",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				resp, err := client.Download(newRequest("models/bert:single", nil))
+				assert.NoError(err)
+				resp.Body.Close()
+
+				for _, p := range []string{"baz", ""} {
+					request := newRequest("models/bert:single", nil)
+					request.Header.Set(password, p)
+					_, err = client.Download(request)
+					assert.Error(err)
+				}
+			},
+		},
+		{
+			name: "list artifact",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				entries, err := client.List(newRequest("models/bert:multi", nil))
+				assert.NoError(err)
+				assert.Len(entries, 2)
+				assert.Equal("model.bin", entries[0].Name)
+				assert.False(entries[0].IsDir)
+				assert.Equal(fmt.Sprintf("oci://%s/models/bert/blobs/%s", u.Host, digest.FromString(testModel)), entries[0].URL.String())
+				assert.Equal("vocab.txt", entries[1].Name)
+
+				resp, err := client.Download(newRequest(strings.TrimPrefix(entries[1].URL.Path, "/"), nil))
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				var buf bytes.Buffer
+				_, err = buf.ReadFrom(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testVocab, buf.String())
+			},
+		},
+		{
+			name: "list image index",
+			run: func(t *testing.T) {
+				assert := assert.New(t)
+				entries, err := client.List(newRequest("models/bert:index", nil))
+				assert.NoError(err)
+				assert.Len(entries, 1)
+				assert.Equal("linux_amd64", entries[0].Name)
+				assert.True(entries[0].IsDir)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.run)
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	dgst := digest.FromString(testModel)
+	tests := []struct {
+		rawURL string
+		expect func(t *testing.T, ref *reference, err error)
+	}{
+		{
+			rawURL: "oci://localhost:5000/models/bert:v1",
+			expect: func(t *testing.T, ref *reference, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&reference{scheme: "https", registry: "localhost:5000", repository: "models/bert", reference: "v1"}, ref)
+			},
+		},
+		{
+			rawURL: "oci://localhost:5000/models/bert",
+			expect: func(t *testing.T, ref *reference, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("models/bert", ref.repository)
+				assert.Equal(defaultTag, ref.reference)
+			},
+		},
+		{
+			rawURL: "oras://localhost:5000/models/bert@" + dgst.String(),
+			expect: func(t *testing.T, ref *reference, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("models/bert", ref.repository)
+				assert.Equal(dgst.String(), ref.reference)
+			},
+		},
+		{
+			rawURL: "oci://localhost:5000/models/bert/blobs/" + dgst.String(),
+			expect: func(t *testing.T, ref *reference, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("models/bert", ref.repository)
+				assert.Equal(dgst, ref.blob)
+				assert.Empty(ref.reference)
+			},
+		},
+		{
+			rawURL: "oci://docker.io/alpine:3.16",
+			expect: func(t *testing.T, ref *reference, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(dockerHubRegistry, ref.registry)
+				assert.Equal("library/alpine", ref.repository)
+				assert.Equal("3.16", ref.reference)
+			},
+		},
+		{
+			rawURL: "oci://localhost:5000",
+			expect: func(t *testing.T, ref *reference, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.rawURL, func(t *testing.T) {
+			request, err := source.NewRequest(tc.rawURL)
+			assert.NoError(t, err)
+			ref, err := parseReference(request)
+			tc.expect(t, ref, err)
+		})
+	}
+}
@@ -33,3 +33,13 @@ func StructToMap(t any) (map[string]any, error) {
 	}
 	return m, nil
 }
+
+// MapToStruct coverts map to struct.
+func MapToStruct(m map[string]any, t any) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, t)
+}
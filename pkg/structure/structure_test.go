@@ -78,3 +78,56 @@ func TestStructToMap(t *testing.T) {
 		})
 	}
 }
+
+func TestMapToStruct(t *testing.T) {
+	type person struct {
+		Name string
+		Age  int
+	}
+
+	tests := []struct {
+		name   string
+		m      map[string]any
+		expect func(*testing.T, *person, error)
+	}{
+		{
+			name: "conver map to struct",
+			m: map[string]any{
+				"Name": "foo",
+				"Age":  float64(18),
+			},
+			expect: func(t *testing.T, p *person, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&person{Name: "foo", Age: 18}, p)
+			},
+		},
+		{
+			name: "conver map with invalid type to struct failed",
+			m: map[string]any{
+				"Age": "foo",
+			},
+			expect: func(t *testing.T, p *person, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "json: cannot unmarshal string into Go struct field person.Age of type int")
+			},
+		},
+		{
+			name: "conver nil to struct",
+			m:    nil,
+			expect: func(t *testing.T, p *person, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&person{}, p)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &person{}
+			err := MapToStruct(tc.m, p)
+			tc.expect(t, p, err)
+		})
+	}
+}
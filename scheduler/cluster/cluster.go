@@ -0,0 +1,325 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/cluster_mock.go -source cluster.go -package mocks
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+	clusterrpc "d7y.io/dragonfly/v2/pkg/rpc/scheduler/cluster"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/version"
+)
+
+// Cluster shares task state between schedulers in the same scheduler cluster.
+type Cluster interface {
+	// ListTaskPeers lists the succeeded peers of the task from the other schedulers,
+	// the schedulers which fail to respond are ignored.
+	ListTaskPeers(ctx context.Context, taskID string) []*schedulerv1.AnnounceTaskRequest
+
+	// Len returns the count of the other schedulers.
+	Len() int
+
+	// Serve refreshes the other schedulers periodically.
+	Serve()
+
+	// Stop stops refreshing and closes the connections of the other schedulers.
+	Stop() error
+}
+
+type cluster struct {
+	// Scheduler config.
+	config *config.Config
+
+	// Manager client discovers the other schedulers.
+	managerClient managerclient.Client
+
+	// TransportCredentials stores the Authenticator required to setup a client connection.
+	transportCredentials credentials.TransportCredentials
+
+	// conns is the connections of the other schedulers, the key is scheduler address.
+	conns map[string]*grpc.ClientConn
+
+	// mu protects conns.
+	mu sync.RWMutex
+
+	// done channel will be closed when cluster stops.
+	done chan struct{}
+}
+
+// Option is a functional option for configuring the cluster.
+type Option func(c *cluster)
+
+// WithTransportCredentials returns an Option which configures a connection
+// level security credentials (e.g., TLS/SSL).
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(c *cluster) {
+		c.transportCredentials = creds
+	}
+}
+
+// New returns a new Cluster interface.
+func New(cfg *config.Config, managerClient managerclient.Client, options ...Option) Cluster {
+	c := &cluster{
+		config:        cfg,
+		managerClient: managerClient,
+		conns:         make(map[string]*grpc.ClientConn),
+		done:          make(chan struct{}),
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	if err := c.refresh(); err != nil {
+		logger.Errorf("refresh cluster schedulers failed: %s", err.Error())
+	}
+
+	return c
+}
+
+// ListTaskPeers lists the succeeded peers of the task from the other schedulers.
+func (c *cluster) ListTaskPeers(ctx context.Context, taskID string) []*schedulerv1.AnnounceTaskRequest {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Cluster.Timeout)
+	defer cancel()
+
+	// The connections are copied to release the lock before requesting the other schedulers,
+	// the request of connection closed by refreshing fails and is ignored.
+	c.mu.RLock()
+	conns := make(map[string]*grpc.ClientConn, len(c.conns))
+	for addr, conn := range c.conns {
+		conns[addr] = conn
+	}
+	c.mu.RUnlock()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		peers []*schedulerv1.AnnounceTaskRequest
+		seen  = make(map[string]struct{})
+	)
+	for addr, conn := range conns {
+		wg.Add(1)
+		go func(addr string, conn *grpc.ClientConn) {
+			defer wg.Done()
+
+			reqs, err := listTaskPeers(ctx, clusterrpc.NewClusterClient(conn), taskID)
+			if err != nil {
+				logger.Debugf("list task %s peers from scheduler %s failed: %s", taskID, addr, err.Error())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, req := range reqs {
+				if req.PiecePacket == nil {
+					continue
+				}
+
+				if _, ok := seen[req.PiecePacket.DstPid]; ok {
+					continue
+				}
+
+				seen[req.PiecePacket.DstPid] = struct{}{}
+				peers = append(peers, req)
+			}
+		}(addr, conn)
+	}
+	wg.Wait()
+
+	return peers
+}
+
+// listTaskPeers receives the peers of task from a scheduler, the peers received
+// before the error are returned as well.
+func listTaskPeers(ctx context.Context, client clusterrpc.ClusterClient, taskID string) ([]*schedulerv1.AnnounceTaskRequest, error) {
+	stream, err := client.ListTaskPeers(ctx, &schedulerv1.StatTaskRequest{TaskId: taskID})
+	if err != nil {
+		return nil, err
+	}
+
+	var reqs []*schedulerv1.AnnounceTaskRequest
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return reqs, nil
+			}
+
+			return reqs, err
+		}
+
+		reqs = append(reqs, req)
+	}
+}
+
+// Len returns the count of the other schedulers.
+func (c *cluster) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.conns)
+}
+
+// Serve refreshes the other schedulers periodically.
+func (c *cluster) Serve() {
+	tick := time.NewTicker(c.config.Cluster.RefreshInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			if err := c.refresh(); err != nil {
+				logger.Errorf("refresh cluster schedulers failed: %s", err.Error())
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Stop stops refreshing and closes the connections of the other schedulers.
+func (c *cluster) Stop() error {
+	close(c.done)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs *multierror.Error
+	for addr, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+
+		delete(c.conns, addr)
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// refresh updates the connections by the addresses of the other schedulers.
+func (c *cluster) refresh() error {
+	addrs, err := c.addrs()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Close the connections of the schedulers that have left.
+	for addr, conn := range c.conns {
+		if _, ok := addrs[addr]; ok {
+			continue
+		}
+
+		if err := conn.Close(); err != nil {
+			logger.Errorf("close cluster scheduler %s connection failed: %s", addr, err.Error())
+		}
+
+		delete(c.conns, addr)
+		logger.Infof("cluster scheduler %s has left", addr)
+	}
+
+	// Dial the schedulers that have joined.
+	for addr := range addrs {
+		if _, ok := c.conns[addr]; ok {
+			continue
+		}
+
+		conn, err := grpc.Dial(addr, c.dialOptions()...)
+		if err != nil {
+			logger.Errorf("dial cluster scheduler %s failed: %s", addr, err.Error())
+			continue
+		}
+
+		c.conns[addr] = conn
+		logger.Infof("cluster scheduler %s has joined", addr)
+	}
+
+	return nil
+}
+
+// addrs returns the addresses of the other schedulers, the static addresses
+// in config are used first, otherwise the schedulers of the same scheduler cluster
+// are listed by manager.
+func (c *cluster) addrs() (map[string]struct{}, error) {
+	addrs := make(map[string]struct{})
+	if len(c.config.Cluster.Addrs) > 0 {
+		for _, addr := range c.config.Cluster.Addrs {
+			addrs[addr] = struct{}{}
+		}
+
+		return addrs, nil
+	}
+
+	resp, err := c.managerClient.ListSchedulers(context.Background(), &managerv1.ListSchedulersRequest{
+		SourceType: managerv1.SourceType_SCHEDULER_SOURCE,
+		HostName:   c.config.Server.Host,
+		Ip:         c.config.Server.AdvertiseIP,
+		Version:    version.GitVersion,
+		Commit:     version.GitCommit,
+		HostInfo: map[string]string{
+			types.ListSchedulersSchedulerClusterIDKey: strconv.FormatUint(uint64(c.config.Manager.SchedulerClusterID), 10),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scheduler := range resp.Schedulers {
+		// Only the schedulers in the same scheduler cluster share task state.
+		if scheduler.SchedulerClusterId != uint64(c.config.Manager.SchedulerClusterID) {
+			continue
+		}
+
+		// Skip the scheduler itself.
+		if scheduler.Ip == c.config.Server.AdvertiseIP && int(scheduler.Port) == c.config.Server.Port {
+			continue
+		}
+
+		addrs[fmt.Sprintf("%s:%d", scheduler.Ip, scheduler.Port)] = struct{}{}
+	}
+
+	return addrs, nil
+}
+
+// dialOptions returns the dial options of the other schedulers.
+func (c *cluster) dialOptions() []grpc.DialOption {
+	if c.transportCredentials != nil {
+		return []grpc.DialOption{grpc.WithTransportCredentials(c.transportCredentials)}
+	}
+
+	return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+}
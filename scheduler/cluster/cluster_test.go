@@ -0,0 +1,222 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
+	clusterrpc "d7y.io/dragonfly/v2/pkg/rpc/scheduler/cluster"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/version"
+)
+
+type mockServer struct {
+	clusterrpc.UnimplementedClusterServer
+	reqs []*schedulerv1.AnnounceTaskRequest
+}
+
+func (s *mockServer) ListTaskPeers(req *schedulerv1.StatTaskRequest, stream clusterrpc.Cluster_ListTaskPeersServer) error {
+	for _, r := range s.reqs {
+		if r.TaskId != req.TaskId {
+			continue
+		}
+
+		if err := stream.Send(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newMockServer(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := grpc.NewServer()
+	clusterrpc.RegisterClusterServer(s, &mockServer{reqs: reqs})
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestCluster_New(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.Config
+		mock   func(m *mocks.MockClientMockRecorder)
+		expect func(t *testing.T, c *cluster)
+	}{
+		{
+			name: "new cluster with static addresses",
+			config: &config.Config{
+				Cluster: config.ClusterConfig{
+					Addrs: []string{"127.0.0.1:8002", "127.0.0.2:8002"},
+				},
+			},
+			mock: func(m *mocks.MockClientMockRecorder) {},
+			expect: func(t *testing.T, c *cluster) {
+				assert := assert.New(t)
+				assert.Len(c.conns, 2)
+				assert.Contains(c.conns, "127.0.0.1:8002")
+				assert.Contains(c.conns, "127.0.0.2:8002")
+			},
+		},
+		{
+			name: "new cluster with schedulers discovered by manager",
+			config: &config.Config{
+				Server: config.ServerConfig{
+					AdvertiseIP: "127.0.0.1",
+					Port:        8002,
+				},
+				Manager: config.ManagerConfig{
+					SchedulerClusterID: 1,
+				},
+			},
+			mock: func(m *mocks.MockClientMockRecorder) {
+				m.ListSchedulers(gomock.Any(), gomock.Eq(&managerv1.ListSchedulersRequest{
+					SourceType: managerv1.SourceType_SCHEDULER_SOURCE,
+					Ip:         "127.0.0.1",
+					Version:    version.GitVersion,
+					Commit:     version.GitCommit,
+					HostInfo: map[string]string{
+						types.ListSchedulersSchedulerClusterIDKey: "1",
+					},
+				})).Return(&managerv1.ListSchedulersResponse{
+					Schedulers: []*managerv1.Scheduler{
+						{Ip: "127.0.0.1", Port: 8002, SchedulerClusterId: 1},
+						{Ip: "127.0.0.2", Port: 8002, SchedulerClusterId: 1},
+						{Ip: "127.0.0.3", Port: 8002, SchedulerClusterId: 2},
+					},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, c *cluster) {
+				assert := assert.New(t)
+				assert.Len(c.conns, 1)
+				assert.Contains(c.conns, "127.0.0.2:8002")
+			},
+		},
+		{
+			name:   "list schedulers failed",
+			config: &config.Config{},
+			mock: func(m *mocks.MockClientMockRecorder) {
+				m.ListSchedulers(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, c *cluster) {
+				assert := assert.New(t)
+				assert.Len(c.conns, 0)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			managerClient := mocks.NewMockClient(ctl)
+			tc.mock(managerClient.EXPECT())
+
+			c := New(tc.config, managerClient)
+			tc.expect(t, c.(*cluster))
+			assert.NoError(t, c.Stop())
+		})
+	}
+}
+
+func TestCluster_ListTaskPeers(t *testing.T) {
+	foo := newMockServer(t, []*schedulerv1.AnnounceTaskRequest{
+		{TaskId: "foo", PiecePacket: &commonv1.PiecePacket{DstPid: "bar"}},
+		{TaskId: "foo", PiecePacket: &commonv1.PiecePacket{DstPid: "baz"}},
+		{TaskId: "qux", PiecePacket: &commonv1.PiecePacket{DstPid: "quux"}},
+	})
+	bar := newMockServer(t, []*schedulerv1.AnnounceTaskRequest{
+		{TaskId: "foo", PiecePacket: &commonv1.PiecePacket{DstPid: "bar"}},
+	})
+
+	tests := []struct {
+		name   string
+		addrs  []string
+		taskID string
+		expect func(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest)
+	}{
+		{
+			name:   "list task peers from schedulers",
+			addrs:  []string{foo, bar},
+			taskID: "foo",
+			expect: func(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest) {
+				assert := assert.New(t)
+				assert.Len(reqs, 2)
+
+				var peerIDs []string
+				for _, req := range reqs {
+					peerIDs = append(peerIDs, req.PiecePacket.DstPid)
+				}
+				assert.ElementsMatch([]string{"bar", "baz"}, peerIDs)
+			},
+		},
+		{
+			name:   "task not found in schedulers",
+			addrs:  []string{foo, bar},
+			taskID: "corge",
+			expect: func(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest) {
+				assert := assert.New(t)
+				assert.Len(reqs, 0)
+			},
+		},
+		{
+			name:   "unavailable scheduler is ignored",
+			addrs:  []string{foo, "127.0.0.1:0"},
+			taskID: "qux",
+			expect: func(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest) {
+				assert := assert.New(t)
+				assert.Len(reqs, 1)
+				assert.Equal("quux", reqs[0].PiecePacket.DstPid)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := New(&config.Config{
+				Cluster: config.ClusterConfig{
+					Addrs:   tc.addrs,
+					Timeout: 3 * time.Second,
+				},
+			}, nil)
+
+			tc.expect(t, c.ListTaskPeers(context.Background(), tc.taskID))
+			assert.NoError(t, c.Stop())
+		})
+	}
+}
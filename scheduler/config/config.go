@@ -57,6 +57,12 @@ type Config struct {
 	// Storage configuration.
 	Storage StorageConfig `yaml:"storage" mapstructure:"storage"`
 
+	// Snapshot configuration.
+	Snapshot SnapshotConfig `yaml:"snapshot" mapstructure:"snapshot"`
+
+	// Cluster configuration.
+	Cluster ClusterConfig `yaml:"cluster" mapstructure:"cluster"`
+
 	// Metrics configuration.
 	Metrics MetricsConfig `yaml:"metrics" mapstructure:"metrics"`
 
@@ -129,13 +135,49 @@ type SchedulerConfig struct {
 
 	// Training configuration.
 	Training TrainingConfig `yaml:"training" mapstructure:"training"`
+
+	// Evaluator configuration.
+	Evaluator EvaluatorConfig `yaml:"evaluator" mapstructure:"evaluator"`
+}
+
+type EvaluatorConfig struct {
+	// FinishedPieceWeight is the weight of finished piece score.
+	FinishedPieceWeight float64 `yaml:"finishedPieceWeight" mapstructure:"finishedPieceWeight"`
+
+	// ParentHostUploadSuccessWeight is the weight of parent's host upload success score.
+	ParentHostUploadSuccessWeight float64 `yaml:"parentHostUploadSuccessWeight" mapstructure:"parentHostUploadSuccessWeight"`
+
+	// FreeUploadWeight is the weight of free upload score.
+	FreeUploadWeight float64 `yaml:"freeUploadWeight" mapstructure:"freeUploadWeight"`
+
+	// HostTypeWeight is the weight of host type score.
+	HostTypeWeight float64 `yaml:"hostTypeWeight" mapstructure:"hostTypeWeight"`
+
+	// IDCAffinityWeight is the weight of idc affinity score.
+	IDCAffinityWeight float64 `yaml:"idcAffinityWeight" mapstructure:"idcAffinityWeight"`
+
+	// NetTopologyAffinityWeight is the weight of net topology affinity score.
+	NetTopologyAffinityWeight float64 `yaml:"netTopologyAffinityWeight" mapstructure:"netTopologyAffinityWeight"`
+
+	// LocationAffinityWeight is the weight of location affinity score.
+	LocationAffinityWeight float64 `yaml:"locationAffinityWeight" mapstructure:"locationAffinityWeight"`
+
+	// CPULoadWeight is the weight of parent's host cpu idle score.
+	CPULoadWeight float64 `yaml:"cpuLoadWeight" mapstructure:"cpuLoadWeight"`
+
+	// MemoryPressureWeight is the weight of parent's host available memory score.
+	MemoryPressureWeight float64 `yaml:"memoryPressureWeight" mapstructure:"memoryPressureWeight"`
+
+	// DiskFreeWeight is the weight of parent's host free disk score.
+	DiskFreeWeight float64 `yaml:"diskFreeWeight" mapstructure:"diskFreeWeight"`
 }
 
 type TrainingConfig struct {
 	// Enable training.
 	Enable bool `yaml:"enable" mapstructure:"enable"`
 
-	// Enable auto refresh model.
+	// Enable auto refresh model, the trained model version is activated
+	// if it performs better than the active model version on the holdout set.
 	EnableAutoRefresh bool `yaml:"enableAutoRefresh" mapstructure:"enableAutoRefresh"`
 
 	// RefreshModelInterval is refresh interval for refreshing model.
@@ -143,6 +185,13 @@ type TrainingConfig struct {
 
 	// CPU limit while training.
 	CPU int `yaml:"cpu" mapstructure:"cpu"`
+
+	// HoldoutRatio is the ratio of records which are held out for evaluating model.
+	HoldoutRatio float64 `yaml:"holdoutRatio" mapstructure:"holdoutRatio"`
+
+	// MaxSamples is the maximum count of samples for training, if the records have more samples,
+	// the samples are selected randomly so that the memory of training is bounded.
+	MaxSamples int `yaml:"maxSamples" mapstructure:"maxSamples"`
 }
 
 type GCConfig struct {
@@ -192,6 +241,11 @@ type ManagerConfig struct {
 type SeedPeerConfig struct {
 	// Enable is to enable seed peer as P2P peer.
 	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// TriggerLimit is the limit of concurrent seed peer triggers, the waiting triggers
+	// are started in order of priority, Level5 triggers are not limited.
+	// If the value is zero, seed peer triggers are not limited.
+	TriggerLimit int `yaml:"triggerLimit" mapstructure:"triggerLimit"`
 }
 
 type KeepAliveConfig struct {
@@ -226,6 +280,95 @@ type StorageConfig struct {
 	// BufferSize sets the size of buffer container,
 	// if the buffer is full, write all the records in the buffer to the file.
 	BufferSize int `yaml:"bufferSize" mapstructure:"bufferSize"`
+
+	// MaxAge sets the maximum age of storage file, the storage file
+	// is rotated when it exceeds the maximum age.
+	MaxAge time.Duration `yaml:"maxAge" mapstructure:"maxAge"`
+
+	// FlushInterval sets the interval of writing the records in the buffer to the file.
+	FlushInterval time.Duration `yaml:"flushInterval" mapstructure:"flushInterval"`
+
+	// Format is the format of record file, supports csv, jsonl and parquet.
+	Format string `yaml:"format" mapstructure:"format"`
+
+	// Upload configuration, the rotated record files are uploaded to object storage.
+	Upload StorageUploadConfig `yaml:"upload" mapstructure:"upload"`
+}
+
+type StorageUploadConfig struct {
+	// Enable uploading record files to object storage.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Object storage name of type, it can be s3, oss or obs.
+	Name string `yaml:"name" mapstructure:"name"`
+
+	// Storage region.
+	Region string `yaml:"region" mapstructure:"region"`
+
+	// Datacenter endpoint.
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+
+	// Access key ID.
+	AccessKey string `yaml:"accessKey" mapstructure:"accessKey"`
+
+	// Access key secret.
+	SecretKey string `yaml:"secretKey" mapstructure:"secretKey"`
+
+	// BucketName is the bucket of record files.
+	BucketName string `yaml:"bucketName" mapstructure:"bucketName"`
+
+	// Prefix is the object key prefix of record files.
+	Prefix string `yaml:"prefix" mapstructure:"prefix"`
+}
+
+type SnapshotConfig struct {
+	// Enable snapshot of resource, scheduler restores hosts, tasks and peers from
+	// the snapshot when it restarts.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Backend is the type of snapshot backend, supports file and redis.
+	Backend string `yaml:"backend" mapstructure:"backend"`
+
+	// Interval is the interval of taking snapshot.
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+
+	// Filename is the snapshot file path of file backend,
+	// default value is the resource.snapshot file in the data directory.
+	Filename string `yaml:"filename" mapstructure:"filename"`
+
+	// Redis configuration of redis backend.
+	Redis SnapshotRedisConfig `yaml:"redis" mapstructure:"redis"`
+}
+
+type SnapshotRedisConfig struct {
+	// Server addresses.
+	Addrs []string `yaml:"addrs" mapstructure:"addrs"`
+
+	// Server username.
+	Username string `yaml:"username" mapstructure:"username"`
+
+	// Server password.
+	Password string `yaml:"password" mapstructure:"password"`
+
+	// DB is the database of snapshot.
+	DB int `yaml:"db" mapstructure:"db"`
+}
+
+type ClusterConfig struct {
+	// Enable shares task state with the other schedulers in the same scheduler cluster,
+	// when the task is not found, scheduler queries the peers of task from the other schedulers.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Addrs is the addresses of the other schedulers, if it is empty,
+	// the other schedulers are discovered by manager.
+	Addrs []string `yaml:"addrs" mapstructure:"addrs"`
+
+	// RefreshInterval is the interval of refreshing schedulers from manager.
+	RefreshInterval time.Duration `yaml:"refreshInterval" mapstructure:"refreshInterval"`
+
+	// Timeout is the timeout of querying the peers of task from the other schedulers,
+	// the registration of the unknown task waits for the query until timeout.
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
 }
 
 type RedisConfig struct {
@@ -318,6 +461,20 @@ func New() *Config {
 				EnableAutoRefresh:    false,
 				RefreshModelInterval: DefaultRefreshModelInterval,
 				CPU:                  DefaultCPU,
+				HoldoutRatio:         DefaultTrainingHoldoutRatio,
+				MaxSamples:           DefaultTrainingMaxSamples,
+			},
+			Evaluator: EvaluatorConfig{
+				FinishedPieceWeight:           DefaultEvaluatorFinishedPieceWeight,
+				ParentHostUploadSuccessWeight: DefaultEvaluatorParentHostUploadSuccessWeight,
+				FreeUploadWeight:              DefaultEvaluatorFreeUploadWeight,
+				HostTypeWeight:                DefaultEvaluatorHostTypeWeight,
+				IDCAffinityWeight:             DefaultEvaluatorIDCAffinityWeight,
+				NetTopologyAffinityWeight:     DefaultEvaluatorNetTopologyAffinityWeight,
+				LocationAffinityWeight:        DefaultEvaluatorLocationAffinityWeight,
+				CPULoadWeight:                 DefaultEvaluatorCPULoadWeight,
+				MemoryPressureWeight:          DefaultEvaluatorMemoryPressureWeight,
+				DiskFreeWeight:                DefaultEvaluatorDiskFreeWeight,
 			},
 		},
 		DynConfig: DynConfig{
@@ -331,7 +488,8 @@ func New() *Config {
 			},
 		},
 		SeedPeer: SeedPeerConfig{
-			Enable: true,
+			Enable:       true,
+			TriggerLimit: DefaultSeedPeerTriggerLimit,
 		},
 		Job: JobConfig{
 			Enable:             true,
@@ -344,9 +502,22 @@ func New() *Config {
 			},
 		},
 		Storage: StorageConfig{
-			MaxSize:    DefaultStorageMaxSize,
-			MaxBackups: DefaultStorageMaxBackups,
-			BufferSize: DefaultStorageBufferSize,
+			MaxSize:       DefaultStorageMaxSize,
+			MaxBackups:    DefaultStorageMaxBackups,
+			BufferSize:    DefaultStorageBufferSize,
+			MaxAge:        DefaultStorageMaxAge,
+			FlushInterval: DefaultStorageFlushInterval,
+			Format:        DefaultStorageFormat,
+		},
+		Snapshot: SnapshotConfig{
+			Enable:   false,
+			Backend:  DefaultSnapshotBackend,
+			Interval: DefaultSnapshotInterval,
+		},
+		Cluster: ClusterConfig{
+			Enable:          false,
+			RefreshInterval: DefaultClusterRefreshInterval,
+			Timeout:         DefaultClusterTimeout,
 		},
 		Metrics: MetricsConfig{
 			Enable:         false,
@@ -430,9 +601,30 @@ func (cfg *Config) Validate() error {
 			return errors.New("training requires parameter cpu")
 		}
 
-		if cfg.Scheduler.Training.EnableAutoRefresh && cfg.Scheduler.Training.RefreshModelInterval <= 0 {
+		if cfg.Scheduler.Training.RefreshModelInterval <= 0 {
 			return errors.New("training requires parameter refreshModelInterval")
 		}
+
+		if cfg.Scheduler.Training.HoldoutRatio <= 0 || cfg.Scheduler.Training.HoldoutRatio >= 1 {
+			return errors.New("training requires parameter holdoutRatio between 0 and 1")
+		}
+
+		if cfg.Scheduler.Training.MaxSamples <= 0 {
+			return errors.New("training requires parameter maxSamples")
+		}
+	}
+
+	if cfg.Scheduler.Evaluator.FinishedPieceWeight < 0 ||
+		cfg.Scheduler.Evaluator.ParentHostUploadSuccessWeight < 0 ||
+		cfg.Scheduler.Evaluator.FreeUploadWeight < 0 ||
+		cfg.Scheduler.Evaluator.HostTypeWeight < 0 ||
+		cfg.Scheduler.Evaluator.IDCAffinityWeight < 0 ||
+		cfg.Scheduler.Evaluator.NetTopologyAffinityWeight < 0 ||
+		cfg.Scheduler.Evaluator.LocationAffinityWeight < 0 ||
+		cfg.Scheduler.Evaluator.CPULoadWeight < 0 ||
+		cfg.Scheduler.Evaluator.MemoryPressureWeight < 0 ||
+		cfg.Scheduler.Evaluator.DiskFreeWeight < 0 {
+		return errors.New("evaluator requires parameter weights to be non-negative")
 	}
 
 	if cfg.DynConfig.RefreshInterval <= 0 {
@@ -491,6 +683,60 @@ func (cfg *Config) Validate() error {
 		return errors.New("storage requires parameter bufferSize")
 	}
 
+	if cfg.Storage.MaxAge <= 0 {
+		return errors.New("storage requires parameter maxAge")
+	}
+
+	if cfg.Storage.FlushInterval <= 0 {
+		return errors.New("storage requires parameter flushInterval")
+	}
+
+	switch cfg.Storage.Format {
+	case StorageFormatCSV, StorageFormatJSONL, StorageFormatParquet:
+	default:
+		return fmt.Errorf("storage does not support format %s", cfg.Storage.Format)
+	}
+
+	if cfg.Storage.Upload.Enable {
+		if cfg.Storage.Upload.Name == "" {
+			return errors.New("storage requires parameter upload name")
+		}
+
+		if cfg.Storage.Upload.BucketName == "" {
+			return errors.New("storage requires parameter upload bucketName")
+		}
+	}
+
+	if cfg.Snapshot.Enable {
+		switch cfg.Snapshot.Backend {
+		case SnapshotBackendFile:
+		case SnapshotBackendRedis:
+			if len(cfg.Snapshot.Redis.Addrs) == 0 {
+				return errors.New("snapshot requires parameter redis addrs")
+			}
+		default:
+			return fmt.Errorf("snapshot does not support backend %s", cfg.Snapshot.Backend)
+		}
+
+		if cfg.Snapshot.Interval <= 0 {
+			return errors.New("snapshot requires parameter interval")
+		}
+	}
+
+	if cfg.SeedPeer.TriggerLimit < 0 {
+		return errors.New("seedPeer requires parameter triggerLimit")
+	}
+
+	if cfg.Cluster.Enable {
+		if cfg.Cluster.RefreshInterval <= 0 {
+			return errors.New("cluster requires parameter refreshInterval")
+		}
+
+		if cfg.Cluster.Timeout <= 0 {
+			return errors.New("cluster requires parameter timeout")
+		}
+	}
+
 	if cfg.Metrics.Enable {
 		if cfg.Metrics.Addr == "" {
 			return errors.New("metrics requires parameter addr")
@@ -48,6 +48,20 @@ func TestConfig_Load(t *testing.T) {
 				EnableAutoRefresh:    true,
 				RefreshModelInterval: 1 * time.Second,
 				CPU:                  2,
+				HoldoutRatio:         0.3,
+				MaxSamples:           1000,
+			},
+			Evaluator: EvaluatorConfig{
+				FinishedPieceWeight:           0.2,
+				ParentHostUploadSuccessWeight: 0.2,
+				FreeUploadWeight:              0.15,
+				HostTypeWeight:                0.15,
+				IDCAffinityWeight:             0.1,
+				NetTopologyAffinityWeight:     0.1,
+				LocationAffinityWeight:        0.1,
+				CPULoadWeight:                 0.1,
+				MemoryPressureWeight:          0.1,
+				DiskFreeWeight:                0.1,
 			},
 		},
 		Server: ServerConfig{
@@ -72,7 +86,8 @@ func TestConfig_Load(t *testing.T) {
 			},
 		},
 		SeedPeer: SeedPeerConfig{
-			Enable: true,
+			Enable:       true,
+			TriggerLimit: 10,
 		},
 		Host: HostConfig{
 			IDC:         "foo",
@@ -94,9 +109,40 @@ func TestConfig_Load(t *testing.T) {
 			},
 		},
 		Storage: StorageConfig{
-			MaxSize:    1,
-			MaxBackups: 1,
-			BufferSize: 1,
+			MaxSize:       1,
+			MaxBackups:    1,
+			BufferSize:    1,
+			MaxAge:        1000,
+			FlushInterval: 1000,
+			Format:        "parquet",
+			Upload: StorageUploadConfig{
+				Enable:     true,
+				Name:       "s3",
+				Region:     "foo",
+				Endpoint:   "bar",
+				AccessKey:  "baz",
+				SecretKey:  "qux",
+				BucketName: "quux",
+				Prefix:     "corge",
+			},
+		},
+		Snapshot: SnapshotConfig{
+			Enable:   true,
+			Backend:  "redis",
+			Interval: 1 * time.Minute,
+			Filename: "foo",
+			Redis: SnapshotRedisConfig{
+				Addrs:    []string{"foo", "bar"},
+				Username: "foo",
+				Password: "bar",
+				DB:       3,
+			},
+		},
+		Cluster: ClusterConfig{
+			Enable:          true,
+			Addrs:           []string{"foo", "bar"},
+			RefreshInterval: 1 * time.Minute,
+			Timeout:         1 * time.Second,
 		},
 		Metrics: MetricsConfig{
 			Enable:         false,
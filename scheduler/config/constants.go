@@ -24,6 +24,9 @@ const (
 	// DefaultSeedPeerConcurrentUploadLimit is default number for seed peer concurrent upload limit.
 	DefaultSeedPeerConcurrentUploadLimit = 300
 
+	// DefaultSeedPeerTriggerLimit is default number for concurrent seed peer triggers.
+	DefaultSeedPeerTriggerLimit = 100
+
 	// DefaultPeerConcurrentUploadLimit is default number for peer concurrent upload limit.
 	DefaultPeerConcurrentUploadLimit = 50
 
@@ -78,6 +81,44 @@ const (
 
 	// DefaultCPU is default cpu usage.
 	DefaultCPU = 1
+
+	// DefaultTrainingHoldoutRatio is default ratio of records held out for evaluating model.
+	DefaultTrainingHoldoutRatio = 0.2
+
+	// DefaultTrainingMaxSamples is default maximum count of samples for training.
+	DefaultTrainingMaxSamples = 100000
+)
+
+const (
+	// DefaultEvaluatorFinishedPieceWeight is default weight of finished piece score.
+	DefaultEvaluatorFinishedPieceWeight float64 = 0.2
+
+	// DefaultEvaluatorParentHostUploadSuccessWeight is default weight of parent's host upload success score.
+	DefaultEvaluatorParentHostUploadSuccessWeight float64 = 0.2
+
+	// DefaultEvaluatorFreeUploadWeight is default weight of free upload score.
+	DefaultEvaluatorFreeUploadWeight float64 = 0.15
+
+	// DefaultEvaluatorHostTypeWeight is default weight of host type score.
+	DefaultEvaluatorHostTypeWeight float64 = 0.15
+
+	// DefaultEvaluatorIDCAffinityWeight is default weight of idc affinity score.
+	DefaultEvaluatorIDCAffinityWeight float64 = 0.1
+
+	// DefaultEvaluatorNetTopologyAffinityWeight is default weight of net topology affinity score.
+	DefaultEvaluatorNetTopologyAffinityWeight float64 = 0.1
+
+	// DefaultEvaluatorLocationAffinityWeight is default weight of location affinity score.
+	DefaultEvaluatorLocationAffinityWeight float64 = 0.1
+
+	// DefaultEvaluatorCPULoadWeight is default weight of cpu load score, it is disabled by default.
+	DefaultEvaluatorCPULoadWeight float64 = 0
+
+	// DefaultEvaluatorMemoryPressureWeight is default weight of memory pressure score, it is disabled by default.
+	DefaultEvaluatorMemoryPressureWeight float64 = 0
+
+	// DefaultEvaluatorDiskFreeWeight is default weight of disk free score, it is disabled by default.
+	DefaultEvaluatorDiskFreeWeight float64 = 0
 )
 
 const (
@@ -134,4 +175,49 @@ const (
 
 	// DefaultStorageBufferSize is the default size of buffer container.
 	DefaultStorageBufferSize = 100
+
+	// DefaultStorageMaxAge is the default maximum age of record file.
+	DefaultStorageMaxAge = time.Hour
+
+	// DefaultStorageFlushInterval is the default interval of writing buffered records to file.
+	DefaultStorageFlushInterval = 10 * time.Second
+
+	// DefaultStorageFormat is the default format of record file.
+	DefaultStorageFormat = StorageFormatCSV
+)
+
+const (
+	// StorageFormatCSV is the csv format of record file.
+	StorageFormatCSV = "csv"
+
+	// StorageFormatJSONL is the gzip compressed json lines format of record file.
+	StorageFormatJSONL = "jsonl"
+
+	// StorageFormatParquet is the parquet format of record file.
+	StorageFormatParquet = "parquet"
+)
+
+const (
+	// SnapshotBackendFile is the snapshot backend of local file.
+	SnapshotBackendFile = "file"
+
+	// SnapshotBackendRedis is the snapshot backend of redis.
+	SnapshotBackendRedis = "redis"
+)
+
+const (
+	// DefaultSnapshotBackend is the default backend of snapshot.
+	DefaultSnapshotBackend = SnapshotBackendFile
+
+	// DefaultSnapshotInterval is the default interval of taking snapshot.
+	DefaultSnapshotInterval = 1 * time.Minute
+)
+
+const (
+	// DefaultClusterRefreshInterval is the default interval of refreshing schedulers in cluster.
+	DefaultClusterRefreshInterval = 1 * time.Minute
+
+	// DefaultClusterTimeout is the default timeout of querying the peers of task from the other schedulers,
+	// it is short because the registration of the unknown task waits for the query.
+	DefaultClusterTimeout = 500 * time.Millisecond
 )
@@ -233,16 +233,7 @@ func (d *dynconfig) GetSchedulerClusterConfig() (types.SchedulerClusterConfig, e
 		return types.SchedulerClusterConfig{}, err
 	}
 
-	if data.Scheduler.SchedulerCluster == nil {
-		return types.SchedulerClusterConfig{}, errors.New("invalid scheduler cluster")
-	}
-
-	var config types.SchedulerClusterConfig
-	if err := json.Unmarshal(data.Scheduler.SchedulerCluster.Config, &config); err != nil {
-		return types.SchedulerClusterConfig{}, err
-	}
-
-	return config, nil
+	return GetSchedulerClusterConfigByScheduler(data.Scheduler)
 }
 
 // GetSchedulerClusterClientConfig returns the client config.
@@ -405,3 +396,21 @@ func GetSeedPeerClusterConfigBySeedPeer(seedPeer *managerv1.SeedPeer) (types.See
 
 	return config, nil
 }
+
+// GetSchedulerClusterConfigByScheduler returns the scheduler cluster config by scheduler.
+func GetSchedulerClusterConfigByScheduler(scheduler *managerv1.Scheduler) (types.SchedulerClusterConfig, error) {
+	if scheduler == nil {
+		return types.SchedulerClusterConfig{}, errors.New("invalid scheduler")
+	}
+
+	if scheduler.SchedulerCluster == nil {
+		return types.SchedulerClusterConfig{}, errors.New("invalid scheduler cluster")
+	}
+
+	var config types.SchedulerClusterConfig
+	if err := json.Unmarshal(scheduler.SchedulerCluster.Config, &config); err != nil {
+		return types.SchedulerClusterConfig{}, err
+	}
+
+	return config, nil
+}
@@ -21,19 +21,33 @@ package job
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/RichardKnop/machinery/v1"
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
 	"github.com/go-http-utils/headers"
 	"github.com/go-playground/validator/v10"
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	internaljob "d7y.io/dragonfly/v2/internal/job"
 	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
+	dfdaemonclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 )
@@ -41,21 +55,68 @@ import (
 const (
 	// preheatTimeout is timeout of preheating.
 	preheatTimeout = 20 * time.Minute
+
+	// preheatProgressInterval is the minimum interval of reporting preheat progress.
+	preheatProgressInterval = 1 * time.Second
+
+	// preheatProgressTimeout is timeout of reporting the final preheat progress.
+	preheatProgressTimeout = 5 * time.Second
+
+	// canceledCheckInterval is the interval of checking whether the running job is canceled.
+	canceledCheckInterval = 1 * time.Second
+
+	// deleteTaskTimeout is timeout of deleting task.
+	deleteTaskTimeout = 10 * time.Minute
+
+	// deleteTaskConcurrency is the number of hosts deleting task concurrently.
+	deleteTaskConcurrency = 16
 )
 
+// errJobCanceled is the error of job canceled by manager.
+var errJobCanceled = errors.New("job is canceled")
+
 type Job interface {
 	Serve()
 }
 
+// groupJob is the state of group jobs in the result backend, which is shared by manager and schedulers.
+type groupJob interface {
+	// IsGroupJobCanceled returns whether the jobs in the group are canceled.
+	IsGroupJobCanceled(ctx context.Context, groupID string) (bool, error)
+
+	// SetPreheatProgress stores the progress of preheat job in the group.
+	SetPreheatProgress(ctx context.Context, groupID, jobID string, progress *internaljob.PreheatProgress) error
+}
+
 type job struct {
 	globalJob    *internaljob.Job
 	schedulerJob *internaljob.Job
 	localJob     *internaljob.Job
 	resource     resource.Resource
 	config       *config.Config
+
+	// groupJob is the state of group jobs of local queue.
+	groupJob groupJob
+
+	// dfdaemonClient returns the dfdaemon client of the host by the address.
+	dfdaemonClient func(ctx context.Context, target string, opts ...grpc.DialOption) (dfdaemonclient.Client, error)
+
+	// transportCredentials is the credentials of connection to dfdaemon.
+	transportCredentials credentials.TransportCredentials
+}
+
+// Option is a functional option for configuring the job.
+type Option func(j *job)
+
+// WithTransportCredentials returns an Option which configures a connection
+// level security credentials (e.g., TLS/SSL).
+func WithTransportCredentials(creds credentials.TransportCredentials) Option {
+	return func(j *job) {
+		j.transportCredentials = creds
+	}
 }
 
-func New(cfg *config.Config, resource resource.Resource) (Job, error) {
+func New(cfg *config.Config, resource resource.Resource, options ...Option) (Job, error) {
 	redisConfig := &internaljob.Config{
 		Addrs:     cfg.Job.Redis.Addrs,
 		Username:  cfg.Job.Redis.Username,
@@ -92,19 +153,27 @@ func New(cfg *config.Config, resource resource.Resource) (Job, error) {
 	logger.Infof("create local job queue: %v", localQueue)
 
 	t := &job{
-		globalJob:    globalJob,
-		schedulerJob: schedulerJob,
-		localJob:     localJob,
-		resource:     resource,
-		config:       cfg,
+		globalJob:      globalJob,
+		schedulerJob:   schedulerJob,
+		localJob:       localJob,
+		resource:       resource,
+		config:         cfg,
+		groupJob:       localJob,
+		dfdaemonClient: dfdaemonclient.GetClient,
+	}
+
+	for _, opt := range options {
+		opt(t)
 	}
 
 	namedJobFuncs := map[string]any{
-		internaljob.PreheatJob: t.preheat,
+		internaljob.PreheatJob:    t.preheat,
+		internaljob.DeleteTaskJob: t.deleteTask,
+		internaljob.GetTaskJob:    t.getTask,
 	}
 
 	if err := localJob.RegisterJob(namedJobFuncs); err != nil {
-		logger.Errorf("register preheat job to local queue error: %s", err.Error())
+		logger.Errorf("register jobs to local queue error: %s", err.Error())
 		return nil, err
 	}
 
@@ -159,44 +228,415 @@ func (j *job) preheat(ctx context.Context, req string) error {
 		return err
 	}
 
-	urlMeta := &commonv1.UrlMeta{
-		Header: preheat.Headers,
-		Tag:    preheat.Tag,
-		Filter: preheat.Filter,
-		Digest: preheat.Digest,
-	}
-	if preheat.Headers != nil {
-		if r, ok := preheat.Headers[headers.Range]; ok {
-			// Range in dragonfly is without "bytes=".
-			urlMeta.Range = strings.TrimLeft(r, "bytes=")
-		}
-	}
+	urlMeta := newURLMeta(preheat.Headers, preheat.Tag, preheat.Filter, preheat.Digest)
 
 	// Trigger seed peer download seeds.
 	taskID := idgen.TaskID(preheat.URL, urlMeta)
 	log := logger.WithTask(taskID, preheat.URL)
-	log.Infof("preheat %s headers: %#v, tag: %s, range: %s, filter: %s, digest: %s",
-		preheat.URL, urlMeta.Header, urlMeta.Tag, urlMeta.Range, urlMeta.Filter, urlMeta.Digest)
-	stream, err := j.resource.SeedPeer().Client().ObtainSeeds(ctx, &cdnsystemv1.SeedRequest{
+	log.Infof("preheat %s tag: %s, range: %s, filter: %s, digest: %s",
+		preheat.URL, urlMeta.Tag, urlMeta.Range, urlMeta.Filter, urlMeta.Digest)
+
+	// The pending job is skipped and the running job is stopped if the job is canceled.
+	ctx, watcher := j.watchCanceled(ctx)
+	defer watcher.stop()
+
+	reporter := j.newPreheatReporter(ctx, preheat.URL)
+	if watcher.canceled() {
+		log.Warnf("preheat %s is skipped: %s", preheat.URL, errJobCanceled.Error())
+		reporter.fail(errJobCanceled)
+		return errJobCanceled
+	}
+
+	// The seed peer cancels downloading if the preheat is stopped.
+	stream, err := j.resource.SeedPeer().Client().ObtainSeeds(metadata.AppendToOutgoingContext(ctx, common.CancelSeedOnDisconnectKey, "true"), &cdnsystemv1.SeedRequest{
 		TaskId:  taskID,
 		Url:     preheat.URL,
 		UrlMeta: urlMeta,
 	})
 	if err != nil {
+		if watcher.canceled() {
+			err = errJobCanceled
+		}
+
 		log.Errorf("preheat %s failed: %s", preheat.URL, err.Error())
+		reporter.fail(err)
 		return err
 	}
 
 	for {
 		piece, err := stream.Recv()
 		if err != nil {
+			if watcher.canceled() {
+				err = errJobCanceled
+			}
+
 			log.Errorf("preheat %s recive piece failed: %s", preheat.URL, err.Error())
+			reporter.fail(err)
 			return err
 		}
 
+		reporter.update(piece)
 		if piece.Done == true {
 			log.Infof("preheat %s succeeded", preheat.URL)
+			reporter.succeed()
+			return nil
+		}
+	}
+}
+
+// deleteTask deletes the task in the hosts of peers which are known from the dag of task,
+// then evicts the task and peers from the resource of scheduler.
+func (j *job) deleteTask(ctx context.Context, req string) error {
+	ctx, cancel := context.WithTimeout(ctx, deleteTaskTimeout)
+	defer cancel()
+
+	deleteTask := &internaljob.DeleteTaskRequest{}
+	if err := internaljob.UnmarshalRequest(req, deleteTask); err != nil {
+		logger.Errorf("unmarshal request err: %s, request body: %s", err.Error(), req)
+		return err
+	}
+
+	if err := validator.New().Struct(deleteTask); err != nil {
+		logger.Errorf("delete task %s validate failed: %s", deleteTask.URL, err.Error())
+		return err
+	}
+
+	urlMeta := newURLMeta(deleteTask.Headers, deleteTask.Tag, deleteTask.Filter, deleteTask.Digest)
+	taskID := idgen.TaskID(deleteTask.URL, urlMeta)
+	log := logger.WithTask(taskID, deleteTask.URL)
+
+	task, ok := j.resource.TaskManager().Load(taskID)
+	if !ok {
+		log.Infof("task %s is not found in scheduler", taskID)
+		return nil
+	}
+
+	var (
+		peers []*resource.Peer
+		hosts = map[string]*resource.Host{}
+	)
+	for _, vertex := range task.DAG.GetVertices() {
+		peer := vertex.Value
+		if peer == nil {
+			continue
+		}
+
+		peers = append(peers, peer)
+		hosts[peer.Host.ID] = peer.Host
+	}
+
+	var (
+		mu     sync.Mutex
+		result error
+	)
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(deleteTaskConcurrency)
+	for _, host := range hosts {
+		host := host
+		eg.Go(func() error {
+			if err := j.deleteTaskInHost(egCtx, host, deleteTask.URL, urlMeta); err != nil {
+				log.Errorf("delete task in host %s failed: %s", host.ID, err.Error())
+				mu.Lock()
+				result = multierror.Append(result, fmt.Errorf("host %s: %w", host.Hostname, err))
+				mu.Unlock()
+				return nil
+			}
+
+			log.Infof("delete task in host %s succeeded", host.ID)
 			return nil
+		})
+	}
+	eg.Wait() // nolint: errcheck
+
+	// Evict the task from scheduler even if some hosts failed, the hosts
+	// still holding the task will register the task again when they are used.
+	for _, peer := range peers {
+		// Notify the running peer that the task is deleted, so that it stops
+		// reporting piece results to the evicted peer.
+		if peer.FSM.Is(resource.PeerStateRunning) || peer.FSM.Is(resource.PeerStateBackToSource) {
+			if stream, ok := peer.LoadStream(); ok {
+				if err := stream.Send(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedTaskStatusError}); err != nil {
+					peer.Log.Errorf("send packet failed: %s", err.Error())
+				}
+			}
+		}
+
+		if !peer.FSM.Is(resource.PeerStateLeave) {
+			if err := peer.FSM.Event(resource.PeerEventLeave); err != nil {
+				peer.Log.Warnf("peer fsm event failed: %s", err.Error())
+			}
+		}
+
+		j.resource.PeerManager().Delete(peer.ID)
+	}
+
+	if !task.FSM.Is(resource.TaskStateLeave) {
+		if err := task.FSM.Event(resource.TaskEventLeave); err != nil {
+			log.Warnf("task fsm event failed: %s", err.Error())
+		}
+	}
+	j.resource.TaskManager().Delete(taskID)
+
+	log.Infof("delete task in %d hosts and evict %d peers", len(hosts), len(peers))
+	return result
+}
+
+// getTask returns the task and its peers in the resource of scheduler,
+// the task without peers is returned if the task is not found.
+func (j *job) getTask(ctx context.Context, req string) (string, error) {
+	getTask := &internaljob.GetTaskRequest{}
+	if err := internaljob.UnmarshalRequest(req, getTask); err != nil {
+		logger.Errorf("unmarshal request err: %s, request body: %s", err.Error(), req)
+		return "", err
+	}
+
+	if err := validator.New().Struct(getTask); err != nil {
+		logger.Errorf("get task %s validate failed: %s", getTask.URL, err.Error())
+		return "", err
+	}
+
+	taskID := getTask.TaskID
+	if taskID == "" {
+		taskID = idgen.TaskID(getTask.URL, newURLMeta(getTask.Headers, getTask.Tag, getTask.Filter, getTask.Digest))
+	}
+
+	resp := &internaljob.GetTaskResponse{
+		SchedulerClusterID: j.config.Manager.SchedulerClusterID,
+		SchedulerHostname:  j.config.Server.Host,
+		TaskID:             taskID,
+		ContentLength:      -1,
+		TotalPieces:        -1,
+		Peers:              []*internaljob.GetTaskPeer{},
+	}
+
+	task, ok := j.resource.TaskManager().Load(taskID)
+	if !ok {
+		logger.WithTaskID(taskID).Info("task is not found in scheduler")
+		return internaljob.MarshalResponse(resp)
+	}
+
+	resp.State = task.FSM.Current()
+	resp.ContentLength = task.ContentLength.Load()
+	resp.TotalPieces = task.TotalPieceCount.Load()
+	for _, vertex := range task.DAG.GetVertices() {
+		peer := vertex.Value
+		if peer == nil {
+			continue
+		}
+
+		resp.Peers = append(resp.Peers, &internaljob.GetTaskPeer{
+			ID:             peer.ID,
+			State:          peer.FSM.Current(),
+			FinishedPieces: int32(peer.FinishedPieces.Count()),
+			HostID:         peer.Host.ID,
+			Hostname:       peer.Host.Hostname,
+			IP:             peer.Host.IP,
+			HostType:       peer.Host.Type.Name(),
+			UpdatedAt:      peer.UpdatedAt.Load(),
+		})
+	}
+
+	return internaljob.MarshalResponse(resp)
+}
+
+// dialOptions returns the dial options of dfdaemon.
+func (j *job) dialOptions() []grpc.DialOption {
+	if j.transportCredentials != nil {
+		return []grpc.DialOption{grpc.WithTransportCredentials(j.transportCredentials)}
+	}
+
+	return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+}
+
+// deleteTaskInHost deletes the task in the host by the dfdaemon DeleteTask rpc.
+func (j *job) deleteTaskInHost(ctx context.Context, host *resource.Host, url string, urlMeta *commonv1.UrlMeta) error {
+	client, err := j.dfdaemonClient(ctx, fmt.Sprintf("%s:%d", host.IP, host.Port), j.dialOptions()...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return client.DeleteTask(ctx, &dfdaemonv1.DeleteTaskRequest{
+		Url:     url,
+		UrlMeta: urlMeta,
+	})
+}
+
+// newURLMeta returns the url meta of task, the range of url meta is parsed from headers.
+func newURLMeta(header map[string]string, tag, filter, digest string) *commonv1.UrlMeta {
+	urlMeta := &commonv1.UrlMeta{
+		Header: header,
+		Tag:    tag,
+		Filter: filter,
+		Digest: digest,
+	}
+	if header != nil {
+		if r, ok := header[headers.Range]; ok {
+			// Range in dragonfly is without "bytes=".
+			urlMeta.Range = strings.TrimLeft(r, "bytes=")
+		}
+	}
+
+	return urlMeta
+}
+
+// canceledWatcher cancels the context of job when the group of job is canceled by manager.
+type canceledWatcher struct {
+	cancel     context.CancelFunc
+	done       chan struct{}
+	isCanceled atomic.Bool
+}
+
+// watchCanceled checks whether the group of job is canceled by interval,
+// the returned context is canceled when the job is canceled.
+func (j *job) watchCanceled(ctx context.Context) (context.Context, *canceledWatcher) {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &canceledWatcher{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	signature := machineryv1tasks.SignatureFromContext(ctx)
+	if signature == nil || signature.GroupUUID == "" {
+		return ctx, w
+	}
+
+	check := func() bool {
+		canceled, err := j.groupJob.IsGroupJobCanceled(ctx, signature.GroupUUID)
+		if err != nil {
+			logger.Warnf("check group %s canceled failed: %s", signature.GroupUUID, err.Error())
+			return false
+		}
+
+		if canceled {
+			w.isCanceled.Store(true)
+			cancel()
+		}
+
+		return canceled
+	}
+
+	if check() {
+		return ctx, w
+	}
+
+	go func() {
+		ticker := time.NewTicker(canceledCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if check() {
+					return
+				}
+			case <-w.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ctx, w
+}
+
+// canceled returns whether the job is canceled.
+func (w *canceledWatcher) canceled() bool {
+	return w.isCanceled.Load()
+}
+
+// stop stops watching and releases the context.
+func (w *canceledWatcher) stop() {
+	close(w.done)
+	w.cancel()
+}
+
+// preheatReporter reports the progress of preheat job to the result backend,
+// the progresses are read by manager to show the state of every url in the group.
+type preheatReporter struct {
+	ctx        context.Context
+	job        groupJob
+	resource   resource.Resource
+	signature  *machineryv1tasks.Signature
+	progress   *internaljob.PreheatProgress
+	reportedAt time.Time
+}
+
+// newPreheatReporter returns a new preheatReporter by the signature of job in ctx.
+func (j *job) newPreheatReporter(ctx context.Context, url string) *preheatReporter {
+	r := &preheatReporter{
+		ctx:       ctx,
+		job:       j.groupJob,
+		resource:  j.resource,
+		signature: machineryv1tasks.SignatureFromContext(ctx),
+		progress: &internaljob.PreheatProgress{
+			URL:           url,
+			State:         machineryv1tasks.StateStarted,
+			ContentLength: -1,
+			TotalPieces:   -1,
+		},
+	}
+
+	r.report(r.ctx)
+	return r
+}
+
+// update accumulates the piece downloaded by seed peer, the progress is reported by interval.
+func (r *preheatReporter) update(piece *cdnsystemv1.PieceSeed) {
+	// The begin piece only stands the seed peer starts downloading.
+	if piece.PieceInfo == nil || piece.PieceInfo.PieceNum != common.BeginOfPiece {
+		if piece.PieceInfo != nil && piece.PieceInfo.PieceNum != common.EndOfPiece {
+			r.progress.PiecesDone++
+			r.progress.BytesDone += int64(piece.PieceInfo.RangeSize)
+		}
+
+		r.progress.ContentLength = piece.ContentLength
+		r.progress.TotalPieces = piece.TotalPieceCount
+	}
+
+	if r.progress.SeedPeerHost == "" && piece.HostId != "" {
+		r.progress.SeedPeerHost = piece.HostId
+		if host, ok := r.resource.HostManager().Load(piece.HostId); ok {
+			r.progress.SeedPeerHost = host.Hostname
 		}
 	}
+
+	if time.Since(r.reportedAt) >= preheatProgressInterval {
+		r.report(r.ctx)
+	}
+}
+
+// succeed reports the progress of succeeded preheat.
+func (r *preheatReporter) succeed() {
+	r.progress.State = machineryv1tasks.StateSuccess
+	r.reportFinal()
+}
+
+// fail reports the progress of failed preheat with the error.
+func (r *preheatReporter) fail(err error) {
+	r.progress.State = machineryv1tasks.StateFailure
+	r.progress.Error = err.Error()
+	r.reportFinal()
+}
+
+// reportFinal reports the progress even though the preheat context is done.
+func (r *preheatReporter) reportFinal() {
+	ctx, cancel := context.WithTimeout(context.Background(), preheatProgressTimeout)
+	defer cancel()
+
+	r.report(ctx)
+}
+
+// report stores the progress, the failure of reporting does not affect the preheat.
+func (r *preheatReporter) report(ctx context.Context) {
+	if r.signature == nil || r.signature.GroupUUID == "" {
+		return
+	}
+
+	r.reportedAt = time.Now()
+	r.progress.UpdatedAt = r.reportedAt
+	if err := r.job.SetPreheatProgress(ctx, r.signature.GroupUUID, r.signature.UUID, r.progress); err != nil {
+		logger.Warnf("report preheat %s progress failed: %s", r.progress.URL, err.Error())
+	}
 }
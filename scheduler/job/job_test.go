@@ -0,0 +1,424 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
+	cdnsystemv1mocks "d7y.io/api/pkg/apis/cdnsystem/v1/mocks"
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+	schedulerv1mocks "d7y.io/api/pkg/apis/scheduler/v1/mocks"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
+	dfdaemonclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
+	dfdaemonmocks "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client/mocks"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+// fakeGroupJob is the state of group jobs in memory.
+type fakeGroupJob struct {
+	canceled   atomic.Bool
+	mu         sync.Mutex
+	progresses []internaljob.PreheatProgress
+}
+
+func (f *fakeGroupJob) IsGroupJobCanceled(ctx context.Context, groupID string) (bool, error) {
+	return f.canceled.Load(), nil
+}
+
+func (f *fakeGroupJob) SetPreheatProgress(ctx context.Context, groupID, jobID string, progress *internaljob.PreheatProgress) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.progresses = append(f.progresses, *progress)
+	return nil
+}
+
+func TestJob_preheatCanceled(t *testing.T) {
+	assert := assert.New(t)
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	res := resource.NewMockResource(ctl)
+	seedPeer := resource.NewMockSeedPeer(ctl)
+	client := resource.NewMockSeedPeerClient(ctl)
+	stream := cdnsystemv1mocks.NewMockSeeder_ObtainSeedsClient(ctl)
+	res.EXPECT().SeedPeer().Return(seedPeer).AnyTimes()
+	seedPeer.EXPECT().Client().Return(client).AnyTimes()
+
+	gj := &fakeGroupJob{}
+	streaming := make(chan struct{})
+	client.EXPECT().ObtainSeeds(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *cdnsystemv1.SeedRequest, opts ...grpc.CallOption) (cdnsystemv1.Seeder_ObtainSeedsClient, error) {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			assert.Equal([]string{"true"}, md.Get(common.CancelSeedOnDisconnectKey))
+
+			gomock.InOrder(
+				stream.EXPECT().Recv().DoAndReturn(func() (*cdnsystemv1.PieceSeed, error) {
+					return &cdnsystemv1.PieceSeed{PieceInfo: &commonv1.PieceInfo{PieceNum: 0, RangeSize: 4}, ContentLength: 8, TotalPieceCount: 2}, nil
+				}),
+				stream.EXPECT().Recv().DoAndReturn(func() (*cdnsystemv1.PieceSeed, error) {
+					// The group is canceled while seed peer is downloading.
+					close(streaming)
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}),
+			)
+			return stream, nil
+		}).Times(1)
+
+	go func() {
+		<-streaming
+		gj.canceled.Store(true)
+	}()
+
+	args, err := internaljob.MarshalRequest(internaljob.PreheatRequest{URL: "http://example.com/foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := &job{resource: res, groupJob: gj, config: &config.Config{SeedPeer: config.SeedPeerConfig{Enable: true}}}
+	task, err := machineryv1tasks.NewWithSignature(j.preheat, &machineryv1tasks.Signature{UUID: "foo", GroupUUID: "bar", Args: args})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = task.Call()
+	assert.ErrorIs(err, errJobCanceled)
+
+	gj.mu.Lock()
+	defer gj.mu.Unlock()
+	progress := gj.progresses[len(gj.progresses)-1]
+	assert.Equal(machineryv1tasks.StateFailure, progress.State)
+	assert.Equal(errJobCanceled.Error(), progress.Error)
+	assert.Equal(int32(1), progress.PiecesDone)
+}
+
+func TestJob_preheatReporter(t *testing.T) {
+	mockHost := resource.NewHost(&schedulerv1.AnnounceHostRequest{Id: "foo", Hostname: "bar"})
+	tests := []struct {
+		name   string
+		pieces []*cdnsystemv1.PieceSeed
+		err    error
+		mock   func(mh *resource.MockHostManagerMockRecorder)
+		expect func(t *testing.T, progress *internaljob.PreheatProgress)
+	}{
+		{
+			name: "seed peer starts downloading",
+			pieces: []*cdnsystemv1.PieceSeed{
+				{HostId: "foo", PieceInfo: &commonv1.PieceInfo{PieceNum: common.BeginOfPiece}},
+			},
+			mock: func(mh *resource.MockHostManagerMockRecorder) {
+				mh.Load(gomock.Eq("foo")).Return(mockHost, true).Times(1)
+			},
+			expect: func(t *testing.T, progress *internaljob.PreheatProgress) {
+				assert := assert.New(t)
+				assert.Equal(machineryv1tasks.StateStarted, progress.State)
+				assert.Equal("bar", progress.SeedPeerHost)
+				assert.Equal(int64(-1), progress.ContentLength)
+				assert.Equal(int32(-1), progress.TotalPieces)
+				assert.Equal(int32(0), progress.PiecesDone)
+			},
+		},
+		{
+			name: "seed peer downloads pieces",
+			pieces: []*cdnsystemv1.PieceSeed{
+				{HostId: "foo", PieceInfo: &commonv1.PieceInfo{PieceNum: common.BeginOfPiece}},
+				{HostId: "foo", PieceInfo: &commonv1.PieceInfo{PieceNum: 0, RangeSize: 4}, ContentLength: -1, TotalPieceCount: -1},
+				{HostId: "foo", PieceInfo: &commonv1.PieceInfo{PieceNum: 1, RangeSize: 2}, ContentLength: 6, TotalPieceCount: 2, Done: true},
+			},
+			mock: func(mh *resource.MockHostManagerMockRecorder) {
+				mh.Load(gomock.Eq("foo")).Return(nil, false).Times(1)
+			},
+			expect: func(t *testing.T, progress *internaljob.PreheatProgress) {
+				assert := assert.New(t)
+				assert.Equal(machineryv1tasks.StateSuccess, progress.State)
+				assert.Equal("foo", progress.SeedPeerHost)
+				assert.Equal(int64(6), progress.ContentLength)
+				assert.Equal(int64(6), progress.BytesDone)
+				assert.Equal(int32(2), progress.TotalPieces)
+				assert.Equal(int32(2), progress.PiecesDone)
+			},
+		},
+		{
+			name: "seed peer reuses completed task",
+			pieces: []*cdnsystemv1.PieceSeed{
+				{HostId: "foo", ContentLength: 0, TotalPieceCount: 0, Done: true},
+			},
+			mock: func(mh *resource.MockHostManagerMockRecorder) {
+				mh.Load(gomock.Eq("foo")).Return(mockHost, true).Times(1)
+			},
+			expect: func(t *testing.T, progress *internaljob.PreheatProgress) {
+				assert := assert.New(t)
+				assert.Equal(machineryv1tasks.StateSuccess, progress.State)
+				assert.Equal(int64(0), progress.ContentLength)
+				assert.Equal(int32(0), progress.TotalPieces)
+			},
+		},
+		{
+			name: "preheat failed",
+			pieces: []*cdnsystemv1.PieceSeed{
+				{HostId: "foo", PieceInfo: &commonv1.PieceInfo{PieceNum: 0, RangeSize: 4}, ContentLength: 8, TotalPieceCount: 2},
+			},
+			err: errors.New("baz"),
+			mock: func(mh *resource.MockHostManagerMockRecorder) {
+				mh.Load(gomock.Eq("foo")).Return(mockHost, true).Times(1)
+			},
+			expect: func(t *testing.T, progress *internaljob.PreheatProgress) {
+				assert := assert.New(t)
+				assert.Equal(machineryv1tasks.StateFailure, progress.State)
+				assert.Equal("baz", progress.Error)
+				assert.Equal(int64(4), progress.BytesDone)
+				assert.Equal(int32(1), progress.PiecesDone)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			res := resource.NewMockResource(ctl)
+			hostManager := resource.NewMockHostManager(ctl)
+			res.EXPECT().HostManager().Return(hostManager).AnyTimes()
+			tc.mock(hostManager.EXPECT())
+
+			j := &job{resource: res}
+			reporter := j.newPreheatReporter(context.Background(), "http://example.com")
+			for _, piece := range tc.pieces {
+				reporter.update(piece)
+				if piece.Done {
+					reporter.succeed()
+				}
+			}
+
+			if tc.err != nil {
+				reporter.fail(tc.err)
+			}
+
+			tc.expect(t, reporter.progress)
+		})
+	}
+}
+
+func TestJob_deleteTask(t *testing.T) {
+	url := "http://example.com/foo"
+	taskID := idgen.TaskID(url, newURLMeta(nil, "", "", ""))
+	args, err := internaljob.MarshalRequest(internaljob.DeleteTaskRequest{URL: url})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := args[0].Value.(string)
+
+	tests := []struct {
+		name   string
+		hosts  int
+		err    error
+		expect func(t *testing.T, task *resource.Task, err error)
+	}{
+		{
+			name:  "task is not found",
+			hosts: 0,
+			expect: func(t *testing.T, task *resource.Task, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:  "delete task in hosts",
+			hosts: 2,
+			expect: func(t *testing.T, task *resource.Task, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(0, task.PeerCount())
+				assert.True(task.FSM.Is(resource.TaskStateLeave))
+			},
+		},
+		{
+			name:  "delete task in hosts failed",
+			hosts: 2,
+			err:   errors.New("foo"),
+			expect: func(t *testing.T, task *resource.Task, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "foo")
+				assert.Equal(0, task.PeerCount())
+				assert.True(task.FSM.Is(resource.TaskStateLeave))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			res := resource.NewMockResource(ctl)
+			taskManager := resource.NewMockTaskManager(ctl)
+			peerManager := resource.NewMockPeerManager(ctl)
+			client := dfdaemonmocks.NewMockClient(ctl)
+			res.EXPECT().TaskManager().Return(taskManager).AnyTimes()
+			res.EXPECT().PeerManager().Return(peerManager).AnyTimes()
+
+			task := resource.NewTask(taskID, url, commonv1.TaskType_Normal, &commonv1.UrlMeta{})
+			if tc.hosts == 0 {
+				taskManager.EXPECT().Load(gomock.Eq(taskID)).Return(nil, false).Times(1)
+			} else {
+				taskManager.EXPECT().Load(gomock.Eq(taskID)).Return(task, true).Times(1)
+				taskManager.EXPECT().Delete(gomock.Eq(taskID)).Times(1)
+				for i := 0; i < tc.hosts; i++ {
+					host := resource.NewHost(&schedulerv1.AnnounceHostRequest{Id: fmt.Sprintf("host-%d", i), Ip: "127.0.0.1", Port: int32(8000 + i)})
+					peer := resource.NewPeer(fmt.Sprintf("peer-%d", i), task, host)
+					task.StorePeer(peer)
+
+					// The running peer is notified that the task is deleted.
+					if i == 0 {
+						peer.FSM.SetState(resource.PeerStateRunning)
+						stream := schedulerv1mocks.NewMockScheduler_ReportPieceResultServer(ctl)
+						stream.EXPECT().Send(gomock.Eq(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedTaskStatusError})).Return(nil).Times(1)
+						peer.StoreStream(stream)
+					}
+					peerManager.EXPECT().Delete(gomock.Eq(peer.ID)).Do(func(key string) { task.DeletePeer(key) }).Times(1)
+				}
+				client.EXPECT().DeleteTask(gomock.Any(), gomock.Any()).Return(tc.err).Times(tc.hosts)
+				client.EXPECT().Close().Return(nil).Times(tc.hosts)
+			}
+
+			j := &job{
+				resource: res,
+				dfdaemonClient: func(ctx context.Context, target string, opts ...grpc.DialOption) (dfdaemonclient.Client, error) {
+					return client, nil
+				},
+			}
+			tc.expect(t, task, j.deleteTask(context.Background(), req))
+		})
+	}
+}
+
+func TestJob_getTask(t *testing.T) {
+	url := "http://example.com/foo"
+	taskID := idgen.TaskID(url, newURLMeta(nil, "", "", ""))
+	tests := []struct {
+		name    string
+		request internaljob.GetTaskRequest
+		mock    func(task *resource.Task, mt *resource.MockTaskManagerMockRecorder)
+		expect  func(t *testing.T, resp *internaljob.GetTaskResponse, err error)
+	}{
+		{
+			name:    "task is not found",
+			request: internaljob.GetTaskRequest{TaskID: taskID},
+			mock: func(task *resource.Task, mt *resource.MockTaskManagerMockRecorder) {
+				mt.Load(gomock.Eq(taskID)).Return(nil, false).Times(1)
+			},
+			expect: func(t *testing.T, resp *internaljob.GetTaskResponse, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(uint(1), resp.SchedulerClusterID)
+				assert.Equal("foo", resp.SchedulerHostname)
+				assert.Equal(taskID, resp.TaskID)
+				assert.Equal("", resp.State)
+				assert.Len(resp.Peers, 0)
+			},
+		},
+		{
+			name:    "get task by url",
+			request: internaljob.GetTaskRequest{URL: url},
+			mock: func(task *resource.Task, mt *resource.MockTaskManagerMockRecorder) {
+				host := resource.NewHost(&schedulerv1.AnnounceHostRequest{Id: "bar", Hostname: "baz", Ip: "127.0.0.1"})
+				peer := resource.NewPeer("qux", task, host)
+				peer.FinishedPieces.Set(0)
+				peer.FinishedPieces.Set(1)
+				task.StorePeer(peer)
+				task.ContentLength.Store(8)
+				task.TotalPieceCount.Store(2)
+				mt.Load(gomock.Eq(taskID)).Return(task, true).Times(1)
+			},
+			expect: func(t *testing.T, resp *internaljob.GetTaskResponse, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(taskID, resp.TaskID)
+				assert.Equal(resource.TaskStatePending, resp.State)
+				assert.Equal(int64(8), resp.ContentLength)
+				assert.Equal(int32(2), resp.TotalPieces)
+				assert.Len(resp.Peers, 1)
+				assert.Equal("qux", resp.Peers[0].ID)
+				assert.Equal(resource.PeerStatePending, resp.Peers[0].State)
+				assert.Equal(int32(2), resp.Peers[0].FinishedPieces)
+				assert.Equal("baz", resp.Peers[0].Hostname)
+				assert.Equal("127.0.0.1", resp.Peers[0].IP)
+				assert.Equal("normal", resp.Peers[0].HostType)
+			},
+		},
+		{
+			name:    "request without task id and url",
+			request: internaljob.GetTaskRequest{},
+			mock:    func(task *resource.Task, mt *resource.MockTaskManagerMockRecorder) {},
+			expect: func(t *testing.T, resp *internaljob.GetTaskResponse, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			res := resource.NewMockResource(ctl)
+			taskManager := resource.NewMockTaskManager(ctl)
+			res.EXPECT().TaskManager().Return(taskManager).AnyTimes()
+			tc.mock(resource.NewTask(taskID, url, commonv1.TaskType_Normal, &commonv1.UrlMeta{}), taskManager.EXPECT())
+
+			args, err := internaljob.MarshalRequest(tc.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			j := &job{
+				resource: res,
+				config: &config.Config{
+					Server:  config.ServerConfig{Host: "foo"},
+					Manager: config.ManagerConfig{SchedulerClusterID: 1},
+				},
+			}
+			data, err := j.getTask(context.Background(), args[0].Value.(string))
+			if err != nil {
+				tc.expect(t, nil, err)
+				return
+			}
+
+			resp := &internaljob.GetTaskResponse{}
+			if err := internaljob.UnmarshalRequest(data, resp); err != nil {
+				t.Fatal(err)
+			}
+			tc.expect(t, resp, nil)
+		})
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sync"
 	"time"
 
 	"github.com/bits-and-blooms/bitset"
@@ -29,10 +31,12 @@ import (
 	"github.com/looplab/fsm"
 	"go.uber.org/atomic"
 
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/container/set"
+	"d7y.io/dragonfly/v2/scheduler/config"
 )
 
 const (
@@ -42,6 +46,10 @@ const (
 	//DefaultApplication default value of application
 	DefaultApplication = "unknown"
 
+	// DefaultPriority is the priority of peer whose application has no priority config,
+	// the seed peer is first triggered to back-to-source, which is the default scheduling.
+	DefaultPriority = managerv1.Priority_Level3
+
 	// Download tiny file timeout.
 	downloadTinyFileContextTimeout = 30 * time.Second
 )
@@ -126,6 +134,14 @@ func WithApplication(application string) PeerOption {
 	}
 }
 
+// WithPriority sets peer's Priority.
+func WithPriority(priority managerv1.Priority) PeerOption {
+	return func(p *Peer) *Peer {
+		p.Priority = priority
+		return p
+	}
+}
+
 type Peer struct {
 	// ID is peer id.
 	ID string
@@ -136,6 +152,10 @@ type Peer struct {
 	// Application is peer application.
 	Application string
 
+	// Priority is peer priority, it is calculated by the application
+	// priority config when the peer is registered.
+	Priority managerv1.Priority
+
 	// Pieces is finished piece set.
 	Pieces set.SafeSet[*schedulerv1.PieceResult]
 
@@ -193,6 +213,7 @@ func NewPeer(id string, task *Task, host *Host, options ...PeerOption) *Peer {
 		ID:               id,
 		Tag:              DefaultTag,
 		Application:      DefaultApplication,
+		Priority:         DefaultPriority,
 		Pieces:           set.NewSafeSet[*schedulerv1.PieceResult](),
 		FinishedPieces:   &bitset.BitSet{},
 		pieceCosts:       []int64{},
@@ -415,3 +436,61 @@ func (p *Peer) DownloadTinyFile() ([]byte, error) {
 
 	return io.ReadAll(resp.Body)
 }
+
+// urlRegexes caches the compiled url regexes of application priority,
+// because the priority is calculated in the hot path of scheduling.
+var urlRegexes sync.Map
+
+// compileURLRegex returns the compiled url regex from cache.
+func compileURLRegex(expr string) (*regexp.Regexp, error) {
+	if regex, ok := urlRegexes.Load(expr); ok {
+		return regex.(*regexp.Regexp), nil
+	}
+
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	urlRegexes.Store(expr, regex)
+	return regex, nil
+}
+
+// CalculatePriority returns priority of peer by its application and task url.
+func (p *Peer) CalculatePriority(dynconfig config.DynconfigInterface) managerv1.Priority {
+	return CalculatePriority(dynconfig, p.Application, p.Task.URL)
+}
+
+// CalculatePriority returns priority by the application priority config,
+// the priority of the first url regex matching the url is used,
+// otherwise the priority of the application is used.
+// If the application has no priority config, DefaultPriority is returned.
+func CalculatePriority(dynconfig config.DynconfigInterface, application, rawURL string) managerv1.Priority {
+	applications, err := dynconfig.GetApplications()
+	if err != nil {
+		logger.Debugf("get applications failed: %s", err.Error())
+		return DefaultPriority
+	}
+
+	for _, pbApplication := range applications {
+		if pbApplication.Name != application || pbApplication.Priority == nil {
+			continue
+		}
+
+		for _, pbURL := range pbApplication.Priority.Urls {
+			regex, err := compileURLRegex(pbURL.Regex)
+			if err != nil {
+				logger.Warnf("url regex %s of application %s is invalid: %s", pbURL.Regex, application, err.Error())
+				continue
+			}
+
+			if regex.MatchString(rawURL) {
+				return pbURL.Value
+			}
+		}
+
+		return pbApplication.Priority.Value
+	}
+
+	return DefaultPriority
+}
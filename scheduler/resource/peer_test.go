@@ -17,6 +17,7 @@
 package resource
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -30,11 +31,13 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 	"d7y.io/api/pkg/apis/scheduler/v1/mocks"
 
 	"d7y.io/dragonfly/v2/client/util"
 	"d7y.io/dragonfly/v2/pkg/idgen"
+	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
 )
 
 var (
@@ -455,3 +458,143 @@ func TestPeer_DownloadTinyFile(t *testing.T) {
 		})
 	}
 }
+
+func TestPeer_CalculatePriority(t *testing.T) {
+	tests := []struct {
+		name        string
+		application string
+		mock        func(md *configmocks.MockDynconfigInterfaceMockRecorder)
+		expect      func(t *testing.T, priority managerv1.Priority)
+	}{
+		{
+			name:        "get applications failed",
+			application: "foo",
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				md.GetApplications().Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, priority managerv1.Priority) {
+				assert := assert.New(t)
+				assert.Equal(priority, DefaultPriority)
+			},
+		},
+		{
+			name:        "application can not be found",
+			application: "foo",
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				md.GetApplications().Return([]*managerv1.Application{
+					{
+						Name: "bar",
+						Priority: &managerv1.ApplicationPriority{
+							Value: managerv1.Priority_Level0,
+						},
+					},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, priority managerv1.Priority) {
+				assert := assert.New(t)
+				assert.Equal(priority, DefaultPriority)
+			},
+		},
+		{
+			name:        "application has no priority",
+			application: "foo",
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				md.GetApplications().Return([]*managerv1.Application{
+					{
+						Name: "foo",
+					},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, priority managerv1.Priority) {
+				assert := assert.New(t)
+				assert.Equal(priority, DefaultPriority)
+			},
+		},
+		{
+			name:        "url regex does not match",
+			application: "foo",
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				md.GetApplications().Return([]*managerv1.Application{
+					{
+						Name: "foo",
+						Priority: &managerv1.ApplicationPriority{
+							Value: managerv1.Priority_Level1,
+							Urls: []*managerv1.URLPriority{
+								{
+									Regex: "bar",
+									Value: managerv1.Priority_Level5,
+								},
+							},
+						},
+					},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, priority managerv1.Priority) {
+				assert := assert.New(t)
+				assert.Equal(priority, managerv1.Priority_Level1)
+			},
+		},
+		{
+			name:        "url regex is invalid",
+			application: "foo",
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				md.GetApplications().Return([]*managerv1.Application{
+					{
+						Name: "foo",
+						Priority: &managerv1.ApplicationPriority{
+							Value: managerv1.Priority_Level1,
+							Urls: []*managerv1.URLPriority{
+								{
+									Regex: "(",
+									Value: managerv1.Priority_Level5,
+								},
+							},
+						},
+					},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, priority managerv1.Priority) {
+				assert := assert.New(t)
+				assert.Equal(priority, managerv1.Priority_Level1)
+			},
+		},
+		{
+			name:        "url regex matches",
+			application: "foo",
+			mock: func(md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				md.GetApplications().Return([]*managerv1.Application{
+					{
+						Name: "foo",
+						Priority: &managerv1.ApplicationPriority{
+							Value: managerv1.Priority_Level1,
+							Urls: []*managerv1.URLPriority{
+								{
+									Regex: "example.com",
+									Value: managerv1.Priority_Level5,
+								},
+							},
+						},
+					},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, priority managerv1.Priority) {
+				assert := assert.New(t)
+				assert.Equal(priority, managerv1.Priority_Level5)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+
+			mockHost := NewHost(mockRawHost)
+			mockTask := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := NewPeer(mockPeerID, mockTask, mockHost, WithApplication(tc.application))
+			tc.mock(dynconfig.EXPECT())
+			tc.expect(t, peer.CalculatePriority(dynconfig))
+		})
+	}
+}
@@ -19,14 +19,26 @@
 package resource
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
+	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/scheduler/config"
 )
 
+const (
+	// snapshotTimeout is the timeout of saving or loading snapshot.
+	snapshotTimeout = 1 * time.Minute
+)
+
 type Resource interface {
 	// SeedPeer interface.
 	SeedPeer() SeedPeer
@@ -62,6 +74,15 @@ type resource struct {
 
 	// TransportCredentials stores the Authenticator required to setup a client connection.
 	transportCredentials credentials.TransportCredentials
+
+	// snapshotBackend persists the snapshot of resource.
+	snapshotBackend SnapshotBackend
+
+	// done channel will be closed when resource stops.
+	done chan struct{}
+
+	// wg waits for the goroutine of saving snapshot periodically.
+	wg sync.WaitGroup
 }
 
 // Option is a functional option for configuring the resource.
@@ -75,9 +96,17 @@ func WithTransportCredentials(creds credentials.TransportCredentials) Option {
 	}
 }
 
+// WithSnapshotBackend returns an Option which restores resource from the snapshot backend,
+// and persists the snapshot of resource periodically.
+func WithSnapshotBackend(backend SnapshotBackend) Option {
+	return func(r *resource) {
+		r.snapshotBackend = backend
+	}
+}
+
 // New returns Resource interface.
 func New(cfg *config.Config, gc gc.GC, dynconfig config.DynconfigInterface, options ...Option) (Resource, error) {
-	resource := &resource{config: cfg}
+	resource := &resource{config: cfg, done: make(chan struct{})}
 
 	for _, opt := range options {
 		opt(resource)
@@ -104,6 +133,16 @@ func New(cfg *config.Config, gc gc.GC, dynconfig config.DynconfigInterface, opti
 	}
 	resource.peerManager = peerManager
 
+	// Restore resource from snapshot and save snapshot periodically.
+	if resource.snapshotBackend != nil {
+		resource.restoreSnapshot()
+		resource.wg.Add(1)
+		go func() {
+			defer resource.wg.Done()
+			resource.serveSnapshot()
+		}()
+	}
+
 	// Initialize seed peer interface.
 	if cfg.SeedPeer.Enable {
 		dialOptions := []grpc.DialOption{}
@@ -141,9 +180,75 @@ func (r *resource) PeerManager() PeerManager {
 }
 
 func (r *resource) Stop() error {
+	close(r.done)
+
+	var errs *multierror.Error
+	if r.snapshotBackend != nil {
+		// Wait for the periodical saving, so the final snapshot is the latest
+		// and the backend is not closed under the saving.
+		r.wg.Wait()
+
+		if err := r.saveSnapshot(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+
+		if err := r.snapshotBackend.Close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
 	if r.config.SeedPeer.Enable {
-		return r.seedPeer.Stop()
+		if err := r.seedPeer.Stop(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
 	}
 
-	return nil
+	return errs.ErrorOrNil()
+}
+
+// restoreSnapshot restores hosts, tasks and peers from the snapshot backend,
+// resource starts with empty state if the snapshot can not be loaded.
+func (r *resource) restoreSnapshot() {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	snapshot, err := r.snapshotBackend.Load(ctx)
+	if err != nil {
+		if errors.Is(err, ErrSnapshotNotFound) {
+			logger.Info("resource snapshot not found")
+			return
+		}
+
+		logger.Errorf("load resource snapshot failed: %s", err.Error())
+		return
+	}
+
+	RestoreSnapshot(snapshot, r.hostManager, r.taskManager, r.peerManager)
+	logger.Infof("restore resource snapshot created at %s, %d hosts, %d tasks and %d peers",
+		snapshot.CreatedAt, len(snapshot.Hosts), len(snapshot.Tasks), len(snapshot.Peers))
+}
+
+// serveSnapshot saves the snapshot of resource periodically.
+func (r *resource) serveSnapshot() {
+	tick := time.NewTicker(r.config.Snapshot.Interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			if err := r.saveSnapshot(); err != nil {
+				logger.Errorf("save resource snapshot failed: %s", err.Error())
+			}
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// saveSnapshot saves the snapshot of resource to the snapshot backend.
+func (r *resource) saveSnapshot() error {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	return r.snapshotBackend.Save(ctx, TakeSnapshot(r.hostManager, r.taskManager, r.peerManager))
 }
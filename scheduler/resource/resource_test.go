@@ -17,12 +17,15 @@
 package resource
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
 	"google.golang.org/grpc/resolver"
 
 	managerv1 "d7y.io/api/pkg/apis/manager/v1"
@@ -182,3 +185,106 @@ func TestResource_New(t *testing.T) {
 		})
 	}
 }
+
+func TestResource_Snapshot(t *testing.T) {
+	tests := []struct {
+		name string
+		mock func(gc *gc.MockGCMockRecorder, backend *MockSnapshotBackendMockRecorder)
+	}{
+		{
+			name: "restore and save snapshot",
+			mock: func(gc *gc.MockGCMockRecorder, backend *MockSnapshotBackendMockRecorder) {
+				gomock.InOrder(
+					gc.Add(gomock.Any()).Return(nil).Times(3),
+					backend.Load(gomock.Any()).Return(&Snapshot{Version: SnapshotVersion}, nil).Times(1),
+					backend.Save(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+					backend.Close().Return(nil).Times(1),
+				)
+			},
+		},
+		{
+			name: "snapshot not found",
+			mock: func(gc *gc.MockGCMockRecorder, backend *MockSnapshotBackendMockRecorder) {
+				gomock.InOrder(
+					gc.Add(gomock.Any()).Return(nil).Times(3),
+					backend.Load(gomock.Any()).Return(nil, ErrSnapshotNotFound).Times(1),
+					backend.Save(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+					backend.Close().Return(nil).Times(1),
+				)
+			},
+		},
+		{
+			name: "load snapshot failed",
+			mock: func(gc *gc.MockGCMockRecorder, backend *MockSnapshotBackendMockRecorder) {
+				gomock.InOrder(
+					gc.Add(gomock.Any()).Return(nil).Times(3),
+					backend.Load(gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+					backend.Save(gomock.Any(), gomock.Any()).Return(nil).Times(1),
+					backend.Close().Return(nil).Times(1),
+				)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			gc := gc.NewMockGC(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			backend := NewMockSnapshotBackend(ctl)
+			tc.mock(gc.EXPECT(), backend.EXPECT())
+
+			cfg := config.New()
+			cfg.SeedPeer.Enable = false
+			resource, err := New(cfg, gc, dynconfig, WithSnapshotBackend(backend))
+			assert.NoError(t, err)
+			assert.NoError(t, resource.Stop())
+		})
+	}
+}
+
+func TestResource_StopWithPendingSnapshot(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	gc := gc.NewMockGC(ctl)
+	dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+	backend := NewMockSnapshotBackend(ctl)
+
+	var (
+		saving     = atomic.NewInt32(0)
+		saved      = make(chan struct{}, 1)
+		concurrent = atomic.NewBool(false)
+	)
+	gc.EXPECT().Add(gomock.Any()).Return(nil).Times(3)
+	backend.EXPECT().Load(gomock.Any()).Return(nil, ErrSnapshotNotFound).Times(1)
+	backend.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, snapshot *Snapshot) error {
+		if saving.Inc() > 1 {
+			concurrent.Store(true)
+		}
+		defer saving.Dec()
+
+		select {
+		case saved <- struct{}{}:
+		default:
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}).MinTimes(2)
+	backend.EXPECT().Close().DoAndReturn(func() error {
+		assert.Equal(t, int32(0), saving.Load())
+		return nil
+	}).Times(1)
+
+	cfg := config.New()
+	cfg.SeedPeer.Enable = false
+	cfg.Snapshot.Interval = 10 * time.Millisecond
+	resource, err := New(cfg, gc, dynconfig, WithSnapshotBackend(backend))
+	assert.NoError(t, err)
+
+	// Stop resource while the periodical saving is running.
+	<-saved
+	assert.NoError(t, resource.Stop())
+	assert.False(t, concurrent.Load())
+}
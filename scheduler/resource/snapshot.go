@@ -0,0 +1,323 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"time"
+
+	"github.com/bits-and-blooms/bitset"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	// SnapshotVersion is the version of snapshot format.
+	SnapshotVersion = 1
+)
+
+// Snapshot is the persistent state of resource.
+type Snapshot struct {
+	// Version is the version of snapshot format.
+	Version int `json:"version"`
+
+	// Hosts is the snapshot of hosts.
+	Hosts []*HostSnapshot `json:"hosts"`
+
+	// Tasks is the snapshot of tasks.
+	Tasks []*TaskSnapshot `json:"tasks"`
+
+	// Peers is the snapshot of peers.
+	Peers []*PeerSnapshot `json:"peers"`
+
+	// CreatedAt is snapshot create time.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// HostSnapshot is the persistent state of host.
+type HostSnapshot struct {
+	ID                    string               `json:"id"`
+	Type                  string               `json:"type"`
+	Hostname              string               `json:"hostname"`
+	IP                    string               `json:"ip"`
+	Port                  int32                `json:"port"`
+	DownloadPort          int32                `json:"downloadPort"`
+	OS                    string               `json:"os"`
+	Platform              string               `json:"platform"`
+	PlatformFamily        string               `json:"platformFamily"`
+	PlatformVersion       string               `json:"platformVersion"`
+	KernelVersion         string               `json:"kernelVersion"`
+	CPU                   *schedulerv1.CPU     `json:"cpu"`
+	Memory                *schedulerv1.Memory  `json:"memory"`
+	Network               *schedulerv1.Network `json:"network"`
+	Disk                  *schedulerv1.Disk    `json:"disk"`
+	Build                 *schedulerv1.Build   `json:"build"`
+	ConcurrentUploadLimit int32                `json:"concurrentUploadLimit"`
+	UploadCount           int64                `json:"uploadCount"`
+	UploadFailedCount     int64                `json:"uploadFailedCount"`
+	CreatedAt             time.Time            `json:"createdAt"`
+	UpdatedAt             time.Time            `json:"updatedAt"`
+}
+
+// TaskSnapshot is the persistent state of task.
+type TaskSnapshot struct {
+	ID                string                `json:"id"`
+	URL               string                `json:"url"`
+	Type              commonv1.TaskType     `json:"type"`
+	URLMeta           *commonv1.UrlMeta     `json:"urlMeta"`
+	DirectPiece       []byte                `json:"directPiece"`
+	ContentLength     int64                 `json:"contentLength"`
+	TotalPieceCount   int32                 `json:"totalPieceCount"`
+	BackToSourceLimit int32                 `json:"backToSourceLimit"`
+	State             string                `json:"state"`
+	Pieces            []*commonv1.PieceInfo `json:"pieces"`
+	CreatedAt         time.Time             `json:"createdAt"`
+	UpdatedAt         time.Time             `json:"updatedAt"`
+}
+
+// PeerSnapshot is the persistent state of peer.
+type PeerSnapshot struct {
+	ID             string         `json:"id"`
+	Tag            string         `json:"tag"`
+	Application    string         `json:"application"`
+	TaskID         string         `json:"taskID"`
+	HostID         string         `json:"hostID"`
+	State          string         `json:"state"`
+	FinishedPieces *bitset.BitSet `json:"finishedPieces"`
+	PieceCosts     []int64        `json:"pieceCosts"`
+	IsBackToSource bool           `json:"isBackToSource"`
+	PieceUpdatedAt time.Time      `json:"pieceUpdatedAt"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+}
+
+// TakeSnapshot returns the snapshot of hosts, tasks and peers.
+// Only the succeeded peers are persisted, because the peers in other states
+// lose their grpc streams after scheduler restarts and will register again.
+func TakeSnapshot(hostManager HostManager, taskManager TaskManager, peerManager PeerManager) *Snapshot {
+	snapshot := &Snapshot{
+		Version:   SnapshotVersion,
+		CreatedAt: time.Now(),
+	}
+
+	hostManager.Range(func(_, value any) bool {
+		host, ok := value.(*Host)
+		if !ok {
+			logger.Error("invalid host")
+			return true
+		}
+
+		snapshot.Hosts = append(snapshot.Hosts, newHostSnapshot(host))
+		return true
+	})
+
+	taskManager.Range(func(_, value any) bool {
+		task, ok := value.(*Task)
+		if !ok {
+			logger.Error("invalid task")
+			return true
+		}
+
+		if task.FSM.Is(TaskStateLeave) {
+			return true
+		}
+
+		snapshot.Tasks = append(snapshot.Tasks, newTaskSnapshot(task))
+		return true
+	})
+
+	peerManager.Range(func(_, value any) bool {
+		peer, ok := value.(*Peer)
+		if !ok {
+			logger.Error("invalid peer")
+			return true
+		}
+
+		if !peer.FSM.Is(PeerStateSucceeded) || peer.Task.FSM.Is(TaskStateLeave) {
+			return true
+		}
+
+		snapshot.Peers = append(snapshot.Peers, newPeerSnapshot(peer))
+		return true
+	})
+
+	return snapshot
+}
+
+// RestoreSnapshot rebuilds hosts, tasks and peers from the snapshot.
+// Resources that already exist in the managers are not overwritten.
+func RestoreSnapshot(snapshot *Snapshot, hostManager HostManager, taskManager TaskManager, peerManager PeerManager) {
+	for _, hs := range snapshot.Hosts {
+		hostManager.LoadOrStore(hs.restore())
+	}
+
+	for _, ts := range snapshot.Tasks {
+		taskManager.LoadOrStore(ts.restore())
+	}
+
+	for _, ps := range snapshot.Peers {
+		task, ok := taskManager.Load(ps.TaskID)
+		if !ok {
+			logger.Warnf("task %s of peer %s not found in snapshot", ps.TaskID, ps.ID)
+			continue
+		}
+
+		host, ok := hostManager.Load(ps.HostID)
+		if !ok {
+			logger.Warnf("host %s of peer %s not found in snapshot", ps.HostID, ps.ID)
+			continue
+		}
+
+		peerManager.LoadOrStore(ps.restore(task, host))
+	}
+}
+
+func newHostSnapshot(host *Host) *HostSnapshot {
+	return &HostSnapshot{
+		ID:                    host.ID,
+		Type:                  host.Type.Name(),
+		Hostname:              host.Hostname,
+		IP:                    host.IP,
+		Port:                  host.Port,
+		DownloadPort:          host.DownloadPort,
+		OS:                    host.OS,
+		Platform:              host.Platform,
+		PlatformFamily:        host.PlatformFamily,
+		PlatformVersion:       host.PlatformVersion,
+		KernelVersion:         host.KernelVersion,
+		CPU:                   host.CPU,
+		Memory:                host.Memory,
+		Network:               host.Network,
+		Disk:                  host.Disk,
+		Build:                 host.Build,
+		ConcurrentUploadLimit: host.ConcurrentUploadLimit.Load(),
+		UploadCount:           host.UploadCount.Load(),
+		UploadFailedCount:     host.UploadFailedCount.Load(),
+		CreatedAt:             host.CreatedAt.Load(),
+		UpdatedAt:             host.UpdatedAt.Load(),
+	}
+}
+
+func (hs *HostSnapshot) restore() *Host {
+	host := NewHost(&schedulerv1.AnnounceHostRequest{
+		Id:              hs.ID,
+		Type:            hs.Type,
+		Hostname:        hs.Hostname,
+		Ip:              hs.IP,
+		Port:            hs.Port,
+		DownloadPort:    hs.DownloadPort,
+		Os:              hs.OS,
+		Platform:        hs.Platform,
+		PlatformFamily:  hs.PlatformFamily,
+		PlatformVersion: hs.PlatformVersion,
+		KernelVersion:   hs.KernelVersion,
+		Cpu:             hs.CPU,
+		Memory:          hs.Memory,
+		Network:         hs.Network,
+		Disk:            hs.Disk,
+		Build:           hs.Build,
+	}, WithConcurrentUploadLimit(hs.ConcurrentUploadLimit))
+
+	host.UploadCount.Store(hs.UploadCount)
+	host.UploadFailedCount.Store(hs.UploadFailedCount)
+	host.CreatedAt.Store(hs.CreatedAt)
+	host.UpdatedAt.Store(hs.UpdatedAt)
+	return host
+}
+
+func newTaskSnapshot(task *Task) *TaskSnapshot {
+	ts := &TaskSnapshot{
+		ID:                task.ID,
+		URL:               task.URL,
+		Type:              task.Type,
+		URLMeta:           task.URLMeta,
+		DirectPiece:       task.DirectPiece,
+		ContentLength:     task.ContentLength.Load(),
+		TotalPieceCount:   task.TotalPieceCount.Load(),
+		BackToSourceLimit: task.BackToSourceLimit.Load(),
+		State:             task.FSM.Current(),
+		CreatedAt:         task.CreatedAt.Load(),
+		UpdatedAt:         task.UpdatedAt.Load(),
+	}
+
+	task.Pieces.Range(func(_, value any) bool {
+		if piece, ok := value.(*commonv1.PieceInfo); ok {
+			ts.Pieces = append(ts.Pieces, piece)
+		}
+
+		return true
+	})
+
+	return ts
+}
+
+func (ts *TaskSnapshot) restore() *Task {
+	task := NewTask(ts.ID, ts.URL, ts.Type, ts.URLMeta, WithBackToSourceLimit(ts.BackToSourceLimit))
+	task.DirectPiece = ts.DirectPiece
+	task.ContentLength.Store(ts.ContentLength)
+	task.TotalPieceCount.Store(ts.TotalPieceCount)
+	for _, piece := range ts.Pieces {
+		task.StorePiece(piece)
+	}
+
+	// The running peers are not persisted, so the unfinished task is restored as pending,
+	// then the next registered peer will trigger the download of task again.
+	if ts.State == TaskStateSucceeded {
+		task.FSM.SetState(TaskStateSucceeded)
+	}
+
+	task.CreatedAt.Store(ts.CreatedAt)
+	task.UpdatedAt.Store(ts.UpdatedAt)
+	return task
+}
+
+func newPeerSnapshot(peer *Peer) *PeerSnapshot {
+	return &PeerSnapshot{
+		ID:             peer.ID,
+		Tag:            peer.Tag,
+		Application:    peer.Application,
+		TaskID:         peer.Task.ID,
+		HostID:         peer.Host.ID,
+		State:          peer.FSM.Current(),
+		FinishedPieces: peer.FinishedPieces.Clone(),
+		PieceCosts:     append([]int64{}, peer.PieceCosts()...),
+		IsBackToSource: peer.IsBackToSource.Load(),
+		PieceUpdatedAt: peer.PieceUpdatedAt.Load(),
+		CreatedAt:      peer.CreatedAt.Load(),
+		UpdatedAt:      peer.UpdatedAt.Load(),
+	}
+}
+
+func (ps *PeerSnapshot) restore(task *Task, host *Host) *Peer {
+	peer := NewPeer(ps.ID, task, host, WithTag(ps.Tag), WithApplication(ps.Application))
+	if ps.FinishedPieces != nil {
+		peer.FinishedPieces = ps.FinishedPieces
+	}
+
+	for _, cost := range ps.PieceCosts {
+		peer.AppendPieceCost(cost)
+	}
+
+	peer.IsBackToSource.Store(ps.IsBackToSource)
+	peer.FSM.SetState(ps.State)
+	peer.PieceUpdatedAt.Store(ps.PieceUpdatedAt)
+	peer.CreatedAt.Store(ps.CreatedAt)
+	peer.UpdatedAt.Store(ps.UpdatedAt)
+	return peer
+}
@@ -0,0 +1,202 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination snapshot_backend_mock.go -source snapshot_backend.go -package resource
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-redis/redis/v8"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+const (
+	// DefaultSnapshotFilename is the default snapshot filename of file backend.
+	DefaultSnapshotFilename = "resource.snapshot"
+
+	// SnapshotNamespace is the namespace of snapshot key in redis.
+	SnapshotNamespace = "scheduler:snapshot"
+)
+
+// ErrSnapshotNotFound is returned when there is no snapshot in backend.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// SnapshotBackend is the persistent backend of resource snapshot.
+type SnapshotBackend interface {
+	// Save persists the snapshot.
+	Save(context.Context, *Snapshot) error
+
+	// Load returns the latest snapshot,
+	// if there is no snapshot, it returns ErrSnapshotNotFound.
+	Load(context.Context) (*Snapshot, error)
+
+	// Close releases the resources of backend.
+	Close() error
+}
+
+// NewSnapshotBackend returns snapshot backend by the snapshot config.
+func NewSnapshotBackend(cfg *config.Config, dataDir string) (SnapshotBackend, error) {
+	switch cfg.Snapshot.Backend {
+	case config.SnapshotBackendFile:
+		filename := cfg.Snapshot.Filename
+		if filename == "" {
+			filename = filepath.Join(dataDir, DefaultSnapshotFilename)
+		}
+
+		return newFileSnapshotBackend(filename), nil
+	case config.SnapshotBackendRedis:
+		return newRedisSnapshotBackend(&cfg.Snapshot.Redis,
+			MakeSnapshotKey(cfg.Manager.SchedulerClusterID, cfg.Server.Host, cfg.Server.AdvertiseIP))
+	default:
+		return nil, fmt.Errorf("invalid snapshot backend %s", cfg.Snapshot.Backend)
+	}
+}
+
+// MakeSnapshotKey returns the snapshot key of scheduler in redis.
+func MakeSnapshotKey(clusterID uint, hostname, ip string) string {
+	return fmt.Sprintf("%s:%d-%s-%s", SnapshotNamespace, clusterID, hostname, ip)
+}
+
+type fileSnapshotBackend struct {
+	// filename is the path of snapshot file.
+	filename string
+}
+
+// newFileSnapshotBackend returns the snapshot backend of local file.
+func newFileSnapshotBackend(filename string) SnapshotBackend {
+	return &fileSnapshotBackend{filename: filename}
+}
+
+// Save writes the snapshot to a temporary file in the same directory and renames it to
+// the snapshot file, so the snapshot file is never left half written. Every saving has
+// its own temporary file, so the concurrent savings do not write the same file.
+func (f *fileSnapshotBackend) Save(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(f.filename)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(f.filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.filename)
+}
+
+func (f *fileSnapshotBackend) Load(ctx context.Context) (*Snapshot, error) {
+	data, err := os.ReadFile(f.filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrSnapshotNotFound
+		}
+
+		return nil, err
+	}
+
+	return unmarshalSnapshot(data)
+}
+
+func (f *fileSnapshotBackend) Close() error {
+	return nil
+}
+
+type redisSnapshotBackend struct {
+	// client is the redis client.
+	client redis.UniversalClient
+
+	// key is the snapshot key in redis.
+	key string
+}
+
+// newRedisSnapshotBackend returns the snapshot backend of redis.
+func newRedisSnapshotBackend(cfg *config.SnapshotRedisConfig, key string) (SnapshotBackend, error) {
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:    cfg.Addrs,
+		DB:       cfg.DB,
+		Username: cfg.Username,
+		Password: cfg.Password,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, err
+	}
+
+	return &redisSnapshotBackend{client: client, key: key}, nil
+}
+
+func (r *redisSnapshotBackend) Save(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(ctx, r.key, data, 0).Err()
+}
+
+func (r *redisSnapshotBackend) Load(ctx context.Context) (*Snapshot, error) {
+	data, err := r.client.Get(ctx, r.key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrSnapshotNotFound
+		}
+
+		return nil, err
+	}
+
+	return unmarshalSnapshot(data)
+}
+
+func (r *redisSnapshotBackend) Close() error {
+	return r.client.Close()
+}
+
+// unmarshalSnapshot decodes snapshot and checks its version.
+func unmarshalSnapshot(data []byte) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("invalid snapshot version %d", snapshot.Version)
+	}
+
+	return snapshot, nil
+}
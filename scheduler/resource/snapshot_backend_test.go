@@ -0,0 +1,184 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+func TestSnapshotBackend_NewSnapshotBackend(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.Config
+		expect func(t *testing.T, backend SnapshotBackend, err error)
+	}{
+		{
+			name: "new file snapshot backend",
+			config: &config.Config{
+				Snapshot: config.SnapshotConfig{
+					Backend: config.SnapshotBackendFile,
+				},
+			},
+			expect: func(t *testing.T, backend SnapshotBackend, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(reflect.TypeOf(backend).Elem().Name(), "fileSnapshotBackend")
+				assert.Equal(filepath.Join("foo", DefaultSnapshotFilename), backend.(*fileSnapshotBackend).filename)
+			},
+		},
+		{
+			name: "new file snapshot backend with filename",
+			config: &config.Config{
+				Snapshot: config.SnapshotConfig{
+					Backend:  config.SnapshotBackendFile,
+					Filename: "bar",
+				},
+			},
+			expect: func(t *testing.T, backend SnapshotBackend, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("bar", backend.(*fileSnapshotBackend).filename)
+			},
+		},
+		{
+			name: "new snapshot backend failed because of invalid backend",
+			config: &config.Config{
+				Snapshot: config.SnapshotConfig{
+					Backend: "baz",
+				},
+			},
+			expect: func(t *testing.T, backend SnapshotBackend, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid snapshot backend baz")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend, err := NewSnapshotBackend(tc.config, "foo")
+			tc.expect(t, backend, err)
+		})
+	}
+}
+
+func TestSnapshotBackend_MakeSnapshotKey(t *testing.T) {
+	assert.Equal(t, "scheduler:snapshot:1-foo-127.0.0.1", MakeSnapshotKey(1, "foo", "127.0.0.1"))
+}
+
+func TestFileSnapshotBackend(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, backend SnapshotBackend, filename string)
+	}{
+		{
+			name: "save and load snapshot",
+			run: func(t *testing.T, backend SnapshotBackend, filename string) {
+				assert := assert.New(t)
+				assert.NoError(backend.Save(context.Background(), &Snapshot{
+					Version: SnapshotVersion,
+					Hosts:   []*HostSnapshot{{ID: "foo"}},
+					Tasks:   []*TaskSnapshot{{ID: "bar"}},
+					Peers:   []*PeerSnapshot{{ID: "baz"}},
+				}))
+
+				snapshot, err := backend.Load(context.Background())
+				assert.NoError(err)
+				assert.Equal("foo", snapshot.Hosts[0].ID)
+				assert.Equal("bar", snapshot.Tasks[0].ID)
+				assert.Equal("baz", snapshot.Peers[0].ID)
+
+				tmps, err := filepath.Glob(filename + ".*.tmp")
+				assert.NoError(err)
+				assert.Empty(tmps)
+			},
+		},
+		{
+			name: "save snapshot concurrently",
+			run: func(t *testing.T, backend SnapshotBackend, filename string) {
+				assert := assert.New(t)
+				var wg sync.WaitGroup
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						assert.NoError(backend.Save(context.Background(), &Snapshot{
+							Version: SnapshotVersion,
+							Hosts:   []*HostSnapshot{{ID: "foo"}},
+						}))
+					}()
+				}
+				wg.Wait()
+
+				snapshot, err := backend.Load(context.Background())
+				assert.NoError(err)
+				assert.Equal("foo", snapshot.Hosts[0].ID)
+
+				tmps, err := filepath.Glob(filename + ".*.tmp")
+				assert.NoError(err)
+				assert.Empty(tmps)
+			},
+		},
+		{
+			name: "load snapshot failed because of snapshot not found",
+			run: func(t *testing.T, backend SnapshotBackend, filename string) {
+				assert := assert.New(t)
+				_, err := backend.Load(context.Background())
+				assert.ErrorIs(err, ErrSnapshotNotFound)
+			},
+		},
+		{
+			name: "load snapshot failed because of invalid version",
+			run: func(t *testing.T, backend SnapshotBackend, filename string) {
+				assert := assert.New(t)
+				assert.NoError(backend.Save(context.Background(), &Snapshot{Version: SnapshotVersion + 1}))
+
+				_, err := backend.Load(context.Background())
+				assert.EqualError(err, "invalid snapshot version 2")
+			},
+		},
+		{
+			name: "load snapshot failed because of invalid content",
+			run: func(t *testing.T, backend SnapshotBackend, filename string) {
+				assert := assert.New(t)
+				assert.NoError(os.MkdirAll(filepath.Dir(filename), 0700))
+				assert.NoError(os.WriteFile(filename, []byte("foo"), 0600))
+
+				_, err := backend.Load(context.Background())
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "data", DefaultSnapshotFilename)
+			backend := newFileSnapshotBackend(filename)
+			tc.run(t, backend, filename)
+			assert.NoError(t, backend.Close())
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/pkg/gc"
+)
+
+func newSnapshotTestManagers(t *testing.T) (HostManager, TaskManager, PeerManager) {
+	ctl := gomock.NewController(t)
+	gc := gc.NewMockGC(ctl)
+	gc.EXPECT().Add(gomock.Any()).Return(nil).AnyTimes()
+
+	hostManager, err := newHostManager(mockHostGCConfig, gc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskManager, err := newTaskManager(mockTaskGCConfig, gc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peerManager, err := newPeerManager(mockPeerGCConfig, gc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hostManager, taskManager, peerManager
+}
+
+func TestSnapshot_TakeAndRestore(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(hostManager HostManager, taskManager TaskManager, peerManager PeerManager)
+		expect func(t *testing.T, snapshot *Snapshot, hostManager HostManager, taskManager TaskManager, peerManager PeerManager)
+	}{
+		{
+			name: "restore succeeded task and peer",
+			mock: func(hostManager HostManager, taskManager TaskManager, peerManager PeerManager) {
+				host := NewHost(mockRawHost, WithConcurrentUploadLimit(10))
+				host.UploadCount.Store(2)
+				hostManager.Store(host)
+
+				task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(mockTaskBackToSourceLimit))
+				task.ContentLength.Store(100)
+				task.TotalPieceCount.Store(1)
+				task.StorePiece(mockPieceInfo)
+				task.FSM.SetState(TaskStateSucceeded)
+				taskManager.Store(task)
+
+				peer := NewPeer(mockPeerID, task, host, WithTag("foo"), WithApplication("bar"))
+				peer.FinishedPieces.Set(1)
+				peer.AppendPieceCost(10)
+				peer.FSM.SetState(PeerStateSucceeded)
+				peerManager.Store(peer)
+			},
+			expect: func(t *testing.T, snapshot *Snapshot, hostManager HostManager, taskManager TaskManager, peerManager PeerManager) {
+				assert := assert.New(t)
+				assert.Equal(SnapshotVersion, snapshot.Version)
+				assert.Len(snapshot.Hosts, 1)
+				assert.Len(snapshot.Tasks, 1)
+				assert.Len(snapshot.Peers, 1)
+
+				host, ok := hostManager.Load(mockRawHost.Id)
+				assert.True(ok)
+				assert.Equal(mockRawHost.Hostname, host.Hostname)
+				assert.Equal(int32(10), host.ConcurrentUploadLimit.Load())
+				assert.Equal(int64(2), host.UploadCount.Load())
+				assert.Equal(int32(1), host.PeerCount.Load())
+
+				task, ok := taskManager.Load(mockTaskID)
+				assert.True(ok)
+				assert.True(task.FSM.Is(TaskStateSucceeded))
+				assert.Equal(int64(100), task.ContentLength.Load())
+				assert.Equal(int32(1), task.TotalPieceCount.Load())
+				assert.Equal(mockTaskBackToSourceLimit, task.BackToSourceLimit.Load())
+				piece, ok := task.LoadPiece(mockPieceInfo.PieceNum)
+				assert.True(ok)
+				assert.Equal(mockPieceInfo.PieceMd5, piece.PieceMd5)
+				assert.Equal(1, task.PeerCount())
+				assert.True(task.HasAvailablePeer())
+
+				peer, ok := peerManager.Load(mockPeerID)
+				assert.True(ok)
+				assert.True(peer.FSM.Is(PeerStateSucceeded))
+				assert.Equal("foo", peer.Tag)
+				assert.Equal("bar", peer.Application)
+				assert.True(peer.FinishedPieces.Test(1))
+				assert.Equal([]int64{10}, peer.PieceCosts())
+			},
+		},
+		{
+			name: "running peer is not persisted and running task is restored as pending",
+			mock: func(hostManager HostManager, taskManager TaskManager, peerManager PeerManager) {
+				host := NewHost(mockRawHost)
+				hostManager.Store(host)
+
+				task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+				task.FSM.SetState(TaskStateRunning)
+				taskManager.Store(task)
+
+				peer := NewPeer(mockPeerID, task, host)
+				peer.FSM.SetState(PeerStateRunning)
+				peerManager.Store(peer)
+			},
+			expect: func(t *testing.T, snapshot *Snapshot, hostManager HostManager, taskManager TaskManager, peerManager PeerManager) {
+				assert := assert.New(t)
+				assert.Len(snapshot.Peers, 0)
+
+				task, ok := taskManager.Load(mockTaskID)
+				assert.True(ok)
+				assert.True(task.FSM.Is(TaskStatePending))
+				assert.Equal(0, task.PeerCount())
+
+				_, ok = peerManager.Load(mockPeerID)
+				assert.False(ok)
+			},
+		},
+		{
+			name: "task in leave state is not persisted",
+			mock: func(hostManager HostManager, taskManager TaskManager, peerManager PeerManager) {
+				host := NewHost(mockRawHost)
+				hostManager.Store(host)
+
+				task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+				task.FSM.SetState(TaskStateLeave)
+				taskManager.Store(task)
+
+				peer := NewPeer(mockPeerID, task, host)
+				peer.FSM.SetState(PeerStateSucceeded)
+				peerManager.Store(peer)
+			},
+			expect: func(t *testing.T, snapshot *Snapshot, hostManager HostManager, taskManager TaskManager, peerManager PeerManager) {
+				assert := assert.New(t)
+				assert.Len(snapshot.Hosts, 1)
+				assert.Len(snapshot.Tasks, 0)
+				assert.Len(snapshot.Peers, 0)
+
+				_, ok := taskManager.Load(mockTaskID)
+				assert.False(ok)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			hostManager, taskManager, peerManager := newSnapshotTestManagers(t)
+			tc.mock(hostManager, taskManager, peerManager)
+			snapshot := TakeSnapshot(hostManager, taskManager, peerManager)
+
+			// Restore snapshot into the empty managers like a restarted scheduler.
+			hostManager, taskManager, peerManager = newSnapshotTestManagers(t)
+			RestoreSnapshot(snapshot, hostManager, taskManager, peerManager)
+			tc.expect(t, snapshot, hostManager, taskManager, peerManager)
+		})
+	}
+}
+
+func TestSnapshot_RestoreSnapshot(t *testing.T) {
+	hostManager, taskManager, peerManager := newSnapshotTestManagers(t)
+	RestoreSnapshot(&Snapshot{
+		Version: SnapshotVersion,
+		Peers: []*PeerSnapshot{
+			{
+				ID:     mockPeerID,
+				TaskID: mockTaskID,
+				HostID: mockRawHost.Id,
+				State:  PeerStateSucceeded,
+			},
+		},
+	}, hostManager, taskManager, peerManager)
+
+	_, ok := peerManager.Load(mockPeerID)
+	assert.False(t, ok)
+}
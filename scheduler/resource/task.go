@@ -26,6 +26,7 @@ import (
 	"go.uber.org/atomic"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
@@ -132,6 +133,9 @@ type Task struct {
 	// if one peer succeeds, the value is reset to zero.
 	PeerFailedCount *atomic.Int32
 
+	// waitingPeerCounts is the count of peers waiting for parents by priority.
+	waitingPeerCounts []*atomic.Int32
+
 	// CreatedAt is task create time.
 	CreatedAt *atomic.Time
 
@@ -157,6 +161,7 @@ func NewTask(id, url string, taskType commonv1.TaskType, meta *commonv1.UrlMeta,
 		Pieces:            &sync.Map{},
 		DAG:               dag.NewDAG[*Peer](),
 		PeerFailedCount:   atomic.NewInt32(0),
+		waitingPeerCounts: newWaitingPeerCounts(),
 		CreatedAt:         atomic.NewTime(time.Now()),
 		UpdatedAt:         atomic.NewTime(time.Now()),
 		Log:               logger.WithTask(id, url),
@@ -253,6 +258,16 @@ func (t *Task) AddPeerEdge(fromPeer *Peer, toPeer *Peer) error {
 	return nil
 }
 
+// DeletePeerEdge deletes inedge between two peers.
+func (t *Task) DeletePeerEdge(fromPeer *Peer, toPeer *Peer) error {
+	if err := t.DAG.DeleteEdge(fromPeer.ID, toPeer.ID); err != nil {
+		return err
+	}
+
+	fromPeer.Host.ConcurrentUploadCount.Dec()
+	return nil
+}
+
 // DeletePeerInEdges deletes inedges of peer.
 func (t *Task) DeletePeerInEdges(key string) error {
 	vertex, err := t.DAG.GetVertex(key)
@@ -424,6 +439,44 @@ func (t *Task) SizeScope() (commonv1.SizeScope, error) {
 	return commonv1.SizeScope_NORMAL, nil
 }
 
+// newWaitingPeerCounts returns the counts of waiting peers for every priority.
+func newWaitingPeerCounts() []*atomic.Int32 {
+	counts := make([]*atomic.Int32, len(managerv1.Priority_name))
+	for i := range counts {
+		counts[i] = atomic.NewInt32(0)
+	}
+
+	return counts
+}
+
+// AddWaitingPeer counts the peer waiting for parents by its priority.
+func (t *Task) AddWaitingPeer(priority managerv1.Priority) {
+	if int(priority) < 0 || int(priority) >= len(t.waitingPeerCounts) {
+		return
+	}
+
+	t.waitingPeerCounts[priority].Inc()
+}
+
+// DeleteWaitingPeer uncounts the peer waiting for parents by its priority.
+func (t *Task) DeleteWaitingPeer(priority managerv1.Priority) {
+	if int(priority) < 0 || int(priority) >= len(t.waitingPeerCounts) {
+		return
+	}
+
+	t.waitingPeerCounts[priority].Dec()
+}
+
+// HigherPriorityWaitingPeerCount returns the count of waiting peers whose priority is higher than priority.
+func (t *Task) HigherPriorityWaitingPeerCount(priority managerv1.Priority) int {
+	var count int
+	for i := int(priority) + 1; i < len(t.waitingPeerCounts); i++ {
+		count += int(t.waitingPeerCounts[i].Load())
+	}
+
+	return count
+}
+
 // CanBackToSource represents whether task can back-to-source.
 func (t *Task) CanBackToSource() bool {
 	return int32(t.BackToSourcePeers.Len()) <= t.BackToSourceLimit.Load() && (t.Type == commonv1.TaskType_Normal || t.Type == commonv1.TaskType_DfStore)
@@ -45,6 +45,10 @@ type TaskManager interface {
 	// Delete deletes task for a key.
 	Delete(string)
 
+	// Range calls f sequentially for each key and value present in the map.
+	// If f returns false, range stops the iteration.
+	Range(f func(any, any) bool)
+
 	// Try to reclaim task.
 	RunGC() error
 }
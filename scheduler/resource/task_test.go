@@ -25,6 +25,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 	"d7y.io/api/pkg/apis/scheduler/v1/mocks"
 
@@ -455,6 +456,60 @@ func TestTask_AddPeerEdge(t *testing.T) {
 	}
 }
 
+func TestTask_DeletePeerEdge(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, mockHost *Host, task *Task)
+	}{
+		{
+			name: "delete peer edge failed",
+			expect: func(t *testing.T, mockHost *Host, task *Task) {
+				assert := assert.New(t)
+				mockPeerE := NewPeer(idgen.PeerID("127.0.0.1"), task, mockHost)
+				mockPeerF := NewPeer(idgen.PeerID("127.0.0.1"), task, mockHost)
+				err := task.DeletePeerEdge(mockPeerE, mockPeerF)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "delete peer edge",
+			expect: func(t *testing.T, mockHost *Host, task *Task) {
+				assert := assert.New(t)
+				mockPeerE := NewPeer(idgen.PeerID("127.0.0.1"), task, mockHost)
+				mockPeerF := NewPeer(idgen.PeerID("127.0.0.1"), task, mockHost)
+				mockPeerG := NewPeer(idgen.PeerID("127.0.0.1"), task, mockHost)
+
+				task.StorePeer(mockPeerE)
+				task.StorePeer(mockPeerF)
+				task.StorePeer(mockPeerG)
+
+				err := task.AddPeerEdge(mockPeerE, mockPeerF)
+				assert.NoError(err)
+				err = task.AddPeerEdge(mockPeerG, mockPeerF)
+				assert.NoError(err)
+				assert.Equal(len(mockPeerF.Parents()), 2)
+				assert.Equal(mockHost.ConcurrentUploadCount.Load(), int32(2))
+
+				err = task.DeletePeerEdge(mockPeerE, mockPeerF)
+				assert.NoError(err)
+				assert.Equal(len(mockPeerE.Children()), 0)
+				assert.Equal(mockPeerF.Parents()[0].ID, mockPeerG.ID)
+				assert.Equal(mockHost.ConcurrentUploadCount.Load(), int32(1))
+				assert.Equal(mockHost.UploadCount.Load(), int64(2))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHost := NewHost(mockRawHost)
+			task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+
+			tc.expect(t, mockHost, task)
+		})
+	}
+}
+
 func TestTask_DeletePeerInEdges(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -1476,6 +1531,58 @@ func TestTask_CanBackToSource(t *testing.T) {
 	}
 }
 
+func TestTask_HigherPriorityWaitingPeerCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(task *Task)
+		expect func(t *testing.T, task *Task)
+	}{
+		{
+			name: "no waiting peer",
+			mock: func(task *Task) {},
+			expect: func(t *testing.T, task *Task) {
+				assert := assert.New(t)
+				assert.Equal(task.HigherPriorityWaitingPeerCount(managerv1.Priority_Level0), 0)
+			},
+		},
+		{
+			name: "count waiting peers whose priority is higher",
+			mock: func(task *Task) {
+				task.AddWaitingPeer(managerv1.Priority_Level2)
+				task.AddWaitingPeer(managerv1.Priority_Level3)
+				task.AddWaitingPeer(managerv1.Priority_Level5)
+				task.AddWaitingPeer(managerv1.Priority_Level5)
+			},
+			expect: func(t *testing.T, task *Task) {
+				assert := assert.New(t)
+				assert.Equal(task.HigherPriorityWaitingPeerCount(managerv1.Priority_Level1), 4)
+				assert.Equal(task.HigherPriorityWaitingPeerCount(managerv1.Priority_Level3), 2)
+				assert.Equal(task.HigherPriorityWaitingPeerCount(managerv1.Priority_Level5), 0)
+			},
+		},
+		{
+			name: "waiting peers are deleted",
+			mock: func(task *Task) {
+				task.AddWaitingPeer(managerv1.Priority_Level5)
+				task.AddWaitingPeer(managerv1.Priority_Level4)
+				task.DeleteWaitingPeer(managerv1.Priority_Level5)
+			},
+			expect: func(t *testing.T, task *Task) {
+				assert := assert.New(t)
+				assert.Equal(task.HigherPriorityWaitingPeerCount(managerv1.Priority_Level3), 1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+			tc.mock(task)
+			tc.expect(t, task)
+		})
+	}
+}
+
 func TestTask_CanReuseDirectPiece(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -25,7 +25,9 @@ import (
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/pkg/idgen"
+	clusterrpc "d7y.io/dragonfly/v2/pkg/rpc/scheduler/cluster"
 	"d7y.io/dragonfly/v2/pkg/rpc/scheduler/server"
+	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 	"d7y.io/dragonfly/v2/scheduler/service"
@@ -38,8 +40,16 @@ type Server struct {
 }
 
 // New returns a new transparent scheduler server from the given options.
-func New(service *service.Service, opts ...grpc.ServerOption) *grpc.Server {
-	return server.New(&Server{service: service}, opts...)
+func New(cfg *config.Config, service *service.Service, opts ...grpc.ServerOption) *grpc.Server {
+	svr := &Server{service: service}
+	grpcServer := server.New(svr, opts...)
+
+	// Register cluster service for the other schedulers in the same scheduler cluster.
+	if cfg.Cluster.Enable {
+		clusterrpc.RegisterClusterServer(grpcServer, svr)
+	}
+
+	return grpcServer
 }
 
 // RegisterPeerTask registers peer and triggers seed peer download task.
@@ -133,3 +143,19 @@ func (s *Server) LeaveHost(ctx context.Context, req *schedulerv1.LeaveHostReques
 
 	return new(empty.Empty), nil
 }
+
+// ListTaskPeers streams the succeeded peers of the task to the other schedulers.
+func (s *Server) ListTaskPeers(req *schedulerv1.StatTaskRequest, stream clusterrpc.Cluster_ListTaskPeersServer) error {
+	reqs, err := s.service.ListTaskPeers(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
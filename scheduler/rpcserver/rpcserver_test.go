@@ -23,7 +23,9 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 
+	clusterrpc "d7y.io/dragonfly/v2/pkg/rpc/scheduler/cluster"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
 	"d7y.io/dragonfly/v2/scheduler/resource"
@@ -44,13 +46,30 @@ var (
 func TestRPCServer_New(t *testing.T) {
 	tests := []struct {
 		name   string
-		expect func(t *testing.T, s any)
+		config *config.Config
+		expect func(t *testing.T, s *grpc.Server)
 	}{
 		{
-			name: "new server",
-			expect: func(t *testing.T, s any) {
+			name:   "new server",
+			config: &config.Config{Scheduler: mockSchedulerConfig},
+			expect: func(t *testing.T, s *grpc.Server) {
 				assert := assert.New(t)
 				assert.Equal(reflect.TypeOf(s).Elem().Name(), "Server")
+				assert.NotContains(s.GetServiceInfo(), clusterrpc.Cluster_ServiceDesc.ServiceName)
+			},
+		},
+		{
+			name: "new server with cluster enabled",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				Cluster: config.ClusterConfig{
+					Enable: true,
+				},
+			},
+			expect: func(t *testing.T, s *grpc.Server) {
+				assert := assert.New(t)
+				assert.Equal(reflect.TypeOf(s).Elem().Name(), "Server")
+				assert.Contains(s.GetServiceInfo(), clusterrpc.Cluster_ServiceDesc.ServiceName)
 			},
 		},
 	}
@@ -63,9 +82,9 @@ func TestRPCServer_New(t *testing.T) {
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
 			storage := storagemocks.NewMockStorage(ctl)
-			svc := service.New(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduler, dynconfig, storage)
+			svc := service.New(tc.config, res, scheduler, dynconfig, storage)
 
-			svr := New(svc)
+			svr := New(tc.config, svc)
 			tc.expect(t, svr)
 		})
 	}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"path"
 	"path/filepath"
 	"time"
 
@@ -39,10 +40,12 @@ import (
 	"d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/pkg/issuer"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
+	"d7y.io/dragonfly/v2/pkg/objectstorage"
 	"d7y.io/dragonfly/v2/pkg/rpc"
 	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	"d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/scheduler/announcer"
+	"d7y.io/dragonfly/v2/scheduler/cluster"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/job"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
@@ -51,6 +54,7 @@ import (
 	"d7y.io/dragonfly/v2/scheduler/scheduler"
 	"d7y.io/dragonfly/v2/scheduler/service"
 	"d7y.io/dragonfly/v2/scheduler/storage"
+	"d7y.io/dragonfly/v2/scheduler/training"
 )
 
 const (
@@ -87,6 +91,12 @@ type Server struct {
 	// Announcer interface.
 	announcer announcer.Announcer
 
+	// Cluster interface.
+	cluster cluster.Cluster
+
+	// Training interface.
+	training training.Training
+
 	// GC service.
 	gc gc.GC
 }
@@ -162,29 +172,79 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 	s.gc = gc.New(gc.WithLogger(logger.GCLogger))
 
 	// Initialize resource.
-	resource, err := resource.New(cfg, s.gc, dynconfig, resource.WithTransportCredentials(clientTransportCredentials))
+	resourceOptions := []resource.Option{resource.WithTransportCredentials(clientTransportCredentials)}
+	if cfg.Snapshot.Enable {
+		snapshotBackend, err := resource.NewSnapshotBackend(cfg, d.DataDir())
+		if err != nil {
+			return nil, err
+		}
+
+		resourceOptions = append(resourceOptions, resource.WithSnapshotBackend(snapshotBackend))
+	}
+
+	resource, err := resource.New(cfg, s.gc, dynconfig, resourceOptions...)
 	if err != nil {
 		return nil, err
 	}
 	s.resource = resource
 
 	// Initialize scheduler.
-	scheduler := scheduler.New(&cfg.Scheduler, dynconfig, d.PluginDir())
+	scheduler := scheduler.New(&cfg.Scheduler, dynconfig, d.PluginDir(), scheduler.WithManagerClient(s.managerClient))
 
 	// Initialize Storage.
+	sink, err := storage.NewSink(cfg.Storage.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	storageOptions := []storage.Option{
+		storage.WithSink(sink),
+		storage.WithMaxAge(cfg.Storage.MaxAge),
+		storage.WithFlushInterval(cfg.Storage.FlushInterval),
+	}
+	if cfg.Storage.Upload.Enable {
+		objectStorage, err := objectstorage.New(
+			cfg.Storage.Upload.Name,
+			cfg.Storage.Upload.Region,
+			cfg.Storage.Upload.Endpoint,
+			cfg.Storage.Upload.AccessKey,
+			cfg.Storage.Upload.SecretKey,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Record files of schedulers are stored in different directories of the bucket.
+		prefix := path.Join(cfg.Storage.Upload.Prefix, fmt.Sprintf("%s-%s", cfg.Server.Host, cfg.Server.AdvertiseIP))
+		storageOptions = append(storageOptions, storage.WithUploader(storage.NewObjectStorageUploader(objectStorage, cfg.Storage.Upload.BucketName, prefix)))
+	}
+
 	storage, err := storage.New(
 		d.DataDir(),
 		cfg.Storage.MaxSize,
 		cfg.Storage.MaxBackups,
 		cfg.Storage.BufferSize,
+		storageOptions...,
 	)
 	if err != nil {
 		return nil, err
 	}
 	s.storage = storage
 
+	// Initialize training.
+	if cfg.Scheduler.Training.Enable {
+		s.training = training.New(cfg, s.storage, s.managerClient, dynconfig)
+	}
+
+	// Initialize cluster.
+	var serviceOptions []service.Option
+	if cfg.Cluster.Enable {
+		s.cluster = cluster.New(cfg, s.managerClient, cluster.WithTransportCredentials(clientTransportCredentials))
+		serviceOptions = append(serviceOptions, service.WithCluster(s.cluster))
+	}
+
 	// Initialize scheduler service.
-	service := service.New(cfg, resource, scheduler, dynconfig, s.storage)
+	service := service.New(cfg, resource, scheduler, dynconfig, s.storage, serviceOptions...)
 
 	// Initialize grpc service and server options of scheduler grpc server.
 	schedulerServerOptions := []grpc.ServerOption{}
@@ -199,12 +259,12 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 		schedulerServerOptions = append(schedulerServerOptions, grpc.Creds(insecure.NewCredentials()))
 	}
 
-	svr := rpcserver.New(service, schedulerServerOptions...)
+	svr := rpcserver.New(cfg, service, schedulerServerOptions...)
 	s.grpcServer = svr
 
 	// Initialize job service.
 	if cfg.Job.Enable {
-		s.job, err = job.New(cfg, resource)
+		s.job, err = job.New(cfg, resource, job.WithTransportCredentials(clientTransportCredentials))
 		if err != nil {
 			return nil, err
 		}
@@ -237,6 +297,18 @@ func (s *Server) Serve() error {
 		logger.Info("job start successfully")
 	}
 
+	// Serve cluster.
+	if s.cluster != nil {
+		go s.cluster.Serve()
+		logger.Info("cluster start successfully")
+	}
+
+	// Serve training.
+	if s.training != nil {
+		go s.training.Serve()
+		logger.Info("training start successfully")
+	}
+
 	// Started metrics server.
 	if s.metricsServer != nil {
 		go func() {
@@ -288,6 +360,15 @@ func (s *Server) Stop() {
 		logger.Info("stop dynconfig closed")
 	}
 
+	// Stop training.
+	if s.training != nil {
+		if err := s.training.Stop(); err != nil {
+			logger.Errorf("stop training failed %s", err.Error())
+		} else {
+			logger.Info("stop training closed")
+		}
+	}
+
 	// Stop resource.
 	if err := s.resource.Stop(); err != nil {
 		logger.Errorf("stop resource failed %s", err.Error())
@@ -295,6 +376,11 @@ func (s *Server) Stop() {
 		logger.Info("stop resource closed")
 	}
 
+	// Close storage.
+	if err := s.storage.Close(); err != nil {
+		logger.Errorf("close storage failed %s", err.Error())
+	}
+
 	// Clean storage.
 	if err := s.storage.Clear(); err != nil {
 		logger.Errorf("clean storage failed %s", err.Error())
@@ -315,6 +401,15 @@ func (s *Server) Stop() {
 		}
 	}
 
+	// Stop cluster.
+	if s.cluster != nil {
+		if err := s.cluster.Stop(); err != nil {
+			logger.Errorf("stop cluster failed %s", err.Error())
+		} else {
+			logger.Info("stop cluster closed")
+		}
+	}
+
 	// Stop announcer.
 	if err := s.announcer.Stop(); err != nil {
 		logger.Errorf("stop announcer failed %s", err.Error())
@@ -17,6 +17,10 @@
 package evaluator
 
 import (
+	"sort"
+
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
@@ -32,6 +36,9 @@ const (
 )
 
 type Evaluator interface {
+	// EvaluateParents sorts the parents by evaluation score in descending order.
+	EvaluateParents(parents []*resource.Peer, child *resource.Peer, taskPieceCount int32) []*resource.Peer
+
 	// Evaluate todo Normalization.
 	Evaluate(parent *resource.Peer, child *resource.Peer, taskPieceCount int32) float64
 
@@ -39,16 +46,109 @@ type Evaluator interface {
 	IsBadNode(peer *resource.Peer) bool
 }
 
-func New(algorithm string, pluginDir string) Evaluator {
+// options is the options of evaluator.
+type options struct {
+	// managerClient is the manager grpc client.
+	managerClient managerclient.Client
+
+	// dynconfig is the dynamic config of scheduler.
+	dynconfig config.DynconfigInterface
+
+	// evaluatorConfig is the weights configuration of evaluator.
+	evaluatorConfig config.EvaluatorConfig
+}
+
+// newOptions returns options with default weights.
+func newOptions(opts ...Option) *options {
+	o := &options{
+		evaluatorConfig: config.EvaluatorConfig{
+			FinishedPieceWeight:           config.DefaultEvaluatorFinishedPieceWeight,
+			ParentHostUploadSuccessWeight: config.DefaultEvaluatorParentHostUploadSuccessWeight,
+			FreeUploadWeight:              config.DefaultEvaluatorFreeUploadWeight,
+			HostTypeWeight:                config.DefaultEvaluatorHostTypeWeight,
+			IDCAffinityWeight:             config.DefaultEvaluatorIDCAffinityWeight,
+			NetTopologyAffinityWeight:     config.DefaultEvaluatorNetTopologyAffinityWeight,
+			LocationAffinityWeight:        config.DefaultEvaluatorLocationAffinityWeight,
+			CPULoadWeight:                 config.DefaultEvaluatorCPULoadWeight,
+			MemoryPressureWeight:          config.DefaultEvaluatorMemoryPressureWeight,
+			DiskFreeWeight:                config.DefaultEvaluatorDiskFreeWeight,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// Option is a functional option for configuring the evaluator.
+type Option func(o *options)
+
+// WithManagerClient sets the manager client, it is required by MLAlgorithm.
+func WithManagerClient(client managerclient.Client) Option {
+	return func(o *options) {
+		o.managerClient = client
+	}
+}
+
+// WithDynconfig sets the dynamic config, it is required by MLAlgorithm,
+// and the weights of evaluator will be overridden by scheduler cluster config.
+func WithDynconfig(dynconfig config.DynconfigInterface) Option {
+	return func(o *options) {
+		o.dynconfig = dynconfig
+	}
+}
+
+// WithEvaluatorConfig sets the weights configuration of evaluator.
+func WithEvaluatorConfig(cfg config.EvaluatorConfig) Option {
+	return func(o *options) {
+		o.evaluatorConfig = cfg
+	}
+}
+
+func New(algorithm string, pluginDir string, opts ...Option) Evaluator {
+	o := newOptions(opts...)
+
 	switch algorithm {
 	case PluginAlgorithm:
 		if plugin, err := LoadPlugin(pluginDir); err == nil {
 			return plugin
 		}
-	// TODO Implement MLAlgorithm.
-	case MLAlgorithm, DefaultAlgorithm:
-		return NewEvaluatorBase()
+	case MLAlgorithm:
+		// If the manager client or dynconfig is not provided,
+		// fall back to the rule-based algorithm.
+		if o.managerClient != nil && o.dynconfig != nil {
+			return NewEvaluatorML(o.managerClient, o.dynconfig, opts...)
+		}
+	case DefaultAlgorithm:
+		return NewEvaluatorBase(opts...)
+	}
+
+	return NewEvaluatorBase(opts...)
+}
+
+// parentsByScore sorts the parents by the scores in descending order.
+type parentsByScore struct {
+	parents []*resource.Peer
+	scores  []float64
+}
+
+func (p *parentsByScore) Len() int           { return len(p.parents) }
+func (p *parentsByScore) Less(i, j int) bool { return p.scores[i] > p.scores[j] }
+func (p *parentsByScore) Swap(i, j int) {
+	p.parents[i], p.parents[j] = p.parents[j], p.parents[i]
+	p.scores[i], p.scores[j] = p.scores[j], p.scores[i]
+}
+
+// evaluateParents evaluates every parent once by evaluate, and sorts the parents by the scores.
+func evaluateParents(evaluate func(parent *resource.Peer, child *resource.Peer, taskPieceCount int32) float64,
+	parents []*resource.Peer, child *resource.Peer, taskPieceCount int32) []*resource.Peer {
+	scores := make([]float64, len(parents))
+	for i, parent := range parents {
+		scores[i] = evaluate(parent, child, taskPieceCount)
 	}
 
-	return NewEvaluatorBase()
+	sort.Stable(&parentsByScore{parents: parents, scores: scores})
+	return parents
 }
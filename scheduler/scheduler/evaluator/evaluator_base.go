@@ -19,38 +19,17 @@ package evaluator
 import (
 	"math/big"
 	"strings"
+	"sync"
 
 	"github.com/montanaflynn/stats"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/math"
 	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
-const (
-	// Finished piece weight.
-	finishedPieceWeight float64 = 0.2
-
-	// Parent's host upload success weight.
-	parentHostUploadSuccessWeight = 0.2
-
-	// Free upload weight.
-	freeUploadWeight = 0.15
-
-	// Host type weight.
-	hostTypeWeight = 0.15
-
-	// IDC affinity weight.
-	idcAffinityWeight = 0.1
-
-	// NetTopology affinity weight.
-	netTopologyAffinityWeight = 0.1
-
-	// Location affinity weight.
-	locationAffinityWeight = 0.1
-)
-
 const (
 	// Maximum score.
 	maxScore float64 = 1
@@ -72,10 +51,75 @@ const (
 	maxElementLen = 5
 )
 
-type evaluatorBase struct{}
+type evaluatorBase struct {
+	// config is the weights configuration of scheduler.
+	config config.EvaluatorConfig
+
+	// weights is the weights in use, it is the config
+	// overridden by the scheduler cluster config.
+	weights config.EvaluatorConfig
 
-func NewEvaluatorBase() Evaluator {
-	return &evaluatorBase{}
+	// mu is used to protect weights.
+	mu *sync.RWMutex
+}
+
+// NewEvaluatorBase returns a new rule-based evaluator, if dynconfig is provided,
+// the weights will be overridden by the scheduler cluster config.
+func NewEvaluatorBase(opts ...Option) Evaluator {
+	o := newOptions(opts...)
+	eb := newEvaluatorBase(o.evaluatorConfig)
+	if o.dynconfig != nil {
+		o.dynconfig.Register(eb)
+	}
+
+	return eb
+}
+
+// newEvaluatorBase returns a new rule-based evaluator with weights.
+func newEvaluatorBase(cfg config.EvaluatorConfig) *evaluatorBase {
+	return &evaluatorBase{
+		config:  cfg,
+		weights: cfg,
+		mu:      &sync.RWMutex{},
+	}
+}
+
+// OnNotify overrides the weights with the scheduler cluster config.
+func (eb *evaluatorBase) OnNotify(data *config.DynconfigData) {
+	weights := eb.config
+	if data != nil {
+		if clusterConfig, err := config.GetSchedulerClusterConfigByScheduler(data.Scheduler); err == nil && clusterConfig.Evaluator != nil {
+			overrideWeight(&weights.FinishedPieceWeight, clusterConfig.Evaluator.FinishedPieceWeight)
+			overrideWeight(&weights.ParentHostUploadSuccessWeight, clusterConfig.Evaluator.ParentHostUploadSuccessWeight)
+			overrideWeight(&weights.FreeUploadWeight, clusterConfig.Evaluator.FreeUploadWeight)
+			overrideWeight(&weights.HostTypeWeight, clusterConfig.Evaluator.HostTypeWeight)
+			overrideWeight(&weights.IDCAffinityWeight, clusterConfig.Evaluator.IDCAffinityWeight)
+			overrideWeight(&weights.NetTopologyAffinityWeight, clusterConfig.Evaluator.NetTopologyAffinityWeight)
+			overrideWeight(&weights.LocationAffinityWeight, clusterConfig.Evaluator.LocationAffinityWeight)
+			overrideWeight(&weights.CPULoadWeight, clusterConfig.Evaluator.CPULoadWeight)
+			overrideWeight(&weights.MemoryPressureWeight, clusterConfig.Evaluator.MemoryPressureWeight)
+			overrideWeight(&weights.DiskFreeWeight, clusterConfig.Evaluator.DiskFreeWeight)
+		}
+	}
+
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	if eb.weights != weights {
+		logger.Infof("evaluator weights have been updated: %#v", weights)
+		eb.weights = weights
+	}
+}
+
+// overrideWeight overrides weight if the value is set and non-negative.
+func overrideWeight(weight *float64, value *float64) {
+	if value != nil && *value >= 0 {
+		*weight = *value
+	}
+}
+
+// EvaluateParents sorts the parents by evaluation score in descending order.
+func (eb *evaluatorBase) EvaluateParents(parents []*resource.Peer, child *resource.Peer, totalPieceCount int32) []*resource.Peer {
+	return evaluateParents(eb.Evaluate, parents, child, totalPieceCount)
 }
 
 // The larger the value after evaluation, the higher the priority.
@@ -112,13 +156,32 @@ func (eb *evaluatorBase) Evaluate(parent *resource.Peer, child *resource.Peer, t
 		return minScore
 	}
 
-	return finishedPieceWeight*calculatePieceScore(parent, child, totalPieceCount) +
-		parentHostUploadSuccessWeight*calculateParentHostUploadSuccessScore(parent) +
-		freeUploadWeight*calculateFreeUploadScore(parent.Host) +
-		hostTypeWeight*calculateHostTypeScore(parent) +
-		idcAffinityWeight*calculateIDCAffinityScore(parentIDC, childIDC) +
-		netTopologyAffinityWeight*calculateMultiElementAffinityScore(parentNetTopology, childNetTopology) +
-		locationAffinityWeight*calculateMultiElementAffinityScore(parentLocation, childLocation)
+	eb.mu.RLock()
+	weights := eb.weights
+	eb.mu.RUnlock()
+
+	score := weights.FinishedPieceWeight*calculatePieceScore(parent, child, totalPieceCount) +
+		weights.ParentHostUploadSuccessWeight*calculateParentHostUploadSuccessScore(parent) +
+		weights.FreeUploadWeight*calculateFreeUploadScore(parent.Host) +
+		weights.HostTypeWeight*calculateHostTypeScore(parent) +
+		weights.IDCAffinityWeight*CalculateIDCAffinityScore(parentIDC, childIDC) +
+		weights.NetTopologyAffinityWeight*CalculateMultiElementAffinityScore(parentNetTopology, childNetTopology) +
+		weights.LocationAffinityWeight*CalculateMultiElementAffinityScore(parentLocation, childLocation)
+
+	// Optional terms based on host stats are calculated only if their weights are set.
+	if weights.CPULoadWeight > 0 {
+		score += weights.CPULoadWeight * calculateCPULoadScore(parent.Host)
+	}
+
+	if weights.MemoryPressureWeight > 0 {
+		score += weights.MemoryPressureWeight * calculateMemoryPressureScore(parent.Host)
+	}
+
+	if weights.DiskFreeWeight > 0 {
+		score += weights.DiskFreeWeight * calculateDiskFreeScore(parent.Host)
+	}
+
+	return score
 }
 
 // calculatePieceScore 0.0~unlimited larger and better.
@@ -181,8 +244,48 @@ func calculateHostTypeScore(peer *resource.Peer) float64 {
 	return maxScore * 0.5
 }
 
-// calculateIDCAffinityScore 0.0~1.0 larger and better.
-func calculateIDCAffinityScore(dst, src string) float64 {
+// calculateCPULoadScore 0.0~1.0 larger and better.
+func calculateCPULoadScore(host *resource.Host) float64 {
+	if host.CPU == nil {
+		return minScore
+	}
+
+	return calculateFreePercentScore(host.CPU.Percent)
+}
+
+// calculateMemoryPressureScore 0.0~1.0 larger and better.
+func calculateMemoryPressureScore(host *resource.Host) float64 {
+	if host.Memory == nil {
+		return minScore
+	}
+
+	return calculateFreePercentScore(host.Memory.UsedPercent)
+}
+
+// calculateDiskFreeScore 0.0~1.0 larger and better.
+func calculateDiskFreeScore(host *resource.Host) float64 {
+	if host.Disk == nil {
+		return minScore
+	}
+
+	return calculateFreePercentScore(host.Disk.UsedPercent)
+}
+
+// calculateFreePercentScore converts used percent(0~100) to free score, 0.0~1.0 larger and better.
+func calculateFreePercentScore(usedPercent float64) float64 {
+	if usedPercent <= 0 {
+		return maxScore
+	}
+
+	if usedPercent >= 100 {
+		return minScore
+	}
+
+	return (100 - usedPercent) / 100
+}
+
+// CalculateIDCAffinityScore 0.0~1.0 larger and better.
+func CalculateIDCAffinityScore(dst, src string) float64 {
 	if dst != "" && src != "" && dst == src {
 		return maxScore
 	}
@@ -190,8 +293,8 @@ func calculateIDCAffinityScore(dst, src string) float64 {
 	return minScore
 }
 
-// calculateMultiElementAffinityScore 0.0~1.0 larger and better.
-func calculateMultiElementAffinityScore(dst, src string) float64 {
+// CalculateMultiElementAffinityScore 0.0~1.0 larger and better.
+func CalculateMultiElementAffinityScore(dst, src string) float64 {
 	if dst == "" || src == "" {
 		return minScore
 	}
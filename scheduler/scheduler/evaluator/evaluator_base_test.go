@@ -23,10 +23,12 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
@@ -448,7 +450,7 @@ func TestEvaluatorBase_calculateHostTypeScore(t *testing.T) {
 	}
 }
 
-func TestEvaluatorBase_calculateIDCAffinityScore(t *testing.T) {
+func TestEvaluatorBase_CalculateIDCAffinityScore(t *testing.T) {
 	tests := []struct {
 		name   string
 		mock   func(dstHost *resource.Host, srcHost *resource.Host)
@@ -514,12 +516,12 @@ func TestEvaluatorBase_calculateIDCAffinityScore(t *testing.T) {
 			dstHost := resource.NewHost(mockRawHost)
 			srcHost := resource.NewHost(mockRawSeedHost)
 			tc.mock(dstHost, srcHost)
-			tc.expect(t, calculateIDCAffinityScore(dstHost.Network.Idc, srcHost.Network.Idc))
+			tc.expect(t, CalculateIDCAffinityScore(dstHost.Network.Idc, srcHost.Network.Idc))
 		})
 	}
 }
 
-func TestEvaluatorBase_calculateMultiElementAffinityScore(t *testing.T) {
+func TestEvaluatorBase_CalculateMultiElementAffinityScore(t *testing.T) {
 	tests := []struct {
 		name   string
 		dst    string
@@ -638,7 +640,186 @@ func TestEvaluatorBase_calculateMultiElementAffinityScore(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			tc.expect(t, calculateMultiElementAffinityScore(tc.dst, tc.src))
+			tc.expect(t, CalculateMultiElementAffinityScore(tc.dst, tc.src))
+		})
+	}
+}
+
+func TestEvaluatorBase_OnNotify(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   *config.DynconfigData
+		expect func(t *testing.T, eb *evaluatorBase)
+	}{
+		{
+			name: "override weights with scheduler cluster config",
+			data: &config.DynconfigData{
+				Scheduler: &managerv1.Scheduler{
+					SchedulerCluster: &managerv1.SchedulerCluster{
+						Config: []byte(`{"evaluator":{"finished_piece_weight":0.5,"cpu_load_weight":0.3}}`),
+					},
+				},
+			},
+			expect: func(t *testing.T, eb *evaluatorBase) {
+				assert := assert.New(t)
+				assert.Equal(eb.weights.FinishedPieceWeight, float64(0.5))
+				assert.Equal(eb.weights.CPULoadWeight, float64(0.3))
+				assert.Equal(eb.weights.FreeUploadWeight, config.DefaultEvaluatorFreeUploadWeight)
+			},
+		},
+		{
+			name: "scheduler cluster config does not contain evaluator",
+			data: &config.DynconfigData{
+				Scheduler: &managerv1.Scheduler{
+					SchedulerCluster: &managerv1.SchedulerCluster{
+						Config: []byte(`{"filter_parent_limit":4}`),
+					},
+				},
+			},
+			expect: func(t *testing.T, eb *evaluatorBase) {
+				assert := assert.New(t)
+				assert.Equal(eb.weights, eb.config)
+			},
+		},
+		{
+			name: "scheduler cluster is empty",
+			data: &config.DynconfigData{
+				Scheduler: &managerv1.Scheduler{},
+			},
+			expect: func(t *testing.T, eb *evaluatorBase) {
+				assert := assert.New(t)
+				assert.Equal(eb.weights, eb.config)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			eb := NewEvaluatorBase().(*evaluatorBase)
+			eb.weights.FinishedPieceWeight = 1
+			eb.OnNotify(tc.data)
+			tc.expect(t, eb)
+		})
+	}
+}
+
+func TestEvaluatorBase_calculateCPULoadScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(host *resource.Host)
+		expect func(t *testing.T, score float64)
+	}{
+		{
+			name: "host cpu percent is 20",
+			mock: func(host *resource.Host) {
+				host.CPU = &schedulerv1.CPU{Percent: 20}
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0.8))
+			},
+		},
+		{
+			name: "host cpu percent is greater than 100",
+			mock: func(host *resource.Host) {
+				host.CPU = &schedulerv1.CPU{Percent: 120}
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0))
+			},
+		},
+		{
+			name: "host cpu is empty",
+			mock: func(host *resource.Host) {
+				host.CPU = nil
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host := resource.NewHost(mockRawHost)
+			tc.mock(host)
+			tc.expect(t, calculateCPULoadScore(host))
+		})
+	}
+}
+
+func TestEvaluatorBase_calculateMemoryPressureScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(host *resource.Host)
+		expect func(t *testing.T, score float64)
+	}{
+		{
+			name: "host memory used percent is 75",
+			mock: func(host *resource.Host) {
+				host.Memory = &schedulerv1.Memory{UsedPercent: 75}
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0.25))
+			},
+		},
+		{
+			name: "host memory is empty",
+			mock: func(host *resource.Host) {
+				host.Memory = nil
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host := resource.NewHost(mockRawHost)
+			tc.mock(host)
+			tc.expect(t, calculateMemoryPressureScore(host))
+		})
+	}
+}
+
+func TestEvaluatorBase_calculateDiskFreeScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(host *resource.Host)
+		expect func(t *testing.T, score float64)
+	}{
+		{
+			name: "host disk used percent is 0",
+			mock: func(host *resource.Host) {
+				host.Disk = &schedulerv1.Disk{UsedPercent: 0}
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(1))
+			},
+		},
+		{
+			name: "host disk is empty",
+			mock: func(host *resource.Host) {
+				host.Disk = nil
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host := resource.NewHost(mockRawHost)
+			tc.mock(host)
+			tc.expect(t, calculateDiskFreeScore(host))
 		})
 	}
 }
@@ -0,0 +1,257 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evaluator
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+	pkgtypes "d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+const (
+	// defaultRefreshTimeout is the timeout of loading the active model version from manager.
+	defaultRefreshTimeout = 30 * time.Second
+)
+
+type evaluatorML struct {
+	// base is the rule-based evaluator,
+	// it is used when the model is unavailable or inference fails.
+	base *evaluatorBase
+
+	// managerClient is the manager grpc client.
+	managerClient managerclient.Client
+
+	// model is the active model.
+	model Model
+
+	// versionID is the version id of the active model.
+	versionID string
+
+	// mu is used to protect model and versionID.
+	mu *sync.RWMutex
+
+	// refreshMu ensures that only one refresh is running.
+	refreshMu *sync.Mutex
+
+	// loaded is closed when the first load of model is finished.
+	loaded chan struct{}
+}
+
+// NewEvaluatorML returns a new machine learning evaluator, the active model version is
+// loaded from manager and swapped when dynconfig notifies a new version is activated.
+// The first load is asynchronous, parents are evaluated by the default algorithm until it finishes.
+func NewEvaluatorML(managerClient managerclient.Client, dynconfig config.DynconfigInterface, opts ...Option) Evaluator {
+	o := newOptions(opts...)
+	e := &evaluatorML{
+		base:          newEvaluatorBase(o.evaluatorConfig),
+		managerClient: managerClient,
+		mu:            &sync.RWMutex{},
+		refreshMu:     &sync.Mutex{},
+		loaded:        make(chan struct{}),
+	}
+
+	if data, err := dynconfig.Get(); err == nil {
+		e.base.OnNotify(data)
+		go func() {
+			defer close(e.loaded)
+			e.refresh(data)
+		}()
+	} else {
+		logger.Warnf("get dynconfig failed, ml evaluator falls back to the default algorithm: %s", err.Error())
+		close(e.loaded)
+	}
+
+	dynconfig.Register(e)
+	return e
+}
+
+// EvaluateParents sorts the parents by evaluation score in descending order. If the model is
+// unavailable or the prediction of any parent fails, all parents are evaluated by the default
+// algorithm, so the scores of different algorithms are not compared.
+func (e *evaluatorML) EvaluateParents(parents []*resource.Peer, child *resource.Peer, totalPieceCount int32) []*resource.Peer {
+	e.mu.RLock()
+	model := e.model
+	e.mu.RUnlock()
+
+	if model == nil {
+		return e.base.EvaluateParents(parents, child, totalPieceCount)
+	}
+
+	scores := make([]float64, len(parents))
+	for i, parent := range parents {
+		score, err := e.evaluate(model, parent, child)
+		if err != nil {
+			child.Log.Warnf("ml evaluator predicts parent %s failed, falls back to the default algorithm: %s", parent.ID, err.Error())
+			return e.base.EvaluateParents(parents, child, totalPieceCount)
+		}
+
+		scores[i] = score
+	}
+
+	sort.Stable(&parentsByScore{parents: parents, scores: scores})
+	return parents
+}
+
+// The larger the value after evaluation, the higher the priority.
+func (e *evaluatorML) Evaluate(parent *resource.Peer, child *resource.Peer, totalPieceCount int32) float64 {
+	e.mu.RLock()
+	model := e.model
+	e.mu.RUnlock()
+
+	if model == nil {
+		return e.base.Evaluate(parent, child, totalPieceCount)
+	}
+
+	score, err := e.evaluate(model, parent, child)
+	if err != nil {
+		parent.Log.Warnf("ml evaluator predicts failed, falls back to the default algorithm: %s", err.Error())
+		return e.base.Evaluate(parent, child, totalPieceCount)
+	}
+
+	return score
+}
+
+// evaluate returns the score of parent by the piece cost predicted by model.
+func (e *evaluatorML) evaluate(model Model, parent *resource.Peer, child *resource.Peer) (float64, error) {
+	// If the SecurityDomain of hosts exists but is not equal,
+	// it cannot be scheduled as a parent.
+	if parent.Host.Network != nil && child.Host.Network != nil &&
+		parent.Host.Network.SecurityDomain != "" && child.Host.Network.SecurityDomain != "" &&
+		parent.Host.Network.SecurityDomain != child.Host.Network.SecurityDomain {
+		return minScore, nil
+	}
+
+	cost, err := model.Predict(NewFeatures(parent, child))
+	if err != nil {
+		return 0, err
+	}
+
+	// The lower the piece cost, the higher the score.
+	if cost < 0 {
+		cost = 0
+	}
+
+	return maxScore / (1 + cost), nil
+}
+
+// IsBadNode determine if peer is a failed node.
+func (e *evaluatorML) IsBadNode(peer *resource.Peer) bool {
+	return e.base.IsBadNode(peer)
+}
+
+// OnNotify refreshes the weights of rule-based evaluator and the model when dynconfig is changed.
+func (e *evaluatorML) OnNotify(data *config.DynconfigData) {
+	e.base.OnNotify(data)
+	go e.refresh(data)
+}
+
+// refresh loads the active model version from manager,
+// if the version is changed, it will be swapped.
+func (e *evaluatorML) refresh(data *config.DynconfigData) {
+	if data == nil || data.Scheduler == nil {
+		return
+	}
+
+	// Skip if the previous refresh is running.
+	if !e.refreshMu.TryLock() {
+		return
+	}
+	defer e.refreshMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRefreshTimeout)
+	defer cancel()
+
+	model, err := e.managerClient.GetModel(ctx, &managerv1.GetModelRequest{
+		SchedulerId: data.Scheduler.Id,
+		ModelId:     types.ModelIDEvaluator,
+	})
+	if err != nil {
+		logger.Warnf("get model %s failed: %s", types.ModelIDEvaluator, err.Error())
+		return
+	}
+
+	e.mu.RLock()
+	versionID := e.versionID
+	e.mu.RUnlock()
+	if model.VersionId == "" || model.VersionId == versionID {
+		return
+	}
+
+	modelVersion, err := e.managerClient.GetModelVersion(ctx, &managerv1.GetModelVersionRequest{
+		SchedulerId: data.Scheduler.Id,
+		ModelId:     types.ModelIDEvaluator,
+		VersionId:   model.VersionId,
+	})
+	if err != nil {
+		logger.Errorf("get model %s version %s failed: %s", types.ModelIDEvaluator, model.VersionId, err.Error())
+		return
+	}
+
+	m, err := UnmarshalModel(modelVersion.Data)
+	if err != nil {
+		logger.Errorf("unmarshal model %s version %s failed: %s", types.ModelIDEvaluator, model.VersionId, err.Error())
+		return
+	}
+
+	e.mu.Lock()
+	e.model = m
+	e.versionID = model.VersionId
+	e.mu.Unlock()
+	logger.Infof("ml evaluator swaps model %s to version %s", types.ModelIDEvaluator, model.VersionId)
+}
+
+// NewFeatures returns the features of parent for child.
+func NewFeatures(parent *resource.Peer, child *resource.Peer) *Features {
+	features := &Features{
+		FreeUploadScore:    calculateFreeUploadScore(parent.Host),
+		UploadSuccessScore: calculateParentHostUploadSuccessScore(parent),
+	}
+
+	if parent.Host.Type != pkgtypes.HostTypeNormal {
+		features.IsSeedPeer = maxScore
+	}
+
+	if parent.Host.Network != nil && child.Host.Network != nil {
+		features.IDCAffinityScore = CalculateIDCAffinityScore(parent.Host.Network.Idc, child.Host.Network.Idc)
+		features.NetTopologyAffinityScore = CalculateMultiElementAffinityScore(parent.Host.Network.NetTopology, child.Host.Network.NetTopology)
+		features.LocationAffinityScore = CalculateMultiElementAffinityScore(parent.Host.Network.Location, child.Host.Network.Location)
+	}
+
+	if parent.Host.CPU != nil {
+		features.CPUPercent = parent.Host.CPU.Percent / 100
+	}
+
+	if parent.Host.Memory != nil {
+		features.MemoryUsedPercent = parent.Host.Memory.UsedPercent / 100
+	}
+
+	if parent.Host.Disk != nil {
+		features.DiskUsedPercent = parent.Host.Disk.UsedPercent / 100
+	}
+
+	return features
+}
@@ -0,0 +1,404 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	managerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+var (
+	mockSchedulerID uint64 = 1
+
+	mockDynconfigData = &config.DynconfigData{
+		Scheduler: &managerv1.Scheduler{
+			Id: mockSchedulerID,
+		},
+	}
+
+	mockLinearRegressionModel = &LinearRegressionModel{
+		Type:              LinearRegressionModelType,
+		Weights:           []float64{-1, -1, -1, -1, -1, -1, 1, 1, 1},
+		Bias:              10,
+		Mean:              make([]float64, FeatureCount),
+		StandardDeviation: []float64{1, 1, 1, 1, 1, 1, 1, 1, 1},
+	}
+)
+
+func TestEvaluatorML_NewEvaluatorML(t *testing.T) {
+	data, err := json.Marshal(mockLinearRegressionModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		mock   func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, managerClient *managerclientmocks.MockClientMockRecorder)
+		expect func(t *testing.T, e *evaluatorML)
+	}{
+		{
+			name: "new ml evaluator and load model",
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, managerClient *managerclientmocks.MockClientMockRecorder) {
+				dynconfig.Get().Return(mockDynconfigData, nil).Times(1)
+				dynconfig.Register(gomock.Any()).Times(1)
+				gomock.InOrder(
+					managerClient.GetModel(gomock.Any(), gomock.Eq(&managerv1.GetModelRequest{
+						SchedulerId: mockSchedulerID,
+						ModelId:     types.ModelIDEvaluator,
+					})).Return(&managerv1.Model{VersionId: "foo"}, nil).Times(1),
+					managerClient.GetModelVersion(gomock.Any(), gomock.Eq(&managerv1.GetModelVersionRequest{
+						SchedulerId: mockSchedulerID,
+						ModelId:     types.ModelIDEvaluator,
+						VersionId:   "foo",
+					})).Return(&managerv1.ModelVersion{VersionId: "foo", Data: data}, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, e *evaluatorML) {
+				assert := assert.New(t)
+				assert.Equal(e.versionID, "foo")
+				assert.NotNil(e.model)
+			},
+		},
+		{
+			name: "new ml evaluator without model",
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, managerClient *managerclientmocks.MockClientMockRecorder) {
+				dynconfig.Get().Return(mockDynconfigData, nil).Times(1)
+				dynconfig.Register(gomock.Any()).Times(1)
+				managerClient.GetModel(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, e *evaluatorML) {
+				assert := assert.New(t)
+				assert.Equal(e.versionID, "")
+				assert.Nil(e.model)
+			},
+		},
+		{
+			name: "new ml evaluator with invalid model data",
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, managerClient *managerclientmocks.MockClientMockRecorder) {
+				dynconfig.Get().Return(mockDynconfigData, nil).Times(1)
+				dynconfig.Register(gomock.Any()).Times(1)
+				gomock.InOrder(
+					managerClient.GetModel(gomock.Any(), gomock.Any()).Return(&managerv1.Model{VersionId: "foo"}, nil).Times(1),
+					managerClient.GetModelVersion(gomock.Any(), gomock.Any()).Return(&managerv1.ModelVersion{VersionId: "foo", Data: []byte("{}")}, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, e *evaluatorML) {
+				assert := assert.New(t)
+				assert.Equal(e.versionID, "")
+				assert.Nil(e.model)
+			},
+		},
+		{
+			name: "get dynconfig failed",
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, managerClient *managerclientmocks.MockClientMockRecorder) {
+				gomock.InOrder(
+					dynconfig.Get().Return(nil, errors.New("foo")).Times(1),
+					dynconfig.Register(gomock.Any()).Times(1),
+				)
+			},
+			expect: func(t *testing.T, e *evaluatorML) {
+				assert := assert.New(t)
+				assert.Equal(reflect.TypeOf(e).Elem().Name(), "evaluatorML")
+				assert.Nil(e.model)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			managerClient := managerclientmocks.NewMockClient(ctl)
+			tc.mock(dynconfig.EXPECT(), managerClient.EXPECT())
+			e := NewEvaluatorML(managerClient, dynconfig).(*evaluatorML)
+			<-e.loaded
+			tc.expect(t, e)
+		})
+	}
+}
+
+func TestEvaluatorML_refresh(t *testing.T) {
+	data, err := json.Marshal(mockLinearRegressionModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		mock   func(e *evaluatorML, managerClient *managerclientmocks.MockClientMockRecorder)
+		expect func(t *testing.T, e *evaluatorML)
+	}{
+		{
+			name: "swap model when version is changed",
+			mock: func(e *evaluatorML, managerClient *managerclientmocks.MockClientMockRecorder) {
+				e.versionID = "foo"
+				gomock.InOrder(
+					managerClient.GetModel(gomock.Any(), gomock.Any()).Return(&managerv1.Model{VersionId: "bar"}, nil).Times(1),
+					managerClient.GetModelVersion(gomock.Any(), gomock.Any()).Return(&managerv1.ModelVersion{VersionId: "bar", Data: data}, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, e *evaluatorML) {
+				assert := assert.New(t)
+				assert.Equal(e.versionID, "bar")
+				assert.NotNil(e.model)
+			},
+		},
+		{
+			name: "version is not changed",
+			mock: func(e *evaluatorML, managerClient *managerclientmocks.MockClientMockRecorder) {
+				e.versionID = "foo"
+				managerClient.GetModel(gomock.Any(), gomock.Any()).Return(&managerv1.Model{VersionId: "foo"}, nil).Times(1)
+			},
+			expect: func(t *testing.T, e *evaluatorML) {
+				assert := assert.New(t)
+				assert.Equal(e.versionID, "foo")
+				assert.Nil(e.model)
+			},
+		},
+		{
+			name: "get model version failed",
+			mock: func(e *evaluatorML, managerClient *managerclientmocks.MockClientMockRecorder) {
+				gomock.InOrder(
+					managerClient.GetModel(gomock.Any(), gomock.Any()).Return(&managerv1.Model{VersionId: "bar"}, nil).Times(1),
+					managerClient.GetModelVersion(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, e *evaluatorML) {
+				assert := assert.New(t)
+				assert.Equal(e.versionID, "")
+				assert.Nil(e.model)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			managerClient := managerclientmocks.NewMockClient(ctl)
+			dynconfig.EXPECT().Get().Return(nil, errors.New("foo")).Times(1)
+			dynconfig.EXPECT().Register(gomock.Any()).Times(1)
+
+			e := NewEvaluatorML(managerClient, dynconfig).(*evaluatorML)
+			<-e.loaded
+			tc.mock(e, managerClient.EXPECT())
+			e.refresh(mockDynconfigData)
+			tc.expect(t, e)
+		})
+	}
+}
+
+func TestEvaluatorML_Evaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  Model
+		mock   func(parent *resource.Peer, child *resource.Peer)
+		expect func(t *testing.T, score float64)
+	}{
+		{
+			name:  "model is not loaded",
+			model: nil,
+			mock: func(parent *resource.Peer, child *resource.Peer) {
+				parent.Host.Network.SecurityDomain = "foo"
+				child.Host.Network.SecurityDomain = "foo"
+				parent.FinishedPieces.Set(0)
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0.85))
+			},
+		},
+		{
+			name:  "security domain is not the same",
+			model: mockLinearRegressionModel,
+			mock: func(parent *resource.Peer, child *resource.Peer) {
+				parent.Host.Network.SecurityDomain = "foo"
+				child.Host.Network.SecurityDomain = "bar"
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0))
+			},
+		},
+		{
+			name:  "evaluate with model",
+			model: mockLinearRegressionModel,
+			mock: func(parent *resource.Peer, child *resource.Peer) {
+				parent.Host.Network.SecurityDomain = "foo"
+				child.Host.Network.SecurityDomain = "foo"
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				// Predicted cost is 10 - 1(free upload) - 1(upload success) - 1(seed peer) - 1(idc) - 1(net topology) - 1(location) = 4.
+				assert.Equal(score, float64(1)/5)
+			},
+		},
+		{
+			name: "inference failed",
+			model: &LinearRegressionModel{
+				Type:    LinearRegressionModelType,
+				Weights: []float64{1},
+			},
+			mock: func(parent *resource.Peer, child *resource.Peer) {
+				parent.Host.Network.SecurityDomain = "foo"
+				child.Host.Network.SecurityDomain = "foo"
+				parent.FinishedPieces.Set(0)
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0.85))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := resource.NewPeer(idgen.PeerID("127.0.0.1"),
+				resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit)),
+				resource.NewHost(mockRawSeedHost))
+			child := resource.NewPeer(idgen.PeerID("127.0.0.1"),
+				resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit)),
+				resource.NewHost(mockRawHost))
+			tc.mock(parent, child)
+
+			e := &evaluatorML{base: NewEvaluatorBase().(*evaluatorBase), model: tc.model, mu: &sync.RWMutex{}, refreshMu: &sync.Mutex{}}
+			tc.expect(t, e.Evaluate(parent, child, 1))
+		})
+	}
+}
+
+func TestEvaluatorML_EvaluateParents(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  Model
+		expect func(t *testing.T, parents []*resource.Peer, seedParent *resource.Peer, normalParent *resource.Peer)
+	}{
+		{
+			name:  "model is not loaded",
+			model: nil,
+			expect: func(t *testing.T, parents []*resource.Peer, seedParent *resource.Peer, normalParent *resource.Peer) {
+				assert := assert.New(t)
+				assert.Equal(parents, []*resource.Peer{seedParent, normalParent})
+			},
+		},
+		{
+			name:  "evaluate with model",
+			model: mockLinearRegressionModel,
+			expect: func(t *testing.T, parents []*resource.Peer, seedParent *resource.Peer, normalParent *resource.Peer) {
+				assert := assert.New(t)
+				assert.Equal(parents, []*resource.Peer{seedParent, normalParent})
+			},
+		},
+		{
+			name: "inference failed",
+			model: &LinearRegressionModel{
+				Type:    LinearRegressionModelType,
+				Weights: []float64{1},
+			},
+			expect: func(t *testing.T, parents []*resource.Peer, seedParent *resource.Peer, normalParent *resource.Peer) {
+				assert := assert.New(t)
+				assert.Equal(parents, []*resource.Peer{seedParent, normalParent})
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			seedParent := resource.NewPeer(idgen.PeerID("127.0.0.1"), task, resource.NewHost(mockRawSeedHost))
+			normalParent := resource.NewPeer(idgen.PeerID("127.0.0.1"), task, resource.NewHost(mockRawHost))
+			child := resource.NewPeer(idgen.PeerID("127.0.0.1"), task, resource.NewHost(mockRawHost))
+			seedParent.FinishedPieces.Set(0)
+
+			e := &evaluatorML{base: NewEvaluatorBase().(*evaluatorBase), model: tc.model, mu: &sync.RWMutex{}, refreshMu: &sync.Mutex{}}
+			parents := e.EvaluateParents([]*resource.Peer{normalParent, seedParent}, child, 1)
+			tc.expect(t, parents, seedParent, normalParent)
+		})
+	}
+}
+
+func TestModel_UnmarshalModel(t *testing.T) {
+	data, err := json.Marshal(mockLinearRegressionModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		data   []byte
+		expect func(t *testing.T, m Model, err error)
+	}{
+		{
+			name: "unmarshal linear regression model",
+			data: data,
+			expect: func(t *testing.T, m Model, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.EqualValues(m, mockLinearRegressionModel)
+			},
+		},
+		{
+			name: "unsupported model type",
+			data: []byte(`{"type":"foo"}`),
+			expect: func(t *testing.T, m Model, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "unsupported model type foo")
+			},
+		},
+		{
+			name: "invalid weights",
+			data: []byte(`{"type":"linear_regression","weights":[1]}`),
+			expect: func(t *testing.T, m Model, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid weights length 1, expect 9")
+			},
+		},
+		{
+			name: "invalid data",
+			data: []byte("foo"),
+			expect: func(t *testing.T, m Model, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := UnmarshalModel(tc.data)
+			tc.expect(t, m, err)
+		})
+	}
+}
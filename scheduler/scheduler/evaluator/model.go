@@ -0,0 +1,161 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	// LinearRegressionModelType is the model type of linear regression.
+	LinearRegressionModelType = "linear_regression"
+)
+
+// Features is the input of the machine learning model,
+// it describes the parent that is evaluated for the child.
+type Features struct {
+	// FreeUploadScore is the ratio of the parent host's free upload count.
+	FreeUploadScore float64
+
+	// UploadSuccessScore is the ratio of the parent host's successful upload count.
+	UploadSuccessScore float64
+
+	// IsSeedPeer is 1 if the parent host is a seed peer, otherwise 0.
+	IsSeedPeer float64
+
+	// IDCAffinityScore is the idc affinity between parent host and child host.
+	IDCAffinityScore float64
+
+	// NetTopologyAffinityScore is the net topology affinity between parent host and child host.
+	NetTopologyAffinityScore float64
+
+	// LocationAffinityScore is the location affinity between parent host and child host.
+	LocationAffinityScore float64
+
+	// CPUPercent is the cpu usage of parent host, range is 0.0~1.0.
+	CPUPercent float64
+
+	// MemoryUsedPercent is the memory usage of parent host, range is 0.0~1.0.
+	MemoryUsedPercent float64
+
+	// DiskUsedPercent is the disk usage of parent host, range is 0.0~1.0.
+	DiskUsedPercent float64
+}
+
+// FeatureCount is the dimension of the features vector.
+const FeatureCount = 9
+
+// Vector returns features as the input vector of model,
+// the order of elements must not be changed, otherwise
+// the models that have been trained will be invalid.
+func (f *Features) Vector() []float64 {
+	return []float64{
+		f.FreeUploadScore,
+		f.UploadSuccessScore,
+		f.IsSeedPeer,
+		f.IDCAffinityScore,
+		f.NetTopologyAffinityScore,
+		f.LocationAffinityScore,
+		f.CPUPercent,
+		f.MemoryUsedPercent,
+		f.DiskUsedPercent,
+	}
+}
+
+// Model is the machine learning model used by evaluator,
+// it predicts the piece download cost(millisecond) from parent.
+type Model interface {
+	// Predict returns the predicted piece download cost.
+	Predict(*Features) (float64, error)
+}
+
+// LinearRegressionModel is the model of linear regression,
+// features are standardized by mean and standard deviation before prediction.
+type LinearRegressionModel struct {
+	// Type is the model type.
+	Type string `json:"type"`
+
+	// Weights is the weight of every feature.
+	Weights []float64 `json:"weights"`
+
+	// Bias is the intercept of model.
+	Bias float64 `json:"bias"`
+
+	// Mean is the mean of every feature in training set.
+	Mean []float64 `json:"mean"`
+
+	// StandardDeviation is the standard deviation of every feature in training set.
+	StandardDeviation []float64 `json:"standardDeviation"`
+}
+
+// Predict returns the predicted piece download cost.
+func (m *LinearRegressionModel) Predict(features *Features) (float64, error) {
+	x := features.Vector()
+	if len(m.Weights) != len(x) {
+		return 0, fmt.Errorf("invalid weights length %d, expect %d", len(m.Weights), len(x))
+	}
+
+	if len(m.Mean) != len(x) || len(m.StandardDeviation) != len(x) {
+		return 0, errors.New("invalid standardization parameters")
+	}
+
+	y := m.Bias
+	for i, v := range x {
+		if m.StandardDeviation[i] > 0 {
+			v = (v - m.Mean[i]) / m.StandardDeviation[i]
+		} else {
+			v = v - m.Mean[i]
+		}
+
+		y += m.Weights[i] * v
+	}
+
+	if math.IsNaN(y) || math.IsInf(y, 0) {
+		return 0, errors.New("invalid prediction")
+	}
+
+	return y, nil
+}
+
+// UnmarshalModel parses the data of model version to model.
+func UnmarshalModel(data []byte) (Model, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	switch header.Type {
+	case LinearRegressionModelType:
+		var model LinearRegressionModel
+		if err := json.Unmarshal(data, &model); err != nil {
+			return nil, err
+		}
+
+		if len(model.Weights) != FeatureCount {
+			return nil, fmt.Errorf("invalid weights length %d, expect %d", len(model.Weights), FeatureCount)
+		}
+
+		return &model, nil
+	default:
+		return nil, fmt.Errorf("unsupported model type %s", header.Type)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 
 	"d7y.io/dragonfly/v2/internal/dfplugin"
+	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
 const (
@@ -35,5 +36,26 @@ func LoadPlugin(dir string) (Evaluator, error) {
 	if rc, ok := client.(Evaluator); ok {
 		return rc, err
 	}
+
+	// The plugin which does not implement EvaluateParents sorts the parents by Evaluate.
+	if rc, ok := client.(pluginEvaluator); ok {
+		return &evaluatorPlugin{rc}, err
+	}
 	return nil, errors.New("invalid evaluator plugin")
 }
+
+// pluginEvaluator is the evaluator plugin evaluates parent one by one.
+type pluginEvaluator interface {
+	Evaluate(parent *resource.Peer, child *resource.Peer, taskPieceCount int32) float64
+	IsBadNode(peer *resource.Peer) bool
+}
+
+// evaluatorPlugin sorts the parents by Evaluate of plugin.
+type evaluatorPlugin struct {
+	pluginEvaluator
+}
+
+// EvaluateParents sorts the parents by evaluation score in descending order.
+func (e *evaluatorPlugin) EvaluateParents(parents []*resource.Peer, child *resource.Peer, taskPieceCount int32) []*resource.Peer {
+	return evaluateParents(e.Evaluate, parents, child, taskPieceCount)
+}
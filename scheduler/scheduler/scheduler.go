@@ -20,13 +20,14 @@ package scheduler
 
 import (
 	"context"
-	"sort"
 	"time"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/pkg/container/set"
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	"d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource"
@@ -53,18 +54,52 @@ type scheduler struct {
 
 	// Scheduler dynamic configuration.
 	dynconfig config.DynconfigInterface
+
+	// Manager client.
+	managerClient managerclient.Client
+}
+
+// Option is a functional option for configuring the scheduler.
+type Option func(s *scheduler)
+
+// WithManagerClient sets the manager client used by evaluator.
+func WithManagerClient(client managerclient.Client) Option {
+	return func(s *scheduler) {
+		s.managerClient = client
+	}
 }
 
-func New(cfg *config.SchedulerConfig, dynconfig config.DynconfigInterface, pluginDir string) Scheduler {
-	return &scheduler{
-		evaluator: evaluator.New(cfg.Algorithm, pluginDir),
+func New(cfg *config.SchedulerConfig, dynconfig config.DynconfigInterface, pluginDir string, options ...Option) Scheduler {
+	s := &scheduler{
 		config:    cfg,
 		dynconfig: dynconfig,
 	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	evaluatorOptions := []evaluator.Option{
+		evaluator.WithDynconfig(dynconfig),
+		evaluator.WithEvaluatorConfig(cfg.Evaluator),
+	}
+	if s.managerClient != nil {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithManagerClient(s.managerClient))
+	}
+
+	s.evaluator = evaluator.New(cfg.Algorithm, pluginDir, evaluatorOptions...)
+	return s
 }
 
 // ScheduleParent schedule a parent and candidates to a peer.
 func (s *scheduler) ScheduleParent(ctx context.Context, peer *resource.Peer, blocklist set.SafeSet[string]) {
+	// The normal peer is waiting for parents during scheduling, the lower priority
+	// peers yield back-to-source to it.
+	if peer.Host.Type == types.HostTypeNormal {
+		peer.Task.AddWaitingPeer(peer.Priority)
+		defer peer.Task.DeleteWaitingPeer(peer.Priority)
+	}
+
 	var n int
 	for {
 		select {
@@ -78,7 +113,7 @@ func (s *scheduler) ScheduleParent(ctx context.Context, peer *resource.Peer, blo
 		// peer will download the task back-to-source.
 		needBackToSource := peer.NeedBackToSource.Load()
 		if (n >= s.config.RetryBackToSourceLimit || needBackToSource) &&
-			peer.Task.CanBackToSource() {
+			peer.Task.CanBackToSource() && s.canBackToSourceByPriority(peer) {
 			stream, ok := peer.LoadStream()
 			if !ok {
 				peer.Log.Error("load stream failed")
@@ -167,12 +202,7 @@ func (s *scheduler) NotifyAndFindParent(ctx context.Context, peer *resource.Peer
 
 	// Sort candidate parents by evaluation score.
 	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
-	sort.Slice(
-		candidateParents,
-		func(i, j int) bool {
-			return s.evaluator.Evaluate(candidateParents[i], peer, taskTotalPieceCount) > s.evaluator.Evaluate(candidateParents[j], peer, taskTotalPieceCount)
-		},
-	)
+	candidateParents = s.evaluator.EvaluateParents(candidateParents, peer, taskTotalPieceCount)
 
 	// Add edges between candidate parent and peer.
 	var (
@@ -180,10 +210,31 @@ func (s *scheduler) NotifyAndFindParent(ctx context.Context, peer *resource.Peer
 		parentIDs []string
 	)
 	for _, candidateParent := range candidateParents {
+		// If the free upload of candidate parent is empty, the lower priority child
+		// is moved to other parents only when the candidate parent is selected as
+		// the main parent, the other candidate parents without free upload are skipped.
+		var preemptible *resource.Peer
+		if candidateParent.Host.FreeUploadCount() <= 0 {
+			if len(parents) > 0 {
+				continue
+			}
+
+			child, ok := s.findPreemptibleChild(candidateParent, peer)
+			if !ok {
+				continue
+			}
+			preemptible = child
+		}
+
 		if err := peer.Task.AddPeerEdge(candidateParent, peer); err != nil {
 			peer.Log.Debugf("peer adds edge failed: %s", err.Error())
 			continue
 		}
+
+		if preemptible != nil {
+			s.preemptChild(candidateParent, preemptible)
+		}
+
 		parents = append(parents, candidateParent)
 		parentIDs = append(parentIDs, candidateParent.ID)
 	}
@@ -221,12 +272,7 @@ func (s *scheduler) FindParent(ctx context.Context, peer *resource.Peer, blockli
 
 	// Sort candidate parents by evaluation score.
 	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
-	sort.Slice(
-		candidateParents,
-		func(i, j int) bool {
-			return s.evaluator.Evaluate(candidateParents[i], peer, taskTotalPieceCount) > s.evaluator.Evaluate(candidateParents[j], peer, taskTotalPieceCount)
-		},
-	)
+	candidateParents = s.evaluator.EvaluateParents(candidateParents, peer, taskTotalPieceCount)
 
 	peer.Log.Infof("find parent %s successful", candidateParents[0].ID)
 	return candidateParents[0], true
@@ -302,11 +348,14 @@ func (s *scheduler) filterCandidateParents(peer *resource.Peer, blocklist set.Sa
 			continue
 		}
 
-		// Candidate parent's free upload is empty.
+		// Candidate parent's free upload is empty and
+		// there is no lower priority child can be preempted.
 		if candidateParent.Host.FreeUploadCount() <= 0 {
-			peer.Log.Debugf("candidate parent %s is not selected because its free upload is empty, upload limit is %d, upload count is %d",
-				candidateParent.ID, candidateParent.Host.ConcurrentUploadLimit.Load(), candidateParent.Host.ConcurrentUploadCount.Load())
-			continue
+			if _, ok := s.findPreemptibleChild(candidateParent, peer); !ok {
+				peer.Log.Debugf("candidate parent %s is not selected because its free upload is empty, upload limit is %d, upload count is %d",
+					candidateParent.ID, candidateParent.Host.ConcurrentUploadLimit.Load(), candidateParent.Host.ConcurrentUploadCount.Load())
+				continue
+			}
 		}
 
 		candidateParents = append(candidateParents, candidateParent)
@@ -317,6 +366,80 @@ func (s *scheduler) filterCandidateParents(peer *resource.Peer, blocklist set.Sa
 	return candidateParents
 }
 
+// canBackToSourceByPriority returns whether peer can back-to-source by its priority.
+// Level1 peer is not allowed to back-to-source, and when the free back-to-source count
+// of task is not enough, peer yields it to the higher priority peers which are waiting for parents.
+func (s *scheduler) canBackToSourceByPriority(peer *resource.Peer) bool {
+	if peer.Priority == managerv1.Priority_Level1 {
+		peer.Log.Infof("peer can not back-to-source, because of priority is %s", peer.Priority.String())
+		return false
+	}
+
+	waitingCount := peer.Task.HigherPriorityWaitingPeerCount(peer.Priority)
+	freeCount := int(peer.Task.BackToSourceLimit.Load()) - int(peer.Task.BackToSourcePeers.Len())
+	if waitingCount > 0 && freeCount <= waitingCount {
+		peer.Log.Infof("peer yields back-to-source to %d higher priority peers, free back-to-source count is %d", waitingCount, freeCount)
+		return false
+	}
+
+	return true
+}
+
+// findPreemptibleChild finds the lowest priority child of parent,
+// whose priority is lower than the peer's priority.
+func (s *scheduler) findPreemptibleChild(parent *resource.Peer, peer *resource.Peer) (*resource.Peer, bool) {
+	var children []*resource.Peer
+	for _, child := range parent.Children() {
+		if child.ID != peer.ID && child.FSM.Is(resource.PeerStateRunning) {
+			children = append(children, child)
+		}
+	}
+
+	if len(children) == 0 {
+		return nil, false
+	}
+
+	var (
+		priority            = peer.Priority
+		preemptible         *resource.Peer
+		preemptiblePriority managerv1.Priority
+	)
+	for _, child := range children {
+		childPriority := child.Priority
+		if childPriority >= priority {
+			continue
+		}
+
+		if preemptible == nil || childPriority < preemptiblePriority {
+			preemptible = child
+			preemptiblePriority = childPriority
+		}
+	}
+
+	return preemptible, preemptible != nil
+}
+
+// preemptChild deletes the edge between parent and child to release the upload of parent,
+// and the child is rescheduled to other parents. The rescheduling is bound to the stream
+// of child, it stops when the child leaves or the scheduler stops serving.
+func (s *scheduler) preemptChild(parent *resource.Peer, child *resource.Peer) {
+	if err := child.Task.DeletePeerEdge(parent, child); err != nil {
+		child.Log.Errorf("child deletes edge with parent %s failed: %s", parent.ID, err.Error())
+		return
+	}
+
+	stream, ok := child.LoadStream()
+	if !ok {
+		child.Log.Warnf("parent %s is preempted by the higher priority peer, child stream is not found", parent.ID)
+		return
+	}
+
+	child.Log.Infof("parent %s is preempted by the higher priority peer, reschedule child", parent.ID)
+	blocklist := set.NewSafeSet[string]()
+	blocklist.Add(parent.ID)
+	go s.ScheduleParent(stream.Context(), child, blocklist)
+}
+
 // Construct peer successful packet.
 func constructSuccessPeerPacket(dynconfig config.DynconfigInterface, peer *resource.Peer, parent *resource.Peer, candidateParents []*resource.Peer) *schedulerv1.PeerPacket {
 	parallelCount := config.DefaultPeerParallelCount
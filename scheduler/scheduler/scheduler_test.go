@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 	"d7y.io/api/pkg/apis/scheduler/v1/mocks"
 
@@ -50,6 +52,15 @@ var (
 		RetryInterval:          10 * time.Millisecond,
 		BackToSourceCount:      int(mockTaskBackToSourceLimit),
 		Algorithm:              evaluator.DefaultAlgorithm,
+		Evaluator: config.EvaluatorConfig{
+			FinishedPieceWeight:           config.DefaultEvaluatorFinishedPieceWeight,
+			ParentHostUploadSuccessWeight: config.DefaultEvaluatorParentHostUploadSuccessWeight,
+			FreeUploadWeight:              config.DefaultEvaluatorFreeUploadWeight,
+			HostTypeWeight:                config.DefaultEvaluatorHostTypeWeight,
+			IDCAffinityWeight:             config.DefaultEvaluatorIDCAffinityWeight,
+			NetTopologyAffinityWeight:     config.DefaultEvaluatorNetTopologyAffinityWeight,
+			LocationAffinityWeight:        config.DefaultEvaluatorLocationAffinityWeight,
+		},
 	}
 
 	mockRawHost = &schedulerv1.AnnounceHostRequest{
@@ -128,6 +139,7 @@ func TestScheduler_New(t *testing.T) {
 			ctl := gomock.NewController(t)
 			defer ctl.Finish()
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			dynconfig.EXPECT().Register(gomock.Any()).Times(1)
 
 			tc.expect(t, New(mockSchedulerConfig, dynconfig, tc.pluginDir))
 		})
@@ -218,6 +230,64 @@ func TestScheduler_ScheduleParent(t *testing.T) {
 				assert.True(peer.Task.FSM.Is(resource.TaskStateRunning))
 			},
 		},
+		{
+			name: "peer needs back-to-source and priority is Level1",
+			mock: func(cancel context.CancelFunc, peer *resource.Peer, seedPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, mr *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				task := peer.Task
+				task.StorePeer(peer)
+				peer.Priority = managerv1.Priority_Level1
+				peer.NeedBackToSource.Store(true)
+				peer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.BackToSourceLimit.Store(1)
+				peer.StoreStream(stream)
+
+				gomock.InOrder(
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1),
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1),
+					mr.Send(gomock.Eq(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedTaskStatusError})).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, peer *resource.Peer) {
+				assert := assert.New(t)
+				assert.Equal(len(peer.Parents()), 0)
+				assert.True(peer.FSM.Is(resource.PeerStateRunning))
+			},
+		},
+		{
+			name: "peer needs back-to-source and yields to higher priority peer",
+			mock: func(cancel context.CancelFunc, peer *resource.Peer, seedPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, mr *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				task := peer.Task
+				task.StorePeer(peer)
+				task.StorePeer(seedPeer)
+				peer.Priority = managerv1.Priority_Level2
+				peer.NeedBackToSource.Store(true)
+				peer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.BackToSourceLimit.Store(1)
+				peer.StoreStream(stream)
+
+				// The higher priority peer is waiting for parents.
+				mockHost := resource.NewHost(mockRawHost)
+				mockHost.ID = idgen.HostID(uuid.New().String(), 8003)
+				waitingPeer := resource.NewPeer(idgen.PeerID("127.0.0.1"), task, mockHost, resource.WithPriority(managerv1.Priority_Level5))
+				waitingPeer.FSM.SetState(resource.PeerStateRunning)
+				task.StorePeer(waitingPeer)
+				task.AddWaitingPeer(waitingPeer.Priority)
+
+				seedPeer.FSM.SetState(resource.PeerStateRunning)
+				gomock.InOrder(
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1),
+					md.GetSchedulerClusterClientConfig().Return(types.SchedulerClusterClientConfig{
+						ParallelCount: 2,
+					}, nil).Times(1),
+					mr.Send(gomock.Any()).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, peer *resource.Peer) {
+				assert := assert.New(t)
+				assert.Equal(len(peer.Parents()), 1)
+				assert.True(peer.FSM.Is(resource.PeerStateRunning))
+			},
+		},
 		{
 			name: "schedule exceeds RetryBackToSourceLimit and peer stream load failed",
 			mock: func(cancel context.CancelFunc, peer *resource.Peer, seedPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, mr *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
@@ -317,6 +387,7 @@ func TestScheduler_ScheduleParent(t *testing.T) {
 			defer ctl.Finish()
 			stream := mocks.NewMockScheduler_ReportPieceResultServer(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			dynconfig.EXPECT().Register(gomock.Any()).Times(1)
 			ctx, cancel := context.WithCancel(context.Background())
 			mockHost := resource.NewHost(mockRawHost)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
@@ -513,6 +584,114 @@ func TestScheduler_NotifyAndFindParent(t *testing.T) {
 				assert.False(ok)
 			},
 		},
+		{
+			name: "parent free upload load is zero and lower priority child is preempted",
+			mock: func(peer *resource.Peer, mockTask *resource.Task, mockPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, dynconfig config.DynconfigInterface, ms *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.Priority = managerv1.Priority_Level5
+				peer.FSM.SetState(resource.PeerStateRunning)
+				mockPeer.FSM.SetState(resource.PeerStateRunning)
+				mockPeer.IsBackToSource.Store(true)
+				peer.Task.BackToSourcePeers.Add(mockPeer.ID)
+				mockPeer.Host.ConcurrentUploadLimit.Store(1)
+				childPeer := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, peer.Host, resource.WithPriority(managerv1.Priority_Level2))
+				childPeer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(mockPeer)
+				peer.Task.StorePeer(childPeer)
+				if err := peer.Task.AddPeerEdge(mockPeer, childPeer); err != nil {
+					t.Fatal(err)
+				}
+				peer.StoreStream(stream)
+				childStream := mocks.NewMockScheduler_ReportPieceResultServer(gomock.NewController(t))
+				childPeer.StoreStream(childStream)
+
+				var wg sync.WaitGroup
+				wg.Add(1)
+
+				gomock.InOrder(
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1),
+					md.GetSchedulerClusterClientConfig().Return(types.SchedulerClusterClientConfig{
+						ParallelCount: 2,
+					}, nil).Times(1),
+					ms.Send(gomock.Any()).Do(func(*schedulerv1.PeerPacket) { wg.Wait() }).Return(nil).Times(1),
+				)
+
+				// Preempted child is rescheduled with the context of its stream.
+				childStream.EXPECT().Context().Return(context.Background()).Times(1)
+				childStream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+				md.GetSchedulerClusterConfig().Do(func() { wg.Done() }).Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, parents []*resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(len(parents), 1)
+				assert.Equal(parents[0].Host.ConcurrentUploadCount.Load(), int32(1))
+				assert.Equal(len(peer.Parents()), 1)
+			},
+		},
+		{
+			name: "parents free upload load are zero and only child of selected parent is preempted",
+			mock: func(peer *resource.Peer, mockTask *resource.Task, mockPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, dynconfig config.DynconfigInterface, ms *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.Priority = managerv1.Priority_Level5
+				peer.FSM.SetState(resource.PeerStateRunning)
+				mockPeer.FSM.SetState(resource.PeerStateRunning)
+				mockPeer.IsBackToSource.Store(true)
+				peer.Task.BackToSourcePeers.Add(mockPeer.ID)
+				mockPeer.Host.ConcurrentUploadLimit.Store(1)
+
+				otherHost := resource.NewHost(mockRawHost)
+				otherHost.ID = idgen.HostID(uuid.New().String(), 8003)
+				otherHost.ConcurrentUploadLimit.Store(1)
+				otherPeer := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, otherHost)
+				otherPeer.FSM.SetState(resource.PeerStateRunning)
+				otherPeer.IsBackToSource.Store(true)
+				peer.Task.BackToSourcePeers.Add(otherPeer.ID)
+
+				childPeer := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, peer.Host, resource.WithPriority(managerv1.Priority_Level2))
+				childPeer.FSM.SetState(resource.PeerStateRunning)
+				otherChildPeer := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, peer.Host, resource.WithPriority(managerv1.Priority_Level2))
+				otherChildPeer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(mockPeer)
+				peer.Task.StorePeer(otherPeer)
+				peer.Task.StorePeer(childPeer)
+				peer.Task.StorePeer(otherChildPeer)
+				if err := peer.Task.AddPeerEdge(mockPeer, childPeer); err != nil {
+					t.Fatal(err)
+				}
+				if err := peer.Task.AddPeerEdge(otherPeer, otherChildPeer); err != nil {
+					t.Fatal(err)
+				}
+				peer.StoreStream(stream)
+				childStream := mocks.NewMockScheduler_ReportPieceResultServer(gomock.NewController(t))
+				childPeer.StoreStream(childStream)
+				otherChildPeer.StoreStream(childStream)
+
+				var wg sync.WaitGroup
+				wg.Add(1)
+
+				gomock.InOrder(
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1),
+					md.GetSchedulerClusterClientConfig().Return(types.SchedulerClusterClientConfig{
+						ParallelCount: 2,
+					}, nil).Times(1),
+					ms.Send(gomock.Any()).Do(func(*schedulerv1.PeerPacket) { wg.Wait() }).Return(nil).Times(1),
+				)
+
+				// Only the preempted child of selected parent is rescheduled with the context of its stream.
+				childStream.EXPECT().Context().Return(context.Background()).Times(1)
+				childStream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+				md.GetSchedulerClusterConfig().Do(func() { wg.Done() }).Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, parents []*resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(len(parents), 2)
+				assert.Equal(parents[0].Host.ConcurrentUploadCount.Load(), int32(1))
+				assert.Equal(parents[1].Host.ConcurrentUploadCount.Load(), int32(1))
+				assert.Equal(len(peer.Parents()), 1)
+			},
+		},
 		{
 			name: "peer stream is empty",
 			mock: func(peer *resource.Peer, mockTask *resource.Task, mockPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, dynconfig config.DynconfigInterface, ms *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
@@ -603,6 +782,7 @@ func TestScheduler_NotifyAndFindParent(t *testing.T) {
 			defer ctl.Finish()
 			stream := mocks.NewMockScheduler_ReportPieceResultServer(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			dynconfig.EXPECT().Register(gomock.Any()).Times(1)
 			mockHost := resource.NewHost(mockRawHost)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
 			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
@@ -882,6 +1062,7 @@ func TestScheduler_FindParent(t *testing.T) {
 			ctl := gomock.NewController(t)
 			defer ctl.Finish()
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			dynconfig.EXPECT().Register(gomock.Any()).Times(1)
 			mockHost := resource.NewHost(mockRawHost)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
 			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
@@ -0,0 +1,138 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+)
+
+// seedPeerLimiter limits the concurrent seed peer triggers, the waiting triggers are
+// started in order of priority, and the triggers with the same priority are first come
+// first served. Level5 triggers are started immediately without waiting.
+type seedPeerLimiter struct {
+	// limit is the limit of concurrent triggers, zero is unlimited.
+	limit int
+
+	// running is the count of running triggers.
+	running int
+
+	// waiters is the priority queue of waiting triggers.
+	waiters seedPeerWaiters
+
+	// seq is the sequence of waiting triggers.
+	seq uint64
+
+	// mu is used to protect running, waiters and seq.
+	mu sync.Mutex
+}
+
+// newSeedPeerLimiter returns a new seed peer limiter.
+func newSeedPeerLimiter(limit int) *seedPeerLimiter {
+	return &seedPeerLimiter{limit: limit}
+}
+
+// Acquire waits until the trigger with priority can be started, or the context is done.
+func (l *seedPeerLimiter) Acquire(ctx context.Context, priority managerv1.Priority) error {
+	l.mu.Lock()
+	if l.limit <= 0 || priority >= managerv1.Priority_Level5 || (l.running < l.limit && l.waiters.Len() == 0) {
+		l.running++
+		l.mu.Unlock()
+		return nil
+	}
+
+	l.seq++
+	w := &seedPeerWaiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		if w.index >= 0 {
+			heap.Remove(&l.waiters, w.index)
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+		l.mu.Unlock()
+
+		// The trigger has been started before the context is done.
+		l.Release()
+		return ctx.Err()
+	}
+}
+
+// Release finishes a running trigger and starts the highest priority waiting trigger.
+func (l *seedPeerLimiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.running--
+	for l.running < l.limit && l.waiters.Len() > 0 {
+		w := heap.Pop(&l.waiters).(*seedPeerWaiter)
+		l.running++
+		close(w.ready)
+	}
+}
+
+// seedPeerWaiter is the waiting seed peer trigger.
+type seedPeerWaiter struct {
+	priority managerv1.Priority
+	seq      uint64
+	index    int
+	ready    chan struct{}
+}
+
+// seedPeerWaiters is the heap of waiting triggers, the higher priority is popped first.
+type seedPeerWaiters []*seedPeerWaiter
+
+func (w seedPeerWaiters) Len() int { return len(w) }
+
+func (w seedPeerWaiters) Less(i, j int) bool {
+	if w[i].priority != w[j].priority {
+		return w[i].priority > w[j].priority
+	}
+
+	return w[i].seq < w[j].seq
+}
+
+func (w seedPeerWaiters) Swap(i, j int) {
+	w[i], w[j] = w[j], w[i]
+	w[i].index = i
+	w[j].index = j
+}
+
+func (w *seedPeerWaiters) Push(x any) {
+	waiter := x.(*seedPeerWaiter)
+	waiter.index = len(*w)
+	*w = append(*w, waiter)
+}
+
+func (w *seedPeerWaiters) Pop() any {
+	old := *w
+	n := len(old)
+	waiter := old[n-1]
+	old[n-1] = nil
+	waiter.index = -1
+	*w = old[:n-1]
+	return waiter
+}
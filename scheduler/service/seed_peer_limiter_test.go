@@ -0,0 +1,100 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+)
+
+func TestSeedPeerLimiter_Acquire(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		run   func(t *testing.T, l *seedPeerLimiter)
+	}{
+		{
+			name:  "limiter is unlimited",
+			limit: 0,
+			run: func(t *testing.T, l *seedPeerLimiter) {
+				assert := assert.New(t)
+				for i := 0; i < 10; i++ {
+					assert.NoError(l.Acquire(context.Background(), managerv1.Priority_Level3))
+				}
+			},
+		},
+		{
+			name:  "Level5 trigger is not limited but Level3 trigger waits",
+			limit: 1,
+			run: func(t *testing.T, l *seedPeerLimiter) {
+				assert := assert.New(t)
+				assert.NoError(l.Acquire(context.Background(), managerv1.Priority_Level3))
+				assert.NoError(l.Acquire(context.Background(), managerv1.Priority_Level5))
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				assert.ErrorIs(l.Acquire(ctx, managerv1.Priority_Level3), context.DeadlineExceeded)
+				assert.Equal(l.waiters.Len(), 0)
+			},
+		},
+		{
+			name:  "waiting Level4 trigger starts before waiting Level3 trigger",
+			limit: 1,
+			run: func(t *testing.T, l *seedPeerLimiter) {
+				assert := assert.New(t)
+				assert.NoError(l.Acquire(context.Background(), managerv1.Priority_Level3))
+
+				started := make(chan managerv1.Priority, 2)
+				acquire := func(priority managerv1.Priority) {
+					if err := l.Acquire(context.Background(), priority); err == nil {
+						started <- priority
+					}
+				}
+
+				go acquire(managerv1.Priority_Level3)
+				assert.Eventually(func() bool {
+					l.mu.Lock()
+					defer l.mu.Unlock()
+					return l.waiters.Len() == 1
+				}, time.Second, time.Millisecond)
+
+				go acquire(managerv1.Priority_Level4)
+				assert.Eventually(func() bool {
+					l.mu.Lock()
+					defer l.mu.Unlock()
+					return l.waiters.Len() == 2
+				}, time.Second, time.Millisecond)
+
+				l.Release()
+				assert.Equal(<-started, managerv1.Priority_Level4)
+				l.Release()
+				assert.Equal(<-started, managerv1.Priority_Level3)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newSeedPeerLimiter(tc.limit))
+		})
+	}
+}
@@ -25,10 +25,13 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	errordetailsv1 "d7y.io/api/pkg/apis/errordetails/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/internal/dferrors"
@@ -37,6 +40,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/rpc/common"
 	pkgtime "d7y.io/dragonfly/v2/pkg/time"
 	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/cluster"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/resource"
@@ -59,6 +63,26 @@ type Service struct {
 
 	// Storage interface.
 	storage storage.Storage
+
+	// Cluster interface shares task state with the other schedulers.
+	cluster cluster.Cluster
+
+	// seedPeerLimiter limits the concurrent seed peer triggers by priority.
+	seedPeerLimiter *seedPeerLimiter
+
+	// clusterSyncs shares the syncing of task from cluster between the concurrent registrations.
+	clusterSyncs singleflight.Group
+}
+
+// Option is a functional option for configuring the service.
+type Option func(s *Service)
+
+// WithCluster returns an Option which queries the peers of the unknown task
+// from the other schedulers in the same scheduler cluster.
+func WithCluster(cluster cluster.Cluster) Option {
+	return func(s *Service) {
+		s.cluster = cluster
+	}
 }
 
 // New service instance.
@@ -68,25 +92,40 @@ func New(
 	scheduler scheduler.Scheduler,
 	dynconfig config.DynconfigInterface,
 	storage storage.Storage,
+	options ...Option,
 ) *Service {
+	s := &Service{
+		resource:        resource,
+		scheduler:       scheduler,
+		config:          cfg,
+		dynconfig:       dynconfig,
+		storage:         storage,
+		seedPeerLimiter: newSeedPeerLimiter(cfg.SeedPeer.TriggerLimit),
+	}
 
-	return &Service{
-		resource:  resource,
-		scheduler: scheduler,
-		config:    cfg,
-		dynconfig: dynconfig,
-		storage:   storage,
+	for _, opt := range options {
+		opt(s)
 	}
+
+	return s
 }
 
 // RegisterPeerTask registers peer and triggers seed peer download task.
 func (s *Service) RegisterPeerTask(ctx context.Context, req *schedulerv1.PeerTaskRequest) (*schedulerv1.RegisterResult, error) {
 	logger.WithPeer(req.PeerHost.Id, req.TaskId, req.PeerId).Infof("register peer task request: %#v %#v %#v",
 		req, req.UrlMeta, req.HostLoad)
+	// Download task is forbidden when the priority is Level0.
+	priority := resource.CalculatePriority(s.dynconfig, req.UrlMeta.Application, req.Url)
+	if priority == managerv1.Priority_Level0 {
+		msg := fmt.Sprintf("download is forbidden, because of application %s priority is %s", req.UrlMeta.Application, priority.String())
+		logger.WithPeer(req.PeerHost.Id, req.TaskId, req.PeerId).Error(msg)
+		return nil, dferrors.New(commonv1.Code_SchedForbidden, msg)
+	}
+
 	// Register task and trigger seed peer download task.
-	task, needBackToSource := s.registerTask(ctx, req)
+	task, needBackToSource := s.registerTask(ctx, req, priority)
 	host := s.registerHost(ctx, req.PeerHost)
-	peer := s.registerPeer(ctx, req.PeerId, task, host, req.UrlMeta.Tag, req.UrlMeta.Application)
+	peer := s.registerPeer(ctx, req.PeerId, task, host, req.UrlMeta.Tag, req.UrlMeta.Application, priority)
 
 	// When the peer registers for the first time and
 	// does not have a seed peer, it will back-to-source.
@@ -315,7 +354,9 @@ func (s *Service) AnnounceTask(ctx context.Context, req *schedulerv1.AnnounceTas
 	task := resource.NewTask(taskID, req.Url, req.TaskType, req.UrlMeta)
 	task, _ = s.resource.TaskManager().LoadOrStore(task)
 	host := s.registerHost(ctx, req.PeerHost)
-	peer := s.registerPeer(ctx, peerID, task, host, req.UrlMeta.Tag, req.UrlMeta.Application)
+	// The announced peer has completed task, it does not wait for parents,
+	// so the priority is not calculated.
+	peer := s.registerPeer(ctx, peerID, task, host, req.UrlMeta.Tag, req.UrlMeta.Application, resource.DefaultPriority)
 	peer.Log.Infof("announce peer task request: %#v %#v %#v %#v", req, req.UrlMeta, req.PeerHost, req.PiecePacket)
 
 	// If the task state is not TaskStateSucceeded,
@@ -398,6 +439,73 @@ func (s *Service) StatTask(ctx context.Context, req *schedulerv1.StatTaskRequest
 	}, nil
 }
 
+// ListTaskPeers lists the succeeded peers of the task as announce task requests,
+// the other schedulers in the same scheduler cluster rebuild the task state by them.
+func (s *Service) ListTaskPeers(ctx context.Context, req *schedulerv1.StatTaskRequest) ([]*schedulerv1.AnnounceTaskRequest, error) {
+	task, loaded := s.resource.TaskManager().Load(req.TaskId)
+	if !loaded {
+		msg := fmt.Sprintf("task %s not found", req.TaskId)
+		logger.Info(msg)
+		return nil, dferrors.New(commonv1.Code_PeerTaskNotFound, msg)
+	}
+
+	var reqs []*schedulerv1.AnnounceTaskRequest
+	for _, vertex := range task.DAG.GetVertices() {
+		peer := vertex.Value
+		if peer == nil || !peer.FSM.Is(resource.PeerStateSucceeded) {
+			continue
+		}
+
+		urlMeta := &commonv1.UrlMeta{}
+		if task.URLMeta != nil {
+			urlMeta = proto.Clone(task.URLMeta).(*commonv1.UrlMeta)
+		}
+		urlMeta.Tag = peer.Tag
+		urlMeta.Application = peer.Application
+
+		var pieceInfos []*commonv1.PieceInfo
+		for pieceNum, ok := peer.FinishedPieces.NextSet(0); ok; pieceNum, ok = peer.FinishedPieces.NextSet(pieceNum + 1) {
+			if pieceInfo, loaded := task.LoadPiece(int32(pieceNum)); loaded {
+				pieceInfos = append(pieceInfos, pieceInfo)
+			}
+		}
+
+		var securityDomain, location, idc, netTopology string
+		if network := peer.Host.Network; network != nil {
+			securityDomain, location, idc, netTopology = network.SecurityDomain, network.Location, network.Idc, network.NetTopology
+		}
+
+		reqs = append(reqs, &schedulerv1.AnnounceTaskRequest{
+			TaskId:   task.ID,
+			Url:      task.URL,
+			UrlMeta:  urlMeta,
+			TaskType: task.Type,
+			PeerHost: &schedulerv1.PeerHost{
+				Id:             peer.Host.ID,
+				Ip:             peer.Host.IP,
+				RpcPort:        peer.Host.Port,
+				DownPort:       peer.Host.DownloadPort,
+				HostName:       peer.Host.Hostname,
+				SecurityDomain: securityDomain,
+				Location:       location,
+				Idc:            idc,
+				NetTopology:    netTopology,
+			},
+			PiecePacket: &commonv1.PiecePacket{
+				TaskId:        task.ID,
+				DstPid:        peer.ID,
+				DstAddr:       fmt.Sprintf("%s:%d", peer.Host.IP, peer.Host.DownloadPort),
+				PieceInfos:    pieceInfos,
+				TotalPiece:    task.TotalPieceCount.Load(),
+				ContentLength: task.ContentLength.Load(),
+			},
+		})
+	}
+
+	task.Log.Debugf("list %d succeeded peers of task", len(reqs))
+	return reqs, nil
+}
+
 // LeaveTask releases peer in scheduler.
 func (s *Service) LeaveTask(ctx context.Context, req *schedulerv1.PeerTarget) error {
 	peer, loaded := s.resource.PeerManager().Load(req.PeerId)
@@ -479,7 +587,7 @@ func (s *Service) LeaveHost(ctx context.Context, req *schedulerv1.LeaveHostReque
 }
 
 // registerTask creates a new task or reuses a previous task.
-func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskRequest) (*resource.Task, bool) {
+func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskRequest, priority managerv1.Priority) (*resource.Task, bool) {
 	task, loaded := s.resource.TaskManager().Load(req.TaskId)
 	if loaded {
 		// Task is the pointer, if the task already exists, the next request will
@@ -497,6 +605,15 @@ func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskReq
 		s.resource.TaskManager().Store(task)
 	}
 
+	// The pending task without available peer may have been downloaded by the peers of
+	// the other schedulers, such as the scheduler which the task belonged to has left.
+	// The peers are synced before back-to-source or triggering seed peer, the concurrent
+	// registrations of the task wait for the same syncing.
+	if s.cluster != nil && task.FSM.Is(resource.TaskStatePending) && s.syncTaskFromClusterOnce(task) {
+		task.Log.Info("task dose not need to back-to-source, because of task has available peer in cluster")
+		return task, false
+	}
+
 	// If the task triggers the TaskEventDownload failed and it has no available peer,
 	// let the peer do the scheduling.
 	if err := task.FSM.Event(resource.TaskEventDownload); err != nil {
@@ -511,6 +628,20 @@ func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskReq
 		return task, true
 	}
 
+	// When the task is downloaded for the first time, Level1 peer is not allowed
+	// to back-to-source and Level2 peer is first to download back-to-source,
+	// other levels trigger the seed peer first. The seed peer triggers are limited,
+	// Level5 triggers start immediately and the waiting Level4 triggers start before
+	// the waiting Level3 triggers.
+	switch priority {
+	case managerv1.Priority_Level1:
+		task.Log.Infof("task dose not need to back-to-source, because of priority is %s", priority.String())
+		return task, false
+	case managerv1.Priority_Level2:
+		task.Log.Infof("task needs to back-to-source, because of priority is %s", priority.String())
+		return task, true
+	}
+
 	// FIXME Need to add the condition that the seed peer grpc client is
 	// available and can be triggered back-to-source.
 	if s.config.SeedPeer.Enable {
@@ -519,7 +650,7 @@ func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskReq
 			return task, true
 		}
 
-		go s.triggerSeedPeerTask(ctx, task)
+		go s.triggerSeedPeerTask(ctx, task, priority)
 		task.Log.Info("task dose not need to back-to-source, because of seed peer has been triggered")
 		return task, false
 	}
@@ -529,6 +660,41 @@ func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskReq
 	return task, true
 }
 
+// syncTaskFromClusterOnce syncs the task from cluster, only one syncing of the task is
+// in flight and the concurrent callers wait for its result.
+func (s *Service) syncTaskFromClusterOnce(task *resource.Task) bool {
+	if s.cluster.Len() == 0 {
+		return false
+	}
+
+	ok, _, _ := s.clusterSyncs.Do(task.ID, func() (any, error) {
+		// The syncing is shared by the concurrent registrations, so it is not
+		// bound to the context of the registration which starts it.
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Cluster.Timeout)
+		defer cancel()
+
+		return s.syncTaskFromCluster(ctx, task), nil
+	})
+
+	return ok.(bool)
+}
+
+// syncTaskFromCluster announces the succeeded peers of task in the other schedulers,
+// it returns true if the task has available peer after syncing.
+func (s *Service) syncTaskFromCluster(ctx context.Context, task *resource.Task) bool {
+	for _, req := range s.cluster.ListTaskPeers(ctx, task.ID) {
+		if req.UrlMeta == nil || req.PeerHost == nil || req.PiecePacket == nil {
+			continue
+		}
+
+		if err := s.AnnounceTask(ctx, req); err != nil {
+			task.Log.Errorf("announce peer %s from cluster failed: %s", req.PiecePacket.DstPid, err.Error())
+		}
+	}
+
+	return task.HasAvailablePeer()
+}
+
 // registerHost creates a new host or reuses a previous host.
 func (s *Service) registerHost(ctx context.Context, peerHost *schedulerv1.PeerHost) *resource.Host {
 	host, loaded := s.resource.HostManager().Load(peerHost.Id)
@@ -563,8 +729,8 @@ func (s *Service) registerHost(ctx context.Context, peerHost *schedulerv1.PeerHo
 }
 
 // registerPeer creates a new peer or reuses a previous peer.
-func (s *Service) registerPeer(ctx context.Context, peerID string, task *resource.Task, host *resource.Host, tag, application string) *resource.Peer {
-	var options []resource.PeerOption
+func (s *Service) registerPeer(ctx context.Context, peerID string, task *resource.Task, host *resource.Host, tag, application string, priority managerv1.Priority) *resource.Peer {
+	options := []resource.PeerOption{resource.WithPriority(priority)}
 	if tag != "" {
 		options = append(options, resource.WithTag(tag))
 	}
@@ -582,11 +748,18 @@ func (s *Service) registerPeer(ctx context.Context, peerID string, task *resourc
 	return peer
 }
 
-// triggerSeedPeerTask starts to trigger seed peer task.
-func (s *Service) triggerSeedPeerTask(ctx context.Context, task *resource.Task) {
-	task.Log.Infof("trigger seed peer download task and task status is %s", task.FSM.Current())
-	peer, endOfPiece, err := s.resource.SeedPeer().TriggerTask(
-		trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx)), task)
+// triggerSeedPeerTask starts to trigger seed peer task,
+// it waits until the trigger with priority is allowed by seed peer limiter.
+func (s *Service) triggerSeedPeerTask(ctx context.Context, task *resource.Task, priority managerv1.Priority) {
+	ctx = trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+	if err := s.seedPeerLimiter.Acquire(ctx, priority); err != nil {
+		task.Log.Errorf("wait for triggering seed peer failed: %s", err.Error())
+		return
+	}
+	defer s.seedPeerLimiter.Release()
+
+	task.Log.Infof("trigger seed peer download task with priority %s and task status is %s", priority.String(), task.FSM.Current())
+	peer, endOfPiece, err := s.resource.SeedPeer().TriggerTask(ctx, task)
 	if err != nil {
 		task.Log.Errorf("trigger seed peer download task failed: %s", err.Error())
 		s.handleTaskFail(ctx, task, nil, err)
@@ -806,7 +979,7 @@ func (s *Service) handlePieceFail(ctx context.Context, peer *resource.Peer, piec
 
 		// Start trigger seed peer task.
 		if s.config.SeedPeer.Enable {
-			go s.triggerSeedPeerTask(ctx, parent.Task)
+			go s.triggerSeedPeerTask(ctx, parent.Task, peer.Priority)
 		}
 	default:
 	}
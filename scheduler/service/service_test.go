@@ -37,6 +37,7 @@ import (
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	errordetailsv1 "d7y.io/api/pkg/apis/errordetails/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 	schedulerv1mocks "d7y.io/api/pkg/apis/scheduler/v1/mocks"
 
@@ -46,6 +47,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/rpc/common"
 	pkgtypes "d7y.io/dragonfly/v2/pkg/types"
+	clustermocks "d7y.io/dragonfly/v2/scheduler/cluster/mocks"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
 	"d7y.io/dragonfly/v2/scheduler/resource"
@@ -210,10 +212,43 @@ func TestService_RegisterPeerTask(t *testing.T) {
 		mock func(
 			req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 			scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-			ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+			ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 		)
 		expect func(t *testing.T, peer *resource.Peer, result *schedulerv1.RegisterResult, err error)
 	}{
+		{
+			name: "application priority is Level0",
+			req: &schedulerv1.PeerTaskRequest{
+				Url: mockTaskURL,
+				UrlMeta: &commonv1.UrlMeta{
+					Application: "foo",
+				},
+				PeerHost: &schedulerv1.PeerHost{
+					Id: mockRawHost.Id,
+				},
+			},
+			mock: func(
+				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
+				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
+			) {
+				md.GetApplications().Return([]*managerv1.Application{
+					{
+						Name: "foo",
+						Priority: &managerv1.ApplicationPriority{
+							Value: managerv1.Priority_Level0,
+						},
+					},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, result *schedulerv1.RegisterResult, err error) {
+				assert := assert.New(t)
+				dferr, ok := err.(*dferrors.DfError)
+				assert.True(ok)
+				assert.Equal(dferr.Code, commonv1.Code_SchedForbidden)
+				assert.Nil(result)
+			},
+		},
 		{
 			name: "task state is TaskStateRunning and it has available peer",
 			req: &schedulerv1.PeerTaskRequest{
@@ -225,12 +260,13 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateRunning)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
 				mockPeer.Task.StorePeer(mockSeedPeer)
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -258,13 +294,14 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateRunning)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
 				mockPeer.Task.StorePeer(mockSeedPeer)
 				mockPeer.FSM.SetState(resource.PeerStateFailed)
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -292,13 +329,14 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
 				mockPeer.Task.StorePeer(mockSeedPeer)
 				mockPeer.Task.ContentLength.Store(-1)
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -327,13 +365,14 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
 				mockPeer.Task.StorePeer(mockSeedPeer)
 				mockPeer.Task.ContentLength.Store(0)
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -364,7 +403,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
@@ -373,6 +412,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockPeer.Task.TotalPieceCount.Store(1)
 				mockPeer.Task.DirectPiece = []byte{1}
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -403,7 +443,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
@@ -411,6 +451,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockPeer.Task.ContentLength.Store(1)
 				mockPeer.Task.TotalPieceCount.Store(1)
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -439,7 +480,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
@@ -448,6 +489,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockPeer.Task.TotalPieceCount.Store(1)
 				mockPeer.FSM.SetState(resource.PeerStateFailed)
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -475,7 +517,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockPeer.Task.StorePeer(mockPeer)
@@ -489,6 +531,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
 
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -518,7 +561,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockPeer.Task.StorePeer(mockPeer)
@@ -532,6 +575,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockSeedPeer.FSM.SetState(resource.PeerStateSucceeded)
 
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -560,7 +604,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockPeer.Task.StorePeer(mockPeer)
@@ -574,6 +618,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockSeedPeer.FSM.SetState(resource.PeerStateSucceeded)
 
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -602,7 +647,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockPeer.Task.StorePeer(mockSeedPeer)
@@ -614,6 +659,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockSeedPeer.FSM.SetState(resource.PeerStateSucceeded)
 
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -643,7 +689,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockPeer.Task.StorePeer(mockPeer)
@@ -656,6 +702,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockSeedPeer.FSM.SetState(resource.PeerStateSucceeded)
 
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -685,7 +732,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
@@ -694,6 +741,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockPeer.Task.TotalPieceCount.Store(2)
 				mockPeer.FSM.SetState(resource.PeerStateFailed)
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -721,7 +769,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			mock: func(
 				req *schedulerv1.PeerTaskRequest, mockPeer *resource.Peer, mockSeedPeer *resource.Peer,
 				scheduler scheduler.Scheduler, res resource.Resource, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
-				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder,
 			) {
 				mockPeer.Task.FSM.SetState(resource.TaskStateSucceeded)
 				mockSeedPeer.FSM.SetState(resource.PeerStateRunning)
@@ -729,6 +777,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 				mockPeer.Task.ContentLength.Store(129)
 				mockPeer.Task.TotalPieceCount.Store(2)
 				gomock.InOrder(
+					md.GetApplications().Return(nil, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Any()).Return(mockPeer.Task, true).Times(1),
 					mr.HostManager().Return(hostManager).Times(1),
@@ -769,7 +818,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			tc.mock(
 				tc.req, mockPeer, mockSeedPeer,
 				scheduler, res, hostManager, taskManager, peerManager,
-				scheduler.EXPECT(), res.EXPECT(), hostManager.EXPECT(), taskManager.EXPECT(), peerManager.EXPECT(),
+				scheduler.EXPECT(), res.EXPECT(), hostManager.EXPECT(), taskManager.EXPECT(), peerManager.EXPECT(), dynconfig.EXPECT(),
 			)
 
 			result, err := svc.RegisterPeerTask(context.Background(), tc.req)
@@ -1555,6 +1604,104 @@ func TestService_AnnounceTask(t *testing.T) {
 	}
 }
 
+func TestService_ListTaskPeers(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(mockHost *resource.Host, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder)
+		expect func(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest, err error)
+	}{
+		{
+			name: "task not found",
+			mock: func(mockHost *resource.Host, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest, err error) {
+				assert := assert.New(t)
+				dferr, ok := err.(*dferrors.DfError)
+				assert.True(ok)
+				assert.Equal(commonv1.Code_PeerTaskNotFound, dferr.Code)
+			},
+		},
+		{
+			name: "peer state is PeerStateRunning",
+			mock: func(mockHost *resource.Host, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder) {
+				mockPeer.FSM.SetState(resource.PeerStateRunning)
+				mockTask.StorePeer(mockPeer)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(reqs, 0)
+			},
+		},
+		{
+			name: "peer state is PeerStateSucceeded",
+			mock: func(mockHost *resource.Host, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder) {
+				mockTask.ContentLength.Store(1000)
+				mockTask.TotalPieceCount.Store(2)
+				mockTask.StorePiece(&commonv1.PieceInfo{PieceNum: 0})
+				mockTask.StorePiece(&commonv1.PieceInfo{PieceNum: 1})
+				mockPeer.FinishedPieces.Set(1)
+				mockPeer.FSM.SetState(resource.PeerStateSucceeded)
+				mockTask.StorePeer(mockPeer)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, reqs []*schedulerv1.AnnounceTaskRequest, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(reqs, 1)
+				assert.Equal(mockTaskID, reqs[0].TaskId)
+				assert.Equal(mockTaskURL, reqs[0].Url)
+				assert.Equal(mockTaskURLMeta.Digest, reqs[0].UrlMeta.Digest)
+				assert.Equal(resource.DefaultTag, reqs[0].UrlMeta.Tag)
+				assert.Equal(resource.DefaultApplication, reqs[0].UrlMeta.Application)
+				assert.Equal(mockRawHost.Id, reqs[0].PeerHost.Id)
+				assert.Equal(mockRawHost.Port, reqs[0].PeerHost.RpcPort)
+				assert.Equal(mockRawHost.DownloadPort, reqs[0].PeerHost.DownPort)
+				assert.Equal(mockHostNetwork.Idc, reqs[0].PeerHost.Idc)
+				assert.Equal(mockPeerID, reqs[0].PiecePacket.DstPid)
+				assert.Equal("127.0.0.1:8001", reqs[0].PiecePacket.DstAddr)
+				assert.Equal([]*commonv1.PieceInfo{{PieceNum: 1}}, reqs[0].PiecePacket.PieceInfos)
+				assert.Equal(int32(2), reqs[0].PiecePacket.TotalPiece)
+				assert.Equal(int64(1000), reqs[0].PiecePacket.ContentLength)
+
+				// The url meta of task must not be modified.
+				assert.Equal("tag", mockTaskURLMeta.Tag)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduler := mocks.NewMockScheduler(ctl)
+			res := resource.NewMockResource(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
+			taskManager := resource.NewMockTaskManager(ctl)
+			svc := New(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduler, dynconfig, storage)
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			mockPeer := resource.NewPeer(mockPeerID, mockTask, mockHost)
+
+			tc.mock(mockHost, mockTask, mockPeer, taskManager, res.EXPECT(), taskManager.EXPECT())
+			reqs, err := svc.ListTaskPeers(context.Background(), &schedulerv1.StatTaskRequest{TaskId: mockTaskID})
+			tc.expect(t, reqs, err)
+		})
+	}
+}
+
 func TestService_LeaveTask(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -1994,7 +2141,7 @@ func TestService_registerTask(t *testing.T) {
 					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2021,7 +2168,7 @@ func TestService_registerTask(t *testing.T) {
 					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2048,7 +2195,7 @@ func TestService_registerTask(t *testing.T) {
 					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2086,7 +2233,7 @@ func TestService_registerTask(t *testing.T) {
 					mc.TriggerTask(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, task *resource.Task) { wg.Done() }).Return(mockPeer, &schedulerv1.PeerResult{}, nil).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTaskURL, task.URL)
@@ -2123,7 +2270,7 @@ func TestService_registerTask(t *testing.T) {
 					mc.TriggerTask(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, task *resource.Task) { wg.Done() }).Return(mockPeer, &schedulerv1.PeerResult{}, nil).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2151,7 +2298,7 @@ func TestService_registerTask(t *testing.T) {
 					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2187,7 +2334,177 @@ func TestService_registerTask(t *testing.T) {
 					mc.TriggerTask(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, task *resource.Task) { wg.Done() }).Return(mockPeer, &schedulerv1.PeerResult{}, nil).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
+				assert := assert.New(t)
+				assert.False(needBackToSource)
+				assert.EqualValues(mockTask, task)
+			},
+		},
+		{
+			name: "task state is TaskStatePending and priority is Level1",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: config.SeedPeerConfig{
+					Enable: true,
+				},
+			},
+			req: &schedulerv1.PeerTaskRequest{
+				Url:     mockTaskURL,
+				UrlMeta: mockTaskURLMeta,
+				PeerHost: &schedulerv1.PeerHost{
+					Id: mockRawHost.Id,
+				},
+			},
+			run: func(t *testing.T, svc *Service, req *schedulerv1.PeerTaskRequest, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, hostManager resource.HostManager, seedPeer resource.SeedPeer, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mh *resource.MockHostManagerMockRecorder, mc *resource.MockSeedPeerMockRecorder) {
+				mockTask.FSM.SetState(resource.TaskStatePending)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(nil, false),
+				)
+
+				task, needBackToSource := svc.registerTask(context.Background(), req, managerv1.Priority_Level1)
+				assert := assert.New(t)
+				assert.False(needBackToSource)
+				assert.EqualValues(mockTask, task)
+				assert.True(task.FSM.Is(resource.TaskStateRunning))
+			},
+		},
+		{
+			name: "task state is TaskStatePending and priority is Level2",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: config.SeedPeerConfig{
+					Enable: true,
+				},
+			},
+			req: &schedulerv1.PeerTaskRequest{
+				Url:     mockTaskURL,
+				UrlMeta: mockTaskURLMeta,
+				PeerHost: &schedulerv1.PeerHost{
+					Id: mockRawHost.Id,
+				},
+			},
+			run: func(t *testing.T, svc *Service, req *schedulerv1.PeerTaskRequest, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, hostManager resource.HostManager, seedPeer resource.SeedPeer, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mh *resource.MockHostManagerMockRecorder, mc *resource.MockSeedPeerMockRecorder) {
+				mockTask.FSM.SetState(resource.TaskStatePending)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(nil, false),
+				)
+
+				task, needBackToSource := svc.registerTask(context.Background(), req, managerv1.Priority_Level2)
+				assert := assert.New(t)
+				assert.True(needBackToSource)
+				assert.EqualValues(mockTask, task)
+				assert.True(task.FSM.Is(resource.TaskStateRunning))
+			},
+		},
+		{
+			name: "task state is TaskStatePending and priority is Level3",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: config.SeedPeerConfig{
+					Enable: true,
+				},
+			},
+			req: &schedulerv1.PeerTaskRequest{
+				Url:     mockTaskURL,
+				UrlMeta: mockTaskURLMeta,
+				PeerHost: &schedulerv1.PeerHost{
+					Id: mockRawHost.Id,
+				},
+			},
+			run: func(t *testing.T, svc *Service, req *schedulerv1.PeerTaskRequest, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, hostManager resource.HostManager, seedPeer resource.SeedPeer, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mh *resource.MockHostManagerMockRecorder, mc *resource.MockSeedPeerMockRecorder) {
+				var wg sync.WaitGroup
+				wg.Add(2)
+				defer wg.Wait()
+
+				mockTask.FSM.SetState(resource.TaskStatePending)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(nil, false),
+					mr.SeedPeer().Do(func() { wg.Done() }).Return(seedPeer).Times(1),
+					mc.TriggerTask(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, task *resource.Task) { wg.Done() }).Return(mockPeer, &schedulerv1.PeerResult{}, nil).Times(1),
+				)
+
+				task, needBackToSource := svc.registerTask(context.Background(), req, managerv1.Priority_Level3)
+				assert := assert.New(t)
+				assert.False(needBackToSource)
+				assert.EqualValues(mockTask, task)
+			},
+		},
+		{
+			name: "task state is TaskStatePending and priority is Level4",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: config.SeedPeerConfig{
+					Enable: true,
+				},
+			},
+			req: &schedulerv1.PeerTaskRequest{
+				Url:     mockTaskURL,
+				UrlMeta: mockTaskURLMeta,
+				PeerHost: &schedulerv1.PeerHost{
+					Id: mockRawHost.Id,
+				},
+			},
+			run: func(t *testing.T, svc *Service, req *schedulerv1.PeerTaskRequest, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, hostManager resource.HostManager, seedPeer resource.SeedPeer, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mh *resource.MockHostManagerMockRecorder, mc *resource.MockSeedPeerMockRecorder) {
+				var wg sync.WaitGroup
+				wg.Add(2)
+				defer wg.Wait()
+
+				mockTask.FSM.SetState(resource.TaskStatePending)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(nil, false),
+					mr.SeedPeer().Do(func() { wg.Done() }).Return(seedPeer).Times(1),
+					mc.TriggerTask(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, task *resource.Task) { wg.Done() }).Return(mockPeer, &schedulerv1.PeerResult{}, nil).Times(1),
+				)
+
+				task, needBackToSource := svc.registerTask(context.Background(), req, managerv1.Priority_Level4)
+				assert := assert.New(t)
+				assert.False(needBackToSource)
+				assert.EqualValues(mockTask, task)
+			},
+		},
+		{
+			name: "task state is TaskStatePending and priority is Level5",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: config.SeedPeerConfig{
+					Enable: true,
+				},
+			},
+			req: &schedulerv1.PeerTaskRequest{
+				Url:     mockTaskURL,
+				UrlMeta: mockTaskURLMeta,
+				PeerHost: &schedulerv1.PeerHost{
+					Id: mockRawHost.Id,
+				},
+			},
+			run: func(t *testing.T, svc *Service, req *schedulerv1.PeerTaskRequest, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, hostManager resource.HostManager, seedPeer resource.SeedPeer, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mh *resource.MockHostManagerMockRecorder, mc *resource.MockSeedPeerMockRecorder) {
+				var wg sync.WaitGroup
+				wg.Add(2)
+				defer wg.Wait()
+
+				mockTask.FSM.SetState(resource.TaskStatePending)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(mockTask, true).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(nil, false),
+					mr.SeedPeer().Do(func() { wg.Done() }).Return(seedPeer).Times(1),
+					mc.TriggerTask(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, task *resource.Task) { wg.Done() }).Return(mockPeer, &schedulerv1.PeerResult{}, nil).Times(1),
+				)
+
+				task, needBackToSource := svc.registerTask(context.Background(), req, managerv1.Priority_Level5)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2218,7 +2535,7 @@ func TestService_registerTask(t *testing.T) {
 					mh.Load(gomock.Any()).Return(mockHost, true).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.True(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2256,7 +2573,7 @@ func TestService_registerTask(t *testing.T) {
 					mc.TriggerTask(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, task *resource.Task) { wg.Done() }).Return(mockPeer, &schedulerv1.PeerResult{}, errors.New("foo")).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTaskURL, task.URL)
@@ -2293,7 +2610,7 @@ func TestService_registerTask(t *testing.T) {
 					mc.TriggerTask(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, task *resource.Task) { wg.Done() }).Return(mockPeer, &schedulerv1.PeerResult{}, errors.New("foo")).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.False(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2325,7 +2642,7 @@ func TestService_registerTask(t *testing.T) {
 					mh.Load(gomock.Any()).Return(nil, false).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.True(needBackToSource)
 				assert.EqualValues(mockTaskURL, task.URL)
@@ -2356,7 +2673,7 @@ func TestService_registerTask(t *testing.T) {
 					mh.Load(gomock.Any()).Return(nil, false).Times(1),
 				)
 
-				task, needBackToSource := svc.registerTask(context.Background(), req)
+				task, needBackToSource := svc.registerTask(context.Background(), req, resource.DefaultPriority)
 				assert := assert.New(t)
 				assert.True(needBackToSource)
 				assert.EqualValues(mockTask, task)
@@ -2385,6 +2702,339 @@ func TestService_registerTask(t *testing.T) {
 	}
 }
 
+func TestService_registerTaskFromCluster(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.Config
+		mock   func(mockHost *resource.Host, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+			mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+			mc *clustermocks.MockClusterMockRecorder)
+		expect func(t *testing.T, task *resource.Task, needBackToSource bool)
+	}{
+		{
+			name: "task is downloaded from the synced peers without triggering seed peer",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: config.SeedPeerConfig{
+					Enable: true,
+				},
+			},
+			mock: func(mockHost *resource.Host, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+				mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				mc *clustermocks.MockClusterMockRecorder) {
+				var storedTask *resource.Task
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(nil, false).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any()).Do(func(task *resource.Task) { storedTask = task }).Return().Times(1),
+					mc.Len().Return(1).Times(1),
+					mc.ListTaskPeers(gomock.Any(), gomock.Eq(mockTaskID)).Return([]*schedulerv1.AnnounceTaskRequest{
+						{
+							TaskId:   mockTaskID,
+							Url:      mockTaskURL,
+							UrlMeta:  &commonv1.UrlMeta{},
+							PeerHost: mockPeerHost,
+							PiecePacket: &commonv1.PiecePacket{
+								DstPid:        mockPeerID,
+								PieceInfos:    []*commonv1.PieceInfo{{PieceNum: 0}},
+								TotalPiece:    1,
+								ContentLength: 1000,
+							},
+						},
+					}).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadOrStore(gomock.Any()).DoAndReturn(func(task *resource.Task) (*resource.Task, bool) {
+						return storedTask, true
+					}).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(mockHost, true).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.LoadOrStore(gomock.Any()).DoAndReturn(func(peer *resource.Peer) (*resource.Peer, bool) {
+						peer.FSM.SetState(resource.PeerStateSucceeded)
+						peer.Task.StorePeer(peer)
+						return peer, true
+					}).Times(1),
+				)
+			},
+			expect: func(t *testing.T, task *resource.Task, needBackToSource bool) {
+				assert := assert.New(t)
+				assert.False(needBackToSource)
+				assert.True(task.FSM.Is(resource.TaskStateSucceeded))
+				assert.True(task.HasAvailablePeer())
+			},
+		},
+		{
+			name: "task has no peer in cluster and disable seed peer",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: config.SeedPeerConfig{
+					Enable: false,
+				},
+			},
+			mock: func(mockHost *resource.Host, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+				mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				mc *clustermocks.MockClusterMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(nil, false).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any()).Return().Times(1),
+					mc.Len().Return(1).Times(1),
+					mc.ListTaskPeers(gomock.Any(), gomock.Eq(mockTaskID)).Return(nil).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, task *resource.Task, needBackToSource bool) {
+				assert := assert.New(t)
+				assert.True(needBackToSource)
+				assert.True(task.FSM.Is(resource.TaskStateRunning))
+			},
+		},
+		{
+			name: "task is not synced without other schedulers",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: config.SeedPeerConfig{
+					Enable: false,
+				},
+			},
+			mock: func(mockHost *resource.Host, hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+				mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				mc *clustermocks.MockClusterMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Any()).Return(nil, false).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any()).Return().Times(1),
+					mc.Len().Return(0).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, task *resource.Task, needBackToSource bool) {
+				assert := assert.New(t)
+				assert.True(needBackToSource)
+				assert.True(task.FSM.Is(resource.TaskStateRunning))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduler := mocks.NewMockScheduler(ctl)
+			res := resource.NewMockResource(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
+			hostManager := resource.NewMockHostManager(ctl)
+			taskManager := resource.NewMockTaskManager(ctl)
+			peerManager := resource.NewMockPeerManager(ctl)
+			cluster := clustermocks.NewMockCluster(ctl)
+			svc := New(tc.config, res, scheduler, dynconfig, storage, WithCluster(cluster))
+			mockHost := resource.NewHost(mockRawHost)
+
+			tc.mock(mockHost, hostManager, taskManager, peerManager, res.EXPECT(), hostManager.EXPECT(), taskManager.EXPECT(), peerManager.EXPECT(), cluster.EXPECT())
+			task, needBackToSource := svc.registerTask(context.Background(), &schedulerv1.PeerTaskRequest{
+				TaskId:  mockTaskID,
+				Url:     mockTaskURL,
+				UrlMeta: mockTaskURLMeta,
+				PeerHost: &schedulerv1.PeerHost{
+					Id: mockRawHost.Id,
+				},
+			}, resource.DefaultPriority)
+			tc.expect(t, task, needBackToSource)
+		})
+	}
+}
+
+func TestService_registerTaskFromClusterConcurrently(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	scheduler := mocks.NewMockScheduler(ctl)
+	res := resource.NewMockResource(ctl)
+	dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+	storage := storagemocks.NewMockStorage(ctl)
+	hostManager := resource.NewMockHostManager(ctl)
+	taskManager := resource.NewMockTaskManager(ctl)
+	cluster := clustermocks.NewMockCluster(ctl)
+	svc := New(&config.Config{Scheduler: mockSchedulerConfig, Cluster: config.ClusterConfig{Timeout: time.Minute}}, res, scheduler, dynconfig, storage, WithCluster(cluster))
+
+	var (
+		mu         sync.Mutex
+		storedTask *resource.Task
+	)
+	loaded := make(chan struct{}, 2)
+	syncing := make(chan struct{})
+	release := make(chan struct{})
+	res.EXPECT().TaskManager().Return(taskManager).AnyTimes()
+	taskManager.EXPECT().Load(gomock.Eq(mockTaskID)).DoAndReturn(func(id string) (*resource.Task, bool) {
+		defer func() { loaded <- struct{}{} }()
+		mu.Lock()
+		defer mu.Unlock()
+		return storedTask, storedTask != nil
+	}).Times(2)
+	taskManager.EXPECT().Store(gomock.Any()).Do(func(task *resource.Task) {
+		mu.Lock()
+		defer mu.Unlock()
+		storedTask = task
+	}).Times(1)
+	var syncErr error
+	cluster.EXPECT().Len().Return(1).Times(2)
+	cluster.EXPECT().ListTaskPeers(gomock.Any(), gomock.Eq(mockTaskID)).DoAndReturn(func(ctx context.Context, id string) []*schedulerv1.AnnounceTaskRequest {
+		close(syncing)
+		<-release
+		syncErr = ctx.Err()
+		return nil
+	}).Times(1)
+	res.EXPECT().HostManager().Return(hostManager).Times(1)
+	hostManager.EXPECT().Load(gomock.Any()).Return(nil, false).Times(1)
+
+	req := &schedulerv1.PeerTaskRequest{
+		TaskId:  mockTaskID,
+		Url:     mockTaskURL,
+		UrlMeta: mockTaskURLMeta,
+		PeerHost: &schedulerv1.PeerHost{
+			Id: mockRawHost.Id,
+		},
+	}
+
+	var wg sync.WaitGroup
+	needBackToSources := make([]bool, 2)
+	tasks := make([]*resource.Task, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tasks[0], needBackToSources[0] = svc.registerTask(ctx, req, resource.DefaultPriority)
+	}()
+	<-loaded
+	<-syncing
+
+	// The syncing is not canceled with the registration which starts it.
+	cancel()
+
+	// The second registration loads the pending task while the first one is syncing.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tasks[1], needBackToSources[1] = svc.registerTask(context.Background(), req, resource.DefaultPriority)
+	}()
+	<-loaded
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert := assert.New(t)
+	assert.NoError(syncErr)
+	assert.Equal(tasks[0], tasks[1])
+	assert.True(tasks[0].FSM.Is(resource.TaskStateRunning))
+	assert.True(needBackToSources[0])
+	assert.False(needBackToSources[1])
+}
+
+func TestService_syncTaskFromCluster(t *testing.T) {
+	tests := []struct {
+		name string
+		mock func(mockHost *resource.Host, mockTask *resource.Task, mockPeer *resource.Peer,
+			hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+			mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+			mc *clustermocks.MockClusterMockRecorder)
+		expect func(t *testing.T, mockTask *resource.Task, ok bool)
+	}{
+		{
+			name: "task has no peer in cluster",
+			mock: func(mockHost *resource.Host, mockTask *resource.Task, mockPeer *resource.Peer,
+				hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+				mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				mc *clustermocks.MockClusterMockRecorder) {
+				mc.ListTaskPeers(gomock.Any(), gomock.Eq(mockTaskID)).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, mockTask *resource.Task, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.True(mockTask.FSM.Is(resource.TaskStatePending))
+			},
+		},
+		{
+			name: "invalid peer in cluster is ignored",
+			mock: func(mockHost *resource.Host, mockTask *resource.Task, mockPeer *resource.Peer,
+				hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+				mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				mc *clustermocks.MockClusterMockRecorder) {
+				mc.ListTaskPeers(gomock.Any(), gomock.Eq(mockTaskID)).Return([]*schedulerv1.AnnounceTaskRequest{{TaskId: mockTaskID}}).Times(1)
+			},
+			expect: func(t *testing.T, mockTask *resource.Task, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+		{
+			name: "task has succeeded peer in cluster",
+			mock: func(mockHost *resource.Host, mockTask *resource.Task, mockPeer *resource.Peer,
+				hostManager resource.HostManager, taskManager resource.TaskManager, peerManager resource.PeerManager,
+				mr *resource.MockResourceMockRecorder, mh *resource.MockHostManagerMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder,
+				mc *clustermocks.MockClusterMockRecorder) {
+				mockPeer.FSM.SetState(resource.PeerStateSucceeded)
+				mockTask.StorePeer(mockPeer)
+				gomock.InOrder(
+					mc.ListTaskPeers(gomock.Any(), gomock.Eq(mockTaskID)).Return([]*schedulerv1.AnnounceTaskRequest{
+						{
+							TaskId:   mockTaskID,
+							Url:      mockTaskURL,
+							UrlMeta:  &commonv1.UrlMeta{},
+							PeerHost: mockPeerHost,
+							PiecePacket: &commonv1.PiecePacket{
+								DstPid:        mockPeerID,
+								PieceInfos:    []*commonv1.PieceInfo{{PieceNum: 0}},
+								TotalPiece:    1,
+								ContentLength: 1000,
+							},
+						},
+					}).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadOrStore(gomock.Any()).Return(mockTask, true).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(mockHost, true).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.LoadOrStore(gomock.Any()).Return(mockPeer, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, mockTask *resource.Task, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.True(mockTask.FSM.Is(resource.TaskStateSucceeded))
+				assert.Equal(int64(1000), mockTask.ContentLength.Load())
+				assert.Equal(int32(1), mockTask.TotalPieceCount.Load())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduler := mocks.NewMockScheduler(ctl)
+			res := resource.NewMockResource(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
+			hostManager := resource.NewMockHostManager(ctl)
+			taskManager := resource.NewMockTaskManager(ctl)
+			peerManager := resource.NewMockPeerManager(ctl)
+			cluster := clustermocks.NewMockCluster(ctl)
+			svc := New(&config.Config{Scheduler: mockSchedulerConfig, Metrics: config.MetricsConfig{EnablePeerHost: true}}, res, scheduler, dynconfig, storage, WithCluster(cluster))
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			mockPeer := resource.NewPeer(mockPeerID, mockTask, mockHost)
+
+			tc.mock(mockHost, mockTask, mockPeer, hostManager, taskManager, peerManager, res.EXPECT(), hostManager.EXPECT(), taskManager.EXPECT(), peerManager.EXPECT(), cluster.EXPECT())
+			tc.expect(t, mockTask, svc.syncTaskFromCluster(context.Background(), mockTask))
+		})
+	}
+}
+
 func TestService_registerHost(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -2532,7 +3182,7 @@ func TestService_triggerSeedPeerTask(t *testing.T) {
 			svc := New(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduler, dynconfig, storage)
 
 			tc.mock(task, peer, seedPeer, res.EXPECT(), seedPeer.EXPECT())
-			svc.triggerSeedPeerTask(context.Background(), task)
+			svc.triggerSeedPeerTask(context.Background(), task, resource.DefaultPriority)
 			tc.expect(t, task, peer)
 		})
 	}
@@ -2677,7 +3327,7 @@ func TestService_registerPeer(t *testing.T) {
 			mockPeer := resource.NewPeer(mockPeerID, mockTask, mockHost)
 
 			tc.mock(mockPeer, peerManager, res.EXPECT(), peerManager.EXPECT())
-			peer := svc.registerPeer(context.Background(), tc.req.PeerId, mockTask, mockHost, tc.req.UrlMeta.Tag, tc.req.UrlMeta.Application)
+			peer := svc.registerPeer(context.Background(), tc.req.PeerId, mockTask, mockHost, tc.req.UrlMeta.Tag, tc.req.UrlMeta.Application, resource.DefaultPriority)
 			tc.expect(t, peer)
 		})
 	}
@@ -0,0 +1,133 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+
+	"github.com/gocarina/gocsv"
+)
+
+const (
+	// CSVFileExt is extension of csv record file.
+	CSVFileExt = "csv"
+)
+
+// csvSink encodes records in csv without headers.
+type csvSink struct{}
+
+// newCSVSink returns a new csv Sink.
+func newCSVSink() Sink {
+	return &csvSink{}
+}
+
+// Ext returns the extension of csv record file.
+func (s *csvSink) Ext() string {
+	return CSVFileExt
+}
+
+// NewWriter returns a csv writer.
+func (s *csvSink) NewWriter(w io.Writer) (Writer, error) {
+	return &csvWriter{w: w}, nil
+}
+
+// NewIterator returns a csv iterator of the record file.
+func (s *csvSink) NewIterator(filename string) (Iterator, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvIterator{
+		file:   file,
+		reader: csv.NewReader(file),
+	}, nil
+}
+
+// csvWriter writes records in csv, the csv is appendable so that
+// there is nothing to flush when it closes.
+type csvWriter struct {
+	w io.Writer
+}
+
+// Write encodes the records in csv.
+func (w *csvWriter) Write(records ...Record) error {
+	return gocsv.MarshalWithoutHeaders(records, w.w)
+}
+
+// Close completes the csv record file.
+func (w *csvWriter) Close() error {
+	return nil
+}
+
+// csvIterator decodes the csv record file row by row.
+type csvIterator struct {
+	file   *os.File
+	reader *csv.Reader
+}
+
+// Next returns the record of next row.
+func (i *csvIterator) Next() (*Record, error) {
+	row, err := i.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	if err := gocsv.UnmarshalCSVWithoutHeaders(&csvRowReader{row: row}, &records); err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, io.EOF
+	}
+
+	return &records[0], nil
+}
+
+// Close closes the csv record file.
+func (i *csvIterator) Close() error {
+	return i.file.Close()
+}
+
+// csvRowReader is the gocsv.CSVReader of a single row.
+type csvRowReader struct {
+	row  []string
+	read bool
+}
+
+// Read returns the row at the first time, then returns io.EOF.
+func (r *csvRowReader) Read() ([]string, error) {
+	if r.read {
+		return nil, io.EOF
+	}
+
+	r.read = true
+	return r.row, nil
+}
+
+// ReadAll returns the unread row.
+func (r *csvRowReader) ReadAll() ([][]string, error) {
+	if r.read {
+		return nil, nil
+	}
+
+	r.read = true
+	return [][]string{r.row}, nil
+}
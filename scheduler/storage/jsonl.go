@@ -0,0 +1,121 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+)
+
+const (
+	// JSONLFileExt is extension of gzip compressed json lines record file.
+	JSONLFileExt = "jsonl.gz"
+)
+
+// jsonlSink encodes a record per line in json and compresses lines by gzip.
+type jsonlSink struct{}
+
+// newJSONLSink returns a new json lines Sink.
+func newJSONLSink() Sink {
+	return &jsonlSink{}
+}
+
+// Ext returns the extension of json lines record file.
+func (s *jsonlSink) Ext() string {
+	return JSONLFileExt
+}
+
+// NewWriter returns a gzip compressed json lines writer.
+func (s *jsonlSink) NewWriter(w io.Writer) (Writer, error) {
+	gw := gzip.NewWriter(w)
+	return &jsonlWriter{
+		gw:      gw,
+		encoder: json.NewEncoder(gw),
+	}, nil
+}
+
+// NewIterator returns a json lines iterator of the record file.
+func (s *jsonlSink) NewIterator(filename string) (Iterator, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &jsonlIterator{
+		file:    file,
+		gr:      gr,
+		decoder: json.NewDecoder(gr),
+	}, nil
+}
+
+// jsonlWriter writes records in gzip compressed json lines.
+type jsonlWriter struct {
+	gw      *gzip.Writer
+	encoder *json.Encoder
+}
+
+// Write encodes the records in json lines, and flushes the compressed data
+// so that the size of record file grows with the records.
+func (w *jsonlWriter) Write(records ...Record) error {
+	for _, record := range records {
+		if err := w.encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	return w.gw.Flush()
+}
+
+// Close writes the gzip footer.
+func (w *jsonlWriter) Close() error {
+	return w.gw.Close()
+}
+
+// jsonlIterator decodes the json lines record file line by line.
+type jsonlIterator struct {
+	file    *os.File
+	gr      *gzip.Reader
+	decoder *json.Decoder
+}
+
+// Next returns the record of next line.
+func (i *jsonlIterator) Next() (*Record, error) {
+	record := &Record{}
+	if err := i.decoder.Decode(record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Close closes the json lines record file.
+func (i *jsonlIterator) Close() error {
+	if err := i.gr.Close(); err != nil {
+		i.file.Close()
+		return err
+	}
+
+	return i.file.Close()
+}
@@ -49,6 +49,20 @@ func (mr *MockStorageMockRecorder) Clear() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockStorage)(nil).Clear))
 }
 
+// Close mocks base method.
+func (m *MockStorage) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockStorageMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStorage)(nil).Close))
+}
+
 // Count mocks base method.
 func (m *MockStorage) Count() int64 {
 	m.ctrl.T.Helper()
@@ -77,19 +91,19 @@ func (mr *MockStorageMockRecorder) Create(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockStorage)(nil).Create), arg0)
 }
 
-// List mocks base method.
-func (m *MockStorage) List() ([]storage.Record, error) {
+// Iterate mocks base method.
+func (m *MockStorage) Iterate() (storage.Iterator, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List")
-	ret0, _ := ret[0].([]storage.Record)
+	ret := m.ctrl.Call(m, "Iterate")
+	ret0, _ := ret[0].(storage.Iterator)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// List indicates an expected call of List.
-func (mr *MockStorageMockRecorder) List() *gomock.Call {
+// Iterate indicates an expected call of Iterate.
+func (mr *MockStorageMockRecorder) Iterate() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStorage)(nil).List))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iterate", reflect.TypeOf((*MockStorage)(nil).Iterate))
 }
 
 // Open mocks base method.
@@ -0,0 +1,178 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	// ParquetFileExt is extension of parquet record file.
+	ParquetFileExt = "parquet"
+
+	// parquetParallelNumber is the number of goroutines to encode and decode parquet.
+	parquetParallelNumber = 1
+
+	// parquetReadBatchSize is the count of records read from parquet at once.
+	parquetReadBatchSize = 100
+)
+
+// parquetSink encodes records in parquet, the schema is defined by
+// the parquet tags of Record.
+type parquetSink struct{}
+
+// newParquetSink returns a new parquet Sink.
+func newParquetSink() Sink {
+	return &parquetSink{}
+}
+
+// Ext returns the extension of parquet record file.
+func (s *parquetSink) Ext() string {
+	return ParquetFileExt
+}
+
+// NewWriter returns a parquet writer.
+func (s *parquetSink) NewWriter(w io.Writer) (Writer, error) {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(Record), parquetParallelNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// The struct marshaler of parquet does not support unsigned integers,
+	// so records are marshaled by json of parquet rows.
+	pw.MarshalFunc = marshal.MarshalJSON
+	return &parquetWriter{pw: pw}, nil
+}
+
+// NewIterator returns a parquet iterator of the record file.
+func (s *parquetSink) NewIterator(filename string) (Iterator, error) {
+	pf, err := local.NewLocalFileReader(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := reader.NewParquetReader(pf, new(Record), parquetParallelNumber)
+	if err != nil {
+		pf.Close()
+		return nil, err
+	}
+
+	return &parquetIterator{
+		pf:   pf,
+		pr:   pr,
+		rows: pr.GetNumRows(),
+	}, nil
+}
+
+// parquetWriter writes records in parquet.
+type parquetWriter struct {
+	pw *writer.ParquetWriter
+}
+
+// Write encodes the records as a row group of parquet.
+func (w *parquetWriter) Write(records ...Record) error {
+	for _, record := range records {
+		row, err := json.Marshal(parquetRow(reflect.ValueOf(record)))
+		if err != nil {
+			return err
+		}
+
+		if err := w.pw.Write(string(row)); err != nil {
+			return err
+		}
+	}
+
+	return w.pw.Flush(true)
+}
+
+// Close writes the parquet footer.
+func (w *parquetWriter) Close() error {
+	return w.pw.WriteStop()
+}
+
+// parquetRow converts value to the row of parquet json marshaler,
+// which is keyed by field name and stores unsigned integers as signed integers.
+func parquetRow(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Struct:
+		row := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			row[v.Type().Field(i).Name] = parquetRow(v.Field(i))
+		}
+
+		return row
+	case reflect.Slice:
+		rows := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			rows[i] = parquetRow(v.Index(i))
+		}
+
+		return rows
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	default:
+		return v.Interface()
+	}
+}
+
+// parquetIterator decodes the parquet record file by batch.
+type parquetIterator struct {
+	pf      source.ParquetFile
+	pr      *reader.ParquetReader
+	rows    int64
+	records []Record
+}
+
+// Next returns the next record, it reads a batch of records if the buffered records are consumed.
+func (i *parquetIterator) Next() (*Record, error) {
+	if len(i.records) == 0 {
+		if i.rows <= 0 {
+			return nil, io.EOF
+		}
+
+		n := int64(parquetReadBatchSize)
+		if i.rows < n {
+			n = i.rows
+		}
+
+		records := make([]Record, n)
+		if err := i.pr.Read(&records); err != nil {
+			return nil, err
+		}
+
+		i.rows -= n
+		i.records = records
+	}
+
+	record := i.records[0]
+	i.records = i.records[1:]
+	return &record, nil
+}
+
+// Close closes the parquet record file.
+func (i *parquetIterator) Close() error {
+	i.pr.ReadStop()
+	return i.pf.Close()
+}
@@ -0,0 +1,139 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/sink_mock.go -source sink.go -package mocks
+
+package storage
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// FormatCSV is the csv format of record file.
+	FormatCSV = "csv"
+
+	// FormatJSONL is the gzip compressed json lines format of record file.
+	FormatJSONL = "jsonl"
+
+	// FormatParquet is the parquet format of record file.
+	FormatParquet = "parquet"
+)
+
+// Sink is the interface used for the format of record file.
+type Sink interface {
+	// Ext returns the extension of record file.
+	Ext() string
+
+	// NewWriter returns a writer which encodes records into w,
+	// the record file is completed after the writer is closed.
+	NewWriter(w io.Writer) (Writer, error)
+
+	// NewIterator returns an iterator which decodes records from the record file.
+	NewIterator(filename string) (Iterator, error)
+}
+
+// Writer encodes records into record file.
+type Writer interface {
+	// Write encodes the records.
+	Write(records ...Record) error
+
+	// Close flushes the encoded records and completes the record file,
+	// it does not close the underlying writer.
+	Close() error
+}
+
+// Iterator iterates records in order.
+type Iterator interface {
+	// Next returns the next record, it returns io.EOF if there is no more record.
+	Next() (*Record, error)
+
+	// Close closes the iterator.
+	Close() error
+}
+
+// NewSink returns a new Sink by the format of record file.
+func NewSink(format string) (Sink, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVSink(), nil
+	case FormatJSONL:
+		return newJSONLSink(), nil
+	case FormatParquet:
+		return newParquetSink(), nil
+	}
+
+	return nil, fmt.Errorf("unknown record format %s", format)
+}
+
+// multiIterator iterates the records of record files in order.
+type multiIterator struct {
+	sink      Sink
+	filenames []string
+	iterator  Iterator
+}
+
+// newMultiIterator returns an iterator of record files.
+func newMultiIterator(sink Sink, filenames []string) Iterator {
+	return &multiIterator{
+		sink:      sink,
+		filenames: filenames,
+	}
+}
+
+// Next returns the next record of record files.
+func (m *multiIterator) Next() (*Record, error) {
+	for {
+		if m.iterator == nil {
+			if len(m.filenames) == 0 {
+				return nil, io.EOF
+			}
+
+			iterator, err := m.sink.NewIterator(m.filenames[0])
+			if err != nil {
+				return nil, err
+			}
+
+			m.iterator = iterator
+			m.filenames = m.filenames[1:]
+		}
+
+		record, err := m.iterator.Next()
+		if err == io.EOF {
+			if err := m.iterator.Close(); err != nil {
+				return nil, err
+			}
+
+			m.iterator = nil
+			continue
+		}
+
+		return record, err
+	}
+}
+
+// Close closes the iterator of current record file.
+func (m *multiIterator) Close() error {
+	m.filenames = nil
+	if m.iterator == nil {
+		return nil
+	}
+
+	err := m.iterator.Close()
+	m.iterator = nil
+	return err
+}
@@ -0,0 +1,181 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSink(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		expect func(t *testing.T, s Sink, err error)
+	}{
+		{
+			name:   "new csv sink",
+			format: FormatCSV,
+			expect: func(t *testing.T, s Sink, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(s.Ext(), CSVFileExt)
+			},
+		},
+		{
+			name:   "new json lines sink",
+			format: FormatJSONL,
+			expect: func(t *testing.T, s Sink, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(s.Ext(), JSONLFileExt)
+			},
+		},
+		{
+			name:   "new parquet sink",
+			format: FormatParquet,
+			expect: func(t *testing.T, s Sink, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(s.Ext(), ParquetFileExt)
+			},
+		},
+		{
+			name:   "unknown format",
+			format: "foo",
+			expect: func(t *testing.T, s Sink, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "unknown record format foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewSink(tc.format)
+			tc.expect(t, s, err)
+		})
+	}
+}
+
+func TestSink_WriterAndIterator(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		records []Record
+	}{
+		{
+			name:    "csv sink",
+			format:  FormatCSV,
+			records: []Record{mockRecord, mockRecord},
+		},
+		{
+			name:    "json lines sink",
+			format:  FormatJSONL,
+			records: []Record{mockRecord, mockRecord},
+		},
+		{
+			name:    "parquet sink",
+			format:  FormatParquet,
+			records: []Record{mockRecord, mockRecord},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			s, err := NewSink(tc.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			filename := filepath.Join(t.TempDir(), "record."+s.Ext())
+			file, err := os.Create(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w, err := s.NewWriter(file)
+			assert.NoError(err)
+			for _, record := range tc.records {
+				assert.NoError(w.Write(record))
+			}
+			assert.NoError(w.Close())
+			assert.NoError(file.Close())
+
+			iterator, err := s.NewIterator(filename)
+			assert.NoError(err)
+			defer iterator.Close()
+
+			for _, record := range tc.records {
+				r, err := iterator.Next()
+				assert.NoError(err)
+				assert.EqualValues(*r, record)
+			}
+
+			_, err = iterator.Next()
+			assert.ErrorIs(err, io.EOF)
+		})
+	}
+}
+
+func TestMultiIterator(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewSink(FormatJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var filenames []string
+	for _, id := range []string{"1", "2"} {
+		filename := filepath.Join(t.TempDir(), "record."+s.Ext())
+		file, err := os.Create(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w, err := s.NewWriter(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := w.Write(Record{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+		file.Close()
+		filenames = append(filenames, filename)
+	}
+
+	iterator := newMultiIterator(s, append(filenames, filepath.Join(t.TempDir(), "foo")))
+	defer iterator.Close()
+
+	record, err := iterator.Next()
+	assert.NoError(err)
+	assert.Equal(record.ID, "1")
+
+	record, err = iterator.Next()
+	assert.NoError(err)
+	assert.Equal(record.ID, "2")
+
+	_, err = iterator.Next()
+	assert.Error(err)
+}
@@ -19,6 +19,7 @@
 package storage
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -26,13 +27,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/gocarina/gocsv"
-
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	pkgio "d7y.io/dragonfly/v2/pkg/io"
 )
@@ -40,9 +39,6 @@ import (
 const (
 	// RecordFilePrefix is prefix of record file name.
 	RecordFilePrefix = "record"
-
-	// RecordFileExt is extension of record file name.
-	RecordFileExt = "csv"
 )
 
 const (
@@ -51,24 +47,37 @@ const (
 
 	// backupTimeFormat is the timestamp format of backup filename.
 	backupTimeFormat = "2006-01-02T15-04-05.000"
+
+	// uploadTimeout is the timeout of uploading a backup file.
+	uploadTimeout = 10 * time.Minute
+
+	// defaultMaxAge is the default max age of active record file.
+	defaultMaxAge = time.Hour
+
+	// defaultFlushInterval is the default interval of flushing buffered records.
+	defaultFlushInterval = 10 * time.Second
 )
 
 // Storage is the interface used for storage.
 type Storage interface {
-	// Create inserts the record into csv file.
+	// Create inserts the record into record file.
 	Create(Record) error
 
-	// List returns all of records in csv file.
-	List() ([]Record, error)
+	// Iterate returns the iterator of records in backup files, the records in the
+	// active record file are readable after it is rotated by max size or max age.
+	Iterate() (Iterator, error)
 
 	// Count returns the count of records.
 	Count() int64
 
-	// Open opens storage for read, it returns io.ReadCloser of storage files.
+	// Open opens storage for read, it returns io.ReadCloser of backup files.
 	Open() (io.ReadCloser, error)
 
 	// Clear removes all record files.
 	Clear() error
+
+	// Close flushes the buffered records and rotates the active record file.
+	Close() error
 }
 
 // storage provides storage function.
@@ -77,41 +86,105 @@ type storage struct {
 	filename   string
 	maxSize    int64
 	maxBackups int
+	maxAge     time.Duration
 	buffer     []Record
 	bufferSize int
 	count      int64
 	mu         *sync.RWMutex
+
+	// sink is the format of record file.
+	sink Sink
+
+	// file and writer are the active record file, they are opened when the records
+	// are written for the first time after rotating.
+	file     *os.File
+	writer   Writer
+	openedAt time.Time
+
+	// flushInterval is the interval of flushing buffered records into record file,
+	// so that the buffered records are not lost for long.
+	flushInterval time.Duration
+	done          chan struct{}
+	once          sync.Once
+	wg            sync.WaitGroup
+
+	// uploader uploads the backup files.
+	uploader Uploader
+	uploads  sync.WaitGroup
+}
+
+// Option is a functional option for configuring the storage.
+type Option func(s *storage)
+
+// WithSink sets the format of record file, default format is csv.
+func WithSink(sink Sink) Option {
+	return func(s *storage) {
+		s.sink = sink
+	}
+}
+
+// WithUploader uploads the backup files after rotating.
+func WithUploader(uploader Uploader) Option {
+	return func(s *storage) {
+		s.uploader = uploader
+	}
+}
+
+// WithMaxAge sets the max age of active record file, the active record file
+// is rotated when it exceeds the max age.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(s *storage) {
+		s.maxAge = maxAge
+	}
+}
+
+// WithFlushInterval sets the interval of flushing buffered records into record file.
+func WithFlushInterval(interval time.Duration) Option {
+	return func(s *storage) {
+		s.flushInterval = interval
+	}
 }
 
 // New returns a new Storage instence.
-func New(baseDir string, maxSize, maxBackups, bufferSize int) (Storage, error) {
+func New(baseDir string, maxSize, maxBackups, bufferSize int, options ...Option) (Storage, error) {
 	s := &storage{
-		baseDir:    baseDir,
-		filename:   filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, RecordFileExt)),
-		maxSize:    int64(maxSize * megabyte),
-		maxBackups: maxBackups,
-		buffer:     make([]Record, 0, bufferSize),
-		bufferSize: bufferSize,
-		mu:         &sync.RWMutex{},
+		baseDir:       baseDir,
+		maxSize:       int64(maxSize * megabyte),
+		maxBackups:    maxBackups,
+		maxAge:        defaultMaxAge,
+		buffer:        make([]Record, 0, bufferSize),
+		bufferSize:    bufferSize,
+		mu:            &sync.RWMutex{},
+		sink:          newCSVSink(),
+		flushInterval: defaultFlushInterval,
+		done:          make(chan struct{}),
 	}
 
+	for _, opt := range options {
+		opt(s)
+	}
+
+	s.filename = filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, s.sink.Ext()))
 	file, err := os.OpenFile(s.filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return nil, err
 	}
 	file.Close()
 
+	s.wg.Add(1)
+	go s.serve()
+
 	return s, nil
 }
 
-// Create inserts the record into csv file.
+// Create inserts the record into record file.
 func (s *storage) Create(record Record) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// Write without buffer.
 	if s.bufferSize == 0 {
-		if err := s.create(s.buffer...); err != nil {
+		if err := s.create(record); err != nil {
 			return err
 		}
 
@@ -122,15 +195,9 @@ func (s *storage) Create(record Record) error {
 
 	// Write records to file.
 	if len(s.buffer) >= s.bufferSize {
-		if err := s.create(s.buffer...); err != nil {
+		if err := s.flush(); err != nil {
 			return err
 		}
-
-		// Update record count.
-		s.count += int64(s.bufferSize)
-
-		// Keep allocated memory.
-		s.buffer = s.buffer[:0]
 	}
 
 	// Write records to buffer.
@@ -138,8 +205,8 @@ func (s *storage) Create(record Record) error {
 	return nil
 }
 
-// List returns all of records in csv file.
-func (s *storage) List() ([]Record, error) {
+// Iterate returns the iterator of records in backup files.
+func (s *storage) Iterate() (Iterator, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -148,32 +215,18 @@ func (s *storage) List() ([]Record, error) {
 		return nil, err
 	}
 
-	var readers []io.Reader
-	var readClosers []io.ReadCloser
-	defer func() {
-		for _, readCloser := range readClosers {
-			if err := readCloser.Close(); err != nil {
-				logger.Error(err)
-			}
-		}
-	}()
-
+	var filenames []string
 	for _, fileInfo := range fileInfos {
-		file, err := os.Open(filepath.Join(s.baseDir, fileInfo.Name()))
-		if err != nil {
-			return nil, err
+		// Empty record file has no record, and it may be invalid for the format
+		// which has header or footer.
+		if fileInfo.Size() == 0 || s.isActive(fileInfo) {
+			continue
 		}
 
-		readers = append(readers, file)
-		readClosers = append(readClosers, file)
-	}
-
-	var records []Record
-	if err := gocsv.UnmarshalWithoutHeaders(io.MultiReader(readers...), &records); err != nil {
-		return nil, err
+		filenames = append(filenames, filepath.Join(s.baseDir, fileInfo.Name()))
 	}
 
-	return records, nil
+	return newMultiIterator(s.sink, filenames), nil
 }
 
 // Count returns the count of records.
@@ -181,7 +234,7 @@ func (s *storage) Count() int64 {
 	return s.count
 }
 
-// Open opens storage for read, it returns io.ReadCloser of storage files.
+// Open opens storage for read, it returns io.ReadCloser of backup files.
 func (s *storage) Open() (io.ReadCloser, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -193,6 +246,10 @@ func (s *storage) Open() (io.ReadCloser, error) {
 
 	var readClosers []io.ReadCloser
 	for _, fileInfo := range fileInfos {
+		if s.isActive(fileInfo) {
+			continue
+		}
+
 		file, err := os.Open(filepath.Join(s.baseDir, fileInfo.Name()))
 		if err != nil {
 			return nil, err
@@ -204,11 +261,26 @@ func (s *storage) Open() (io.ReadCloser, error) {
 	return pkgio.MultiReadCloser(readClosers...), nil
 }
 
-// Clear removes all records.
+// Clear removes all records, and waits for the backup files being uploaded.
+// The lock is released before waiting, so records can be created during uploading.
 func (s *storage) Clear() error {
+	if err := s.clear(); err != nil {
+		return err
+	}
+
+	s.uploads.Wait()
+	return nil
+}
+
+// clear closes the active record file and removes all record files.
+func (s *storage) clear() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.closeWriter(); err != nil {
+		logger.Errorf("close record file failed: %s", err.Error())
+	}
+
 	fileInfos, err := s.backups()
 	if err != nil {
 		return err
@@ -224,58 +296,204 @@ func (s *storage) Clear() error {
 	return nil
 }
 
-// create inserts the records into csv file.
-func (s *storage) create(records ...Record) error {
-	file, err := s.openFile()
-	if err != nil {
+// Close stops flushing, then flushes the buffered records and rotates the
+// active record file, so that the records are completed in backup files.
+func (s *storage) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.flush(); err != nil {
 		return err
 	}
-	defer file.Close()
 
-	if err := gocsv.MarshalWithoutHeaders(records, file); err != nil {
+	return s.rotate()
+}
+
+// serve flushes the buffered records and rotates the expired active record file
+// every flush interval until closed.
+func (s *storage) serve() {
+	defer s.wg.Done()
+
+	tick := time.NewTicker(s.flushInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			s.mu.Lock()
+			if err := s.flush(); err != nil {
+				logger.Errorf("flush records failed: %s", err.Error())
+			}
+
+			if s.writer != nil && time.Since(s.openedAt) >= s.maxAge {
+				if err := s.rotate(); err != nil {
+					logger.Errorf("rotate record file failed: %s", err.Error())
+				}
+			}
+			s.mu.Unlock()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// flush writes the buffered records into record file.
+func (s *storage) flush() error {
+	if len(s.buffer) == 0 {
+		return nil
+	}
+
+	if err := s.create(s.buffer...); err != nil {
 		return err
 	}
 
+	// Update record count.
+	s.count += int64(len(s.buffer))
+
+	// Keep allocated memory.
+	s.buffer = s.buffer[:0]
 	return nil
 }
 
-// openFile opens the record file and removes record files that exceed the total size.
-func (s *storage) openFile() (*os.File, error) {
-	fileInfo, err := os.Stat(s.filename)
+// isActive reports whether the file is the active record file.
+func (s *storage) isActive(fileInfo fs.FileInfo) bool {
+	return filepath.Join(s.baseDir, fileInfo.Name()) == s.filename
+}
+
+// create inserts the records into record file.
+func (s *storage) create(records ...Record) error {
+	writer, err := s.openWriter()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if s.maxSize <= fileInfo.Size() {
-		if err := os.Rename(s.filename, s.backupFilename()); err != nil {
+	return writer.Write(records...)
+}
+
+// openWriter opens the writer of active record file, and rotates the active
+// record file if it exceeds the max size or the max age.
+func (s *storage) openWriter() (Writer, error) {
+	if s.writer != nil {
+		fileInfo, err := s.file.Stat()
+		if err != nil {
+			return nil, err
+		}
+
+		if fileInfo.Size() < s.maxSize && time.Since(s.openedAt) < s.maxAge {
+			return s.writer, nil
+		}
+
+		if err := s.rotate(); err != nil {
 			return nil, err
 		}
 	}
 
-	fileInfos, err := s.backups()
+	// The writer always starts a new record file, because the completed record
+	// file of some formats can not be appended.
+	file, err := os.OpenFile(s.filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := s.sink.NewWriter(file)
 	if err != nil {
+		file.Close()
 		return nil, err
 	}
 
-	if s.maxBackups < len(fileInfos)+1 {
+	s.file = file
+	s.writer = writer
+	s.openedAt = time.Now()
+	return writer, nil
+}
+
+// closeWriter completes and closes the active record file.
+func (s *storage) closeWriter() error {
+	if s.writer == nil {
+		return nil
+	}
+
+	err := s.writer.Close()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	s.file = nil
+	s.writer = nil
+	return err
+}
+
+// rotate completes the active record file and renames it to the backup file,
+// then removes the backup files that exceed the max backups.
+func (s *storage) rotate() error {
+	if s.writer == nil {
+		return nil
+	}
+
+	if err := s.closeWriter(); err != nil {
+		return err
+	}
+
+	backupFilename := s.backupFilename()
+	if err := os.Rename(s.filename, backupFilename); err != nil {
+		return err
+	}
+
+	// Open the backup file before removing the exceeded backup files,
+	// the backup file can be uploaded even if it is removed.
+	if s.uploader != nil {
+		file, err := os.Open(backupFilename)
+		if err != nil {
+			logger.Errorf("open backup file %s failed: %s", backupFilename, err.Error())
+		} else {
+			s.uploads.Add(1)
+			go s.upload(file)
+		}
+	}
+
+	fileInfos, err := s.backups()
+	if err != nil {
+		return err
+	}
+
+	for len(fileInfos) > 0 && s.maxBackups < len(fileInfos)+1 {
 		filename := filepath.Join(s.baseDir, fileInfos[0].Name())
 		if err := os.Remove(filename); err != nil {
-			return nil, err
+			return err
 		}
+
+		fileInfos = fileInfos[1:]
 	}
 
-	file, err := os.OpenFile(s.filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
-	if err != nil {
-		return nil, err
+	return nil
+}
+
+// upload uploads the backup file by uploader.
+func (s *storage) upload(file *os.File) {
+	defer s.uploads.Done()
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+
+	name := filepath.Base(file.Name())
+	if err := s.uploader.Upload(ctx, name, file); err != nil {
+		logger.Errorf("upload backup file %s failed: %s", name, err.Error())
+		return
 	}
 
-	return file, nil
+	logger.Infof("upload backup file %s successfully", name)
 }
 
 // backupFilename generates file name of backup files.
 func (s *storage) backupFilename() string {
 	timestamp := time.Now().Format(backupTimeFormat)
-	return filepath.Join(s.baseDir, fmt.Sprintf("%s-%s.%s", RecordFilePrefix, timestamp, RecordFileExt))
+	return filepath.Join(s.baseDir, fmt.Sprintf("%s-%s.%s", RecordFilePrefix, timestamp, s.sink.Ext()))
 }
 
 // backupFilename returns backup file information.
@@ -286,9 +504,9 @@ func (s *storage) backups() ([]fs.FileInfo, error) {
 	}
 
 	var backups []fs.FileInfo
-	regexp := regexp.MustCompile(RecordFilePrefix)
+	ext := fmt.Sprintf(".%s", s.sink.Ext())
 	for _, fileInfo := range fileInfos {
-		if !fileInfo.IsDir() && regexp.MatchString(fileInfo.Name()) {
+		if !fileInfo.IsDir() && strings.HasPrefix(fileInfo.Name(), RecordFilePrefix) && strings.HasSuffix(fileInfo.Name(), ext) {
 			backups = append(backups, fileInfo)
 		}
 	}
@@ -17,13 +17,16 @@
 package storage
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sync"
 	"testing"
 	"time"
 
@@ -240,13 +243,13 @@ func TestStorage_Create(t *testing.T) {
 			baseDir:    os.TempDir(),
 			bufferSize: 0,
 			mock: func(s Storage) {
-				s.(*storage).baseDir = "foo"
+				s.(*storage).filename = filepath.Join("foo", fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt))
 			},
 			expect: func(t *testing.T, s Storage, baseDir string) {
 				assert := assert.New(t)
 				err := s.Create(Record{})
 				assert.Error(err)
-				s.(*storage).baseDir = baseDir
+				s.(*storage).filename = filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt))
 			},
 		},
 	}
@@ -267,11 +270,12 @@ func TestStorage_Create(t *testing.T) {
 	}
 }
 
-func TestStorage_List(t *testing.T) {
+func TestStorage_Iterate(t *testing.T) {
 	tests := []struct {
 		name       string
 		baseDir    string
 		bufferSize int
+		options    []Option
 		record     Record
 		mock       func(t *testing.T, s Storage, baseDir string, record Record)
 		expect     func(t *testing.T, s Storage, baseDir string, record Record)
@@ -283,8 +287,9 @@ func TestStorage_List(t *testing.T) {
 			mock:       func(t *testing.T, s Storage, baseDir string, record Record) {},
 			expect: func(t *testing.T, s Storage, baseDir string, record Record) {
 				assert := assert.New(t)
-				_, err := s.List()
-				assert.Error(err)
+				records, err := listRecords(s)
+				assert.NoError(err)
+				assert.Equal(len(records), 0)
 			},
 		},
 		{
@@ -296,7 +301,7 @@ func TestStorage_List(t *testing.T) {
 			},
 			expect: func(t *testing.T, s Storage, baseDir string, record Record) {
 				assert := assert.New(t)
-				_, err := s.List()
+				_, err := s.Iterate()
 				assert.Error(err)
 				s.(*storage).baseDir = baseDir
 			},
@@ -306,15 +311,24 @@ func TestStorage_List(t *testing.T) {
 			baseDir:    os.TempDir(),
 			bufferSize: config.DefaultStorageBufferSize,
 			mock: func(t *testing.T, s Storage, baseDir string, record Record) {
-				file, err := os.OpenFile(filepath.Join(baseDir, "record-test.csv"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0300)
+				file, err := os.OpenFile(filepath.Join(baseDir, "record-test.csv"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 				if err != nil {
 					t.Fatal(err)
 				}
-				file.Close()
+				defer file.Close()
+
+				if err := gocsv.MarshalWithoutHeaders([]Record{{ID: "1"}}, file); err != nil {
+					t.Fatal(err)
+				}
 			},
 			expect: func(t *testing.T, s Storage, baseDir string, record Record) {
 				assert := assert.New(t)
-				_, err := s.List()
+				iterator, err := s.Iterate()
+				assert.NoError(err)
+				defer iterator.Close()
+
+				assert.NoError(os.Remove(filepath.Join(baseDir, "record-test.csv")))
+				_, err = iterator.Next()
 				assert.Error(err)
 			},
 		},
@@ -330,15 +344,22 @@ func TestStorage_List(t *testing.T) {
 			},
 			expect: func(t *testing.T, s Storage, baseDir string, record Record) {
 				assert := assert.New(t)
-				_, err := s.List()
-				assert.Error(err)
+				records, err := listRecords(s)
+				assert.NoError(err)
+				assert.Equal(len(records), 0)
 
+				// Records of active record file are not listed before rotated.
 				if err := s.Create(record); err != nil {
 					t.Fatal(err)
 				}
-				records, err := s.List()
+				records, err = listRecords(s)
 				assert.NoError(err)
-				assert.Equal(len(records), 1)
+				assert.Equal(len(records), 0)
+
+				assert.NoError(s.Close())
+				records, err = listRecords(s)
+				assert.NoError(err)
+				assert.Equal(len(records), 2)
 				assert.EqualValues(records[0], record)
 			},
 		},
@@ -358,6 +379,11 @@ func TestStorage_List(t *testing.T) {
 					t.Fatal(err)
 				}
 
+				modTime := time.Now().Add(-time.Minute)
+				if err := os.Chtimes(file.Name(), modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+
 				if err := s.Create(Record{ID: "1"}); err != nil {
 					t.Fatal(err)
 				}
@@ -368,18 +394,45 @@ func TestStorage_List(t *testing.T) {
 			},
 			expect: func(t *testing.T, s Storage, baseDir string, record Record) {
 				assert := assert.New(t)
-				records, err := s.List()
+				records, err := listRecords(s)
 				assert.NoError(err)
-				assert.Equal(len(records), 2)
+				assert.Equal(len(records), 1)
+				assert.Equal(records[0].ID, "2")
+
+				assert.NoError(s.Close())
+				records, err = listRecords(s)
+				assert.NoError(err)
+				assert.Equal(len(records), 3)
 				assert.Equal(records[0].ID, "2")
 				assert.Equal(records[1].ID, "1")
+				assert.Equal(records[2].ID, "3")
+			},
+		},
+		{
+			name:       "list records of active record file rotated by max age",
+			baseDir:    os.TempDir(),
+			bufferSize: config.DefaultStorageBufferSize,
+			options:    []Option{WithMaxAge(0), WithFlushInterval(10 * time.Millisecond)},
+			record:     mockRecord,
+			mock: func(t *testing.T, s Storage, baseDir string, record Record) {
+				if err := s.Create(record); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expect: func(t *testing.T, s Storage, baseDir string, record Record) {
+				assert := assert.New(t)
+				assert.Eventually(func() bool {
+					records, err := listRecords(s)
+					return err == nil && len(records) == 1
+				}, time.Second, 10*time.Millisecond)
+				assert.NoError(s.Close())
 			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			s, err := New(tc.baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, tc.bufferSize)
+			s, err := New(tc.baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, tc.bufferSize, tc.options...)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -445,6 +498,7 @@ func TestStorage_Open(t *testing.T) {
 				if err := s.Create(record); err != nil {
 					t.Fatal(err)
 				}
+				assert.NoError(s.Close())
 
 				readCloser, err := s.Open()
 				assert.NoError(err)
@@ -452,7 +506,7 @@ func TestStorage_Open(t *testing.T) {
 				var records []Record
 				err = gocsv.UnmarshalWithoutHeaders(readCloser, &records)
 				assert.NoError(err)
-				assert.Equal(len(records), 1)
+				assert.Equal(len(records), 2)
 				assert.EqualValues(records[0], record)
 			},
 		},
@@ -472,6 +526,11 @@ func TestStorage_Open(t *testing.T) {
 					t.Fatal(err)
 				}
 
+				modTime := time.Now().Add(-time.Minute)
+				if err := os.Chtimes(file.Name(), modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+
 				if err := s.Create(Record{ID: "1"}); err != nil {
 					t.Fatal(err)
 				}
@@ -488,9 +547,20 @@ func TestStorage_Open(t *testing.T) {
 				var records []Record
 				err = gocsv.UnmarshalWithoutHeaders(readCloser, &records)
 				assert.NoError(err)
-				assert.Equal(len(records), 2)
+				assert.Equal(len(records), 1)
+				assert.Equal(records[0].ID, "2")
+
+				assert.NoError(s.Close())
+				readCloser, err = s.Open()
+				assert.NoError(err)
+
+				records = nil
+				err = gocsv.UnmarshalWithoutHeaders(readCloser, &records)
+				assert.NoError(err)
+				assert.Equal(len(records), 3)
 				assert.Equal(records[0].ID, "2")
 				assert.Equal(records[1].ID, "1")
+				assert.Equal(records[2].ID, "3")
 			},
 		},
 	}
@@ -588,13 +658,13 @@ func TestStorage_create(t *testing.T) {
 			name:    "open file failed",
 			baseDir: os.TempDir(),
 			mock: func(s Storage) {
-				s.(*storage).baseDir = "foo"
+				s.(*storage).filename = filepath.Join("foo", fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt))
 			},
 			expect: func(t *testing.T, s Storage, baseDir string) {
 				assert := assert.New(t)
 				err := s.(*storage).create(Record{})
 				assert.Error(err)
-				s.(*storage).baseDir = baseDir
+				s.(*storage).filename = filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt))
 			},
 		},
 	}
@@ -615,7 +685,7 @@ func TestStorage_create(t *testing.T) {
 	}
 }
 
-func TestStorage_openFile(t *testing.T) {
+func TestStorage_openWriter(t *testing.T) {
 	tests := []struct {
 		name       string
 		baseDir    string
@@ -633,13 +703,13 @@ func TestStorage_openFile(t *testing.T) {
 			maxBackups: config.DefaultStorageMaxBackups,
 			bufferSize: config.DefaultStorageBufferSize,
 			mock: func(t *testing.T, s Storage) {
-				s.(*storage).baseDir = "bat"
+				s.(*storage).filename = filepath.Join("bat", fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt))
 			},
 			expect: func(t *testing.T, s Storage, baseDir string) {
 				assert := assert.New(t)
-				_, err := s.(*storage).openFile()
+				_, err := s.(*storage).openWriter()
 				assert.Error(err)
-				s.(*storage).baseDir = baseDir
+				s.(*storage).filename = filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt))
 			},
 		},
 		{
@@ -659,10 +729,13 @@ func TestStorage_openFile(t *testing.T) {
 			},
 			expect: func(t *testing.T, s Storage, baseDir string) {
 				assert := assert.New(t)
-				file, err := s.(*storage).openFile()
+				_, err := s.(*storage).openWriter()
 				assert.NoError(err)
-				assert.Equal(file.Name(), filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, RecordFileExt)))
-				file.Close()
+				assert.Equal(s.(*storage).file.Name(), filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt)))
+
+				fileInfos, err := s.(*storage).backups()
+				assert.NoError(err)
+				assert.Equal(len(fileInfos), 2)
 			},
 		},
 		{
@@ -675,13 +748,21 @@ func TestStorage_openFile(t *testing.T) {
 				if err := s.Create(Record{ID: "1"}); err != nil {
 					t.Fatal(err)
 				}
+
+				if err := s.Create(Record{ID: "2"}); err != nil {
+					t.Fatal(err)
+				}
 			},
 			expect: func(t *testing.T, s Storage, baseDir string) {
 				assert := assert.New(t)
-				file, err := s.(*storage).openFile()
+				_, err := s.(*storage).openWriter()
+				assert.NoError(err)
+				assert.Equal(s.(*storage).file.Name(), filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt)))
+
+				fileInfos, err := s.(*storage).backups()
 				assert.NoError(err)
-				assert.Equal(file.Name(), filepath.Join(baseDir, fmt.Sprintf("%s.%s", RecordFilePrefix, RecordFileExt)))
-				file.Close()
+				assert.Equal(len(fileInfos), 1)
+				assert.Equal(fileInfos[0].Name(), fmt.Sprintf("%s.%s", RecordFilePrefix, CSVFileExt))
 			},
 		},
 	}
@@ -710,7 +791,7 @@ func TestStorage_backupFilename(t *testing.T) {
 	}
 
 	filename := s.(*storage).backupFilename()
-	regexp := regexp.MustCompile(fmt.Sprintf("%s-.*.%s$", RecordFilePrefix, RecordFileExt))
+	regexp := regexp.MustCompile(fmt.Sprintf("%s-.*.%s$", RecordFilePrefix, CSVFileExt))
 	assert := assert.New(t)
 	assert.True(regexp.MatchString(filename))
 
@@ -770,3 +851,172 @@ func TestStorage_backups(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_WithSink(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+	}{
+		{
+			name:   "csv record file",
+			format: FormatCSV,
+		},
+		{
+			name:   "json lines record file",
+			format: FormatJSONL,
+		},
+		{
+			name:   "parquet record file",
+			format: FormatParquet,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			sink, err := NewSink(tc.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0, WithSink(sink))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 3; i++ {
+				if err := s.Create(mockRecord); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			records, err := listRecords(s)
+			assert.NoError(err)
+			assert.Equal(len(records), 0)
+
+			assert.NoError(s.Close())
+			records, err = listRecords(s)
+			assert.NoError(err)
+			assert.Equal(len(records), 3)
+			for _, record := range records {
+				assert.EqualValues(record, mockRecord)
+			}
+
+			assert.Equal(s.Count(), int64(3))
+			if err := s.Clear(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestStorage_WithUploader(t *testing.T) {
+	uploader := &mockUploader{}
+	s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0, WithUploader(uploader), WithMaxAge(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The active record file is rotated by max age when the next record is written.
+	for i := 0; i < 2; i++ {
+		if err := s.Create(mockRecord); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert := assert.New(t)
+	assert.Equal(len(uploader.names), 2)
+	for _, name := range uploader.names {
+		assert.Regexp(fmt.Sprintf("^%s-.*.%s$", RecordFilePrefix, CSVFileExt), name)
+	}
+}
+
+func TestStorage_ClearWithPendingUploads(t *testing.T) {
+	uploader := &mockUploader{block: make(chan struct{})}
+	s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0, WithUploader(uploader), WithMaxAge(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The active record file is rotated by max age when the next record is written.
+	for i := 0; i < 2; i++ {
+		if err := s.Create(mockRecord); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cleared := make(chan error)
+	go func() {
+		cleared <- s.Clear()
+	}()
+
+	// Records can be created and iterated while clear is waiting for the pending uploads.
+	assert := assert.New(t)
+	assert.Eventually(func() bool {
+		_, err := s.(*storage).backups()
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(s.Create(mockRecord))
+	_, err = listRecords(s)
+	assert.NoError(err)
+
+	select {
+	case <-cleared:
+		t.Fatal("clear returns before uploads are finished")
+	default:
+	}
+
+	close(uploader.block)
+	assert.NoError(<-cleared)
+	assert.NoError(s.Clear())
+}
+
+// mockUploader records the names of uploaded record files.
+type mockUploader struct {
+	mu    sync.Mutex
+	names []string
+
+	// block blocks uploading until it is closed, if it is not nil.
+	block chan struct{}
+}
+
+func (u *mockUploader) Upload(ctx context.Context, name string, file *os.File) error {
+	if u.block != nil {
+		<-u.block
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.names = append(u.names, name)
+	return nil
+}
+
+func listRecords(s Storage) ([]Record, error) {
+	iterator, err := s.Iterate()
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	var records []Record
+	for {
+		record, err := iterator.Next()
+		if err != nil {
+			if err == io.EOF {
+				return records, nil
+			}
+
+			return nil, err
+		}
+
+		records = append(records, *record)
+	}
+}
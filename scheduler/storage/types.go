@@ -19,296 +19,296 @@ package storage
 // Task contains content for task.
 type Task struct {
 	// ID is task id.
-	ID string `csv:"id"`
+	ID string `csv:"id" json:"id" parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// URL is task download url.
-	URL string `csv:"url"`
+	URL string `csv:"url" json:"url" parquet:"name=url, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Type is task type.
-	Type string `csv:"type"`
+	Type string `csv:"type" json:"type" parquet:"name=type, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// ContentLength is task total content length.
-	ContentLength int64 `csv:"contentLength"`
+	ContentLength int64 `csv:"contentLength" json:"contentLength" parquet:"name=contentLength, type=INT64"`
 
 	// TotalPieceCount is total piece count.
-	TotalPieceCount int32 `csv:"totalPieceCount"`
+	TotalPieceCount int32 `csv:"totalPieceCount" json:"totalPieceCount" parquet:"name=totalPieceCount, type=INT32"`
 
 	// BackToSourceLimit is back-to-source limit.
-	BackToSourceLimit int32 `csv:"backToSourceLimit"`
+	BackToSourceLimit int32 `csv:"backToSourceLimit" json:"backToSourceLimit" parquet:"name=backToSourceLimit, type=INT32"`
 
 	// BackToSourcePeerCount is back-to-source peer count.
-	BackToSourcePeerCount int32 `csv:"backToSourcePeerCount"`
+	BackToSourcePeerCount int32 `csv:"backToSourcePeerCount" json:"backToSourcePeerCount" parquet:"name=backToSourcePeerCount, type=INT32"`
 
 	// State is the download state of the task.
-	State string `csv:"state"`
+	State string `csv:"state" json:"state" parquet:"name=state, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// CreatedAt is peer create nanosecond time.
-	CreatedAt int64 `csv:"createdAt"`
+	CreatedAt int64 `csv:"createdAt" json:"createdAt" parquet:"name=createdAt, type=INT64"`
 
 	// UpdatedAt is peer update nanosecond time.
-	UpdatedAt int64 `csv:"updatedAt"`
+	UpdatedAt int64 `csv:"updatedAt" json:"updatedAt" parquet:"name=updatedAt, type=INT64"`
 }
 
 // Host contains content for host.
 type Host struct {
 	// ID is host id.
-	ID string `csv:"id"`
+	ID string `csv:"id" json:"id" parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Type is host type.
-	Type string `csv:"type"`
+	Type string `csv:"type" json:"type" parquet:"name=type, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Hostname is host name.
-	Hostname string `csv:"hostname"`
+	Hostname string `csv:"hostname" json:"hostname" parquet:"name=hostname, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// IP is host ip.
-	IP string `csv:"ip"`
+	IP string `csv:"ip" json:"ip" parquet:"name=ip, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Port is grpc service port.
-	Port int32 `csv:"port"`
+	Port int32 `csv:"port" json:"port" parquet:"name=port, type=INT32"`
 
 	// DownloadPort is piece downloading port.
-	DownloadPort int32 `csv:"downloadPort"`
+	DownloadPort int32 `csv:"downloadPort" json:"downloadPort" parquet:"name=downloadPort, type=INT32"`
 
 	// Host OS.
-	OS string `csv:"os"`
+	OS string `csv:"os" json:"os" parquet:"name=os, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Host platform.
-	Platform string `csv:"platform"`
+	Platform string `csv:"platform" json:"platform" parquet:"name=platform, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Host platform family.
-	PlatformFamily string `csv:"platformFamily"`
+	PlatformFamily string `csv:"platformFamily" json:"platformFamily" parquet:"name=platformFamily, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Host platform version.
-	PlatformVersion string `csv:"platformVersion"`
+	PlatformVersion string `csv:"platformVersion" json:"platformVersion" parquet:"name=platformVersion, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Host kernel version.
-	KernelVersion string `csv:"kernelVersion"`
+	KernelVersion string `csv:"kernelVersion" json:"kernelVersion" parquet:"name=kernelVersion, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// ConcurrentUploadLimit is concurrent upload limit count.
-	ConcurrentUploadLimit int32 `csv:"concurrentUploadLimit"`
+	ConcurrentUploadLimit int32 `csv:"concurrentUploadLimit" json:"concurrentUploadLimit" parquet:"name=concurrentUploadLimit, type=INT32"`
 
 	// ConcurrentUploadCount is concurrent upload count.
-	ConcurrentUploadCount int32 `csv:"concurrentUploadCount"`
+	ConcurrentUploadCount int32 `csv:"concurrentUploadCount" json:"concurrentUploadCount" parquet:"name=concurrentUploadCount, type=INT32"`
 
 	// UploadCount is total upload count.
-	UploadCount int64 `csv:"uploadCount"`
+	UploadCount int64 `csv:"uploadCount" json:"uploadCount" parquet:"name=uploadCount, type=INT64"`
 
 	// UploadFailedCount is upload failed count.
-	UploadFailedCount int64 `csv:"uploadFailedCount"`
+	UploadFailedCount int64 `csv:"uploadFailedCount" json:"uploadFailedCount" parquet:"name=uploadFailedCount, type=INT64"`
 
 	// CPU Stat.
-	CPU CPU `csv:"cpu"`
+	CPU CPU `csv:"cpu" json:"cpu" parquet:"name=cpu"`
 
 	// Memory Stat.
-	Memory Memory `csv:"memory"`
+	Memory Memory `csv:"memory" json:"memory" parquet:"name=memory"`
 
 	// Network Stat.
-	Network Network `csv:"network"`
+	Network Network `csv:"network" json:"network" parquet:"name=network"`
 
 	// Disk Stat.
-	Disk Disk `csv:"disk"`
+	Disk Disk `csv:"disk" json:"disk" parquet:"name=disk"`
 
 	// Build information.
-	Build Build `csv:"build"`
+	Build Build `csv:"build" json:"build" parquet:"name=build"`
 
 	// CreatedAt is peer create nanosecond time.
-	CreatedAt int64 `csv:"createdAt"`
+	CreatedAt int64 `csv:"createdAt" json:"createdAt" parquet:"name=createdAt, type=INT64"`
 
 	// UpdatedAt is peer update nanosecond time.
-	UpdatedAt int64 `csv:"updatedAt"`
+	UpdatedAt int64 `csv:"updatedAt" json:"updatedAt" parquet:"name=updatedAt, type=INT64"`
 }
 
 // CPU contains content for cpu.
 type CPU struct {
 	// Number of logical cores in the system.
-	LogicalCount uint32 `csv:"logicalCount"`
+	LogicalCount uint32 `csv:"logicalCount" json:"logicalCount" parquet:"name=logicalCount, type=INT32, convertedtype=UINT_32"`
 
 	// Number of physical cores in the system.
-	PhysicalCount uint32 `csv:"physicalCount"`
+	PhysicalCount uint32 `csv:"physicalCount" json:"physicalCount" parquet:"name=physicalCount, type=INT32, convertedtype=UINT_32"`
 
 	// Percent calculates the percentage of cpu used.
-	Percent float64 `csv:"percent"`
+	Percent float64 `csv:"percent" json:"percent" parquet:"name=percent, type=DOUBLE"`
 
 	// Calculates the percentage of cpu used by process.
-	ProcessPercent float64 `csv:"processPercent"`
+	ProcessPercent float64 `csv:"processPercent" json:"processPercent" parquet:"name=processPercent, type=DOUBLE"`
 
 	// Times contains the amounts of time the CPU has spent performing different kinds of work.
-	Times CPUTimes `csv:"times"`
+	Times CPUTimes `csv:"times" json:"times" parquet:"name=times"`
 }
 
 // CPUTimes contains content for cpu times.
 type CPUTimes struct {
 	// CPU time of user.
-	User float64 `csv:"user"`
+	User float64 `csv:"user" json:"user" parquet:"name=user, type=DOUBLE"`
 
 	// CPU time of system.
-	System float64 `csv:"system"`
+	System float64 `csv:"system" json:"system" parquet:"name=system, type=DOUBLE"`
 
 	// CPU time of idle.
-	Idle float64 `csv:"idle"`
+	Idle float64 `csv:"idle" json:"idle" parquet:"name=idle, type=DOUBLE"`
 
 	// CPU time of nice.
-	Nice float64 `csv:"nice"`
+	Nice float64 `csv:"nice" json:"nice" parquet:"name=nice, type=DOUBLE"`
 
 	// CPU time of iowait.
-	Iowait float64 `csv:"iowait"`
+	Iowait float64 `csv:"iowait" json:"iowait" parquet:"name=iowait, type=DOUBLE"`
 
 	// CPU time of irq.
-	Irq float64 `csv:"irq"`
+	Irq float64 `csv:"irq" json:"irq" parquet:"name=irq, type=DOUBLE"`
 
 	// CPU time of softirq.
-	Softirq float64 `csv:"softirq"`
+	Softirq float64 `csv:"softirq" json:"softirq" parquet:"name=softirq, type=DOUBLE"`
 
 	// CPU time of steal.
-	Steal float64 `csv:"steal"`
+	Steal float64 `csv:"steal" json:"steal" parquet:"name=steal, type=DOUBLE"`
 
 	// CPU time of guest.
-	Guest float64 `csv:"guest"`
+	Guest float64 `csv:"guest" json:"guest" parquet:"name=guest, type=DOUBLE"`
 
 	// CPU time of guest nice.
-	GuestNice float64 `csv:"guestNice"`
+	GuestNice float64 `csv:"guestNice" json:"guestNice" parquet:"name=guestNice, type=DOUBLE"`
 }
 
 // Memory contains content for memory.
 type Memory struct {
 	// Total amount of RAM on this system.
-	Total uint64 `csv:"total"`
+	Total uint64 `csv:"total" json:"total" parquet:"name=total, type=INT64, convertedtype=UINT_64"`
 
 	// RAM available for programs to allocate.
-	Available uint64 `csv:"available"`
+	Available uint64 `csv:"available" json:"available" parquet:"name=available, type=INT64, convertedtype=UINT_64"`
 
 	// RAM used by programs.
-	Used uint64 `csv:"used"`
+	Used uint64 `csv:"used" json:"used" parquet:"name=used, type=INT64, convertedtype=UINT_64"`
 
 	// Percentage of RAM used by programs.
-	UsedPercent float64 `csv:"usedPercent"`
+	UsedPercent float64 `csv:"usedPercent" json:"usedPercent" parquet:"name=usedPercent, type=DOUBLE"`
 
 	// Calculates the percentage of memory used by process.
-	ProcessUsedPercent float64 `csv:"processUsedPercent"`
+	ProcessUsedPercent float64 `csv:"processUsedPercent" json:"processUsedPercent" parquet:"name=processUsedPercent, type=DOUBLE"`
 
 	// This is the kernel's notion of free memory.
-	Free uint64 `csv:"free"`
+	Free uint64 `csv:"free" json:"free" parquet:"name=free, type=INT64, convertedtype=UINT_64"`
 }
 
 // Network contains content for network.
 type Network struct {
 	// Return count of tcp connections opened and status is ESTABLISHED.
-	TCPConnectionCount uint32 `csv:"tcpConnectionCount"`
+	TCPConnectionCount uint32 `csv:"tcpConnectionCount" json:"tcpConnectionCount" parquet:"name=tcpConnectionCount, type=INT32, convertedtype=UINT_32"`
 
 	// Return count of upload tcp connections opened and status is ESTABLISHED.
-	UploadTCPConnectionCount uint32 `csv:"uploadTCPConnectionCount"`
+	UploadTCPConnectionCount uint32 `csv:"uploadTCPConnectionCount" json:"uploadTCPConnectionCount" parquet:"name=uploadTCPConnectionCount, type=INT32, convertedtype=UINT_32"`
 
 	// Security domain for network.
-	SecurityDomain string `csv:"securityDomain"`
+	SecurityDomain string `csv:"securityDomain" json:"securityDomain" parquet:"name=securityDomain, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Location path(area|country|province|city|...).
-	Location string `csv:"location"`
+	Location string `csv:"location" json:"location" parquet:"name=location, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// IDC where the peer host is located
-	IDC string `csv:"idc"`
+	IDC string `csv:"idc" json:"idc" parquet:"name=idc, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Network topology(switch|router|...).
-	NetTopology string `csv:"netTopology"`
+	NetTopology string `csv:"netTopology" json:"netTopology" parquet:"name=netTopology, type=BYTE_ARRAY, convertedtype=UTF8"`
 }
 
 // Build contains content for build.
 type Build struct {
 	// Git version.
-	GitVersion string `csv:"gitVersion"`
+	GitVersion string `csv:"gitVersion" json:"gitVersion" parquet:"name=gitVersion, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Git commit.
-	GitCommit string `csv:"gitCommit"`
+	GitCommit string `csv:"gitCommit" json:"gitCommit" parquet:"name=gitCommit, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Golang version.
-	GoVersion string `csv:"goVersion"`
+	GoVersion string `csv:"goVersion" json:"goVersion" parquet:"name=goVersion, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Build platform.
-	Platform string `csv:"platform"`
+	Platform string `csv:"platform" json:"platform" parquet:"name=platform, type=BYTE_ARRAY, convertedtype=UTF8"`
 }
 
 // Disk contains content for disk.
 type Disk struct {
 	// Total amount of disk on the data path of dragonfly.
-	Total uint64 `csv:"total"`
+	Total uint64 `csv:"total" json:"total" parquet:"name=total, type=INT64, convertedtype=UINT_64"`
 
 	// Free amount of disk on the data path of dragonfly.
-	Free uint64 `csv:"free"`
+	Free uint64 `csv:"free" json:"free" parquet:"name=free, type=INT64, convertedtype=UINT_64"`
 
 	// Used amount of disk on the data path of dragonfly.
-	Used uint64 `csv:"used"`
+	Used uint64 `csv:"used" json:"used" parquet:"name=used, type=INT64, convertedtype=UINT_64"`
 
 	// Used percent of disk on the data path of dragonfly directory.
-	UsedPercent float64 `csv:"usedPercent"`
+	UsedPercent float64 `csv:"usedPercent" json:"usedPercent" parquet:"name=usedPercent, type=DOUBLE"`
 
 	// Total amount of indoes on the data path of dragonfly directory.
-	InodesTotal uint64 `csv:"inodesTotal"`
+	InodesTotal uint64 `csv:"inodesTotal" json:"inodesTotal" parquet:"name=inodesTotal, type=INT64, convertedtype=UINT_64"`
 
 	// Used amount of indoes on the data path of dragonfly directory.
-	InodesUsed uint64 `csv:"inodesUsed"`
+	InodesUsed uint64 `csv:"inodesUsed" json:"inodesUsed" parquet:"name=inodesUsed, type=INT64, convertedtype=UINT_64"`
 
 	// Free amount of indoes on the data path of dragonfly directory.
-	InodesFree uint64 `csv:"inodesFree"`
+	InodesFree uint64 `csv:"inodesFree" json:"inodesFree" parquet:"name=inodesFree, type=INT64, convertedtype=UINT_64"`
 
 	// Used percent of indoes on the data path of dragonfly directory.
-	InodesUsedPercent float64 `csv:"inodesUsedPercent"`
+	InodesUsedPercent float64 `csv:"inodesUsedPercent" json:"inodesUsedPercent" parquet:"name=inodesUsedPercent, type=DOUBLE"`
 }
 
 // Parent contains content for parent.
 type Parent struct {
 	// ID is peer id.
-	ID string `csv:"id"`
+	ID string `csv:"id" json:"id" parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Tag is peer tag.
-	Tag string `csv:"tag"`
+	Tag string `csv:"tag" json:"tag" parquet:"name=tag, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Application is peer application.
-	Application string `csv:"application"`
+	Application string `csv:"application" json:"application" parquet:"name=application, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// State is the download state of the peer.
-	State string `csv:"state"`
+	State string `csv:"state" json:"state" parquet:"name=state, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Cost is the task download time(millisecond).
-	Cost uint32 `csv:"cost"`
+	Cost uint32 `csv:"cost" json:"cost" parquet:"name=cost, type=INT32, convertedtype=UINT_32"`
 
 	// Host is peer host.
-	Host Host `csv:"host"`
+	Host Host `csv:"host" json:"host" parquet:"name=host"`
 
 	// CreatedAt is peer create nanosecond time.
-	CreatedAt int64 `csv:"createdAt"`
+	CreatedAt int64 `csv:"createdAt" json:"createdAt" parquet:"name=createdAt, type=INT64"`
 
 	// UpdatedAt is peer update nanosecond time.
-	UpdatedAt int64 `csv:"updatedAt"`
+	UpdatedAt int64 `csv:"updatedAt" json:"updatedAt" parquet:"name=updatedAt, type=INT64"`
 }
 
 // Record contains content for record.
 type Record struct {
 	// ID is peer id.
-	ID string `csv:"id"`
+	ID string `csv:"id" json:"id" parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Tag is peer tag.
-	Tag string `csv:"tag"`
+	Tag string `csv:"tag" json:"tag" parquet:"name=tag, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Application is peer application.
-	Application string `csv:"application"`
+	Application string `csv:"application" json:"application" parquet:"name=application, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// State is the download state of the peer.
-	State string `csv:"state"`
+	State string `csv:"state" json:"state" parquet:"name=state, type=BYTE_ARRAY, convertedtype=UTF8"`
 
 	// Cost is the task download time(millisecond).
-	Cost uint32 `csv:"cost"`
+	Cost uint32 `csv:"cost" json:"cost" parquet:"name=cost, type=INT32, convertedtype=UINT_32"`
 
 	// Task is peer task.
-	Task Task `csv:"task"`
+	Task Task `csv:"task" json:"task" parquet:"name=task"`
 
 	// Host is peer host.
-	Host Host `csv:"host"`
+	Host Host `csv:"host" json:"host" parquet:"name=host"`
 
 	// Parents is peer parents.
-	Parents []Parent `csv:"parents" csv[]:"20"`
+	Parents []Parent `csv:"parents" csv[]:"20" json:"parents" parquet:"name=parents, repetitiontype=REPEATED"`
 
 	// CreatedAt is peer create nanosecond time.
-	CreatedAt int64 `csv:"createdAt"`
+	CreatedAt int64 `csv:"createdAt" json:"createdAt" parquet:"name=createdAt, type=INT64"`
 
 	// UpdatedAt is peer update nanosecond time.
-	UpdatedAt int64 `csv:"updatedAt"`
+	UpdatedAt int64 `csv:"updatedAt" json:"updatedAt" parquet:"name=updatedAt, type=INT64"`
 }
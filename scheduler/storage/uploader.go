@@ -0,0 +1,69 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/uploader_mock.go -source uploader.go -package mocks
+
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+
+	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/objectstorage"
+)
+
+// Uploader is the interface used for uploading the rotated record files.
+type Uploader interface {
+	// Upload uploads the record file with the name.
+	Upload(ctx context.Context, name string, file *os.File) error
+}
+
+// objectStorageUploader uploads record files to object storage.
+type objectStorageUploader struct {
+	objectStorage objectstorage.ObjectStorage
+	bucketName    string
+	prefix        string
+}
+
+// NewObjectStorageUploader returns a new Uploader of object storage, the object key
+// of record file is the name with the prefix.
+func NewObjectStorageUploader(objectStorage objectstorage.ObjectStorage, bucketName, prefix string) Uploader {
+	return &objectStorageUploader{
+		objectStorage: objectStorage,
+		bucketName:    bucketName,
+		prefix:        prefix,
+	}
+}
+
+// Upload puts the record file into the bucket with the sha256 digest.
+func (u *objectStorageUploader) Upload(ctx context.Context, name string, file *os.File) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	dgst := digest.New(digest.AlgorithmSHA256, hex.EncodeToString(h.Sum(nil)))
+	return u.objectStorage.PutObject(ctx, u.bucketName, path.Join(u.prefix, name), dgst.String(), file)
+}
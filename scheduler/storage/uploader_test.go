@@ -0,0 +1,96 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/objectstorage/mocks"
+)
+
+func TestObjectStorageUploader_Upload(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(m *mocks.MockObjectStorageMockRecorder)
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "upload record file",
+			mock: func(m *mocks.MockObjectStorageMockRecorder) {
+				m.PutObject(gomock.Any(), "foo", "bar/record.csv",
+					"sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, bucketName, objectKey, digest string, reader io.Reader) error {
+						data, err := io.ReadAll(reader)
+						if err != nil {
+							return err
+						}
+
+						if string(data) != "foo" {
+							return errors.New("invalid data")
+						}
+
+						return nil
+					}).Times(1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "put object failed",
+			mock: func(m *mocks.MockObjectStorageMockRecorder) {
+				m.PutObject(gomock.Any(), "foo", "bar/record.csv", gomock.Any(), gomock.Any()).Return(errors.New("baz")).Times(1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "baz")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			objectStorage := mocks.NewMockObjectStorage(ctl)
+			tc.mock(objectStorage.EXPECT())
+
+			filename := filepath.Join(t.TempDir(), "record.csv")
+			if err := os.WriteFile(filename, []byte("foo"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := os.Open(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			uploader := NewObjectStorageUploader(objectStorage, "foo", "bar")
+			tc.expect(t, uploader.Upload(context.Background(), "record.csv", file))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package training
+
+import (
+	pkgtypes "d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/scheduler/evaluator"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+const (
+	// minFeatureScore is the minimum score of feature.
+	minFeatureScore = 0
+
+	// maxFeatureScore is the maximum score of feature.
+	maxFeatureScore = 1
+)
+
+// newRecordFeatures returns the features of parent for child by the download record,
+// it is consistent with the features of evaluator.NewFeatures.
+func newRecordFeatures(parent *storage.Parent, child *storage.Host) *evaluator.Features {
+	features := &evaluator.Features{
+		IDCAffinityScore:         evaluator.CalculateIDCAffinityScore(parent.Host.Network.IDC, child.Network.IDC),
+		NetTopologyAffinityScore: evaluator.CalculateMultiElementAffinityScore(parent.Host.Network.NetTopology, child.Network.NetTopology),
+		LocationAffinityScore:    evaluator.CalculateMultiElementAffinityScore(parent.Host.Network.Location, child.Network.Location),
+		CPUPercent:               parent.Host.CPU.Percent / 100,
+		MemoryUsedPercent:        parent.Host.Memory.UsedPercent / 100,
+		DiskUsedPercent:          parent.Host.Disk.UsedPercent / 100,
+	}
+
+	if limit := parent.Host.ConcurrentUploadLimit; limit > 0 && limit > parent.Host.ConcurrentUploadCount {
+		features.FreeUploadScore = float64(limit-parent.Host.ConcurrentUploadCount) / float64(limit)
+	}
+
+	switch uploadCount, uploadFailedCount := parent.Host.UploadCount, parent.Host.UploadFailedCount; {
+	case uploadCount < uploadFailedCount:
+		features.UploadSuccessScore = minFeatureScore
+	case uploadCount == 0 && uploadFailedCount == 0:
+		features.UploadSuccessScore = maxFeatureScore
+	default:
+		features.UploadSuccessScore = float64(uploadCount-uploadFailedCount) / float64(uploadCount)
+	}
+
+	if parent.Host.Type != pkgtypes.HostTypeNormalName {
+		features.IsSeedPeer = maxFeatureScore
+	}
+
+	return features
+}
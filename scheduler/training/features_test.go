@@ -0,0 +1,99 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package training
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pkgtypes "d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/scheduler/evaluator"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+func TestTraining_newRecordFeatures(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent storage.Parent
+		child  storage.Host
+		expect func(t *testing.T, features *evaluator.Features)
+	}{
+		{
+			name: "parent is seed peer",
+			parent: storage.Parent{
+				Host: storage.Host{
+					Type:                  pkgtypes.HostTypeSuperSeedName,
+					ConcurrentUploadLimit: 100,
+					ConcurrentUploadCount: 20,
+					UploadCount:           10,
+					UploadFailedCount:     2,
+					CPU:                   storage.CPU{Percent: 50},
+					Memory:                storage.Memory{UsedPercent: 40},
+					Disk:                  storage.Disk{UsedPercent: 20},
+					Network: storage.Network{
+						IDC:         "foo",
+						NetTopology: "a|b",
+						Location:    "c|d",
+					},
+				},
+			},
+			child: storage.Host{
+				Network: storage.Network{
+					IDC:         "foo",
+					NetTopology: "a|b",
+					Location:    "c|e",
+				},
+			},
+			expect: func(t *testing.T, features *evaluator.Features) {
+				assert := assert.New(t)
+				assert.EqualValues(features, &evaluator.Features{
+					FreeUploadScore:          0.8,
+					UploadSuccessScore:       0.8,
+					IsSeedPeer:               1,
+					IDCAffinityScore:         1,
+					NetTopologyAffinityScore: 1,
+					LocationAffinityScore:    0.2,
+					CPUPercent:               0.5,
+					MemoryUsedPercent:        0.4,
+					DiskUsedPercent:          0.2,
+				})
+			},
+		},
+		{
+			name: "parent is normal peer without uploads",
+			parent: storage.Parent{
+				Host: storage.Host{
+					Type: pkgtypes.HostTypeNormalName,
+				},
+			},
+			child: storage.Host{},
+			expect: func(t *testing.T, features *evaluator.Features) {
+				assert := assert.New(t)
+				assert.EqualValues(features, &evaluator.Features{
+					UploadSuccessScore: 1,
+				})
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, newRecordFeatures(&tc.parent, &tc.child))
+		})
+	}
+}
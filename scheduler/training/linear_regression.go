@@ -0,0 +1,259 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package training
+
+import (
+	"errors"
+	"math"
+	"sync"
+
+	"d7y.io/dragonfly/v2/scheduler/scheduler/evaluator"
+)
+
+const (
+	// ridgeLambda is the l2 regularization of linear regression,
+	// it keeps the normal equation solvable when a feature is constant.
+	ridgeLambda = 1e-3
+)
+
+// Sample is the features and the piece cost of a parent.
+type Sample struct {
+	// Features is the features of parent for child.
+	Features *evaluator.Features
+
+	// Cost is the piece download cost(millisecond).
+	Cost float64
+}
+
+// Metrics is the evaluation result of model.
+type Metrics struct {
+	// MAE is mean absolute error.
+	MAE float64
+
+	// MSE is mean squared error.
+	MSE float64
+
+	// RMSE is root mean squared error.
+	RMSE float64
+
+	// R2 is coefficient of determination.
+	R2 float64
+}
+
+// FitLinearRegression fits a ridge linear regression on standardized features,
+// the normal equation is accumulated by cpu goroutines.
+func FitLinearRegression(samples []Sample, cpu int) (*evaluator.LinearRegressionModel, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("samples are empty")
+	}
+
+	if cpu <= 0 {
+		cpu = 1
+	}
+
+	n := evaluator.FeatureCount
+	xs := make([][]float64, len(samples))
+	for i, sample := range samples {
+		xs[i] = sample.Features.Vector()
+	}
+
+	// Standardize the features by mean and standard deviation.
+	mean := make([]float64, n)
+	standardDeviation := make([]float64, n)
+	var bias float64
+	for k, x := range xs {
+		for i, v := range x {
+			mean[i] += v
+		}
+		bias += samples[k].Cost
+	}
+
+	for i := range mean {
+		mean[i] /= float64(len(samples))
+	}
+	bias /= float64(len(samples))
+
+	for _, x := range xs {
+		for i, v := range x {
+			standardDeviation[i] += (v - mean[i]) * (v - mean[i])
+		}
+	}
+
+	for i := range standardDeviation {
+		standardDeviation[i] = math.Sqrt(standardDeviation[i] / float64(len(samples)))
+	}
+
+	standardize := func(x []float64) []float64 {
+		z := make([]float64, n)
+		for i, v := range x {
+			if standardDeviation[i] > 0 {
+				z[i] = (v - mean[i]) / standardDeviation[i]
+			} else {
+				z[i] = v - mean[i]
+			}
+		}
+
+		return z
+	}
+
+	// Accumulate the normal equation (Z^T*Z + lambda*I)*w = Z^T*(y - bias) by partitions.
+	var (
+		a  = newMatrix(n)
+		b  = make([]float64, n)
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	size := (len(samples) + cpu - 1) / cpu
+	for start := 0; start < len(samples); start += size {
+		end := start + size
+		if end > len(samples) {
+			end = len(samples)
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+
+			pa := newMatrix(n)
+			pb := make([]float64, n)
+			for k := start; k < end; k++ {
+				z := standardize(xs[k])
+				y := samples[k].Cost - bias
+				for i := 0; i < n; i++ {
+					for j := 0; j < n; j++ {
+						pa[i][j] += z[i] * z[j]
+					}
+					pb[i] += z[i] * y
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					a[i][j] += pa[i][j]
+				}
+				b[i] += pb[i]
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		a[i][i] += ridgeLambda * float64(len(samples))
+	}
+
+	weights, err := solve(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evaluator.LinearRegressionModel{
+		Type:              evaluator.LinearRegressionModelType,
+		Weights:           weights,
+		Bias:              bias,
+		Mean:              mean,
+		StandardDeviation: standardDeviation,
+	}, nil
+}
+
+// Evaluate returns the metrics of model on the samples.
+func Evaluate(model *evaluator.LinearRegressionModel, samples []Sample) (*Metrics, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("samples are empty")
+	}
+
+	var mean float64
+	for _, sample := range samples {
+		mean += sample.Cost
+	}
+	mean /= float64(len(samples))
+
+	var absoluteError, squaredError, totalSquares float64
+	for _, sample := range samples {
+		cost, err := model.Predict(sample.Features)
+		if err != nil {
+			return nil, err
+		}
+
+		absoluteError += math.Abs(cost - sample.Cost)
+		squaredError += (cost - sample.Cost) * (cost - sample.Cost)
+		totalSquares += (sample.Cost - mean) * (sample.Cost - mean)
+	}
+
+	metrics := &Metrics{
+		MAE: absoluteError / float64(len(samples)),
+		MSE: squaredError / float64(len(samples)),
+	}
+	metrics.RMSE = math.Sqrt(metrics.MSE)
+
+	if totalSquares > 0 {
+		metrics.R2 = 1 - squaredError/totalSquares
+	}
+
+	return metrics, nil
+}
+
+// newMatrix returns a n*n zero matrix.
+func newMatrix(n int) [][]float64 {
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+	}
+
+	return m
+}
+
+// solve solves the linear equations a*x = b by gaussian elimination with
+// partial pivoting, a and b are modified in place.
+func solve(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, errors.New("linear equations are singular")
+		}
+
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+			b[row] -= factor * b[col]
+		}
+	}
+
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+
+	return x, nil
+}
@@ -0,0 +1,182 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package training
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/scheduler/evaluator"
+)
+
+// mockSamples returns the samples whose cost is linear with features.
+func mockSamples(n int) []Sample {
+	r := rand.New(rand.NewSource(1))
+	samples := make([]Sample, n)
+	for i := range samples {
+		features := &evaluator.Features{
+			FreeUploadScore:          r.Float64(),
+			UploadSuccessScore:       r.Float64(),
+			IsSeedPeer:               float64(r.Intn(2)),
+			IDCAffinityScore:         float64(r.Intn(2)),
+			NetTopologyAffinityScore: r.Float64(),
+			LocationAffinityScore:    r.Float64(),
+			CPUPercent:               r.Float64(),
+			MemoryUsedPercent:        r.Float64(),
+			DiskUsedPercent:          r.Float64(),
+		}
+
+		samples[i] = Sample{
+			Features: features,
+			Cost: 100 - 20*features.FreeUploadScore - 10*features.IsSeedPeer -
+				30*features.IDCAffinityScore + 40*features.CPUPercent,
+		}
+	}
+
+	return samples
+}
+
+func TestLinearRegression_FitLinearRegression(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []Sample
+		cpu     int
+		expect  func(t *testing.T, model *evaluator.LinearRegressionModel, err error)
+	}{
+		{
+			name:    "fit linear samples",
+			samples: mockSamples(1000),
+			cpu:     4,
+			expect: func(t *testing.T, model *evaluator.LinearRegressionModel, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(model.Type, evaluator.LinearRegressionModelType)
+				assert.Equal(len(model.Weights), evaluator.FeatureCount)
+
+				metrics, err := Evaluate(model, mockSamples(100))
+				assert.NoError(err)
+				assert.Less(metrics.MAE, 0.5)
+				assert.Greater(metrics.R2, 0.99)
+			},
+		},
+		{
+			name:    "fit by a goroutine",
+			samples: mockSamples(10),
+			cpu:     0,
+			expect: func(t *testing.T, model *evaluator.LinearRegressionModel, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(len(model.Weights), evaluator.FeatureCount)
+			},
+		},
+		{
+			name: "fit constant features",
+			samples: []Sample{
+				{Features: &evaluator.Features{}, Cost: 1},
+				{Features: &evaluator.Features{}, Cost: 3},
+			},
+			cpu: 1,
+			expect: func(t *testing.T, model *evaluator.LinearRegressionModel, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(model.Bias, float64(2))
+				assert.EqualValues(model.Weights, make([]float64, evaluator.FeatureCount))
+			},
+		},
+		{
+			name:    "samples are empty",
+			samples: []Sample{},
+			cpu:     1,
+			expect: func(t *testing.T, model *evaluator.LinearRegressionModel, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "samples are empty")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model, err := FitLinearRegression(tc.samples, tc.cpu)
+			tc.expect(t, model, err)
+		})
+	}
+}
+
+func TestLinearRegression_Evaluate(t *testing.T) {
+	model := &evaluator.LinearRegressionModel{
+		Type:              evaluator.LinearRegressionModelType,
+		Weights:           make([]float64, evaluator.FeatureCount),
+		Bias:              2,
+		Mean:              make([]float64, evaluator.FeatureCount),
+		StandardDeviation: make([]float64, evaluator.FeatureCount),
+	}
+
+	tests := []struct {
+		name    string
+		model   *evaluator.LinearRegressionModel
+		samples []Sample
+		expect  func(t *testing.T, metrics *Metrics, err error)
+	}{
+		{
+			name:  "evaluate model",
+			model: model,
+			samples: []Sample{
+				{Features: &evaluator.Features{}, Cost: 1},
+				{Features: &evaluator.Features{}, Cost: 5},
+			},
+			expect: func(t *testing.T, metrics *Metrics, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.EqualValues(metrics, &Metrics{
+					MAE:  2,
+					MSE:  5,
+					RMSE: 2.23606797749979,
+					R2:   -0.25,
+				})
+			},
+		},
+		{
+			name:    "samples are empty",
+			model:   model,
+			samples: nil,
+			expect: func(t *testing.T, metrics *Metrics, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "samples are empty")
+			},
+		},
+		{
+			name: "invalid model",
+			model: &evaluator.LinearRegressionModel{
+				Type: evaluator.LinearRegressionModelType,
+			},
+			samples: []Sample{{Features: &evaluator.Features{}, Cost: 1}},
+			expect: func(t *testing.T, metrics *Metrics, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics, err := Evaluate(tc.model, tc.samples)
+			tc.expect(t, metrics, err)
+		})
+	}
+}
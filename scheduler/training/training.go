@@ -0,0 +1,300 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/training_mock.go -source training.go -package mocks
+
+package training
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+	"d7y.io/dragonfly/v2/scheduler/scheduler/evaluator"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+const (
+	// MinSampleCount is the minimum count of samples for training.
+	MinSampleCount = 100
+
+	// trainingTimeout is the timeout of training and uploading a model version.
+	trainingTimeout = 1 * time.Hour
+)
+
+// Training trains the model of ml evaluator by the download records.
+type Training interface {
+	// Train fits a model by the records of storage, evaluates it against
+	// the holdout set and uploads it to manager as a new model version.
+	Train(ctx context.Context) error
+
+	// Serve trains the model periodically.
+	Serve()
+
+	// Stop stops training.
+	Stop() error
+}
+
+type training struct {
+	// Scheduler config.
+	config *config.Config
+
+	// Storage provides the download records.
+	storage storage.Storage
+
+	// Manager client stores the model versions.
+	managerClient managerclient.Client
+
+	// Dynamic config provides the scheduler id.
+	dynconfig config.DynconfigInterface
+
+	// done channel will be closed when training stops.
+	done chan struct{}
+}
+
+// New returns a new Training interface.
+func New(cfg *config.Config, storage storage.Storage, managerClient managerclient.Client, dynconfig config.DynconfigInterface) Training {
+	return &training{
+		config:        cfg,
+		storage:       storage,
+		managerClient: managerClient,
+		dynconfig:     dynconfig,
+		done:          make(chan struct{}),
+	}
+}
+
+// Train fits a model by the records of storage and uploads it to manager.
+func (t *training) Train(ctx context.Context) error {
+	data, err := t.dynconfig.Get()
+	if err != nil {
+		return err
+	}
+
+	if data.Scheduler == nil {
+		return errors.New("scheduler is not registered to manager")
+	}
+
+	samples, err := t.samples()
+	if err != nil {
+		return err
+	}
+
+	if len(samples) < MinSampleCount {
+		return fmt.Errorf("insufficient samples %d, expect at least %d", len(samples), MinSampleCount)
+	}
+
+	// Hold out a part of samples for evaluating the model.
+	rand.Shuffle(len(samples), func(i, j int) {
+		samples[i], samples[j] = samples[j], samples[i]
+	})
+	holdout := int(float64(len(samples)) * t.config.Scheduler.Training.HoldoutRatio)
+	if holdout <= 0 {
+		holdout = 1
+	}
+
+	model, err := FitLinearRegression(samples[holdout:], t.config.Scheduler.Training.CPU)
+	if err != nil {
+		return err
+	}
+
+	metrics, err := Evaluate(model, samples[:holdout])
+	if err != nil {
+		return err
+	}
+	logger.Infof("model is trained by %d samples, mae: %f, mse: %f, rmse: %f, r2: %f",
+		len(samples)-holdout, metrics.MAE, metrics.MSE, metrics.RMSE, metrics.R2)
+
+	return t.upload(ctx, data.Scheduler.Id, model, metrics)
+}
+
+// Serve trains the model periodically.
+func (t *training) Serve() {
+	tick := time.NewTicker(t.config.Scheduler.Training.RefreshModelInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			ctx, cancel := context.WithTimeout(context.Background(), trainingTimeout)
+			if err := t.Train(ctx); err != nil {
+				logger.Errorf("train model failed: %s", err.Error())
+			}
+			cancel()
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// Stop stops training.
+func (t *training) Stop() error {
+	close(t.done)
+	return nil
+}
+
+// samples returns the samples of the succeeded download records. The cost of record is the
+// download cost of the whole task, which can not be split among the parents, so only the
+// records downloaded from a single parent are sampled and labeled by the cost of the parent.
+// The records are streamed and at most max samples are kept by reservoir sampling, so every
+// sample is selected with the same probability.
+func (t *training) samples() ([]Sample, error) {
+	iterator, err := t.storage.Iterate()
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	var (
+		samples    []Sample
+		seen       int
+		maxSamples = t.config.Scheduler.Training.MaxSamples
+	)
+	for {
+		record, err := iterator.Next()
+		if err != nil {
+			if err == io.EOF {
+				return samples, nil
+			}
+
+			return nil, err
+		}
+
+		if record.State != resource.PeerStateSucceeded || record.Task.TotalPieceCount <= 0 {
+			continue
+		}
+
+		parent, ok := singleParent(record)
+		if !ok {
+			continue
+		}
+
+		seen++
+		sample := Sample{
+			Features: newRecordFeatures(parent, &record.Host),
+			Cost:     float64(record.Cost) / float64(record.Task.TotalPieceCount),
+		}
+
+		if len(samples) < maxSamples {
+			samples = append(samples, sample)
+			continue
+		}
+
+		if j := rand.Intn(seen); j < maxSamples {
+			samples[j] = sample
+		}
+	}
+}
+
+// singleParent returns the parent of record if the record is downloaded from a single parent.
+func singleParent(record *storage.Record) (*storage.Parent, bool) {
+	var parent *storage.Parent
+	for i := range record.Parents {
+		if record.Parents[i].ID == "" {
+			continue
+		}
+
+		if parent != nil {
+			return nil, false
+		}
+
+		parent = &record.Parents[i]
+	}
+
+	return parent, parent != nil
+}
+
+// upload creates the model version in manager, the model version is activated
+// if the model does not exist, or it performs better than the active model version
+// when auto refresh is enabled.
+func (t *training) upload(ctx context.Context, schedulerID uint64, model *evaluator.LinearRegressionModel, metrics *Metrics) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	modelVersion, err := t.managerClient.CreateModelVersion(ctx, &managerv1.CreateModelVersionRequest{
+		SchedulerId: schedulerID,
+		ModelId:     types.ModelIDEvaluator,
+		Data:        data,
+		Mae:         metrics.MAE,
+		Mse:         metrics.MSE,
+		Rmse:        metrics.RMSE,
+		R2:          metrics.R2,
+	})
+	if err != nil {
+		return err
+	}
+	logger.Infof("create model %s version %s", types.ModelIDEvaluator, modelVersion.VersionId)
+
+	activeModel, err := t.managerClient.GetModel(ctx, &managerv1.GetModelRequest{
+		SchedulerId: schedulerID,
+		ModelId:     types.ModelIDEvaluator,
+	})
+	if err != nil {
+		logger.Infof("get model %s failed, create model: %s", types.ModelIDEvaluator, err.Error())
+		if _, err := t.managerClient.CreateModel(ctx, &managerv1.CreateModelRequest{
+			ModelId:     types.ModelIDEvaluator,
+			Name:        types.ModelIDEvaluator,
+			VersionId:   modelVersion.VersionId,
+			SchedulerId: schedulerID,
+			HostName:    t.config.Server.Host,
+			Ip:          t.config.Server.AdvertiseIP,
+		}); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if !t.config.Scheduler.Training.EnableAutoRefresh {
+		return nil
+	}
+
+	if activeModel.VersionId != "" {
+		activeModelVersion, err := t.managerClient.GetModelVersion(ctx, &managerv1.GetModelVersionRequest{
+			SchedulerId: schedulerID,
+			ModelId:     types.ModelIDEvaluator,
+			VersionId:   activeModel.VersionId,
+		})
+		if err == nil && activeModelVersion.Mae <= metrics.MAE {
+			logger.Infof("model %s version %s is not better than active version %s",
+				types.ModelIDEvaluator, modelVersion.VersionId, activeModel.VersionId)
+			return nil
+		}
+	}
+
+	if _, err := t.managerClient.UpdateModel(ctx, &managerv1.UpdateModelRequest{
+		ModelId:     types.ModelIDEvaluator,
+		VersionId:   modelVersion.VersionId,
+		SchedulerId: schedulerID,
+	}); err != nil {
+		return err
+	}
+	logger.Infof("activate model %s version %s", types.ModelIDEvaluator, modelVersion.VersionId)
+
+	return nil
+}
@@ -0,0 +1,349 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package training
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+
+	"d7y.io/dragonfly/v2/manager/types"
+	managerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+	"d7y.io/dragonfly/v2/scheduler/scheduler/evaluator"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+	storagemocks "d7y.io/dragonfly/v2/scheduler/storage/mocks"
+)
+
+var (
+	mockSchedulerID uint64 = 1
+
+	mockDynconfigData = &config.DynconfigData{
+		Scheduler: &managerv1.Scheduler{
+			Id: mockSchedulerID,
+		},
+	}
+
+	mockConfig = &config.Config{
+		Server: config.ServerConfig{
+			Host:        "localhost",
+			AdvertiseIP: "127.0.0.1",
+		},
+		Scheduler: config.SchedulerConfig{
+			Training: config.TrainingConfig{
+				Enable:               true,
+				EnableAutoRefresh:    true,
+				RefreshModelInterval: config.DefaultRefreshModelInterval,
+				CPU:                  2,
+				HoldoutRatio:         config.DefaultTrainingHoldoutRatio,
+				MaxSamples:           config.DefaultTrainingMaxSamples,
+			},
+		},
+	}
+)
+
+// mockRecords returns the succeeded records downloaded from a single parent, a record
+// downloaded from two parents and a failed record are appended, which are not sampled.
+func mockRecords(n int) []storage.Record {
+	records := make([]storage.Record, n)
+	for i := range records {
+		records[i] = storage.Record{
+			ID:    "foo",
+			State: resource.PeerStateSucceeded,
+			Cost:  uint32(1000 + i%10*100),
+			Task: storage.Task{
+				TotalPieceCount: 10,
+			},
+			Parents: []storage.Parent{
+				{
+					ID: "bar",
+					Host: storage.Host{
+						ConcurrentUploadLimit: 10,
+						ConcurrentUploadCount: int32(i % 10),
+						CPU:                   storage.CPU{Percent: float64(i % 10 * 10)},
+						Memory:                storage.Memory{UsedPercent: float64(i % 5 * 10)},
+					},
+				},
+				{},
+			},
+		}
+	}
+
+	records = append(records, storage.Record{
+		ID:    "baz",
+		State: resource.PeerStateSucceeded,
+		Cost:  1000,
+		Task: storage.Task{
+			TotalPieceCount: 10,
+		},
+		Parents: []storage.Parent{{ID: "bar"}, {ID: "baz"}},
+	})
+	records = append(records, storage.Record{ID: "qux", State: resource.PeerStateFailed})
+	return records
+}
+
+func TestTraining_Train(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []storage.Record
+		mock    func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, s *storagemocks.MockStorageMockRecorder, m *managerclientmocks.MockClientMockRecorder)
+		expect  func(t *testing.T, err error)
+	}{
+		{
+			name:    "create model by the trained model version",
+			records: mockRecords(100),
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, s *storagemocks.MockStorageMockRecorder, m *managerclientmocks.MockClientMockRecorder) {
+				gomock.InOrder(
+					dynconfig.Get().Return(mockDynconfigData, nil).Times(1),
+					m.CreateModelVersion(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(ctx context.Context, req *managerv1.CreateModelVersionRequest, opts ...any) (*managerv1.ModelVersion, error) {
+							if req.SchedulerId != mockSchedulerID || req.ModelId != types.ModelIDEvaluator {
+								return nil, errors.New("invalid request")
+							}
+
+							if _, err := evaluator.UnmarshalModel(req.Data); err != nil {
+								return nil, err
+							}
+
+							return &managerv1.ModelVersion{VersionId: "v1"}, nil
+						}).Times(1),
+					m.GetModel(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+					m.CreateModel(gomock.Any(), &managerv1.CreateModelRequest{
+						ModelId:     types.ModelIDEvaluator,
+						Name:        types.ModelIDEvaluator,
+						VersionId:   "v1",
+						SchedulerId: mockSchedulerID,
+						HostName:    "localhost",
+						Ip:          "127.0.0.1",
+					}).Return(&managerv1.Model{}, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:    "activate the trained model version",
+			records: mockRecords(100),
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, s *storagemocks.MockStorageMockRecorder, m *managerclientmocks.MockClientMockRecorder) {
+				gomock.InOrder(
+					dynconfig.Get().Return(mockDynconfigData, nil).Times(1),
+					m.CreateModelVersion(gomock.Any(), gomock.Any()).Return(&managerv1.ModelVersion{VersionId: "v2"}, nil).Times(1),
+					m.GetModel(gomock.Any(), gomock.Any()).Return(&managerv1.Model{VersionId: "v1"}, nil).Times(1),
+					m.GetModelVersion(gomock.Any(), gomock.Any()).Return(&managerv1.ModelVersion{VersionId: "v1", Mae: 1000}, nil).Times(1),
+					m.UpdateModel(gomock.Any(), &managerv1.UpdateModelRequest{
+						ModelId:     types.ModelIDEvaluator,
+						VersionId:   "v2",
+						SchedulerId: mockSchedulerID,
+					}).Return(&managerv1.Model{}, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:    "active model version is better",
+			records: mockRecords(100),
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, s *storagemocks.MockStorageMockRecorder, m *managerclientmocks.MockClientMockRecorder) {
+				gomock.InOrder(
+					dynconfig.Get().Return(mockDynconfigData, nil).Times(1),
+					m.CreateModelVersion(gomock.Any(), gomock.Any()).Return(&managerv1.ModelVersion{VersionId: "v2"}, nil).Times(1),
+					m.GetModel(gomock.Any(), gomock.Any()).Return(&managerv1.Model{VersionId: "v1"}, nil).Times(1),
+					m.GetModelVersion(gomock.Any(), gomock.Any()).Return(&managerv1.ModelVersion{VersionId: "v1", Mae: 0}, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:    "create model version failed",
+			records: mockRecords(100),
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, s *storagemocks.MockStorageMockRecorder, m *managerclientmocks.MockClientMockRecorder) {
+				gomock.InOrder(
+					dynconfig.Get().Return(mockDynconfigData, nil).Times(1),
+					m.CreateModelVersion(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+		{
+			name:    "insufficient samples",
+			records: mockRecords(10),
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, s *storagemocks.MockStorageMockRecorder, m *managerclientmocks.MockClientMockRecorder) {
+				dynconfig.Get().Return(mockDynconfigData, nil).Times(1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "insufficient samples 10, expect at least 100")
+			},
+		},
+		{
+			name: "scheduler is not registered",
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, s *storagemocks.MockStorageMockRecorder, m *managerclientmocks.MockClientMockRecorder) {
+				dynconfig.Get().Return(&config.DynconfigData{}, nil).Times(1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler is not registered to manager")
+			},
+		},
+		{
+			name: "get dynconfig failed",
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder, s *storagemocks.MockStorageMockRecorder, m *managerclientmocks.MockClientMockRecorder) {
+				dynconfig.Get().Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			managerClient := managerclientmocks.NewMockClient(ctl)
+			s := storagemocks.NewMockStorage(ctl)
+			iterator := storagemocks.NewMockIterator(ctl)
+
+			if tc.records != nil {
+				s.EXPECT().Iterate().Return(iterator, nil).Times(1)
+				for i := range tc.records {
+					iterator.EXPECT().Next().Return(&tc.records[i], nil).Times(1)
+				}
+				iterator.EXPECT().Next().Return(nil, io.EOF).Times(1)
+				iterator.EXPECT().Close().Return(nil).Times(1)
+			}
+
+			tc.mock(dynconfig.EXPECT(), s.EXPECT(), managerClient.EXPECT())
+			training := New(mockConfig, s, managerClient, dynconfig)
+			tc.expect(t, training.Train(context.Background()))
+		})
+	}
+}
+
+func TestTraining_samples(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxSamples int
+		mock       func(s *storagemocks.MockStorageMockRecorder, iterator *storagemocks.MockIterator)
+		expect     func(t *testing.T, samples []Sample, err error)
+	}{
+		{
+			name: "convert records to samples",
+			mock: func(s *storagemocks.MockStorageMockRecorder, iterator *storagemocks.MockIterator) {
+				records := mockRecords(1)
+				gomock.InOrder(
+					s.Iterate().Return(iterator, nil).Times(1),
+					iterator.EXPECT().Next().Return(&records[0], nil).Times(1),
+					iterator.EXPECT().Next().Return(&records[1], nil).Times(1),
+					iterator.EXPECT().Next().Return(&records[2], nil).Times(1),
+					iterator.EXPECT().Next().Return(nil, io.EOF).Times(1),
+					iterator.EXPECT().Close().Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, samples []Sample, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(len(samples), 1)
+				for _, sample := range samples {
+					assert.Equal(sample.Cost, float64(100))
+					assert.Equal(sample.Features.FreeUploadScore, float64(1))
+				}
+			},
+		},
+		{
+			name:       "samples are limited by max samples",
+			maxSamples: 3,
+			mock: func(s *storagemocks.MockStorageMockRecorder, iterator *storagemocks.MockIterator) {
+				records := mockRecords(10)
+				s.Iterate().Return(iterator, nil).Times(1)
+				for i := range records {
+					iterator.EXPECT().Next().Return(&records[i], nil).Times(1)
+				}
+				iterator.EXPECT().Next().Return(nil, io.EOF).Times(1)
+				iterator.EXPECT().Close().Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, samples []Sample, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(len(samples), 3)
+			},
+		},
+		{
+			name: "iterate storage failed",
+			mock: func(s *storagemocks.MockStorageMockRecorder, iterator *storagemocks.MockIterator) {
+				s.Iterate().Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, samples []Sample, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+		{
+			name: "read record failed",
+			mock: func(s *storagemocks.MockStorageMockRecorder, iterator *storagemocks.MockIterator) {
+				gomock.InOrder(
+					s.Iterate().Return(iterator, nil).Times(1),
+					iterator.EXPECT().Next().Return(nil, errors.New("foo")).Times(1),
+					iterator.EXPECT().Close().Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, samples []Sample, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s := storagemocks.NewMockStorage(ctl)
+			iterator := storagemocks.NewMockIterator(ctl)
+
+			cfg := *mockConfig
+			if tc.maxSamples > 0 {
+				cfg.Scheduler.Training.MaxSamples = tc.maxSamples
+			}
+
+			tc.mock(s.EXPECT(), iterator)
+			tr := New(&cfg, s, nil, nil)
+			samples, err := tr.(*training).samples()
+			tc.expect(t, samples, err)
+		})
+	}
+}